@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	stdlog "log"
 	"net/http"
@@ -14,11 +13,15 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 
+	"github.com/onerilhan/go-payment-api/internal/auth"
 	"github.com/onerilhan/go-payment-api/internal/config"
 	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/features"
 	"github.com/onerilhan/go-payment-api/internal/handlers"
+	"github.com/onerilhan/go-payment-api/internal/health"
 	"github.com/onerilhan/go-payment-api/internal/logger"
 	"github.com/onerilhan/go-payment-api/internal/middleware"
 	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
@@ -27,6 +30,8 @@ import (
 	"github.com/onerilhan/go-payment-api/internal/models"
 	"github.com/onerilhan/go-payment-api/internal/repository"
 	"github.com/onerilhan/go-payment-api/internal/services"
+	"github.com/onerilhan/go-payment-api/internal/storage"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 func main() {
@@ -41,13 +46,42 @@ func main() {
 	// logger başlat
 	logger.Init(cfg.AppEnv)
 
+	// JSON yanıt charset'i
+	utils.SetJSONCharset(cfg.JSONCharset)
+
+	// Geçmiş endpoint'lerinin varsayılan sıralama yönü
+	if err := models.SetDefaultSortOrder(cfg.HistoryDefaultSort); err != nil {
+		log.Fatal().Err(err).Msg("Geçersiz HISTORY_DEFAULT_SORT")
+	}
+
+	// İstatistik toplamlarının ondalık hassasiyeti
+	if err := models.SetAmountPrecision(cfg.AmountPrecision); err != nil {
+		log.Fatal().Err(err).Msg("Geçersiz AMOUNT_PRECISION")
+	}
+
+	// JWT imza anahtarı (zayıf/eksik anahtar ile token üretilmesin diye açılış durdurulur)
+	if err := configureJWT(cfg); err != nil {
+		log.Fatal().Err(err).Str("alg", cfg.JWTAlg).Msg("Geçersiz JWT yapılandırması")
+	}
+
+	// Access ve refresh token geçerlilik süreleri
+	auth.SetAccessTokenTTL(cfg.AccessTokenTTL)
+	auth.SetRefreshTokenTTL(cfg.RefreshTokenTTL)
+
+	// Refresh ile uzatılabilecek mutlak oturum süresi
+	auth.SetMaxSessionAge(cfg.SessionMaxAge)
+
+	// Ortam bazlı feature flag'ler
+	features.Set(features.Parse(cfg.FeatureFlags))
+
 	log.Info().
 		Str("environment", cfg.AppEnv).
+		Interface("features", features.All()).
 		Str("port", cfg.Port).
 		Msg("Ödeme API Projesi başlatıldı")
 
 	// Database bağlantısı
-	database, err := db.Connect(cfg.GetDSN())
+	database, err := db.Connect(cfg.GetDSN(), cfg.DBQueryMetrics)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Veritabanı bağlantısı başarısız")
 	}
@@ -70,40 +104,208 @@ func main() {
 	// DEBUG: Migration çağrısından sonra
 	log.Info().Msg("DEBUG: Migration runner tamamlandı")
 
+	// Şema doğrulaması: eksik migration'lı deploy runtime scan hatası yerine açılışta yakalanır
+	if cfg.SchemaValidationEnabled {
+		schemaModels := map[string]interface{}{
+			"users":         models.User{},
+			"transactions":  models.Transaction{},
+			"balances":      models.Balance{},
+			"balance_holds": models.BalanceHold{},
+		}
+		if err := db.ValidateSchema(db.NewPostgresSchemaSource(database), schemaModels); err != nil {
+			log.Fatal().Err(err).Msg("Şema doğrulaması başarısız")
+		}
+	}
+
 	// Repository, Service, Handler katmanları
 	userRepo := repository.NewUserRepository(database)
 	transactionRepo := repository.NewTransactionRepository(database)
 	balanceRepo := repository.NewBalanceRepository(database)
 
+	// Kapatılan hesapların token'ları her instance'ta ve restart sonrasında da reddedilir
+	auth.SetActiveUserChecker(userRepo.IsActive)
+
 	userService := services.NewUserService(userRepo)
+	accountNumberConfig := services.DefaultAccountNumberConfig()
+	accountNumberConfig.CountryCode = cfg.AccountNumberCountryCode
+	accountNumberConfig.BankCode = cfg.AccountNumberBankCode
+	userService.SetAccountNumberConfig(accountNumberConfig)
+	if err := userService.SetRegisterableRoles(cfg.RegisterableRoles); err != nil {
+		log.Fatal().Err(err).Msg("Geçersiz REGISTERABLE_ROLES")
+	}
+
+	// İlk admin bootstrap'ı (ADMIN_EMAIL/ADMIN_PASSWORD verilmişse ve hiç admin yoksa)
+	adminUser, adminCreated, err := userService.BootstrapAdmin(cfg.AdminName, cfg.AdminEmail, cfg.AdminPassword)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Admin bootstrap başarısız")
+	}
+	if adminCreated {
+		log.Info().Int("user_id", adminUser.ID).Str("email", adminUser.Email).Msg("İlk admin kullanıcı oluşturuldu")
+	}
+
 	balanceService := services.NewBalanceService(balanceRepo)
-	transactionService := services.NewTransactionService(transactionRepo, balanceService, database)
+
+	// Bakiyenin diğer para birimlerinde gösterimi (display_in); sabit kur tablosu boşsa kapalı
+	switch cfg.ExchangeRateSource {
+	case "database":
+		balanceService.SetCurrencyDisplay(services.NewRepositoryRateProvider(repository.NewExchangeRateRepository(database)), cfg.DisplayCurrencies)
+	case "static":
+		if len(cfg.ExchangeRates) > 0 {
+			balanceService.SetCurrencyDisplay(services.NewStaticRateProvider(cfg.ExchangeRates), cfg.DisplayCurrencies)
+		}
+	default:
+		log.Fatal().Str("source", cfg.ExchangeRateSource).Msg("Geçersiz EXCHANGE_RATE_SOURCE (static | database)")
+	}
+	transactionService := services.NewTransactionService(transactionRepo, userRepo, balanceService, database)
+
+	retryConfig := db.DefaultRetryConfig()
+	retryConfig.MaxRetries = cfg.TxRetryMaxRetries
+	transactionService.SetRetryConfig(retryConfig)
+	transactionService.SetBulkStatusMaxIDs(cfg.BulkStatusMaxIDs)
+
+	limitConfig := services.DefaultTransactionLimitConfig()
+	if cfg.DailyTxCountLimits != nil {
+		limitConfig.DailyCountByRole = cfg.DailyTxCountLimits
+	}
+	limitConfig.DailySoftLimitRatio = cfg.DailySoftLimitRatio
+	limitConfig.TransferDescriptionRequiredAbove = cfg.TransferDescriptionRequiredAbove
+	limitConfig.MemoTransfersPerRecipient = cfg.MemoTransfersPerRecipient
+	limitConfig.MemoTransferWindow = cfg.MemoTransferWindow
+	limitConfig.NewAccountTransferCooldown = cfg.NewAccountTransferCooldown
+	limitConfig.MinTransferInterval = cfg.MinTransferInterval
+	for txType, maxAmount := range cfg.TransactionMaxAmounts {
+		limitConfig.MaxAmountByType[txType] = maxAmount
+	}
+	limitConfig.MinAmountByType = cfg.TransactionMinAmounts
+	limitConfig.DailyAmountLimitDefault = cfg.DailyTxAmountLimit
+	limitConfig.DailyAmountByRole = cfg.DailyTxAmountLimits
+	transactionService.SetLimitConfig(limitConfig)
+	fraudReviewConfig := &services.FraudReviewConfig{
+		AmountThreshold:             cfg.FraudReviewAmount,
+		NewRecipientAmountThreshold: cfg.FraudReviewNewRecipientAmount,
+		AnomalyMultiple:             cfg.FraudReviewAnomalyMultiple,
+		AnomalyLookback:             cfg.FraudReviewAnomalyLookback,
+		AnomalyMinHistory:           cfg.FraudReviewAnomalyMinHistory,
+	}
+	transactionService.SetReviewRules(fraudReviewConfig.ReviewRules(transactionRepo)...)
+
+	// Idempotency-Key: tekrar gönderilen credit/debit/transfer ilk sonucu döner
+	idempotencyRepo := repository.NewIdempotencyRepository(database)
+	idempotencyConfig := services.DefaultIdempotencyConfig()
+	idempotencyConfig.Retention = cfg.IdempotencyKeyRetention
+	idempotencyConfig.WaitTimeout = cfg.IdempotencyKeyWaitTimeout
+	transactionService.SetIdempotency(idempotencyRepo, idempotencyConfig)
 
 	// Transaction Queue oluştur (3 worker, 50 buffer)
 	transactionQueue := services.NewTransactionQueue(3, transactionService, 50)
+	queueRetryConfig := services.DefaultTransactionQueueRetryConfig()
+	queueRetryConfig.MaxRetries = cfg.TxQueueMaxRetries
+	queueRetryConfig.BaseDelay = cfg.TxQueueRetryBaseDelay
+	queueRetryConfig.MaxDelay = cfg.TxQueueRetryMaxDelay
+	queueRetryConfig.DeadLetterSize = cfg.TxQueueDeadLetterSize
+	transactionQueue.SetRetryConfig(queueRetryConfig)
+	transactionQueue.SetEnqueueTimeout(cfg.TxQueueEnqueueTimeout)
 	transactionQueue.Start()
 
-	userHandler := handlers.NewUserHandler(userService)
+	accountService := services.NewAccountService(database)
+	accountService.SetRetryConfig(retryConfig)
+
+	notificationService := services.NewNotificationService(repository.NewNotificationPreferenceRepository(database))
+	userHandler := handlers.NewUserHandler(userService, accountService, notificationService)
 	balanceHandler := handlers.NewBalanceHandler(balanceService)
 	transactionHandler := handlers.NewTransactionHandler(transactionService, transactionQueue, balanceService)
+	transactionHandler.SetOffsetPagination(cfg.HistoryOffsetPagination)
+
+	// İleri tarihli transferler: zamanı gelenler queue'ya verilir
+	transferSchedulerConfig := services.DefaultTransferSchedulerConfig()
+	transferSchedulerConfig.PollInterval = cfg.ScheduledTransferPollInterval
+	transferSchedulerConfig.BatchSize = cfg.ScheduledTransferBatchSize
+	transferSchedulerConfig.MaxActivePerUser = cfg.ScheduledTransferMaxActivePerUser
+	transferScheduler := services.NewTransferScheduler(transactionService, repository.NewScheduledTransferRepository(database), transactionQueue, transferSchedulerConfig)
+	transactionHandler.SetTransferScheduler(transferScheduler)
 
 	// Global context (metrics gibi background goroutine'leri durdurmak için)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Index kullanım sağlık kontrolü (sıcak tablolarda seq scan uyarısı)
+	if cfg.IndexHealthCheckEnabled {
+		indexHealthConfig := db.DefaultIndexHealthConfig()
+		indexHealthConfig.CheckInterval = cfg.IndexHealthCheckInterval
+		indexHealthConfig.SeqScanThreshold = cfg.IndexHealthSeqScanRatio
+		db.StartIndexHealthMonitor(ctx, db.NewPostgresTableStatsSource(database), indexHealthConfig)
+	}
+
+	// Eski bakiye geçmişini arşive taşı (kullanıcı başına baseline bırakılır)
+	if cfg.BalanceHistoryRetention > 0 {
+		balanceArchiveConfig := services.DefaultBalanceArchiveConfig()
+		balanceArchiveConfig.Retention = cfg.BalanceHistoryRetention
+		balanceArchiveConfig.Interval = cfg.BalanceHistoryArchiveInterval
+		services.StartBalanceHistoryArchiver(ctx, balanceRepo, balanceArchiveConfig)
+	}
+
+	// Periyodik bakiye snapshot'ları (belirli tarihteki bakiye en yakın snapshot'tan ileri doğru hesaplanır)
+	if cfg.BalanceSnapshotInterval > 0 {
+		balanceSnapshotConfig := services.DefaultBalanceSnapshotConfig()
+		balanceSnapshotConfig.Interval = cfg.BalanceSnapshotInterval
+		services.StartBalanceSnapshotWriter(ctx, balanceRepo, balanceSnapshotConfig)
+	}
+
+	// Bakiyeleri ledger (balance_history) ile periyodik karşılaştır, sapan kullanıcıları alert olarak bildir
+	if cfg.BalanceReconciliationInterval > 0 {
+		reconciliationConfig := services.DefaultBalanceReconciliationConfig()
+		reconciliationConfig.Interval = cfg.BalanceReconciliationInterval
+		reconciliationConfig.BatchSize = cfg.BalanceReconciliationBatchSize
+		services.StartBalanceReconciler(ctx, balanceRepo, reconciliationConfig, services.LogReconciliationAlert)
+	}
+
+	// Önceki günün transaction'larını storage'a yaz (gece raporlaması için)
+	if cfg.TransactionExportDir != "" {
+		exportStorage, err := storage.NewLocalStorage(cfg.TransactionExportDir)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Transaction export storage oluşturulamadı")
+		}
+		exportConfig := services.DefaultTransactionExportConfig()
+		exportConfig.Format = cfg.TransactionExportFormat
+		exportConfig.Interval = cfg.TransactionExportInterval
+		services.StartTransactionExporter(ctx, transactionRepo, exportStorage, exportConfig)
+	}
+
+	// Saklama süresi dolan idempotency key'leri temizle
+	services.StartIdempotencyKeyCleanup(ctx, idempotencyRepo, idempotencyConfig)
+
+	// Zamanlanmış transfer scheduler'ı (shutdown'da queue'dan önce durdurulur)
+	transferScheduler.Start(ctx)
+
+	// Readiness (shutdown başlayınca load balancer'a hazır olmadığımızı bildirir)
+	readiness := health.NewReadiness()
+	degradationConfig := health.DefaultDegradationConfig()
+	degradationConfig.QueueSaturation = cfg.ReadinessQueueSaturation
+	degradationConfig.DBLatencyThreshold = cfg.ReadinessDBLatencyThreshold
+	readiness.SetDegradationChecks(degradationConfig, transactionQueue.Stats, database.PingContext)
+
 	// Gorilla Mux Router Setup
-	router := setupRouter(userHandler, balanceHandler, transactionHandler, cfg.AppEnv, userService, ctx, database)
+	router := setupRouter(userHandler, balanceHandler, transactionHandler, cfg, userService, ctx, database, readiness, transactionQueue)
 
 	// HTTP Server configuration
 	serverAddr := ":" + cfg.Port
+	connLimitConfig := middleware.DefaultConnLimitConfig()
+	connLimitConfig.MaxConnsPerIP = cfg.MaxConnsPerIP
+	// Proxy arkasındaki client'lar proxy'nin IP'sini paylaşır: limit sadece doğrudan bağlananlara uygulanır
+	connLimitConfig.WhitelistIPs = append(connLimitConfig.WhitelistIPs, cfg.TrustedProxies...)
+	connLimiter := middleware.NewConnLimiter(connLimitConfig)
 	server := &http.Server{
 		Addr:         serverAddr,
 		Handler:      router,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		ConnState:    connLimiter.ConnState, // slowloris: IP başına açık bağlantı limiti
 	}
 
+	// Effective config dump (secret'lar maskelenir)
+	logEffectiveConfig(cfg, server, database)
+
 	// Graceful shutdown setup
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
@@ -134,6 +336,21 @@ func main() {
 			Str("signal", sig.String()).
 			Msg("Shutdown signal alındı, graceful shutdown başlıyor...")
 
+		// Önce readiness'ı düşür ve LB'nin instance'ı kayıttan çıkarmasını bekle (ikinci signal beklemeyi keser)
+		drainCtx, drainCancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-shutdown:
+				drainCancel()
+			case <-drainCtx.Done():
+			}
+		}()
+		readiness.BeginShutdown(drainCtx, cfg.ShutdownDrainDelay)
+		drainCancel()
+
+		// Scheduler kapanan queue'ya job vermesin
+		transferScheduler.Stop()
+
 		// Graceful shutdown sequence başlat
 		performGracefulShutdown(server, transactionQueue)
 		// Global context'i de iptal et (metrics'in arka plan goroutine'i durur)
@@ -141,6 +358,75 @@ func main() {
 	}
 }
 
+// configureJWT JWT_ALG'e göre HS256 secret'ını veya RS256 PEM anahtarlarını yükler
+func configureJWT(cfg *config.Config) error {
+	switch cfg.JWTAlg {
+	case auth.AlgHS256:
+		return auth.Configure([]byte(cfg.JWTSecret))
+	case auth.AlgRS256:
+		privateKeyPEM, err := os.ReadFile(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("JWT_PRIVATE_KEY_FILE okunamadı: %w", err)
+		}
+		publicKeyPEM, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("JWT_PUBLIC_KEY_FILE okunamadı: %w", err)
+		}
+		return auth.ConfigureRS256(privateKeyPEM, publicKeyPEM)
+	default:
+		return fmt.Errorf("%w: %s", auth.ErrUnsupportedAlgorithm, cfg.JWTAlg)
+	}
+}
+
+// newRateLimitStore RATE_LIMIT_BACKEND'e göre limit store'unu oluşturur (memory için nil = middleware'in varsayılanı).
+// Redis'e açılışta ulaşılamazsa hata döner; çalışırken erişilemezse middleware istekleri engellemez.
+func newRateLimitStore(ctx context.Context, cfg *config.Config, rateLimitConfig *middleware.RateLimitConfig) (middleware.LimiterStore, error) {
+	switch cfg.RateLimitBackend {
+	case middleware.RateLimitBackendMemory:
+		return nil, nil
+	case middleware.RateLimitBackendRedis:
+		// Havuz sınırlıdır: Redis yavaşladığında istekler yeni bağlantı açmak yerine PoolTimeout kadar bekleyip hata alır
+		redisOptions := &redis.Options{
+			Addr:         cfg.RedisAddr,
+			Password:     cfg.RedisPassword,
+			DB:           cfg.RedisDB,
+			DialTimeout:  2 * time.Second,
+			ReadTimeout:  time.Second,
+			WriteTimeout: time.Second,
+			PoolSize:     10,
+			PoolTimeout:  2 * time.Second,
+		}
+		client := redis.NewClient(redisOptions)
+
+		pingCtx, cancel := context.WithTimeout(ctx, redisOptions.DialTimeout)
+		defer cancel()
+		if err := client.Ping(pingCtx).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("redis'e bağlanılamadı (%s): %w", cfg.RedisAddr, err)
+		}
+		return middleware.NewRedisLimiterStore(client, rateLimitConfig, "ratelimit:"), nil
+	default:
+		return nil, fmt.Errorf("desteklenmeyen rate limit backend'i: %s", cfg.RateLimitBackend)
+	}
+}
+
+// logEffectiveConfig yüklenen effective config'i debug seviyesinde loglar
+func logEffectiveConfig(cfg *config.Config, server *http.Server, database *sql.DB) {
+	rateLimitConfig := middleware.DefaultRateLimitConfig()
+
+	log.Debug().
+		Interface("config", cfg.Dump()).
+		Dur("read_timeout", server.ReadTimeout).
+		Dur("write_timeout", server.WriteTimeout).
+		Dur("idle_timeout", server.IdleTimeout).
+		Int("rate_limit_requests_per_minute", rateLimitConfig.RequestsPerMinute).
+		Int("rate_limit_burst", rateLimitConfig.Burst).
+		Dur("rate_limit_window", rateLimitConfig.WindowSize).
+		Strs("cors_allowed_origins", middleware.DefaultCORSConfig().AllowedOrigins).
+		Int("db_max_open_conns", database.Stats().MaxOpenConnections).
+		Msg("Effective configuration")
+}
+
 // performGracefulShutdown graceful shutdown işlemlerini sırasıyla yapar
 func performGracefulShutdown(server *http.Server, transactionQueue *services.TransactionQueue) {
 	// Shutdown timeout context (maksimum 30 saniye bekle)
@@ -180,22 +466,14 @@ func performGracefulShutdown(server *http.Server, transactionQueue *services.Tra
 		}
 	}
 
-	// 2. Transaction Queue'yu durdur
+	// 2. Transaction Queue'yu durdur (buffer'daki job'lar işlenir, süre dolarsa kalanlar hata sonucu alır)
 	log.Info().Msg("Transaction Queue graceful shutdown başlatılıyor...")
-	queueDone := make(chan struct{})
-	go func() {
-		defer close(queueDone)
-		transactionQueue.Stop()
+	queueCtx, queueCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer queueCancel()
+	if err := transactionQueue.Drain(queueCtx); err != nil {
+		log.Warn().Err(err).Msg("Transaction Queue shutdown timeout! Bekleyen job'lar hata ile sonuçlandırıldı")
+	} else {
 		log.Info().Msg("Transaction Queue graceful shutdown tamamlandı")
-	}()
-
-	// Queue shutdown timeout kontrolü (10 saniye)
-	queueTimeout := time.NewTimer(10 * time.Second)
-	select {
-	case <-queueDone:
-		queueTimeout.Stop()
-	case <-queueTimeout.C:
-		log.Warn().Msg("Transaction Queue shutdown timeout!")
 	}
 
 	// 3. Final log
@@ -203,35 +481,61 @@ func performGracefulShutdown(server *http.Server, transactionQueue *services.Tra
 }
 
 // setupRouter Gorilla Mux router'ını ayarlar
-func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.BalanceHandler, transactionHandler *handlers.TransactionHandler, appEnv string, userService *services.UserService, ctx context.Context, database *sql.DB) *mux.Router {
+func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.BalanceHandler, transactionHandler *handlers.TransactionHandler, cfg *config.Config, userService *services.UserService, ctx context.Context, database *sql.DB, readiness *health.Readiness, transactionQueue *services.TransactionQueue) *mux.Router {
 	router := mux.NewRouter()
+	appEnv := cfg.AppEnv
 
 	// MIDDLEWARE CHAIN SIRASI (önemli!)
-	// Request → Error → CORS → Logging → Security → RateLimit → Auth → Handler
+	// Request → ServerTiming → Error → CORS → Logging → Security → RateLimit → Auth → Handler
+
+	// Server-Timing (en dışta - error middleware'in ürettiği response'lar da süreleri taşısın; yalnızca development)
+	router.Use(middleware.NewServerTimingMiddleware(&middleware.ServerTimingConfig{
+		Enabled: cfg.ServerTimingEnabled,
+		AppEnv:  appEnv,
+	}))
 
-	//  Error Handling Middleware (en dışta - panic recovery için)
+	//  Error Handling Middleware (panic recovery için)
+	// Aynı config handler'ların hata mesajı çevirisinde de kullanılır (production'da DB detayları gizlenir)
+	errorConfig := errors.ProductionErrorConfig()
 	if appEnv == "development" {
-		router.Use(middleware.ErrorHandlingMiddlewareForDevelopment())
-	} else {
-		router.Use(middleware.ErrorHandlingMiddlewareForProduction())
+		errorConfig = errors.DevelopmentErrorConfig()
 	}
+	errors.SetActiveConfig(errorConfig)
+	router.Use(middleware.ErrorHandlingMiddleware(errorConfig))
 
 	// Validation middleware
+	var validationConfig *validation.Config
 	if appEnv == "development" {
 		// Development: Detaylı hata mesajları
-		config := validation.DefaultConfig()
-		config.PathValidation = map[string]string{
+		validationConfig = validation.DefaultConfig()
+		validationConfig.PathValidation = map[string]string{
 			"id":      "positive_integer",
 			"user_id": "positive_integer",
 		}
-		config.RequireNonEmptyJSON = true
-		router.Use(validation.Middleware(config))
+		validationConfig.RequireNonEmptyJSON = true
 	} else {
 		// Production: Strict validation
-		router.Use(validation.Middleware(validation.StrictConfig()))
+		validationConfig = validation.StrictConfig()
+	}
+	if cfg.FormMaxMemory > 0 {
+		validationConfig.MaxFormMemory = cfg.FormMaxMemory
 	}
+	validationConfig.DetailedSecurityErrors = cfg.SecurityErrorDetails
+	router.Use(validation.Middleware(validationConfig))
 	// 3. Metrics middleware (Response time, memory, request count, vb.)
-	metricsMW, metricsHandler := middleware.NewMetricsMiddleware(ctx, middleware.DefaultMetricsConfig())
+	// Rate limit sayaçları metrics ve rate limit middleware'leri arasında paylaşılır
+	var rateLimitMetrics *middleware.RateLimitMetrics
+	if cfg.RateLimitMetrics {
+		rateLimitMetrics = middleware.NewRateLimitMetrics()
+	}
+	metricsConfig := middleware.DefaultMetricsConfig()
+	metricsConfig.GroupByRouteTemplate = cfg.LogRouteTemplate
+	metricsConfig.EnableQueryCount = cfg.DBQueryMetrics
+	metricsConfig.RateLimitMetrics = rateLimitMetrics
+	metricsConfig.EndpointTTL = cfg.MetricsEndpointTTL
+	metricsConfig.ResponseTimeBuckets = cfg.MetricsResponseTimeBuckets
+	metricsConfig.QueueMetricsSource = func() interface{} { return transactionQueue.Metrics() }
+	metricsMW, metricsHandler := middleware.NewMetricsMiddleware(ctx, metricsConfig)
 	router.Use(metricsMW)
 	// Metrics endpoint
 	router.HandleFunc("/metrics", metricsHandler).Methods("GET")
@@ -240,13 +544,26 @@ func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.Bal
 	router.Use(middleware.CORSMiddlewareWithDefaults())
 
 	// Logger middleware
-	router.Use(middleware.RequestLoggingMiddlewareWithDefaults())
+	loggingConfig := middleware.DefaultLoggingConfig()
+	loggingConfig.RequestIDGenerator = middleware.NewRequestIDGenerator(cfg.RequestIDFormat)
+	loggingConfig.LogRouteTemplate = cfg.LogRouteTemplate
+	loggingConfig.LogQueryCount = cfg.DBQueryMetrics
+	router.Use(middleware.RequestLoggingMiddleware(loggingConfig))
 
 	// Security headers middleware
-	router.Use(middleware.SecurityHeadersMiddlewareWithDefaults())
+	securityConfig := middleware.DefaultSecurityConfig()
+	securityConfig.TrustedProxies = cfg.TrustedProxies
+	router.Use(middleware.SecurityHeadersMiddleware(securityConfig))
 
 	// Rate limit middleware
-	router.Use(middleware.RateLimitMiddlewareWithDefaults())
+	rateLimitConfig := middleware.DefaultRateLimitConfig()
+	rateLimitConfig.Metrics = rateLimitMetrics
+	rateLimitStore, err := newRateLimitStore(ctx, cfg, rateLimitConfig)
+	if err != nil {
+		log.Fatal().Err(err).Str("backend", cfg.RateLimitBackend).Msg("Rate limit store oluşturulamadı")
+	}
+	rateLimitConfig.Store = rateLimitStore
+	router.Use(middleware.NewRateLimitMiddleware(rateLimitConfig).Handler())
 
 	// Global OPTIONS handler
 	router.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -254,7 +571,8 @@ func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.Bal
 	})
 
 	// Health check endpoint
-	router.HandleFunc("/health", getHealthHandler(database)).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/health", getHealthHandler(database, newMigrationStatusCache(database, cfg.HealthMigrationStatusTTL), readiness)).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/ready", readiness.Handler()).Methods(http.MethodGet, http.MethodHead)
 
 	// Development test endpoints
 	if appEnv == "development" {
@@ -312,9 +630,7 @@ func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.Bal
 				return
 			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusCreated)
-			json.NewEncoder(w).Encode(map[string]interface{}{
+			utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{
 				"success": true,
 				"message": "Admin user created successfully",
 				"admin": map[string]interface{}{
@@ -338,15 +654,23 @@ func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.Bal
 
 	// Protected endpoints (Authentication required)
 	protected := api.NewRoute().Subrouter()
-	protected.Use(middleware.AuthMiddleware)
+	authConfig := middleware.DefaultAuthConfig()
+	authConfig.DetailedErrors = cfg.AuthDetailedErrors
+	protected.Use(middleware.NewAuthMiddleware(authConfig))
+
+	// Logout access token gerektirdiği için protected altında (path /api/v1/auth/logout)
+	protected.HandleFunc("/auth/logout", userHandler.Logout).Methods("POST")
 
 	// User endpoints with RBAC
 	users := protected.PathPrefix("/users").Subrouter()
 	users.Use(middleware.UserManagementRBAC())
 	users.HandleFunc("", userHandler.GetAllUsers).Methods("GET")
 	users.HandleFunc("/profile", userHandler.GetProfile).Methods("GET")
+	users.HandleFunc("/profile/close", userHandler.CloseAccount).Methods("POST")
+	users.HandleFunc("/profile/notifications", userHandler.GetNotificationPreferences).Methods("GET")
+	users.HandleFunc("/profile/notifications", userHandler.UpdateNotificationPreferences).Methods("PUT")
 	users.HandleFunc("/{id:[0-9]+}", userHandler.GetUserByID).Methods("GET")
-	users.HandleFunc("/{id:[0-9]+}", userHandler.UpdateUser).Methods("PUT")
+	users.HandleFunc("/{id:[0-9]+}", userHandler.UpdateUser).Methods("PUT", "PATCH")
 	users.HandleFunc("/{id:[0-9]+}", userHandler.DeleteUser).Methods("DELETE")
 
 	// Admin-only endpoints
@@ -355,14 +679,43 @@ func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.Bal
 	adminUsers.HandleFunc("/{id:[0-9]+}/promote", userHandler.PromoteToMod).Methods("POST")
 	adminUsers.HandleFunc("/{id:[0-9]+}/demote", userHandler.DemoteUser).Methods("POST")
 
+	// Admin-only: fraud incelemesindeki transferler
+	adminReviews := protected.PathPrefix("/admin/transaction-reviews").Subrouter()
+	adminReviews.Use(middleware.RequireAdmin())
+	adminReviews.HandleFunc("", transactionHandler.ListTransferReviews).Methods("GET")
+	adminReviews.HandleFunc("/{id:[0-9]+}/approve", transactionHandler.ApproveTransferReview).Methods("POST")
+	adminReviews.HandleFunc("/{id:[0-9]+}/reject", transactionHandler.RejectTransferReview).Methods("POST")
+
+	// Admin-only: pending_review transaction'ları transaction ID ile onay/red
+	adminTransactions := protected.PathPrefix("/admin/transactions").Subrouter()
+	adminTransactions.Use(middleware.RequireAdmin())
+	adminTransactions.HandleFunc("/{id:[0-9]+}/approve", transactionHandler.ApproveHeldTransaction).Methods("POST")
+	adminTransactions.HandleFunc("/{id:[0-9]+}/reject", transactionHandler.RejectHeldTransaction).Methods("POST")
+	adminTransactions.HandleFunc("/bulk-status", transactionHandler.BulkUpdateTransactionStatus).Methods("POST")
+
 	// Transaction endpoints with RBAC
 	transactions := protected.PathPrefix("/transactions").Subrouter()
 	transactions.Use(middleware.RequirePermission(middleware.PermMakeTransaction))
 	transactions.HandleFunc("/credit", transactionHandler.Credit).Methods("POST")
 	transactions.HandleFunc("/debit", transactionHandler.Debit).Methods("POST")
 	transactions.HandleFunc("/transfer", transactionHandler.Transfer).Methods("POST")
+	transactions.HandleFunc("/transfer-by-account-number", transactionHandler.TransferByAccountNumber).Methods("POST")
+	transactions.HandleFunc("/transfer-by-email", transactionHandler.TransferByEmail).Methods("POST")
+	transactions.HandleFunc("/batch-transfer", transactionHandler.BatchTransfer).Methods("POST")
+	transactions.HandleFunc("/authorize", transactionHandler.AuthorizeHold).Methods("POST")
+	transactions.HandleFunc("/capture", transactionHandler.CaptureHold).Methods("POST")
+	transactions.HandleFunc("/void", transactionHandler.VoidHold).Methods("POST")
 	transactions.HandleFunc("/history", transactionHandler.GetHistory).Methods("GET")
+	transactions.HandleFunc("/limits", transactionHandler.GetLimits).Methods("GET")
+	transactions.HandleFunc("/counterparties", transactionHandler.GetCounterparties).Methods("GET")
+	transactions.HandleFunc("/scheduled/{id:[0-9]+}", transactionHandler.CancelScheduledTransfer).Methods("DELETE")
 	transactions.HandleFunc("/{id:[0-9]+}", transactionHandler.GetTransactionByID).Methods("GET")
+	transactions.HandleFunc("/{id:[0-9]+}/refund", transactionHandler.RefundTransaction).Methods("POST")
+
+	// Admin-only: completed transaction'ın ters kaydı (iade)
+	reversals := transactions.NewRoute().Subrouter()
+	reversals.Use(middleware.RequirePermission(middleware.PermReverseTransaction))
+	reversals.HandleFunc("/{id:[0-9]+}/reverse", transactionHandler.ReverseTransaction).Methods("POST")
 
 	// Balance endpoints with RBAC
 	balances := protected.PathPrefix("/balances").Subrouter()
@@ -370,10 +723,12 @@ func setupRouter(userHandler *handlers.UserHandler, balanceHandler *handlers.Bal
 	balances.HandleFunc("/current", balanceHandler.GetCurrentBalance).Methods("GET")
 	balances.HandleFunc("/historical", balanceHandler.GetBalanceHistory).Methods("GET")
 	balances.HandleFunc("/at-time", balanceHandler.GetBalanceAtTime).Methods("GET")
+	balances.HandleFunc("/minimum", balanceHandler.GetMinimumBalance).Methods("GET")
+	balances.HandleFunc("/minimum", balanceHandler.UpdateMinimumBalance).Methods("PUT")
 
 	// JSON NotFound ve MethodNotAllowed handlers
 	router.NotFoundHandler = middleware.NotFoundJSONHandler()
-	router.MethodNotAllowedHandler = middleware.MethodNotAllowedJSONHandler()
+	router.MethodNotAllowedHandler = middleware.MethodNotAllowedJSONHandler(router)
 
 	// Route listesini log'la (development için)
 	if appEnv == "development" {
@@ -509,8 +864,22 @@ func autoMigrate(runner *migration.Runner) error {
 }
 
 // getHealthHandler migration status içeren health check handler döner
-func getHealthHandler(database *sql.DB) http.HandlerFunc {
+// DB ping her istekte yapılır, migration status ise cache TTL'i boyunca tekrar okunmaz
+func getHealthHandler(database *sql.DB, migrationStatusCache *migration.StatusCache, readiness *health.Readiness) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Shutdown başladıysa (drain süresi) LB trafiği kessin diye 503 dön
+		if !readiness.IsReady() {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			utils.WriteJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"status":    health.StatusShuttingDown,
+				"timestamp": time.Now().Format(time.RFC3339),
+			})
+			return
+		}
+
 		// HEAD isteğinde body yazma, sadece 200 dön
 		if r.Method == http.MethodHead {
 			w.WriteHeader(http.StatusOK)
@@ -522,30 +891,43 @@ func getHealthHandler(database *sql.DB) http.HandlerFunc {
 			"status":    "healthy",
 			"timestamp": time.Now().Format(time.RFC3339),
 		}
+		statusCode := http.StatusOK
+
+		// Readiness: DB bağlantısı (cache'lenmez)
+		pingCtx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := database.PingContext(pingCtx); err != nil {
+			response["status"] = "unhealthy"
+			response["database"] = "down"
+			statusCode = http.StatusServiceUnavailable
+		} else {
+			response["database"] = "up"
+		}
 
 		// Migration status ekle
-		migrationStatus := getMigrationStatus(database)
+		migrationStatus := getMigrationStatus(migrationStatusCache)
 		if migrationStatus != nil {
 			response["migration"] = migrationStatus
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
+		utils.WriteJSON(w, statusCode, response)
 	}
 }
 
-// getMigrationStatus migration durumunu döner
-func getMigrationStatus(database *sql.DB) map[string]interface{} {
+// newMigrationStatusCache health check için migration status cache'i oluşturur
+func newMigrationStatusCache(database *sql.DB, ttl time.Duration) *migration.StatusCache {
 	// Migration runner oluştur (lightweight config)
 	config := migration.DefaultConfig()
 	config.Verbose = false
+	config.MaxOpenConns = 0 // Sadece okuma: uygulamanın pool limitine dokunma
 
-	runner := migration.NewRunner(database, config)
-	defer runner.Close()
+	return migration.NewStatusCache(ttl, migration.RunnerStatusFetcher(database, config))
+}
 
-	// Status al
-	status, err := runner.GetStatus()
+// getMigrationStatus migration durumunu döner
+func getMigrationStatus(migrationStatusCache *migration.StatusCache) map[string]interface{} {
+	// Status al (TTL içinde cache'ten)
+	status, err := migrationStatusCache.Get()
 	if err != nil {
 		return map[string]interface{}{
 			"status": "error",
@@ -32,7 +32,7 @@ func main() {
 	cfg := config.LoadConfig()
 
 	// Database bağlantısı
-	database, err := db.Connect(cfg.GetDSN())
+	database, err := db.Connect(cfg.GetDSN(), false)
 	if err != nil {
 		fmt.Printf("Database connection failed: %v\n", err)
 		os.Exit(1)
@@ -41,6 +41,7 @@ func main() {
 
 	// Migration runner oluştur (CLI config ile)
 	runner := migration.NewRunner(database, migration.CLIConfig())
+	defer runner.Close()
 
 	// Komut çalıştır
 	switch command {
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TokenDenylist logout ile iptal edilen token'ları jti bazında, token'ın kendi süresi dolana kadar tutar.
+// Süresi dolan kayıtlar periyodik olarak temizlenir (süresi dolmuş token zaten doğrulamadan geçemez).
+type TokenDenylist struct {
+	mutex   sync.RWMutex
+	entries map[string]time.Time // jti → token'ın expiry zamanı
+}
+
+// NewTokenDenylist boş denylist oluşturur; cleanupInterval > 0 ise temizlik goroutine'i başlatılır
+func NewTokenDenylist(cleanupInterval time.Duration) *TokenDenylist {
+	denylist := &TokenDenylist{
+		entries: make(map[string]time.Time),
+	}
+
+	if cleanupInterval > 0 {
+		go denylist.cleanupLoop(cleanupInterval)
+	}
+
+	return denylist
+}
+
+// Add token'ı expiresAt zamanına kadar iptal edilmiş olarak işaretler
+func (d *TokenDenylist) Add(jti string, expiresAt time.Time) {
+	if jti == "" {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.entries[jti] = expiresAt
+}
+
+// Contains token'ın iptal edilmiş olup olmadığını döner (süresi dolmuş kayıtlar sayılmaz)
+func (d *TokenDenylist) Contains(jti string) bool {
+	if jti == "" {
+		return false
+	}
+
+	d.mutex.RLock()
+	expiresAt, exists := d.entries[jti]
+	d.mutex.RUnlock()
+
+	return exists && time.Now().Before(expiresAt)
+}
+
+// Len denylist'teki kayıt sayısını döner
+func (d *TokenDenylist) Len() int {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return len(d.entries)
+}
+
+// cleanup süresi dolmuş kayıtları siler
+func (d *TokenDenylist) cleanup() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	for jti, expiresAt := range d.entries {
+		if !now.Before(expiresAt) {
+			delete(d.entries, jti)
+		}
+	}
+}
+
+// cleanupLoop belirli aralıklarla süresi dolmuş kayıtları temizler
+func (d *TokenDenylist) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.cleanup()
+		log.Debug().Int("denylisted_tokens", d.Len()).Msg("Token denylist cleanup completed")
+	}
+}
+
+// tokenDenylist logout ile iptal edilen token'ların süreç genelindeki listesi
+var tokenDenylist = NewTokenDenylist(10 * time.Minute)
+
+// RevokeToken token'ı (jti) kendi süresi dolana kadar geçersiz kılar (logout)
+func RevokeToken(claims *Claims) {
+	if claims == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return
+	}
+	tokenDenylist.Add(claims.ID, claims.ExpiresAt.Time)
+}
+
+// isTokenDenylisted token'ın logout ile iptal edilip edilmediğini kontrol eder
+func isTokenDenylisted(claims *Claims) bool {
+	return tokenDenylist.Contains(claims.ID)
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenDenylist_CleanupRemovesExpired, süresi dolmuş kayıtların temizlendiğini, geçerlilerin kaldığını test eder.
+func TestTokenDenylist_CleanupRemovesExpired(t *testing.T) {
+	// Arrange
+	denylist := NewTokenDenylist(0)
+	denylist.Add("expired", time.Now().Add(-time.Minute))
+	denylist.Add("active", time.Now().Add(time.Hour))
+
+	// Act
+	denylist.cleanup()
+
+	// Assert
+	assert.Equal(t, 1, denylist.Len())
+	assert.True(t, denylist.Contains("active"))
+	assert.False(t, denylist.Contains("expired"))
+}
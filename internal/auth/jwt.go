@@ -1,46 +1,259 @@
 package auth
 
 import (
+	"crypto/rsa"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// MinSecretLength HS256 imza anahtarı için kabul edilen minimum uzunluk (byte)
+const MinSecretLength = 32
+
+// MinRSAKeyBits RS256 anahtarları için kabul edilen minimum modulus uzunluğu (bit)
+const MinRSAKeyBits = 2048
+
+// Desteklenen imza algoritmaları (JWT_ALG)
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+var (
+	// ErrSecretNotConfigured Configure çağrılmadan token üretilmek/doğrulanmak istendiğinde döner
+	ErrSecretNotConfigured = errors.New("JWT secret yapılandırılmamış")
+	// ErrSecretTooShort secret MinSecretLength'ten kısa olduğunda döner
+	ErrSecretTooShort = fmt.Errorf("JWT secret en az %d byte olmalı", MinSecretLength)
+	// ErrRSAKeyTooShort RSA anahtarı MinRSAKeyBits'ten kısa olduğunda döner
+	ErrRSAKeyTooShort = fmt.Errorf("RSA anahtarı en az %d bit olmalı", MinRSAKeyBits)
+	// ErrRSAKeyMismatch private ve public key aynı anahtar çiftine ait olmadığında döner
+	ErrRSAKeyMismatch = errors.New("RSA private ve public key eşleşmiyor")
+	// ErrUnsupportedAlgorithm JWT_ALG desteklenmeyen bir değer olduğunda döner
+	ErrUnsupportedAlgorithm = errors.New("desteklenmeyen JWT algoritması")
+	// ErrAlgorithmMismatch token'ın alg header'ı yapılandırılmış algoritmadan farklı olduğunda döner (alg-confusion koruması)
+	ErrAlgorithmMismatch = errors.New("token algoritması yapılandırılmış algoritma ile uyuşmuyor")
+)
+
+// jwtAlg token imzalamada kullanılan ve doğrulamada kabul edilen tek algoritma
+var jwtAlg = AlgHS256
+
+// jwtSecret HS256 imza anahtarı, açılışta Configure ile config'ten (JWT_SECRET) set edilir
+var jwtSecret []byte
+
+// RS256 anahtarları, açılışta ConfigureRS256 ile PEM dosyalarından set edilir
+var (
+	rsaPrivateKey *rsa.PrivateKey
+	rsaPublicKey  *rsa.PublicKey
+)
+
+// Configure HS256 imza anahtarını ayarlar; boş veya MinSecretLength'ten kısa secret reddedilir
+func Configure(secret []byte) error {
+	if len(secret) == 0 {
+		return ErrSecretNotConfigured
+	}
+	if len(secret) < MinSecretLength {
+		return ErrSecretTooShort
+	}
+	jwtSecret = append([]byte(nil), secret...)
+	rsaPrivateKey, rsaPublicKey = nil, nil
+	jwtAlg = AlgHS256
+	return nil
+}
+
+// ConfigureRS256 RS256 için imza (private) ve doğrulama (public) anahtarlarını PEM'den yükler.
+// Sonrasında yalnızca RS256 token'lar kabul edilir; HS256 secret'ı ile imzalanmış token'lar reddedilir.
+func ConfigureRS256(privateKeyPEM, publicKeyPEM []byte) error {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("RSA private key okunamadı: %w", err)
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return fmt.Errorf("RSA public key okunamadı: %w", err)
+	}
+	if publicKey.N.BitLen() < MinRSAKeyBits {
+		return ErrRSAKeyTooShort
+	}
+	if !privateKey.PublicKey.Equal(publicKey) {
+		return ErrRSAKeyMismatch
+	}
+
+	rsaPrivateKey, rsaPublicKey = privateKey, publicKey
+	jwtSecret = nil
+	jwtAlg = AlgRS256
+	return nil
+}
+
+// Algorithm yapılandırılmış imza algoritmasını döner
+func Algorithm() string {
+	return jwtAlg
+}
+
+// signingMethod yapılandırılmış algoritmanın jwt signing method'unu döner
+func signingMethod() jwt.SigningMethod {
+	if jwtAlg == AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodHS256
+}
+
+// signingKey yapılandırılmış imza anahtarını döner (yapılandırılmamışsa hata)
+func signingKey() (interface{}, error) {
+	if jwtAlg == AlgRS256 {
+		if rsaPrivateKey == nil {
+			return nil, ErrSecretNotConfigured
+		}
+		return rsaPrivateKey, nil
+	}
+	if len(jwtSecret) == 0 {
+		return nil, ErrSecretNotConfigured
+	}
+	return jwtSecret, nil
+}
+
+// verificationKey yapılandırılmış doğrulama anahtarını döner (RS256'da public key)
+func verificationKey() (interface{}, error) {
+	if jwtAlg == AlgRS256 {
+		if rsaPublicKey == nil {
+			return nil, ErrSecretNotConfigured
+		}
+		return rsaPublicKey, nil
+	}
+	return signingKey()
+}
+
+// Token tipleri (token_type claim'i)
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+var (
+	// ErrNotAccessToken refresh token API isteğinde access token yerine kullanıldığında döner
+	ErrNotAccessToken = errors.New("refresh token API isteklerinde kullanılamaz")
+	// ErrNotRefreshToken refresh endpoint'ine refresh token yerine access token gönderildiğinde döner
+	ErrNotRefreshToken = errors.New("yenileme için refresh token gönderilmeli, access token kabul edilmez")
 )
 
-// JWT için secret key (production'da env'den okunmalı)
-var jwtSecret = []byte("your-secret-key-change-this-in-production")
+// ErrSessionExpired oturum mutlak ömrünü doldurdu; refresh yerine tekrar login gerekir
+var ErrSessionExpired = errors.New("oturum süresi doldu, tekrar giriş yapın")
+
+// accessTokenTTL üretilen access token'ların geçerlilik süresi
+var accessTokenTTL = 24 * time.Hour
+
+// SetAccessTokenTTL access token geçerlilik süresini ayarlar (0 veya negatifse değişiklik yapılmaz)
+func SetAccessTokenTTL(ttl time.Duration) {
+	if ttl > 0 {
+		accessTokenTTL = ttl
+	}
+}
+
+// refreshTokenTTL refresh token'ların geçerlilik süresi (mutlak oturum ömrü ayrıca uygulanır)
+var refreshTokenTTL = 7 * 24 * time.Hour
+
+// SetRefreshTokenTTL refresh token geçerlilik süresini ayarlar (0 veya negatifse değişiklik yapılmaz)
+func SetRefreshTokenTTL(ttl time.Duration) {
+	if ttl > 0 {
+		refreshTokenTTL = ttl
+	}
+}
+
+// AccessTokenTTL access token geçerlilik süresini döner
+func AccessTokenTTL() time.Duration {
+	return accessTokenTTL
+}
+
+// maxSessionAge ilk login'den itibaren refresh ile uzatılabilecek maksimum oturum süresi (0 = sınırsız)
+var maxSessionAge = 30 * 24 * time.Hour
+
+// SetMaxSessionAge oturumun mutlak ömrünü ayarlar (0 ise refresh süresiz yapılabilir)
+func SetMaxSessionAge(age time.Duration) {
+	maxSessionAge = age
+}
 
 // Claims JWT payload'ını temsil eder
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"` // RBAC için role eklendi
+	UserID     int              `json:"user_id"`
+	Email      string           `json:"email"`
+	Role       string           `json:"role"`                  // RBAC için role eklendi
+	AuthTime   *jwt.NumericDate `json:"auth_time,omitempty"`   // İlk login zamanı, refresh'te korunur
+	ClientType string           `json:"client_type,omitempty"` // Token'ı alan client (web/mobile/api), transaction kanalı için
+	TokenType  string           `json:"token_type,omitempty"`  // access | refresh (boş = eski access token)
 	jwt.RegisteredClaims
 }
 
-// GenerateToken kullanıcı için JWT token oluşturur
+// TokenPair login'de dönen access/refresh token çifti
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // Access token süresi (saniye)
+}
+
+// GenerateToken kullanıcı için access token oluşturur (login: auth_time şimdi olarak işaretlenir)
 func GenerateToken(userID int, email string, role string) (string, error) {
-	// Token 24 saat geçerli olacak
-	expirationTime := time.Now().Add(24 * time.Hour)
+	return generateToken(userID, email, role, "", TokenTypeAccess, time.Now())
+}
+
+// GenerateTokenPair login için access token ve daha uzun ömürlü refresh token üretir (ikisi aynı auth_time'ı taşır)
+func GenerateTokenPair(userID int, email string, role string) (*TokenPair, error) {
+	authTime := time.Now()
+
+	accessToken, err := generateToken(userID, email, role, "", TokenTypeAccess, authTime)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := generateToken(userID, email, role, "", TokenTypeRefresh, authTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenTTL / time.Second),
+	}, nil
+}
+
+// generateToken verilen tip, client tipi ve ilk login zamanı ile JWT token oluşturur
+func generateToken(userID int, email string, role string, clientType string, tokenType string, authTime time.Time) (string, error) {
+	// Token tipine göre yapılandırılmış süre boyunca geçerli olacak
+	ttl := accessTokenTTL
+	if tokenType == TokenTypeRefresh {
+		ttl = refreshTokenTTL
+	}
+	expirationTime := time.Now().Add(ttl)
 
 	// Claims oluştur
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role, // Role'u JWT'ye ekle
+		UserID:     userID,
+		Email:      email,
+		Role:       models.NormalizeRole(role), // Role'u JWT'ye kanonik biçimde ekle
+		AuthTime:   jwt.NewNumericDate(authTime),
+		ClientType: clientType,
+		TokenType:  tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(), // jti: logout'ta token bazlı iptal için
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
+	key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+
 	// Token oluştur
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(signingMethod(), claims)
 
 	// Token'ı imzala ve string'e çevir
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", fmt.Errorf("token oluşturulamadı: %w", err)
 	}
@@ -48,81 +261,131 @@ func GenerateToken(userID int, email string, role string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken JWT token'ını doğrular ve claims'i döner
-func ValidateToken(tokenString string) (*Claims, error) {
-	// Token'ı parse et
+// parseToken token'ın imzasını ve süresini doğrular, claims'i döner (tip ve iptal kontrolü yapmaz)
+func parseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Signing method kontrolü
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("beklenmeyen signing method: %v", token.Header["alg"])
+		// Signing method kontrolü: yalnızca yapılandırılmış algoritma kabul edilir (alg-confusion koruması)
+		if token.Method.Alg() != jwtAlg {
+			return nil, fmt.Errorf("%w: %v", ErrAlgorithmMismatch, token.Header["alg"])
 		}
-		return jwtSecret, nil
+		return verificationKey()
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("token parse edilemedi: %w", err)
 	}
 
-	// Claims'i al
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		// Eski veya dışarıda üretilmiş token'larda rol farklı yazılmış olabilir; tüketiciler hep kanonik biçimi görür
+		claims.Role = models.NormalizeRole(claims.Role)
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("geçersiz token")
 }
 
+// ValidateToken access token'ı doğrular ve claims'i döner; refresh token'lar API isteklerinde kabul edilmez
+func ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.IsRefreshToken() {
+		return nil, ErrNotAccessToken
+	}
+	if isTokenRevoked(claims) || isTokenDenylisted(claims) {
+		return nil, ErrTokenRevoked
+	}
+	if err := checkUserActive(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// RefreshToken geçerli bir refresh token ile yeni access token üretir.
+// Eski access token'ın süresinin dolması beklenmez; access token gönderilirse ErrNotRefreshToken döner.
 func RefreshToken(tokenString string) (string, int64, error) {
-	// Token'ı parse et
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("beklenmeyen signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		log.Warn().Err(err).Msg("Geçersiz refresh token ile refresh denendi")
+		return "", 0, fmt.Errorf("refresh token geçersiz: %w", err)
+	}
 
-	// Token geçerliyse refresh gerekmiyor
-	if err == nil && token.Valid {
-		log.Warn().Msg("Token refresh denendi ama token hala geçerli")
-		return "", 0, fmt.Errorf("token hala geçerli, refresh gerekmiyor")
+	// Token tipi karışıklığı: access token ile yeni token alınamaz
+	if !claims.IsRefreshToken() {
+		log.Warn().Int("user_id", claims.UserID).Str("token_type", claims.TokenType).Msg("Refresh endpoint'ine refresh olmayan token gönderildi")
+		return "", 0, ErrNotRefreshToken
 	}
 
-	// Token nil ise parse hatası
-	if token == nil {
-		log.Error().Err(err).Msg("Token parse edilemedi")
-		return "", 0, fmt.Errorf("token parse edilemedi: %w", err)
+	// İptal edilmiş token refresh edilemez (örn. kapatılmış hesap, logout)
+	if isTokenRevoked(claims) || isTokenDenylisted(claims) {
+		log.Warn().Int("user_id", claims.UserID).Msg("İptal edilmiş token ile refresh denendi")
+		return "", 0, ErrTokenRevoked
 	}
 
-	// JWT v5 hataları
-	if errors.Is(err, jwt.ErrTokenExpired) {
-		claims, ok := token.Claims.(*Claims)
-		if !ok {
-			log.Error().Msg("Token claims alınamadı")
-			return "", 0, fmt.Errorf("token claims alınamadı")
-		}
+	// Kapatılmış hesabın refresh token'ı yeni access token alamaz (restart/diğer instance'lar dahil)
+	if err := checkUserActive(claims); err != nil {
+		log.Warn().Err(err).Int("user_id", claims.UserID).Msg("Aktif olmayan kullanıcının token'ı ile refresh denendi")
+		return "", 0, err
+	}
 
-		// Yeni token oluştur (role'u da dahil et)
-		newToken, genErr := GenerateToken(claims.UserID, claims.Email, claims.Role)
-		if genErr != nil {
-			log.Error().Err(genErr).Msg("Yeni token oluşturulamadı")
-			return "", 0, fmt.Errorf("yeni token oluşturulamadı: %w", genErr)
-		}
+	// Mutlak oturum ömrü dolmuşsa aktiviteden bağımsız olarak tekrar login gerekir
+	authTime := claims.authTime()
+	if isSessionExpired(authTime) {
+		log.Warn().Int("user_id", claims.UserID).Time("auth_time", authTime).Msg("Oturum ömrü dolmuş token ile refresh denendi")
+		return "", 0, ErrSessionExpired
+	}
 
-		expiresIn := int64(24 * 60 * 60) // 24 saat
-		log.Info().Int("user_id", claims.UserID).Str("role", claims.Role).Msg("Token başarıyla refresh edildi")
-		return newToken, expiresIn, nil
+	// Yeni access token oluştur (role, client tipi ve ilk login zamanı korunur)
+	newToken, err := generateToken(claims.UserID, claims.Email, claims.Role, claims.ClientType, TokenTypeAccess, authTime)
+	if err != nil {
+		log.Error().Err(err).Msg("Yeni token oluşturulamadı")
+		return "", 0, fmt.Errorf("yeni token oluşturulamadı: %w", err)
 	}
 
-	if errors.Is(err, jwt.ErrTokenMalformed) {
-		log.Warn().Msg("Malformed token ile refresh denendi")
-		return "", 0, fmt.Errorf("token malformed")
+	expiresIn := int64(accessTokenTTL / time.Second)
+	log.Info().Int("user_id", claims.UserID).Str("role", claims.Role).Msg("Token başarıyla refresh edildi")
+	return newToken, expiresIn, nil
+}
+
+// RevokeRefreshToken logout'ta kullanıcının refresh token'ını da denylist'e ekler (başka kullanıcının token'ı iptal edilemez)
+func RevokeRefreshToken(tokenString string, userID int) error {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return fmt.Errorf("refresh token geçersiz: %w", err)
 	}
+	if !claims.IsRefreshToken() {
+		return ErrNotRefreshToken
+	}
+	if claims.UserID != userID {
+		return fmt.Errorf("refresh token bu kullanıcıya ait değil")
+	}
+
+	RevokeToken(claims)
+	return nil
+}
+
+// IsRefreshToken token'ın refresh token olup olmadığını döner (token_type'sız eski token'lar access sayılır)
+func (c *Claims) IsRefreshToken() bool {
+	return c.TokenType == TokenTypeRefresh
+}
 
-	if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
-		log.Warn().Msg("Invalid signature ile refresh denendi")
-		return "", 0, fmt.Errorf("token signature invalid")
+// authTime token'ın ilk login zamanını döner; auth_time'sız eski token'larda IssuedAt kullanılır
+func (c *Claims) authTime() time.Time {
+	if c.AuthTime != nil {
+		return c.AuthTime.Time
 	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.Time
+	}
+	return time.Time{}
+}
 
-	// Diğer her şey
-	log.Error().Err(err).Msg("Token refresh başarısız")
-	return "", 0, fmt.Errorf("token refresh edilemedi: %w", err)
+// isSessionExpired ilk login'den bu yana maksimum oturum süresinin geçip geçmediğini kontrol eder
+func isSessionExpired(authTime time.Time) bool {
+	if maxSessionAge <= 0 {
+		return false
+	}
+	return time.Since(authTime) > maxSessionAge
 }
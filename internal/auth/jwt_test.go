@@ -0,0 +1,458 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signRefreshToken verilen ilk login zamanına sahip, geçerli süreli imzalı refresh token üretir
+func signRefreshToken(t *testing.T, userID int, authTime time.Time) string {
+	t.Helper()
+
+	claims := &Claims{
+		UserID:    userID,
+		Email:     "test@example.com",
+		Role:      "user",
+		AuthTime:  jwt.NewNumericDate(authTime),
+		TokenType: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-25 * time.Hour)),
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	require.NoError(t, err)
+	return tokenString
+}
+
+// testSecret testlerde kullanılan imza anahtarı
+const testSecret = "auth-test-secret-0123456789abcdefghij"
+
+// restoreSigningConfig test sonunda imza algoritmasını ve anahtarları eski haline döndürür
+func restoreSigningConfig(t *testing.T) {
+	t.Helper()
+
+	previousAlg, previousSecret := jwtAlg, jwtSecret
+	previousPrivate, previousPublic := rsaPrivateKey, rsaPublicKey
+	t.Cleanup(func() {
+		jwtAlg, jwtSecret = previousAlg, previousSecret
+		rsaPrivateKey, rsaPublicKey = previousPrivate, previousPublic
+	})
+}
+
+// withSecret test süresince JWT imza anahtarını değiştirir
+func withSecret(t *testing.T, secret string) {
+	t.Helper()
+
+	restoreSigningConfig(t)
+	require.NoError(t, Configure([]byte(secret)))
+}
+
+// generateRSAKeyPEMs test için RSA anahtar çifti üretir ve PEM olarak döner
+func generateRSAKeyPEMs(t *testing.T, bits int) ([]byte, []byte) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return privatePEM, publicPEM
+}
+
+// withRS256 test süresince RS256 imzalamayı yeni bir anahtar çifti ile açar ve public key PEM'ini döner
+func withRS256(t *testing.T) []byte {
+	t.Helper()
+
+	privatePEM, publicPEM := generateRSAKeyPEMs(t, MinRSAKeyBits)
+	restoreSigningConfig(t)
+	require.NoError(t, ConfigureRS256(privatePEM, publicPEM))
+	return publicPEM
+}
+
+// withMaxSessionAge test süresince mutlak oturum ömrünü değiştirir
+func withMaxSessionAge(t *testing.T, age time.Duration) {
+	t.Helper()
+
+	previous := maxSessionAge
+	SetMaxSessionAge(age)
+	t.Cleanup(func() { SetMaxSessionAge(previous) })
+}
+
+// TestRefreshToken_WithinAbsoluteSessionAge, oturum ömrü içindeki token'ın yenilendiğini ve auth_time'ın korunduğunu test eder.
+func TestRefreshToken_WithinAbsoluteSessionAge(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	withMaxSessionAge(t, 30*24*time.Hour)
+	authTime := time.Now().Add(-10 * 24 * time.Hour).Truncate(time.Second)
+	tokenString := signRefreshToken(t, 10, authTime)
+
+	// Act
+	newToken, expiresIn, err := RefreshToken(tokenString)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(24*60*60), expiresIn)
+
+	claims, err := ValidateToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, 10, claims.UserID)
+	require.NotNil(t, claims.AuthTime)
+	assert.True(t, claims.AuthTime.Time.Equal(authTime))
+}
+
+// TestRefreshToken_PastAbsoluteSessionAge, oturum ömrü dolmuş token'ın aktiviteden bağımsız olarak yenilenmediğini test eder.
+func TestRefreshToken_PastAbsoluteSessionAge(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	withMaxSessionAge(t, 30*24*time.Hour)
+	tokenString := signRefreshToken(t, 10, time.Now().Add(-31*24*time.Hour))
+
+	// Act
+	newToken, _, err := RefreshToken(tokenString)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrSessionExpired)
+	assert.Empty(t, newToken)
+}
+
+// TestRefreshToken_ZeroSessionAgeDisablesLimit, sıfır oturum ömründe eski login'lerin de yenilenebildiğini test eder.
+func TestRefreshToken_ZeroSessionAgeDisablesLimit(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	withMaxSessionAge(t, 0)
+	tokenString := signRefreshToken(t, 10, time.Now().Add(-365*24*time.Hour))
+
+	// Act
+	newToken, _, err := RefreshToken(tokenString)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, newToken)
+}
+
+// TestGenerateToken_SetsAuthTime, login'de üretilen token'a auth_time yazıldığını test eder.
+func TestGenerateToken_SetsAuthTime(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+
+	// Act
+	tokenString, err := GenerateToken(10, "test@example.com", "user")
+	require.NoError(t, err)
+	claims, err := ValidateToken(tokenString)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, claims.AuthTime)
+	assert.WithinDuration(t, time.Now(), claims.AuthTime.Time, 2*time.Second)
+}
+
+// TestValidateToken_RejectsTokenFromOtherSecret, bir secret ile üretilen token'ın başka secret altında doğrulanamadığını test eder.
+func TestValidateToken_RejectsTokenFromOtherSecret(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	tokenString, err := GenerateToken(10, "test@example.com", "user")
+	require.NoError(t, err)
+	withSecret(t, "another-auth-test-secret-0123456789abc")
+
+	// Act
+	claims, err := ValidateToken(tokenString)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+// TestConfigure_RejectsWeakSecret, boş ve 32 byte'tan kısa secret'ların reddedildiğini test eder.
+func TestConfigure_RejectsWeakSecret(t *testing.T) {
+	// Act
+	emptyErr := Configure(nil)
+	shortErr := Configure([]byte("kisa-secret"))
+
+	// Assert
+	assert.ErrorIs(t, emptyErr, ErrSecretNotConfigured)
+	assert.ErrorIs(t, shortErr, ErrSecretTooShort)
+}
+
+// TestGenerateToken_FailsWithoutSecret, secret yapılandırılmadan token üretilemediğini test eder.
+func TestGenerateToken_FailsWithoutSecret(t *testing.T) {
+	// Arrange
+	previous := jwtSecret
+	jwtSecret = nil
+	t.Cleanup(func() { jwtSecret = previous })
+
+	// Act
+	tokenString, err := GenerateToken(10, "test@example.com", "user")
+
+	// Assert
+	assert.Empty(t, tokenString)
+	assert.ErrorIs(t, err, ErrSecretNotConfigured)
+}
+
+// TestRefreshToken_PreservesRole, role claim'inin generate→validate→refresh döngüsünde korunduğunu test eder.
+func TestRefreshToken_PreservesRole(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	tokens, err := GenerateTokenPair(10, "admin@example.com", "admin")
+	require.NoError(t, err)
+	claims, err := ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+
+	// Act
+	newToken, _, err := RefreshToken(tokens.RefreshToken)
+
+	// Assert
+	require.NoError(t, err)
+	refreshedClaims, err := ValidateToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Role)
+	assert.Equal(t, "admin", refreshedClaims.Role)
+	assert.Equal(t, 10, refreshedClaims.UserID)
+}
+
+// withAccessTokenTTL test süresince access token geçerlilik süresini değiştirir
+func withAccessTokenTTL(t *testing.T, ttl time.Duration) {
+	t.Helper()
+
+	previous := accessTokenTTL
+	SetAccessTokenTTL(ttl)
+	t.Cleanup(func() { accessTokenTTL = previous })
+}
+
+// TestRefreshToken_ShortAccessTokenTTL, dakika altı TTL ile üretilen token'ın gerçekten dolduğunu ve refresh'in aynı TTL'i raporladığını test eder.
+func TestRefreshToken_ShortAccessTokenTTL(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	withMaxSessionAge(t, time.Hour)
+	withAccessTokenTTL(t, time.Second)
+	tokens, err := GenerateTokenPair(10, "test@example.com", "user")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tokens.ExpiresIn)
+
+	// Act
+	require.Eventually(t, func() bool {
+		_, validateErr := ValidateToken(tokens.AccessToken)
+		return errors.Is(validateErr, jwt.ErrTokenExpired)
+	}, 3*time.Second, 50*time.Millisecond)
+	newToken, expiresIn, err := RefreshToken(tokens.RefreshToken)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expiresIn)
+	claims, err := ValidateToken(newToken)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Second), claims.ExpiresAt.Time, time.Second)
+}
+
+// TestRefreshToken_WhileAccessTokenStillValid, access token henüz geçerliyken refresh token ile yeni access token alınabildiğini test eder.
+func TestRefreshToken_WhileAccessTokenStillValid(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	tokens, err := GenerateTokenPair(10, "test@example.com", "user")
+	require.NoError(t, err)
+	_, err = ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+
+	// Act
+	newToken, _, err := RefreshToken(tokens.RefreshToken)
+
+	// Assert
+	require.NoError(t, err)
+	claims, err := ValidateToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, TokenTypeAccess, claims.TokenType)
+}
+
+// TestRefreshToken_RejectsAccessToken, refresh endpoint'ine gönderilen access token'ın (eski tip token dahil) reddedildiğini test eder.
+func TestRefreshToken_RejectsAccessToken(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	tokens, err := GenerateTokenPair(10, "test@example.com", "user")
+	require.NoError(t, err)
+
+	// token_type claim'i olmayan eski access token
+	legacyClaims := &Claims{
+		UserID: 10,
+		Email:  "test@example.com",
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+	}
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, legacyClaims).SignedString(jwtSecret)
+	require.NoError(t, err)
+
+	// Act
+	accessResult, _, accessErr := RefreshToken(tokens.AccessToken)
+	legacyResult, _, legacyErr := RefreshToken(legacyToken)
+
+	// Assert
+	assert.Empty(t, accessResult)
+	assert.ErrorIs(t, accessErr, ErrNotRefreshToken)
+	assert.Empty(t, legacyResult)
+	assert.Error(t, legacyErr)
+}
+
+// TestValidateToken_RejectsRefreshToken, refresh token'ın API isteklerinde access token yerine kullanılamadığını test eder.
+func TestValidateToken_RejectsRefreshToken(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	tokens, err := GenerateTokenPair(10, "test@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	claims, err := ValidateToken(tokens.RefreshToken)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.ErrorIs(t, err, ErrNotAccessToken)
+}
+
+// TestRefreshToken_ForgedTypeWithOtherSecret, başka secret ile imzalanmış "refresh" tipli token'ın kabul edilmediğini test eder.
+func TestRefreshToken_ForgedTypeWithOtherSecret(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	forged := &Claims{
+		UserID:    10,
+		Role:      "admin",
+		TokenType: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	forgedToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, forged).SignedString([]byte("attacker-secret-0123456789abcdefghij"))
+	require.NoError(t, err)
+
+	// Act
+	newToken, _, err := RefreshToken(forgedToken)
+
+	// Assert
+	assert.Empty(t, newToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+// TestConfigureRS256_SignAndVerify, RS256 ile üretilen token'ların public key ile doğrulanıp refresh edilebildiğini test eder.
+func TestConfigureRS256_SignAndVerify(t *testing.T) {
+	// Arrange
+	publicPEM := withRS256(t)
+	tokens, err := GenerateTokenPair(10, "test@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	claims, err := ValidateToken(tokens.AccessToken)
+	newToken, _, refreshErr := RefreshToken(tokens.RefreshToken)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 10, claims.UserID)
+	require.NoError(t, refreshErr)
+	assert.NotEmpty(t, newToken)
+
+	// Downstream servis yalnızca public key ile doğrulayabilir
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+	require.NoError(t, err)
+	parsed, err := jwt.ParseWithClaims(tokens.AccessToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}, jwt.WithValidMethods([]string{AlgRS256}))
+	require.NoError(t, err)
+	assert.Equal(t, AlgRS256, parsed.Header["alg"])
+}
+
+// TestValidateToken_RejectsHS256WhenRS256Configured, RS256 yapılandırılmışken HS256 token'ların (public key'i secret olarak kullanan alg-confusion dahil) reddedildiğini test eder.
+func TestValidateToken_RejectsHS256WhenRS256Configured(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	hsToken, err := GenerateToken(10, "test@example.com", "admin")
+	require.NoError(t, err)
+
+	publicPEM := withRS256(t)
+	confusionClaims := &Claims{
+		UserID:    10,
+		Role:      "admin",
+		TokenType: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	confusionToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, confusionClaims).SignedString(publicPEM)
+	require.NoError(t, err)
+
+	// Act
+	hsClaims, hsErr := ValidateToken(hsToken)
+	confusionResult, confusionErr := ValidateToken(confusionToken)
+
+	// Assert
+	assert.Nil(t, hsClaims)
+	assert.ErrorIs(t, hsErr, ErrAlgorithmMismatch)
+	assert.Nil(t, confusionResult)
+	assert.ErrorIs(t, confusionErr, ErrAlgorithmMismatch)
+}
+
+// TestConfigureRS256_RejectsInvalidKeys, eşleşmeyen anahtar çiftinin ve kısa RSA anahtarının reddedildiğini test eder.
+func TestConfigureRS256_RejectsInvalidKeys(t *testing.T) {
+	// Arrange
+	restoreSigningConfig(t)
+	privatePEM, _ := generateRSAKeyPEMs(t, MinRSAKeyBits)
+	_, otherPublicPEM := generateRSAKeyPEMs(t, MinRSAKeyBits)
+	shortPrivatePEM, shortPublicPEM := generateRSAKeyPEMs(t, 1024)
+
+	// Act
+	mismatchErr := ConfigureRS256(privatePEM, otherPublicPEM)
+	shortErr := ConfigureRS256(shortPrivatePEM, shortPublicPEM)
+
+	// Assert
+	assert.ErrorIs(t, mismatchErr, ErrRSAKeyMismatch)
+	assert.ErrorIs(t, shortErr, ErrRSAKeyTooShort)
+}
+
+// withActiveUserChecker test süresince kullanıcı durumu kontrolünü değiştirir
+func withActiveUserChecker(t *testing.T, checker ActiveUserChecker) {
+	t.Helper()
+
+	previous := activeUserChecker
+	SetActiveUserChecker(checker)
+	t.Cleanup(func() { SetActiveUserChecker(previous) })
+}
+
+// TestRefreshToken_RejectsClosedAccount, süreç içi iptal kaydı olmasa da (restart / başka instance) kapatılmış hesabın
+// refresh token'ının yeni access token alamadığını ve access token'ının reddedildiğini test eder.
+func TestRefreshToken_RejectsClosedAccount(t *testing.T) {
+	// Arrange
+	withSecret(t, testSecret)
+	withMaxSessionAge(t, 30*24*time.Hour)
+	refreshToken := signRefreshToken(t, 41, time.Now().Add(-time.Hour))
+	accessToken, err := GenerateToken(41, "kapali@example.com", "user")
+	require.NoError(t, err)
+	activeToken, err := GenerateToken(42, "aktif@example.com", "user")
+	require.NoError(t, err)
+
+	withActiveUserChecker(t, func(userID int) (bool, error) {
+		return userID != 41, nil
+	})
+
+	// Act
+	newToken, _, refreshErr := RefreshToken(refreshToken)
+	_, accessErr := ValidateToken(accessToken)
+	_, activeErr := ValidateToken(activeToken)
+
+	// Assert
+	assert.Empty(t, newToken)
+	assert.ErrorIs(t, refreshErr, ErrTokenRevoked)
+	assert.ErrorIs(t, accessErr, ErrTokenRevoked)
+	assert.NoError(t, activeErr)
+}
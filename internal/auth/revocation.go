@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTokenRevoked token kullanıcı iptalinden önce üretildiği için geçersiz
+var ErrTokenRevoked = errors.New("token iptal edilmiş")
+
+// revokedUsers kullanıcı bazlı token iptal zamanları (bu andan önce üretilen token'lar geçersiz)
+var (
+	revokedUsers   = make(map[int]time.Time)
+	revokedUsersMu sync.RWMutex
+)
+
+// ActiveUserChecker kullanıcının hâlâ aktif (kapatılmamış/silinmemiş) olup olmadığını döner
+type ActiveUserChecker func(userID int) (bool, error)
+
+// activeUserChecker açılışta SetActiveUserChecker ile set edilir (nil = kontrol yapılmaz)
+var activeUserChecker ActiveUserChecker
+
+// SetActiveUserChecker token doğrulama ve refresh'te kullanılacak kullanıcı durumu kontrolünü ayarlar.
+// RevokeUserTokens process içidir; restart sonrası veya diğer instance'larda kapatılan hesabın token'larını bu kontrol reddeder.
+func SetActiveUserChecker(checker ActiveUserChecker) {
+	activeUserChecker = checker
+}
+
+// checkUserActive token sahibinin aktif olduğunu kontrol eder; kapatılmış hesapta ErrTokenRevoked döner
+func checkUserActive(claims *Claims) error {
+	if activeUserChecker == nil {
+		return nil
+	}
+
+	active, err := activeUserChecker(claims.UserID)
+	if err != nil {
+		return fmt.Errorf("kullanıcı durumu kontrol edilemedi: %w", err)
+	}
+	if !active {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// RevokeUserTokens kullanıcının şu ana kadar üretilmiş tüm token'larını geçersiz kılar
+func RevokeUserTokens(userID int) {
+	revokedUsersMu.Lock()
+	defer revokedUsersMu.Unlock()
+	revokedUsers[userID] = time.Now()
+}
+
+// isTokenRevoked token'ın kullanıcı iptalinden önce üretilip üretilmediğini kontrol eder
+func isTokenRevoked(claims *Claims) bool {
+	revokedUsersMu.RLock()
+	revokedAt, exists := revokedUsers[claims.UserID]
+	revokedUsersMu.RUnlock()
+
+	if !exists {
+		return false
+	}
+
+	// IssuedAt saniye hassasiyetinde; iptal ile aynı saniyede üretilen token da geçersiz sayılır
+	if claims.IssuedAt == nil {
+		return true
+	}
+	return !claims.IssuedAt.Time.After(revokedAt.Truncate(time.Second))
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config ortam yapılandırmalarını tutar
@@ -12,8 +15,192 @@ type Config struct {
 	DBHost string
 	DBPort string
 	DBUser string
-	DBPass string
+	DBPass string `secret:"true"`
 	DBName string
+
+	// Index kullanım sağlık kontrolü
+	IndexHealthCheckEnabled  bool
+	IndexHealthCheckInterval time.Duration
+	IndexHealthSeqScanRatio  float64
+
+	// balance_history arşivleme (saklama süresi 0 = arşivleme kapalı)
+	BalanceHistoryRetention       time.Duration
+	BalanceHistoryArchiveInterval time.Duration
+
+	// Periyodik bakiye snapshot'ı sıklığı (0 = kapalı); point-in-time bakiye en yakın snapshot'tan hesaplanır
+	BalanceSnapshotInterval time.Duration
+
+	// Bakiyelerin balance_history toplamıyla periyodik mutabakatı (sıklık 0 = kapalı); sapmalar alert olarak loglanır
+	BalanceReconciliationInterval  time.Duration
+	BalanceReconciliationBatchSize int
+
+	// SIGTERM sonrası readiness düşürülüp server kapatılmadan önce beklenen süre (LB deregistration için)
+	ShutdownDrainDelay time.Duration
+
+	// Açılışta users/transactions/balances kolonlarını model `db` tag'leriyle karşılaştır (uyumsuzlukta açılmaz)
+	SchemaValidationEnabled bool
+
+	// Health check'te migration status cache süresi (0 = cache yok)
+	HealthMigrationStatusTTL time.Duration
+
+	// Readiness degraded eşikleri: kuyruk doluluk oranı ve DB ping süresi (0 = kontrol kapalı)
+	ReadinessQueueSaturation    float64
+	ReadinessDBLatencyThreshold time.Duration
+
+	// JSON yanıtlarında Content-Type charset'i
+	JSONCharset string
+
+	// Geçmiş (history) endpoint'lerinde sort parametresi yoksa kullanılan yön (asc | desc)
+	HistoryDefaultSort string
+
+	// Transaction geçmişinde offset sayfalaması (kapalıysa sadece cursor ile sayfalanır)
+	HistoryOffsetPagination bool
+
+	// Admin toplu status güncellemesinde tek istekte kabul edilen en fazla transaction
+	BulkStatusMaxIDs int
+
+	// İstatistik toplamlarının yuvarlandığı ondalık basamak (0-6)
+	AmountPrecision int
+
+	// Zamanlanmış transferlerin taranma sıklığı ve tur başına alınan kayıt
+	ScheduledTransferPollInterval time.Duration
+	ScheduledTransferBatchSize    int
+
+	// Kullanıcı başına en fazla aktif zamanlanmış transfer (0 = sınırsız)
+	ScheduledTransferMaxActivePerUser int
+
+	// Günlük transaction export'u: local storage dizini (boş = kapalı), format (csv | jsonl) ve çalışma sıklığı
+	TransactionExportDir      string
+	TransactionExportFormat   string
+	TransactionExportInterval time.Duration
+
+	// Bakiyenin diğer para birimlerinde gösterimi: kur kaynağı (static | database),
+	// sabit kurlar ("USD/TRY:32.5,EUR/TRY:35.2") ve display_in ile istenebilecek birimler
+	ExchangeRateSource string
+	ExchangeRates      map[string]float64
+	DisplayCurrencies  []string
+
+	// Request ID formatı (uuid | ulid)
+	RequestIDFormat string
+
+	// Token hatalarında nedeni (expired_token, invalid_signature...) client'a bildir
+	AuthDetailedErrors bool
+
+	// JWT imza anahtarı (en az 32 byte, boşsa uygulama açılmaz)
+	JWTSecret string `secret:"true"`
+
+	// JWT imza algoritması (HS256 | RS256); RS256'da token'lar private key ile imzalanır, public key ile doğrulanır
+	JWTAlg            string
+	JWTPrivateKeyFile string
+	JWTPublicKeyFile  string
+
+	// Access ve refresh token geçerlilik süreleri
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// İlk login'den itibaren refresh ile uzatılabilecek maksimum oturum süresi (0 = sınırsız)
+	SessionMaxAge time.Duration
+
+	// Log ve metriklerde eşleşen route template'ini kullan
+	LogRouteTemplate bool
+
+	// Request başına DB sorgu sayısını say, logla ve metriklere ekle
+	DBQueryMetrics bool
+
+	// Rate limit engelleme sayaçlarını metrik snapshot'ına ekle
+	RateLimitMetrics bool
+
+	// Rate limit backend'i (memory | redis); redis birden fazla instance'ın limiti paylaşması içindir
+	RateLimitBackend string
+	RedisAddr        string
+	RedisPassword    string `secret:"true"`
+	RedisDB          int
+
+	// Bu süre boyunca istek gelmeyen endpoint'lerin metrikleri silinir (0 = budama kapalı)
+	MetricsEndpointTTL time.Duration
+
+	// Endpoint response time histogram bucket üst sınırları ("5ms,50ms,500ms,5s"; boş = varsayılan bucket'lar)
+	MetricsResponseTimeBuckets []time.Duration
+
+	// Deadlock/serialization hatalarında transaction tekrar sayısı
+	TxRetryMaxRetries int
+
+	// Transaction queue'da geçici hatayla başarısız job'ların tekrar ayarları ve saklanan dead-letter sayısı
+	TxQueueMaxRetries     int
+	TxQueueRetryBaseDelay time.Duration
+	TxQueueRetryMaxDelay  time.Duration
+	TxQueueDeadLetterSize int
+
+	// Transaction queue doluyken isteğin boş yer beklediği süre (0 = beklemeden 503)
+	TxQueueEnqueueTimeout time.Duration
+
+	// Response'a aşama sürelerini (validation, auth, db, handler) içeren Server-Timing header'ı ekle (yalnızca development)
+	ServerTimingEnabled bool
+
+	// X-Forwarded-Proto'suna güvenilen proxy'ler (virgülle ayrılmış IP/CIDR)
+	TrustedProxies []string
+
+	// Role bazlı günlük transaction sayısı limitleri ("user:50,mod:200"; boş = limitsiz)
+	DailyTxCountLimits map[string]int
+	// Günlük limitin uyarı verilen oranı (0.8 = %80'de X-Limit-Warning, 0 = kapalı)
+	DailySoftLimitRatio float64
+
+	// Idempotency-Key saklama süresi ve aynı key'li eşzamanlı isteğin bekleme süresi
+	IdempotencyKeyRetention   time.Duration
+	IdempotencyKeyWaitTimeout time.Duration
+
+	// Açıklamanın zorunlu olduğu transfer tutarı eşiği (0 = kapalı)
+	TransferDescriptionRequiredAbove float64
+
+	// Aynı alıcıya pencere içinde açıklamalı transfer limiti (0 = limitsiz)
+	MemoTransfersPerRecipient int
+	MemoTransferWindow        time.Duration
+
+	// Yeni kayıt olan hesapların transfer yapamadığı süre (0 = kapalı)
+	NewAccountTransferCooldown time.Duration
+
+	// Aynı kullanıcının ardışık iki transferi arasında geçmesi gereken süre (0 = kapalı)
+	MinTransferInterval time.Duration
+
+	// İşlem tipine göre tek işlem tutar aralığı ("transfer:1,debit:50000"; tanımsız tipte varsayılan geçerli)
+	TransactionMinAmounts map[string]float64
+	TransactionMaxAmounts map[string]float64
+	// Günlük giden tutar limiti (debit + giden transfer, 0 = limitsiz) ve role bazlı ezme ("mod:250000")
+	DailyTxAmountLimit  float64
+	DailyTxAmountLimits map[string]float64
+
+	// Fraud incelemesi eşikleri: tutar ve yeni alıcıya transfer (0 = kural kapalı)
+	FraudReviewAmount             float64
+	FraudReviewNewRecipientAmount float64
+
+	// Anomali incelemesi: son dönem ortalamasının katı, geçmiş penceresi ve minimum geçmiş (kat 0 = kural kapalı)
+	FraudReviewAnomalyMultiple   float64
+	FraudReviewAnomalyLookback   time.Duration
+	FraudReviewAnomalyMinHistory int
+
+	// IP başına eşzamanlı bağlantı limiti (0 = limitsiz; TCP peer'ı sayar, TRUSTED_PROXIES muaftır)
+	MaxConnsPerIP int
+
+	// Multipart form parse'ında bellekte tutulacak maksimum boyut (byte, 0 = validation varsayılanı)
+	FormMaxMemory int64
+
+	// Güvenlik ihlali yanıtında kategori (sql_injection, xss...) ve alan adını döndür
+	SecurityErrorDetails bool
+
+	// Ortam bazlı feature flag'ler ("fees,webhooks:false")
+	FeatureFlags []string
+
+	// Hesap numarası (IBAN benzeri) üretimi: ülke kodu ve banka kodu
+	AccountNumberCountryCode string
+	AccountNumberBankCode    string
+
+	// Kullanıcıların kendi kaydında seçebileceği roller (admin/mod olamaz)
+	RegisterableRoles []string
+
+	// İlk admin bootstrap'ı: admin yoksa açılışta bu bilgilerle oluşturulur (boş = kapalı)
+	AdminName     string
+	AdminEmail    string
+	AdminPassword string `secret:"true"`
 }
 
 // yardımcı fonksiyon: ortam değişkeni yoksa default değeri döner
@@ -25,6 +212,120 @@ func getEnv(key, defaultVal string) string {
 	return val
 }
 
+// getEnvBool ortam değişkenini bool olarak okur, parse edilemezse default döner
+func getEnvBool(key string, defaultVal bool) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// getEnvList virgülle ayrılmış ortam değişkenini liste olarak okur, boşsa default döner
+func getEnvList(key string, defaultVal []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal
+	}
+
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvIntMap "anahtar:sayı" çiftlerini virgülle ayrılmış ortam değişkeninden okur, geçersiz çiftleri atlar
+func getEnvIntMap(key string, defaultVal map[string]int) map[string]int {
+	items := getEnvList(key, nil)
+	if len(items) == 0 {
+		return defaultVal
+	}
+
+	result := make(map[string]int, len(items))
+	for _, item := range items {
+		name, rawVal, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		val, err := strconv.Atoi(strings.TrimSpace(rawVal))
+		if err != nil {
+			continue
+		}
+		result[strings.TrimSpace(name)] = val
+	}
+	return result
+}
+
+// getEnvFloatMap "anahtar:tutar" çiftlerini virgülle ayrılmış ortam değişkeninden okur, geçersiz çiftleri atlar
+func getEnvFloatMap(key string, defaultVal map[string]float64) map[string]float64 {
+	items := getEnvList(key, nil)
+	if len(items) == 0 {
+		return defaultVal
+	}
+
+	result := make(map[string]float64, len(items))
+	for _, item := range items {
+		name, rawVal, ok := strings.Cut(item, ":")
+		if !ok {
+			continue
+		}
+		val, err := strconv.ParseFloat(strings.TrimSpace(rawVal), 64)
+		if err != nil {
+			continue
+		}
+		result[strings.TrimSpace(name)] = val
+	}
+	return result
+}
+
+// getEnvInt ortam değişkenini int olarak okur, parse edilemezse default döner
+func getEnvInt(key string, defaultVal int) int {
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// getEnvFloat ortam değişkenini float64 olarak okur, parse edilemezse default döner
+func getEnvFloat(key string, defaultVal float64) float64 {
+	val, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// getEnvDuration ortam değişkenini duration olarak okur ("30s", "5m"), parse edilemezse default döner
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	val, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// getEnvDurationList virgülle ayrılmış ortam değişkenini duration listesi olarak okur; boşsa veya bir öğe parse edilemezse default döner
+func getEnvDurationList(key string, defaultVal []time.Duration) []time.Duration {
+	items := getEnvList(key, nil)
+	if len(items) == 0 {
+		return defaultVal
+	}
+
+	result := make([]time.Duration, 0, len(items))
+	for _, item := range items {
+		val, err := time.ParseDuration(item)
+		if err != nil {
+			return defaultVal
+		}
+		result = append(result, val)
+	}
+	return result
+}
+
 // LoadConfig tüm yapılandırmayı yükler
 func LoadConfig() *Config {
 	return &Config{
@@ -35,6 +336,122 @@ func LoadConfig() *Config {
 		DBUser: getEnv("DB_USER", "ilhan"),
 		DBPass: getEnv("DB_PASS", "password"),
 		DBName: getEnv("DB_NAME", "paymentdb"),
+
+		IndexHealthCheckEnabled:  getEnvBool("INDEX_HEALTH_CHECK_ENABLED", true),
+		IndexHealthCheckInterval: getEnvDuration("INDEX_HEALTH_CHECK_INTERVAL", time.Hour),
+		IndexHealthSeqScanRatio:  getEnvFloat("INDEX_HEALTH_SEQ_SCAN_RATIO", 0.5),
+
+		BalanceHistoryRetention:       getEnvDuration("BALANCE_HISTORY_RETENTION", 0),
+		BalanceHistoryArchiveInterval: getEnvDuration("BALANCE_HISTORY_ARCHIVE_INTERVAL", 24*time.Hour),
+
+		BalanceSnapshotInterval: getEnvDuration("BALANCE_SNAPSHOT_INTERVAL", time.Hour),
+
+		BalanceReconciliationInterval:  getEnvDuration("BALANCE_RECONCILIATION_INTERVAL", 24*time.Hour),
+		BalanceReconciliationBatchSize: getEnvInt("BALANCE_RECONCILIATION_BATCH_SIZE", 500),
+
+		SchemaValidationEnabled: getEnvBool("SCHEMA_VALIDATION_ENABLED", true),
+
+		ShutdownDrainDelay:       getEnvDuration("SHUTDOWN_DRAIN_DELAY", 0),
+		HealthMigrationStatusTTL: getEnvDuration("HEALTH_MIGRATION_STATUS_TTL", 10*time.Second),
+
+		ReadinessQueueSaturation:    getEnvFloat("READINESS_QUEUE_SATURATION", 0.8),
+		ReadinessDBLatencyThreshold: getEnvDuration("READINESS_DB_LATENCY_THRESHOLD", 500*time.Millisecond),
+
+		JSONCharset:        getEnv("JSON_CHARSET", "utf-8"),
+		HistoryDefaultSort: getEnv("HISTORY_DEFAULT_SORT", "desc"),
+		RequestIDFormat:    getEnv("REQUEST_ID_FORMAT", "uuid"),
+
+		HistoryOffsetPagination: getEnvBool("HISTORY_OFFSET_PAGINATION", true),
+
+		BulkStatusMaxIDs: getEnvInt("BULK_STATUS_MAX_IDS", 100),
+
+		AmountPrecision: getEnvInt("AMOUNT_PRECISION", 2),
+
+		ScheduledTransferPollInterval: getEnvDuration("SCHEDULED_TRANSFER_POLL_INTERVAL", 10*time.Second),
+		ScheduledTransferBatchSize:    getEnvInt("SCHEDULED_TRANSFER_BATCH_SIZE", 50),
+
+		ScheduledTransferMaxActivePerUser: getEnvInt("SCHEDULED_TRANSFER_MAX_ACTIVE_PER_USER", 100),
+
+		TransactionExportDir:      getEnv("TRANSACTION_EXPORT_DIR", ""),
+		TransactionExportFormat:   getEnv("TRANSACTION_EXPORT_FORMAT", "csv"),
+		TransactionExportInterval: getEnvDuration("TRANSACTION_EXPORT_INTERVAL", 24*time.Hour),
+
+		ExchangeRateSource: getEnv("EXCHANGE_RATE_SOURCE", "static"),
+		ExchangeRates:      getEnvFloatMap("EXCHANGE_RATES", nil),
+		DisplayCurrencies:  getEnvList("DISPLAY_CURRENCIES", []string{"USD", "EUR"}),
+
+		AuthDetailedErrors: getEnvBool("AUTH_DETAILED_ERRORS", true),
+		JWTSecret:          getEnv("JWT_SECRET", ""),
+		JWTAlg:             getEnv("JWT_ALG", "HS256"),
+		JWTPrivateKeyFile:  getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTPublicKeyFile:   getEnv("JWT_PUBLIC_KEY_FILE", ""),
+		AccessTokenTTL:     getEnvDuration("ACCESS_TOKEN_TTL", 24*time.Hour),
+		RefreshTokenTTL:    getEnvDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		SessionMaxAge:      getEnvDuration("SESSION_MAX_AGE", 30*24*time.Hour),
+		LogRouteTemplate:   getEnvBool("LOG_ROUTE_TEMPLATE", true),
+		DBQueryMetrics:     getEnvBool("DB_QUERY_METRICS", true),
+		RateLimitMetrics:   getEnvBool("RATE_LIMIT_METRICS", true),
+		MetricsEndpointTTL: getEnvDuration("METRICS_ENDPOINT_TTL", time.Hour),
+
+		MetricsResponseTimeBuckets: getEnvDurationList("METRICS_RESPONSE_TIME_BUCKETS", nil),
+
+		RateLimitBackend: getEnv("RATE_LIMIT_BACKEND", "memory"),
+		RedisAddr:        getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:    getEnv("REDIS_PASSWORD", ""),
+		RedisDB:          getEnvInt("REDIS_DB", 0),
+
+		TxRetryMaxRetries: getEnvInt("TX_RETRY_MAX_RETRIES", 3),
+
+		TxQueueMaxRetries:     getEnvInt("TX_QUEUE_MAX_RETRIES", 2),
+		TxQueueRetryBaseDelay: getEnvDuration("TX_QUEUE_RETRY_BASE_DELAY", 100*time.Millisecond),
+		TxQueueRetryMaxDelay:  getEnvDuration("TX_QUEUE_RETRY_MAX_DELAY", 2*time.Second),
+		TxQueueDeadLetterSize: getEnvInt("TX_QUEUE_DEAD_LETTER_SIZE", 100),
+
+		TxQueueEnqueueTimeout: getEnvDuration("TX_QUEUE_ENQUEUE_TIMEOUT", 100*time.Millisecond),
+
+		ServerTimingEnabled: getEnvBool("SERVER_TIMING_ENABLED", false),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
+
+		IdempotencyKeyRetention:   getEnvDuration("IDEMPOTENCY_KEY_RETENTION", 24*time.Hour),
+		IdempotencyKeyWaitTimeout: getEnvDuration("IDEMPOTENCY_KEY_WAIT_TIMEOUT", 30*time.Second),
+
+		DailyTxCountLimits:               getEnvIntMap("DAILY_TX_COUNT_LIMITS", nil),
+		DailySoftLimitRatio:              getEnvFloat("DAILY_SOFT_LIMIT_RATIO", 0.8),
+		TransferDescriptionRequiredAbove: getEnvFloat("TRANSFER_DESCRIPTION_REQUIRED_ABOVE", 0),
+		MemoTransfersPerRecipient:        getEnvInt("MEMO_TRANSFERS_PER_RECIPIENT", 0),
+		MemoTransferWindow:               getEnvDuration("MEMO_TRANSFER_WINDOW", time.Hour),
+		NewAccountTransferCooldown:       getEnvDuration("NEW_ACCOUNT_TRANSFER_COOLDOWN", 0),
+
+		MinTransferInterval: getEnvDuration("MIN_TRANSFER_INTERVAL", 0),
+
+		TransactionMinAmounts: getEnvFloatMap("TRANSACTION_MIN_AMOUNTS", nil),
+		TransactionMaxAmounts: getEnvFloatMap("TRANSACTION_MAX_AMOUNTS", nil),
+		DailyTxAmountLimit:    getEnvFloat("DAILY_TX_AMOUNT_LIMIT", 0),
+		DailyTxAmountLimits:   getEnvFloatMap("DAILY_TX_AMOUNT_LIMITS", nil),
+
+		FraudReviewAmount:             getEnvFloat("FRAUD_REVIEW_AMOUNT", 0),
+		FraudReviewNewRecipientAmount: getEnvFloat("FRAUD_REVIEW_NEW_RECIPIENT_AMOUNT", 0),
+		FraudReviewAnomalyMultiple:    getEnvFloat("FRAUD_REVIEW_ANOMALY_MULTIPLE", 0),
+		FraudReviewAnomalyLookback:    getEnvDuration("FRAUD_REVIEW_ANOMALY_LOOKBACK", 90*24*time.Hour),
+		FraudReviewAnomalyMinHistory:  getEnvInt("FRAUD_REVIEW_ANOMALY_MIN_HISTORY", 5),
+
+		MaxConnsPerIP: getEnvInt("MAX_CONNS_PER_IP", 0),
+
+		FormMaxMemory: int64(getEnvInt("FORM_MAX_MEMORY", 0)),
+
+		SecurityErrorDetails: getEnvBool("SECURITY_ERROR_DETAILS", true),
+
+		FeatureFlags: getEnvList("FEATURE_FLAGS", nil),
+
+		AccountNumberCountryCode: getEnv("ACCOUNT_NUMBER_COUNTRY_CODE", "TR"),
+		AccountNumberBankCode:    getEnv("ACCOUNT_NUMBER_BANK_CODE", "000990"),
+
+		RegisterableRoles: getEnvList("REGISTERABLE_ROLES", []string{"user"}),
+
+		AdminName:     getEnv("ADMIN_NAME", "System Admin"),
+		AdminEmail:    getEnv("ADMIN_EMAIL", ""),
+		AdminPassword: getEnv("ADMIN_PASSWORD", ""),
 	}
 }
 
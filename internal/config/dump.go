@@ -0,0 +1,37 @@
+package config
+
+import (
+	"reflect"
+)
+
+// redactedValue maskelenmiş secret alanların dump'taki değeri
+const redactedValue = "***REDACTED***"
+
+// Dump effective config'i alan adı -> değer map'i olarak döner
+// `secret:"true"` tag'li alanlar maskelenir (boşsa boş bırakılır, eksik secret görülebilsin diye)
+func (c *Config) Dump() map[string]interface{} {
+	dump := make(map[string]interface{})
+
+	val := reflect.ValueOf(c).Elem()
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := val.Field(i)
+		if field.Tag.Get("secret") == "true" {
+			if value.IsZero() {
+				dump[field.Name] = ""
+			} else {
+				dump[field.Name] = redactedValue
+			}
+			continue
+		}
+
+		dump[field.Name] = value.Interface()
+	}
+
+	return dump
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfigDump_RedactsSecrets, dump'ta secret alanların maskelendiğini ve diğer alanların göründüğünü test eder.
+func TestConfigDump_RedactsSecrets(t *testing.T) {
+	// Arrange
+	cfg := &Config{
+		AppEnv: "staging",
+		DBHost: "db.internal",
+		DBPass: "çok-gizli-parola",
+	}
+
+	// Act
+	dump := cfg.Dump()
+
+	// Assert
+	assert.Equal(t, redactedValue, dump["DBPass"])
+	assert.Equal(t, "staging", dump["AppEnv"])
+	assert.Equal(t, "db.internal", dump["DBHost"])
+	assert.NotContains(t, dump, "çok-gizli-parola")
+}
+
+// TestConfigDump_RedactsAdminPassword, bootstrap admin parolasının debug dump'ında maskelendiğini test eder.
+func TestConfigDump_RedactsAdminPassword(t *testing.T) {
+	// Arrange
+	cfg := &Config{
+		AdminEmail:    "admin@example.com",
+		AdminPassword: "ilk-admin-parolasi",
+	}
+
+	// Act
+	dump := cfg.Dump()
+
+	// Assert
+	assert.Equal(t, redactedValue, dump["AdminPassword"])
+	assert.Equal(t, "admin@example.com", dump["AdminEmail"])
+}
+
+// TestConfigDump_EmptySecretStaysEmpty, boş secret'ın boş göründüğünü (eksik ayar fark edilsin diye) test eder.
+func TestConfigDump_EmptySecretStaysEmpty(t *testing.T) {
+	// Act
+	dump := (&Config{}).Dump()
+
+	// Assert
+	assert.Equal(t, "", dump["DBPass"])
+}
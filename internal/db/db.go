@@ -4,17 +4,24 @@ import (
 	"database/sql"
 	"fmt"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 	"github.com/rs/zerolog/log"
 )
 
-// Connect veritabanına bağlantı açar
-func Connect(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", dsn)
+// Connect veritabanına bağlantı açar (countQueries true ise sorgular NewCountingConnector ile sayılır)
+func Connect(dsn string, countQueries bool) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("veritabanı açılırken hata: %w", err)
 	}
 
+	var db *sql.DB
+	if countQueries {
+		db = sql.OpenDB(NewCountingConnector(connector))
+	} else {
+		db = sql.OpenDB(connector)
+	}
+
 	// Bağlantıyı test et
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("veritabanına ping atılamadı: %w", err)
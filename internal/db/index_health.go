@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// TableScanStats bir tablonun sequential/index scan istatistikleri
+type TableScanStats struct {
+	TableName string
+	SeqScan   int64
+	IdxScan   int64
+}
+
+// SeqScanRatio toplam scan'ler içindeki sequential scan oranını döner
+func (s TableScanStats) SeqScanRatio() float64 {
+	total := s.SeqScan + s.IdxScan
+	if total == 0 {
+		return 0
+	}
+	return float64(s.SeqScan) / float64(total)
+}
+
+// TableStatsSource tablo scan istatistiklerini sağlayan kaynak (test için fake'lenebilir)
+type TableStatsSource interface {
+	GetTableScanStats(tables []string) ([]TableScanStats, error)
+}
+
+// IndexHealthConfig index kullanım kontrolü ayarları
+type IndexHealthConfig struct {
+	Tables           []string      // Kontrol edilecek sıcak tablolar
+	SeqScanThreshold float64       // Bu oranın üstündeki seq scan uyarı üretir (0-1)
+	MinTotalScans    int64         // Bu sayının altındaki tablolar değerlendirilmez (gürültü)
+	CheckInterval    time.Duration // Periyodik kontrol sıklığı
+}
+
+// DefaultIndexHealthConfig varsayılan index kontrol ayarları
+func DefaultIndexHealthConfig() *IndexHealthConfig {
+	return &IndexHealthConfig{
+		Tables:           []string{"transactions", "balances"},
+		SeqScanThreshold: 0.5,
+		MinTotalScans:    1000,
+		CheckInterval:    time.Hour,
+	}
+}
+
+// pgTableStatsSource pg_stat_user_tables üzerinden istatistik okur
+type pgTableStatsSource struct {
+	db *sql.DB
+}
+
+// NewPostgresTableStatsSource PostgreSQL istatistik kaynağı oluşturur
+func NewPostgresTableStatsSource(db *sql.DB) TableStatsSource {
+	return &pgTableStatsSource{db: db}
+}
+
+// GetTableScanStats verilen tabloların scan istatistiklerini getirir
+func (s *pgTableStatsSource) GetTableScanStats(tables []string) ([]TableScanStats, error) {
+	query := `
+		SELECT relname, COALESCE(seq_scan, 0), COALESCE(idx_scan, 0)
+		FROM pg_stat_user_tables
+		WHERE relname = ANY($1)
+	`
+
+	rows, err := s.db.Query(query, pq.Array(tables))
+	if err != nil {
+		return nil, fmt.Errorf("tablo istatistikleri alınamadı: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []TableScanStats
+	for rows.Next() {
+		var st TableScanStats
+		if err := rows.Scan(&st.TableName, &st.SeqScan, &st.IdxScan); err != nil {
+			return nil, fmt.Errorf("tablo istatistik scan hatası: %w", err)
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
+
+// CheckIndexUsage seq scan oranı yüksek tabloları loglar ve döner
+func CheckIndexUsage(source TableStatsSource, config *IndexHealthConfig) ([]TableScanStats, error) {
+	if config == nil {
+		config = DefaultIndexHealthConfig()
+	}
+
+	stats, err := source.GetTableScanStats(config.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	var flagged []TableScanStats
+	for _, st := range stats {
+		if st.SeqScan+st.IdxScan < config.MinTotalScans {
+			continue
+		}
+
+		ratio := st.SeqScanRatio()
+		if ratio > config.SeqScanThreshold {
+			flagged = append(flagged, st)
+			log.Warn().
+				Str("table", st.TableName).
+				Int64("seq_scan", st.SeqScan).
+				Int64("idx_scan", st.IdxScan).
+				Float64("seq_scan_ratio", ratio).
+				Float64("threshold", config.SeqScanThreshold).
+				Msg("Yüksek sequential scan oranı - beklenen index eksik olabilir")
+		}
+	}
+
+	if len(flagged) == 0 {
+		log.Debug().Int("checked_tables", len(stats)).Msg("Index kullanım kontrolü: sorun yok")
+	}
+
+	return flagged, nil
+}
+
+// StartIndexHealthMonitor index kontrolünü hemen ve periyodik olarak çalıştırır
+func StartIndexHealthMonitor(ctx context.Context, source TableStatsSource, config *IndexHealthConfig) {
+	if config == nil {
+		config = DefaultIndexHealthConfig()
+	}
+	interval := config.CheckInterval
+	if interval <= 0 {
+		interval = DefaultIndexHealthConfig().CheckInterval
+	}
+
+	go func() {
+		run := func() {
+			if _, err := CheckIndexUsage(source, config); err != nil {
+				log.Error().Err(err).Msg("Index kullanım kontrolü başarısız")
+			}
+		}
+
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Index health monitor stopped")
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
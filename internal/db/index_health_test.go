@@ -0,0 +1,101 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTableStatsSource sabit istatistik dönen sahte kaynak
+type fakeTableStatsSource struct {
+	stats []TableScanStats
+}
+
+func (f *fakeTableStatsSource) GetTableScanStats(tables []string) ([]TableScanStats, error) {
+	return f.stats, nil
+}
+
+// countingTableStatsSource her çağrıyı sayan ve bildiren sahte kaynak
+type countingTableStatsSource struct {
+	calls  atomic.Int32
+	called chan struct{}
+}
+
+func (c *countingTableStatsSource) GetTableScanStats(tables []string) ([]TableScanStats, error) {
+	c.calls.Add(1)
+	select {
+	case c.called <- struct{}{}:
+	default:
+	}
+	return nil, nil
+}
+
+// TestCheckIndexUsage_HighSeqScan_EmitsWarning, yüksek seq scan oranında uyarı loglandığını test eder.
+func TestCheckIndexUsage_HighSeqScan_EmitsWarning(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf)
+	defer func() { log.Logger = original }()
+
+	source := &fakeTableStatsSource{stats: []TableScanStats{
+		{TableName: "transactions", SeqScan: 9000, IdxScan: 1000},
+		{TableName: "balances", SeqScan: 100, IdxScan: 9900},
+	}}
+
+	// Act
+	flagged, err := CheckIndexUsage(source, DefaultIndexHealthConfig())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, flagged, 1)
+	assert.Equal(t, "transactions", flagged[0].TableName)
+	assert.Contains(t, buf.String(), `"level":"warn"`)
+	assert.Contains(t, buf.String(), `"table":"transactions"`)
+	assert.NotContains(t, buf.String(), `"table":"balances"`)
+}
+
+// TestCheckIndexUsage_LowTraffic_Ignored, az scan yapılan tabloların değerlendirilmediğini test eder.
+func TestCheckIndexUsage_LowTraffic_Ignored(t *testing.T) {
+	// Arrange
+	source := &fakeTableStatsSource{stats: []TableScanStats{
+		{TableName: "transactions", SeqScan: 50, IdxScan: 0},
+	}}
+
+	// Act
+	flagged, err := CheckIndexUsage(source, DefaultIndexHealthConfig())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, flagged)
+}
+
+// TestStartIndexHealthMonitor_ZeroInterval_UsesDefault, CheckInterval 0 iken monitor'ün panic'lemeden varsayılan
+// aralığa düştüğünü (ilk kontrolden sonra sıkı döngüye girmediğini) test eder.
+func TestStartIndexHealthMonitor_ZeroInterval_UsesDefault(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source := &countingTableStatsSource{called: make(chan struct{}, 1)}
+	config := DefaultIndexHealthConfig()
+	config.CheckInterval = 0
+
+	// Act
+	StartIndexHealthMonitor(ctx, source, config)
+
+	// Assert
+	select {
+	case <-source.called:
+	case <-time.After(time.Second):
+		t.Fatal("ilk index kontrolü çalışmadı")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), source.calls.Load())
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync/atomic"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// totalQueries uygulama genelinde sayılan toplam sorgu sayısı
+var totalQueries atomic.Int64
+
+// TotalQueries counting connector üzerinden çalıştırılan toplam sorgu sayısını döner
+func TotalQueries() int64 {
+	return totalQueries.Load()
+}
+
+// recordQuery sorguyu global sayaca, context'te varsa request sayacına ve süresini Server-Timing'e yazar
+func recordQuery(ctx context.Context, startedAt time.Time) {
+	totalQueries.Add(1)
+	if counter := utils.QueryCounterFromContext(ctx); counter != nil {
+		counter.Inc()
+	}
+	utils.RecordTiming(ctx, utils.TimingDB, time.Since(startedAt))
+}
+
+// NewCountingConnector her Query/Exec çağrısını sayan connector wrapper'ı döner.
+// Request sayacına yalnızca context ile çalıştırılan (QueryContext, ExecContext...) sorgular yazılır.
+func NewCountingConnector(connector driver.Connector) driver.Connector {
+	return &countingConnector{Connector: connector}
+}
+
+// countingConnector açılan bağlantıları countingConn ile sarar
+type countingConnector struct {
+	driver.Connector
+}
+
+// Connect alttaki bağlantıyı açar ve sarar
+func (c *countingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn}, nil
+}
+
+// countingConn sorguları sayan driver bağlantısı (opsiyonel arayüzler alttaki bağlantıya iletilir)
+type countingConn struct {
+	driver.Conn
+}
+
+// QueryContext sorguyu sayar ve alttaki bağlantıya iletir
+func (c *countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql prepare + stmt yoluna düşer, orada sayılır
+	}
+	startedAt := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		recordQuery(ctx, startedAt)
+	}
+	return rows, err
+}
+
+// ExecContext komutu sayar ve alttaki bağlantıya iletir
+func (c *countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	startedAt := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		recordQuery(ctx, startedAt)
+	}
+	return result, err
+}
+
+// PrepareContext statement'ı hazırlar ve çalıştırmaları sayılacak şekilde sarar
+func (c *countingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &countingStmt{Stmt: stmt}, nil
+}
+
+// BeginTx transaction'ı alttaki bağlantıda başlatır (BEGIN sorgu olarak sayılmaz)
+func (c *countingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() // ConnBeginTx desteklemeyen driver'lar için
+}
+
+// Ping alttaki bağlantı destekliyorsa ping atar
+func (c *countingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession alttaki bağlantı destekliyorsa oturumu sıfırlar
+func (c *countingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid alttaki bağlantı destekliyorsa geçerliliğini döner
+func (c *countingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// countingStmt prepared statement çalıştırmalarını sayar
+type countingStmt struct {
+	driver.Stmt
+}
+
+// QueryContext statement sorgusunu sayar ve çalıştırır
+func (s *countingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer recordQuery(ctx, time.Now())
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values) // StmtQueryContext desteklemeyen driver'lar için
+}
+
+// ExecContext statement komutunu sayar ve çalıştırır
+func (s *countingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer recordQuery(ctx, time.Now())
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values) // StmtExecContext desteklemeyen driver'lar için
+}
+
+// namedValuesToValues isimsiz parametreleri eski driver.Value listesine çevirir
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, driver.ErrSkip
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
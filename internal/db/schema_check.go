@@ -0,0 +1,134 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaSource tablo kolonlarını (kolon adı → data_type) sağlayan kaynak (test için fake'lenebilir)
+type SchemaSource interface {
+	GetTableColumns(table string) (map[string]string, error)
+}
+
+// pgSchemaSource information_schema.columns üzerinden kolonları okur
+type pgSchemaSource struct {
+	db *sql.DB
+}
+
+// NewPostgresSchemaSource PostgreSQL şema kaynağı oluşturur
+func NewPostgresSchemaSource(db *sql.DB) SchemaSource {
+	return &pgSchemaSource{db: db}
+}
+
+// GetTableColumns tablonun kolonlarını ve tiplerini getirir (tablo yoksa boş map)
+func (s *pgSchemaSource) GetTableColumns(table string) (map[string]string, error) {
+	query := `
+		SELECT column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1
+	`
+
+	rows, err := s.db.Query(query, table)
+	if err != nil {
+		return nil, fmt.Errorf("%s kolonları alınamadı: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return nil, fmt.Errorf("kolon scan hatası: %w", err)
+		}
+		columns[name] = dataType
+	}
+
+	return columns, rows.Err()
+}
+
+// compatibleDataTypes Go tipine karşılık kabul edilen Postgres data_type değerleri
+var compatibleDataTypes = map[reflect.Kind][]string{
+	reflect.Int:     {"integer", "bigint", "smallint"},
+	reflect.Int64:   {"integer", "bigint", "smallint"},
+	reflect.Float64: {"numeric", "double precision", "real"},
+	reflect.String:  {"character varying", "text", "character", "uuid"},
+	reflect.Bool:    {"boolean"},
+}
+
+// timeDataTypes time.Time alanlarıyla uyumlu data_type değerleri
+var timeDataTypes = []string{"timestamp without time zone", "timestamp with time zone", "date"}
+
+// ValidateSchema verilen tablo → model eşlemesindeki `db` tag'li alanların tabloda uyumlu tipte kolon olarak
+// bulunduğunu kontrol eder. Tüm uyumsuzluklar tek hatada toplanır (eksik migration'lı deploy'u açılışta yakalamak için).
+func ValidateSchema(source SchemaSource, models map[string]interface{}) error {
+	tables := make([]string, 0, len(models))
+	for table := range models {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var problems []string
+	for _, table := range tables {
+		columns, err := source.GetTableColumns(table)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			problems = append(problems, fmt.Sprintf("%s tablosu bulunamadı", table))
+			continue
+		}
+
+		modelType := reflect.TypeOf(models[table])
+		for modelType.Kind() == reflect.Ptr {
+			modelType = modelType.Elem()
+		}
+
+		for i := 0; i < modelType.NumField(); i++ {
+			field := modelType.Field(i)
+			column := field.Tag.Get("db")
+			if column == "" || column == "-" {
+				continue
+			}
+
+			dataType, ok := columns[column]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s kolonu eksik", table, column))
+				continue
+			}
+			if !isCompatibleDataType(field.Type, dataType) {
+				problems = append(problems, fmt.Sprintf("%s.%s kolonu %s, %s alanı ile uyumsuz", table, column, dataType, field.Type))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("veritabanı şeması modellerle uyuşmuyor (migration eksik olabilir): %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isCompatibleDataType Go alan tipinin Postgres kolon tipiyle scan edilebilir olup olmadığını döner (bilinmeyen tipler kabul edilir)
+func isCompatibleDataType(goType reflect.Type, dataType string) bool {
+	for goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+
+	allowed := compatibleDataTypes[goType.Kind()]
+	if goType == reflect.TypeOf(time.Time{}) {
+		allowed = timeDataTypes
+	}
+	if allowed == nil {
+		return true
+	}
+
+	for _, candidate := range allowed {
+		if candidate == dataType {
+			return true
+		}
+	}
+	return false
+}
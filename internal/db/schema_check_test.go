@@ -0,0 +1,88 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSchemaSource sabit kolon listesi dönen sahte şema kaynağı
+type fakeSchemaSource struct {
+	tables map[string]map[string]string
+}
+
+func (f *fakeSchemaSource) GetTableColumns(table string) (map[string]string, error) {
+	return f.tables[table], nil
+}
+
+// schemaTestModel şema kontrolü için örnek model
+type schemaTestModel struct {
+	ID        int       `db:"id"`
+	Amount    float64   `db:"amount"`
+	Channel   *string   `db:"channel"`
+	CreatedAt time.Time `db:"created_at"`
+	Computed  string    // db tag'i yok, kontrol edilmez
+}
+
+// TestValidateSchema_MatchingSchemaPasses, tüm kolonları uyumlu tipte olan şemanın kabul edildiğini test eder.
+func TestValidateSchema_MatchingSchemaPasses(t *testing.T) {
+	// Arrange
+	source := &fakeSchemaSource{tables: map[string]map[string]string{
+		"transactions": {
+			"id":         "integer",
+			"amount":     "numeric",
+			"channel":    "character varying",
+			"created_at": "timestamp without time zone",
+		},
+	}}
+
+	// Act
+	err := ValidateSchema(source, map[string]interface{}{"transactions": schemaTestModel{}})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+// TestValidateSchema_MissingColumnFails, modeldeki kolonu eksik olan şemanın açık bir mesajla reddedildiğini test eder.
+func TestValidateSchema_MissingColumnFails(t *testing.T) {
+	// Arrange
+	source := &fakeSchemaSource{tables: map[string]map[string]string{
+		"transactions": {
+			"id":         "integer",
+			"amount":     "numeric",
+			"created_at": "timestamp without time zone",
+		},
+	}}
+
+	// Act
+	err := ValidateSchema(source, map[string]interface{}{"transactions": &schemaTestModel{}})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "transactions.channel kolonu eksik")
+}
+
+// TestValidateSchema_IncompatibleTypeAndMissingTable, uyumsuz kolon tipinin ve eksik tablonun birlikte raporlandığını test eder.
+func TestValidateSchema_IncompatibleTypeAndMissingTable(t *testing.T) {
+	// Arrange
+	source := &fakeSchemaSource{tables: map[string]map[string]string{
+		"transactions": {
+			"id":         "integer",
+			"amount":     "text",
+			"channel":    "character varying",
+			"created_at": "timestamp without time zone",
+		},
+	}}
+
+	// Act
+	err := ValidateSchema(source, map[string]interface{}{
+		"transactions": schemaTestModel{},
+		"balances":     schemaTestModel{},
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "balances tablosu bulunamadı")
+	assert.Contains(t, err.Error(), "transactions.amount kolonu text, float64 alanı ile uyumsuz")
+}
@@ -2,8 +2,12 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math/rand"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
@@ -52,6 +56,87 @@ func WithTransaction(db *sql.DB, fn TransactionFunc) error {
 	return nil
 }
 
+// Tekrar denenebilir Postgres SQLSTATE kodları
+const (
+	SQLStateDeadlockDetected     = "40P01"
+	SQLStateSerializationFailure = "40001"
+)
+
+// SQLStateUniqueViolation unique constraint ihlali SQLSTATE kodu
+const SQLStateUniqueViolation = "23505"
+
+// IsUniqueViolation hatanın verilen constraint/index üzerinde unique ihlali olup olmadığını kontrol eder (constraint boşsa herhangi biri)
+func IsUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != SQLStateUniqueViolation {
+		return false
+	}
+	return constraint == "" || pqErr.Constraint == constraint
+}
+
+// RetryConfig deadlock/serialization hatalarında transaction tekrar ayarları
+type RetryConfig struct {
+	MaxRetries int           // İlk denemeden sonraki maksimum tekrar sayısı (0 = tekrar yok)
+	BaseDelay  time.Duration // İlk tekrar öncesi bekleme (her denemede ikiye katlanır)
+	MaxDelay   time.Duration // Bekleme üst sınırı
+}
+
+// DefaultRetryConfig varsayılan tekrar ayarları
+func DefaultRetryConfig() *RetryConfig {
+	return &RetryConfig{
+		MaxRetries: 3,
+		BaseDelay:  20 * time.Millisecond,
+		MaxDelay:   500 * time.Millisecond,
+	}
+}
+
+// IsRetryableError hatanın deadlock veya serialization failure olup olmadığını kontrol eder
+func IsRetryableError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == SQLStateDeadlockDetected || pqErr.Code == SQLStateSerializationFailure
+}
+
+// WithTransactionRetry WithTransaction'ı deadlock/serialization hatalarında jitter'lı backoff ile tekrar çalıştırır
+// fn her denemede baştan çalışır, bu yüzden transaction dışı state'i her denemede sıfırlamalıdır
+func WithTransactionRetry(db *sql.DB, config *RetryConfig, fn TransactionFunc) error {
+	// Config nil ise default kullan
+	if config == nil {
+		config = DefaultRetryConfig()
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = WithTransaction(db, fn)
+		if err == nil || !IsRetryableError(err) || attempt >= config.MaxRetries {
+			return err
+		}
+
+		delay := retryDelay(config, attempt)
+		log.Warn().
+			Err(err).
+			Int("attempt", attempt+1).
+			Int("max_retries", config.MaxRetries).
+			Dur("delay", delay).
+			Msg("Transaction çakışması, tekrar deneniyor")
+		time.Sleep(delay)
+	}
+}
+
+// retryDelay üstel backoff üzerine full jitter uygular
+func retryDelay(config *RetryConfig, attempt int) time.Duration {
+	delay := config.BaseDelay << attempt
+	if delay <= 0 || (config.MaxDelay > 0 && delay > config.MaxDelay) {
+		delay = config.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
 // TransactionRepository transaction içinde repository işlemleri için helper
 type TransactionRepository struct {
 	tx *sql.Tx
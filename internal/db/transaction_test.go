@@ -0,0 +1,66 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWithTransactionRetry_RetriesAfterDeadlock, ilk deneme deadlock aldığında tekrar denemenin başarılı olduğunu test eder.
+func TestWithTransactionRetry_RetriesAfterDeadlock(t *testing.T) {
+	// Arrange
+	database, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE balances").WillReturnError(&pq.Error{Code: SQLStateDeadlockDetected})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE balances").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	config := &RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	attempts := 0
+
+	// Act
+	err = WithTransactionRetry(database, config, func(tx *sql.Tx) error {
+		attempts++
+		_, err := tx.Exec("UPDATE balances SET amount = $1 WHERE user_id = $2", 10.0, 1)
+		return err
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestWithTransactionRetry_DoesNotRetryOtherErrors, deadlock dışı hatalarda tekrar denenmediğini test eder.
+func TestWithTransactionRetry_DoesNotRetryOtherErrors(t *testing.T) {
+	// Arrange
+	database, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	attempts := 0
+
+	// Act
+	err = WithTransactionRetry(database, DefaultRetryConfig(), func(tx *sql.Tx) error {
+		attempts++
+		return errors.New("yetersiz bakiye")
+	})
+
+	// Assert
+	assert.EqualError(t, err, "yetersiz bakiye")
+	assert.Equal(t, 1, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
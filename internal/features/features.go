@@ -0,0 +1,87 @@
+package features
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bilinen feature flag isimleri
+const (
+	Fees          = "fees"
+	Holds         = "holds"
+	MultiCurrency = "multi_currency"
+	Refunds       = "refunds"
+	Webhooks      = "webhooks"
+)
+
+// registry ortam bazlı açık/kapalı flag'ler (tanımsız flag kapalı kabul edilir)
+var registry = struct {
+	sync.RWMutex
+	flags map[string]bool
+}{
+	flags: make(map[string]bool),
+}
+
+// Enabled flag'in açık olup olmadığını döner
+func Enabled(name string) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.flags[strings.ToLower(name)]
+}
+
+// Set tüm flag'leri verilen map ile değiştirir
+func Set(flags map[string]bool) {
+	normalized := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		normalized[strings.ToLower(name)] = enabled
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	registry.flags = normalized
+}
+
+// SetEnabled tek bir flag'i açar/kapatır
+func SetEnabled(name string, enabled bool) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.flags[strings.ToLower(name)] = enabled
+}
+
+// All mevcut flag'lerin kopyasını döner (loglama ve testler için)
+func All() map[string]bool {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	flags := make(map[string]bool, len(registry.flags))
+	for name, enabled := range registry.flags {
+		flags[name] = enabled
+	}
+	return flags
+}
+
+// Parse "fees,webhooks:false,holds:true" formatındaki listeyi flag map'ine çevirir.
+// Değersiz isimler açık kabul edilir, parse edilemeyen değerler atlanır.
+func Parse(items []string) map[string]bool {
+	flags := make(map[string]bool, len(items))
+	for _, item := range items {
+		name, rawVal, hasVal := strings.Cut(item, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		if !hasVal {
+			flags[name] = true
+			continue
+		}
+
+		enabled, err := strconv.ParseBool(strings.TrimSpace(rawVal))
+		if err != nil {
+			continue
+		}
+		flags[name] = enabled
+	}
+	return flags
+}
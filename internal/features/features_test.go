@@ -0,0 +1,28 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse_BareNamesAndExplicitValues, değersiz isimlerin açık, açık değerlerin parse edildiğini test eder.
+func TestParse_BareNamesAndExplicitValues(t *testing.T) {
+	// Act
+	flags := Parse([]string{"Fees", "webhooks:false", "holds:true", "multi_currency:maybe", ""})
+
+	// Assert
+	assert.Equal(t, map[string]bool{Fees: true, Webhooks: false, Holds: true}, flags)
+}
+
+// TestEnabled_UndefinedFlagIsDisabled, tanımsız flag'in kapalı kabul edildiğini test eder.
+func TestEnabled_UndefinedFlagIsDisabled(t *testing.T) {
+	// Arrange
+	previous := All()
+	t.Cleanup(func() { Set(previous) })
+	Set(map[string]bool{Fees: true})
+
+	// Assert
+	assert.True(t, Enabled("FEES"))
+	assert.False(t, Enabled(Webhooks))
+}
@@ -1,15 +1,19 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/onerilhan/go-payment-api/internal/auth"
 	"github.com/onerilhan/go-payment-api/internal/middleware"
+	apperrors "github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/models"
 	"github.com/onerilhan/go-payment-api/internal/services"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 // BalanceHandler balance HTTP isteklerini yönetir
@@ -24,12 +28,6 @@ func NewBalanceHandler(balanceService *services.BalanceService) *BalanceHandler
 
 // GetCurrentBalance kullanıcının mevcut bakiyesini döner (protected)
 func (h *BalanceHandler) GetCurrentBalance(w http.ResponseWriter, r *http.Request) {
-	// Sadece GET metoduna izin ver
-	if r.Method != http.MethodGet {
-		http.Error(w, "Geçersiz HTTP metodu", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Context'ten user bilgilerini al
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -37,6 +35,12 @@ func (h *BalanceHandler) GetCurrentBalance(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// display_in verilmişse bakiye istenen para birimlerinde de (gösterge amaçlı) döner
+	if displayIn := r.URL.Query().Get("display_in"); displayIn != "" {
+		h.writeBalanceInCurrencies(w, claims.UserID, strings.Split(displayIn, ","))
+		return
+	}
+
 	// Kullanıcının bakiyesini getir
 	balance, err := h.balanceService.GetBalance(claims.UserID)
 	if err != nil {
@@ -53,21 +57,37 @@ func (h *BalanceHandler) GetCurrentBalance(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().Int("user_id", claims.UserID).Float64("balance", balance.Amount).Msg("Bakiye bilgisi getirildi")
 }
 
-// GetBalanceHistory kullanıcının bakiye geçmişi endpoint'i (protected)
-func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
-	// Sadece GET metoduna izin ver
-	if r.Method != http.MethodGet {
-		http.Error(w, "Geçersiz HTTP metodu", http.StatusMethodNotAllowed)
+// writeBalanceInCurrencies bakiyeyi istenen para birimlerine çevirip yazar (desteklenmeyen birim 400, kur hatası 503)
+func (h *BalanceHandler) writeBalanceInCurrencies(w http.ResponseWriter, userID int, currencies []string) {
+	display, err := h.balanceService.GetBalanceInCurrencies(userID, currencies)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedCurrency):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrExchangeRateUnavailable):
+			log.Warn().Err(err).Int("user_id", userID).Msg("Bakiye dönüşümü için kur alınamadı")
+			http.Error(w, "Kur bilgisi şu anda alınamıyor. Lütfen tekrar deneyin.", http.StatusServiceUnavailable)
+		default:
+			log.Error().Err(err).Int("user_id", userID).Msg("Bakiye getirilemedi")
+			http.Error(w, "Bakiye bilgisi alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
+		}
 		return
 	}
 
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    display,
+		"message": "Bakiye bilgisi getirildi. Dönüştürülmüş tutarlar gösterge niteliğindedir.",
+	})
+}
+
+// GetBalanceHistory kullanıcının bakiye geçmişi endpoint'i (protected)
+func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
 	// Context'ten user bilgilerini al
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -97,8 +117,15 @@ func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	// Sıralama yönü (asc | desc, boş ise varsayılan)
+	sort, err := models.ParseSortOrder(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Bakiye geçmişini getir
-	history, err := h.balanceService.GetBalanceHistory(claims.UserID, limit, offset)
+	history, err := h.balanceService.GetBalanceHistory(claims.UserID, limit, offset, sort)
 	if err != nil {
 		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Bakiye geçmişi getirilemedi")
 		http.Error(w, "Bakiye geçmişi alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
@@ -112,15 +139,14 @@ func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Reques
 			"history": history,
 			"limit":   limit,
 			"offset":  offset,
+			"sort":    sort,
 			"count":   len(history),
 		},
 		"message": "Bakiye geçmişi başarıyla getirildi",
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("user_id", claims.UserID).
@@ -132,12 +158,6 @@ func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Reques
 
 // GetBalanceAtTime belirli tarihte bakiye endpoint'i (protected)
 func (h *BalanceHandler) GetBalanceAtTime(w http.ResponseWriter, r *http.Request) {
-	// Sadece GET metoduna izin ver
-	if r.Method != http.MethodGet {
-		http.Error(w, "Geçersiz HTTP metodu", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Context'ten user bilgilerini al
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -156,7 +176,7 @@ func (h *BalanceHandler) GetBalanceAtTime(w http.ResponseWriter, r *http.Request
 	balanceAtTime, err := h.balanceService.GetBalanceAtTime(claims.UserID, timeStr)
 	if err != nil {
 		log.Error().Err(err).Int("user_id", claims.UserID).Str("time", timeStr).Msg("Belirli tarihteki bakiye hesaplanamadı")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, apperrors.SafeMessage(err), http.StatusBadRequest)
 		return
 	}
 
@@ -168,9 +188,7 @@ func (h *BalanceHandler) GetBalanceAtTime(w http.ResponseWriter, r *http.Request
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("user_id", claims.UserID).
@@ -178,3 +196,58 @@ func (h *BalanceHandler) GetBalanceAtTime(w http.ResponseWriter, r *http.Request
 		Float64("amount", balanceAtTime.Amount).
 		Msg("Belirli tarihteki bakiye hesaplandı")
 }
+
+// GetMinimumBalance kullanıcının belirlediği minimum bakiyeyi döner (protected)
+func (h *BalanceHandler) GetMinimumBalance(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası. Lütfen tekrar giriş yapın.", http.StatusUnauthorized)
+		return
+	}
+
+	minimum, err := h.balanceService.GetMinimumBalance(claims.UserID)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Minimum bakiye getirilemedi")
+		http.Error(w, "Minimum bakiye alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    minimum,
+		"message": "Minimum bakiye getirildi",
+	})
+}
+
+// UpdateMinimumBalance kullanıcının transfer ve çekimlerle altına inilemeyen minimum bakiyesini günceller (protected)
+func (h *BalanceHandler) UpdateMinimumBalance(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası. Lütfen tekrar giriş yapın.", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.UpdateMinimumBalanceRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	minimum, err := h.balanceService.SetMinimumBalance(claims.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Minimum bakiye güncellenemedi")
+		http.Error(w, "Minimum bakiye güncellenemedi. Lütfen tekrar deneyin.", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    minimum,
+		"message": "Minimum bakiye güncellendi",
+	})
+
+	log.Info().Int("user_id", claims.UserID).Float64("minimum_balance", minimum.MinimumBalance).Msg("Minimum bakiye güncellendi")
+}
@@ -1,24 +1,45 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 
 	"github.com/onerilhan/go-payment-api/internal/auth"
 	"github.com/onerilhan/go-payment-api/internal/middleware"
+	apperrors "github.com/onerilhan/go-payment-api/internal/middleware/errors"
 	"github.com/onerilhan/go-payment-api/internal/models"
 	"github.com/onerilhan/go-payment-api/internal/services"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
+// LimitWarningHeader soft limit eşiği geçildiğinde işlem yanıtına eklenen header
+const LimitWarningHeader = "X-Limit-Warning"
+
+// IdempotencyKeyHeader tekrar gönderilen isteğin yeniden çalıştırılmaması için client'ın gönderdiği header
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// queueFullRetryAfterSeconds transaction queue dolu olduğunda client'a önerilen tekrar deneme süresi
+const queueFullRetryAfterSeconds = 1
+
 // TransactionHandler transaction HTTP isteklerini yönetir
 type TransactionHandler struct {
 	transactionService *services.TransactionService
 	transactionQueue   *services.TransactionQueue
 	balanceService     *services.BalanceService // ← YENİ: Queue eklendi
+
+	transferScheduler *services.TransferScheduler // İleri tarihli transferler (nil = scheduled_at desteklenmez)
+
+	// true ise geçmiş sadece cursor ile sayfalanır (offset parametresi reddedilir)
+	offsetPaginationDisabled bool
 }
 
 // NewTransactionHandler yeni handler oluşturur
@@ -30,14 +51,36 @@ func NewTransactionHandler(transactionService *services.TransactionService, tran
 	}
 }
 
+// SetTransferScheduler scheduled_at içeren transfer isteklerini kaydedecek scheduler'ı ayarlar
+func (h *TransactionHandler) SetTransferScheduler(scheduler *services.TransferScheduler) {
+	h.transferScheduler = scheduler
+}
+
+// SetOffsetPagination geçmiş endpoint'inde offset sayfalamasını açar/kapatır (varsayılan açık, geriye uyumluluk için)
+func (h *TransactionHandler) SetOffsetPagination(enabled bool) {
+	h.offsetPaginationDisabled = !enabled
+}
+
 // Transfer para transfer endpoint'i (queue ile async)
 func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
-	// Sadece POST metoduna izin ver
-	if r.Method != http.MethodPost {
-		http.Error(w, "Sadece POST metoduna izin verilir", http.StatusMethodNotAllowed)
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "User bilgisi bulunamadı", http.StatusInternalServerError)
 		return
 	}
 
+	// JSON'u parse et
+	var req models.TransferRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	h.enqueueTransfer(w, r, claims.UserID, &req)
+}
+
+// TransferByAccountNumber hesap numarası ile para transfer endpoint'i (queue ile async)
+func (h *TransactionHandler) TransferByAccountNumber(w http.ResponseWriter, r *http.Request) {
 	// Context'ten user bilgilerini al
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -46,45 +89,360 @@ func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// JSON'u parse et
-	var req models.TransferRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Geçersiz JSON formatı", http.StatusBadRequest)
+	var req models.TransferByAccountNumberRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	// Checksum hatalı numaralar lookup yapılmadan reddedilir
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	toUserID, err := h.transactionService.ResolveAccountNumber(req.ToAccountNumber)
+	if err != nil {
+		http.Error(w, apperrors.SafeMessage(err), http.StatusNotFound)
+		return
+	}
+
+	h.enqueueTransfer(w, r, claims.UserID, req.ToTransferRequest(toUserID))
+}
+
+// TransferByEmail alıcının email adresi ile para transfer endpoint'i (queue ile async)
+func (h *TransactionHandler) TransferByEmail(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "User bilgisi bulunamadı", http.StatusInternalServerError)
+		return
+	}
+
+	// JSON'u parse et
+	var req models.TransferByEmailRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	toUserID, err := h.transactionService.ResolveEmail(req.ToEmail)
+	if err != nil {
+		http.Error(w, "alıcı kullanıcı bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	h.enqueueTransfer(w, r, claims.UserID, req.ToTransferRequest(toUserID))
+}
+
+// enqueueTransfer transferi queue'ya ekler, sonucu bekler ve yanıtı yazar
+func (h *TransactionHandler) enqueueTransfer(w http.ResponseWriter, r *http.Request, fromUserID int, req *models.TransferRequest) {
+	// İleri tarihli transfer: şimdi çalışmaz, kaydedilip 202 döner
+	if req.ScheduledAt != nil {
+		h.scheduleTransfer(w, fromUserID, req)
+		return
+	}
+
+	r, ok := withIdempotencyKey(w, r)
+	if !ok {
 		return
 	}
 
 	// Job'ı queue'ya ekle (async)
-	resultChan := h.transactionQueue.AddJob(claims.UserID, &req)
+	resultChan := h.transactionQueue.AddJob(r.Context(), fromUserID, req)
 
 	// Result'u bekle
 	result := <-resultChan
 
 	// Hata kontrolü
 	if result.Error != nil {
-		log.Error().Err(result.Error).Int("user_id", claims.UserID).Msg("Transfer başarısız")
-		http.Error(w, result.Error.Error(), http.StatusBadRequest)
+		log.Error().Err(result.Error).Int("user_id", fromUserID).Msg("Transfer başarısız")
+		writeTransactionError(w, result.Error, transferErrorStatus(result.Error))
 		return
 	}
 
-	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(result.Transaction)
+	// Fraud incelemesine alınan transfer: kabul edildi ama henüz gerçekleşmedi
+	if result.Transaction.IsPendingReview() {
+		utils.WriteJSON(w, http.StatusAccepted, result.Transaction)
+
+		log.Warn().
+			Int("from_user_id", fromUserID).
+			Int("to_user_id", req.ToUserID).
+			Int("transaction_id", result.Transaction.ID).
+			Float64("amount", req.Amount).
+			Msg("Transfer fraud incelemesine alındı")
+		return
+	}
+
+	// Başarılı yanıt (limite yaklaşıldıysa uyarıyla)
+	result.Transaction.LimitWarning = h.limitWarning(w, fromUserID)
+	utils.WriteJSON(w, http.StatusCreated, result.Transaction)
 
 	log.Info().
-		Int("from_user_id", claims.UserID).
+		Int("from_user_id", fromUserID).
 		Int("to_user_id", req.ToUserID).
 		Float64("amount", req.Amount).
 		Msg("Para transferi queue ile başarılı")
 }
 
-// GetHistory kullanıcının transaction geçmişini döner (protected)
-func (h *TransactionHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
-	// Sadece GET metoduna izin ver
-	if r.Method != http.MethodGet {
-		http.Error(w, "Geçersiz HTTP metodu", http.StatusMethodNotAllowed)
+// scheduleTransfer transferi ScheduledAt anına zamanlar ve 202 Accepted döner (geçmiş zaman 400)
+func (h *TransactionHandler) scheduleTransfer(w http.ResponseWriter, fromUserID int, req *models.TransferRequest) {
+	if h.transferScheduler == nil {
+		http.Error(w, "Zamanlanmış transfer desteklenmiyor", http.StatusBadRequest)
+		return
+	}
+
+	scheduled, err := h.transferScheduler.Schedule(fromUserID, req)
+	if err != nil {
+		log.Warn().Err(err).Int("user_id", fromUserID).Msg("Transfer zamanlanamadı")
+		http.Error(w, apperrors.SafeMessage(err), transferErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success":      true,
+		"data":         scheduled,
+		"scheduled_at": scheduled.ScheduledAt,
+		"message":      "Transfer zamanlandı",
+	})
+
+	log.Info().
+		Int("scheduled_transfer_id", scheduled.ID).
+		Int("from_user_id", fromUserID).
+		Int("to_user_id", req.ToUserID).
+		Time("scheduled_at", scheduled.ScheduledAt).
+		Msg("Transfer zamanlandı")
+}
+
+// CancelScheduledTransfer henüz çalışmamış zamanlanmış transferi iptal eder
+func (h *TransactionHandler) CancelScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+	if h.transferScheduler == nil {
+		http.Error(w, "Zamanlanmış transfer bulunamadı", http.StatusNotFound)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		http.Error(w, "Geçersiz ID", http.StatusBadRequest)
+		return
+	}
+
+	cancelled, err := h.transferScheduler.Cancel(claims.UserID, id)
+	if err != nil {
+		log.Warn().Err(err).Int("scheduled_transfer_id", id).Int("user_id", claims.UserID).Msg("Zamanlanmış transfer iptal edilemedi")
+		http.Error(w, apperrors.SafeMessage(err), scheduledTransferErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    cancelled,
+		"message": "Zamanlanmış transfer iptal edildi",
+	})
+}
+
+// scheduledTransferErrorStatus zamanlanmış transfer iptal hatasına uygun HTTP status'unu döner
+func scheduledTransferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrScheduledTransferNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrScheduledTransferNotCancellable):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// limitWarning başarılı işlemden sonra soft limit eşiği geçildiyse X-Limit-Warning header'ını set eder ve uyarıyı döner.
+// Uyarı hesaplanamazsa işlem zaten tamamlandığı için yanıt uyarısız döner.
+func (h *TransactionHandler) limitWarning(w http.ResponseWriter, userID int) *models.LimitWarning {
+	warning, err := h.transactionService.GetDailyLimitWarning(userID)
+	if err != nil {
+		log.Warn().Err(err).Int("user_id", userID).Msg("Limit uyarısı hesaplanamadı")
+		return nil
+	}
+	if warning == nil {
+		return nil
+	}
+
+	w.Header().Set(LimitWarningHeader, warning.HeaderValue())
+	return warning
+}
+
+// transferErrorStatus transfer hatası için HTTP status kodunu döner
+func transferErrorStatus(err error) int {
+	if errors.Is(err, services.ErrMemoTransferRateLimited) || errors.Is(err, services.ErrTransferTooSoon) {
+		return http.StatusTooManyRequests
+	}
+	if errors.Is(err, services.ErrTransferRequiresReview) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, services.ErrNewAccountTransferCooldown) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, services.ErrScheduledTransferLimitExceeded) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, services.ErrTransactionQueueFull) || errors.Is(err, services.ErrTransactionQueueStopped) {
+		return http.StatusServiceUnavailable
+	}
+	return idempotencyErrorStatus(err, http.StatusBadRequest)
+}
+
+// writeTransactionError para hareketi hatasını yazar. Yetersiz bakiyede client eksik tutarı hesaplamak zorunda
+// kalmasın diye mevcut bakiye, istenen tutar ve eksik kısım sayı olarak 422 JSON'da döner; diğer hatalar status ile yazılır.
+// Transferler arası bekleme süresi dolmadıysa kalan süre, queue doluysa önerilen bekleme Retry-After header'ında döner.
+func writeTransactionError(w http.ResponseWriter, err error, status int) {
+	var intervalErr *services.TransferIntervalError
+	if errors.As(err, &intervalErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(intervalErr.RetryAfterSeconds()))
+	}
+	if errors.Is(err, services.ErrTransactionQueueFull) {
+		w.Header().Set("Retry-After", strconv.Itoa(queueFullRetryAfterSeconds))
+	}
+
+	var balanceErr *services.InsufficientBalanceError
+	if !errors.As(err, &balanceErr) {
+		http.Error(w, apperrors.SafeMessage(err), status)
 		return
 	}
 
+	utils.WriteJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"success":         false,
+		"error":           balanceErr.Error(),
+		"error_code":      "insufficient_balance",
+		"current_balance": balanceErr.CurrentBalance,
+		"requested":       balanceErr.Requested,
+		"shortfall":       balanceErr.Shortfall(),
+	})
+}
+
+// withIdempotencyKey Idempotency-Key header'ı varsa doğrulayıp request context'ine ekler (geçersizse 400 yazar, false döner)
+func withIdempotencyKey(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		return r, true
+	}
+	if err := models.ValidateIdempotencyKey(key); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return r, false
+	}
+	return r.WithContext(utils.WithIdempotencyKey(r.Context(), key)), true
+}
+
+// idempotencyErrorStatus Idempotency-Key hatalarına uygun status'u, diğer hatalarda fallback'i döner
+func idempotencyErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrIdempotencyKeyInProgress):
+		return http.StatusConflict
+	}
+	return fallback
+}
+
+// decodeBatchTransferRequest batch transfer body'sini token token decode eder.
+// transfers array'i MaxBatchTransferItems'ı aştığı anda durur, böylece dev array'ler belleğe alınmaz.
+func decodeBatchTransferRequest(body io.Reader) (*models.BatchTransferRequest, error) {
+	dec := json.NewDecoder(body)
+	req := &models.BatchTransferRequest{}
+
+	if err := utils.ExpectJSONDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "mode":
+			if err := dec.Decode(&req.Mode); err != nil {
+				return nil, err
+			}
+		case "transfers":
+			err := utils.DecodeJSONArray(dec, models.MaxBatchTransferItems, func(dec *json.Decoder) error {
+				var transfer models.TransferRequest
+				if err := dec.Decode(&transfer); err != nil {
+					return err
+				}
+				req.Transfers = append(req.Transfers, transfer)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		default:
+			// Bilinmeyen alanlar json.Unmarshal'daki gibi yok sayılır
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := utils.ExpectJSONDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// BatchTransfer toplu transfer endpoint'i (mode: atomic | best_effort)
+func (h *TransactionHandler) BatchTransfer(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "User bilgisi bulunamadı", http.StatusInternalServerError)
+		return
+	}
+
+	// JSON'u stream olarak parse et (transfers array'i limit aşılınca okunmayı bırakır)
+	req, err := decodeBatchTransferRequest(r.Body)
+	if errors.Is(err, utils.ErrJSONArrayTooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	result, err := h.transactionService.BatchTransfer(r.Context(), claims.UserID, req)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Batch transfer başarısız")
+		writeTransactionError(w, err, transferErrorStatus(err))
+		return
+	}
+
+	// Kısmi başarıda 207 Multi-Status döner
+	h.limitWarning(w, claims.UserID)
+	statusCode := http.StatusCreated
+	if result.Failed > 0 || result.Skipped > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+	utils.WriteJSON(w, statusCode, result)
+
+	log.Info().
+		Int("from_user_id", claims.UserID).
+		Str("mode", result.Mode).
+		Int("succeeded", result.Succeeded).
+		Int("failed", result.Failed).
+		Int("skipped", result.Skipped).
+		Msg("Batch transfer tamamlandı")
+}
+
+// GetHistory kullanıcının transaction geçmişini döner (protected)
+func (h *TransactionHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	// Context'ten user bilgilerini al
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -114,8 +472,43 @@ func (h *TransactionHandler) GetHistory(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Filtreler (type, status, from, to) ve sıralama (asc | desc | amount_asc | amount_desc)
+	query := r.URL.Query()
+	from, err := parseDateParam(query.Get("from"), false)
+	if err != nil {
+		http.Error(w, "Geçersiz from parametresi (YYYY-MM-DD veya RFC3339 olmalı)", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateParam(query.Get("to"), true)
+	if err != nil {
+		http.Error(w, "Geçersiz to parametresi (YYYY-MM-DD veya RFC3339 olmalı)", http.StatusBadRequest)
+		return
+	}
+
+	filter := &models.TransactionHistoryFilter{
+		Type:   strings.ToLower(strings.TrimSpace(query.Get("type"))),
+		Status: strings.ToLower(strings.TrimSpace(query.Get("status"))),
+		From:   from,
+		To:     to,
+		Sort:   query.Get("sort"),
+	}
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// ?cursor= verilmişse (boş = ilk sayfa) veya offset sayfalama kapalıysa keyset ile sayfalanır
+	if query.Has("cursor") || h.offsetPaginationDisabled {
+		if offsetStr != "" {
+			http.Error(w, "offset ve cursor birlikte kullanılamaz; sonraki sayfa için next_cursor kullanın", http.StatusBadRequest)
+			return
+		}
+		h.writeHistoryPage(w, claims.UserID, filter, limit, query.Get("cursor"))
+		return
+	}
+
 	// Transaction geçmişini getir
-	transactions, err := h.transactionService.GetUserTransactions(claims.UserID, limit, offset)
+	transactions, err := h.transactionService.GetUserTransactionsFiltered(claims.UserID, filter, limit, offset)
 	if err != nil {
 		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Transaction geçmişi getirilemedi")
 		http.Error(w, "İşlem geçmişi alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
@@ -129,15 +522,14 @@ func (h *TransactionHandler) GetHistory(w http.ResponseWriter, r *http.Request)
 			"transactions": transactions,
 			"limit":        limit,
 			"offset":       offset,
+			"sort":         filter.Sort,
 			"count":        len(transactions),
 		},
 		"message": "İşlem geçmişi başarıyla getirildi",
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("user_id", claims.UserID).
@@ -147,15 +539,47 @@ func (h *TransactionHandler) GetHistory(w http.ResponseWriter, r *http.Request)
 		Msg("Transaction geçmişi getirildi")
 }
 
-// Credit hesaba para yatırma endpoint'i
-func (h *TransactionHandler) Credit(w http.ResponseWriter, r *http.Request) {
-	// Sadece POST metoduna izin ver
-	if r.Method != http.MethodPost {
-		w.Header().Set("Allow", http.MethodPost)
-		http.Error(w, "Sadece POST metoduna izin verilir", http.StatusMethodNotAllowed)
+// writeHistoryPage transaction geçmişini cursor ile sayfalayıp yazar (sonraki sayfa varsa next_cursor döner)
+func (h *TransactionHandler) writeHistoryPage(w http.ResponseWriter, userID int, filter *models.TransactionHistoryFilter, limit int, cursor string) {
+	if filter.Sort == models.SortAmountAsc || filter.Sort == models.SortAmountDesc {
+		http.Error(w, "Cursor sayfalama sadece tarih sıralamasıyla (asc | desc) kullanılabilir", http.StatusBadRequest)
+		return
+	}
+	if cursor != "" {
+		decoded, err := models.DecodeHistoryCursor(cursor)
+		if err != nil {
+			http.Error(w, "Geçersiz cursor parametresi", http.StatusBadRequest)
+			return
+		}
+		filter.Cursor = decoded
+	}
+
+	page, err := h.transactionService.GetUserTransactionsPage(userID, filter, limit)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", userID).Msg("Transaction geçmişi getirilemedi")
+		http.Error(w, "İşlem geçmişi alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
 		return
 	}
 
+	data := map[string]interface{}{
+		"transactions": page.Transactions,
+		"limit":        limit,
+		"sort":         filter.Sort,
+		"count":        len(page.Transactions),
+	}
+	if page.NextCursor != "" {
+		data["next_cursor"] = page.NextCursor
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"message": "İşlem geçmişi başarıyla getirildi",
+	})
+}
+
+// Credit hesaba para yatırma endpoint'i
+func (h *TransactionHandler) Credit(w http.ResponseWriter, r *http.Request) {
 	// Context'ten user bilgilerini al (JWT middleware tarafından eklenir)
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -165,27 +589,24 @@ func (h *TransactionHandler) Credit(w http.ResponseWriter, r *http.Request) {
 
 	// JSON'u parse et
 	var req models.CreditRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Geçersiz JSON formatı", http.StatusBadRequest)
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	r, ok = withIdempotencyKey(w, r)
+	if !ok {
 		return
 	}
 
 	// Credit işlemini yap
-	transaction, err := h.transactionService.Credit(claims.UserID, &req)
+	// İşlem sonrası bakiye transaction içinde hesaplanır (ayrı okuma eşzamanlı işlemlerle yarışabilir)
+	transaction, newBalance, err := h.transactionService.Credit(r.Context(), claims.UserID, &req)
 	if err != nil {
 		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Credit işlemi başarısız")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, apperrors.SafeMessage(err), idempotencyErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
-	// Güncel bakiyeyi al
-	newBalance, err := h.balanceService.GetBalance(claims.UserID)
-	if err != nil {
-		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Bakiye alınamadı")
-		// Transaction başarılı ama bakiye alınamadı - yine de devam et
-		newBalance = &models.Balance{Amount: 0}
-	}
-
 	// Güvenli response oluştur (hassas bilgileri filtrele)
 	response := models.CreditResponse{
 		Success: true,
@@ -197,30 +618,22 @@ func (h *TransactionHandler) Credit(w http.ResponseWriter, r *http.Request) {
 			Description: transaction.Description,
 			CreatedAt:   transaction.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		},
-		NewBalance: newBalance.Amount,
-		Message:    "Para yatırma işlemi başarılı",
+		NewBalance:   newBalance,
+		Message:      "Para yatırma işlemi başarılı",
+		LimitWarning: h.limitWarning(w, claims.UserID),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusCreated, response)
 
 	log.Info().
 		Int("user_id", claims.UserID).
 		Float64("amount", req.Amount).
-		Float64("new_balance", newBalance.Amount).
+		Float64("new_balance", newBalance).
 		Msg("Credit işlemi tamamlandı")
 }
 
 // Debit hesaptan para çekme endpoint'i
 func (h *TransactionHandler) Debit(w http.ResponseWriter, r *http.Request) {
-	// Sadece POST metoduna izin ver
-	if r.Method != http.MethodPost {
-		w.Header().Set("Allow", http.MethodPost)
-		http.Error(w, "Sadece POST metoduna izin verilir", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Context'ten user bilgilerini al (JWT middleware tarafından eklenir)
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -230,27 +643,24 @@ func (h *TransactionHandler) Debit(w http.ResponseWriter, r *http.Request) {
 
 	// JSON'u parse et
 	var req models.DebitRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Geçersiz JSON formatı", http.StatusBadRequest)
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	r, ok = withIdempotencyKey(w, r)
+	if !ok {
 		return
 	}
 
 	// Debit işlemini yap
-	transaction, err := h.transactionService.Debit(claims.UserID, &req)
+	// İşlem sonrası bakiye transaction içinde hesaplanır (ayrı okuma eşzamanlı işlemlerle yarışabilir)
+	transaction, newBalance, err := h.transactionService.Debit(r.Context(), claims.UserID, &req)
 	if err != nil {
 		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Debit işlemi başarısız")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeTransactionError(w, err, idempotencyErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
-	// Güncel bakiyeyi al
-	newBalance, err := h.balanceService.GetBalance(claims.UserID)
-	if err != nil {
-		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Bakiye alınamadı")
-		// Transaction başarılı ama bakiye alınamadı - yine de devam et
-		newBalance = &models.Balance{Amount: 0}
-	}
-
 	// Response oluştur
 	response := models.DebitResponse{
 		Success: true,
@@ -262,21 +672,533 @@ func (h *TransactionHandler) Debit(w http.ResponseWriter, r *http.Request) {
 			Description: transaction.Description,
 			CreatedAt:   transaction.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		},
-		NewBalance: newBalance.Amount,
-		Message:    "Para çekme işlemi başarılı",
+		NewBalance:   newBalance,
+		Message:      "Para çekme işlemi başarılı",
+		LimitWarning: h.limitWarning(w, claims.UserID),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusCreated, response)
 
 	log.Info().
 		Int("user_id", claims.UserID).
 		Float64("amount", req.Amount).
-		Float64("new_balance", newBalance.Amount).
+		Float64("new_balance", newBalance).
 		Msg("Debit işlemi tamamlandı")
 }
 
+// GetLimits kullanıcının günlük/aylık limitlerini ve kalan haklarını döner
+func (h *TransactionHandler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	limits, err := h.transactionService.GetTransactionLimits(claims.UserID)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Transaction limitleri getirilemedi")
+		http.Error(w, "Limit bilgisi alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"data":    limits,
+		"message": "Limit bilgisi başarıyla getirildi",
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+
+	log.Info().
+		Int("user_id", claims.UserID).
+		Int("daily_count", limits.Daily.Count.Used).
+		Float64("daily_amount", limits.Daily.Amount.Used).
+		Msg("Transaction limitleri getirildi")
+}
+
+// GetCounterparties kullanıcının en çok işlem yaptığı karşı tarafları döner (protected)
+// Opsiyonel from/to (YYYY-MM-DD veya RFC3339) tarih aralığını, limit ise dönen kayıt sayısını belirler.
+func (h *TransactionHandler) GetCounterparties(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+
+	from, err := parseDateParam(query.Get("from"), false)
+	if err != nil {
+		http.Error(w, "Geçersiz from parametresi (YYYY-MM-DD veya RFC3339 olmalı)", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateParam(query.Get("to"), true)
+	if err != nil {
+		http.Error(w, "Geçersiz to parametresi (YYYY-MM-DD veya RFC3339 olmalı)", http.StatusBadRequest)
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		http.Error(w, "from tarihi to tarihinden önce olmalıdır", http.StatusBadRequest)
+		return
+	}
+
+	limit := models.DefaultCounterpartyLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 && parsedLimit <= models.MaxCounterpartyLimit {
+			limit = parsedLimit
+		}
+	}
+
+	counterparties, err := h.transactionService.GetCounterparties(claims.UserID, from, to, limit)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Karşı taraf özeti getirilemedi")
+		http.Error(w, "Karşı taraf özeti alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"counterparties": counterparties,
+		"limit":          limit,
+		"count":          len(counterparties),
+	}
+	if !from.IsZero() {
+		data["from"] = from.Format(time.RFC3339)
+	}
+	if !to.IsZero() {
+		data["to"] = to.Format(time.RFC3339)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"message": "Karşı taraf özeti başarıyla getirildi",
+	})
+
+	log.Info().
+		Int("user_id", claims.UserID).
+		Int("count", len(counterparties)).
+		Msg("Karşı taraf özeti getirildi")
+}
+
+// parseDateParam YYYY-MM-DD veya RFC3339 tarih parametresini parse eder (boş = sıfır zaman).
+// endOfDay true ise YYYY-MM-DD formatındaki tarih o günü kapsayacak şekilde ertesi günün başına çevrilir.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// ListTransferReviews fraud incelemesindeki transferleri listeler (admin)
+func (h *TransactionHandler) ListTransferReviews(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if parsedLimit, err := strconv.Atoi(query.Get("limit")); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
+		limit = parsedLimit
+	}
+	offset := 0
+	if parsedOffset, err := strconv.Atoi(query.Get("offset")); err == nil && parsedOffset >= 0 {
+		offset = parsedOffset
+	}
+
+	status := query.Get("status")
+	if status != "" && !models.IsValidReviewStatus(status) {
+		http.Error(w, "Geçersiz status. Geçerli değerler: open, approved, rejected", http.StatusBadRequest)
+		return
+	}
+
+	reviews, err := h.transactionService.ListTransferReviews(status, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msg("İnceleme kayıtları getirilemedi")
+		http.Error(w, "İnceleme kayıtları alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"reviews": reviews,
+			"limit":   limit,
+			"offset":  offset,
+			"count":   len(reviews),
+		},
+		"message": "İnceleme kayıtları başarıyla getirildi",
+	})
+}
+
+// ApproveTransferReview incelemedeki transferi onaylar ve gerçekleştirir (admin, review ID ile)
+func (h *TransactionHandler) ApproveTransferReview(w http.ResponseWriter, r *http.Request) {
+	h.decideTransferReview(w, r, h.transactionService.ApproveTransferReview, models.ReviewStatusApproved)
+}
+
+// RejectTransferReview incelemedeki transferi reddeder (admin, review ID ile)
+func (h *TransactionHandler) RejectTransferReview(w http.ResponseWriter, r *http.Request) {
+	h.decideTransferReview(w, r, h.transactionService.RejectTransferReview, models.ReviewStatusRejected)
+}
+
+// ApproveHeldTransaction pending_review durumundaki transaction'ı onaylar (admin, transaction ID ile)
+func (h *TransactionHandler) ApproveHeldTransaction(w http.ResponseWriter, r *http.Request) {
+	h.decideTransferReview(w, r, h.transactionService.ApproveHeldTransaction, models.ReviewStatusApproved)
+}
+
+// RejectHeldTransaction pending_review durumundaki transaction'ı reddeder (admin, transaction ID ile)
+func (h *TransactionHandler) RejectHeldTransaction(w http.ResponseWriter, r *http.Request) {
+	h.decideTransferReview(w, r, h.transactionService.RejectHeldTransaction, models.ReviewStatusRejected)
+}
+
+// reviewDecider path'teki ID ile inceleme kararını uygulayan service metodu
+type reviewDecider func(ctx context.Context, id int, decision *models.ReviewDecision) (*models.TransactionReview, error)
+
+// decideTransferReview onay/red endpoint'lerinin ortak akışı
+func (h *TransactionHandler) decideTransferReview(w http.ResponseWriter, r *http.Request, decide reviewDecider, outcome string) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		http.Error(w, "Geçersiz ID", http.StatusBadRequest)
+		return
+	}
+
+	// Sebep opsiyonel: body boş olabilir
+	var req models.ReviewDecisionRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil && !errors.Is(err, io.EOF) {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	review, err := decide(r.Context(), id, &models.ReviewDecision{
+		AdminUserID: claims.UserID,
+		Reason:      req.Reason,
+		IPAddress:   utils.GetClientIP(r),
+		UserAgent:   r.UserAgent(),
+	})
+	if err != nil {
+		log.Error().Err(err).Int("id", id).Str("decision", outcome).Msg("İnceleme kararı uygulanamadı")
+		http.Error(w, apperrors.SafeMessage(err), reviewErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    review,
+		"message": "İnceleme kararı uygulandı",
+	})
+
+	log.Info().
+		Int("review_id", review.ID).
+		Int("transaction_id", review.TransactionID).
+		Int("admin_id", claims.UserID).
+		Str("decision", outcome).
+		Msg("Transfer incelemesi karara bağlandı")
+}
+
+// reviewErrorStatus inceleme kararı hatasına uygun HTTP status'unu döner
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrReviewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrReviewAlreadyDecided):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrBelowMinimumBalance):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// AuthorizeHold checkout'ta tutarı bloke eden endpoint (marketplace authorize)
+func (h *TransactionHandler) AuthorizeHold(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.AuthorizeHoldRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	hold, err := h.transactionService.AuthorizeHold(r.Context(), claims.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Hold oluşturulamadı")
+		http.Error(w, apperrors.SafeMessage(err), holdErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    hold,
+		"message": "Tutar bloke edildi",
+	})
+
+	log.Info().
+		Int("hold_id", hold.ID).
+		Int("user_id", claims.UserID).
+		Int("to_user_id", hold.ToUserID).
+		Float64("amount", hold.Amount).
+		Msg("Hold oluşturuldu")
+}
+
+// CaptureHold hold'un tamamını veya bir kısmını transfere çeviren endpoint (kalan tutar serbest bırakılır).
+// Hold'un alıcısı (ör. merchant) kendi hold'unu, ters kayıt yetkisi olan admin ise herhangi bir hold'u capture edebilir.
+func (h *TransactionHandler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CaptureHoldRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	asAdmin := middleware.HasPermission(claims.Role, middleware.PermReverseTransaction)
+	result, err := h.transactionService.CaptureHold(r.Context(), claims.UserID, asAdmin, &req)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Int("hold_id", req.HoldID).Msg("Hold capture edilemedi")
+		writeTransactionError(w, err, holdErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    result,
+		"message": "Hold capture edildi",
+	})
+
+	log.Info().
+		Int("hold_id", result.Hold.ID).
+		Int("transaction_id", result.Transaction.ID).
+		Float64("captured", result.Hold.CapturedAmount).
+		Float64("released", result.Released).
+		Msg("Hold capture edildi")
+}
+
+// VoidHold aktif hold'u serbest bırakan endpoint (yalnızca hold'un alıcısı veya ters kayıt yetkisi olan admin)
+func (h *TransactionHandler) VoidHold(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.VoidHoldRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	asAdmin := middleware.HasPermission(claims.Role, middleware.PermReverseTransaction)
+	hold, err := h.transactionService.VoidHold(r.Context(), claims.UserID, asAdmin, &req)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Int("hold_id", req.HoldID).Msg("Hold void edilemedi")
+		http.Error(w, apperrors.SafeMessage(err), holdErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    hold,
+		"message": "Hold serbest bırakıldı",
+	})
+
+	log.Info().
+		Int("hold_id", hold.ID).
+		Int("user_id", claims.UserID).
+		Float64("released", hold.ReleasedAmount()).
+		Msg("Hold void edildi")
+}
+
+// holdErrorStatus hold işlemi hatasına uygun HTTP status'unu döner
+func holdErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrHoldsDisabled), errors.Is(err, services.ErrHoldNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrHoldForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrHoldNotActive):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusUnprocessableEntity
+	}
+	// Hold transferin ön kontrollerinden geçer (bekleme süresi, fraud incelemesi, rate limitler)
+	return transferErrorStatus(err)
+}
+
+// ReverseTransaction completed transaction'ı ters kayıtla geri alır (admin, transaction ID ile)
+func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		http.Error(w, "Geçersiz ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ReverseTransactionRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reversal, err := h.transactionService.Reverse(r.Context(), id, req.Reason)
+	if err != nil {
+		log.Error().Err(err).Int("transaction_id", id).Int("admin_id", claims.UserID).Msg("Transaction ters çevrilemedi")
+		http.Error(w, apperrors.SafeMessage(err), reversalErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    reversal,
+		"message": "Transaction ters çevrildi",
+	})
+
+	log.Info().
+		Int("transaction_id", id).
+		Int("reversal_id", reversal.ID).
+		Int("admin_id", claims.UserID).
+		Str("reason", req.Reason).
+		Msg("Transaction ters çevrildi")
+}
+
+// reversalErrorStatus ters kayıt hatasına uygun HTTP status'unu döner
+func reversalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTransactionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTransactionAlreadyReversed), errors.Is(err, services.ErrTransactionNotReversible):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// RefundTransaction completed transaction'ın bir kısmını iade eder. Transferin alıcısı (ör. merchant) kendi aldığı
+// transferi, ters kayıt yetkisi olan admin ise herhangi bir transaction'ı iade edebilir.
+func (h *TransactionHandler) RefundTransaction(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		http.Error(w, "Geçersiz ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.RefundTransactionRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	asAdmin := middleware.HasPermission(claims.Role, middleware.PermReverseTransaction)
+	result, err := h.transactionService.Refund(r.Context(), id, claims.UserID, asAdmin, &req)
+	if err != nil {
+		log.Error().Err(err).Int("transaction_id", id).Int("user_id", claims.UserID).Msg("Transaction iade edilemedi")
+		writeTransactionError(w, err, refundErrorStatus(err))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    result,
+		"message": "Kısmi iade yapıldı",
+	})
+
+	log.Info().
+		Int("transaction_id", id).
+		Int("refund_id", result.Refund.ID).
+		Int("user_id", claims.UserID).
+		Float64("amount", req.Amount).
+		Float64("remaining_refundable", result.RemainingRefundable).
+		Msg("Kısmi iade yapıldı")
+}
+
+// refundErrorStatus iade hatasına uygun HTTP status'unu döner
+func refundErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrRefundsDisabled), errors.Is(err, services.ErrTransactionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRefundForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrRefundExceedsRemaining):
+		return http.StatusUnprocessableEntity
+	}
+	return reversalErrorStatus(err)
+}
+
+// BulkUpdateTransactionStatus takılı kalmış transaction'ları toplu olarak failed/cancelled yapar (admin, incident kurtarma).
+// Sonuçlar ID bazında döner; bir kısmı başarısızsa 207 Multi-Status yazılır.
+func (h *TransactionHandler) BulkUpdateTransactionStatus(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		http.Error(w, "Yetkilendirme hatası", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.BulkStatusUpdateRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(apperrors.NewInvalidJSONError(err))
+	}
+
+	result, err := h.transactionService.BulkUpdateStatus(r.Context(), &req, &models.ReviewDecision{
+		AdminUserID: claims.UserID,
+		IPAddress:   utils.GetClientIP(r),
+		UserAgent:   r.UserAgent(),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if result.Failed > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+	utils.WriteJSON(w, statusCode, result)
+
+	log.Info().
+		Int("admin_id", claims.UserID).
+		Str("status", result.Status).
+		Int("succeeded", result.Succeeded).
+		Int("failed", result.Failed).
+		Str("reason", req.Reason).
+		Msg("Toplu transaction status güncellemesi tamamlandı")
+}
+
 // GetTransactionByID ID ile transaction getirme endpoint'i (Gorilla Mux version)
 func (h *TransactionHandler) GetTransactionByID(w http.ResponseWriter, r *http.Request) {
 	// Context'ten user bilgilerini al
@@ -342,9 +1264,7 @@ func (h *TransactionHandler) GetTransactionByID(w http.ResponseWriter, r *http.R
 		"message": "Transaction başarıyla getirildi",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("user_id", claims.UserID).
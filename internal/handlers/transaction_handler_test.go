@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/auth"
+	"github.com/onerilhan/go-payment-api/internal/middleware"
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/repository"
+	"github.com/onerilhan/go-payment-api/internal/services"
+)
+
+// expectUserLookup limitler için yapılan kullanıcı sorgusunu bekler
+func expectUserLookup(dbMock sqlmock.Sqlmock, userID int) {
+	dbMock.ExpectQuery("SELECT (.+) FROM users").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "account_number", "version"}).
+			AddRow(userID, "Ali", "ali@example.com", "user", time.Now(), nil, 1))
+}
+
+// expectDailyCount günlük işlem sayısı sorgusunu bekler
+func expectDailyCount(dbMock sqlmock.Sqlmock, userID int, count int) {
+	dbMock.ExpectQuery("SELECT COUNT").WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+// runCredit limit ayarlı servis ile credit endpoint'ini çalıştırır; bugünkü işlem sayısı countBefore'dur
+func runCredit(t *testing.T, countBefore int) *httptest.ResponseRecorder {
+	t.Helper()
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+
+	// Role limitleri
+	expectUserLookup(dbMock, 7)
+
+	// Credit transaction'ı (sayı bakiye kilidi altında kontrol edilir)
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("INSERT INTO balances").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec("SELECT 1 FROM balances").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectDailyCount(dbMock, 7, countBefore)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	dbMock.ExpectQuery("UPDATE balances").
+		WillReturnRows(sqlmock.NewRows([]string{"previous", "amount"}).AddRow(100.0, 150.0))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	// İşlem sonrası uyarı hesabı (günlük kullanım: sayı ve giden tutar)
+	expectUserLookup(dbMock, 7)
+	dbMock.ExpectQuery("SELECT COUNT").WithArgs(7, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "amount"}).AddRow(countBefore+1, 0.0))
+
+	transactionService := services.NewTransactionService(
+		repository.NewTransactionRepository(database),
+		repository.NewUserRepository(database),
+		services.NewBalanceService(repository.NewBalanceRepository(database)),
+		database,
+	)
+	transactionService.SetLimitConfig(&services.TransactionLimitConfig{
+		DailyCountByRole:    map[string]int{"user": 10},
+		DailySoftLimitRatio: 0.8,
+	})
+	transactionHandler := NewTransactionHandler(transactionService, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/credit", strings.NewReader(`{"amount":50}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: 7, Role: "user"}))
+	rec := httptest.NewRecorder()
+
+	transactionHandler.Credit(rec, req)
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	return rec
+}
+
+// TestTransactionHandler_Credit_WarnsBetweenSoftAndHardLimit, soft (%80) ve hard (%100) eşik arasındaki işlemin uyarıyla kabul edildiğini test eder.
+func TestTransactionHandler_Credit_WarnsBetweenSoftAndHardLimit(t *testing.T) {
+	// Act
+	belowSoft := runCredit(t, 6)     // işlem sonrası 7/10
+	betweenLimits := runCredit(t, 8) // işlem sonrası 9/10
+
+	// Assert
+	require.Equal(t, http.StatusCreated, belowSoft.Code)
+	assert.Empty(t, belowSoft.Header().Get(LimitWarningHeader))
+	assert.NotContains(t, belowSoft.Body.String(), "limit_warning")
+
+	require.Equal(t, http.StatusCreated, betweenLimits.Code)
+	assert.Equal(t, "daily; used=9; limit=10; remaining=1", betweenLimits.Header().Get(LimitWarningHeader))
+
+	var response models.CreditResponse
+	require.NoError(t, json.Unmarshal(betweenLimits.Body.Bytes(), &response))
+	require.NotNil(t, response.LimitWarning)
+	assert.Equal(t, 9, response.LimitWarning.Used)
+	assert.Equal(t, 1, response.LimitWarning.Remaining)
+}
+
+// TestTransactionHandler_Debit_InsufficientBalanceReturnsShortfall, bakiye yetmeyen çekimde 422 ile mevcut bakiye,
+// istenen tutar ve eksik tutarın sayı olarak döndüğünü test eder.
+func TestTransactionHandler_Debit_InsufficientBalanceReturnsShortfall(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(50.1))
+	dbMock.ExpectRollback()
+
+	transactionService := services.NewTransactionService(
+		repository.NewTransactionRepository(database),
+		repository.NewUserRepository(database),
+		services.NewBalanceService(repository.NewBalanceRepository(database)),
+		database,
+	)
+	transactionService.SetLimitConfig(nil)
+	transactionHandler := NewTransactionHandler(transactionService, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/debit", strings.NewReader(`{"amount":80}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: 7, Role: "user"}))
+	rec := httptest.NewRecorder()
+
+	// Act
+	transactionHandler.Debit(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "insufficient_balance", response["error_code"])
+	assert.Equal(t, 50.1, response["current_balance"])
+	assert.Equal(t, 80.0, response["requested"])
+	assert.Equal(t, 29.9, response["shortfall"])
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// newHistoryRequest kullanıcı 7 adına verilen query ile geçmiş isteği oluşturur
+func newHistoryRequest(query string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/history?"+query, nil)
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: 7, Role: "user"}))
+}
+
+// TestTransactionHandler_GetHistory_Filters, birleşik filtrelerin repository'e iletildiğini, boş sonucun boş liste olarak
+// döndüğünü ve geçersiz filtre değerlerinin 400 ile reddedildiğini test eder.
+func TestTransactionHandler_GetHistory_Filters(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC) // to=2025-08-31 günün sonuna kadar dahil
+	dbMock.ExpectQuery("SELECT (.+) FROM transactions WHERE (.+) ORDER BY amount DESC").
+		WithArgs(7, models.TypeDebit, models.StatusCompleted, from, to, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}))
+
+	transactionService := services.NewTransactionService(
+		repository.NewTransactionRepository(database),
+		repository.NewUserRepository(database),
+		services.NewBalanceService(repository.NewBalanceRepository(database)),
+		database,
+	)
+	transactionHandler := NewTransactionHandler(transactionService, nil, nil)
+
+	// Act
+	filtered := httptest.NewRecorder()
+	transactionHandler.GetHistory(filtered, newHistoryRequest("type=debit&status=completed&from=2025-08-01&to=2025-08-31&sort=amount_desc"))
+
+	invalid := map[string]string{
+		"type":   "type=refund",
+		"status": "status=done",
+		"sort":   "sort=amount",
+		"range":  "from=2025-09-01&to=2025-08-01",
+	}
+	rejected := make(map[string]int)
+	for name, query := range invalid {
+		rec := httptest.NewRecorder()
+		transactionHandler.GetHistory(rec, newHistoryRequest(query))
+		rejected[name] = rec.Code
+	}
+
+	// Assert
+	require.Equal(t, http.StatusOK, filtered.Code)
+
+	var response struct {
+		Data struct {
+			Transactions []*models.Transaction `json:"transactions"`
+			Sort         string                `json:"sort"`
+			Count        int                   `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(filtered.Body.Bytes(), &response))
+	assert.NotNil(t, response.Data.Transactions)
+	assert.Empty(t, response.Data.Transactions)
+	assert.Equal(t, models.SortAmountDesc, response.Data.Sort)
+	assert.Equal(t, 0, response.Data.Count)
+
+	for name, code := range rejected {
+		assert.Equal(t, http.StatusBadRequest, code, name)
+	}
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionHandler_Transfer_QueueFullReturns503, queue bekleme süresi içinde yer açılmazsa transferin
+// 503 ve Retry-After header'ı ile döndüğünü test eder.
+func TestTransactionHandler_Transfer_QueueFullReturns503(t *testing.T) {
+	// Arrange: worker'sız, buffer'sız queue hiçbir job'ı kabul edemez
+	transactionQueue := services.NewTransactionQueue(0, nil, 0)
+	transactionQueue.SetEnqueueTimeout(10 * time.Millisecond)
+	transactionHandler := NewTransactionHandler(nil, transactionQueue, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/transfer", strings.NewReader(`{"to_user_id":20,"amount":10}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: 7, Role: "user"}))
+	rec := httptest.NewRecorder()
+
+	// Act
+	transactionHandler.Transfer(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), services.ErrTransactionQueueFull.Error())
+}
@@ -1,7 +1,8 @@
 package handlers
 
 import (
-	"encoding/json"
+	stderrors "errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -13,39 +14,31 @@ import (
 	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
 	"github.com/onerilhan/go-payment-api/internal/models"
 	"github.com/onerilhan/go-payment-api/internal/services"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 // UserHandler HTTP isteklerini yönetir
 type UserHandler struct {
-	userService *services.UserService
+	userService         *services.UserService
+	accountService      *services.AccountService
+	notificationService *services.NotificationService
 }
 
 // NewUserHandler yeni handler oluşturur
-func NewUserHandler(userService *services.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *services.UserService, accountService *services.AccountService, notificationService *services.NotificationService) *UserHandler {
+	return &UserHandler{
+		userService:         userService,
+		accountService:      accountService,
+		notificationService: notificationService,
+	}
 }
 
 // Register kullanıcı kayıt endpoint'i - VALİDASYON EKLENDİ
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
-	// Sadece POST metoduna izin ver
-	if r.Method != http.MethodPost {
-		panic(&errors.ValidationError{
-			Message:    "Sadece POST metoduna izin verilir",
-			StatusCode: http.StatusMethodNotAllowed,
-			Field:      "method",
-			Value:      r.Method,
-		})
-	}
-
 	// JSON'u parse et
 	var req models.CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		panic(&errors.ValidationError{
-			Message:    "Geçersiz JSON formatı",
-			StatusCode: http.StatusBadRequest,
-			Field:      "body",
-			Value:      err.Error(),
-		})
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(errors.NewInvalidJSONError(err))
 	}
 
 	//  YENİ VALİDASYON KONTROLÜ
@@ -68,7 +61,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Error().Err(err).Msg("Kullanıcı kaydı başarısız")
 		panic(&errors.ValidationError{
-			Message:    err.Error(),
+			Message:    errors.SafeMessage(err),
 			StatusCode: http.StatusBadRequest,
 			Field:      "registration",
 			Value:      req.Email,
@@ -76,9 +69,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(user)
+	utils.WriteJSON(w, http.StatusCreated, user)
 
 	log.Info().
 		Str("email", user.Email).
@@ -88,25 +79,10 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 // Login kullanıcı giriş endpoint'i - VALİDASYON EKLENDİ
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
-	// Sadece POST metoduna izin ver
-	if r.Method != http.MethodPost {
-		panic(&errors.ValidationError{
-			Message:    "Sadece POST metoduna izin verilir",
-			StatusCode: http.StatusMethodNotAllowed,
-			Field:      "method",
-			Value:      r.Method,
-		})
-	}
-
 	// JSON'u parse et
 	var req models.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		panic(&errors.ValidationError{
-			Message:    "Geçersiz JSON formatı",
-			StatusCode: http.StatusBadRequest,
-			Field:      "body",
-			Value:      err.Error(),
-		})
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(errors.NewInvalidJSONError(err))
 	}
 
 	//  YENİ VALİDASYON KONTROLÜ
@@ -128,15 +104,13 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Error().Err(err).Msg("Giriş başarısız")
 		panic(&errors.AuthError{
-			Message:    err.Error(),
+			Message:    errors.SafeMessage(err),
 			StatusCode: http.StatusUnauthorized,
 		})
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
+	utils.WriteJSON(w, http.StatusOK, user)
 
 	log.Info().
 		Str("email", user.User.Email).
@@ -146,16 +120,6 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 // GetProfile kullanıcının kendi profilini döner (protected endpoint)
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	// Sadece GET metoduna izin ver
-	if r.Method != http.MethodGet {
-		panic(&errors.ValidationError{
-			Message:    "Sadece GET metoduna izin verilir",
-			StatusCode: http.StatusMethodNotAllowed,
-			Field:      "method",
-			Value:      r.Method,
-		})
-	}
-
 	// Context'ten user bilgilerini al
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -178,44 +142,42 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
+	utils.WriteJSON(w, http.StatusOK, user)
 
 	log.Info().Int("user_id", claims.UserID).Msg("Profil bilgileri getirildi")
 }
 
-// Refresh JWT token yenileme endpoint'i
+// Refresh refresh token ile yeni access token üreten endpoint (access token henüz geçerliyken de çalışır)
 func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.Header().Set("Allow", http.MethodPost)
-		panic(&errors.ValidationError{
-			Message:    "Sadece POST metoduna izin verilir",
-			StatusCode: http.StatusMethodNotAllowed,
-			Field:      "method",
-			Value:      r.Method,
-		})
-	}
-
 	var req struct {
-		Token string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+		Token        string `json:"token"` // Eski client'lar için; refresh token olmalı
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		panic(&errors.ValidationError{
-			Message:    "Geçersiz JSON formatı",
-			StatusCode: http.StatusBadRequest,
-			Field:      "body",
-			Value:      err.Error(),
-		})
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(errors.NewInvalidJSONError(err))
+	}
+
+	refreshToken := req.RefreshToken
+	if refreshToken == "" {
+		refreshToken = req.Token
 	}
 
-	newToken, expiresIn, err := auth.RefreshToken(req.Token)
+	newToken, expiresIn, err := auth.RefreshToken(refreshToken)
 	if err != nil {
 		log.Error().Err(err).Msg("Token refresh başarısız")
-		panic(&errors.AuthError{
-			Message:    err.Error(),
+		authErr := &errors.AuthError{
+			Message:    errors.SafeMessage(err),
 			StatusCode: http.StatusUnauthorized,
-		})
+		}
+		// Mutlak oturum ömrü dolduysa client'ın tekrar login'e yönlendirebilmesi için kod eklenir
+		if stderrors.Is(err, auth.ErrSessionExpired) {
+			authErr.Code = errors.AuthCodeSessionExpired
+		}
+		// Access token gönderildiyse client login yanıtındaki refresh token'ı kullanmalı
+		if stderrors.Is(err, auth.ErrNotRefreshToken) {
+			authErr.Code = errors.AuthCodeWrongTokenType
+		}
+		panic(authErr)
 	}
 
 	response := models.RefreshResponse{
@@ -225,23 +187,51 @@ func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		Message:   "Token başarıyla yenilendi",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 }
 
-// GetAllUsers tüm kullanıcıları listeler (protected endpoint)
-func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	// Sadece GET metoduna izin ver
-	if r.Method != http.MethodGet {
-		panic(&errors.ValidationError{
-			Message:    "Sadece GET metoduna izin verilir",
-			StatusCode: http.StatusMethodNotAllowed,
-			Field:      "method",
-			Value:      r.Method,
+// Logout mevcut access token'ı (ve gönderildiyse refresh token'ı) süresi dolmadan geçersiz kılar
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		panic(&errors.AuthError{
+			Message:    "Yetkilendirme hatası",
+			StatusCode: http.StatusUnauthorized,
 		})
 	}
 
+	// Body opsiyonel: refresh token gönderilirse o da iptal edilir
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil && !stderrors.Is(err, io.EOF) {
+		panic(errors.NewInvalidJSONError(err))
+	}
+
+	if req.RefreshToken != "" {
+		if err := auth.RevokeRefreshToken(req.RefreshToken, claims.UserID); err != nil {
+			log.Warn().Err(err).Int("user_id", claims.UserID).Msg("Logout'ta refresh token iptal edilemedi")
+			panic(&errors.ValidationError{
+				Message:    errors.SafeMessage(err),
+				StatusCode: http.StatusBadRequest,
+				Field:      "refresh_token",
+			})
+		}
+	}
+
+	auth.RevokeToken(claims)
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Çıkış yapıldı",
+	})
+
+	log.Info().Int("user_id", claims.UserID).Msg("Kullanıcı çıkış yaptı")
+}
+
+// GetAllUsers tüm kullanıcıları listeler (protected endpoint)
+func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	// Context'ten user bilgilerini al (authentication kontrolü)
 	_, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
 	if !ok {
@@ -299,9 +289,7 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Başarılı yanıt
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("total_count", totalCount).
@@ -357,16 +345,15 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// Başarılı yanıt
+	// Başarılı yanıt (ETag, koşullu güncellemede If-Match ile geri gönderilir)
 	response := map[string]interface{}{
 		"success": true,
 		"data":    user,
 		"message": "Kullanıcı başarıyla getirildi",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	w.Header().Set("ETag", models.UserETag(user.Version))
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().Int("user_id", userID).Msg("Kullanıcı detayı getirildi")
 }
@@ -407,13 +394,8 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	// JSON'u parse et
 	var req models.UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		panic(&errors.ValidationError{
-			Message:    "Geçersiz JSON formatı",
-			StatusCode: http.StatusBadRequest,
-			Field:      "body",
-			Value:      err.Error(),
-		})
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(errors.NewInvalidJSONError(err))
 	}
 
 	//  YENİ VALİDASYON KONTROLÜ
@@ -430,6 +412,20 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	// If-Match: okunan versiyon değiştiyse güncelleme 412 ile reddedilir ("*" koşulsuz)
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" {
+		version, err := models.ParseUserETag(ifMatch)
+		if err != nil {
+			panic(&errors.ValidationError{
+				Message:    "Geçersiz If-Match header'ı",
+				StatusCode: http.StatusBadRequest,
+				Field:      "If-Match",
+				Value:      ifMatch,
+			})
+		}
+		req.ExpectedVersion = &version
+	}
+
 	// Authorization: Sadece kendi hesabını güncelleyebilir (RBAC middleware'de kontrol edilir)
 	if claims.UserID != targetUserID {
 		log.Warn().
@@ -446,10 +442,19 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	// Güncelleme işlemini yap
 	updatedUser, err := h.userService.UpdateUser(targetUserID, &req)
+	if stderrors.Is(err, models.ErrUserVersionConflict) {
+		log.Warn().Int("user_id", targetUserID).Int("expected_version", *req.ExpectedVersion).Msg("Kullanıcı güncellemesi versiyon çakışması")
+		panic(&errors.ValidationError{
+			Message:    "Kullanıcı siz okuduktan sonra güncellenmiş, güncel halini alıp tekrar deneyin",
+			StatusCode: http.StatusPreconditionFailed,
+			Field:      "If-Match",
+			Value:      r.Header.Get("If-Match"),
+		})
+	}
 	if err != nil {
 		log.Error().Err(err).Int("user_id", targetUserID).Msg("Kullanıcı güncellenemedi")
 		panic(&errors.ValidationError{
-			Message:    err.Error(),
+			Message:    errors.SafeMessage(err),
 			StatusCode: http.StatusBadRequest,
 			Field:      "update",
 			Value:      targetUserID,
@@ -463,9 +468,8 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		"message": "Kullanıcı başarıyla güncellendi",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	w.Header().Set("ETag", models.UserETag(updatedUser.Version))
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("user_id", targetUserID).
@@ -526,7 +530,7 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Error().Err(err).Int("user_id", targetUserID).Msg("Kullanıcı silinemedi")
 		panic(&errors.ValidationError{
-			Message:    err.Error(),
+			Message:    errors.SafeMessage(err),
 			StatusCode: http.StatusBadRequest,
 			Field:      "delete",
 			Value:      targetUserID,
@@ -539,9 +543,7 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		"message": "Kullanıcı başarıyla silindi",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("user_id", targetUserID).
@@ -549,6 +551,50 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		Msg(" Kullanıcı silindi (soft delete)")
 }
 
+// CloseAccount kullanıcının kendi hesabını kapatma endpoint'i (protected)
+func (h *UserHandler) CloseAccount(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		panic(&errors.AuthError{
+			Message:    "Yetkilendirme hatası",
+			StatusCode: http.StatusUnauthorized,
+		})
+	}
+
+	// JSON'u parse et (boş body: aktarım hesabı yok)
+	var req models.CloseAccountRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil && !stderrors.Is(err, io.EOF) {
+		panic(errors.NewInvalidJSONError(err))
+	}
+
+	// Hesabı kapat
+	result, err := h.accountService.CloseAccount(claims.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Hesap kapatılamadı")
+		panic(&errors.ValidationError{
+			Message:    errors.SafeMessage(err),
+			StatusCode: http.StatusBadRequest,
+			Field:      "close_account",
+			Value:      claims.UserID,
+		})
+	}
+
+	// Başarılı yanıt
+	response := map[string]interface{}{
+		"success": true,
+		"data":    result,
+		"message": "Hesap başarıyla kapatıldı",
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+
+	log.Info().
+		Int("user_id", claims.UserID).
+		Float64("transferred_amount", result.TransferredAmount).
+		Msg(" Kullanıcı hesabını kapattı")
+}
+
 // PromoteToMod kullanıcıyı moderator yapma endpoint'i (sadece admin)
 func (h *UserHandler) PromoteToMod(w http.ResponseWriter, r *http.Request) {
 	// Context'ten admin user bilgilerini al
@@ -588,7 +634,7 @@ func (h *UserHandler) PromoteToMod(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Error().Err(err).Int("target_user_id", targetUserID).Msg("Moderator promotion başarısız")
 		panic(&errors.ValidationError{
-			Message:    err.Error(),
+			Message:    errors.SafeMessage(err),
 			StatusCode: http.StatusBadRequest,
 			Field:      "promotion",
 			Value:      targetUserID,
@@ -605,9 +651,7 @@ func (h *UserHandler) PromoteToMod(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("admin_user_id", claims.UserID).
@@ -654,7 +698,7 @@ func (h *UserHandler) DemoteUser(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Error().Err(err).Int("target_user_id", targetUserID).Msg("User demotion başarısız")
 		panic(&errors.ValidationError{
-			Message:    err.Error(),
+			Message:    errors.SafeMessage(err),
 			StatusCode: http.StatusBadRequest,
 			Field:      "demotion",
 			Value:      targetUserID,
@@ -671,12 +715,69 @@ func (h *UserHandler) DemoteUser(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	utils.WriteJSON(w, http.StatusOK, response)
 
 	log.Info().
 		Int("admin_user_id", claims.UserID).
 		Int("target_user_id", targetUserID).
 		Msg("Kullanıcı user yapıldı")
 }
+
+// GetNotificationPreferences kullanıcının bildirim tercihlerini döner (kayıt yoksa varsayılanlar)
+func (h *UserHandler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		panic(&errors.AuthError{
+			Message:    "Yetkilendirme hatası",
+			StatusCode: http.StatusUnauthorized,
+		})
+	}
+
+	preferences, err := h.notificationService.GetPreferences(claims.UserID)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Bildirim tercihleri alınamadı")
+		http.Error(w, "Bildirim tercihleri alınamadı. Lütfen tekrar deneyin.", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    preferences,
+	})
+}
+
+// UpdateNotificationPreferences kullanıcının bildirim tercihlerini günceller (gönderilmeyen alanlar değişmez)
+func (h *UserHandler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	// Context'ten user bilgilerini al
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*auth.Claims)
+	if !ok {
+		panic(&errors.AuthError{
+			Message:    "Yetkilendirme hatası",
+			StatusCode: http.StatusUnauthorized,
+		})
+	}
+
+	// JSON'u parse et
+	var req models.UpdateNotificationPreferencesRequest
+	if err := utils.DecodeJSONBody(r.Body, &req); err != nil {
+		panic(errors.NewInvalidJSONError(err))
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(claims.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Int("user_id", claims.UserID).Msg("Bildirim tercihleri güncellenemedi")
+		panic(&errors.ValidationError{
+			Message:    errors.SafeMessage(err),
+			StatusCode: http.StatusBadRequest,
+			Field:      "notifications",
+			Value:      claims.UserID,
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    preferences,
+		"message": "Bildirim tercihleri güncellendi",
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/onerilhan/go-payment-api/internal/auth"
+	"github.com/onerilhan/go-payment-api/internal/middleware"
+	apperrors "github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/repository"
+	"github.com/onerilhan/go-payment-api/internal/services"
+)
+
+// TestUserHandler_UpdateUser_StaleIfMatchReturns412, okunan versiyon değiştiyse güncellemenin 412 ile reddedildiğini test eder.
+func TestUserHandler_UpdateUser_StaleIfMatchReturns412(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// Diğer cihaz kullanıcıyı versiyon 3'e taşımış, istemci hâlâ 2'yi gönderiyor
+	dbMock.ExpectQuery("UPDATE users").
+		WithArgs("Yeni İsim", sqlmock.AnyArg(), 7, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}))
+	dbMock.ExpectQuery("SELECT EXISTS").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	userHandler := NewUserHandler(services.NewUserService(repository.NewUserRepository(database)), nil, nil)
+	handler := middleware.ErrorHandlingMiddleware(nil)(http.HandlerFunc(userHandler.UpdateUser))
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/7", strings.NewReader(`{"name":"Yeni İsim"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
+	req = mux.SetURLVars(req, map[string]string{"id": "7"})
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: 7, Email: "ali@example.com", Role: "user"}))
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestUserHandler_Logout_RevokesToken, login → logout sonrası aynı token'ın 401 revoked_token ile reddedildiğini test eder.
+func TestUserHandler_Logout_RevokesToken(t *testing.T) {
+	// Arrange
+	require.NoError(t, auth.Configure([]byte("handler-test-secret-0123456789abcdefgh")))
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Sifre123!"), bcrypt.MinCost)
+	require.NoError(t, err)
+	dbMock.ExpectQuery("SELECT (.+) FROM users").WithArgs("ali@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "password", "role", "created_at", "account_number"}).
+			AddRow(42, "Ali", "ali@example.com", string(hash), "user", time.Now(), nil))
+
+	userHandler := NewUserHandler(services.NewUserService(repository.NewUserRepository(database)), nil, nil)
+
+	router := mux.NewRouter()
+	router.Use(middleware.ErrorHandlingMiddlewareWithDefaults())
+	api := router.PathPrefix("/api/v1").Subrouter()
+	public := api.PathPrefix("/auth").Subrouter()
+	public.HandleFunc("/login", userHandler.Login).Methods("POST")
+	protected := api.NewRoute().Subrouter()
+	protected.Use(middleware.NewAuthMiddleware(middleware.DefaultAuthConfig()))
+	protected.HandleFunc("/auth/logout", userHandler.Logout).Methods("POST")
+	protected.HandleFunc("/users/profile", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	serve := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	loginRec := serve(http.MethodPost, "/api/v1/auth/login", "", `{"email":"ali@example.com","password":"Sifre123!"}`)
+	require.Equal(t, http.StatusOK, loginRec.Code, loginRec.Body.String())
+	var login struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	require.NoError(t, json.Unmarshal(loginRec.Body.Bytes(), &login))
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/users/profile", login.Token, "").Code)
+
+	// Act
+	logoutRec := serve(http.MethodPost, "/api/v1/auth/logout", login.Token, `{"refresh_token":"`+login.RefreshToken+`"}`)
+	afterLogout := serve(http.MethodGet, "/api/v1/users/profile", login.Token, "")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, logoutRec.Code, logoutRec.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, afterLogout.Code)
+	assert.Contains(t, afterLogout.Body.String(), "revoked_token")
+
+	_, _, refreshErr := auth.RefreshToken(login.RefreshToken)
+	assert.ErrorIs(t, refreshErr, auth.ErrTokenRevoked)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// serveRegister Register handler'ını verilen error config'iyle çalıştırır ve hata yanıtını döner
+func serveRegister(t *testing.T, config *apperrors.ErrorConfig, body string) (*httptest.ResponseRecorder, apperrors.ErrorResponse) {
+	t.Helper()
+
+	userHandler := NewUserHandler(services.NewUserService(repository.NewUserRepository(nil)), nil, nil)
+	handler := middleware.ErrorHandlingMiddleware(config)(http.HandlerFunc(userHandler.Register))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var response apperrors.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return rec, response
+}
+
+// TestUserHandler_Register_InvalidJSONDetailsInDevelopment, development'ta yarım kalmış JSON'un satır/sütun ve
+// body kesitiyle, tip uyuşmazlığının alan ve tip bilgisiyle döndüğünü; production'da ise details'in genel kaldığını test eder.
+func TestUserHandler_Register_InvalidJSONDetailsInDevelopment(t *testing.T) {
+	// Arrange
+	truncated := "{\n  \"name\": \"Ali\",\n  \"email\": \"ali@exa"
+	mismatch := `{"name": "Ali", "email": 42}`
+
+	// Act
+	truncatedRec, truncatedResp := serveRegister(t, apperrors.DevelopmentErrorConfig(), truncated)
+	_, mismatchResp := serveRegister(t, apperrors.DevelopmentErrorConfig(), mismatch)
+	_, prodResp := serveRegister(t, apperrors.ProductionErrorConfig(), truncated)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, truncatedRec.Code)
+	assert.Equal(t, "Geçersiz JSON formatı", truncatedResp.Error)
+	assert.Equal(t, apperrors.InvalidJSONCode, truncatedResp.ErrorCode)
+	assert.Equal(t, "unexpected_eof", truncatedResp.Details["json_error"])
+	assert.Equal(t, float64(len(truncated)), truncatedResp.Details["offset"])
+	assert.Equal(t, float64(3), truncatedResp.Details["line"])
+	assert.Equal(t, float64(20), truncatedResp.Details["column"])
+	assert.Equal(t, `{   "name": "Ali",   "email": "ali@exa`, truncatedResp.Details["snippet"])
+
+	assert.Equal(t, "type_mismatch", mismatchResp.Details["json_error"])
+	assert.Equal(t, "email", mismatchResp.Details["field"])
+	assert.Equal(t, "string", mismatchResp.Details["expected"])
+	assert.Equal(t, "number", mismatchResp.Details["actual"])
+	assert.Equal(t, float64(1), mismatchResp.Details["line"])
+
+	assert.Equal(t, "Geçersiz JSON formatı", prodResp.Error)
+	assert.Equal(t, apperrors.InvalidJSONCode, prodResp.ErrorCode)
+	assert.NotContains(t, prodResp.Details, "json_error")
+	assert.NotContains(t, prodResp.Details, "snippet")
+}
@@ -0,0 +1,176 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// Readiness status değerleri
+const (
+	StatusReady        = "ready"
+	StatusDegraded     = "degraded"
+	StatusShuttingDown = "shutting_down"
+)
+
+// QueueStatsFunc kuyrukta bekleyen iş sayısını ve kapasiteyi döner
+type QueueStatsFunc func() (length, capacity int)
+
+// PingFunc bağımlılığa (DB) ping atar
+type PingFunc func(ctx context.Context) error
+
+// DegradationConfig readiness'ın degraded raporlayacağı eşikler.
+// Degraded instance 200 döner; orkestrasyon trafiği kaydırabilir ama instance rotasyondan çıkmaz.
+type DegradationConfig struct {
+	QueueSaturation    float64       // Kuyruk doluluk oranı bu değer ve üzerindeyse degraded (0 = kontrol kapalı)
+	DBLatencyThreshold time.Duration // DB ping süresi bu değeri aşarsa degraded (0 = kontrol kapalı)
+	PingTimeout        time.Duration // Ping için üst süre
+}
+
+// DefaultDegradationConfig varsayılan eşikler: %80 kuyruk doluluğu, 500ms DB gecikmesi
+func DefaultDegradationConfig() *DegradationConfig {
+	return &DegradationConfig{
+		QueueSaturation:    0.8,
+		DBLatencyThreshold: 500 * time.Millisecond,
+		PingTimeout:        2 * time.Second,
+	}
+}
+
+// Readiness instance'ın trafik almaya hazır olup olmadığını tutar.
+// Shutdown başladığında önce readiness düşürülür ki load balancer instance'ı rotasyondan çıkarsın.
+type Readiness struct {
+	shuttingDown atomic.Bool
+
+	degradation *DegradationConfig
+	queueStats  QueueStatsFunc
+	ping        PingFunc
+}
+
+// NewReadiness hazır durumda yeni bir Readiness oluşturur
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// IsReady instance trafik almaya hazır mı
+func (r *Readiness) IsReady() bool {
+	return !r.shuttingDown.Load()
+}
+
+// SetDegradationChecks degraded durumunu hesaplamak için eşikleri ve kaynakları ayarlar (nil kaynak = o kontrol yapılmaz)
+func (r *Readiness) SetDegradationChecks(config *DegradationConfig, queueStats QueueStatsFunc, ping PingFunc) {
+	r.degradation = config
+	r.queueStats = queueStats
+	r.ping = ping
+}
+
+// BeginShutdown readiness'ı hemen düşürür ve drainDelay kadar (veya ctx iptal edilene kadar) bekler.
+// Bu sürede server istek kabul etmeye devam eder; load balancer'ın instance'ı kayıttan çıkarması beklenir.
+func (r *Readiness) BeginShutdown(ctx context.Context, drainDelay time.Duration) {
+	r.shuttingDown.Store(true)
+
+	if drainDelay <= 0 {
+		return
+	}
+
+	log.Info().
+		Dur("drain_delay", drainDelay).
+		Msg("Readiness düşürüldü, load balancer'ın trafiği kesmesi bekleniyor")
+
+	timer := time.NewTimer(drainDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		log.Warn().Msg("Drain beklemesi erken sonlandırıldı")
+	}
+}
+
+// Handler readiness endpoint'i: hazırsa 200 (kuyruk/DB eşik aşımında status degraded), shutdown başladıysa 503 döner
+func (r *Readiness) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !r.IsReady() {
+			if req.Method == http.MethodHead {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			utils.WriteJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"status":    StatusShuttingDown,
+				"timestamp": time.Now().Format(time.RFC3339),
+			})
+			return
+		}
+
+		// HEAD isteğinde body yazma (degradation kontrolü ping gerektirdiği için atlanır)
+		if req.Method == http.MethodHead {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":    StatusReady,
+			"timestamp": time.Now().Format(time.RFC3339),
+		}
+		if reasons := r.degradationReasons(req.Context(), response); len(reasons) > 0 {
+			response["status"] = StatusDegraded
+			response["reasons"] = reasons
+		}
+
+		utils.WriteJSON(w, http.StatusOK, response)
+	}
+}
+
+// degradationReasons kuyruk doluluğunu ve DB ping süresini eşiklerle karşılaştırır,
+// ölçümleri response'a ekler ve aşılan eşiklerin sebeplerini döner (boş = degraded değil)
+func (r *Readiness) degradationReasons(ctx context.Context, response map[string]interface{}) []string {
+	config := r.degradation
+	if config == nil {
+		return nil
+	}
+
+	var reasons []string
+
+	if config.QueueSaturation > 0 && r.queueStats != nil {
+		length, capacity := r.queueStats()
+		if capacity > 0 {
+			saturation := float64(length) / float64(capacity)
+			response["queue"] = map[string]interface{}{
+				"length":     length,
+				"capacity":   capacity,
+				"saturation": saturation,
+			}
+			if saturation >= config.QueueSaturation {
+				reasons = append(reasons, fmt.Sprintf("transaction kuyruğu %%%.0f dolu", saturation*100))
+			}
+		}
+	}
+
+	if config.DBLatencyThreshold > 0 && r.ping != nil {
+		timeout := config.PingTimeout
+		if timeout <= 0 {
+			timeout = 2 * time.Second
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		start := time.Now()
+		err := r.ping(pingCtx)
+		latency := time.Since(start)
+		response["db_latency_ms"] = latency.Milliseconds()
+
+		switch {
+		case err != nil:
+			reasons = append(reasons, "DB ping başarısız")
+		case latency > config.DBLatencyThreshold:
+			reasons = append(reasons, fmt.Sprintf("DB ping süresi %dms (eşik %dms)", latency.Milliseconds(), config.DBLatencyThreshold.Milliseconds()))
+		}
+	}
+
+	return reasons
+}
@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReadiness_NotReadyOnceShutdownBegins, shutdown başlar başlamaz (drain beklenirken) readiness'ın düştüğünü test eder.
+func TestReadiness_NotReadyOnceShutdownBegins(t *testing.T) {
+	// Arrange
+	readiness := NewReadiness()
+	handler := readiness.Handler()
+
+	before := httptest.NewRecorder()
+	handler(before, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		readiness.BeginShutdown(ctx, time.Minute)
+	}()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, before.Code)
+	assert.Eventually(t, func() bool { return !readiness.IsReady() }, time.Second, time.Millisecond)
+
+	during := httptest.NewRecorder()
+	handler(during, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, during.Code)
+	assert.Contains(t, during.Body.String(), StatusShuttingDown)
+
+	// Drain beklemesi hâlâ sürüyor olmalı; ctx iptali beklemeyi bitirir
+	select {
+	case <-drained:
+		t.Fatal("drain beklemesi erken bitti")
+	default:
+	}
+	cancel()
+	<-drained
+}
+
+// TestReadiness_ZeroDelayReturnsImmediately, drain süresi 0 iken beklemeden dönüldüğünü test eder.
+func TestReadiness_ZeroDelayReturnsImmediately(t *testing.T) {
+	// Arrange
+	readiness := NewReadiness()
+
+	// Act
+	start := time.Now()
+	readiness.BeginShutdown(context.Background(), 0)
+
+	// Assert
+	assert.False(t, readiness.IsReady())
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+// TestReadiness_NearFullQueueIsDegraded, kuyruk eşik üzerinde doluyken readiness'ın 200 ile degraded döndüğünü test eder.
+func TestReadiness_NearFullQueueIsDegraded(t *testing.T) {
+	// Arrange
+	readiness := NewReadiness()
+	queueLength := 45
+	readiness.SetDegradationChecks(DefaultDegradationConfig(),
+		func() (int, int) { return queueLength, 50 },
+		func(ctx context.Context) error { return nil },
+	)
+	handler := readiness.Handler()
+
+	// Act
+	degraded := httptest.NewRecorder()
+	handler(degraded, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	queueLength = 10
+	healthy := httptest.NewRecorder()
+	handler(healthy, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, degraded.Code)
+	assert.Contains(t, degraded.Body.String(), `"status":"degraded"`)
+	assert.Contains(t, degraded.Body.String(), "transaction kuyruğu %90 dolu")
+	assert.Equal(t, http.StatusOK, healthy.Code)
+	assert.Contains(t, healthy.Body.String(), `"status":"ready"`)
+}
+
+// TestReadiness_SlowDBIsDegraded, DB ping süresi eşiği aştığında readiness'ın degraded döndüğünü test eder.
+func TestReadiness_SlowDBIsDegraded(t *testing.T) {
+	// Arrange
+	readiness := NewReadiness()
+	config := DefaultDegradationConfig()
+	config.DBLatencyThreshold = 10 * time.Millisecond
+	readiness.SetDegradationChecks(config, nil, func(ctx context.Context) error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+
+	// Act
+	rec := httptest.NewRecorder()
+	readiness.Handler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"degraded"`)
+}
@@ -2,6 +2,7 @@
 package interfaces
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/onerilhan/go-payment-api/internal/models"
@@ -18,6 +19,9 @@ type UserRepositoryInterface interface {
 	// GetByID ID ile kullanıcı bulur
 	GetByID(id int) (*models.User, error)
 
+	// GetByAccountNumber hesap numarası ile kullanıcı bulur
+	GetByAccountNumber(accountNumber string) (*models.User, error)
+
 	// Update kullanıcı bilgilerini günceller
 	Update(id int, user *models.UpdateUserRequest) (*models.User, error)
 
@@ -26,6 +30,12 @@ type UserRepositoryInterface interface {
 
 	// GetAll tüm kullanıcıları listeler (pagination ile)
 	GetAll(limit, offset int) ([]*models.User, int, error) // users, total_count, error
+
+	// CountByRole belirli role sahip (silinmemiş) kullanıcı sayısını döner
+	CountByRole(role string) (int, error)
+
+	// IsActive kullanıcının var olup silinmemiş olduğunu döner
+	IsActive(id int) (bool, error)
 }
 
 // TransactionRepositoryInterface transaction database işlemleri için interface
@@ -36,12 +46,32 @@ type TransactionRepositoryInterface interface {
 	// GetByID ID ile transaction getirir
 	GetByID(id int) (*models.Transaction, error)
 
-	// GetByUserID kullanıcının transaction'larını getirir
-	GetByUserID(userID int, limit, offset int) ([]*models.Transaction, error)
+	// GetByUserID kullanıcının transaction'larını verilen yönde (asc|desc) getirir
+	GetByUserID(userID int, limit, offset int, sort string) ([]*models.Transaction, error)
+	// GetByUserIDFiltered kullanıcının transaction'larını tip, status ve tarih filtreleriyle getirir
+	GetByUserIDFiltered(userID int, filter *models.TransactionHistoryFilter, limit, offset int) ([]*models.Transaction, error)
 
 	// GetByStatus belirli status'taki transaction'ları getirir
 	GetByStatus(status string, limit, offset int) ([]*models.Transaction, error)
 
+	// StreamByDateRange tarih aralığındaki transaction'ları satır satır callback'e iletir
+	StreamByDateRange(from, to time.Time, fn func(*models.Transaction) error) error
+
+	// CountUserTransactionsSince kullanıcının belirli andan beri başlattığı transaction sayısını döner
+	CountUserTransactionsSince(userID int, since time.Time) (int, error)
+	// GetUserUsageSince kullanıcının belirli andan beri işlem sayısını ve gönderdiği toplam tutarı döner
+	GetUserUsageSince(userID int, since time.Time) (*models.TransactionUsage, error)
+	// CountTransfersWithDescriptionSince göndericinin alıcıya belirli andan beri yaptığı açıklamalı transfer sayısını döner
+	CountTransfersWithDescriptionSince(fromUserID, toUserID int, since time.Time) (int, error)
+	// GetTransferAmountHistorySince kullanıcının belirli andan beri tamamlanmış giden transferlerinin sayı/ortalama/max değerlerini döner
+	GetTransferAmountHistorySince(userID int, since time.Time) (*models.TransferAmountHistory, error)
+	// CountCompletedTransfersBetween göndericiden alıcıya tamamlanmış transfer sayısını döner
+	CountCompletedTransfersBetween(fromUserID, toUserID int) (int, error)
+	// GetTransactionReviews verilen status'taki fraud inceleme kayıtlarını transaction'larıyla birlikte getirir
+	GetTransactionReviews(status string, limit, offset int) ([]*models.TransactionReview, error)
+	// GetCounterpartySummary kullanıcının karşı taraflarını (maskeli) gönderilen/alınan tutar ve işlem sayısıyla, hacme göre sıralı döner
+	GetCounterpartySummary(userID int, from, to time.Time, limit int) ([]*models.CounterpartySummary, error)
+
 	// UpdateStatus transaction status'unu günceller
 	UpdateStatus(id int, status string) error
 
@@ -60,14 +90,101 @@ type BalanceRepositoryInterface interface {
 	// UpdateBalance kullanıcının bakiyesini günceller
 	UpdateBalance(userID int, newAmount float64) error
 
-	// GetBalanceHistory kullanıcının bakiye geçmişini getirir
-	GetBalanceHistory(userID int, limit, offset int) ([]*models.BalanceHistory, error)
+	// AdjustBalance bakiyeyi atomik olarak delta kadar değiştirir, yeni bakiyeyi döner (negatife düşürmez)
+	AdjustBalance(userID int, delta float64) (float64, error)
+
+	// AdjustBalanceWithReference bakiyeyi referansla idempotent olarak değiştirir (tekrar referansta ilk kayıt, applied=false)
+	AdjustBalanceWithReference(userID int, delta float64, reference string) (*models.BalanceHistory, bool, error)
+
+	// GetBalanceHistory kullanıcının bakiye geçmişini verilen yönde (asc|desc) getirir
+	GetBalanceHistory(userID int, limit, offset int, sort string) ([]*models.BalanceHistory, error)
 
 	// CreateBalanceSnapshot belirli bir anda bakiye snapshot'ı oluşturur
 	CreateBalanceSnapshot(userID int, amount float64, reason string) error
 
+	// CreateBalanceSnapshots asOf anındaki bakiyeleri (değişen kullanıcılar için) snapshot olarak yazar
+	CreateBalanceSnapshots(asOf time.Time, reason string) (int, error)
+
 	// Belirli bir zamandaki bakiyeyi getirir.
 	GetBalanceAtTime(userID int, atTime time.Time) (*models.BalanceAtTime, error)
+
+	// GetPendingOutgoingAmount kullanıcının pending durumdaki giden işlemlerinin toplamını döner
+	GetPendingOutgoingAmount(userID int) (float64, error)
+
+	// GetActiveHoldAmount kullanıcının aktif (capture/void edilmemiş) hold'larının toplamını döner
+	GetActiveHoldAmount(userID int) (float64, error)
+
+	// GetMinimumBalance kullanıcının belirlediği minimum bakiyeyi döner (yoksa 0)
+	GetMinimumBalance(userID int) (float64, error)
+
+	// SetMinimumBalance kullanıcının minimum bakiyesini kaydeder
+	SetMinimumBalance(userID int, minimum float64) error
+
+	// ArchiveBalanceHistory eski geçmişi arşive taşır, kullanıcı başına baseline bırakır
+	ArchiveBalanceHistory(before time.Time) (*models.BalanceArchiveResult, error)
+
+	// GetLedgerBalances afterUserID'den sonraki en fazla limit kullanıcının bakiyesini ledger toplamıyla birlikte döner
+	GetLedgerBalances(afterUserID, limit int) ([]*models.LedgerBalance, error)
+}
+
+// ExchangeRateRepositoryInterface kur tablosu database işlemleri için interface
+type ExchangeRateRepositoryInterface interface {
+	// GetRate iki para birimi arasındaki kuru getirir
+	GetRate(baseCurrency, quoteCurrency string) (*models.ExchangeRate, error)
+
+	// UpsertRate kuru ekler veya günceller
+	UpsertRate(baseCurrency, quoteCurrency string, rate float64) error
+}
+
+// NotificationPreferenceRepositoryInterface bildirim tercihleri database işlemleri için interface
+type NotificationPreferenceRepositoryInterface interface {
+	// GetByUserID kullanıcının kayıtlı tercihlerini getirir (kayıt yoksa nil, nil)
+	GetByUserID(userID int) (*models.NotificationPreferences, error)
+
+	// Upsert tercihleri ekler veya günceller
+	Upsert(preferences *models.NotificationPreferences) (*models.NotificationPreferences, error)
+}
+
+// IdempotencyRepositoryInterface Idempotency-Key kayıtları için interface
+type IdempotencyRepositoryInterface interface {
+	// Reserve key'i in_progress olarak kaydeder; key zaten varsa mevcut kaydı ve false döner.
+	// expiredBefore'dan eski kayıtlar yok sayılır (saklama süresi dolan key tekrar kullanılabilir).
+	Reserve(record *models.IdempotencyRecord, expiredBefore time.Time) (*models.IdempotencyRecord, bool, error)
+
+	// Complete kaydı ilk isteğin sonucuyla, işlemi yazan DB transaction'ı içinde tamamlar (commit ile atomik)
+	Complete(tx *sql.Tx, id int, transactionID int, newBalance float64) error
+
+	// Release başarısız isteğin kaydını siler (aynı key ile tekrar denenebilir)
+	Release(id int) error
+
+	// DeleteExpired verilen andan eski kayıtları siler, silinen sayısını döner
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+// ScheduledTransferRepositoryInterface ileri tarihli transfer kayıtları için interface
+type ScheduledTransferRepositoryInterface interface {
+	// Create transferi scheduled status'u ile kaydeder; maxActive > 0 ise kullanıcının aktif kayıt sayısı
+	// sınırdayken kaydetmez ve nil, nil döner (sayım ve kayıt atomiktir)
+	Create(transfer *models.ScheduledTransfer, maxActive int) (*models.ScheduledTransfer, error)
+
+	// GetByID kaydı getirir (yoksa nil, nil)
+	GetByID(id int) (*models.ScheduledTransfer, error)
+
+	// ClaimDue zamanı gelmiş (veya staleBefore'dan beri processing'de takılı kalmış) en fazla limit kaydı
+	// processing olarak işaretleyip döner; birden fazla instance aynı kaydı almaz
+	ClaimDue(now, staleBefore time.Time, limit int) ([]*models.ScheduledTransfer, error)
+
+	// Reschedule processing'deki kaydı tekrar scheduled yapar (queue doluysa sonraki turda denenir)
+	Reschedule(id int) error
+
+	// MarkCompleted kaydı oluşan transferle tamamlar
+	MarkCompleted(id int, transactionID int) error
+
+	// MarkFailed kaydı hata mesajıyla failed yapar
+	MarkFailed(id int, lastError string) error
+
+	// Cancel scheduled durumdaki kaydı iptal eder; kayıt artık scheduled değilse false döner
+	Cancel(id int) (bool, error)
 }
 
 // AuditRepositoryInterface audit log database işlemleri için interface
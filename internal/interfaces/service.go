@@ -1,7 +1,12 @@
 // internal/interfaces/service.go
 package interfaces
 
-import "github.com/onerilhan/go-payment-api/internal/models"
+import (
+	"context"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
 
 // UserServiceInterface kullanıcı business logic için interface
 type UserServiceInterface interface {
@@ -27,16 +32,23 @@ type UserServiceInterface interface {
 // TransactionServiceInterface transaction business logic için interface
 type TransactionServiceInterface interface {
 	// Transfer kullanıcılar arası para transferi yapar
-	Transfer(fromUserID int, req *models.TransferRequest) (*models.Transaction, error)
+	Transfer(ctx context.Context, fromUserID int, req *models.TransferRequest) (*models.Transaction, error)
+
+	// BatchTransfer birden fazla alıcıya toplu transfer yapar (atomic | best_effort)
+	BatchTransfer(ctx context.Context, fromUserID int, req *models.BatchTransferRequest) (*models.BatchTransferResult, error)
 
-	// Credit kullanıcının hesabına para yatırır
-	Credit(userID int, req *models.CreditRequest) (*models.Transaction, error)
+	// Credit kullanıcının hesabına para yatırır, işlem sonrası bakiyeyi de döner
+	Credit(ctx context.Context, userID int, req *models.CreditRequest) (*models.Transaction, float64, error)
 
-	// Debit kullanıcının hesabından para çeker
-	Debit(userID int, req *models.DebitRequest) (*models.Transaction, error)
+	// Debit kullanıcının hesabından para çeker, işlem sonrası bakiyeyi de döner
+	Debit(ctx context.Context, userID int, req *models.DebitRequest) (*models.Transaction, float64, error)
 
 	// GetUserTransactions kullanıcının transaction geçmişini getirir
-	GetUserTransactions(userID int, limit, offset int) ([]*models.Transaction, error)
+	GetUserTransactions(userID int, limit, offset int, sort string) ([]*models.Transaction, error)
+	// GetUserTransactionsFiltered kullanıcının transaction geçmişini filtreleyerek getirir
+	GetUserTransactionsFiltered(userID int, filter *models.TransactionHistoryFilter, limit, offset int) ([]*models.Transaction, error)
+	// GetUserTransactionsPage kullanıcının transaction geçmişini cursor ile sayfalar
+	GetUserTransactionsPage(userID int, filter *models.TransactionHistoryFilter, limit int) (*models.TransactionPage, error)
 
 	// GetTransactionByID ID ile transaction getirir
 	GetTransactionByID(id int) (*models.Transaction, error)
@@ -44,6 +56,27 @@ type TransactionServiceInterface interface {
 	// GetTransactionStats kullanıcının transaction istatistiklerini getirir
 	GetTransactionStats(userID int) (*models.TransactionStats, error)
 
+	// GetCounterparties kullanıcının en çok işlem yaptığı karşı tarafları (maskeli) getirir
+	GetCounterparties(userID int, from, to time.Time, limit int) ([]*models.CounterpartySummary, error)
+
+	// GetTransactionLimits kullanıcının limitlerini ve bugün/bu ay tükettiği kısmı getirir
+	GetTransactionLimits(userID int) (*models.TransactionLimits, error)
+
+	// ListTransferReviews fraud incelemesindeki transferleri status'a göre listeler
+	ListTransferReviews(status string, limit, offset int) ([]*models.TransactionReview, error)
+
+	// ApproveTransferReview incelemedeki transferi onaylar ve gerçekleştirir
+	ApproveTransferReview(ctx context.Context, reviewID int, decision *models.ReviewDecision) (*models.TransactionReview, error)
+
+	// RejectTransferReview incelemedeki transferi reddeder (failed)
+	RejectTransferReview(ctx context.Context, reviewID int, decision *models.ReviewDecision) (*models.TransactionReview, error)
+
+	// ApproveHeldTransaction pending_review durumundaki transaction'ı ID'si ile onaylar ve gerçekleştirir
+	ApproveHeldTransaction(ctx context.Context, transactionID int, decision *models.ReviewDecision) (*models.TransactionReview, error)
+
+	// RejectHeldTransaction pending_review durumundaki transaction'ı ID'si ile reddeder (failed)
+	RejectHeldTransaction(ctx context.Context, transactionID int, decision *models.ReviewDecision) (*models.TransactionReview, error)
+
 	// ValidateTransactionType transaction type'ını doğrular
 	ValidateTransactionType(txType string) error
 
@@ -56,12 +89,27 @@ type BalanceServiceInterface interface {
 	// GetBalance thread-safe balance okuma
 	GetBalance(userID int) (*models.Balance, error)
 
+	// GetAvailableBalance kesinleşmiş bakiyeden aktif hold ve pending giden işlemler düşülmüş bakiyeyi getirir
+	GetAvailableBalance(userID int) (*models.AvailableBalance, error)
+
 	// UpdateBalance thread-safe balance güncelleme
 	UpdateBalance(userID int, amount float64) error
 
+	// AdjustBalance bakiyeyi atomik olarak delta kadar değiştirir, yeni bakiyeyi döner
+	AdjustBalance(userID int, delta float64) (float64, error)
+
+	// AdjustBalanceWithReference bakiyeyi referansla idempotent olarak değiştirir (tekrar referansta ilk kayıt, applied=false)
+	AdjustBalanceWithReference(userID int, delta float64, reference string) (*models.BalanceHistory, bool, error)
+
 	// GetBalanceHistory kullanıcının bakiye geçmişini getirir
-	GetBalanceHistory(userID int, limit, offset int) ([]*models.BalanceHistory, error)
+	GetBalanceHistory(userID int, limit, offset int, sort string) ([]*models.BalanceHistory, error)
 
 	// CreateBalanceSnapshot belirli bir anda bakiye snapshot'ı oluşturur
 	CreateBalanceSnapshot(userID int, amount float64, reason string) error
 }
+
+// ExchangeRateProvider para birimleri arası kur sağlayıcısı için interface
+type ExchangeRateProvider interface {
+	// Rate 1 birim from para biriminin to cinsinden değerini döner
+	Rate(from, to string) (float64, error)
+}
@@ -2,11 +2,15 @@ package middleware
 
 import (
 	"context"
+	stderrors "errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/onerilhan/go-payment-api/internal/auth"
 	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 	"github.com/rs/zerolog/log"
 )
 
@@ -15,72 +19,131 @@ type ContextKey string
 
 const UserContextKey ContextKey = "user"
 
-// AuthMiddleware JWT token kontrolü yapar (Gorilla Mux için middleware)
+// AuthConfig authentication middleware ayarları
+type AuthConfig struct {
+	// DetailedErrors token hatasının nedenini (expired_token, invalid_signature...) client'a bildirir.
+	// false ise header'ı olan tüm token hataları generic invalid_token olarak döner.
+	DetailedErrors bool
+}
+
+// DefaultAuthConfig varsayılan authentication ayarları
+func DefaultAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		DetailedErrors: true,
+	}
+}
+
+// AuthMiddleware JWT token kontrolü yapar (Gorilla Mux için middleware, varsayılan ayarlarla)
 func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Authorization header'ını al
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			log.Warn().
+	return NewAuthMiddleware(DefaultAuthConfig())(next)
+}
+
+// NewAuthMiddleware config ile JWT authentication middleware'i oluşturur.
+// Hatalar error middleware'in yakaladığı, makine tarafından okunabilir kodlu 401 AuthError olarak döner.
+func NewAuthMiddleware(config *AuthConfig) func(http.Handler) http.Handler {
+	// Config nil ise default kullan
+	if config == nil {
+		config = DefaultAuthConfig()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startedAt := time.Now()
+
+			// Authorization header'ını al
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				log.Warn().
+					Str("path", r.URL.Path).
+					Str("method", r.Method).
+					Msg("Authorization header eksik")
+
+				// Error middleware'in yakalayacağı şekilde panic at
+				panic(newAuthError(errors.AuthCodeMissingToken, "Authorization header gerekli"))
+			}
+
+			// "Bearer " prefix'ini kontrol et
+			tokenParts := strings.Split(authHeader, " ")
+			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" || tokenParts[1] == "" {
+				log.Warn().
+					Str("path", r.URL.Path).
+					Str("auth_header", maskAuthHeader(authHeader)).
+					Msg("Geçersiz Authorization format")
+
+				panic(config.tokenError(errors.AuthCodeMalformedToken, "Authorization format: 'Bearer <token>'"))
+			}
+
+			// Token'ı al
+			tokenString := tokenParts[1]
+
+			// Token'ı doğrula
+			claims, err := auth.ValidateToken(tokenString)
+			if err != nil {
+				code, message := classifyTokenError(err)
+
+				log.Warn().
+					Err(err).
+					Str("path", r.URL.Path).
+					Str("error_code", code).
+					Msg("Token doğrulama başarısız")
+
+				panic(config.tokenError(code, message))
+			}
+
+			// User bilgilerini ve işlemi başlatan kanalı context'e ekle
+			ctx := context.WithValue(r.Context(), UserContextKey, claims)
+			ctx = utils.WithChannel(ctx, RequestChannel(r, claims))
+			r = r.WithContext(ctx)
+
+			log.Debug().
+				Int("user_id", claims.UserID).
+				Str("email", claims.Email).
+				Str("role", claims.Role).
 				Str("path", r.URL.Path).
 				Str("method", r.Method).
-				Msg("Authorization header eksik")
-
-			// Error middleware'in yakalayacağı şekilde panic at
-			panic(&errors.AuthError{
-				Message:    "Authorization header gerekli",
-				StatusCode: http.StatusUnauthorized,
-			})
-		}
-
-		// "Bearer " prefix'ini kontrol et
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			log.Warn().
-				Str("path", r.URL.Path).
-				Str("auth_header", maskAuthHeader(authHeader)).
-				Msg("Geçersiz Authorization format")
-
-			// Error middleware'in yakalayacağı şekilde panic at
-			panic(&errors.AuthError{
-				Message:    "Authorization format: 'Bearer <token>'",
-				StatusCode: http.StatusUnauthorized,
-			})
-		}
-
-		// Token'ı al
-		tokenString := tokenParts[1]
-
-		// Token'ı doğrula
-		claims, err := auth.ValidateToken(tokenString)
-		if err != nil {
-			log.Warn().
-				Err(err).
-				Str("path", r.URL.Path).
-				Msg("Token doğrulama başarısız")
-
-			// Error middleware'in yakalayacağı şekilde panic at
-			panic(&errors.AuthError{
-				Message:    "Geçersiz token",
-				StatusCode: http.StatusUnauthorized,
-			})
-		}
-
-		// User bilgilerini context'e ekle
-		ctx := context.WithValue(r.Context(), UserContextKey, claims)
-		r = r.WithContext(ctx)
-
-		log.Debug().
-			Int("user_id", claims.UserID).
-			Str("email", claims.Email).
-			Str("role", claims.Role).
-			Str("path", r.URL.Path).
-			Str("method", r.Method).
-			Msg("Authentication successful")
-
-		// Sonraki handler'a geç
-		next.ServeHTTP(w, r)
-	})
+				Msg("Authentication successful")
+
+			utils.RecordTiming(ctx, utils.TimingAuth, time.Since(startedAt))
+
+			// Sonraki handler'a geç
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// classifyTokenError token doğrulama hatasını hata koduna ve kullanıcı mesajına çevirir
+func classifyTokenError(err error) (code string, message string) {
+	switch {
+	case stderrors.Is(err, jwt.ErrTokenExpired):
+		return errors.AuthCodeExpiredToken, "Token süresi dolmuş"
+	case stderrors.Is(err, jwt.ErrTokenSignatureInvalid), stderrors.Is(err, jwt.ErrTokenUnverifiable):
+		return errors.AuthCodeInvalidSignature, "Token imzası geçersiz"
+	case stderrors.Is(err, jwt.ErrTokenMalformed):
+		return errors.AuthCodeMalformedToken, "Token formatı bozuk"
+	case stderrors.Is(err, auth.ErrTokenRevoked):
+		return errors.AuthCodeRevokedToken, "Token iptal edilmiş"
+	case stderrors.Is(err, auth.ErrNotAccessToken):
+		return errors.AuthCodeWrongTokenType, "Refresh token ile API isteği yapılamaz, access token kullanın"
+	default:
+		return errors.AuthCodeInvalidToken, "Geçersiz token"
+	}
+}
+
+// tokenError config'e göre detaylı ya da generic token hatası oluşturur
+func (c *AuthConfig) tokenError(code, message string) *errors.AuthError {
+	if !c.DetailedErrors {
+		return newAuthError(errors.AuthCodeInvalidToken, "Geçersiz token")
+	}
+	return newAuthError(code, message)
+}
+
+// newAuthError kodlu 401 AuthError oluşturur
+func newAuthError(code, message string) *errors.AuthError {
+	return &errors.AuthError{
+		Message:    message,
+		StatusCode: http.StatusUnauthorized,
+		Code:       code,
+	}
 }
 
 // maskAuthHeader auth header'ı log için maskler (security)
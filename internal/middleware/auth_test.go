@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/auth"
+	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// testJWTSecret auth paketinin token üretip doğrulayabilmesi için test secret'ı
+const testJWTSecret = "middleware-test-secret-0123456789abcdef"
+
+// serveWithAuth isteği error + auth middleware zincirinden geçirir
+func serveWithAuth(config *AuthConfig, authHeader string) (*httptest.ResponseRecorder, errors.ErrorResponse) {
+	handler := ErrorHandlingMiddlewareWithDefaults()(NewAuthMiddleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/profile", nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var response errors.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	return rec, response
+}
+
+// signToken verilen secret ve süre ile test token'ı imzalar
+func signToken(t *testing.T, secret string, expiresAt time.Time) string {
+	t.Helper()
+	claims := &auth.Claims{
+		UserID: 1,
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+// TestAuthMiddleware_ErrorCodes, eksik/geçersiz token senaryolarının doğru hata kodunu döndüğünü test eder.
+func TestAuthMiddleware_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		authHeader string
+		wantCode   string
+	}{
+		{"header yok", "", errors.AuthCodeMissingToken},
+		{"Bearer prefix yok", "Token abc", errors.AuthCodeMalformedToken},
+		{"boş token", "Bearer ", errors.AuthCodeMalformedToken},
+		{"bozuk token", "Bearer not-a-jwt", errors.AuthCodeMalformedToken},
+		{"yanlış imza", "Bearer " + signToken(t, "baska-secret", time.Now().Add(time.Hour)), errors.AuthCodeInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rec, response := serveWithAuth(DefaultAuthConfig(), tt.authHeader)
+
+			// Assert
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, tt.wantCode, response.ErrorCode)
+			assert.False(t, response.Success)
+		})
+	}
+}
+
+// TestAuthMiddleware_RevokedToken, iptal edilmiş token'ın revoked_token koduyla reddedildiğini test eder.
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	// Arrange
+	require.NoError(t, auth.Configure([]byte(testJWTSecret)))
+	token, err := auth.GenerateToken(987654, "revoked@example.com", "user")
+	require.NoError(t, err)
+	auth.RevokeUserTokens(987654)
+
+	// Act
+	rec, response := serveWithAuth(DefaultAuthConfig(), "Bearer "+token)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, errors.AuthCodeRevokedToken, response.ErrorCode)
+}
+
+// TestAuthMiddleware_ValidToken, geçerli token'ın handler'a ulaştığını test eder.
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	// Arrange
+	require.NoError(t, auth.Configure([]byte(testJWTSecret)))
+	token, err := auth.GenerateToken(1, "user@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	rec, _ := serveWithAuth(DefaultAuthConfig(), "Bearer "+token)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestAuthMiddleware_RejectsRefreshToken, refresh token'ın Bearer olarak kullanılınca wrong_token_type ile reddedildiğini test eder.
+func TestAuthMiddleware_RejectsRefreshToken(t *testing.T) {
+	// Arrange
+	require.NoError(t, auth.Configure([]byte(testJWTSecret)))
+	tokens, err := auth.GenerateTokenPair(1, "user@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	rec, response := serveWithAuth(DefaultAuthConfig(), "Bearer "+tokens.RefreshToken)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, errors.AuthCodeWrongTokenType, response.ErrorCode)
+}
+
+// TestAuthMiddleware_GenericErrorsWhenNotDetailed, DetailedErrors kapalıyken token hatalarının generic döndüğünü test eder.
+func TestAuthMiddleware_GenericErrorsWhenNotDetailed(t *testing.T) {
+	// Arrange
+	config := &AuthConfig{DetailedErrors: false}
+
+	// Act
+	_, missing := serveWithAuth(config, "")
+	_, badSignature := serveWithAuth(config, "Bearer "+signToken(t, "baska-secret", time.Now().Add(time.Hour)))
+
+	// Assert
+	assert.Equal(t, errors.AuthCodeMissingToken, missing.ErrorCode)
+	assert.Equal(t, errors.AuthCodeInvalidToken, badSignature.ErrorCode)
+	assert.Equal(t, "Geçersiz token", badSignature.Error)
+}
+
+// TestClassifyTokenError_Expired, süresi dolmuş token hatasının expired_token olarak sınıflandığını test eder.
+func TestClassifyTokenError_Expired(t *testing.T) {
+	// Arrange: jwt kütüphanesinden gerçek expired hatası üret
+	token := signToken(t, "test-secret", time.Now().Add(-time.Minute))
+	_, err := jwt.ParseWithClaims(token, &auth.Claims{}, func(*jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	require.Error(t, err)
+
+	// Act
+	code, message := classifyTokenError(err)
+
+	// Assert
+	assert.Equal(t, errors.AuthCodeExpiredToken, code)
+	assert.Equal(t, "Token süresi dolmuş", message)
+}
+
+// TestRequestChannel, kanalın önce token'daki client_type'tan, yoksa X-Client header'ından okunduğunu test eder.
+func TestRequestChannel(t *testing.T) {
+	tests := []struct {
+		name       string
+		clientType string
+		header     string
+		want       string
+	}{
+		{"token client_type öncelikli", "mobile", "web", models.ChannelMobile},
+		{"token'da yoksa header", "", "API", models.ChannelAPI},
+		{"tanımsız değer kaydedilmez", "", "smart-tv", ""},
+		{"hiçbiri yok", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/credit", nil)
+			if tt.header != "" {
+				req.Header.Set(ClientHeader, tt.header)
+			}
+
+			assert.Equal(t, tt.want, RequestChannel(req, &auth.Claims{ClientType: tt.clientType}))
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/onerilhan/go-payment-api/internal/auth"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// ClientHeader token'da client_type yoksa kanalın okunduğu header
+const ClientHeader = "X-Client"
+
+// RequestChannel isteği başlatan kanalı belirler: önce token'daki client_type, yoksa X-Client header'ı.
+// Tanımsız değerler boş string döner (kanal kaydedilmez).
+func RequestChannel(r *http.Request, claims *auth.Claims) string {
+	if claims != nil {
+		if channel := models.NormalizeChannel(claims.ClientType); channel != "" {
+			return channel
+		}
+	}
+	return models.NormalizeChannel(r.Header.Get(ClientHeader))
+}
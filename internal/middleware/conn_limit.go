@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ConnLimitConfig IP başına eşzamanlı bağlantı limiti ayarları
+type ConnLimitConfig struct {
+	MaxConnsPerIP int      // 0 = limitsiz
+	WhitelistIPs  []string // Limitten muaf IP/CIDR listesi (ör. load balancer / güvenilen proxy'ler)
+}
+
+// DefaultConnLimitConfig varsayılan bağlantı limiti ayarları (kapalı: proxy arkasında tüm client'lar
+// proxy'nin IP'sini paylaştığından limit yalnızca açıkça ayarlandığında uygulanır)
+func DefaultConnLimitConfig() *ConnLimitConfig {
+	return &ConnLimitConfig{
+		MaxConnsPerIP: 0,
+		WhitelistIPs:  []string{},
+	}
+}
+
+// ConnLimiter http.Server.ConnState hook'u ile IP başına açık bağlantıları sayar.
+// Limiti aşan yeni bağlantılar daha ilk request okunmadan kapatılır (slowloris koruması).
+type ConnLimiter struct {
+	config *ConnLimitConfig
+	counts map[string]int
+	conns  map[net.Conn]string // sayılan bağlantılar (reddedilenler kapanışta düşülmesin diye)
+	mutex  sync.Mutex
+}
+
+// NewConnLimiter yeni bağlantı limiter'ı oluşturur
+func NewConnLimiter(config *ConnLimitConfig) *ConnLimiter {
+	if config == nil {
+		config = DefaultConnLimitConfig()
+	}
+
+	return &ConnLimiter{
+		config: config,
+		counts: make(map[string]int),
+		conns:  make(map[net.Conn]string),
+	}
+}
+
+// ConnState http.Server.ConnState alanına verilecek hook
+func (cl *ConnLimiter) ConnState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		if !cl.acquire(conn) {
+			conn.Close()
+		}
+	case http.StateClosed, http.StateHijacked:
+		cl.release(conn)
+	}
+}
+
+// ActiveConns IP'nin açık bağlantı sayısını döner
+func (cl *ConnLimiter) ActiveConns(ip string) int {
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+	return cl.counts[ip]
+}
+
+// acquire bağlantıyı sayar, limit aşılmışsa false döner
+func (cl *ConnLimiter) acquire(conn net.Conn) bool {
+	if cl.config.MaxConnsPerIP <= 0 {
+		return true
+	}
+
+	ip := remoteIP(conn)
+	if cl.isWhitelisted(ip) {
+		return true
+	}
+
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+
+	if cl.counts[ip] >= cl.config.MaxConnsPerIP {
+		log.Warn().
+			Str("ip", ip).
+			Int("max_conns_per_ip", cl.config.MaxConnsPerIP).
+			Msg("Connection limit exceeded, closing connection")
+		return false
+	}
+
+	cl.counts[ip]++
+	cl.conns[conn] = ip
+	return true
+}
+
+// release kapanan bağlantıyı sayaçtan düşer
+func (cl *ConnLimiter) release(conn net.Conn) {
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+
+	ip, ok := cl.conns[conn]
+	if !ok {
+		return
+	}
+	delete(cl.conns, conn)
+
+	cl.counts[ip]--
+	if cl.counts[ip] <= 0 {
+		delete(cl.counts, ip)
+	}
+}
+
+// isWhitelisted IP'nin limitten muaf olup olmadığını kontrol eder (IP veya CIDR eşleşmesi)
+func (cl *ConnLimiter) isWhitelisted(ip string) bool {
+	return isTrustedProxy(ip, cl.config.WhitelistIPs)
+}
+
+// remoteIP bağlantının uzak IP'sini döner (proxy header'larına bakılmaz, TCP seviyesinde çalışır)
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startConnLimitedServer ConnLimiter hook'u takılı test server'ı başlatır
+func startConnLimitedServer(t *testing.T, limiter *ConnLimiter) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = limiter.ConnState
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// isConnClosedByServer server'ın bağlantıyı kapatıp kapatmadığını kontrol eder
+func isConnClosedByServer(conn net.Conn) bool {
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err := conn.Read(make([]byte, 1))
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return false
+	}
+	return err != nil
+}
+
+// TestConnLimiter_RejectsOverCap, aynı IP'den limiti aşan bağlantının kapatıldığını test eder.
+func TestConnLimiter_RejectsOverCap(t *testing.T) {
+	// Arrange
+	limiter := NewConnLimiter(&ConnLimitConfig{MaxConnsPerIP: 2})
+	server := startConnLimitedServer(t, limiter)
+	addr := server.Listener.Addr().String()
+
+	var open []net.Conn
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		open = append(open, conn)
+	}
+	require.Eventually(t, func() bool { return limiter.ActiveConns("127.0.0.1") == 2 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	extra, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer extra.Close()
+
+	// Assert
+	assert.True(t, isConnClosedByServer(extra))
+	for _, conn := range open {
+		assert.False(t, isConnClosedByServer(conn))
+	}
+	assert.Equal(t, 2, limiter.ActiveConns("127.0.0.1"))
+}
+
+// TestConnLimiter_ReleasesOnClose, kapanan bağlantının yerine yenisinin kabul edildiğini test eder.
+func TestConnLimiter_ReleasesOnClose(t *testing.T) {
+	// Arrange
+	limiter := NewConnLimiter(&ConnLimitConfig{MaxConnsPerIP: 1})
+	server := startConnLimitedServer(t, limiter)
+	addr := server.Listener.Addr().String()
+
+	first, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return limiter.ActiveConns("127.0.0.1") == 1 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	first.Close()
+	require.Eventually(t, func() bool { return limiter.ActiveConns("127.0.0.1") == 0 }, time.Second, 10*time.Millisecond)
+
+	second, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer second.Close()
+
+	// Assert
+	assert.False(t, isConnClosedByServer(second))
+}
+
+// TestConnLimiter_Unlimited, limit 0 iken bağlantıların sayılmadığını test eder.
+func TestConnLimiter_Unlimited(t *testing.T) {
+	// Arrange
+	limiter := NewConnLimiter(&ConnLimitConfig{MaxConnsPerIP: 0})
+	server := startConnLimitedServer(t, limiter)
+
+	// Act
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Assert
+	assert.False(t, isConnClosedByServer(conn))
+	assert.Equal(t, 0, limiter.ActiveConns("127.0.0.1"))
+}
+
+// TestConnLimiter_WhitelistedCIDRNotCounted, whitelist'teki CIDR'dan (ör. güvenilen proxy) gelen bağlantıların
+// limite takılmadığını test eder.
+func TestConnLimiter_WhitelistedCIDRNotCounted(t *testing.T) {
+	// Arrange
+	limiter := NewConnLimiter(&ConnLimitConfig{MaxConnsPerIP: 1, WhitelistIPs: []string{"127.0.0.0/8"}})
+	server := startConnLimitedServer(t, limiter)
+	addr := server.Listener.Addr().String()
+
+	// Act
+	var conns []net.Conn
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+
+	// Assert
+	for _, conn := range conns {
+		assert.False(t, isConnClosedByServer(conn))
+	}
+	assert.Equal(t, 0, limiter.ActiveConns("127.0.0.1"))
+}
+
+// TestDefaultConnLimitConfig_Disabled, varsayılan ayarda bağlantı limitinin kapalı olduğunu test eder.
+func TestDefaultConnLimitConfig_Disabled(t *testing.T) {
+	assert.Equal(t, 0, DefaultConnLimitConfig().MaxConnsPerIP)
+}
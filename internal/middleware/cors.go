@@ -42,10 +42,13 @@ func DefaultCORSConfig() *CORSConfig {
 			"Origin",
 			"User-Agent",
 			"X-Requested-With",
+			"If-Match", // Kullanıcı güncellemesinde optimistic concurrency
+			"X-Client", // Transaction kanalı (web/mobile/api)
 		},
 		ExposedHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			"ETag",
 		},
 		AllowCredentials: true,
 		MaxAge:           86400, // 24 saat
@@ -67,8 +70,9 @@ func ProductionCORSConfig(allowedDomains []string) *CORSConfig {
 			"Authorization",
 			"Content-Type",
 			"Accept",
+			"If-Match",
 		},
-		ExposedHeaders:   []string{"Content-Length"},
+		ExposedHeaders:   []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           3600, // 1 saat
 	}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"runtime/debug"
@@ -10,6 +9,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 // ErrorHandlingMiddleware centralized error handling ve panic recovery
@@ -28,6 +28,8 @@ func ErrorHandlingMiddleware(config *errors.ErrorConfig) func(http.Handler) http
 					var errorMessage string
 					var isAPIError bool
 					var errorType string
+					var errorCode string
+					var errorDetails map[string]interface{}
 
 					// Type switch ile esnek error yakalama
 					switch err := recovered.(type) {
@@ -37,14 +39,23 @@ func ErrorHandlingMiddleware(config *errors.ErrorConfig) func(http.Handler) http
 						errorMessage = err.Error()
 						isAPIError = true
 						errorType = fmt.Sprintf("%T", err)
+						if coded, ok := err.(errors.CodedError); ok {
+							errorCode = coded.ErrorCode()
+						}
+						if detailed, ok := err.(errors.DetailedError); ok {
+							errorDetails = detailed.ErrorDetails()
+						}
+						if detailed, ok := err.(errors.ConfigDetailedError); ok {
+							errorDetails = detailed.ErrorDetailsFor(config)
+						}
 
 						// API error'u özel olarak logla
 						logAPIError(err, r, errorType)
 
 					case error:
-						// Normal Go error
+						// Normal Go error (production'da iç detaylar client'a gösterilmez)
 						statusCode = 500
-						errorMessage = err.Error()
+						errorMessage = config.UserMessage(err)
 						isAPIError = false
 						errorType = "error"
 
@@ -87,7 +98,7 @@ func ErrorHandlingMiddleware(config *errors.ErrorConfig) func(http.Handler) http
 						stack = panicInfo.Stack
 					}
 
-					sendErrorResponse(w, r, statusCode, errorMessage, config, stack)
+					sendErrorResponse(w, r, statusCode, errorMessage, errorCode, errorDetails, config, stack)
 				}
 			}()
 
@@ -105,7 +116,7 @@ func ErrorHandlingMiddleware(config *errors.ErrorConfig) func(http.Handler) http
 			if wrapped.statusCode >= 400 && !wrapped.responseWritten {
 				// Status code'a göre custom mesaj al
 				errorMessage := getErrorMessage(wrapped.statusCode, config)
-				sendErrorResponse(w, r, wrapped.statusCode, errorMessage, config, "")
+				sendErrorResponse(w, r, wrapped.statusCode, errorMessage, "", nil, config, "")
 			}
 		})
 	}
@@ -162,12 +173,13 @@ func (erw *errorResponseWriter) Write(b []byte) (int, error) {
 }
 
 // sendErrorResponse standardized error response gönderir
-func sendErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, config *errors.ErrorConfig, stack string) {
+func sendErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, errorCode string, details map[string]interface{}, config *errors.ErrorConfig, stack string) {
 	// Response body oluştur
 	response := errors.ErrorResponse{
 		Success:   false,
 		Error:     truncateString(message, config.MaxErrorLength),
 		Code:      statusCode,
+		ErrorCode: errorCode,
 		Timestamp: time.Now().Format(time.RFC3339),
 		RequestID: w.Header().Get("X-Request-ID"),
 	}
@@ -182,12 +194,12 @@ func sendErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, m
 		"method": r.Method,
 		"path":   r.URL.Path,
 	}
+	for key, value := range details {
+		response.Details[key] = value
+	}
 
 	// JSON response gönder
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := utils.WriteJSON(w, statusCode, response); err != nil {
 		// JSON encoding hatası - environment'a göre fallback
 		if config.ShowStackTrace {
 			// Development: Detaylı log
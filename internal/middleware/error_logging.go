@@ -23,6 +23,7 @@ func logAPIError(err errors.APIError, r *http.Request, errorType string) {
 	switch e := err.(type) {
 	case *errors.AuthError:
 		logEvent.Str("category", "authentication").
+			Str("error_code", e.Code).
 			Msg("Authentication failed")
 
 	case *errors.RBACError:
@@ -37,6 +38,14 @@ func logAPIError(err errors.APIError, r *http.Request, errorType string) {
 			Interface("value", e.Value).
 			Msg("Validation failed")
 
+	case *errors.SecurityError:
+		logEvent.Str("category", "security").
+			Str("error_code", e.Code).
+			Str("threat", e.Category).
+			Str("location", e.Location).
+			Str("field", e.Field).
+			Msg("Security check failed")
+
 	default:
 		logEvent.Str("category", "api_error").
 			Msg("API error occurred")
@@ -8,6 +8,11 @@ type ErrorConfig struct {
 	IncludeHeaders  []string       // Response'da gösterilecek header'lar
 	EnablePanicLogs bool           // Panic durumlarını ayrıca logla
 	MaxErrorLength  int            // Error mesajının maksimum uzunluğu
+
+	SanitizeInternalErrors bool // DB/driver hata detaylarını client mesajlarından çıkar
+
+	JSONErrorDetails       bool // Malformed JSON'da hata yeri (satır, sütun, alan) ve body kesitini details'e ekle
+	JSONErrorSnippetLength int  // Details'e eklenen body kesitinin maksimum uzunluğu (byte, 0 = kesit yok)
 }
 
 // DefaultErrorConfig varsayılan error handling ayarları
@@ -28,6 +33,11 @@ func DefaultErrorConfig() *ErrorConfig {
 		IncludeHeaders:  []string{"X-Request-ID", "X-RateLimit-Remaining"},
 		EnablePanicLogs: true,
 		MaxErrorLength:  500,
+
+		SanitizeInternalErrors: true,
+
+		JSONErrorDetails:       false,
+		JSONErrorSnippetLength: 40,
 	}
 }
 
@@ -37,6 +47,8 @@ func DevelopmentErrorConfig() *ErrorConfig {
 	config.ShowStackTrace = true
 	config.LogLevel = "DEBUG"
 	config.MaxErrorLength = 2000
+	config.SanitizeInternalErrors = false // Development'ta tam hata detayı gösterilir
+	config.JSONErrorDetails = true        // Client payload'ını düzeltebilsin diye JSON hata yeri gösterilir
 	return config
 }
 
@@ -47,5 +59,7 @@ func ProductionErrorConfig() *ErrorConfig {
 	config.CustomErrorMap[500] = "Bir hata oluştu. Teknik ekibimiz bilgilendirildi."
 	config.LogLevel = "ERROR"
 	config.MaxErrorLength = 200
+	config.SanitizeInternalErrors = true
+	config.JSONErrorDetails = false
 	return config
 }
@@ -7,6 +7,7 @@ type ErrorResponse struct {
 	Success   bool                   `json:"success"`
 	Error     string                 `json:"error"`
 	Code      int                    `json:"code"`
+	ErrorCode string                 `json:"error_code,omitempty"` // Makine tarafından okunabilir hata kodu
 	Timestamp string                 `json:"timestamp"`
 	RequestID string                 `json:"request_id,omitempty"`
 	Details   map[string]interface{} `json:"details,omitempty"`
@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// genericInternalMessage iç hata dışında kullanıcıya gösterilecek bir mesaj kalmadığında kullanılır
+const genericInternalMessage = "İşlem gerçekleştirilemedi. Lütfen daha sonra tekrar deneyin."
+
+// activeConfig handler'ların hata mesajı çevirisinde kullandığı config (main'de ortama göre ayarlanır)
+var activeConfig = struct {
+	sync.RWMutex
+	config *ErrorConfig
+}{
+	config: DefaultErrorConfig(),
+}
+
+// SetActiveConfig SafeMessage'ın kullandığı config'i ayarlar (nil ise default)
+func SetActiveConfig(config *ErrorConfig) {
+	if config == nil {
+		config = DefaultErrorConfig()
+	}
+
+	activeConfig.Lock()
+	defer activeConfig.Unlock()
+	activeConfig.config = config
+}
+
+// SafeMessage aktif config'e göre error'un client'a gösterilecek mesajını döner
+func SafeMessage(err error) string {
+	activeConfig.RLock()
+	config := activeConfig.config
+	activeConfig.RUnlock()
+
+	return config.UserMessage(err)
+}
+
+// UserMessage error'u client'a gösterilebilecek mesaja çevirir.
+// SanitizeInternalErrors açıksa zincirdeki DB/driver/ağ hataları mesajdan çıkarılır
+// ("transaction kaydı oluşturulamadı: pq: ..." → "transaction kaydı oluşturulamadı");
+// kapalıysa (development) mesaj olduğu gibi döner. Tam detay her durumda server tarafında loglanmalıdır.
+func (c *ErrorConfig) UserMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	message := err.Error()
+	if c == nil || !c.SanitizeInternalErrors {
+		return message
+	}
+
+	internal := findInternalError(err)
+	if internal == nil {
+		return message
+	}
+
+	// İç hatanın metni mesajın sonundaysa, önündeki kullanıcıya yönelik kısım korunur
+	safe := message
+	if idx := strings.Index(message, internal.Error()); idx >= 0 {
+		safe = message[:idx]
+	}
+	safe = strings.TrimRight(strings.TrimSpace(safe), ":")
+	safe = strings.TrimSpace(safe)
+
+	if safe == "" {
+		return genericInternalMessage
+	}
+	return safe
+}
+
+// findInternalError zincirdeki ilk iç (DB, driver, ağ) hatayı döner, yoksa nil
+func findInternalError(err error) error {
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) {
+		return pqErr
+	}
+
+	var netErr *net.OpError
+	if stderrors.As(err, &netErr) {
+		return netErr
+	}
+
+	for _, target := range []error{sql.ErrNoRows, sql.ErrConnDone, sql.ErrTxDone, driver.ErrBadConn, context.DeadlineExceeded} {
+		if stderrors.Is(err, target) {
+			return target
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUserMessage_WrappedDBErrorSanitizedInProduction, sarmalanmış DB hatasının production'da gizlendiğini test eder.
+func TestUserMessage_WrappedDBErrorSanitizedInProduction(t *testing.T) {
+	// Arrange
+	dbErr := &pq.Error{Code: "23503", Message: `insert or update on table "transactions" violates foreign key constraint "transactions_to_user_id_fkey"`}
+	err := fmt.Errorf("transaction kaydı oluşturulamadı: %w", dbErr)
+
+	// Act
+	message := ProductionErrorConfig().UserMessage(err)
+
+	// Assert
+	assert.Equal(t, "transaction kaydı oluşturulamadı", message)
+	assert.NotContains(t, message, "pq:")
+	assert.NotContains(t, message, "transactions_to_user_id_fkey")
+}
+
+// TestUserMessage_WrappedDBErrorVerboseInDevelopment, sarmalanmış DB hatasının development'ta tam gösterildiğini test eder.
+func TestUserMessage_WrappedDBErrorVerboseInDevelopment(t *testing.T) {
+	// Arrange
+	dbErr := &pq.Error{Code: "23503", Message: `violates foreign key constraint "transactions_to_user_id_fkey"`}
+	err := fmt.Errorf("transaction kaydı oluşturulamadı: %w", dbErr)
+
+	// Act
+	message := DevelopmentErrorConfig().UserMessage(err)
+
+	// Assert
+	assert.Equal(t, err.Error(), message)
+	assert.Contains(t, message, "pq: violates foreign key constraint")
+}
+
+// TestUserMessage_BareDriverErrorFallsBackToGeneric, kullanıcıya yönelik metin kalmayan iç hatada genel mesaj döndüğünü test eder.
+func TestUserMessage_BareDriverErrorFallsBackToGeneric(t *testing.T) {
+	// Act
+	message := ProductionErrorConfig().UserMessage(sql.ErrConnDone)
+
+	// Assert
+	assert.Equal(t, genericInternalMessage, message)
+}
+
+// TestUserMessage_BusinessErrorUnchanged, iç hata içermeyen iş kuralı hatalarının değiştirilmediğini test eder.
+func TestUserMessage_BusinessErrorUnchanged(t *testing.T) {
+	// Arrange
+	err := fmt.Errorf("yetersiz bakiye. Mevcut bakiye: %.2f TL", 12.5)
+
+	// Act
+	message := ProductionErrorConfig().UserMessage(err)
+
+	// Assert
+	assert.Equal(t, err.Error(), message)
+}
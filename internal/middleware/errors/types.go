@@ -1,15 +1,51 @@
 package errors
 
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
 // APIError interface for custom error types
 type APIError interface {
 	error
 	Status() int
 }
 
+// CodedError makine tarafından okunabilir hata kodu taşıyan error'lar için interface
+type CodedError interface {
+	ErrorCode() string
+}
+
+// Authentication hata kodları (client'ların ayırt edebilmesi için)
+const (
+	AuthCodeMissingToken     = "missing_token"
+	AuthCodeMalformedToken   = "malformed_token"
+	AuthCodeExpiredToken     = "expired_token"
+	AuthCodeInvalidSignature = "invalid_signature"
+	AuthCodeRevokedToken     = "revoked_token"
+	AuthCodeSessionExpired   = "session_expired"
+	AuthCodeWrongTokenType   = "wrong_token_type"
+	AuthCodeInvalidToken     = "invalid_token"
+)
+
+// DetailedError yanıtın details alanına ek bilgi koyan error'lar için interface
+type DetailedError interface {
+	ErrorDetails() map[string]interface{}
+}
+
+// ConfigDetailedError details alanı aktif error config'ine bağlı olan error'lar için interface
+// (ör. development'ta gösterilip production'da gizlenen detaylar)
+type ConfigDetailedError interface {
+	ErrorDetailsFor(config *ErrorConfig) map[string]interface{}
+}
+
 // AuthError authentication hatası için custom error type
 type AuthError struct {
 	Message    string
 	StatusCode int
+	Code       string // Makine tarafından okunabilir hata kodu (ör. expired_token)
 }
 
 // Error AuthError'un error interface implementation'ı
@@ -22,6 +58,11 @@ func (e *AuthError) Status() int {
 	return e.StatusCode
 }
 
+// ErrorCode AuthError'un CodedError interface implementation'ı
+func (e *AuthError) ErrorCode() string {
+	return e.Code
+}
+
 // RBACError authorization hatası için custom error type
 type RBACError struct {
 	Message    string
@@ -57,3 +98,123 @@ func (e *ValidationError) Error() string {
 func (e *ValidationError) Status() int {
 	return e.StatusCode
 }
+
+// Güvenlik ihlali hata kodları (validation middleware'in tespit kategorisine göre)
+const (
+	SecurityCodeSQLInjection  = "security_sql_injection"
+	SecurityCodeXSS           = "security_xss"
+	SecurityCodeParamTooLong  = "security_param_too_long"
+	SecurityCodeThreatGeneric = "security_threat_detected"
+)
+
+// SecurityError güvenlik kontrolüne takılan istek için custom error type.
+// Şüpheli değer client'a geri yansıtılmaz; sadece kategori ve yer (location + alan adı) döner.
+type SecurityError struct {
+	Message    string
+	StatusCode int
+	Code       string // Makine tarafından okunabilir hata kodu (ör. security_xss)
+	Category   string // sql_injection | xss | param_too_long (boş = detay gizli)
+	Location   string // query | form | header
+	Field      string // Parametre veya header adı
+}
+
+// Error SecurityError'un error interface implementation'ı
+func (e *SecurityError) Error() string {
+	return e.Message
+}
+
+// Status SecurityError'un APIError interface implementation'ı
+func (e *SecurityError) Status() int {
+	return e.StatusCode
+}
+
+// ErrorCode SecurityError'un CodedError interface implementation'ı
+func (e *SecurityError) ErrorCode() string {
+	return e.Code
+}
+
+// ErrorDetails SecurityError'un DetailedError interface implementation'ı (detay gizliyse nil)
+func (e *SecurityError) ErrorDetails() map[string]interface{} {
+	if e.Category == "" {
+		return nil
+	}
+	details := map[string]interface{}{
+		"category": e.Category,
+		"location": e.Location,
+	}
+	if e.Field != "" {
+		details["field"] = e.Field
+	}
+	return details
+}
+
+// InvalidJSONCode malformed JSON body için hata kodu
+const InvalidJSONCode = "invalid_json"
+
+// InvalidJSONError request body JSON olarak decode edilemediğinde döner.
+// Mesaj her ortamda geneldir; hata yeri ve body kesiti yalnızca config izin veriyorsa details'e eklenir.
+type InvalidJSONError struct {
+	Message    string
+	StatusCode int
+	Cause      *utils.JSONDecodeError
+}
+
+// NewInvalidJSONError decode hatasından 400 InvalidJSONError üretir
+func NewInvalidJSONError(err error) *InvalidJSONError {
+	var cause *utils.JSONDecodeError
+	if !stderrors.As(err, &cause) {
+		cause = utils.NewJSONDecodeError(err, nil)
+	}
+	return &InvalidJSONError{
+		Message:    "Geçersiz JSON formatı",
+		StatusCode: http.StatusBadRequest,
+		Cause:      cause,
+	}
+}
+
+// Error InvalidJSONError'un error interface implementation'ı
+func (e *InvalidJSONError) Error() string {
+	return e.Message
+}
+
+// Status InvalidJSONError'un APIError interface implementation'ı
+func (e *InvalidJSONError) Status() int {
+	return e.StatusCode
+}
+
+// ErrorCode InvalidJSONError'un CodedError interface implementation'ı
+func (e *InvalidJSONError) ErrorCode() string {
+	return InvalidJSONCode
+}
+
+// Unwrap alttaki decode hatasını döner
+func (e *InvalidJSONError) Unwrap() error {
+	return e.Cause
+}
+
+// ErrorDetailsFor InvalidJSONError'un ConfigDetailedError interface implementation'ı (JSONErrorDetails kapalıysa nil)
+func (e *InvalidJSONError) ErrorDetailsFor(config *ErrorConfig) map[string]interface{} {
+	if config == nil || !config.JSONErrorDetails || e.Cause == nil {
+		return nil
+	}
+
+	details := map[string]interface{}{
+		"json_error": e.Cause.Kind,
+	}
+	if e.Cause.Offset >= 0 {
+		details["offset"] = e.Cause.Offset
+	}
+	if line, column, ok := e.Cause.Position(); ok {
+		details["line"] = line
+		details["column"] = column
+	}
+	if snippet := e.Cause.Snippet(config.JSONErrorSnippetLength); snippet != "" {
+		details["snippet"] = snippet
+	}
+	if e.Cause.Kind == utils.JSONErrorTypeMismatch {
+		details["field"] = e.Cause.Field
+		details["expected"] = e.Cause.Expected
+		details["actual"] = e.Cause.Actual
+	}
+	return details
+}
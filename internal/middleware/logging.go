@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/oklog/ulid/v2"
 	"github.com/rs/zerolog/log"
 
 	"github.com/onerilhan/go-payment-api/internal/utils"
@@ -31,11 +34,23 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Request ID formatları
+const (
+	RequestIDFormatUUID = "uuid" // UUIDv4 (varsayılan)
+	RequestIDFormatULID = "ulid" // Zamana göre sıralanabilir ULID
+)
+
+// RequestIDGenerator request ID üreten fonksiyon tipi
+type RequestIDGenerator func() string
+
 // LoggingConfig logging middleware ayarları
 type LoggingConfig struct {
-	SkipPaths   []string // Log'lanmayacak path'ler (health check gibi)
-	LogBody     bool     // Request/response body'leri logla
-	MaxBodySize int64    // Maksimum body size (byte)
+	SkipPaths          []string           // Log'lanmayacak path'ler (health check gibi)
+	LogBody            bool               // Request/response body'leri logla
+	MaxBodySize        int64              // Maksimum body size (byte)
+	RequestIDGenerator RequestIDGenerator // Request ID üretici (nil ise UUID)
+	LogRouteTemplate   bool               // Eşleşen mux route template'ini logla ("/api/v1/transactions/{id}")
+	LogQueryCount      bool               // Request'in çalıştırdığı DB sorgu sayısını logla (N+1 tespiti)
 }
 
 // DefaultLoggingConfig varsayılan logging ayarları
@@ -43,10 +58,14 @@ func DefaultLoggingConfig() *LoggingConfig {
 	return &LoggingConfig{
 		SkipPaths: []string{
 			"/health",
+			"/ready",
 			"/favicon.ico",
 		},
-		LogBody:     false, // Production'da false olmalı (security)
-		MaxBodySize: 1024,  // 1KB
+		LogBody:            false, // Production'da false olmalı (security)
+		MaxBodySize:        1024,  // 1KB
+		RequestIDGenerator: generateUUID,
+		LogRouteTemplate:   true,
+		LogQueryCount:      true,
 	}
 }
 
@@ -57,6 +76,11 @@ func RequestLoggingMiddleware(config *LoggingConfig) func(http.Handler) http.Han
 		config = DefaultLoggingConfig()
 	}
 
+	generateRequestID := config.RequestIDGenerator
+	if generateRequestID == nil {
+		generateRequestID = generateUUID
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip paths kontrolü
@@ -89,6 +113,17 @@ func RequestLoggingMiddleware(config *LoggingConfig) func(http.Handler) http.Han
 			// Request ID'yi header'a ekle
 			wrapped.Header().Set("X-Request-ID", requestID)
 
+			// Request ID'yi context'e ekle (service log'larında korelasyon için)
+			r = r.WithContext(utils.WithRequestID(r.Context(), requestID))
+
+			// DB sorgu sayacını context'e ekle (metrics middleware eklediyse aynısı kullanılır)
+			var queryCounter *utils.QueryCounter
+			if config.LogQueryCount {
+				var ctx context.Context
+				ctx, queryCounter = utils.WithQueryCounter(r.Context())
+				r = r.WithContext(ctx)
+			}
+
 			// Request başlangıç log'u
 			logEvent := log.Info().
 				Str("request_id", requestID).
@@ -102,6 +137,12 @@ func RequestLoggingMiddleware(config *LoggingConfig) func(http.Handler) http.Han
 				logEvent.Str("query", query)
 			}
 
+			route := ""
+			if config.LogRouteTemplate {
+				route = RouteTemplate(r)
+				logEvent.Str("route", route)
+			}
+
 			logEvent.Msg("Request started")
 
 			// Handler'ı çalıştır
@@ -145,11 +186,36 @@ func RequestLoggingMiddleware(config *LoggingConfig) func(http.Handler) http.Han
 					Float64("duration_ms", float64(duration.Nanoseconds())/1e6)
 			}
 
+			if config.LogRouteTemplate {
+				responseLogEvent.Str("route", route)
+			}
+
+			if queryCounter != nil {
+				responseLogEvent.Int64("db_query_count", queryCounter.Count())
+			}
+
 			responseLogEvent.Msg("Request completed")
 		})
 	}
 }
 
+// UnmatchedRouteTemplate hiçbir route ile eşleşmeyen istekler için kullanılan template
+const UnmatchedRouteTemplate = "unmatched"
+
+// RouteTemplate isteğin eşleştiği mux route template'ini döner, eşleşme yoksa UnmatchedRouteTemplate
+func RouteTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return UnmatchedRouteTemplate
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil || template == "" {
+		return UnmatchedRouteTemplate
+	}
+	return template
+}
+
 // shouldSkipLogging belirli path'lerin log'lanmaması gerekip gerekmediğini kontrol eder
 func shouldSkipLogging(path string, skipPaths []string) bool {
 	for _, skipPath := range skipPaths {
@@ -167,11 +233,26 @@ func shouldSkipLogging(path string, skipPaths []string) bool {
 	return false
 }
 
-// generateRequestID benzersiz request ID oluşturur
-func generateRequestID() string {
+// NewRequestIDGenerator format adına göre request ID üretici döner (bilinmeyen format UUID'ye düşer)
+func NewRequestIDGenerator(format string) RequestIDGenerator {
+	switch strings.ToLower(format) {
+	case RequestIDFormatULID:
+		return generateULID
+	default:
+		return generateUUID
+	}
+}
+
+// generateUUID UUIDv4 request ID oluşturur
+func generateUUID() string {
 	return uuid.New().String()
 }
 
+// generateULID zamana göre sıralanabilir ULID oluşturur (aynı ms içinde monoton artar)
+func generateULID() string {
+	return ulid.Make().String()
+}
+
 // RequestLoggingMiddlewareWithDefaults varsayılan ayarlarla logging middleware döner
 func RequestLoggingMiddlewareWithDefaults() func(http.Handler) http.Handler {
 	return RequestLoggingMiddleware(DefaultLoggingConfig())
@@ -182,11 +263,15 @@ func ProductionLoggingConfig() *LoggingConfig {
 	return &LoggingConfig{
 		SkipPaths: []string{
 			"/health",
+			"/ready",
 			"/metrics", // Prometheus metrics
 			"/favicon.ico",
 			"/robots.txt",
 		},
-		LogBody:     false, // Güvenlik için kapalı
-		MaxBodySize: 0,     // Body logging kapalı
+		LogBody:            false, // Güvenlik için kapalı
+		MaxBodySize:        0,     // Body logging kapalı
+		RequestIDGenerator: generateUUID,
+		LogRouteTemplate:   true,
+		LogQueryCount:      true,
 	}
 }
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+)
+
+// captureLogEvents global logger'ı buffer'a yönlendirir, test bitince eski haline döndürür
+func captureLogEvents(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+// findLogEvent buffer'daki JSON log satırlarından mesajı eşleşeni döner
+func findLogEvent(t *testing.T, buf *bytes.Buffer, message string) map[string]interface{} {
+	t.Helper()
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		if event["message"] == message {
+			return event
+		}
+	}
+	t.Fatalf("%q log event'i bulunamadı", message)
+	return nil
+}
+
+// TestRequestLogging_IncludesRouteTemplate, request log'unda eşleşen route template'inin yer aldığını test eder.
+func TestRequestLogging_IncludesRouteTemplate(t *testing.T) {
+	// Arrange
+	buf := captureLogEvents(t)
+	router := mux.NewRouter()
+	router.Use(RequestLoggingMiddleware(DefaultLoggingConfig()))
+	router.HandleFunc("/api/v1/transactions/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+
+	// Act
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/transactions/123", nil))
+
+	// Assert
+	event := findLogEvent(t, buf, "Request completed")
+	assert.Equal(t, "/api/v1/transactions/{id:[0-9]+}", event["route"])
+	assert.Equal(t, "/api/v1/transactions/123", event["path"])
+}
+
+// TestRequestLogging_UnmatchedRouteFallback, route eşleşmesi olmayan isteklerde template'in "unmatched" olduğunu test eder.
+func TestRequestLogging_UnmatchedRouteFallback(t *testing.T) {
+	// Arrange
+	buf := captureLogEvents(t)
+	handler := RequestLoggingMiddleware(DefaultLoggingConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/yok", nil))
+
+	// Assert
+	event := findLogEvent(t, buf, "Request completed")
+	assert.Equal(t, UnmatchedRouteTemplate, event["route"])
+}
+
+// TestNewRequestIDGenerator_ULIDSortsInTimeOrder, sırayla üretilen ULID'lerin zaman sırasına göre sıralandığını test eder.
+func TestNewRequestIDGenerator_ULIDSortsInTimeOrder(t *testing.T) {
+	// Arrange
+	generate := NewRequestIDGenerator(RequestIDFormatULID)
+
+	// Act
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = generate()
+	}
+
+	// Assert
+	assert.True(t, sort.StringsAreSorted(ids), "ULID'ler üretim sırasına göre sıralı olmalı")
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		assert.Len(t, id, 26)
+		assert.False(t, seen[id], "ULID tekrar etmemeli")
+		seen[id] = true
+	}
+}
+
+// TestNewRequestIDGenerator_DefaultsToUUID, bilinmeyen formatta UUID üretildiğini test eder.
+func TestNewRequestIDGenerator_DefaultsToUUID(t *testing.T) {
+	// Act
+	id := NewRequestIDGenerator("bilinmeyen")()
+
+	// Assert
+	assert.Len(t, id, 36)
+}
+
+// dsnConnector driver + DSN ikilisini driver.Connector'a çevirir (sqlmock'u counting connector ile sarmak için)
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+// TestRequestLogging_LogsDBQueryCount, iki sorgu çalıştıran handler için tamamlanma log'unda sorgu sayısının iki olduğunu test eder.
+func TestRequestLogging_LogsDBQueryCount(t *testing.T) {
+	// Arrange
+	mockDB, dbMock, err := sqlmock.NewWithDSN("logging_query_count_test")
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	database := sql.OpenDB(db.NewCountingConnector(dsnConnector{driver: mockDB.Driver(), dsn: "logging_query_count_test"}))
+	defer database.Close()
+
+	dbMock.ExpectQuery("SELECT amount FROM balances").WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(100.0))
+	dbMock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	buf := captureLogEvents(t)
+	handler := RequestLoggingMiddleware(DefaultLoggingConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var amount float64
+		require.NoError(t, database.QueryRowContext(r.Context(), "SELECT amount FROM balances WHERE user_id = $1", 1).Scan(&amount))
+		_, err := database.ExecContext(r.Context(), "UPDATE users SET updated_at = NOW() WHERE id = $1", 1)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Act
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/balances/current", nil))
+
+	// Assert
+	event := findLogEvent(t, buf, "Request completed")
+	assert.Equal(t, float64(2), event["db_query_count"])
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -2,14 +2,14 @@ package middleware
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"runtime"
-	"sort"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 // MetricsConfig middleware ayarları
@@ -22,8 +22,22 @@ type MetricsConfig struct {
 
 	SlowRequestThreshold time.Duration // Yavaş istek eşiği
 	MemoryAlertThreshold uint64        // Bellek kullanım eşiği (bytes)
-	MaxStoredResponse    int           // Kaç adet response time saklanacak
 	MemoryCheckInterval  time.Duration // Bellek kontrol sıklığı
+
+	MemoryStatsSource  func() uint64 // Bellek ölçüm kaynağı (nil ise runtime.ReadMemStats)
+	MaxMonitorRestarts int           // Panik sonrası monitor en fazla kaç kez yeniden başlatılır
+
+	GroupByRouteTemplate bool // Endpoint metriklerini path yerine route template'e göre grupla (cardinality)
+	EnableQueryCount     bool // Endpoint bazında request başına DB sorgu sayısını topla
+
+	ResponseTimeBuckets []time.Duration // Endpoint response time histogram'ının bucket üst sınırları (boş = DefaultResponseTimeBuckets)
+
+	RateLimitMetrics   *RateLimitMetrics  // Rate limit engelleme sayaçları snapshot'a eklenir (nil = eklenmez)
+	QueueMetricsSource func() interface{} // Transaction queue tekrar/dead-letter sayaçları snapshot'a eklenir (nil = eklenmez)
+
+	EndpointTTL   time.Duration    // Bu süre boyunca istek gelmeyen endpoint'in metrikleri silinir (0 = budama kapalı)
+	PruneInterval time.Duration    // Budamanın periyodik çalışma sıklığı (0 = EndpointTTL)
+	Clock         func() time.Time // Zaman kaynağı (nil ise time.Now)
 }
 
 // Varsayılan config
@@ -36,8 +50,10 @@ func DefaultMetricsConfig() *MetricsConfig {
 		EnableStatusCodeCount: true,
 		SlowRequestThreshold:  2 * time.Second,
 		MemoryAlertThreshold:  100 * 1024 * 1024, // 100MB
-		MaxStoredResponse:     100,
 		MemoryCheckInterval:   30 * time.Second,
+		MaxMonitorRestarts:    5,
+		GroupByRouteTemplate:  true,
+		EnableQueryCount:      true,
 	}
 }
 
@@ -46,13 +62,18 @@ type Metrics struct {
 	mutex               sync.RWMutex
 	TotalRequests       int64
 	ActiveRequests      int64
-	ResponseTimes       map[string][]time.Duration
 	StatusCodeCounts    map[int]int64
 	EndpointCounts      map[string]int64
+	ErrorCounts         map[string]int64 // Endpoint bazında 4xx/5xx response sayısı
 	SlowRequests        int64
 	MemoryUsage         uint64
 	LastMemoryCheck     time.Time
 	AverageResponseTime time.Duration
+	QueryCounts         map[string]*QueryCountStat
+	TotalQueries        int64
+	EndpointLastSeen    map[string]time.Time // Endpoint'e son istek zamanı (TTL budaması için)
+
+	ResponseTimeHistograms map[string]*ResponseTimeHistogram // Endpoint bazında response time dağılımı (bellek endpoint başına sabit)
 }
 
 // Snapshot formatı (JSON response)
@@ -64,7 +85,12 @@ type MetricsSnapshot struct {
 	AverageResponseTime time.Duration               `json:"average_response_time"`
 	StatusCodeCounts    map[int]int64               `json:"status_code_counts"`
 	EndpointCounts      map[string]int64            `json:"endpoint_counts"`
+	EndpointErrors      map[string]ErrorRateStat    `json:"endpoint_errors"`
 	ResponseTimeSummary map[string]ResponseTimeStat `json:"response_time_summary"`
+	TotalQueries        int64                       `json:"total_db_queries"`
+	QueryCountSummary   map[string]QueryCountStat   `json:"db_query_summary"`
+	RateLimit           *RateLimitSnapshot          `json:"rate_limit,omitempty"`
+	TransactionQueue    interface{}                 `json:"transaction_queue,omitempty"`
 	LastUpdated         time.Time                   `json:"last_updated"`
 }
 
@@ -78,6 +104,31 @@ type ResponseTimeStat struct {
 	P99     time.Duration `json:"p99"`
 }
 
+// ErrorRateStat endpoint bazında hata oranı (4xx/5xx response / toplam istek)
+type ErrorRateStat struct {
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// QueryCountStat endpoint bazında request başına DB sorgu sayısı özeti
+type QueryCountStat struct {
+	Requests int64   `json:"requests"`
+	Total    int64   `json:"total"`
+	Max      int64   `json:"max"`
+	Average  float64 `json:"average"`
+}
+
+// record bir request'in sorgu sayısını özete ekler
+func (s *QueryCountStat) record(count int64) {
+	s.Requests++
+	s.Total += count
+	if count > s.Max {
+		s.Max = count
+	}
+	s.Average = float64(s.Total) / float64(s.Requests)
+}
+
 // Custom response writer (status code yakalamak için)
 type metricsResponseWriter struct {
 	http.ResponseWriter
@@ -96,14 +147,29 @@ func NewMetricsMiddleware(ctx context.Context, config *MetricsConfig) (func(http
 	}
 
 	metrics := &Metrics{
-		ResponseTimes:    make(map[string][]time.Duration),
 		StatusCodeCounts: make(map[int]int64),
 		EndpointCounts:   make(map[string]int64),
+		ErrorCounts:      make(map[string]int64),
+		QueryCounts:      make(map[string]*QueryCountStat),
+		EndpointLastSeen: make(map[string]time.Time),
+
+		ResponseTimeHistograms: make(map[string]*ResponseTimeHistogram),
 	}
 
+	now := config.Clock
+	if now == nil {
+		now = time.Now
+	}
+	buckets := normalizeBuckets(config.ResponseTimeBuckets)
+
 	// Memory monitor başlat
 	if config.EnableMemoryTracking {
-		go memoryMonitor(ctx, metrics, config)
+		go runMemoryMonitor(ctx, metrics, config)
+	}
+
+	// Artık istek gelmeyen endpoint'lerin metrikleri periyodik olarak silinir (bellek sınırlı kalır)
+	if config.EndpointTTL > 0 {
+		go runEndpointPruner(ctx, metrics, config.EndpointTTL, config.PruneInterval, now)
 	}
 
 	// Middleware
@@ -111,40 +177,68 @@ func NewMetricsMiddleware(ctx context.Context, config *MetricsConfig) (func(http
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			// Endpoint anahtarı: route template (ör. "/api/v1/transactions/{id}") veya ham path
+			route := RouteTemplate(r)
+			endpoint := r.URL.Path
+			if config.GroupByRouteTemplate {
+				endpoint = route
+			}
+
 			if config.EnableRequestCount {
 				metrics.mutex.Lock()
 				metrics.TotalRequests++
 				if config.EnableConcurrency {
 					metrics.ActiveRequests++
 				}
-				metrics.EndpointCounts[r.URL.Path]++
+				metrics.EndpointCounts[endpoint]++
 				metrics.mutex.Unlock()
 			}
 
+			// DB sorgu sayacı (iç middleware'ler ve handler aynı sayacı kullanır)
+			var queryCounter *utils.QueryCounter
+			if config.EnableQueryCount {
+				var reqCtx context.Context
+				reqCtx, queryCounter = utils.WithQueryCounter(r.Context())
+				r = r.WithContext(reqCtx)
+			}
+
 			wrapped := &metricsResponseWriter{ResponseWriter: w, statusCode: 200}
 			next.ServeHTTP(wrapped, r)
 
 			elapsed := time.Since(start)
 
 			metrics.mutex.Lock()
+			metrics.EndpointLastSeen[endpoint] = now()
 			if config.EnableConcurrency {
 				metrics.ActiveRequests--
 			}
 
 			if config.EnableResponseTime {
-				if metrics.ResponseTimes[r.URL.Path] == nil {
-					metrics.ResponseTimes[r.URL.Path] = []time.Duration{}
-				}
-				rtList := append(metrics.ResponseTimes[r.URL.Path], elapsed)
-				if len(rtList) > config.MaxStoredResponse {
-					rtList = rtList[len(rtList)-config.MaxStoredResponse:]
+				histogram := metrics.ResponseTimeHistograms[endpoint]
+				if histogram == nil {
+					histogram = newResponseTimeHistogram(buckets)
+					metrics.ResponseTimeHistograms[endpoint] = histogram
 				}
-				metrics.ResponseTimes[r.URL.Path] = rtList
+				histogram.observe(elapsed)
 				updateAverage(metrics)
 			}
 
 			if config.EnableStatusCodeCount {
 				metrics.StatusCodeCounts[wrapped.statusCode]++
+				if wrapped.statusCode >= http.StatusBadRequest {
+					metrics.ErrorCounts[endpoint]++
+				}
+			}
+
+			if queryCounter != nil {
+				queryCount := queryCounter.Count()
+				stat := metrics.QueryCounts[endpoint]
+				if stat == nil {
+					stat = &QueryCountStat{}
+					metrics.QueryCounts[endpoint] = stat
+				}
+				stat.record(queryCount)
+				metrics.TotalQueries += queryCount
 			}
 
 			if elapsed > config.SlowRequestThreshold {
@@ -152,6 +246,7 @@ func NewMetricsMiddleware(ctx context.Context, config *MetricsConfig) (func(http
 				log.Warn().
 					Str("method", r.Method).
 					Str("path", r.URL.Path).
+					Str("route", route).
 					Dur("response_time", elapsed).
 					Msg("Slow request detected")
 			}
@@ -161,6 +256,7 @@ func NewMetricsMiddleware(ctx context.Context, config *MetricsConfig) (func(http
 			log.Info().
 				Str("method", r.Method).
 				Str("path", r.URL.Path).
+				Str("route", route).
 				Dur("response_time", elapsed).
 				Int("status_code", wrapped.statusCode).
 				Int64("active_requests", metrics.ActiveRequests).
@@ -168,18 +264,71 @@ func NewMetricsMiddleware(ctx context.Context, config *MetricsConfig) (func(http
 		})
 	}
 
-	// Handler (JSON output)
+	// Handler (JSON veya Accept/format'a göre Prometheus text output)
 	handlerFunc := func(w http.ResponseWriter, r *http.Request) {
+		// Snapshot sadece aktif endpoint'leri göstersin diye pruner turunu beklemeden budanır
+		if config.EndpointTTL > 0 {
+			pruneStaleEndpoints(metrics, now().Add(-config.EndpointTTL))
+		}
+		if wantsPrometheusFormat(r) {
+			w.Header().Set("Content-Type", PrometheusContentType)
+			w.WriteHeader(http.StatusOK)
+			writePrometheusMetrics(w, metrics)
+			return
+		}
 		snapshot := getSnapshot(metrics)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(snapshot)
+		if config.RateLimitMetrics != nil {
+			snapshot.RateLimit = config.RateLimitMetrics.Snapshot()
+		}
+		if config.QueueMetricsSource != nil {
+			snapshot.TransactionQueue = config.QueueMetricsSource()
+		}
+		utils.WriteJSON(w, http.StatusOK, snapshot)
 	}
 
 	return middlewareFunc, handlerFunc
 }
 
-// Bellek monitor
-func memoryMonitor(ctx context.Context, m *Metrics, config *MetricsConfig) {
+// runMemoryMonitor memory monitor'ü panic durumunda sınırlı sayıda yeniden başlatır
+func runMemoryMonitor(ctx context.Context, m *Metrics, config *MetricsConfig) {
+	restarts := 0
+	for {
+		if !memoryMonitor(ctx, m, config) {
+			return // Context iptal edildi, normal çıkış
+		}
+
+		restarts++
+		if restarts > config.MaxMonitorRestarts {
+			log.Error().
+				Int("restarts", restarts-1).
+				Msg("Memory monitor restart limiti aşıldı, bellek metrikleri artık güncellenmeyecek")
+			return
+		}
+
+		log.Warn().
+			Int("restart", restarts).
+			Int("max_restarts", config.MaxMonitorRestarts).
+			Msg("Memory monitor yeniden başlatılıyor")
+	}
+}
+
+// memoryMonitor bellek kullanımını periyodik ölçer; panic olursa true döner
+func memoryMonitor(ctx context.Context, m *Metrics, config *MetricsConfig) (panicked bool) {
+	// Panic recovery
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().
+				Interface("recover", r).
+				Msg("Memory monitor panikledi ama toparlandı")
+			panicked = true
+		}
+	}()
+
+	readMemory := config.MemoryStatsSource
+	if readMemory == nil {
+		readMemory = readRuntimeMemory
+	}
+
 	ticker := time.NewTicker(config.MemoryCheckInterval)
 	defer ticker.Stop()
 
@@ -187,34 +336,84 @@ func memoryMonitor(ctx context.Context, m *Metrics, config *MetricsConfig) {
 		select {
 		case <-ctx.Done():
 			log.Info().Msg("Memory monitor stopped")
-			return
+			return false
 		case <-ticker.C:
-			var mem runtime.MemStats
-			runtime.ReadMemStats(&mem)
+			alloc := readMemory()
 
 			m.mutex.Lock()
-			m.MemoryUsage = mem.Alloc
+			m.MemoryUsage = alloc
 			m.LastMemoryCheck = time.Now()
 			m.mutex.Unlock()
 
-			if mem.Alloc > config.MemoryAlertThreshold {
+			if alloc > config.MemoryAlertThreshold {
 				log.Warn().
-					Uint64("current_memory", mem.Alloc).
+					Uint64("current_memory", alloc).
 					Msg("High memory usage detected")
 			}
 		}
 	}
 }
 
+// runEndpointPruner TTL içinde görülmeyen endpoint metriklerini periyodik olarak siler
+func runEndpointPruner(ctx context.Context, m *Metrics, ttl, interval time.Duration, now func() time.Time) {
+	if interval <= 0 {
+		interval = ttl
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if pruned := pruneStaleEndpoints(m, now().Add(-ttl)); pruned > 0 {
+				log.Debug().Int("pruned", pruned).Msg("Eski endpoint metrikleri silindi")
+			}
+		}
+	}
+}
+
+// pruneStaleEndpoints son isteği cutoff'tan eski olan endpoint'lerin metriklerini siler; silinen endpoint sayısını döner.
+// Toplam sayaçlar (TotalRequests, StatusCodeCounts...) korunur, ortalama response time kalan endpoint'lerden yeniden hesaplanır.
+func pruneStaleEndpoints(m *Metrics, cutoff time.Time) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	pruned := 0
+	for endpoint, lastSeen := range m.EndpointLastSeen {
+		if !lastSeen.Before(cutoff) {
+			continue
+		}
+		delete(m.EndpointLastSeen, endpoint)
+		delete(m.EndpointCounts, endpoint)
+		delete(m.ErrorCounts, endpoint)
+		delete(m.QueryCounts, endpoint)
+		delete(m.ResponseTimeHistograms, endpoint)
+		pruned++
+	}
+
+	if pruned > 0 {
+		updateAverage(m)
+	}
+	return pruned
+}
+
+// readRuntimeMemory runtime'dan anlık heap kullanımını okur
+func readRuntimeMemory() uint64 {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return mem.Alloc
+}
+
 // Ortalama response time güncelle
 func updateAverage(m *Metrics) {
 	var total time.Duration
 	var count int64
-	for _, times := range m.ResponseTimes {
-		for _, t := range times {
-			total += t
-			count++
-		}
+	for _, histogram := range m.ResponseTimeHistograms {
+		total += histogram.Sum
+		count += histogram.Count
 	}
 	if count > 0 {
 		m.AverageResponseTime = total / time.Duration(count)
@@ -226,19 +425,26 @@ func getSnapshot(m *Metrics) *MetricsSnapshot {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	summary := make(map[string]ResponseTimeStat)
-	for path, times := range m.ResponseTimes {
-		if len(times) > 0 {
-			stat := ResponseTimeStat{
-				Count:   len(times),
-				Average: avg(times),
-				Min:     min(times),
-				Max:     max(times),
-				P95:     percentile(times, 95),
-				P99:     percentile(times, 99),
-			}
-			summary[path] = stat
+	summary := make(map[string]ResponseTimeStat, len(m.ResponseTimeHistograms))
+	for endpoint, histogram := range m.ResponseTimeHistograms {
+		if histogram.Count > 0 {
+			summary[endpoint] = histogram.stat()
+		}
+	}
+
+	// Hata oranı istek sayılan her endpoint için verilir (hatasız endpoint'ler 0 oranla görünür)
+	endpointErrors := make(map[string]ErrorRateStat, len(m.EndpointCounts))
+	for endpoint, requests := range m.EndpointCounts {
+		stat := ErrorRateStat{Requests: requests, Errors: m.ErrorCounts[endpoint]}
+		if requests > 0 {
+			stat.ErrorRate = float64(stat.Errors) / float64(requests)
 		}
+		endpointErrors[endpoint] = stat
+	}
+
+	queryCounts := make(map[string]QueryCountStat, len(m.QueryCounts))
+	for endpoint, stat := range m.QueryCounts {
+		queryCounts[endpoint] = *stat
 	}
 
 	return &MetricsSnapshot{
@@ -249,53 +455,15 @@ func getSnapshot(m *Metrics) *MetricsSnapshot {
 		AverageResponseTime: m.AverageResponseTime,
 		StatusCodeCounts:    copyMap(m.StatusCodeCounts),
 		EndpointCounts:      copyMap(m.EndpointCounts),
+		EndpointErrors:      endpointErrors,
 		ResponseTimeSummary: summary,
+		TotalQueries:        m.TotalQueries,
+		QueryCountSummary:   queryCounts,
 		LastUpdated:         time.Now(),
 	}
 }
 
 // Yardımcı fonksiyonlar
-func avg(times []time.Duration) time.Duration {
-	var total time.Duration
-	for _, t := range times {
-		total += t
-	}
-	return total / time.Duration(len(times))
-}
-
-func min(times []time.Duration) time.Duration {
-	min := times[0]
-	for _, t := range times {
-		if t < min {
-			min = t
-		}
-	}
-	return min
-}
-
-func max(times []time.Duration) time.Duration {
-	max := times[0]
-	for _, t := range times {
-		if t > max {
-			max = t
-		}
-	}
-	return max
-}
-
-func percentile(times []time.Duration, p int) time.Duration {
-	if len(times) == 0 {
-		return 0
-	}
-	sorted := append([]time.Duration{}, times...)
-	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
-	index := int(float64(len(sorted))*float64(p)/100.0 + 0.5)
-	if index >= len(sorted) {
-		index = len(sorted) - 1
-	}
-	return sorted[index]
-}
-
 func copyMap[K comparable, V any](original map[K]V) map[K]V {
 	out := make(map[K]V)
 	for k, v := range original {
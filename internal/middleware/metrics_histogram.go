@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultResponseTimeBuckets varsayılan response time histogram bucket üst sınırları (Prometheus varsayılanları)
+var DefaultResponseTimeBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// ResponseTimeHistogram endpoint'in tüm response sürelerinin bucket'lı dağılımı.
+// Bellek istek sayısından bağımsızdır; percentile'lar bucket içinde doğrusal interpolasyonla tahmin edilir.
+type ResponseTimeHistogram struct {
+	Bounds       []time.Duration // Bucket üst sınırları (artan sırada)
+	BucketCounts []int64         // Bounds[i] sınırına düşen (kümülatif olmayan) istek sayısı; son eleman son sınırı aşanlar
+	Count        int64
+	Sum          time.Duration
+	Min          time.Duration
+	Max          time.Duration
+}
+
+// normalizeBuckets sınırları sıralar, sıfır/negatif ve tekrarlı değerleri atar (boşsa varsayılanlar döner)
+func normalizeBuckets(bounds []time.Duration) []time.Duration {
+	normalized := make([]time.Duration, 0, len(bounds))
+	for _, bound := range bounds {
+		if bound > 0 {
+			normalized = append(normalized, bound)
+		}
+	}
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i] < normalized[j] })
+
+	unique := normalized[:0]
+	for i, bound := range normalized {
+		if i == 0 || bound != normalized[i-1] {
+			unique = append(unique, bound)
+		}
+	}
+	if len(unique) == 0 {
+		return DefaultResponseTimeBuckets
+	}
+	return unique
+}
+
+// newResponseTimeHistogram verilen (normalize edilmiş) sınırlarla boş histogram oluşturur
+func newResponseTimeHistogram(bounds []time.Duration) *ResponseTimeHistogram {
+	return &ResponseTimeHistogram{
+		Bounds:       bounds,
+		BucketCounts: make([]int64, len(bounds)+1),
+	}
+}
+
+// observe süreyi ilgili bucket'a ekler
+func (h *ResponseTimeHistogram) observe(elapsed time.Duration) {
+	if h.Count == 0 || elapsed < h.Min {
+		h.Min = elapsed
+	}
+	if elapsed > h.Max {
+		h.Max = elapsed
+	}
+	h.Count++
+	h.Sum += elapsed
+
+	index := sort.Search(len(h.Bounds), func(i int) bool { return elapsed <= h.Bounds[i] })
+	h.BucketCounts[index]++
+}
+
+// percentile p. yüzdelik dilimi tahmin eder: hedef sıranın düştüğü bucket içinde doğrusal interpolasyon yapılır,
+// bucket sınırları gözlenen min/max ile daraltılır (tahmin hiçbir zaman gerçek aralığın dışına çıkmaz)
+func (h *ResponseTimeHistogram) percentile(p float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+
+	rank := math.Ceil(p / 100 * float64(h.Count))
+	var cumulative int64
+	for i, count := range h.BucketCounts {
+		if count == 0 || float64(cumulative+count) < rank {
+			cumulative += count
+			continue
+		}
+
+		lower := h.Min
+		if i > 0 && h.Bounds[i-1] > lower {
+			lower = h.Bounds[i-1]
+		}
+		upper := h.Max
+		if i < len(h.Bounds) && h.Bounds[i] < upper {
+			upper = h.Bounds[i]
+		}
+
+		fraction := (rank - float64(cumulative)) / float64(count)
+		return lower + time.Duration(fraction*float64(upper-lower))
+	}
+	return h.Max
+}
+
+// stat histogram'dan JSON snapshot özetini üretir
+func (h *ResponseTimeHistogram) stat() ResponseTimeStat {
+	return ResponseTimeStat{
+		Count:   int(h.Count),
+		Average: h.Sum / time.Duration(h.Count),
+		Min:     h.Min,
+		Max:     h.Max,
+		P95:     h.percentile(95),
+		P99:     h.percentile(99),
+	}
+}
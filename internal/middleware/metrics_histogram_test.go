@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResponseTimeHistogram_UniformDistribution, 1-1000ms arası eşit dağılımda özet alanlarının doğru,
+// P95/P99 tahminlerinin ise ilgili bucket genişliği içinde kaldığını ve belleğin sabit olduğunu test eder.
+func TestResponseTimeHistogram_UniformDistribution(t *testing.T) {
+	// Arrange
+	histogram := newResponseTimeHistogram(normalizeBuckets(nil))
+
+	// Act
+	for i := 1; i <= 1000; i++ {
+		histogram.observe(time.Duration(i) * time.Millisecond)
+	}
+	stat := histogram.stat()
+
+	// Assert
+	assert.Equal(t, 1000, stat.Count)
+	assert.Equal(t, time.Millisecond, stat.Min)
+	assert.Equal(t, time.Second, stat.Max)
+	assert.Equal(t, 500500*time.Microsecond, stat.Average)
+
+	// Gerçek değerler 950ms ve 990ms; ikisi de (500ms, 1s] bucket'ında
+	bucketWidth := 500 * time.Millisecond
+	assert.InDelta(t, float64(950*time.Millisecond), float64(stat.P95), float64(bucketWidth))
+	assert.InDelta(t, float64(990*time.Millisecond), float64(stat.P99), float64(bucketWidth))
+	assert.LessOrEqual(t, stat.P95, stat.P99)
+	assert.Len(t, histogram.BucketCounts, len(DefaultResponseTimeBuckets)+1)
+}
+
+// TestResponseTimeHistogram_CustomBucketsSkewedDistribution, özel bucket'larla çoğu hızlı, az sayıda yavaş isteğin
+// olduğu dağılımda percentile tahminlerinin doğru bucket'a düştüğünü ve gözlenen max'ı aşmadığını test eder.
+func TestResponseTimeHistogram_CustomBucketsSkewedDistribution(t *testing.T) {
+	// Arrange: sırasız, tekrarlı ve geçersiz sınırlar normalize edilir
+	bounds := normalizeBuckets([]time.Duration{500 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond, 0, 250 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond})
+	require.Equal(t, []time.Duration{10 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond}, bounds)
+	histogram := newResponseTimeHistogram(bounds)
+
+	// Act: 90 istek 20ms, 10 istek 300ms, 1 istek son sınırı aşan 2s
+	for i := 0; i < 90; i++ {
+		histogram.observe(20 * time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		histogram.observe(300 * time.Millisecond)
+	}
+	histogram.observe(2 * time.Second)
+	stat := histogram.stat()
+
+	// Assert
+	assert.Equal(t, 101, stat.Count)
+	assert.Equal(t, 20*time.Millisecond, stat.Min)
+	assert.Equal(t, 2*time.Second, stat.Max)
+
+	// P95 (96. istek) gerçekte 300ms: (250ms, 500ms] bucket'ı
+	assert.GreaterOrEqual(t, stat.P95, 250*time.Millisecond)
+	assert.LessOrEqual(t, stat.P95, 500*time.Millisecond)
+
+	// P99 (100. istek) gerçekte 300ms; son bucket'ın tamamı bu sırayı kapsar
+	assert.GreaterOrEqual(t, stat.P99, 250*time.Millisecond)
+	assert.LessOrEqual(t, stat.P99, 500*time.Millisecond)
+
+	assert.Equal(t, []int64{0, 90, 0, 0, 10, 1}, histogram.BucketCounts)
+	assert.Zero(t, newResponseTimeHistogram(bounds).percentile(95), "boş histogram")
+}
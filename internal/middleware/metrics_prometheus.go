@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType Prometheus text exposition formatının content type'ı
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusNamespace metrik adlarının ön eki
+const prometheusNamespace = "payment_api"
+
+// wantsPrometheusFormat ?format=prometheus verilmişse veya Accept JSON istemeden text/plain ya da OpenMetrics
+// istiyorsa true döner (Prometheus scraper'ının varsayılan Accept header'ı bu koşulu sağlar)
+func wantsPrometheusFormat(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "prometheus":
+		return true
+	case "json":
+		return false
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "application/json") {
+		return false
+	}
+	return strings.Contains(accept, "text/plain") || strings.Contains(accept, "application/openmetrics-text")
+}
+
+// writePrometheusMetrics Metrics verisini Prometheus text exposition formatında yazar.
+// Label'lı seriler deterministik çıktı için sıralanır.
+func writePrometheusMetrics(w io.Writer, m *Metrics) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	writePrometheusHeader(w, "http_requests_total", "counter", "Toplam HTTP istek sayısı")
+	fmt.Fprintf(w, "%s_http_requests_total %d\n", prometheusNamespace, m.TotalRequests)
+
+	writePrometheusHeader(w, "http_requests_active", "gauge", "Şu an işlenmekte olan HTTP istek sayısı")
+	fmt.Fprintf(w, "%s_http_requests_active %d\n", prometheusNamespace, m.ActiveRequests)
+
+	writePrometheusHeader(w, "http_slow_requests_total", "counter", "Yavaş istek eşiğini aşan HTTP istek sayısı")
+	fmt.Fprintf(w, "%s_http_slow_requests_total %d\n", prometheusNamespace, m.SlowRequests)
+
+	writePrometheusHeader(w, "http_responses_total", "counter", "Status code bazında HTTP response sayısı")
+	codes := make([]int, 0, len(m.StatusCodeCounts))
+	for code := range m.StatusCodeCounts {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "%s_http_responses_total{code=\"%d\"} %d\n", prometheusNamespace, code, m.StatusCodeCounts[code])
+	}
+
+	writePrometheusHeader(w, "http_endpoint_requests_total", "counter", "Endpoint bazında HTTP istek sayısı")
+	for _, endpoint := range sortedKeys(m.EndpointCounts) {
+		fmt.Fprintf(w, "%s_http_endpoint_requests_total{endpoint=\"%s\"} %d\n", prometheusNamespace, escapeLabelValue(endpoint), m.EndpointCounts[endpoint])
+	}
+
+	writePrometheusHeader(w, "http_endpoint_errors_total", "counter", "Endpoint bazında 4xx/5xx HTTP response sayısı")
+	for _, endpoint := range sortedKeys(m.ErrorCounts) {
+		fmt.Fprintf(w, "%s_http_endpoint_errors_total{endpoint=\"%s\"} %d\n", prometheusNamespace, escapeLabelValue(endpoint), m.ErrorCounts[endpoint])
+	}
+
+	writePrometheusHeader(w, "http_request_duration_seconds", "histogram", "Endpoint bazında HTTP response süresi")
+	for _, endpoint := range sortedKeys(m.ResponseTimeHistograms) {
+		histogram := m.ResponseTimeHistograms[endpoint]
+		label := escapeLabelValue(endpoint)
+
+		var cumulative int64
+		for i, bound := range histogram.Bounds {
+			cumulative += histogram.BucketCounts[i]
+			fmt.Fprintf(w, "%s_http_request_duration_seconds_bucket{endpoint=\"%s\",le=\"%s\"} %d\n",
+				prometheusNamespace, label, strconv.FormatFloat(bound.Seconds(), 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "%s_http_request_duration_seconds_bucket{endpoint=\"%s\",le=\"+Inf\"} %d\n", prometheusNamespace, label, histogram.Count)
+		fmt.Fprintf(w, "%s_http_request_duration_seconds_sum{endpoint=\"%s\"} %s\n",
+			prometheusNamespace, label, strconv.FormatFloat(histogram.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(w, "%s_http_request_duration_seconds_count{endpoint=\"%s\"} %d\n", prometheusNamespace, label, histogram.Count)
+	}
+
+	writePrometheusHeader(w, "db_queries_total", "counter", "Request'ler içinde çalıştırılan toplam DB sorgusu")
+	fmt.Fprintf(w, "%s_db_queries_total %d\n", prometheusNamespace, m.TotalQueries)
+
+	writePrometheusHeader(w, "memory_usage_bytes", "gauge", "Son ölçülen heap kullanımı")
+	fmt.Fprintf(w, "%s_memory_usage_bytes %d\n", prometheusNamespace, m.MemoryUsage)
+}
+
+// writePrometheusHeader metriğin HELP ve TYPE satırlarını yazar
+func writePrometheusHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s_%s %s\n", prometheusNamespace, name, help)
+	fmt.Fprintf(w, "# TYPE %s_%s %s\n", prometheusNamespace, name, metricType)
+}
+
+// escapeLabelValue label değerindeki ters bölü, tırnak ve satır sonlarını exposition formatına göre kaçırır
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// sortedKeys map anahtarlarını sıralı döner
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
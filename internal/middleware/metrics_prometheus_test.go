@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prometheusSample exposition formatındaki tek bir örnek satırı
+type prometheusSample struct {
+	Labels string
+	Value  float64
+}
+
+// parsePrometheusText exposition çıktısını metrik adı → örnekler ve metrik adı → TYPE olarak ayrıştırır
+func parsePrometheusText(t *testing.T, body string) (map[string][]prometheusSample, map[string]string) {
+	t.Helper()
+
+	samples := make(map[string][]prometheusSample)
+	types := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.Fields(line)
+			require.Len(t, fields, 4, "geçersiz TYPE satırı: %s", line)
+			types[fields[2]] = fields[3]
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		separator := strings.LastIndex(line, " ")
+		require.Positive(t, separator, "geçersiz örnek satırı: %s", line)
+		value, err := strconv.ParseFloat(line[separator+1:], 64)
+		require.NoError(t, err, "geçersiz örnek değeri: %s", line)
+
+		series := line[:separator]
+		name, labels := series, ""
+		if open := strings.Index(series, "{"); open >= 0 {
+			require.True(t, strings.HasSuffix(series, "}"), "kapanmamış label seti: %s", line)
+			name, labels = series[:open], series[open+1:len(series)-1]
+		}
+		samples[name] = append(samples[name], prometheusSample{Labels: labels, Value: value})
+	}
+	require.NoError(t, scanner.Err())
+	return samples, types
+}
+
+// sampleValue verilen label setine sahip örneğin değerini döner
+func sampleValue(t *testing.T, samples []prometheusSample, labels string) float64 {
+	t.Helper()
+	for _, sample := range samples {
+		if sample.Labels == labels {
+			return sample.Value
+		}
+	}
+	require.FailNow(t, "örnek bulunamadı", "labels: %s", labels)
+	return 0
+}
+
+// TestMetricsHandler_PrometheusFormat, Prometheus Accept header'ıyla gelen isteğe sayaçların, status code ve
+// endpoint label'lı serilerin ve kümülatif response time histogram'ının text formatında döndüğünü test eder.
+func TestMetricsHandler_PrometheusFormat(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultMetricsConfig()
+	config.EnableMemoryTracking = false
+	metricsMW, metricsHandler := NewMetricsMiddleware(ctx, config)
+
+	router := mux.NewRouter()
+	router.Use(metricsMW)
+	router.HandleFunc("/api/v1/transactions/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/transactions/3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	for _, path := range []string{"/api/v1/transactions/1", "/api/v1/transactions/2", "/api/v1/transactions/3"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0;q=0.5,text/plain;version=0.0.4;q=0.4,*/*;q=0.1")
+	rec := httptest.NewRecorder()
+
+	// Act
+	metricsHandler(rec, req)
+
+	// Assert
+	assert.Equal(t, PrometheusContentType, rec.Header().Get("Content-Type"))
+	samples, types := parsePrometheusText(t, rec.Body.String())
+
+	assert.Equal(t, "counter", types["payment_api_http_requests_total"])
+	assert.Equal(t, "gauge", types["payment_api_http_requests_active"])
+	assert.Equal(t, "histogram", types["payment_api_http_request_duration_seconds"])
+	assert.Contains(t, samples, "payment_api_http_slow_requests_total")
+	assert.Contains(t, samples, "payment_api_memory_usage_bytes")
+
+	assert.Equal(t, 3.0, sampleValue(t, samples["payment_api_http_requests_total"], ""))
+	assert.Equal(t, 0.0, sampleValue(t, samples["payment_api_http_requests_active"], ""))
+	assert.Equal(t, 2.0, sampleValue(t, samples["payment_api_http_responses_total"], `code="200"`))
+	assert.Equal(t, 1.0, sampleValue(t, samples["payment_api_http_responses_total"], `code="404"`))
+
+	endpoint := `endpoint="/api/v1/transactions/{id:[0-9]+}"`
+	assert.Equal(t, 3.0, sampleValue(t, samples["payment_api_http_endpoint_requests_total"], endpoint))
+	assert.Equal(t, 1.0, sampleValue(t, samples["payment_api_http_endpoint_errors_total"], endpoint))
+	assert.Equal(t, 3.0, sampleValue(t, samples["payment_api_http_request_duration_seconds_count"], endpoint))
+	assert.Equal(t, 3.0, sampleValue(t, samples["payment_api_http_request_duration_seconds_bucket"], endpoint+`,le="+Inf"`))
+	assert.Contains(t, samples, "payment_api_http_request_duration_seconds_sum")
+
+	buckets := samples["payment_api_http_request_duration_seconds_bucket"]
+	require.Len(t, buckets, len(DefaultResponseTimeBuckets)+1)
+	for i := 1; i < len(buckets); i++ {
+		assert.GreaterOrEqual(t, buckets[i].Value, buckets[i-1].Value, "bucket'lar kümülatif olmalı")
+	}
+}
+
+// TestMetricsHandler_FormatNegotiation, varsayılan ve JSON isteyen isteklerin JSON snapshot'ı,
+// format=prometheus parametresinin ise text formatını aldığını test eder.
+func TestMetricsHandler_FormatNegotiation(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultMetricsConfig()
+	config.EnableMemoryTracking = false
+	_, metricsHandler := NewMetricsMiddleware(ctx, config)
+
+	serve := func(target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		metricsHandler(rec, req)
+		return rec
+	}
+
+	// Act
+	defaultRec := serve("/metrics", "")
+	browserRec := serve("/metrics", "text/html,application/xhtml+xml,*/*;q=0.8")
+	jsonRec := serve("/metrics", "application/json, text/plain")
+	queryRec := serve("/metrics?format=prometheus", "")
+
+	// Assert
+	assert.Contains(t, defaultRec.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, browserRec.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, jsonRec.Header().Get("Content-Type"), "application/json")
+	assert.Equal(t, PrometheusContentType, queryRec.Header().Get("Content-Type"))
+	assert.Contains(t, queryRec.Body.String(), "# TYPE payment_api_http_requests_total counter")
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryMonitor_RecoversFromPanic, ölçüm kaynağı paniklediğinde monitor'ün yeniden başlayıp devam ettiğini test eder.
+func TestMemoryMonitor_RecoversFromPanic(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	config := DefaultMetricsConfig()
+	config.MemoryCheckInterval = 5 * time.Millisecond
+	config.MemoryStatsSource = func() uint64 {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("ölçüm kaynağı patladı")
+		}
+		return 4242
+	}
+
+	m := &Metrics{}
+
+	// Act
+	go runMemoryMonitor(ctx, m, config)
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+		return m.MemoryUsage == 4242
+	}, time.Second, 5*time.Millisecond)
+}
+
+// TestMemoryMonitor_StopsAfterMaxRestarts, sürekli panik durumunda restart limitinde durduğunu test eder.
+func TestMemoryMonitor_StopsAfterMaxRestarts(t *testing.T) {
+	// Arrange
+	var calls int32
+	config := DefaultMetricsConfig()
+	config.MemoryCheckInterval = time.Millisecond
+	config.MaxMonitorRestarts = 2
+	config.MemoryStatsSource = func() uint64 {
+		atomic.AddInt32(&calls, 1)
+		panic("her zaman patlar")
+	}
+
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		runMemoryMonitor(context.Background(), &Metrics{}, config)
+		close(done)
+	}()
+
+	// Assert
+	select {
+	case <-done:
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	case <-time.After(time.Second):
+		t.Fatal("monitor restart limitinde durmadı")
+	}
+}
+
+// TestMetricsMiddleware_GroupsByRouteTemplate, endpoint metriklerinin route template'e göre gruplandığını test eder.
+func TestMetricsMiddleware_GroupsByRouteTemplate(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultMetricsConfig()
+	config.EnableMemoryTracking = false
+	metricsMW, metricsHandler := NewMetricsMiddleware(ctx, config)
+
+	router := mux.NewRouter()
+	router.Use(metricsMW)
+	router.HandleFunc("/api/v1/transactions/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Act
+	for _, path := range []string{"/api/v1/transactions/1", "/api/v1/transactions/2", "/api/v1/transactions/3"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	var snapshot MetricsSnapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+	assert.Equal(t, map[string]int64{"/api/v1/transactions/{id:[0-9]+}": 3}, snapshot.EndpointCounts)
+}
+
+// TestMetricsMiddleware_PrunesStaleEndpoints, TTL boyunca istek gelmeyen endpoint'in metriklerinin snapshot'tan
+// silindiğini, aktif endpoint'in ve toplam sayaçların korunduğunu test eder.
+func TestMetricsMiddleware_PrunesStaleEndpoints(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	config := DefaultMetricsConfig()
+	config.EnableMemoryTracking = false
+	config.GroupByRouteTemplate = false
+	config.EndpointTTL = 10 * time.Minute
+	config.PruneInterval = time.Hour // Periyodik tur test sırasında çalışmasın
+	config.Clock = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	metricsMW, metricsHandler := NewMetricsMiddleware(ctx, config)
+
+	handler := metricsMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	hit := func(path string) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	snapshot := func() MetricsSnapshot {
+		rec := httptest.NewRecorder()
+		metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		var s MetricsSnapshot
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+		return s
+	}
+
+	// Act
+	hit("/gecici-404")
+	advance(5 * time.Minute)
+	hit("/aktif")
+	withinWindow := snapshot()
+
+	advance(6 * time.Minute) // /gecici-404 11 dk, /aktif 6 dk önce görüldü
+	afterWindow := snapshot()
+
+	// Assert
+	assert.Contains(t, withinWindow.EndpointCounts, "/gecici-404")
+	assert.Equal(t, map[string]int64{"/aktif": 1}, afterWindow.EndpointCounts)
+	assert.NotContains(t, afterWindow.ResponseTimeSummary, "/gecici-404")
+	assert.Contains(t, afterWindow.ResponseTimeSummary, "/aktif")
+	assert.Equal(t, int64(2), afterWindow.TotalRequests)
+}
+
+// TestMetricsMiddleware_EndpointErrorRate, 4xx/5xx response'ların ID'li path yerine route template altında
+// sayıldığını ve snapshot'taki hata oranının endpoint'in kendi istek sayısına göre hesaplandığını test eder.
+func TestMetricsMiddleware_EndpointErrorRate(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultMetricsConfig()
+	config.EnableMemoryTracking = false
+	metricsMW, metricsHandler := NewMetricsMiddleware(ctx, config)
+
+	router := mux.NewRouter()
+	router.Use(metricsMW)
+	router.HandleFunc("/api/v1/users/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		switch mux.Vars(r)["id"] {
+		case "404":
+			w.WriteHeader(http.StatusNotFound)
+		case "500":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+	router.HandleFunc("/api/v1/balances/current", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Act
+	for _, path := range []string{"/api/v1/users/1", "/api/v1/users/404", "/api/v1/users/500", "/api/v1/users/42", "/api/v1/balances/current"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	var snapshot MetricsSnapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+
+	assert.Len(t, snapshot.EndpointErrors, 2)
+	assert.NotContains(t, snapshot.EndpointErrors, "/api/v1/users/404")
+	assert.Equal(t, ErrorRateStat{Requests: 4, Errors: 2, ErrorRate: 0.5}, snapshot.EndpointErrors["/api/v1/users/{id:[0-9]+}"])
+	assert.Equal(t, ErrorRateStat{Requests: 1}, snapshot.EndpointErrors["/api/v1/balances/current"])
+}
+
+// TestMetricsMiddleware_IDPathsCollapseToTemplate, farklı ID'li isteklerin sayaç, response time ve sorgu
+// özetlerinde tek {id} template anahtarında toplandığını, route'a eşleşmeyen isteklerin ise ham path yerine
+// tek bir "unmatched" anahtarında kaldığını test eder.
+func TestMetricsMiddleware_IDPathsCollapseToTemplate(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultMetricsConfig()
+	config.EnableMemoryTracking = false
+	metricsMW, metricsHandler := NewMetricsMiddleware(ctx, config)
+
+	router := mux.NewRouter()
+	router.Use(metricsMW)
+	router.HandleFunc("/api/v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	unmatched := metricsMW(http.NotFoundHandler())
+
+	// Act
+	for _, id := range []string{"1", "2", "42", "1001"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/"+id, nil))
+	}
+	for _, path := range []string{"/wp-admin/1", "/wp-admin/2"} {
+		unmatched.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	var snapshot MetricsSnapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+
+	assert.Equal(t, map[string]int64{"/api/v1/users/{id}": 4, UnmatchedRouteTemplate: 2}, snapshot.EndpointCounts)
+	require.Contains(t, snapshot.ResponseTimeSummary, "/api/v1/users/{id}")
+	assert.Equal(t, 4, snapshot.ResponseTimeSummary["/api/v1/users/{id}"].Count)
+	assert.Len(t, snapshot.ResponseTimeSummary, 2)
+	assert.Len(t, snapshot.QueryCountSummary, 2)
+}
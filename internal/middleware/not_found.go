@@ -1,13 +1,16 @@
 package middleware
 
 import (
-	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 
 	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/middleware/validation"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 // NotFoundJSONHandler JSON formatında 404 Not Found döner
@@ -26,12 +29,8 @@ func NotFoundJSONHandler() http.HandlerFunc {
 			},
 		}
 
-		// Header'ları set et
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-
-		// JSON encode et
-		if err := json.NewEncoder(w).Encode(response); err != nil {
+		// JSON response gönder
+		if err := utils.WriteJSON(w, http.StatusNotFound, response); err != nil {
 			// Fallback: JSON encode başarısızsa plain text
 			log.Error().Err(err).Msg("NotFound JSON encoding failed")
 			http.Error(w, "Not Found", http.StatusNotFound)
@@ -49,8 +48,14 @@ func NotFoundJSONHandler() http.HandlerFunc {
 }
 
 // MethodNotAllowedJSONHandler JSON formatında 405 Method Not Allowed döner
-func MethodNotAllowedJSONHandler() http.HandlerFunc {
+// Router verilirse path için kayıtlı metodlar Allow header'ında döner
+func MethodNotAllowedJSONHandler(router *mux.Router) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowedMethods := validation.AllowedMethodsForPath(router, r)
+		if len(allowedMethods) > 0 {
+			w.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+		}
+
 		// ErrorResponse struct'ını kullan
 		response := errors.ErrorResponse{
 			Success:   false,
@@ -59,18 +64,14 @@ func MethodNotAllowedJSONHandler() http.HandlerFunc {
 			Timestamp: time.Now().Format(time.RFC3339),
 			RequestID: w.Header().Get("X-Request-ID"),
 			Details: map[string]interface{}{
-				"method":       r.Method,
-				"path":         r.URL.Path,
-				"allowed_info": "Desteklenen metodlar için OPTIONS isteği gönderin",
+				"method":          r.Method,
+				"path":            r.URL.Path,
+				"allowed_methods": allowedMethods,
 			},
 		}
 
-		// Header'ları set et
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-
-		// JSON encode et
-		if err := json.NewEncoder(w).Encode(response); err != nil {
+		// JSON response gönder
+		if err := utils.WriteJSON(w, http.StatusMethodNotAllowed, response); err != nil {
 			// Fallback: JSON encode başarısızsa plain text
 			log.Error().Err(err).Msg("MethodNotAllowed JSON encoding failed")
 			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
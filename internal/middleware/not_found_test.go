@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNotFoundJSONHandler_SetsCharset, JSON yanıtında utf-8 charset'inin bulunduğunu test eder.
+func TestNotFoundJSONHandler_SetsCharset(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	NotFoundJSONHandler().ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+}
+
+// TestMethodNotAllowedJSONHandler_SetsAllowHeader, kayıtlı olmayan metodun Allow header'lı 405 döndüğünü test eder.
+func TestMethodNotAllowedJSONHandler_SetsAllowHeader(t *testing.T) {
+	// Arrange
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/transactions/credit", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPost)
+	router.MethodNotAllowedHandler = MethodNotAllowedJSONHandler(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/credit", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+}
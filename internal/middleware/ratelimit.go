@@ -1,14 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
-	"golang.org/x/time/rate"
 
 	"github.com/onerilhan/go-payment-api/internal/utils"
 )
@@ -22,6 +21,9 @@ type RateLimitConfig struct {
 	BlacklistIPs      []string
 	SkipPaths         []string
 	CustomMessage     string
+	Metrics           *RateLimitMetrics // Engelleme sayaçları (nil = metrik toplanmaz)
+
+	Store LimiterStore // Limit durumunun tutulduğu backend (nil = process içi MemoryLimiterStore)
 }
 
 // DefaultRateLimitConfig varsayılan rate limit ayarları
@@ -34,24 +36,17 @@ func DefaultRateLimitConfig() *RateLimitConfig {
 		BlacklistIPs:      []string{},
 		SkipPaths: []string{
 			"/health",
+			"/ready",
 			"/favicon.ico",
 		},
 		CustomMessage: "Rate limit exceeded. Please try again later.",
 	}
 }
 
-// ipLimiter tek bir IP için rate limiter
-type ipLimiter struct {
-	limiter     *rate.Limiter
-	lastSeen    time.Time
-	windowStart time.Time
-}
-
 // RateLimitMiddleware rate limiting middleware
 type RateLimitMiddleware struct {
-	config   *RateLimitConfig
-	limiters map[string]*ipLimiter
-	mutex    sync.RWMutex
+	config *RateLimitConfig
+	store  LimiterStore
 }
 
 // NewRateLimitMiddleware yeni rate limit middleware oluşturur
@@ -60,13 +55,20 @@ func NewRateLimitMiddleware(config *RateLimitConfig) *RateLimitMiddleware {
 		config = DefaultRateLimitConfig()
 	}
 
+	store := config.Store
+	if store == nil {
+		store = NewMemoryLimiterStore(config)
+	}
+
 	middleware := &RateLimitMiddleware{
-		config:   config,
-		limiters: make(map[string]*ipLimiter),
-		mutex:    sync.RWMutex{},
+		config: config,
+		store:  store,
 	}
 
-	go middleware.cleanupLimiters()
+	// Paylaşılan backend'lerde (Redis) anahtar sayısı instance'tan okunamaz, metrik sadece sayabilen store'larda bağlanır
+	if counter, ok := store.(activeKeyCounter); ok && config.Metrics != nil {
+		config.Metrics.setActiveKeysSource(counter.ActiveKeys)
+	}
 
 	return middleware
 }
@@ -84,6 +86,7 @@ func (rlm *RateLimitMiddleware) Handler() func(http.Handler) http.Handler {
 
 			if rlm.isBlacklisted(clientIP) {
 				log.Warn().Str("client_ip", clientIP).Msg("Request blocked - IP blacklisted")
+				rlm.recordBlock(RateLimitReasonBlacklist, r)
 				rlm.sendRateLimitResponse(w, "IP address is blacklisted", 403, 0, time.Now())
 				return
 			}
@@ -93,12 +96,13 @@ func (rlm *RateLimitMiddleware) Handler() func(http.Handler) http.Handler {
 				return
 			}
 
-			allowed, remaining, resetTime := rlm.checkRateLimit(clientIP)
+			allowed, remaining, resetTime := rlm.checkRateLimit(r.Context(), clientIP)
 
 			rlm.setRateLimitHeaders(w, remaining, resetTime)
 
 			if !allowed {
 				log.Warn().Str("client_ip", clientIP).Msg("Request blocked - rate limit exceeded")
+				rlm.recordBlock(RateLimitReasonRate, r)
 				rlm.sendRateLimitResponse(w, rlm.config.CustomMessage, 429, remaining, resetTime)
 				return
 			}
@@ -108,41 +112,23 @@ func (rlm *RateLimitMiddleware) Handler() func(http.Handler) http.Handler {
 	}
 }
 
-// checkRateLimit IP'nin rate limit'ini kontrol eder
-func (rlm *RateLimitMiddleware) checkRateLimit(ip string) (allowed bool, remaining int, resetTime time.Time) {
-	rlm.mutex.Lock()
-	defer rlm.mutex.Unlock()
-
-	now := time.Now()
-
-	limiter, exists := rlm.limiters[ip]
-	if !exists {
-		rateLimit := rate.Every(rlm.config.WindowSize / time.Duration(rlm.config.RequestsPerMinute))
-		limiter = &ipLimiter{
-			limiter:     rate.NewLimiter(rateLimit, rlm.config.Burst),
-			lastSeen:    now,
-			windowStart: now,
-		}
-		rlm.limiters[ip] = limiter
-	}
-
-	limiter.lastSeen = now
-
-	if now.Sub(limiter.windowStart) >= rlm.config.WindowSize {
-		limiter.windowStart = now
+// checkRateLimit IP'nin rate limit'ini store üzerinden kontrol eder.
+// Store'a ulaşılamazsa istek engellenmez (fail-open): backend arızası API'yi durdurmamalı.
+func (rlm *RateLimitMiddleware) checkRateLimit(ctx context.Context, ip string) (allowed bool, remaining int, resetTime time.Time) {
+	decision, err := rlm.store.Take(ctx, ip)
+	if err != nil {
+		log.Warn().Err(err).Str("client_ip", ip).Msg("Rate limit store'a ulaşılamadı, istek limitsiz geçiriliyor")
+		return true, rlm.config.Burst, time.Now().Add(rlm.config.WindowSize)
 	}
+	return decision.Allowed, decision.Remaining, decision.ResetAt
+}
 
-	allowed = limiter.limiter.Allow()
-
-	tokens := limiter.limiter.Tokens()
-	remaining = int(tokens)
-	if remaining < 0 {
-		remaining = 0
+// recordBlock engellenen request'i paylaşılan metrik toplayıcısına bildirir (path: route template)
+func (rlm *RateLimitMiddleware) recordBlock(reason string, r *http.Request) {
+	if rlm.config.Metrics == nil {
+		return
 	}
-
-	resetTime = limiter.windowStart.Add(rlm.config.WindowSize)
-
-	return allowed, remaining, resetTime
+	rlm.config.Metrics.RecordBlock(reason, RouteTemplate(r))
 }
 
 // setRateLimitHeaders rate limit header'larını set eder
@@ -185,7 +171,7 @@ func (rlm *RateLimitMiddleware) isBlacklisted(ip string) bool {
 
 // sendRateLimitResponse rate limit response
 func (rlm *RateLimitMiddleware) sendRateLimitResponse(w http.ResponseWriter, message string, statusCode int, remaining int, resetTime time.Time) {
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", utils.JSONContentType())
 
 	retryAfterSeconds := int(time.Until(resetTime).Seconds())
 	if retryAfterSeconds < 0 {
@@ -211,27 +197,6 @@ func (rlm *RateLimitMiddleware) sendRateLimitResponse(w http.ResponseWriter, mes
 	json.NewEncoder(w).Encode(response)
 }
 
-// / cleanupLimiters eski limiter'ları temizler
-func (rlm *RateLimitMiddleware) cleanupLimiters() {
-	ticker := time.NewTicker(10 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		rlm.mutex.Lock()
-
-		now := time.Now()
-		for ip, limiter := range rlm.limiters {
-			if now.Sub(limiter.lastSeen) > 30*time.Minute {
-				delete(rlm.limiters, ip)
-			}
-		}
-
-		log.Debug().Int("active_limiters", len(rlm.limiters)).Msg("Rate limiter cleanup completed")
-
-		rlm.mutex.Unlock()
-	}
-}
-
 // RateLimitMiddlewareWithDefaults varsayılan ayarlarla middleware döner
 func RateLimitMiddlewareWithDefaults() func(http.Handler) http.Handler {
 	middleware := NewRateLimitMiddleware(DefaultRateLimitConfig())
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"sync"
+)
+
+// Rate limit engelleme nedenleri (ratelimit_blocked_total etiketi)
+const (
+	RateLimitReasonRate      = "rate"
+	RateLimitReasonBlacklist = "blacklist"
+)
+
+// RateLimitMetrics rate limit middleware'lerinin paylaştığı engelleme sayacı.
+// Metrics middleware'ine verilirse snapshot'a rate_limit bölümü olarak eklenir.
+type RateLimitMetrics struct {
+	mutex         sync.Mutex
+	blockedTotal  map[string]int64 // nedene göre (rate | blacklist)
+	blockedByPath map[string]int64 // route template'e göre
+	activeKeys    func() int       // Takip edilen limiter (IP) sayısı kaynağı
+}
+
+// RateLimitSnapshot rate limit metriklerinin JSON görünümü
+type RateLimitSnapshot struct {
+	BlockedTotal  map[string]int64 `json:"ratelimit_blocked_total"`
+	ActiveKeys    int              `json:"ratelimit_active_keys"`
+	BlockedByPath map[string]int64 `json:"ratelimit_blocked_by_path"`
+}
+
+// NewRateLimitMetrics yeni rate limit metrik toplayıcısı oluşturur
+func NewRateLimitMetrics() *RateLimitMetrics {
+	return &RateLimitMetrics{
+		blockedTotal:  make(map[string]int64),
+		blockedByPath: make(map[string]int64),
+	}
+}
+
+// RecordBlock engellenen bir request'i neden ve path bazında sayar
+func (m *RateLimitMetrics) RecordBlock(reason, path string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.blockedTotal[reason]++
+	m.blockedByPath[path]++
+}
+
+// setActiveKeysSource aktif limiter sayısının okunacağı fonksiyonu bağlar
+func (m *RateLimitMetrics) setActiveKeysSource(source func() int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.activeKeys = source
+}
+
+// Snapshot sayaçların anlık kopyasını döner
+func (m *RateLimitMetrics) Snapshot() *RateLimitSnapshot {
+	m.mutex.Lock()
+	activeKeys := m.activeKeys
+	snapshot := &RateLimitSnapshot{
+		BlockedTotal:  copyMap(m.blockedTotal),
+		BlockedByPath: copyMap(m.blockedByPath),
+	}
+	m.mutex.Unlock()
+
+	// Kaynak kendi kilidini alır; sayaç kilidi tutulurken çağrılmaz
+	if activeKeys != nil {
+		snapshot.ActiveKeys = activeKeys()
+	}
+	return snapshot
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedisClient verilen miniredis sunucusuna bağlanan client döner
+func newTestRedisClient(t *testing.T, server *miniredis.Miniredis) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), PoolSize: 2})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// TestRedisLimiterStore_SharedAcrossInstances, ayrı client'larla açılmış iki store'un (iki API instance'ı)
+// aynı IP için tek bir limiti paylaştığını ve farklı IP'lerin birbirini etkilemediğini test eder.
+func TestRedisLimiterStore_SharedAcrossInstances(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	rateLimitConfig := DefaultRateLimitConfig()
+	rateLimitConfig.Burst = 3
+
+	instanceA := NewRedisLimiterStore(newTestRedisClient(t, server), rateLimitConfig, "ratelimit:")
+	instanceB := NewRedisLimiterStore(newTestRedisClient(t, server), rateLimitConfig, "ratelimit:")
+
+	// Act
+	var decisions []LimiterDecision
+	for _, store := range []LimiterStore{instanceA, instanceB, instanceA, instanceB} {
+		decision, err := store.Take(ctx, "10.0.0.1")
+		require.NoError(t, err)
+		decisions = append(decisions, decision)
+	}
+	other, err := instanceB.Take(ctx, "10.0.0.2")
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, decisions[0].Allowed)
+	assert.True(t, decisions[1].Allowed)
+	assert.True(t, decisions[2].Allowed)
+	assert.False(t, decisions[3].Allowed)
+	assert.Equal(t, 0, decisions[3].Remaining)
+	assert.True(t, decisions[3].ResetAt.After(time.Now()))
+	assert.True(t, other.Allowed)
+	assert.Equal(t, 2, other.Remaining)
+	assert.True(t, server.Exists("ratelimit:10.0.0.1"))
+}
+
+// TestRedisLimiterStore_ReloadsFlushedScript, Redis'in script cache'i boşaltıldıktan sonra (ör. restart)
+// Script.Run'ın EVAL'e düşüp limiti uygulamaya devam ettiğini test eder.
+func TestRedisLimiterStore_ReloadsFlushedScript(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := newTestRedisClient(t, server)
+	store := NewRedisLimiterStore(client, DefaultRateLimitConfig(), "ratelimit:")
+
+	_, err := store.Take(ctx, "10.0.0.1")
+	require.NoError(t, err)
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+
+	// Act
+	decision, err := store.Take(ctx, "10.0.0.1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, DefaultRateLimitConfig().Burst-2, decision.Remaining)
+}
+
+// TestRedisLimiterStore_UnavailableReturnsError, Redis'e ulaşılamadığında Take'in hata döndüğünü test eder
+// (middleware bu durumda isteği engellemez).
+func TestRedisLimiterStore_UnavailableReturnsError(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	store := NewRedisLimiterStore(newTestRedisClient(t, server), DefaultRateLimitConfig(), "ratelimit:")
+	server.Close()
+
+	// Act
+	_, err := store.Take(context.Background(), "10.0.0.1")
+
+	// Assert
+	assert.ErrorContains(t, err, "rate limit script'i çalıştırılamadı")
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+// Rate limit backend'leri (RATE_LIMIT_BACKEND)
+const (
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendRedis  = "redis"
+)
+
+// LimiterDecision tek bir istek için limit kararı
+type LimiterDecision struct {
+	Allowed   bool
+	Remaining int       // Kalan istek hakkı
+	ResetAt   time.Time // Header'lardaki X-RateLimit-Reset / Retry-After kaynağı
+}
+
+// LimiterStore IP bazlı limit durumunu tutan backend.
+// Process içi store tek instance için yeterlidir; birden fazla API instance'ı limitin paylaşılması için Redis store kullanır.
+type LimiterStore interface {
+	// Take key için bir istek hakkı harcamaya çalışır
+	Take(ctx context.Context, key string) (LimiterDecision, error)
+}
+
+// activeKeyCounter takip ettiği anahtar sayısını sayabilen store'lar (metrik snapshot'ı için)
+type activeKeyCounter interface {
+	ActiveKeys() int
+}
+
+// ipLimiter tek bir IP için rate limiter
+type ipLimiter struct {
+	limiter     *rate.Limiter
+	lastSeen    time.Time
+	windowStart time.Time
+}
+
+// MemoryLimiterStore limiter'ları process içi map'te tutan store (restart'ta sıfırlanır, instance'lar arasında paylaşılmaz)
+type MemoryLimiterStore struct {
+	config   *RateLimitConfig
+	limiters map[string]*ipLimiter
+	mutex    sync.RWMutex
+}
+
+// NewMemoryLimiterStore process içi store oluşturur ve boşta kalan limiter'ların temizliğini başlatır
+func NewMemoryLimiterStore(config *RateLimitConfig) *MemoryLimiterStore {
+	if config == nil {
+		config = DefaultRateLimitConfig()
+	}
+
+	store := &MemoryLimiterStore{
+		config:   config,
+		limiters: make(map[string]*ipLimiter),
+	}
+
+	go store.cleanupLimiters()
+
+	return store
+}
+
+// Take IP'nin token bucket'ından bir token harcar
+func (s *MemoryLimiterStore) Take(ctx context.Context, key string) (LimiterDecision, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	limiter, exists := s.limiters[key]
+	if !exists {
+		rateLimit := rate.Every(s.config.WindowSize / time.Duration(s.config.RequestsPerMinute))
+		limiter = &ipLimiter{
+			limiter:     rate.NewLimiter(rateLimit, s.config.Burst),
+			lastSeen:    now,
+			windowStart: now,
+		}
+		s.limiters[key] = limiter
+	}
+
+	limiter.lastSeen = now
+
+	if now.Sub(limiter.windowStart) >= s.config.WindowSize {
+		limiter.windowStart = now
+	}
+
+	allowed := limiter.limiter.Allow()
+
+	remaining := int(limiter.limiter.Tokens())
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return LimiterDecision{
+		Allowed:   allowed,
+		Remaining: remaining,
+		ResetAt:   limiter.windowStart.Add(s.config.WindowSize),
+	}, nil
+}
+
+// ActiveKeys takip edilen limiter (IP) sayısını döner
+func (s *MemoryLimiterStore) ActiveKeys() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.limiters)
+}
+
+// cleanupLimiters eski limiter'ları temizler
+func (s *MemoryLimiterStore) cleanupLimiters() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mutex.Lock()
+
+		now := time.Now()
+		for ip, limiter := range s.limiters {
+			if now.Sub(limiter.lastSeen) > 30*time.Minute {
+				delete(s.limiters, ip)
+			}
+		}
+
+		log.Debug().Int("active_limiters", len(s.limiters)).Msg("Rate limiter cleanup completed")
+
+		s.mutex.Unlock()
+	}
+}
+
+// tokenBucketScript IP'nin token bucket'ını tek atomik adımda doldurur ve bir token harcar.
+// Zaman Redis'in saatinden alınır, böylece instance'lar arası saat farkı limiti etkilemez.
+// Dönüş: {allowed, kalan token, reset'e kalan ms, redis zamanı (ms)}
+var tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local wait = (1 - tokens) / rate
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+	wait = (burst - tokens) / rate
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+
+return {allowed, math.floor(tokens), math.ceil(wait), now}
+`)
+
+// RedisLimiterStore token bucket'ları Redis'te tutan, instance'lar arasında paylaşılan store
+type RedisLimiterStore struct {
+	client    redis.Scripter
+	config    *RateLimitConfig
+	keyPrefix string
+}
+
+// NewRedisLimiterStore Redis store oluşturur; anahtarlar keyPrefix + IP olarak tutulur
+func NewRedisLimiterStore(client redis.Scripter, config *RateLimitConfig, keyPrefix string) *RedisLimiterStore {
+	if config == nil {
+		config = DefaultRateLimitConfig()
+	}
+	return &RedisLimiterStore{client: client, config: config, keyPrefix: keyPrefix}
+}
+
+// Take IP'nin Redis'teki token bucket'ından bir token harcar
+func (s *RedisLimiterStore) Take(ctx context.Context, key string) (LimiterDecision, error) {
+	tokensPerMs := float64(s.config.RequestsPerMinute) / float64(s.config.WindowSize.Milliseconds())
+
+	// Script.Run önce EVALSHA dener, script Redis'te yoksa EVAL ile gönderir
+	numbers, err := tokenBucketScript.Run(ctx, s.client, []string{s.keyPrefix + key}, tokensPerMs, s.config.Burst).Int64Slice()
+	if err != nil {
+		return LimiterDecision{}, fmt.Errorf("rate limit script'i çalıştırılamadı: %w", err)
+	}
+	if len(numbers) != 4 {
+		return LimiterDecision{}, fmt.Errorf("beklenmeyen rate limit script yanıtı: %v", numbers)
+	}
+
+	return LimiterDecision{
+		Allowed:   numbers[0] == 1,
+		Remaining: int(numbers[1]),
+		ResetAt:   time.UnixMilli(numbers[3] + numbers[2]),
+	}, nil
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRateLimitMiddleware_BlockIncrementsMetrics, engellenen request'lerin neden ve route bazında sayıldığını ve snapshot'a eklendiğini test eder.
+func TestRateLimitMiddleware_BlockIncrementsMetrics(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rateLimitMetrics := NewRateLimitMetrics()
+
+	rateLimitConfig := DefaultRateLimitConfig()
+	rateLimitConfig.Burst = 1
+	rateLimitConfig.BlacklistIPs = []string{"10.0.0.66"}
+	rateLimitConfig.Metrics = rateLimitMetrics
+
+	metricsConfig := DefaultMetricsConfig()
+	metricsConfig.EnableMemoryTracking = false
+	metricsConfig.RateLimitMetrics = rateLimitMetrics
+	metricsMW, metricsHandler := NewMetricsMiddleware(ctx, metricsConfig)
+
+	router := mux.NewRouter()
+	router.Use(metricsMW)
+	router.Use(NewRateLimitMiddleware(rateLimitConfig).Handler())
+	router.HandleFunc("/api/v1/transactions/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	newRequest := func(ip string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/1", nil)
+		req.Header.Set("X-Real-IP", ip)
+		return req
+	}
+
+	// Act: burst 1 olduğu için ikinci istek rate limit'e takılır
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, newRequest("10.0.0.1"))
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, newRequest("10.0.0.1"))
+	blacklisted := httptest.NewRecorder()
+	router.ServeHTTP(blacklisted, newRequest("10.0.0.66"))
+
+	rec := httptest.NewRecorder()
+	metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, http.StatusForbidden, blacklisted.Code)
+
+	var snapshot MetricsSnapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+	require.NotNil(t, snapshot.RateLimit)
+	assert.Equal(t, map[string]int64{RateLimitReasonRate: 1, RateLimitReasonBlacklist: 1}, snapshot.RateLimit.BlockedTotal)
+	assert.Equal(t, map[string]int64{"/api/v1/transactions/{id:[0-9]+}": 2}, snapshot.RateLimit.BlockedByPath)
+	assert.Equal(t, 1, snapshot.RateLimit.ActiveKeys)
+}
+
+// TestRateLimitMiddleware_NoMetricsConfigured, metrik toplayıcısı verilmediğinde engellemenin yine çalıştığını test eder.
+func TestRateLimitMiddleware_NoMetricsConfigured(t *testing.T) {
+	// Arrange
+	rateLimitConfig := DefaultRateLimitConfig()
+	rateLimitConfig.BlacklistIPs = []string{"10.0.0.66"}
+	handler := NewRateLimitMiddleware(rateLimitConfig).Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	req.Header.Set("X-Real-IP", "10.0.0.66")
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+// serveRateLimited handler'a verilen IP'den tek istek gönderir ve status code'u döner
+func serveRateLimited(handler http.Handler, ip string) int {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	req.Header.Set("X-Real-IP", ip)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// TestRateLimitMiddleware_SharedStoreAcrossInstances, aynı store'u kullanan iki middleware instance'ının limiti
+// ayrı ayrı değil birlikte tükettiğini test eder (Redis store'un çoklu instance'ta sağladığı davranış).
+func TestRateLimitMiddleware_SharedStoreAcrossInstances(t *testing.T) {
+	// Arrange
+	rateLimitConfig := DefaultRateLimitConfig()
+	rateLimitConfig.Burst = 2
+	rateLimitConfig.Store = NewMemoryLimiterStore(rateLimitConfig)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	instanceA := NewRateLimitMiddleware(rateLimitConfig).Handler()(ok)
+	instanceB := NewRateLimitMiddleware(rateLimitConfig).Handler()(ok)
+
+	// Act
+	codes := []int{
+		serveRateLimited(instanceA, "10.0.0.1"),
+		serveRateLimited(instanceB, "10.0.0.1"),
+		serveRateLimited(instanceA, "10.0.0.1"),
+		serveRateLimited(instanceB, "10.0.0.2"),
+	}
+
+	// Assert
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK}, codes)
+}
+
+// failingLimiterStore her çağrıda hata dönen store (ulaşılamayan Redis)
+type failingLimiterStore struct{}
+
+func (failingLimiterStore) Take(ctx context.Context, key string) (LimiterDecision, error) {
+	return LimiterDecision{}, errors.New("dial tcp: connection refused")
+}
+
+// TestRateLimitMiddleware_StoreErrorFailsOpen, store'a ulaşılamadığında isteklerin engellenmediğini test eder.
+func TestRateLimitMiddleware_StoreErrorFailsOpen(t *testing.T) {
+	// Arrange
+	rateLimitConfig := DefaultRateLimitConfig()
+	rateLimitConfig.Burst = 1
+	rateLimitConfig.Store = failingLimiterStore{}
+	handler := NewRateLimitMiddleware(rateLimitConfig).Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Act
+	first := serveRateLimited(handler, "10.0.0.1")
+	second := serveRateLimited(handler, "10.0.0.1")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first)
+	assert.Equal(t, http.StatusOK, second)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/onerilhan/go-payment-api/internal/auth"
 	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/models"
 )
 
 // Permission represents a specific permission
@@ -33,6 +34,7 @@ const (
 	PermViewAnyBalance      Permission = "view_any_balance"
 	PermViewAllTransactions Permission = "view_all_transactions"
 	PermSystemManagement    Permission = "system_management"
+	PermReverseTransaction  Permission = "reverse_transaction"
 
 	// Moderator permissions
 	PermViewUserList     Permission = "view_user_list"
@@ -50,6 +52,14 @@ var RolePermissions = map[string][]Permission{
 		PermViewOwnBalance,
 		PermMakeTransaction,
 	},
+	"merchant": {
+		// Merchant şimdilik user ile aynı yetkilere sahip
+		PermViewOwnProfile,
+		PermUpdateOwnProfile,
+		PermDeleteOwnProfile,
+		PermViewOwnBalance,
+		PermMakeTransaction,
+	},
 	"mod": {
 		// Moderator inherits user permissions
 		PermViewOwnProfile,
@@ -78,6 +88,7 @@ var RolePermissions = map[string][]Permission{
 		PermViewAnyBalance,
 		PermViewAllTransactions,
 		PermSystemManagement,
+		PermReverseTransaction,
 		PermViewUserList,
 		PermViewUserDetails,
 		PermModerateUsers,
@@ -179,9 +190,14 @@ func RequirePermissionWithConfig(config *RBACConfig) func(http.Handler) http.Han
 	}
 }
 
+// HasPermission role'ün verilen izne sahip olup olmadığını döner (handler içi yetki ayrımları için)
+func HasPermission(role string, permission Permission) bool {
+	return hasPermission(role, permission)
+}
+
 // hasPermission checks if role has the required permission
 func hasPermission(role string, permission Permission) bool {
-	permissions, exists := RolePermissions[role]
+	permissions, exists := RolePermissions[models.NormalizeRole(role)]
 	if !exists {
 		return false
 	}
@@ -196,9 +212,9 @@ func hasPermission(role string, permission Permission) bool {
 
 // getUserRole extracts user role from JWT claims
 func getUserRole(claims *auth.Claims) string {
-	// JWT'den role'u al
-	if claims.Role != "" {
-		return claims.Role
+	// JWT'den role'u al (RolePermissions anahtarlarıyla eşleşmesi için normalize edilir)
+	if role := models.NormalizeRole(claims.Role); role != "" {
+		return role
 	}
 
 	// Fallback: Default role assignment (should not happen in production)
@@ -298,7 +314,14 @@ func UserManagementRBAC() func(http.Handler) http.Handler {
 					}
 				}
 
-			case strings.Contains(path, "/users") && method == "PUT":
+			case strings.Contains(path, "/users/profile") && (method == "PUT" || method == "PATCH"):
+				// Own profile settings (ör. bildirim tercihleri)
+				config = &RBACConfig{
+					RequiredPermission: PermUpdateOwnProfile,
+					AllowOwner:         false,
+				}
+
+			case strings.Contains(path, "/users") && (method == "PUT" || method == "PATCH"):
 				// Update user - allow owner or admin
 				config = &RBACConfig{
 					RequiredPermission: PermUpdateAnyUser,
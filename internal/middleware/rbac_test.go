@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/auth"
+)
+
+// TestRBAC_MixedCaseRoleResolvesPermissions, rolü farklı harf büyüklüğüyle taşıyan token'ın hem üretimde hem
+// doğrulamada normalize edilip doğru rolün izinlerine ulaştığını test eder.
+func TestRBAC_MixedCaseRoleResolvesPermissions(t *testing.T) {
+	// Arrange
+	require.NoError(t, auth.Configure([]byte(testJWTSecret)))
+
+	generated, err := auth.GenerateToken(1, "admin@example.com", "Admin")
+	require.NoError(t, err)
+
+	// Normalizasyondan önce üretilmiş gibi rolü olduğu gibi taşıyan token
+	legacyClaims := &auth.Claims{
+		UserID:    1,
+		Role:      " ADMIN",
+		TokenType: auth.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, legacyClaims).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+
+	var seenRole string
+	handler := ErrorHandlingMiddlewareWithDefaults()(NewAuthMiddleware(DefaultAuthConfig())(
+		RequirePermission(PermViewAllUsers)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := r.Context().Value(UserContextKey).(*auth.Claims)
+			seenRole = claims.Role
+			w.WriteHeader(http.StatusOK)
+		}))))
+
+	for name, token := range map[string]string{"generated": generated, "legacy": legacy} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+
+		// Act
+		handler.ServeHTTP(rec, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, rec.Code, name)
+		assert.Equal(t, "admin", seenRole, name)
+	}
+	assert.True(t, HasPermission("Admin", PermSystemManagement))
+	assert.False(t, HasPermission("Admin", Permission("unknown")))
+}
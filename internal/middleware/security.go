@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 )
@@ -16,6 +18,8 @@ type SecurityConfig struct {
 	HSTSMaxAge            int
 	HSTSIncludeSubdomains bool
 	HSTSPreload           bool
+	HSTSOnlyOverTLS       bool     // HSTS'yi sadece HTTPS isteklerinde gönder
+	TrustedProxies        []string // X-Forwarded-Proto'suna güvenilen proxy IP/CIDR listesi
 
 	// X-Frame-Options
 	FrameOptions string // DENY, SAMEORIGIN, ALLOW-FROM uri
@@ -40,6 +44,7 @@ func DefaultSecurityConfig() *SecurityConfig {
 		HSTSMaxAge:            31536000, // 1 yıl
 		HSTSIncludeSubdomains: true,
 		HSTSPreload:           false, // Preload listesi için manual submission gerekli
+		HSTSOnlyOverTLS:       true,
 		FrameOptions:          "DENY",
 		ContentTypeNosniff:    true,
 		XSSProtection:         "1; mode=block",
@@ -55,6 +60,7 @@ func ProductionSecurityConfig() *SecurityConfig {
 		HSTSMaxAge:            63072000, // 2 yıl
 		HSTSIncludeSubdomains: true,
 		HSTSPreload:           true,
+		HSTSOnlyOverTLS:       true,
 		FrameOptions:          "DENY",
 		ContentTypeNosniff:    true,
 		XSSProtection:         "1; mode=block",
@@ -73,6 +79,7 @@ func DevelopmentSecurityConfig() *SecurityConfig {
 		HSTSMaxAge:            0, // Development'ta HSTS kapalı (HTTP kullanımı için)
 		HSTSIncludeSubdomains: false,
 		HSTSPreload:           false,
+		HSTSOnlyOverTLS:       true,
 		FrameOptions:          "SAMEORIGIN", // Development tools için daha esnek
 		ContentTypeNosniff:    true,
 		XSSProtection:         "1; mode=block",
@@ -95,8 +102,8 @@ func SecurityHeadersMiddleware(config *SecurityConfig) func(http.Handler) http.H
 				w.Header().Set("Content-Security-Policy", config.ContentSecurityPolicy)
 			}
 
-			// HTTP Strict Transport Security (HSTS)
-			if config.HSTSMaxAge > 0 {
+			// HTTP Strict Transport Security (HSTS) - düz HTTP'de anlamsız
+			if config.HSTSMaxAge > 0 && (!config.HSTSOnlyOverTLS || isHTTPSRequest(r, config.TrustedProxies)) {
 				hstsValue := formatHSTSHeader(config.HSTSMaxAge, config.HSTSIncludeSubdomains, config.HSTSPreload)
 				w.Header().Set("Strict-Transport-Security", hstsValue)
 			}
@@ -137,6 +144,45 @@ func SecurityHeadersMiddleware(config *SecurityConfig) func(http.Handler) http.H
 	}
 }
 
+// isHTTPSRequest isteğin TLS üzerinden geldiğini kontrol eder
+// X-Forwarded-Proto sadece güvenilen proxy'den geliyorsa dikkate alınır
+func isHTTPSRequest(r *http.Request, trustedProxies []string) bool {
+	if r.TLS != nil {
+		return true
+	}
+
+	if !strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		return false
+	}
+
+	return isTrustedProxy(r.RemoteAddr, trustedProxies)
+}
+
+// isTrustedProxy remote adresin güvenilen proxy listesinde olup olmadığını kontrol eder
+func isTrustedProxy(remoteAddr string, trustedProxies []string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range trustedProxies {
+		if strings.Contains(proxy, "/") {
+			if _, cidr, err := net.ParseCIDR(proxy); err == nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // formatHSTSHeader HSTS header değerini formatlar
 func formatHSTSHeader(maxAge int, includeSubdomains, preload bool) string {
 	hsts := fmt.Sprintf("max-age=%d", maxAge)
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// ServerTimingConfig Server-Timing header ayarları
+type ServerTimingConfig struct {
+	Enabled bool   // Aşama sürelerini Server-Timing header'ı olarak ekle
+	AppEnv  string // Header yalnızca development ortamında eklenir (iç süreler production'da açığa çıkmasın)
+}
+
+// NewServerTimingMiddleware request'in validation, auth, DB ve handler sürelerini context'te toplayıp
+// response'a Server-Timing header'ı olarak ekler. Kapalıyken veya development dışında request'e dokunmaz.
+func NewServerTimingMiddleware(config *ServerTimingConfig) func(http.Handler) http.Handler {
+	if config == nil || !config.Enabled || config.AppEnv != "development" {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, timing := utils.WithServerTiming(r.Context())
+			wrapped := &serverTimingWriter{ResponseWriter: w, timing: timing, startedAt: time.Now()}
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+		})
+	}
+}
+
+// serverTimingWriter header'lar gönderilmeden hemen önce Server-Timing header'ını ekler
+type serverTimingWriter struct {
+	http.ResponseWriter
+	timing      *utils.ServerTiming
+	startedAt   time.Time
+	wroteHeader bool
+}
+
+// WriteHeader o ana kadar ölçülen aşama sürelerini header'a yazar
+func (w *serverTimingWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Server-Timing", serverTimingHeader(w.timing.Stages(), time.Since(w.startedAt)))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write header yazılmadıysa önce 200 ile yazar
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// serverTimingHeader aşamaları milisaniye cinsinden Server-Timing formatına çevirir.
+// handler süresi toplamdan middleware aşamaları (validation, auth) düşülerek hesaplanır; DB süresi handler'ın içindedir.
+func serverTimingHeader(stages []utils.TimingStage, total time.Duration) string {
+	handler := total
+	entries := make([]string, 0, len(stages)+2)
+	for _, stage := range stages {
+		if stage.Name == utils.TimingValidation || stage.Name == utils.TimingAuth {
+			handler -= stage.Duration
+		}
+		entries = append(entries, serverTimingEntry(stage.Name, stage.Duration))
+	}
+	if handler < 0 {
+		handler = 0
+	}
+
+	entries = append(entries, serverTimingEntry("handler", handler), serverTimingEntry("total", total))
+	return strings.Join(entries, ", ")
+}
+
+// serverTimingEntry tek bir "ad;dur=ms" girdisi üretir
+func serverTimingEntry(name string, duration time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.3f", name, float64(duration.Nanoseconds())/1e6)
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// serveWithServerTiming handler'ın auth ve DB aşaması kaydettiği bir request'i middleware'den geçirir
+func serveWithServerTiming(config *ServerTimingConfig) *httptest.ResponseRecorder {
+	handler := NewServerTimingMiddleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.RecordTiming(r.Context(), utils.TimingAuth, 2*time.Millisecond)
+		utils.RecordTiming(r.Context(), utils.TimingDB, time.Millisecond)
+		utils.RecordTiming(r.Context(), utils.TimingDB, 500*time.Microsecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/balances/current", nil))
+	return rec
+}
+
+// TestServerTiming_HeaderOnlyInDevelopment, açıkken development'ta aşama sürelerinin Server-Timing header'ında
+// döndüğünü, production'da ise flag açık olsa bile header'ın eklenmediğini test eder.
+func TestServerTiming_HeaderOnlyInDevelopment(t *testing.T) {
+	// Act
+	dev := serveWithServerTiming(&ServerTimingConfig{Enabled: true, AppEnv: "development"})
+	prod := serveWithServerTiming(&ServerTimingConfig{Enabled: true, AppEnv: "production"})
+	disabled := serveWithServerTiming(&ServerTimingConfig{Enabled: false, AppEnv: "development"})
+
+	// Assert
+	header := dev.Header().Get("Server-Timing")
+	assert.Contains(t, header, "auth;dur=2.000")
+	assert.Contains(t, header, "db;dur=1.500")
+	assert.Contains(t, header, "handler;dur=")
+	assert.Contains(t, header, "total;dur=")
+
+	assert.Empty(t, prod.Header().Get("Server-Timing"))
+	assert.Empty(t, disabled.Header().Get("Server-Timing"))
+}
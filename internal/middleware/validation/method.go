@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// probeMethods Allow header hesaplanırken denenen HTTP metodları
+var probeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// AllowedMethodsForPath router'da istek path'i için kayıtlı tüm metodları döner
+func AllowedMethodsForPath(router *mux.Router, r *http.Request) []string {
+	if router == nil {
+		return nil
+	}
+
+	var allowed []string
+	for _, method := range probeMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+
+		var match mux.RouteMatch
+		if router.Match(probe, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,45 @@
+package validation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAllowedMethodsForPath, path için yalnızca route'a kayıtlı metodların döndüğünü test eder.
+func TestAllowedMethodsForPath(t *testing.T) {
+	// Arrange
+	router := mux.NewRouter()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	router.HandleFunc("/api/v1/users/{id:[0-9]+}", noop).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/users/{id:[0-9]+}", noop).Methods(http.MethodPut)
+	router.HandleFunc("/api/v1/users/{id:[0-9]+}", noop).Methods(http.MethodDelete)
+	router.HandleFunc("/health", noop).Methods(http.MethodGet, http.MethodHead)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/42", nil)
+
+	// Act
+	allowed := AllowedMethodsForPath(router, req)
+
+	// Assert
+	assert.Equal(t, []string{http.MethodGet, http.MethodPut, http.MethodDelete}, allowed)
+}
+
+// TestAllowedMethodsForPath_UnknownPath, kayıtlı olmayan path için boş liste döndüğünü test eder.
+func TestAllowedMethodsForPath_UnknownPath(t *testing.T) {
+	// Arrange
+	router := mux.NewRouter()
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+	router.NotFoundHandler = http.NotFoundHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/yok", nil)
+
+	// Act
+	allowed := AllowedMethodsForPath(router, req)
+
+	// Assert
+	assert.Empty(t, allowed)
+}
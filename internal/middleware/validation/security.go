@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -47,10 +48,56 @@ var (
 	}
 )
 
+// ErrFormBodyTooLarge form body MaxBodySize'ı aştığında döner (body parse edilmeden reddedilir)
+var ErrFormBodyTooLarge = errors.New("form body çok büyük")
+
+// Güvenlik ihlali kategorileri
+const (
+	ThreatSQLInjection = "sql_injection"
+	ThreatXSS          = "xss"
+	ThreatParamTooLong = "param_too_long"
+)
+
+// İhlalin tespit edildiği yerler
+const (
+	LocationQuery  = "query"
+	LocationForm   = "form"
+	LocationHeader = "header"
+)
+
+// safeFieldName client'a döndürülebilecek parametre adı (ad da saldırgan kontrolünde olduğu için kısıtlı)
+var safeFieldName = regexp.MustCompile(`^[A-Za-z0-9_.\-\[\]]{1,64}$`)
+
+// SecurityViolation güvenlik kontrolünün neyi nerede yakaladığı (şüpheli değer tutulmaz)
+type SecurityViolation struct {
+	Category string // sql_injection | xss | param_too_long
+	Location string // query | form | header
+	Field    string // Parametre/header adı; güvenli karakterlerden oluşmuyorsa boş
+}
+
+// newSecurityViolation alan adını güvenli değilse atarak violation oluşturur
+func newSecurityViolation(category, location, field string) *SecurityViolation {
+	if !safeFieldName.MatchString(field) {
+		field = ""
+	}
+	return &SecurityViolation{Category: category, Location: location, Field: field}
+}
+
+// Error violation'ı okunabilir metne çevirir
+func (v *SecurityViolation) Error() string {
+	if v.Field == "" {
+		return fmt.Sprintf("%s in %s", v.Category, v.Location)
+	}
+	return fmt.Sprintf("%s in %s %q", v.Category, v.Location, v.Field)
+}
+
 // ValidateSecurity performs security validation (SQL injection, XSS)
 func ValidateSecurity(r *http.Request, config *Config) error {
 	if config.SQLInjection {
-		if err := detectSQLInjection(r, config.MaxParamSize); err != nil {
+		if err := detectSQLInjection(r, config); err != nil {
+			if errors.Is(err, ErrFormBodyTooLarge) {
+				return err
+			}
 			return fmt.Errorf("SQL injection detected: %w", err)
 		}
 	}
@@ -65,19 +112,21 @@ func ValidateSecurity(r *http.Request, config *Config) error {
 }
 
 // detectSQLInjection checks for malicious SQL patterns
-func detectSQLInjection(r *http.Request, maxParamSize int) error {
+func detectSQLInjection(r *http.Request, config *Config) error {
+	maxParamSize := config.MaxParamSize
+
 	// Check query parameters
-	for _, values := range r.URL.Query() {
+	for name, values := range r.URL.Query() {
 		for _, param := range values {
 			param = strings.TrimSpace(param)
 			if param == "" {
 				continue
 			}
 			if len(param) > maxParamSize {
-				return fmt.Errorf("query parameter too long")
+				return newSecurityViolation(ThreatParamTooLong, LocationQuery, name)
 			}
 			if detectMaliciousPatterns(param, sqlPatterns) {
-				return fmt.Errorf("malicious SQL pattern in query parameter")
+				return newSecurityViolation(ThreatSQLInjection, LocationQuery, name)
 			}
 		}
 	}
@@ -86,18 +135,22 @@ func detectSQLInjection(r *http.Request, maxParamSize int) error {
 	contentType := r.Header.Get("Content-Type")
 	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") ||
 		strings.HasPrefix(contentType, "multipart/form-data") {
-		if err := r.ParseForm(); err == nil {
-			for _, values := range r.Form {
+		err := parseFormBounded(r, config.MaxBodySize, config.MaxFormMemory)
+		if errors.Is(err, ErrFormBodyTooLarge) {
+			return err
+		}
+		if err == nil {
+			for name, values := range r.Form {
 				for _, value := range values {
 					value = strings.TrimSpace(value)
 					if value == "" {
 						continue
 					}
 					if len(value) > maxParamSize {
-						return fmt.Errorf("form parameter too long")
+						return newSecurityViolation(ThreatParamTooLong, LocationForm, name)
 					}
 					if detectMaliciousPatterns(value, sqlPatterns) {
-						return fmt.Errorf("malicious SQL pattern in form data")
+						return newSecurityViolation(ThreatSQLInjection, LocationForm, name)
 					}
 				}
 			}
@@ -107,20 +160,52 @@ func detectSQLInjection(r *http.Request, maxParamSize int) error {
 	return nil
 }
 
+// isFormBodyTooLarge hatanın form body boyut sınırından kaynaklanıp kaynaklanmadığını döner
+func isFormBodyTooLarge(err error) bool {
+	return errors.Is(err, ErrFormBodyTooLarge)
+}
+
+// parseFormBounded form body'sini toplam boyut sınırı içinde parse eder.
+// Content-Length sınırı aşıyorsa body hiç okunmaz; chunked body'ler MaxBytesReader ile kesilir.
+// Multipart formlarda bellekte tutulacak kısım maxMemory ile sınırlanır.
+func parseFormBounded(r *http.Request, maxBodySize, maxMemory int64) error {
+	if maxBodySize > 0 {
+		if r.ContentLength > maxBodySize {
+			return ErrFormBodyTooLarge
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+		}
+	}
+
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		err = r.ParseMultipartForm(maxMemory)
+	} else {
+		err = r.ParseForm()
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrFormBodyTooLarge
+	}
+	return err
+}
+
 // detectXSS checks for malicious XSS patterns
 func detectXSS(r *http.Request, maxParamSize int) error {
 	// Check query parameters
-	for _, values := range r.URL.Query() {
+	for name, values := range r.URL.Query() {
 		for _, param := range values {
 			param = strings.TrimSpace(param)
 			if param == "" {
 				continue
 			}
 			if len(param) > maxParamSize {
-				return fmt.Errorf("query parameter too long")
+				return newSecurityViolation(ThreatParamTooLong, LocationQuery, name)
 			}
 			if detectMaliciousPatterns(param, xssPatterns) {
-				return fmt.Errorf("malicious XSS pattern in query parameter")
+				return newSecurityViolation(ThreatXSS, LocationQuery, name)
 			}
 		}
 	}
@@ -136,7 +221,7 @@ func detectXSS(r *http.Request, maxParamSize int) error {
 			continue // Skip overly long headers
 		}
 		if detectMaliciousPatterns(headerValue, xssPatterns) {
-			return fmt.Errorf("malicious XSS pattern in header: %s", headerName)
+			return newSecurityViolation(ThreatXSS, LocationHeader, headerName)
 		}
 	}
 
@@ -0,0 +1,148 @@
+package validation
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
+)
+
+// countingReader okunan byte sayısını kaydeden reader
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+// TestValidateSecurity_OversizedFormRejectedBeforeParsing, Content-Length sınırı aşan form body'sinin okunmadan reddedildiğini test eder.
+func TestValidateSecurity_OversizedFormRejectedBeforeParsing(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	config.MaxBodySize = 1024
+
+	body := &countingReader{r: strings.NewReader("a=" + strings.Repeat("x", 4096))}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/credit", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.ContentLength = 4098
+
+	// Act
+	err := ValidateSecurity(req, config)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrFormBodyTooLarge)
+	assert.Zero(t, body.read)
+	assert.Nil(t, req.Form)
+}
+
+// TestValidateSecurity_ChunkedFormBodyIsBounded, Content-Length'siz (chunked) büyük form body'sinin sınırda kesildiğini test eder.
+func TestValidateSecurity_ChunkedFormBodyIsBounded(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	config.MaxBodySize = 1024
+
+	body := &countingReader{r: strings.NewReader("a=" + strings.Repeat("x", 64*1024))}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/credit", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.ContentLength = -1
+
+	// Act
+	err := ValidateSecurity(req, config)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrFormBodyTooLarge)
+	assert.LessOrEqual(t, body.read, 2*1024)
+}
+
+// TestValidateSecurity_SmallFormStillScanned, sınır içindeki form body'sinin taranmaya devam ettiğini test eder.
+func TestValidateSecurity_SmallFormStillScanned(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions/credit", strings.NewReader("q=1 union select password"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Act
+	err := ValidateSecurity(req, config)
+
+	// Assert
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrFormBodyTooLarge)
+	assert.Contains(t, err.Error(), "SQL injection detected")
+}
+
+// runSecurityCheck isteği middleware'den geçirir ve güvenlik kontrolünün fırlattığı hatayı döner (geçerse nil)
+func runSecurityCheck(t *testing.T, config *Config, req *http.Request) (securityErr *errors.SecurityError) {
+	t.Helper()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			var ok bool
+			securityErr, ok = recovered.(*errors.SecurityError)
+			require.True(t, ok, "beklenmeyen panic: %v", recovered)
+		}
+	}()
+
+	Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(httptest.NewRecorder(), req)
+	return nil
+}
+
+// TestMiddleware_SecurityErrorCategories, SQL injection ve XSS bloklarının farklı kategori ve hata koduyla döndüğünü,
+// alan adının eklendiğini ve şüpheli değerin mesaja yansımadığını test eder.
+func TestMiddleware_SecurityErrorCategories(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	sqlReq := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/history?status=1%20UNION%20SELECT%20password", nil)
+	xssReq := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/history?note=%3Cscript%3Ealert(1)%3C/script%3E", nil)
+
+	// Act
+	sqlErr := runSecurityCheck(t, config, sqlReq)
+	xssErr := runSecurityCheck(t, config, xssReq)
+
+	// Assert
+	require.NotNil(t, sqlErr)
+	assert.Equal(t, errors.SecurityCodeSQLInjection, sqlErr.ErrorCode())
+	assert.Equal(t, map[string]interface{}{"category": ThreatSQLInjection, "location": LocationQuery, "field": "status"}, sqlErr.ErrorDetails())
+	assert.NotContains(t, strings.ToLower(sqlErr.Error()), "union")
+
+	require.NotNil(t, xssErr)
+	assert.Equal(t, errors.SecurityCodeXSS, xssErr.ErrorCode())
+	assert.Equal(t, ThreatXSS, xssErr.Category)
+	assert.Equal(t, "note", xssErr.Field)
+	assert.NotContains(t, xssErr.Error(), "<script>")
+
+	assert.NotEqual(t, sqlErr.Message, xssErr.Message)
+}
+
+// TestMiddleware_SecurityErrorDetailsDisabled, detaylar kapalıyken genel mesaj ve kod döndüğünü, güvenli olmayan
+// alan adının ise detaylı modda da yansıtılmadığını test eder.
+func TestMiddleware_SecurityErrorDetailsDisabled(t *testing.T) {
+	// Arrange
+	generic := DefaultConfig()
+	generic.DetailedSecurityErrors = false
+	detailed := DefaultConfig()
+
+	// Act
+	genericErr := runSecurityCheck(t, generic, httptest.NewRequest(http.MethodGet, "/x?q=javascript:alert(1)", nil))
+	unsafeNameErr := runSecurityCheck(t, detailed, httptest.NewRequest(http.MethodGet, "/x?%3Cimg%3E=javascript:alert(1)", nil))
+
+	// Assert
+	require.NotNil(t, genericErr)
+	assert.Equal(t, errors.SecurityCodeThreatGeneric, genericErr.ErrorCode())
+	assert.Equal(t, "Güvenlik ihlali tespit edildi", genericErr.Error())
+	assert.Nil(t, genericErr.ErrorDetails())
+
+	require.NotNil(t, unsafeNameErr)
+	assert.Equal(t, errors.SecurityCodeXSS, unsafeNameErr.ErrorCode())
+	assert.Empty(t, unsafeNameErr.Field)
+	assert.NotContains(t, unsafeNameErr.Error(), "<img>")
+}
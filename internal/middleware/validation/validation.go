@@ -2,25 +2,33 @@
 package validation
 
 import (
+	stderrors "errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/onerilhan/go-payment-api/internal/middleware/errors"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 // Config validation middleware ayarları
 type Config struct {
 	MaxBodySize         int64             // Maximum request body size (bytes)
 	MaxParamSize        int               // Maximum parameter size for security checks
+	MaxFormMemory       int64             // Multipart form parse'ında bellekte tutulacak maksimum boyut (bytes)
 	RequiredHeaders     []string          // Required headers
-	AllowedMethods      []string          // Allowed HTTP methods
+	AllowedMethods      []string          // Allowed HTTP methods (route bazlı kısıt mux kaydından gelir)
 	ContentTypes        []string          // Allowed content types
 	JSONValidation      bool              // Enable JSON validation
 	SQLInjection        bool              // Enable SQL injection detection
 	XSSProtection       bool              // Enable XSS protection
 	PathValidation      map[string]string // Path parameter validation rules
 	RequireNonEmptyJSON bool              // Require non-empty JSON body for JSON requests
+
+	DetailedSecurityErrors bool // Güvenlik ihlalinde kategori ve alan adını client'a döndür (kapalıysa genel mesaj)
 }
 
 // DefaultConfig varsayılan validation ayarları
@@ -28,6 +36,7 @@ func DefaultConfig() *Config {
 	return &Config{
 		MaxBodySize:     1024 * 1024, // 1MB
 		MaxParamSize:    2048,        // 2KB per parameter
+		MaxFormMemory:   256 * 1024,  // 256KB
 		RequiredHeaders: []string{},
 		AllowedMethods: []string{
 			"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH", "HEAD",
@@ -41,14 +50,17 @@ func DefaultConfig() *Config {
 		XSSProtection:       true,
 		PathValidation:      make(map[string]string),
 		RequireNonEmptyJSON: false,
+
+		DetailedSecurityErrors: true,
 	}
 }
 
 // StrictConfig API endpoints için sıkı validation
 func StrictConfig() *Config {
 	config := DefaultConfig()
-	config.MaxBodySize = 512 * 1024 // 512KB
-	config.MaxParamSize = 1024      // 1KB
+	config.MaxBodySize = 512 * 1024   // 512KB
+	config.MaxParamSize = 1024        // 1KB
+	config.MaxFormMemory = 128 * 1024 // 128KB
 	config.RequiredHeaders = []string{"Content-Type", "User-Agent"}
 	config.RequireNonEmptyJSON = true
 	config.PathValidation = map[string]string{
@@ -77,8 +89,11 @@ func Middleware(config *Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			// 1. HTTP Method validation
+			startedAt := time.Now()
+
+			// 1. HTTP Method validation (global liste; route bazlı 405 mux tarafından üretilir)
 			if err := ValidateMethod(r, config.AllowedMethods); err != nil {
+				w.Header().Set("Allow", strings.Join(config.AllowedMethods, ", "))
 				panic(&errors.ValidationError{
 					Message:    err.Error(),
 					StatusCode: http.StatusMethodNotAllowed,
@@ -119,18 +134,22 @@ func Middleware(config *Config) func(http.Handler) http.Handler {
 
 			// 5. Security validation (SQL injection, XSS)
 			if err := ValidateSecurity(r, config); err != nil {
+				if isFormBodyTooLarge(err) {
+					panic(&errors.ValidationError{
+						Message:    fmt.Sprintf("request body çok büyük. Maksimum boyut: %d bytes", config.MaxBodySize),
+						StatusCode: http.StatusRequestEntityTooLarge,
+						Field:      "content",
+						Value:      "form_body_too_large",
+					})
+				}
+
 				log.Warn().
 					Str("client_ip", getClientIP(r)).
 					Str("path", r.URL.Path).
 					Err(err).
 					Msg("Security threat detected")
 
-				panic(&errors.ValidationError{
-					Message:    "Güvenlik ihlali tespit edildi",
-					StatusCode: http.StatusBadRequest,
-					Field:      "security",
-					Value:      "security_threat_detected",
-				})
+				panic(newSecurityError(err, config.DetailedSecurityErrors))
 			}
 
 			log.Debug().
@@ -139,12 +158,66 @@ func Middleware(config *Config) func(http.Handler) http.Handler {
 				Int64("content_length", r.ContentLength).
 				Msg("Request validation passed")
 
+			utils.RecordTiming(r.Context(), utils.TimingValidation, time.Since(startedAt))
+
 			// Sonraki middleware'a geç
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// securityErrorCodes ihlal kategorilerinin client'a dönen sabit hata kodları
+var securityErrorCodes = map[string]string{
+	ThreatSQLInjection: errors.SecurityCodeSQLInjection,
+	ThreatXSS:          errors.SecurityCodeXSS,
+	ThreatParamTooLong: errors.SecurityCodeParamTooLong,
+}
+
+// securityThreatLabels kategorilerin client mesajındaki karşılıkları
+var securityThreatLabels = map[string]string{
+	ThreatSQLInjection: "SQL injection",
+	ThreatXSS:          "XSS",
+	ThreatParamTooLong: "çok uzun parametre",
+}
+
+// securityLocationLabels ihlal yerlerinin client mesajındaki karşılıkları
+var securityLocationLabels = map[string]string{
+	LocationQuery:  "query parametresi",
+	LocationForm:   "form alanı",
+	LocationHeader: "header",
+}
+
+// newSecurityError güvenlik kontrolü hatasını client'a dönecek hataya çevirir.
+// detailed kapalıysa veya kategori bilinmiyorsa genel mesaj ve kod döner; şüpheli değer hiçbir durumda eklenmez.
+func newSecurityError(err error, detailed bool) *errors.SecurityError {
+	securityErr := &errors.SecurityError{
+		Message:    "Güvenlik ihlali tespit edildi",
+		StatusCode: http.StatusBadRequest,
+		Code:       errors.SecurityCodeThreatGeneric,
+	}
+
+	var violation *SecurityViolation
+	if !detailed || !stderrors.As(err, &violation) {
+		return securityErr
+	}
+	code, ok := securityErrorCodes[violation.Category]
+	if !ok {
+		return securityErr
+	}
+
+	securityErr.Code = code
+	securityErr.Category = violation.Category
+	securityErr.Location = violation.Location
+	securityErr.Field = violation.Field
+
+	where := securityLocationLabels[violation.Location]
+	if violation.Field != "" {
+		where = fmt.Sprintf("%s %q", where, violation.Field)
+	}
+	securityErr.Message = fmt.Sprintf("Güvenlik ihlali tespit edildi: %s (%s)", securityThreatLabels[violation.Category], where)
+	return securityErr
+}
+
 // MiddlewareWithDefaults varsayılan ayarlarla middleware döner
 func MiddlewareWithDefaults() func(http.Handler) http.Handler {
 	return Middleware(DefaultConfig())
@@ -13,6 +13,9 @@ import (
 type Runner struct {
 	db     *sql.DB          // Database bağlantısı
 	config *MigrationConfig // Migration ayarları
+
+	previousMaxOpenConns int  // Close'da geri yüklenecek pool limiti
+	poolLimitApplied     bool // MaxOpenConns DB handle'ına uygulandı mı?
 }
 
 // NewRunner yeni migration runner oluşturur
@@ -31,10 +34,36 @@ func NewRunner(db *sql.DB, config *MigrationConfig) *Runner {
 		}
 	}
 
-	return &Runner{
+	runner := &Runner{
 		db:     db,
 		config: config,
 	}
+	runner.applyPoolLimit()
+
+	return runner
+}
+
+// applyPoolLimit config.MaxOpenConns'u DB handle'ına uygular (paralel DDL'i engeller)
+// MaxOpenConns <= 0 ise pool ayarlarına dokunulmaz. Lease açıksa heartbeat için bir bağlantı ayrılır:
+// lease bağlantısı çalışma boyunca tutulduğundan migration'lar yine en fazla MaxOpenConns bağlantı kullanır.
+func (r *Runner) applyPoolLimit() {
+	if r.db == nil || r.config.MaxOpenConns <= 0 {
+		return
+	}
+
+	limit := r.config.MaxOpenConns
+	if r.config.LeaseTTL > 0 {
+		limit++
+	}
+
+	r.previousMaxOpenConns = r.db.Stats().MaxOpenConnections
+	r.db.SetMaxOpenConns(limit)
+	r.poolLimitApplied = true
+
+	log.Debug().
+		Int("max_open_conns", limit).
+		Int("previous_max_open_conns", r.previousMaxOpenConns).
+		Msg("Migration DB bağlantı limiti uygulandı")
 }
 
 // ensurePathExists klasör yoksa oluşturur
@@ -84,8 +113,12 @@ func (r *Runner) Initialize() error {
 	return nil
 }
 
-// Close runner'ı kapatır (DB bağlantısını kapatmaz)
+// Close runner'ı kapatır (DB bağlantısını kapatmaz, pool limitini eski haline getirir)
 func (r *Runner) Close() error {
+	if r.poolLimitApplied {
+		r.db.SetMaxOpenConns(r.previousMaxOpenConns)
+		r.poolLimitApplied = false
+	}
 	log.Debug().Msg("Migration runner kapatıldı")
 	return nil
 }
@@ -26,6 +26,13 @@ func (r *Runner) RunUp(targetVersion int64) ([]MigrationResult, error) {
 		return nil, fmt.Errorf("migration sistemi initialize edilemedi: %w", err)
 	}
 
+	// Süreçler arası eşzamanlı çalışma koruması
+	releaseLease, err := r.acquireLease()
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLease()
+
 	// Migration'ları status ile yükle
 	migrations, err := r.LoadMigrationsWithStatus()
 	if err != nil {
@@ -90,6 +97,13 @@ func (r *Runner) RunDown(targetVersion int64) ([]MigrationResult, error) {
 		log.Info().Int64("target_version", targetVersion).Msg("Migration DOWN başlatılıyor")
 	}
 
+	// Süreçler arası eşzamanlı çalışma koruması
+	releaseLease, err := r.acquireLease()
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLease()
+
 	// Migration'ları status ile yükle
 	migrations, err := r.LoadMigrationsWithStatus()
 	if err != nil {
@@ -0,0 +1,155 @@
+// internal/migration/runner_lease.go
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrMigrationLeaseHeld başka bir runner'ın lease'i hâlâ tazeyken dönen hata
+var ErrMigrationLeaseHeld = errors.New("başka bir migration çalışması devam ediyor")
+
+// leaseTableName lease satırının tutulduğu tablo (takip tablosunun yanında)
+func (r *Runner) leaseTableName() string {
+	return r.config.TableName + "_lease"
+}
+
+// leaseHolderID bu runner'ı lease'te tanımlayan değer (host:pid:başlangıç)
+func leaseHolderID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano())
+}
+
+// acquireLease migration çalışması için lease alır ve periyodik heartbeat başlatır; dönen fonksiyon lease'i bırakır.
+// Lease süreçler arası çalışır: aynı DB'ye karşı CLI ve uygulama aynı anda migrate etmeye kalkarsa ikincisi reddedilir.
+// Heartbeat LeaseTTL süresince gelmeyen lease (çökmüş runner) bayat sayılır ve devralınabilir.
+// Lease işlemleri pool'dan ayrılan tek bir bağlantıda yapılır (bkz. applyPoolLimit); uzun süren bir migration
+// migration bağlantısını tutarken de heartbeat yazılabilir.
+func (r *Runner) acquireLease() (func(), error) {
+	ttl := time.Duration(r.config.LeaseTTL) * time.Second
+	if ttl <= 0 {
+		return func() {}, nil
+	}
+
+	ctx := context.Background()
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration lease bağlantısı alınamadı: %w", err)
+	}
+
+	createSQL := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id SMALLINT PRIMARY KEY,   -- Tek satır (1)
+			holder VARCHAR(255),       -- Lease sahibi runner (boşsa serbest)
+			acquired_at TIMESTAMPTZ,   -- Lease'in alındığı zaman
+			heartbeat_at TIMESTAMPTZ   -- Son heartbeat
+		)
+	`, r.leaseTableName())
+	if _, err := conn.ExecContext(ctx, createSQL); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("migration lease tablosu oluşturulamadı: %w", err)
+	}
+
+	holder := leaseHolderID()
+	if err := r.claimLease(ctx, conn, holder, ttl); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.heartbeatLease(ctx, conn, holder, ttl, stop)
+	}()
+
+	return func() {
+		close(stop)
+		wg.Wait()
+
+		releaseSQL := fmt.Sprintf(`UPDATE %s SET holder = NULL, heartbeat_at = NULL WHERE id = 1 AND holder = $1`, r.leaseTableName())
+		if _, err := conn.ExecContext(ctx, releaseSQL, holder); err != nil {
+			log.Warn().Err(err).Str("holder", holder).Msg("Migration lease bırakılamadı, TTL sonunda düşecek")
+		}
+		conn.Close()
+	}, nil
+}
+
+// claimLease lease satırını kilitler; boşsa veya heartbeat'i TTL'den eskiyse holder'a verir.
+// Heartbeat yaşı SQL'de DB saatine göre hesaplanır: runner'lar arası saat farkı ve session TimeZone'u sonucu etkilemez
+// (eski kurulumlardaki TIMESTAMP kolonlar da NOW() ile aynı session saat diliminde karşılaştırılır).
+func (r *Runner) claimLease(ctx context.Context, conn *sql.Conn, holder string, ttl time.Duration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration lease transaction'ı başlatılamadı: %w", err)
+	}
+	defer tx.Rollback()
+
+	table := r.leaseTableName()
+	if _, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s (id) VALUES (1) ON CONFLICT (id) DO NOTHING`, table)); err != nil {
+		return fmt.Errorf("migration lease satırı oluşturulamadı: %w", err)
+	}
+
+	var currentHolder sql.NullString
+	var ageSeconds sql.NullFloat64
+	err = tx.QueryRow(fmt.Sprintf(`SELECT holder, EXTRACT(EPOCH FROM NOW() - heartbeat_at) FROM %s WHERE id = 1 FOR UPDATE`, table)).
+		Scan(&currentHolder, &ageSeconds)
+	if err != nil {
+		return fmt.Errorf("migration lease okunamadı: %w", err)
+	}
+
+	if currentHolder.Valid && ageSeconds.Valid {
+		age := time.Duration(ageSeconds.Float64 * float64(time.Second))
+		if age < ttl {
+			return fmt.Errorf("%w (sahibi %s, son heartbeat %s önce)", ErrMigrationLeaseHeld, currentHolder.String, age.Round(time.Second))
+		}
+		log.Warn().
+			Str("previous_holder", currentHolder.String).
+			Dur("heartbeat_age", age).
+			Msg("Bayat migration lease devralınıyor")
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`UPDATE %s SET holder = $1, acquired_at = NOW(), heartbeat_at = NOW() WHERE id = 1`, table), holder)
+	if err != nil {
+		return fmt.Errorf("migration lease alınamadı: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration lease commit edilemedi: %w", err)
+	}
+	return nil
+}
+
+// heartbeatLease stop kapanana kadar lease'in heartbeat'ini lease bağlantısı üzerinden TTL'in üçte birinde bir yeniler
+func (r *Runner) heartbeatLease(ctx context.Context, conn *sql.Conn, holder string, ttl time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	heartbeatSQL := fmt.Sprintf(`UPDATE %s SET heartbeat_at = NOW() WHERE id = 1 AND holder = $1`, r.leaseTableName())
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			result, err := conn.ExecContext(ctx, heartbeatSQL, holder)
+			if err != nil {
+				log.Warn().Err(err).Msg("Migration lease heartbeat yazılamadı")
+				continue
+			}
+			if rows, _ := result.RowsAffected(); rows == 0 {
+				log.Error().Str("holder", holder).Msg("Migration lease başka bir runner tarafından devralındı")
+			}
+		}
+	}
+}
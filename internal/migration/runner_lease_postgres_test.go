@@ -0,0 +1,77 @@
+//go:build postgres
+
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/config"
+)
+
+// newLeaseTestRunner session TimeZone'u verilen bölgeye ayarlı bağlantılarla, eski kurulumlardaki gibi TIMESTAMP
+// kolonlu bir lease tablosu üzerinde çalışan runner döner. Bu dosya sadece `go test -tags postgres ./...` ile derlenir.
+func newLeaseTestRunner(t *testing.T, timeZone string) (*Runner, *sql.DB) {
+	t.Helper()
+
+	database, err := sql.Open("postgres", config.LoadConfig().GetDSN()+"&timezone="+timeZone)
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+
+	var sessionZone string
+	require.NoError(t, database.QueryRow(`SHOW TIME ZONE`).Scan(&sessionZone), "PostgreSQL'e bağlanılamadı")
+	require.Equal(t, timeZone, sessionZone)
+
+	migrationConfig := DefaultConfig()
+	migrationConfig.AutoCreatePath = false
+	migrationConfig.MaxOpenConns = 0
+	migrationConfig.LeaseTTL = 60
+	migrationConfig.TableName = fmt.Sprintf("lease_tz_test_%d", time.Now().UnixNano())
+	runner := NewRunner(database, migrationConfig)
+
+	table := runner.leaseTableName()
+	_, err = database.Exec(fmt.Sprintf(`
+		CREATE TABLE %s (id SMALLINT PRIMARY KEY, holder VARCHAR(255), acquired_at TIMESTAMP, heartbeat_at TIMESTAMP)
+	`, table))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s`, table)) })
+
+	return runner, database
+}
+
+// TestRunner_AcquireLease_NonUTCSessionTimeZone, session TimeZone'u UTC değilken (pozitif ve TTL'den büyük negatif
+// offset) taze lease'in ikinci runner'ı engellediğini, heartbeat'i TTL'den eski lease'in ise devralındığını test eder.
+func TestRunner_AcquireLease_NonUTCSessionTimeZone(t *testing.T) {
+	for _, timeZone := range []string{"Europe/Istanbul", "America/New_York"} {
+		t.Run(timeZone, func(t *testing.T) {
+			// Arrange
+			runner, database := newLeaseTestRunner(t, timeZone)
+
+			// Act: taze lease
+			release, err := runner.acquireLease()
+			require.NoError(t, err)
+			_, heldErr := runner.acquireLease()
+			release()
+
+			// Act: 10 dakikadır heartbeat yazmamış (çökmüş) runner'ın lease'i
+			_, err = database.Exec(fmt.Sprintf(`
+				UPDATE %s SET holder = 'crashed:1:1', heartbeat_at = NOW() - INTERVAL '10 minutes' WHERE id = 1
+			`, runner.leaseTableName()))
+			require.NoError(t, err)
+			reclaimed, reclaimErr := runner.acquireLease()
+			if reclaimed != nil {
+				reclaimed()
+			}
+
+			// Assert
+			assert.ErrorIs(t, heldErr, ErrMigrationLeaseHeld)
+			assert.NoError(t, reclaimErr)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewRunner_AppliesMaxOpenConns, runner'ın config'teki MaxOpenConns'u (lease açıkken heartbeat için ayrılan bağlantıyla)
+// DB handle'ına uyguladığını ve Close'da geri aldığını test eder.
+func TestNewRunner_AppliesMaxOpenConns(t *testing.T) {
+	// Arrange
+	database, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	database.SetMaxOpenConns(10)
+	config := ProductionConfig()
+	config.AutoCreatePath = false
+
+	// Act
+	runner := NewRunner(database, config)
+	applied := database.Stats().MaxOpenConnections
+	runner.Close()
+
+	// Assert
+	assert.Equal(t, 2, applied) // 1 migration + 1 lease bağlantısı
+	assert.Equal(t, 10, database.Stats().MaxOpenConnections)
+}
+
+// TestNewRunner_ZeroMaxOpenConnsLeavesPoolUntouched, MaxOpenConns 0 iken pool ayarına dokunulmadığını test eder.
+func TestNewRunner_ZeroMaxOpenConnsLeavesPoolUntouched(t *testing.T) {
+	// Arrange
+	database, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	database.SetMaxOpenConns(25)
+	config := DefaultConfig()
+	config.AutoCreatePath = false
+	config.MaxOpenConns = 0
+
+	// Act
+	runner := NewRunner(database, config)
+	defer runner.Close()
+
+	// Assert
+	assert.Equal(t, 25, database.Stats().MaxOpenConnections)
+}
+
+// leaseTestRunner lease testleri için pool'a dokunmayan runner oluşturur
+func leaseTestRunner(t *testing.T) (*Runner, sqlmock.Sqlmock) {
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+
+	config := DefaultConfig()
+	config.AutoCreatePath = false
+	config.MaxOpenConns = 0
+	config.LeaseTTL = 60
+
+	return NewRunner(database, config), dbMock
+}
+
+// expectLeaseRow lease tablosunun oluşturulup satırın kilitlenerek okunmasını mock'lar (holder boşsa lease serbest)
+func expectLeaseRow(dbMock sqlmock.Sqlmock, holder string, heartbeatAge time.Duration) {
+	dbMock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations_lease").WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("INSERT INTO schema_migrations_lease").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"holder", "age"})
+	if holder == "" {
+		rows.AddRow(nil, nil)
+	} else {
+		rows.AddRow(holder, heartbeatAge.Seconds())
+	}
+	dbMock.ExpectQuery("SELECT holder, EXTRACT\\(EPOCH FROM NOW\\(\\) - heartbeat_at\\) FROM schema_migrations_lease WHERE id = 1 FOR UPDATE").
+		WillReturnRows(rows)
+}
+
+// TestRunner_AcquireLease_StaleLeaseReclaimed, heartbeat'i TTL'den eski lease'in (çökmüş runner) devralındığını
+// ve iş bitince bırakıldığını test eder.
+func TestRunner_AcquireLease_StaleLeaseReclaimed(t *testing.T) {
+	// Arrange
+	runner, dbMock := leaseTestRunner(t)
+	expectLeaseRow(dbMock, "cli-host:4242:1", 2*time.Minute)
+	dbMock.ExpectExec("UPDATE schema_migrations_lease SET holder = \\$1").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+	dbMock.ExpectExec("UPDATE schema_migrations_lease SET holder = NULL").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	release, err := runner.acquireLease()
+	if release != nil {
+		release()
+	}
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestRunner_AcquireLease_FreshLeaseBlocks, heartbeat'i taze lease varken ikinci runner'ın reddedildiğini test eder.
+func TestRunner_AcquireLease_FreshLeaseBlocks(t *testing.T) {
+	// Arrange
+	runner, dbMock := leaseTestRunner(t)
+	expectLeaseRow(dbMock, "app-host:7:1", 10*time.Second)
+	dbMock.ExpectRollback()
+
+	// Act
+	release, err := runner.acquireLease()
+
+	// Assert
+	assert.Nil(t, release)
+	assert.ErrorIs(t, err, ErrMigrationLeaseHeld)
+	assert.Contains(t, err.Error(), "app-host:7:1")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestRunner_AcquireLease_HeartbeatDuringLongStatement, pool tek migration bağlantısına sınırlıyken uzun süren bir
+// statement o bağlantıyı tutarken heartbeat'in lease bağlantısı üzerinden yazılmaya devam ettiğini test eder.
+func TestRunner_AcquireLease_HeartbeatDuringLongStatement(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+	dbMock.MatchExpectationsInOrder(false)
+
+	config := CLIConfig()
+	config.AutoCreatePath = false
+	config.LeaseTTL = 1 // heartbeat ~333ms'de bir
+	runner := NewRunner(database, config)
+	defer runner.Close()
+
+	expectLeaseRow(dbMock, "", 0)
+	dbMock.ExpectExec("UPDATE schema_migrations_lease SET holder = \\$1").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+	dbMock.ExpectExec("ALTER TABLE transactions").WillDelayFor(1200 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 0))
+	// Statement boyunca (~333, ~666, ~1000ms) üç heartbeat beklenir; pool'daki tek bağlantı kullanılsaydı
+	// heartbeat statement bitene kadar bekler, release'e kadar en fazla iki tanesi yazılabilirdi
+	for i := 0; i < 3; i++ {
+		dbMock.ExpectExec("UPDATE schema_migrations_lease SET heartbeat_at = NOW\\(\\)").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	dbMock.ExpectExec("UPDATE schema_migrations_lease SET holder = NULL").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	release, err := runner.acquireLease()
+	assert.NoError(t, err)
+	_, execErr := database.Exec("ALTER TABLE transactions ADD COLUMN note TEXT")
+	release()
+
+	// Assert: statement sürerken heartbeat yazılmaya devam etti
+	assert.NoError(t, execErr)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// StatusFetcher migration status'unu okuyan fonksiyon
+type StatusFetcher func() (*MigrationStatus, error)
+
+// RunnerStatusFetcher her çağrıda geçici bir Runner ile status okuyan fetcher döner
+func RunnerStatusFetcher(db *sql.DB, config *MigrationConfig) StatusFetcher {
+	return func() (*MigrationStatus, error) {
+		runner := NewRunner(db, config)
+		defer runner.Close()
+
+		return runner.GetStatus()
+	}
+}
+
+// StatusCache migration status'unu TTL boyunca cache'ler.
+// Sık health check'lerde her istekte migration tablosunun sorgulanmasını engeller.
+type StatusCache struct {
+	fetch StatusFetcher
+	ttl   time.Duration
+	now   func() time.Time
+
+	mutex     sync.Mutex
+	status    *MigrationStatus
+	err       error
+	fetchedAt time.Time
+	hasValue  bool
+}
+
+// NewStatusCache yeni status cache oluşturur (ttl <= 0 ise cache kullanılmaz)
+func NewStatusCache(ttl time.Duration, fetch StatusFetcher) *StatusCache {
+	return &StatusCache{
+		fetch: fetch,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// Get TTL dolmadıysa cache'teki status'u, dolduysa yeniden okunan status'u döner.
+// Hatalar da TTL boyunca cache'lenir; DB sorunluyken her health check'te tekrar denenmez.
+func (c *StatusCache) Get() (*MigrationStatus, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ttl > 0 && c.hasValue && c.now().Sub(c.fetchedAt) < c.ttl {
+		return c.status, c.err
+	}
+
+	c.status, c.err = c.fetch()
+	c.fetchedAt = c.now()
+	c.hasValue = true
+
+	return c.status, c.err
+}
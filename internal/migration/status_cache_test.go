@@ -0,0 +1,72 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingFetcher çağrı sayısını tutan sahte status fetcher döner
+func countingFetcher(calls *int, err error) StatusFetcher {
+	return func() (*MigrationStatus, error) {
+		*calls++
+		if err != nil {
+			return nil, err
+		}
+		return &MigrationStatus{CurrentVersion: int64(*calls)}, nil
+	}
+}
+
+// TestStatusCache_ReusesWithinTTL, TTL içindeki çağrılarda status sorgusunun tekrar çalışmadığını test eder.
+func TestStatusCache_ReusesWithinTTL(t *testing.T) {
+	// Arrange
+	calls := 0
+	now := time.Date(2025, 8, 8, 12, 0, 0, 0, time.UTC)
+	cache := NewStatusCache(10*time.Second, countingFetcher(&calls, nil))
+	cache.now = func() time.Time { return now }
+
+	// Act
+	first, _ := cache.Get()
+	now = now.Add(5 * time.Second)
+	second, _ := cache.Get()
+	now = now.Add(9 * time.Second)
+	third, _ := cache.Get()
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.Same(t, first, second)
+	assert.Equal(t, int64(2), third.CurrentVersion)
+}
+
+// TestStatusCache_CachesErrors, hata durumunda da TTL boyunca tekrar sorgu yapılmadığını test eder.
+func TestStatusCache_CachesErrors(t *testing.T) {
+	// Arrange
+	calls := 0
+	fetchErr := errors.New("migration tablosu okunamadı")
+	cache := NewStatusCache(10*time.Second, countingFetcher(&calls, fetchErr))
+
+	// Act
+	_, err1 := cache.Get()
+	_, err2 := cache.Get()
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.ErrorIs(t, err1, fetchErr)
+	assert.ErrorIs(t, err2, fetchErr)
+}
+
+// TestStatusCache_ZeroTTLDisablesCache, TTL 0 iken her çağrıda status'un yeniden okunduğunu test eder.
+func TestStatusCache_ZeroTTLDisablesCache(t *testing.T) {
+	// Arrange
+	calls := 0
+	cache := NewStatusCache(0, countingFetcher(&calls, nil))
+
+	// Act
+	cache.Get()
+	cache.Get()
+
+	// Assert
+	assert.Equal(t, 2, calls)
+}
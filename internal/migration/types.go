@@ -106,9 +106,10 @@ type MigrationConfig struct {
 
 	// Performans ayarları
 	LockTimeout        int `json:"lockTimeout"`        // Kilit timeout (saniye)
-	MaxOpenConns       int `json:"maxOpenConns"`       // Maksimum DB bağlantısı
+	MaxOpenConns       int `json:"maxOpenConns"`       // Migration'ların maksimum DB bağlantısı (runner süresince uygulanır, lease bağlantısı hariç, 0 = dokunma)
 	TransactionTimeout int `json:"transactionTimeout"` // Transaction timeout (saniye)
 	BatchSize          int `json:"batchSize"`          // Toplu işlem boyutu
+	LeaseTTL           int `json:"leaseTTL"`           // Çalışma lease'i heartbeat gelmezse kaç saniyede düşer (0 = lease kapalı)
 
 	// Backup ayarları
 	BackupStrategy BackupStrategy `json:"backupStrategy"` // Backup stratejisi
@@ -156,6 +157,7 @@ func DefaultConfig() *MigrationConfig {
 		MaxOpenConns:       1,   // Güvenli başlangıç
 		TransactionTimeout: 900, // 15 dakika
 		BatchSize:          100, // Toplu işlem boyutu
+		LeaseTTL:           300, // 5 dakika
 
 		// Backup ayarları
 		BackupStrategy: BackupNone,
@@ -177,6 +179,7 @@ func CLIConfig() *MigrationConfig {
 	c.IsCLI = true
 	c.MaxOpenConns = 1           // CLI için tek bağlantı güvenli
 	c.LockTimeout = 1800         // CLI: 30 dakika (manuel işlem)
+	c.LeaseTTL = 1800            // CLI: 30 dakika heartbeat gelmezse lease düşer
 	c.BackupStrategy = BackupSQL // CLI: SQL backup default
 	c.Verbose = true             // CLI: detaylı çıktı
 	c.Debug = false              // CLI: debug opsiyonel
@@ -206,6 +209,7 @@ func ProductionConfig() *MigrationConfig {
 	c.IsCLI = false
 	c.MaxOpenConns = 1           // Production: tek bağlantı
 	c.LockTimeout = 1800         // Production: 30 dakika
+	c.LeaseTTL = 1800            // Production: 30 dakika heartbeat gelmezse lease düşer
 	c.TransactionTimeout = 1800  // Production: uzun timeout
 	c.BackupStrategy = BackupSQL // Production: mutlaka backup
 	c.ValidateChecksums = true   // Production: checksum zorunlu
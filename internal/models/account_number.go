@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hesap numarası uzunluk sınırları (IBAN standardı)
+const (
+	MinAccountNumberLength = 15
+	MaxAccountNumberLength = 34
+)
+
+// NormalizeAccountNumber boşlukları kaldırır ve harfleri büyütür ("tr12 0009 ..." -> "TR120009...")
+func NormalizeAccountNumber(accountNumber string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(accountNumber), ""))
+}
+
+// AccountNumberCheckDigits ülke kodu ve BBAN için 2 haneli mod-97 kontrol basamaklarını hesaplar
+func AccountNumberCheckDigits(countryCode, bban string) (string, error) {
+	remainder, err := mod97(bban + countryCode + "00")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%02d", 98-remainder), nil
+}
+
+// ValidateAccountNumber hesap numarasının formatını ve mod-97 checksum'ını doğrular
+func ValidateAccountNumber(accountNumber string) error {
+	normalized := NormalizeAccountNumber(accountNumber)
+
+	if len(normalized) < MinAccountNumberLength || len(normalized) > MaxAccountNumberLength {
+		return fmt.Errorf("hesap numarası %d-%d karakter olmalıdır", MinAccountNumberLength, MaxAccountNumberLength)
+	}
+
+	if !isUpperLetter(normalized[0]) || !isUpperLetter(normalized[1]) {
+		return fmt.Errorf("hesap numarası 2 harfli ülke kodu ile başlamalıdır")
+	}
+	if !isDigit(normalized[2]) || !isDigit(normalized[3]) {
+		return fmt.Errorf("hesap numarasının kontrol basamakları geçersiz")
+	}
+
+	// İlk 4 karakter sona taşınır, sonuç mod 97 = 1 olmalı
+	remainder, err := mod97(normalized[4:] + normalized[:4])
+	if err != nil {
+		return err
+	}
+	if remainder != 1 {
+		return fmt.Errorf("hesap numarası geçersiz (checksum hatası)")
+	}
+
+	return nil
+}
+
+// mod97 harfleri sayıya çevirerek (A=10 ... Z=35) büyük sayının 97'ye göre kalanını hesaplar
+func mod97(value string) (int, error) {
+	remainder := 0
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case isDigit(c):
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case isUpperLetter(c):
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return 0, fmt.Errorf("hesap numarasında geçersiz karakter: %q", c)
+		}
+	}
+	return remainder, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isUpperLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxAmountPrecision desteklenen en fazla ondalık basamak (int64 minor unit taşmasın diye sınırlı)
+const MaxAmountPrecision = 6
+
+// amountPrecision toplamların yuvarlandığı ondalık basamak (config ile değiştirilebilir)
+var amountPrecision = 2
+
+// SetAmountPrecision istatistik toplamlarının ondalık basamak sayısını ayarlar
+func SetAmountPrecision(precision int) error {
+	if precision < 0 || precision > MaxAmountPrecision {
+		return fmt.Errorf("geçersiz tutar hassasiyeti: %d (0-%d arası olmalı)", precision, MaxAmountPrecision)
+	}
+	amountPrecision = precision
+	return nil
+}
+
+// AmountPrecision istatistik toplamlarının ondalık basamak sayısını döner
+func AmountPrecision() int {
+	return amountPrecision
+}
+
+// ParseMinorUnits DB'den gelen decimal metni ("1234.57") float'a çevirmeden minor unit'e (kuruş) çevirir.
+// Fazla basamaklar yarıdan yukarı yuvarlanır.
+func ParseMinorUnits(value string, precision int) (int64, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" {
+		whole = "0"
+	}
+
+	roundUp := false
+	if len(fraction) > precision {
+		roundUp = fraction[precision] >= '5'
+		fraction = fraction[:precision]
+	}
+	fraction += strings.Repeat("0", precision-len(fraction))
+
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("geçersiz tutar: %q", value)
+	}
+	if roundUp {
+		units++
+	}
+	if negative {
+		units = -units
+	}
+	return units, nil
+}
+
+// MinorUnitsToAmount minor unit'i tek bölme ile tutara çevirir (toplama sırasında float hatası birikmez)
+func MinorUnitsToAmount(units int64, precision int) float64 {
+	return float64(units) / math.Pow10(precision)
+}
@@ -5,6 +5,15 @@ import (
 	"time"
 )
 
+// Audit log entity ve action değerleri
+const (
+	AuditEntityTransaction = "transaction"
+
+	AuditActionReviewApprove = "review_approve"
+	AuditActionReviewReject  = "review_reject"
+	AuditActionBulkStatus    = "bulk_status_update"
+)
+
 // AuditLog audit log modelini temsil eder
 type AuditLog struct {
 	ID         int             `json:"id" db:"id"`
@@ -1,6 +1,19 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBalanceWouldBeNegative atomik bakiye değişikliği bakiyeyi negatife düşürecekse döner
+var ErrBalanceWouldBeNegative = errors.New("bakiye negatife düşemez")
+
+// ErrAdjustmentReferenceConflict düzeltme referansı başka kullanıcı veya tutarla daha önce kullanılmışsa döner
+var ErrAdjustmentReferenceConflict = errors.New("düzeltme referansı farklı bir bakiye düzeltmesi için kullanılmış")
+
+// MaxAdjustmentReferenceLength bakiye düzeltme referansının maksimum uzunluğu (balance_history.reference)
+const MaxAdjustmentReferenceLength = 100
 
 // Balance kullanıcı bakiye modelini temsil eder
 type Balance struct {
@@ -9,6 +22,54 @@ type Balance struct {
 	LastUpdatedAt time.Time `json:"last_updated_at" db:"last_updated_at"`
 }
 
+// MinimumBalance kullanıcının transfer ve çekimlerle altına inilemeyen, kendi belirlediği bakiye tabanı
+type MinimumBalance struct {
+	UserID         int     `json:"user_id"`
+	MinimumBalance float64 `json:"minimum_balance"` // 0 = taban yok
+}
+
+// UpdateMinimumBalanceRequest minimum bakiye güncelleme isteği (taban her zaman düşürülebilir)
+type UpdateMinimumBalanceRequest struct {
+	MinimumBalance *float64 `json:"minimum_balance"`
+}
+
+// Validate UpdateMinimumBalanceRequest'i doğrular
+func (r *UpdateMinimumBalanceRequest) Validate() error {
+	if r.MinimumBalance == nil {
+		return fmt.Errorf("minimum_balance gereklidir")
+	}
+	if *r.MinimumBalance < 0 {
+		return fmt.Errorf("minimum bakiye negatif olamaz")
+	}
+	return nil
+}
+
+// AvailableBalance kullanılabilir bakiye ve hesaplamasının kırılımı
+type AvailableBalance struct {
+	UserID          int     `json:"user_id"`
+	Settled         float64 `json:"settled"`          // balances tablosundaki kesinleşmiş bakiye
+	PendingOutgoing float64 `json:"pending_outgoing"` // Kullanıcının pending durumdaki giden işlemleri
+	Held            float64 `json:"held"`             // Aktif bloke (hold) tutarları
+	Available       float64 `json:"available"`        // Settled - PendingOutgoing - Held (negatif olmaz)
+}
+
+// ConvertedBalance bakiyenin başka bir para birimindeki gösterge karşılığı
+type ConvertedBalance struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+	Rate     float64 `json:"rate"` // 1 birim native para biriminin bu para birimindeki değeri
+}
+
+// BalanceDisplay native bakiye ve istenen para birimlerindeki gösterge karşılıkları
+type BalanceDisplay struct {
+	UserID        int                `json:"user_id"`
+	Currency      string             `json:"currency"` // Bakiyenin tutulduğu para birimi
+	Amount        float64            `json:"amount"`
+	Converted     []ConvertedBalance `json:"converted"`
+	Indicative    bool               `json:"indicative"` // Dönüşümler bilgi amaçlıdır, işlemler native para biriminde yapılır
+	LastUpdatedAt time.Time          `json:"last_updated_at"`
+}
+
 // BalanceHistory kullanıcının bakiye geçmişini tutar
 type BalanceHistory struct {
 	ID             int       `json:"id" db:"id"`
@@ -16,9 +77,64 @@ type BalanceHistory struct {
 	PreviousAmount float64   `json:"previous_amount" db:"previous_amount"`
 	NewAmount      float64   `json:"new_amount" db:"new_amount"`
 	ChangeAmount   float64   `json:"change_amount" db:"change_amount"`   // +/- değişim miktarı
-	Reason         string    `json:"reason" db:"reason"`                 // "credit", "debit", "transfer_in", "transfer_out"
+	Reason         string    `json:"reason" db:"reason"`                 // BalanceReason* değerlerinden biri
 	TransactionID  *int      `json:"transaction_id" db:"transaction_id"` // İlgili transaction ID (opsiyonel)
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+
+	Reference *string `json:"reference,omitempty" db:"reference"` // Doğrudan düzeltmenin idempotency referansı (opsiyonel)
+}
+
+// Bakiye değişikliği reason değerleri (balance_history.reason)
+const (
+	BalanceReasonCredit       = "credit"
+	BalanceReasonDebit        = "debit"
+	BalanceReasonTransferIn   = "transfer_in"
+	BalanceReasonTransferOut  = "transfer_out"
+	BalanceReasonReversalIn   = "reversal_in"
+	BalanceReasonReversalOut  = "reversal_out"
+	BalanceReasonRefundIn     = "refund_in"
+	BalanceReasonRefundOut    = "refund_out"
+	BalanceReasonCompensation = "compensation" // Kapatılan işlemin bakiye hareketinin geri alınması
+	BalanceReasonAdjustment   = "adjustment"   // Transaction'a bağlı olmayan doğrudan bakiye düzeltmesi
+)
+
+// BalanceReasonArchiveBaseline arşivlenen geçmişin toplamını tutan baseline kaydının reason değeri
+const BalanceReasonArchiveBaseline = "archive_baseline"
+
+// BalanceReasonOpeningBalance geçmiş kaydı tutulmadan önce oluşmuş bakiyenin açılış kaydının reason değeri (migration 000027)
+const BalanceReasonOpeningBalance = "opening_balance"
+
+// BalanceSnapshotReasonPeriodic periyodik snapshot job'ının yazdığı snapshot'ların reason değeri
+const BalanceSnapshotReasonPeriodic = "periodic"
+
+// BalanceArchiveResult balance_history arşivleme sonucu
+type BalanceArchiveResult struct {
+	ArchivedRows int       `json:"archived_rows"` // Arşiv tablosuna taşınan satır sayısı
+	Baselines    int       `json:"baselines"`     // Oluşturulan kullanıcı baseline kaydı sayısı
+	Before       time.Time `json:"before"`        // Bu andan eski satırlar arşivlendi
+}
+
+// LedgerBalance kullanıcının kayıtlı bakiyesi ile balance_history toplamından hesaplanan (ledger) bakiyesi
+type LedgerBalance struct {
+	UserID       int     `json:"user_id"`
+	Amount       float64 `json:"amount"`
+	LedgerAmount float64 `json:"ledger_amount"`
+}
+
+// BalanceDrift kayıtlı bakiyesi ledger'dan sapan kullanıcı
+type BalanceDrift struct {
+	UserID       int     `json:"user_id"`
+	Amount       float64 `json:"amount"`
+	LedgerAmount float64 `json:"ledger_amount"`
+	Difference   float64 `json:"difference"` // amount - ledger_amount
+}
+
+// BalanceReconciliationReport tüm bakiyelerin ledger ile karşılaştırılma sonucu
+type BalanceReconciliationReport struct {
+	Scanned    int            `json:"scanned"`
+	Drifts     []BalanceDrift `json:"drifts"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
 }
 
 // BalanceAtTime belirli bir tarihte bakiye bilgisi
@@ -27,4 +143,6 @@ type BalanceAtTime struct {
 	Amount  float64 `json:"amount"`
 	AtTime  string  `json:"at_time"`
 	Message string  `json:"message"`
+
+	SnapshotAt *time.Time `json:"snapshot_at,omitempty"` // Hesabın başladığı snapshot (yoksa tüm geçmiş toplandı)
 }
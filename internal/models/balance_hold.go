@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Balance hold status constants
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusVoided   = "voided"
+)
+
+// BalanceHold checkout'ta bloke edilen (authorize) ve sonradan tahsil (capture) ya da serbest bırakılan (void) tutar
+type BalanceHold struct {
+	ID             int        `json:"id" db:"id"`
+	UserID         int        `json:"user_id" db:"user_id"`       // Bakiyesi bloke edilen (ödeyen) kullanıcı
+	ToUserID       int        `json:"to_user_id" db:"to_user_id"` // Capture'da transferin alıcısı (satıcı)
+	Amount         float64    `json:"amount" db:"amount"`
+	CapturedAmount float64    `json:"captured_amount" db:"captured_amount"`
+	Status         string     `json:"status" db:"status"`
+	Description    string     `json:"description" db:"description"`
+	TransactionID  *int       `json:"transaction_id,omitempty" db:"transaction_id"` // Capture ile oluşan transfer
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// IsActive hold hâlâ bakiyeyi bloke ediyor mu
+func (h *BalanceHold) IsActive() bool {
+	return h.Status == HoldStatusActive
+}
+
+// ReleasedAmount capture/void sonrası serbest bırakılan tutarı döner (aktif hold için 0)
+func (h *BalanceHold) ReleasedAmount() float64 {
+	if h.IsActive() {
+		return 0
+	}
+	return h.Amount - h.CapturedAmount
+}
+
+// AuthorizeHoldRequest checkout'ta tutarı bloke etme isteği
+type AuthorizeHoldRequest struct {
+	ToUserID    int     `json:"to_user_id"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+// Validate AuthorizeHoldRequest'i doğrular
+func (req *AuthorizeHoldRequest) Validate() error {
+	if req.ToUserID <= 0 {
+		return fmt.Errorf("geçersiz kullanıcı ID")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
+	}
+	return nil
+}
+
+// CaptureHoldRequest hold'un tamamını veya bir kısmını transfere çevirme isteği (amount boşsa tamamı)
+type CaptureHoldRequest struct {
+	HoldID int      `json:"hold_id"`
+	Amount *float64 `json:"amount,omitempty"`
+}
+
+// Validate CaptureHoldRequest'i doğrular (hold tutarını aşma kontrolü hold okunduktan sonra yapılır)
+func (req *CaptureHoldRequest) Validate() error {
+	if req.HoldID <= 0 {
+		return fmt.Errorf("geçersiz hold ID")
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
+	}
+	return nil
+}
+
+// VoidHoldRequest aktif hold'u serbest bırakma isteği
+type VoidHoldRequest struct {
+	HoldID int `json:"hold_id"`
+}
+
+// Validate VoidHoldRequest'i doğrular
+func (req *VoidHoldRequest) Validate() error {
+	if req.HoldID <= 0 {
+		return fmt.Errorf("geçersiz hold ID")
+	}
+	return nil
+}
+
+// CaptureHoldResponse capture sonucu: güncellenen hold ve oluşan transfer
+type CaptureHoldResponse struct {
+	Hold        *BalanceHold `json:"hold"`
+	Transaction *Transaction `json:"transaction"`
+	Released    float64      `json:"released"` // Kısmi capture'da serbest bırakılan kalan tutar
+}
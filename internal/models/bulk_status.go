@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBulkStatusMaxIDs toplu status güncellemesinde tek istekte kabul edilen varsayılan en fazla transaction sayısı
+const DefaultBulkStatusMaxIDs = 100
+
+// BulkStatusUpdateRequest admin'in takılı kalmış transaction'ları toplu kapatma isteği
+type BulkStatusUpdateRequest struct {
+	TransactionIDs []int  `json:"transaction_ids"`
+	Status         string `json:"status"` // failed | cancelled
+	Reason         string `json:"reason"`
+}
+
+// Validate isteği doğrular; tekrar eden ID'ler tek kez işlenmek üzere ayıklanır.
+// Tamamlama (completed) bakiye hareketi gerektirdiği için toplu yapılamaz.
+func (req *BulkStatusUpdateRequest) Validate(maxIDs int) error {
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if req.Status != StatusFailed && req.Status != StatusCancelled {
+		return fmt.Errorf("geçersiz hedef status: %q. Geçerli değerler: %s, %s", req.Status, StatusFailed, StatusCancelled)
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return fmt.Errorf("sebep zorunludur")
+	}
+	if len(req.Reason) > 255 {
+		return fmt.Errorf("sebep en fazla 255 karakter olabilir")
+	}
+
+	if len(req.TransactionIDs) == 0 {
+		return fmt.Errorf("en az bir transaction ID gereklidir")
+	}
+	if maxIDs > 0 && len(req.TransactionIDs) > maxIDs {
+		return fmt.Errorf("tek istekte en fazla %d transaction güncellenebilir", maxIDs)
+	}
+
+	seen := make(map[int]bool, len(req.TransactionIDs))
+	ids := req.TransactionIDs[:0]
+	for _, id := range req.TransactionIDs {
+		if id <= 0 {
+			return fmt.Errorf("geçersiz transaction ID: %d", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	req.TransactionIDs = ids
+
+	return nil
+}
+
+// BulkStatusItemResult tek transaction'ın toplu güncelleme sonucu
+type BulkStatusItemResult struct {
+	TransactionID  int    `json:"transaction_id"`
+	Success        bool   `json:"success"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	Status         string `json:"status,omitempty"`
+	Compensated    bool   `json:"compensated,omitempty"` // İşleme bağlı bakiye hareketleri geri alındı
+	Error          string `json:"error,omitempty"`
+}
+
+// BulkStatusUpdateResult toplu status güncelleme yanıtı
+type BulkStatusUpdateResult struct {
+	Status    string                 `json:"status"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Results   []BulkStatusItemResult `json:"results"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "strings"
+
+// Karşı taraf özeti listesi sınırları
+const (
+	DefaultCounterpartyLimit = 10
+	MaxCounterpartyLimit     = 50
+)
+
+// CounterpartySummary kullanıcının bir karşı tarafla yaptığı transferlerin özeti (karşı taraf maskelenir)
+type CounterpartySummary struct {
+	Name             string  `json:"name"`
+	Email            string  `json:"email"`
+	TotalSent        float64 `json:"total_sent"`
+	TotalReceived    float64 `json:"total_received"`
+	TransactionCount int     `json:"transaction_count"`
+}
+
+// Volume karşı tarafla toplam işlem hacmini döner (gönderilen + alınan)
+func (c *CounterpartySummary) Volume() float64 {
+	return c.TotalSent + c.TotalReceived
+}
+
+// MaskName isimdeki her kelimenin yalnızca ilk harfini bırakır ("Ali Yılmaz" -> "A** Y*****")
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = maskRunes(word, 1)
+	}
+	return strings.Join(words, " ")
+}
+
+// MaskEmail email'in yerel kısmının yalnızca ilk harfini bırakır ("ali@example.com" -> "a**@example.com")
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return maskRunes(email, 1)
+	}
+	return maskRunes(email[:at], 1) + email[at:]
+}
+
+// maskRunes ilk keep karakter dışındaki karakterleri '*' ile değiştirir (UTF-8 güvenli)
+func maskRunes(value string, keep int) string {
+	runes := []rune(value)
+	for i := keep; i < len(runes); i++ {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
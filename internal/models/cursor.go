@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor cursor parametresi çözülemediğinde döner
+var ErrInvalidCursor = errors.New("geçersiz cursor")
+
+// HistoryCursor keyset sayfalamada son görülen satırın konumu (created_at + id).
+// Client'a opak bir string olarak verilir; içeriğine güvenilmemeli, sadece geri gönderilmelidir.
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// Encode cursor'u URL-safe base64 string'e çevirir
+func (c *HistoryCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeHistoryCursor Encode ile üretilmiş cursor'u çözer
+func DecodeHistoryCursor(value string) (*HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("%w: id", ErrInvalidCursor)
+	}
+
+	return &HistoryCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// TransactionPage cursor ile getirilen transaction sayfası (NextCursor boş = son sayfa)
+type TransactionPage struct {
+	Transactions []*Transaction
+	NextCursor   string
+}
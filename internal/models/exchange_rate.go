@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ExchangeRate iki para birimi arasındaki kuru temsil eder (1 BaseCurrency = Rate QuoteCurrency)
+type ExchangeRate struct {
+	ID            int       `json:"id" db:"id"`
+	BaseCurrency  string    `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency" db:"quote_currency"`
+	Rate          float64   `json:"rate" db:"rate"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Idempotency key status constants
+const (
+	IdempotencyStatusInProgress = "in_progress"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// MaxIdempotencyKeyLength Idempotency-Key header'ının maksimum uzunluğu (idempotency_keys.idempotency_key VARCHAR(255))
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyRecord kullanıcının Idempotency-Key ile yaptığı isteğin kaydı ve (tamamlandıysa) sonucu
+type IdempotencyRecord struct {
+	ID            int       `json:"id" db:"id"`
+	UserID        int       `json:"user_id" db:"user_id"`
+	Key           string    `json:"idempotency_key" db:"idempotency_key"`
+	Endpoint      string    `json:"endpoint" db:"endpoint"`
+	RequestHash   string    `json:"request_hash" db:"request_hash"` // Aynı key'in farklı istekle kullanımını yakalamak için
+	Status        string    `json:"status" db:"status"`
+	TransactionID *int      `json:"transaction_id,omitempty" db:"transaction_id"`
+	NewBalance    float64   `json:"new_balance" db:"new_balance"` // Credit/debit yanıtındaki işlem sonrası bakiye
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsCompleted ilk istek sonuçlandı mı
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.Status == IdempotencyStatusCompleted
+}
+
+// ValidateIdempotencyKey Idempotency-Key header değerini doğrular
+func ValidateIdempotencyKey(key string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("Idempotency-Key boş olamaz")
+	}
+	if len(key) > MaxIdempotencyKeyLength {
+		return fmt.Errorf("Idempotency-Key en fazla %d karakter olabilir", MaxIdempotencyKeyLength)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bildirim event tipleri (kullanıcı tercihleriyle eşleşir)
+const (
+	NotificationTransferReceived = "transfer_received"
+	NotificationLowBalance       = "low_balance"
+	NotificationSecurityEvent    = "security_event"
+)
+
+// NotificationPreferences kullanıcının hangi olaylar için bildirim almak istediği
+type NotificationPreferences struct {
+	UserID           int       `json:"user_id" db:"user_id"`
+	TransferReceived bool      `json:"transfer_received" db:"transfer_received"`
+	LowBalance       bool      `json:"low_balance" db:"low_balance"`
+	SecurityEvents   bool      `json:"security_events" db:"security_events"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultNotificationPreferences kayıt yokken uygulanan varsayılanlar (tüm bildirimler açık)
+func DefaultNotificationPreferences(userID int) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:           userID,
+		TransferReceived: true,
+		LowBalance:       true,
+		SecurityEvents:   true,
+	}
+}
+
+// Allows event tipinin bildirilip bildirilmeyeceğini döner (bilinmeyen event'ler engellenmez)
+func (p *NotificationPreferences) Allows(event string) bool {
+	switch event {
+	case NotificationTransferReceived:
+		return p.TransferReceived
+	case NotificationLowBalance:
+		return p.LowBalance
+	case NotificationSecurityEvent:
+		return p.SecurityEvents
+	default:
+		return true
+	}
+}
+
+// UpdateNotificationPreferencesRequest bildirim tercihi güncelleme isteği (nil = değiştirilmeyecek)
+type UpdateNotificationPreferencesRequest struct {
+	TransferReceived *bool `json:"transfer_received,omitempty"`
+	LowBalance       *bool `json:"low_balance,omitempty"`
+	SecurityEvents   *bool `json:"security_events,omitempty"`
+}
+
+// Validate en az bir tercihin gönderildiğini kontrol eder
+func (r *UpdateNotificationPreferencesRequest) Validate() error {
+	if r.TransferReceived == nil && r.LowBalance == nil && r.SecurityEvents == nil {
+		return fmt.Errorf("güncellenecek en az bir bildirim tercihi belirtilmeli")
+	}
+	return nil
+}
+
+// ApplyTo gönderilen tercihleri mevcut tercihlerin üzerine yazar
+func (r *UpdateNotificationPreferencesRequest) ApplyTo(preferences *NotificationPreferences) {
+	if r.TransferReceived != nil {
+		preferences.TransferReceived = *r.TransferReceived
+	}
+	if r.LowBalance != nil {
+		preferences.LowBalance = *r.LowBalance
+	}
+	if r.SecurityEvents != nil {
+		preferences.SecurityEvents = *r.SecurityEvents
+	}
+}
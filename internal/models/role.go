@@ -0,0 +1,42 @@
+package models
+
+import "strings"
+
+// Sistemde tanımlı kullanıcı rolleri
+const (
+	RoleUser     = "user"
+	RoleMod      = "mod"
+	RoleAdmin    = "admin"
+	RoleMerchant = "merchant"
+)
+
+// knownRoles tanımlı roller (sıralı, hata mesajlarında bu sırayla listelenir)
+var knownRoles = []string{RoleUser, RoleAdmin, RoleMod, RoleMerchant}
+
+// KnownRoles tanımlı rollerin kopyasını döner
+func KnownRoles() []string {
+	return append([]string(nil), knownRoles...)
+}
+
+// NormalizeRole rolü karşılaştırmalarda kullanılan kanonik biçime (boşluksuz, küçük harf) çevirir.
+// Token üretimi ve RBAC kontrolleri rolü bu fonksiyondan geçirir.
+func NormalizeRole(role string) string {
+	return strings.ToLower(strings.TrimSpace(role))
+}
+
+// IsKnownRole rolün tanımlı olup olmadığını döner (büyük/küçük harf duyarsız)
+func IsKnownRole(role string) bool {
+	role = NormalizeRole(role)
+	for _, known := range knownRoles {
+		if role == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrivilegedRole rolün sadece sistem yöneticisi tarafından atanabilen bir rol olup olmadığını döner
+func IsPrivilegedRole(role string) bool {
+	role = NormalizeRole(role)
+	return role == RoleAdmin || role == RoleMod
+}
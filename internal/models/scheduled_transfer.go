@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Scheduled transfer status constants
+const (
+	ScheduledStatusScheduled  = "scheduled"  // Zamanı bekleniyor (iptal edilebilir)
+	ScheduledStatusProcessing = "processing" // Scheduler tarafından queue'ya verildi
+	ScheduledStatusCompleted  = "completed"
+	ScheduledStatusFailed     = "failed"
+	ScheduledStatusCancelled  = "cancelled"
+)
+
+// ScheduledTransfer ileri tarihli transfer kaydı
+type ScheduledTransfer struct {
+	ID            int       `json:"id" db:"id"`
+	FromUserID    int       `json:"from_user_id" db:"from_user_id"`
+	ToUserID      int       `json:"to_user_id" db:"to_user_id"`
+	Amount        float64   `json:"amount" db:"amount"`
+	Description   string    `json:"description,omitempty" db:"description"`
+	ScheduledAt   time.Time `json:"scheduled_at" db:"scheduled_at"`
+	Status        string    `json:"status" db:"status"`
+	TransactionID *int      `json:"transaction_id,omitempty" db:"transaction_id"` // Çalıştırıldıysa oluşan transfer
+	LastError     string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsCancellable transfer hâlâ iptal edilebilir mi (scheduler henüz almadı)
+func (s *ScheduledTransfer) IsCancellable() bool {
+	return s.Status == ScheduledStatusScheduled
+}
+
+// ToTransferRequest zamanı gelen kaydı queue'ya verilecek (anlık) transfer isteğine çevirir
+func (s *ScheduledTransfer) ToTransferRequest() *TransferRequest {
+	return &TransferRequest{
+		ToUserID:    s.ToUserID,
+		Amount:      s.Amount,
+		Description: s.Description,
+	}
+}
+
+// ValidateScheduledAt zamanlama anının gelecekte olduğunu doğrular
+func ValidateScheduledAt(scheduledAt, now time.Time) error {
+	if !scheduledAt.After(now) {
+		return fmt.Errorf("scheduled_at gelecekte bir zaman olmalıdır")
+	}
+	return nil
+}
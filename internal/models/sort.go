@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Geçmiş (history) listelerinde sıralama yönleri
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// Transaction geçmişinde tutara göre sıralama değerleri
+const (
+	SortAmountAsc  = "amount_asc"
+	SortAmountDesc = "amount_desc"
+)
+
+// defaultSortOrder sort parametresi verilmediğinde kullanılan yön (config ile değiştirilebilir)
+var defaultSortOrder = SortDesc
+
+// SetDefaultSortOrder geçmiş listelerinin varsayılan sıralama yönünü ayarlar
+func SetDefaultSortOrder(order string) error {
+	parsed, err := ParseSortOrder(order)
+	if err != nil {
+		return err
+	}
+	defaultSortOrder = parsed
+	return nil
+}
+
+// DefaultSortOrder geçmiş listelerinin varsayılan sıralama yönünü döner
+func DefaultSortOrder() string {
+	return defaultSortOrder
+}
+
+// ParseSortOrder sort parametresini doğrular (boş ise varsayılan yön döner)
+func ParseSortOrder(order string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "":
+		return defaultSortOrder, nil
+	case SortAsc:
+		return SortAsc, nil
+	case SortDesc:
+		return SortDesc, nil
+	default:
+		return "", fmt.Errorf("geçersiz sort değeri: %s. Geçerli değerler: asc, desc", order)
+	}
+}
+
+// ParseHistorySort transaction geçmişi sort parametresini doğrular (asc/desc tarihe, amount_* tutara göre sıralar)
+func ParseHistorySort(order string) (string, error) {
+	switch normalized := strings.ToLower(strings.TrimSpace(order)); normalized {
+	case SortAmountAsc, SortAmountDesc:
+		return normalized, nil
+	default:
+		if sort, err := ParseSortOrder(order); err == nil {
+			return sort, nil
+		}
+		return "", fmt.Errorf("geçersiz sort değeri: %s. Geçerli değerler: asc, desc, amount_asc, amount_desc", order)
+	}
+}
@@ -2,15 +2,17 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
 // Transaction status constants
 const (
-	StatusPending   = "pending"
-	StatusCompleted = "completed"
-	StatusFailed    = "failed"
-	StatusCancelled = "cancelled"
+	StatusPending       = "pending"
+	StatusPendingReview = "pending_review" // Fraud incelemesi bekleyen transfer (bakiye henüz hareket etmedi)
+	StatusCompleted     = "completed"
+	StatusFailed        = "failed"
+	StatusCancelled     = "cancelled"
 )
 
 type Transaction struct {
@@ -21,15 +23,70 @@ type Transaction struct {
 	Type        string    `json:"type" db:"type"`
 	Status      string    `json:"status" db:"status"`
 	Description string    `json:"description" db:"description"`
+	Channel     string    `json:"channel,omitempty" db:"channel"` // Başlatan kanal (web/mobile/api), bilinmiyorsa boş
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	ReversedTransactionID *int `json:"reversed_transaction_id,omitempty" db:"reversed_transaction_id"` // Ters kayıtsa, ters çevrilen orijinal transaction
+	RefundedTransactionID *int `json:"refunded_transaction_id,omitempty" db:"refunded_transaction_id"` // Kısmi iadeyse, iade edilen orijinal transaction
+
+	LimitWarning *LimitWarning `json:"limit_warning,omitempty" db:"-"` // Sadece transfer yanıtında, soft limit eşiği geçildiyse
 }
 
 type TransferRequest struct {
-	ToUserID    int     `json:"to_user_id"`
+	ToUserID    int        `json:"to_user_id"`
+	Amount      float64    `json:"amount"`
+	Description string     `json:"description"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"` // Gelecekte bir an verilirse transfer o zamana zamanlanır
+}
+
+// TransferByAccountNumberRequest hesap numarası ile transfer isteği
+type TransferByAccountNumberRequest struct {
+	ToAccountNumber string  `json:"to_account_number"`
+	Amount          float64 `json:"amount"`
+	Description     string  `json:"description"`
+}
+
+// TransferByEmailRequest alıcının email adresi ile transfer isteği
+type TransferByEmailRequest struct {
+	ToEmail     string  `json:"to_email"`
 	Amount      float64 `json:"amount"`
 	Description string  `json:"description"`
 }
 
+// Batch transfer modları
+const (
+	BatchModeAtomic     = "atomic"      // Hepsi ya da hiçbiri (tek DB transaction)
+	BatchModeBestEffort = "best_effort" // Her transfer kendi transaction'ında, hatalar raporlanır
+)
+
+// MaxBatchTransferItems tek batch'teki maksimum transfer sayısı
+const MaxBatchTransferItems = 100
+
+// BatchTransferRequest birden fazla alıcıya toplu transfer isteği
+type BatchTransferRequest struct {
+	Mode      string            `json:"mode"` // atomic (varsayılan) | best_effort
+	Transfers []TransferRequest `json:"transfers"`
+}
+
+// BatchTransferItemResult batch içindeki tek transferin sonucu
+type BatchTransferItemResult struct {
+	ToUserID    int          `json:"to_user_id"`
+	Amount      float64      `json:"amount"`
+	Success     bool         `json:"success"`
+	Skipped     bool         `json:"skipped,omitempty"` // Bakiye tükendiği için denenmedi
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// BatchTransferResult batch transfer yanıtı
+type BatchTransferResult struct {
+	Mode      string                    `json:"mode"`
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Skipped   int                       `json:"skipped"`
+	Results   []BatchTransferItemResult `json:"results"`
+}
+
 // CreditRequest hesaba para yatırma isteği
 type CreditRequest struct {
 	Amount      float64 `json:"amount"`
@@ -44,18 +101,20 @@ type DebitRequest struct {
 
 // DebitResponse para çekme yanıtı
 type DebitResponse struct {
-	Success     bool                `json:"success"`
-	Transaction *TransactionSummary `json:"transaction"`
-	NewBalance  float64             `json:"new_balance"`
-	Message     string              `json:"message"`
+	Success      bool                `json:"success"`
+	Transaction  *TransactionSummary `json:"transaction"`
+	NewBalance   float64             `json:"new_balance"`
+	Message      string              `json:"message"`
+	LimitWarning *LimitWarning       `json:"limit_warning,omitempty"` // Soft limit eşiği geçildiyse
 }
 
 // CreditResponse para yatırma yanıtı
 type CreditResponse struct {
-	Success     bool                `json:"success"`
-	Transaction *TransactionSummary `json:"transaction"`
-	NewBalance  float64             `json:"new_balance"`
-	Message     string              `json:"message"`
+	Success      bool                `json:"success"`
+	Transaction  *TransactionSummary `json:"transaction"`
+	NewBalance   float64             `json:"new_balance"`
+	Message      string              `json:"message"`
+	LimitWarning *LimitWarning       `json:"limit_warning,omitempty"` // Soft limit eşiği geçildiyse
 }
 
 // TransactionSummary hassas bilgileri filtrelenmiş transaction
@@ -80,6 +139,65 @@ type TransactionStats struct {
 	TotalDebitAmount    float64 `json:"total_debit_amount" db:"total_debit_amount"`
 	TotalTransferAmount float64 `json:"total_transfer_amount" db:"total_transfer_amount"`
 	LastTransactionDate *string `json:"last_transaction_date" db:"last_transaction_date"`
+
+	// Registry'deki tüm tipler için sayı ve tutarlar (refund, fee gibi yeni tipler dahil)
+	CountsByType  map[string]int     `json:"counts_by_type"`
+	AmountsByType map[string]float64 `json:"amounts_by_type"`
+
+	// Kanal bazlı işlem sayıları (kanalı bilinmeyenler "unknown" altında)
+	CountsByChannel map[string]int `json:"counts_by_channel"`
+}
+
+// UnknownChannel kanal bilgisi olmayan işlemlerin istatistikteki anahtarı
+const UnknownChannel = "unknown"
+
+// NewTransactionStats kayıtlı tüm tipler sıfırla başlatılmış istatistik oluşturur
+func NewTransactionStats(userID int) *TransactionStats {
+	stats := &TransactionStats{
+		UserID:          userID,
+		CountsByType:    make(map[string]int),
+		AmountsByType:   make(map[string]float64),
+		CountsByChannel: make(map[string]int),
+	}
+	for _, txType := range TransactionTypes() {
+		stats.CountsByType[txType] = 0
+		stats.AmountsByType[txType] = 0
+	}
+	return stats
+}
+
+// AddTypeTotals bir tipin sayı ve tutarını istatistiğe ekler (kayıtlı olmayan tipler yok sayılır)
+func (s *TransactionStats) AddTypeTotals(txType string, count int, amount float64) {
+	if !IsValidTransactionType(txType) {
+		return
+	}
+
+	s.TotalTransactions += count
+	s.CountsByType[txType] += count
+	s.AmountsByType[txType] += amount
+
+	switch txType {
+	case TypeCredit:
+		s.TotalCredits += count
+		s.TotalCreditAmount += amount
+	case TypeDebit:
+		s.TotalDebits += count
+		s.TotalDebitAmount += amount
+	case TypeTransfer:
+		s.TotalTransfers += count
+		s.TotalTransferAmount += amount
+	}
+}
+
+// AddChannelTotals bir tip/kanal çiftinin sayısını kanal dağılımına ekler (kayıtlı olmayan tipler yok sayılır)
+func (s *TransactionStats) AddChannelTotals(txType, channel string, count int) {
+	if !IsValidTransactionType(txType) {
+		return
+	}
+	if channel == "" {
+		channel = UnknownChannel
+	}
+	s.CountsByChannel[channel] += count
 }
 
 //         TRANSACTION STATE MANAGEMENT METHODS
@@ -87,14 +205,15 @@ type TransactionStats struct {
 // ValidateStatus status'un geçerli olup olmadığını kontrol eder
 func (t *Transaction) ValidateStatus() error {
 	validStatuses := map[string]bool{
-		StatusPending:   true,
-		StatusCompleted: true,
-		StatusFailed:    true,
-		StatusCancelled: true,
+		StatusPending:       true,
+		StatusPendingReview: true,
+		StatusCompleted:     true,
+		StatusFailed:        true,
+		StatusCancelled:     true,
 	}
 
 	if !validStatuses[t.Status] {
-		return fmt.Errorf("geçersiz transaction status: %s. Geçerli statuslar: pending, completed, failed, cancelled", t.Status)
+		return fmt.Errorf("geçersiz transaction status: %s. Geçerli statuslar: pending, pending_review, completed, failed, cancelled", t.Status)
 	}
 
 	return nil
@@ -110,10 +229,11 @@ func (t *Transaction) CanTransition(newStatus string) error {
 
 	// State transition rules (finite state machine)
 	transitions := map[string][]string{
-		StatusPending:   {StatusCompleted, StatusFailed, StatusCancelled},
-		StatusCompleted: {}, // Completed'dan başka yere geçilemez
-		StatusFailed:    {}, // Failed'dan başka yere geçilemez
-		StatusCancelled: {}, // Cancelled'dan başka yere geçilemez
+		StatusPending:       {StatusCompleted, StatusFailed, StatusCancelled, StatusPendingReview},
+		StatusPendingReview: {StatusCompleted, StatusFailed}, // İnceleme sonucu: onay veya red
+		StatusCompleted:     {},                              // Completed'dan başka yere geçilemez
+		StatusFailed:        {},                              // Failed'dan başka yere geçilemez
+		StatusCancelled:     {},                              // Cancelled'dan başka yere geçilemez
 	}
 
 	allowedTransitions, exists := transitions[t.Status]
@@ -152,10 +272,11 @@ func (t *Transaction) SetStatus(newStatus string) error {
 // GetValidTransitions mevcut status'tan geçilebilecek status'ları döner
 func (t *Transaction) GetValidTransitions() []string {
 	transitions := map[string][]string{
-		StatusPending:   {StatusCompleted, StatusFailed, StatusCancelled},
-		StatusCompleted: {},
-		StatusFailed:    {},
-		StatusCancelled: {},
+		StatusPending:       {StatusCompleted, StatusFailed, StatusCancelled, StatusPendingReview},
+		StatusPendingReview: {StatusCompleted, StatusFailed},
+		StatusCompleted:     {},
+		StatusFailed:        {},
+		StatusCancelled:     {},
 	}
 
 	if allowedTransitions, exists := transitions[t.Status]; exists {
@@ -172,6 +293,11 @@ func (t *Transaction) IsPending() bool {
 	return t.Status == StatusPending
 }
 
+// IsPendingReview transaction fraud incelemesinde mi
+func (t *Transaction) IsPendingReview() bool {
+	return t.Status == StatusPendingReview
+}
+
 // IsCompleted transaction completed durumunda mı
 func (t *Transaction) IsCompleted() bool {
 	return t.Status == StatusCompleted
@@ -201,32 +327,22 @@ func (t *Transaction) CanBeModified() bool {
 
 // ValidateType transaction type'ının geçerli olup olmadığını kontrol eder
 func (t *Transaction) ValidateType() error {
-	validTypes := map[string]bool{
-		"credit":   true,
-		"debit":    true,
-		"transfer": true,
-	}
-
-	if !validTypes[t.Type] {
-		return fmt.Errorf("geçersiz transaction type: %s. Geçerli tipler: credit, debit, transfer", t.Type)
-	}
-
-	return nil
+	return ValidateTransactionType(t.Type)
 }
 
 // IsCredit credit transaction mı
 func (t *Transaction) IsCredit() bool {
-	return t.Type == "credit"
+	return t.Type == TypeCredit
 }
 
 // IsDebit debit transaction mı
 func (t *Transaction) IsDebit() bool {
-	return t.Type == "debit"
+	return t.Type == TypeDebit
 }
 
 // IsTransfer transfer transaction mı
 func (t *Transaction) IsTransfer() bool {
-	return t.Type == "transfer"
+	return t.Type == TypeTransfer
 }
 
 //               TRANSACTION VALIDATION
@@ -250,21 +366,21 @@ func (t *Transaction) Validate() error {
 
 	// Type'a göre user ID kontrolü
 	switch t.Type {
-	case "credit":
+	case TypeCredit:
 		if t.ToUserID == nil {
 			return fmt.Errorf("credit transaction için to_user_id gerekli")
 		}
 		if t.FromUserID != nil {
 			return fmt.Errorf("credit transaction için from_user_id olmamalı")
 		}
-	case "debit":
+	case TypeDebit:
 		if t.FromUserID == nil {
 			return fmt.Errorf("debit transaction için from_user_id gerekli")
 		}
 		if t.ToUserID != nil {
 			return fmt.Errorf("debit transaction için to_user_id olmamalı")
 		}
-	case "transfer":
+	case TypeTransfer:
 		if t.FromUserID == nil || t.ToUserID == nil {
 			return fmt.Errorf("transfer transaction için hem from_user_id hem to_user_id gerekli")
 		}
@@ -284,7 +400,7 @@ func NewCreditTransaction(toUserID int, amount float64, description string) *Tra
 		ToUserID:    &toUserID,
 		FromUserID:  nil,
 		Amount:      amount,
-		Type:        "credit",
+		Type:        TypeCredit,
 		Status:      StatusPending,
 		Description: description,
 		CreatedAt:   time.Now(),
@@ -297,7 +413,7 @@ func NewDebitTransaction(fromUserID int, amount float64, description string) *Tr
 		FromUserID:  &fromUserID,
 		ToUserID:    nil,
 		Amount:      amount,
-		Type:        "debit",
+		Type:        TypeDebit,
 		Status:      StatusPending,
 		Description: description,
 		CreatedAt:   time.Now(),
@@ -310,13 +426,62 @@ func NewTransferTransaction(fromUserID, toUserID int, amount float64, descriptio
 		FromUserID:  &fromUserID,
 		ToUserID:    &toUserID,
 		Amount:      amount,
-		Type:        "transfer",
+		Type:        TypeTransfer,
 		Status:      StatusPending,
 		Description: description,
 		CreatedAt:   time.Now(),
 	}
 }
 
+// NewReversalTransaction orijinal transaction'ı dengeleyen ters kaydı oluşturur.
+// Taraflar yer değiştirir: credit → debit, debit → credit, transfer → alıcıdan gönderene transfer.
+func NewReversalTransaction(original *Transaction, description string) (*Transaction, error) {
+	reversal, err := newCounterTransaction(original, original.Amount, description)
+	if err != nil {
+		return nil, err
+	}
+
+	originalID := original.ID
+	reversal.ReversedTransactionID = &originalID
+	return reversal, nil
+}
+
+// NewRefundTransaction orijinal transaction'ın amount kadarını geri veren kısmi iade kaydını oluşturur.
+// Yön ters kayıtla aynıdır; kayıt RefundedTransactionID ile orijinale bağlanır.
+func NewRefundTransaction(original *Transaction, amount float64, description string) (*Transaction, error) {
+	refund, err := newCounterTransaction(original, amount, description)
+	if err != nil {
+		return nil, err
+	}
+
+	originalID := original.ID
+	refund.RefundedTransactionID = &originalID
+	return refund, nil
+}
+
+// newCounterTransaction orijinalin taraflarını yer değiştiren amount tutarlı pending kaydı oluşturur
+func newCounterTransaction(original *Transaction, amount float64, description string) (*Transaction, error) {
+	switch original.Type {
+	case TypeCredit:
+		if original.ToUserID == nil {
+			return nil, fmt.Errorf("credit transaction'ının alıcısı yok")
+		}
+		return NewDebitTransaction(*original.ToUserID, amount, description), nil
+	case TypeDebit:
+		if original.FromUserID == nil {
+			return nil, fmt.Errorf("debit transaction'ının göndereni yok")
+		}
+		return NewCreditTransaction(*original.FromUserID, amount, description), nil
+	case TypeTransfer:
+		if original.FromUserID == nil || original.ToUserID == nil {
+			return nil, fmt.Errorf("transfer transaction'ının tarafları eksik")
+		}
+		return NewTransferTransaction(*original.ToUserID, *original.FromUserID, amount, description), nil
+	default:
+		return nil, fmt.Errorf("%s tipindeki transaction ters çevrilemez", original.Type)
+	}
+}
+
 //         REQUEST VALIDATION METHODS
 
 // Validate TransferRequest'i doğrular
@@ -329,21 +494,95 @@ func (req *TransferRequest) Validate() error {
 		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
 	}
 
-	if req.Amount > 1000000 {
-		return fmt.Errorf("maksimum transfer limiti: 1,000,000 TL")
+	return nil
+}
+
+// ValidateDescription belirtilen tutarın üzerindeki transferlerde açıklamanın girildiğini doğrular (requiredAbove <= 0 ise kontrol yok)
+func (req *TransferRequest) ValidateDescription(requiredAbove float64) error {
+	if requiredAbove <= 0 || req.Amount <= requiredAbove {
+		return nil
+	}
+
+	if strings.TrimSpace(req.Description) == "" {
+		return fmt.Errorf("%.2f TL üzerindeki transferlerde açıklama (transfer amacı) zorunludur", requiredAbove)
 	}
 
 	return nil
 }
 
-// Validate CreditRequest'i doğrular
-func (req *CreditRequest) Validate() error {
+// Validate TransferByAccountNumberRequest'i doğrular (checksum lookup'tan önce kontrol edilir)
+func (req *TransferByAccountNumberRequest) Validate() error {
+	if strings.TrimSpace(req.ToAccountNumber) == "" {
+		return fmt.Errorf("alıcı hesap numarası gereklidir")
+	}
+	if err := ValidateAccountNumber(req.ToAccountNumber); err != nil {
+		return err
+	}
 	if req.Amount <= 0 {
 		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
 	}
+	return nil
+}
 
-	if req.Amount > 1000000 {
-		return fmt.Errorf("maksimum yatırma limiti: 1,000,000 TL")
+// ToTransferRequest çözümlenen alıcı ID'si ile standart transfer isteğini oluşturur
+func (req *TransferByAccountNumberRequest) ToTransferRequest(toUserID int) *TransferRequest {
+	return &TransferRequest{
+		ToUserID:    toUserID,
+		Amount:      req.Amount,
+		Description: req.Description,
+	}
+}
+
+// Validate TransferByEmailRequest'i doğrular, email lookup'tan önce normalize edilir
+func (req *TransferByEmailRequest) Validate() error {
+	req.ToEmail = strings.ToLower(strings.TrimSpace(req.ToEmail))
+	if req.ToEmail == "" {
+		return fmt.Errorf("alıcı email adresi gereklidir")
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
+	}
+	return nil
+}
+
+// ToTransferRequest çözümlenen alıcı ID'si ile standart transfer isteğini oluşturur
+func (req *TransferByEmailRequest) ToTransferRequest(toUserID int) *TransferRequest {
+	return &TransferRequest{
+		ToUserID:    toUserID,
+		Amount:      req.Amount,
+		Description: req.Description,
+	}
+}
+
+// Validate BatchTransferRequest'i doğrular, mod boşsa atomic kabul edilir
+func (req *BatchTransferRequest) Validate() error {
+	if req.Mode == "" {
+		req.Mode = BatchModeAtomic
+	}
+	if req.Mode != BatchModeAtomic && req.Mode != BatchModeBestEffort {
+		return fmt.Errorf("geçersiz batch modu: %s. Geçerli modlar: atomic, best_effort", req.Mode)
+	}
+
+	if len(req.Transfers) == 0 {
+		return fmt.Errorf("en az bir transfer gereklidir")
+	}
+	if len(req.Transfers) > MaxBatchTransferItems {
+		return fmt.Errorf("bir batch'te en fazla %d transfer yapılabilir", MaxBatchTransferItems)
+	}
+
+	for i := range req.Transfers {
+		if err := req.Transfers[i].Validate(); err != nil {
+			return fmt.Errorf("transfer #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate CreditRequest'i doğrular
+func (req *CreditRequest) Validate() error {
+	if req.Amount <= 0 {
+		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
 	}
 
 	return nil
@@ -355,9 +594,52 @@ func (req *DebitRequest) Validate() error {
 		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
 	}
 
-	if req.Amount > 1000000 {
-		return fmt.Errorf("maksimum çekme limiti: 1,000,000 TL")
+	return nil
+}
+
+// ReverseTransactionRequest admin ters kayıt (iade) isteği
+type ReverseTransactionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Validate ReverseTransactionRequest'i doğrular
+func (req *ReverseTransactionRequest) Validate() error {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return fmt.Errorf("ters kayıt sebebi zorunludur")
+	}
+	if len(req.Reason) > 255 {
+		return fmt.Errorf("ters kayıt sebebi en fazla 255 karakter olabilir")
 	}
+	return nil
+}
 
+// RefundTransactionRequest kısmi iade isteği (tutar orijinalin iade edilmemiş kısmını aşamaz)
+type RefundTransactionRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// Validate RefundTransactionRequest'i doğrular
+func (req *RefundTransactionRequest) Validate() error {
+	if req.Amount <= 0 {
+		return fmt.Errorf("iade tutarı sıfırdan büyük olmalıdır")
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return fmt.Errorf("iade sebebi zorunludur")
+	}
+	if len(req.Reason) > 255 {
+		return fmt.Errorf("iade sebebi en fazla 255 karakter olabilir")
+	}
 	return nil
 }
+
+// RefundResult oluşturulan iade kaydı ve orijinal transaction'ın güncel iade durumu
+type RefundResult struct {
+	Refund              *Transaction `json:"refund"`
+	OriginalAmount      float64      `json:"original_amount"`
+	RefundedTotal       float64      `json:"refunded_total"`       // Bu iade dahil kümülatif iade tutarı
+	RemainingRefundable float64      `json:"remaining_refundable"` // Hâlâ iade edilebilecek tutar
+}
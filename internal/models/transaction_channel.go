@@ -0,0 +1,21 @@
+package models
+
+import "strings"
+
+// Transaction'ı başlatan kanal (analitik için transactions.channel kolonunda saklanır)
+const (
+	ChannelWeb    = "web"
+	ChannelMobile = "mobile"
+	ChannelAPI    = "api"
+)
+
+// NormalizeChannel kanal değerini küçük harfe çevirir; tanımsız kanallar için boş string döner
+func NormalizeChannel(channel string) string {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	switch channel {
+	case ChannelWeb, ChannelMobile, ChannelAPI:
+		return channel
+	default:
+		return ""
+	}
+}
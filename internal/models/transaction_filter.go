@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TransactionHistoryFilter transaction geçmişi filtreleri (boş/sıfır alan = o filtre uygulanmaz)
+type TransactionHistoryFilter struct {
+	Type   string
+	Status string
+	From   time.Time // Dahil
+	To     time.Time // Hariç
+	Sort   string    // asc | desc | amount_asc | amount_desc
+
+	// Cursor verilirse bu satırdan sonraki kayıtlar (sort yönünde) getirilir; sadece tarih sıralamasında geçerli
+	Cursor *HistoryCursor
+}
+
+// Validate filtre değerlerini bilinen tip/status sabitlerine göre doğrular ve sort'u normalize eder
+func (f *TransactionHistoryFilter) Validate() error {
+	if f.Type != "" {
+		if err := ValidateTransactionType(f.Type); err != nil {
+			return err
+		}
+	}
+	if f.Status != "" {
+		if err := (&Transaction{Status: f.Status}).ValidateStatus(); err != nil {
+			return err
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return fmt.Errorf("from tarihi to tarihinden önce olmalıdır")
+	}
+
+	sort, err := ParseHistorySort(f.Sort)
+	if err != nil {
+		return err
+	}
+	f.Sort = sort
+	return nil
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limit periyotları
+const (
+	LimitPeriodDaily   = "daily"
+	LimitPeriodMonthly = "monthly"
+)
+
+// TransactionUsage kullanıcının bir andan beri yaptığı işlem sayısı ve gönderdiği toplam tutar
+type TransactionUsage struct {
+	Count  int     `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+// TransferAmountHistory kullanıcının bir andan beri tamamlanmış giden transferlerinin sayısı, ortalaması ve en büyüğü
+type TransferAmountHistory struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+	Max     float64 `json:"max"`
+}
+
+// CountAllowance işlem sayısı limiti ve kullanımı (Limit/Remaining nil = limitsiz)
+type CountAllowance struct {
+	Limit     *int `json:"limit"`
+	Used      int  `json:"used"`
+	Remaining *int `json:"remaining"`
+}
+
+// AmountAllowance işlem tutarı limiti ve kullanımı (Limit/Remaining nil = limitsiz)
+type AmountAllowance struct {
+	Limit     *float64 `json:"limit"`
+	Used      float64  `json:"used"`
+	Remaining *float64 `json:"remaining"`
+}
+
+// PeriodAllowance bir periyottaki (günlük/aylık) sayı ve tutar kullanımı
+type PeriodAllowance struct {
+	Period string          `json:"period"`
+	Since  time.Time       `json:"since"`
+	Count  CountAllowance  `json:"count"`
+	Amount AmountAllowance `json:"amount"`
+}
+
+// LimitWarning işlem kabul edildi ama kullanım soft limit eşiğini geçti (X-Limit-Warning header'ı ve yanıt alanı).
+// Sayı limiti uyarısında Used/Limit/Remaining, tutar limiti uyarısında *Amount alanları dolar.
+type LimitWarning struct {
+	Period          string  `json:"period"`
+	Used            int     `json:"used"`
+	Limit           int     `json:"limit"`
+	Remaining       int     `json:"remaining"`
+	UsedAmount      float64 `json:"used_amount,omitempty"`
+	LimitAmount     float64 `json:"limit_amount,omitempty"`
+	RemainingAmount float64 `json:"remaining_amount,omitempty"`
+	Message         string  `json:"message"`
+}
+
+// NewCountLimitWarning kullanım limit*ratio eşiğine ulaştıysa uyarı, değilse nil döner (limit <= 0 = limitsiz)
+func NewCountLimitWarning(period string, limit, used int, ratio float64) *LimitWarning {
+	if limit <= 0 || ratio <= 0 || float64(used) < float64(limit)*ratio {
+		return nil
+	}
+
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	message := fmt.Sprintf("İşlem limitine yaklaşıldı (%s): %d/%d kullanıldı", period, used, limit)
+	if remaining == 0 {
+		message += ", limit doldu: sonraki işlemler reddedilecek"
+	}
+
+	return &LimitWarning{
+		Period:    period,
+		Used:      used,
+		Limit:     limit,
+		Remaining: remaining,
+		Message:   message,
+	}
+}
+
+// NewAmountLimitWarning giden tutar limit*ratio eşiğine ulaştıysa uyarı, değilse nil döner (limit <= 0 = limitsiz)
+func NewAmountLimitWarning(period string, limit, used float64, ratio float64) *LimitWarning {
+	if limit <= 0 || ratio <= 0 || used < limit*ratio {
+		return nil
+	}
+
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	message := fmt.Sprintf("Tutar limitine yaklaşıldı (%s): %.2f/%.2f TL kullanıldı", period, used, limit)
+	if remaining == 0 {
+		message += ", limit doldu: sonraki işlemler reddedilecek"
+	}
+
+	return &LimitWarning{
+		Period:          period,
+		UsedAmount:      used,
+		LimitAmount:     limit,
+		RemainingAmount: remaining,
+		Message:         message,
+	}
+}
+
+// HeaderValue X-Limit-Warning header değerini döner
+// (ör. "daily; used=45; limit=50; remaining=5" veya "daily; used_amount=900.00; limit_amount=1000.00; remaining_amount=100.00")
+func (w *LimitWarning) HeaderValue() string {
+	if w.LimitAmount > 0 {
+		return fmt.Sprintf("%s; used_amount=%.2f; limit_amount=%.2f; remaining_amount=%.2f", w.Period, w.UsedAmount, w.LimitAmount, w.RemainingAmount)
+	}
+	return fmt.Sprintf("%s; used=%d; limit=%d; remaining=%d", w.Period, w.Used, w.Limit, w.Remaining)
+}
+
+// TransactionLimits kullanıcının limitleri ve bugün/bu ay tükettiği kısım
+type TransactionLimits struct {
+	UserID            int             `json:"user_id"`
+	Role              string          `json:"role"`
+	MaxTransferAmount float64         `json:"max_transfer_amount"` // Tek transferde izin verilen üst tutar
+	Daily             PeriodAllowance `json:"daily"`
+	Monthly           PeriodAllowance `json:"monthly"`
+}
+
+// NewCountAllowance limit ve kullanımdan kalan hakkı hesaplar (limit <= 0 = limitsiz)
+func NewCountAllowance(limit, used int) CountAllowance {
+	allowance := CountAllowance{Used: used}
+	if limit > 0 {
+		remaining := limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		allowance.Limit = &limit
+		allowance.Remaining = &remaining
+	}
+	return allowance
+}
+
+// NewAmountAllowance limit ve kullanımdan kalan tutarı hesaplar (limit <= 0 = limitsiz)
+func NewAmountAllowance(limit, used float64) AmountAllowance {
+	allowance := AmountAllowance{Used: used}
+	if limit > 0 {
+		remaining := limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		allowance.Limit = &limit
+		allowance.Remaining = &remaining
+	}
+	return allowance
+}
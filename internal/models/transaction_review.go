@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Transaction review status constants
+const (
+	ReviewStatusOpen     = "open"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// TransactionReview fraud incelemesine alınan transferin inceleme kaydı
+type TransactionReview struct {
+	ID            int          `json:"id" db:"id"`
+	TransactionID int          `json:"transaction_id" db:"transaction_id"`
+	Reason        string       `json:"reason" db:"reason"`
+	Status        string       `json:"status" db:"status"`
+	Note          string       `json:"note,omitempty" db:"note"`
+	ReviewedBy    *int         `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt    *time.Time   `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+	Transaction   *Transaction `json:"transaction,omitempty"`
+}
+
+// ReviewDecisionRequest admin onay/red isteği (sebep opsiyonel)
+type ReviewDecisionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ReviewDecision admin inceleme kararının bilgileri (inceleme notuna ve audit kaydına yazılır)
+type ReviewDecision struct {
+	AdminUserID int
+	Reason      string
+	IPAddress   string
+	UserAgent   string
+}
+
+// IsValidReviewStatus inceleme status'unun geçerli olup olmadığını döner
+func IsValidReviewStatus(status string) bool {
+	switch status {
+	case ReviewStatusOpen, ReviewStatusApproved, ReviewStatusRejected:
+		return true
+	}
+	return false
+}
+
+// IsOpen inceleme henüz karara bağlanmamış mı
+func (r *TransactionReview) IsOpen() bool {
+	return r.Status == ReviewStatusOpen
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Transaction type constants
+const (
+	TypeCredit   = "credit"
+	TypeDebit    = "debit"
+	TypeTransfer = "transfer"
+)
+
+// maxTransactionTypeLength transactions.type kolonunun uzunluğu (VARCHAR(20))
+const maxTransactionTypeLength = 20
+
+// transactionTypeRegistry geçerli transaction type'larının tek kaynağı.
+// Yeni bir tip (refund, fee, adjustment...) sadece RegisterTransactionType ile eklenir;
+// validasyonlar ve istatistik sorgusu bu listeyi kullanır.
+var transactionTypeRegistry = struct {
+	sync.RWMutex
+	types []string
+}{
+	types: []string{TypeCredit, TypeDebit, TypeTransfer},
+}
+
+// RegisterTransactionType yeni bir transaction type'ını kayıt eder (zaten kayıtlıysa bir şey yapmaz)
+func RegisterTransactionType(txType string) error {
+	if txType == "" || len(txType) > maxTransactionTypeLength {
+		return fmt.Errorf("transaction type 1-%d karakter olmalıdır", maxTransactionTypeLength)
+	}
+	for _, r := range txType {
+		if (r < 'a' || r > 'z') && r != '_' {
+			return fmt.Errorf("transaction type sadece küçük harf ve '_' içerebilir: %s", txType)
+		}
+	}
+
+	transactionTypeRegistry.Lock()
+	defer transactionTypeRegistry.Unlock()
+
+	for _, existing := range transactionTypeRegistry.types {
+		if existing == txType {
+			return nil
+		}
+	}
+	transactionTypeRegistry.types = append(transactionTypeRegistry.types, txType)
+	return nil
+}
+
+// IsValidTransactionType transaction type'ının kayıtlı olup olmadığını döner
+func IsValidTransactionType(txType string) bool {
+	transactionTypeRegistry.RLock()
+	defer transactionTypeRegistry.RUnlock()
+
+	for _, existing := range transactionTypeRegistry.types {
+		if existing == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionTypes kayıtlı transaction type'larını kayıt sırasıyla döner
+func TransactionTypes() []string {
+	transactionTypeRegistry.RLock()
+	defer transactionTypeRegistry.RUnlock()
+
+	types := make([]string, len(transactionTypeRegistry.types))
+	copy(types, transactionTypeRegistry.types)
+	return types
+}
+
+// ValidateTransactionType transaction type'ını registry'ye göre doğrular
+func ValidateTransactionType(txType string) error {
+	if !IsValidTransactionType(txType) {
+		return fmt.Errorf("geçersiz transaction tipi: %s. Geçerli tipler: %s", txType, strings.Join(TransactionTypes(), ", "))
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -16,6 +18,10 @@ type User struct {
 	Password  string    `json:"-" db:"password"` // JSON'da gösterilmez
 	Role      string    `json:"role" db:"role"`  // YENİ: Role alanı eklendi
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	AccountNumber *string `json:"account_number,omitempty" db:"account_number"` // Paylaşılabilir hesap numarası (IBAN benzeri)
+
+	Version int `json:"version" db:"version"` // Her güncellemede artar (ETag / If-Match)
 }
 
 // CreateUserRequest kullanıcı oluşturma isteği
@@ -25,6 +31,7 @@ type CreateUserRequest struct {
 	Password        string `json:"password"`
 	ConfirmPassword string `json:"confirm_password"` // YENİ: Şifre tekrarı
 	Role            string `json:"role,omitempty"`   // YENİ: Role opsiyonel
+	AccountNumber   string `json:"-"`                // Register sırasında service tarafından üretilir
 }
 
 // LoginRequest giriş isteği
@@ -35,8 +42,10 @@ type LoginRequest struct {
 
 // LoginResponse giriş yanıtı
 type LoginResponse struct {
-	User  *User  `json:"user"`
-	Token string `json:"token"`
+	User         *User  `json:"user"`
+	Token        string `json:"token"`         // Access token
+	RefreshToken string `json:"refresh_token"` // Sadece /auth/refresh'te kullanılır
+	ExpiresIn    int64  `json:"expires_in"`    // Access token süresi (saniye)
 }
 
 // RefreshResponse token refresh yanıtı
@@ -53,6 +62,43 @@ type UpdateUserRequest struct {
 	Email    *string `json:"email,omitempty"`    // nil = değiştirilmeyecek
 	Password *string `json:"password,omitempty"` // empty string ≠ nil
 	Role     *string `json:"role,omitempty"`     // YENİ: Role güncelleme
+
+	ExpectedVersion *int `json:"-"` // If-Match ile gelen versiyon; nil = koşulsuz güncelleme
+}
+
+// ErrUserVersionConflict kullanıcı okunduktan sonra başka bir istek tarafından güncellendiğinde döner
+var ErrUserVersionConflict = errors.New("kullanıcı başka bir istek tarafından güncellenmiş")
+
+// ErrEmailAlreadyInUse email başka bir aktif kullanıcıda kayıtlıysa döner
+var ErrEmailAlreadyInUse = errors.New("bu email zaten kullanılıyor")
+
+// UserETag kullanıcı versiyonundan ETag üretir (ör. "\"3\"")
+func UserETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseUserETag If-Match header'ındaki ETag'den versiyonu çözer ("3", W/"3" veya 3 kabul edilir)
+func ParseUserETag(etag string) (int, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	value = strings.Trim(value, `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("geçersiz ETag: %s", etag)
+	}
+	return version, nil
+}
+
+// CloseAccountRequest hesap kapatma isteği
+type CloseAccountRequest struct {
+	TransferToUserID *int `json:"transfer_to_user_id,omitempty"` // Kalan bakiyenin aktarılacağı hesap (bakiye sıfırsa opsiyonel)
+}
+
+// CloseAccountResult hesap kapatma sonucu
+type CloseAccountResult struct {
+	UserID            int     `json:"user_id"`
+	TransferredAmount float64 `json:"transferred_amount"`
+	TransferToUserID  *int    `json:"transfer_to_user_id,omitempty"`
+	TransactionID     *int    `json:"transaction_id,omitempty"`
 }
 
 // ========== USER VALIDATION METHODS ==========
@@ -125,33 +171,26 @@ func (u *User) ValidateEmail() error {
 
 // ValidateRole kullanıcı rolünü doğrular
 func (u *User) ValidateRole() error {
-	// Geçerli roller
-	validRoles := map[string]bool{
-		"user":  true,
-		"admin": true,
-		"mod":   true, // moderator
-	}
-
 	// Boşsa default role ver
 	if u.Role == "" {
-		u.Role = "user"
+		u.Role = RoleUser
 		return nil
 	}
 
 	// Role kontrolü
-	if !validRoles[strings.ToLower(u.Role)] {
-		return fmt.Errorf("geçersiz rol: %s. Geçerli roller: user, admin, mod", u.Role)
+	if !IsKnownRole(u.Role) {
+		return fmt.Errorf("geçersiz rol: %s. Geçerli roller: %s", u.Role, strings.Join(KnownRoles(), ", "))
 	}
 
 	// Küçük harfe çevir (normalize)
-	u.Role = strings.ToLower(u.Role)
+	u.Role = NormalizeRole(u.Role)
 
 	return nil
 }
 
 // HasRole belirli bir role sahip mi kontrol eder
 func (u *User) HasRole(role string) bool {
-	return strings.EqualFold(u.Role, role)
+	return NormalizeRole(u.Role) == NormalizeRole(role)
 }
 
 // IsAdmin admin rolünde mi kontrol eder
@@ -295,26 +334,19 @@ func (req *CreateUserRequest) ValidatePassword() error {
 
 // ValidateRole CreateUserRequest role'ünü doğrular
 func (req *CreateUserRequest) ValidateRole() error {
-	// Geçerli roller
-	validRoles := map[string]bool{
-		"user":  true,
-		"admin": true,
-		"mod":   true,
-	}
-
 	// Boşsa default role ver
 	if req.Role == "" {
-		req.Role = "user"
+		req.Role = RoleUser
 		return nil
 	}
 
 	// Role kontrolü
-	if !validRoles[strings.ToLower(req.Role)] {
-		return fmt.Errorf("geçersiz rol: %s. Geçerli roller: user, admin, mod", req.Role)
+	if !IsKnownRole(req.Role) {
+		return fmt.Errorf("geçersiz rol: %s. Geçerli roller: %s", req.Role, strings.Join(KnownRoles(), ", "))
 	}
 
 	// Küçük harfe çevir
-	req.Role = strings.ToLower(req.Role)
+	req.Role = NormalizeRole(req.Role)
 
 	return nil
 }
@@ -425,17 +457,22 @@ func (req *UpdateUserRequest) Validate() error {
 
 	// Role kontrol
 	if req.Role != nil {
-		validRoles := map[string]bool{
-			"user":  true,
-			"admin": true,
-			"mod":   true,
-		}
-		if !validRoles[strings.ToLower(*req.Role)] {
+		if !IsKnownRole(*req.Role) {
 			return fmt.Errorf("geçersiz rol: %s", *req.Role)
 		}
 		// Normalize
-		*req.Role = strings.ToLower(*req.Role)
+		*req.Role = NormalizeRole(*req.Role)
 	}
 
 	return nil
 }
+
+// ========== CLOSE ACCOUNT REQUEST VALIDATION ==========
+
+// Validate CloseAccountRequest'i doğrular
+func (req *CloseAccountRequest) Validate() error {
+	if req.TransferToUserID != nil && *req.TransferToUserID <= 0 {
+		return fmt.Errorf("geçersiz aktarım hesabı ID")
+	}
+	return nil
+}
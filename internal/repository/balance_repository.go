@@ -3,12 +3,17 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/onerilhan/go-payment-api/internal/db"
 	"github.com/onerilhan/go-payment-api/internal/interfaces"
 	"github.com/onerilhan/go-payment-api/internal/models"
 )
 
+// adjustmentReferenceIndex düzeltme referanslarının tekrar kullanılmasını engelleyen partial unique index adı
+const adjustmentReferenceIndex = "idx_balance_history_reason_reference"
+
 // BalanceRepository balance database işlemleri
 type BalanceRepository struct {
 	db *sql.DB
@@ -19,10 +24,11 @@ func NewBalanceRepository(db *sql.DB) interfaces.BalanceRepositoryInterface {
 	return &BalanceRepository{db: db}
 }
 
+// CreateBalanceSnapshot kullanıcının şu anki bakiyesi için tek bir snapshot kaydeder
 func (r *BalanceRepository) CreateBalanceSnapshot(userID int, amount float64, reason string) error {
 	query := `
-		INSERT INTO balance_history (user_id, amount, reason)
-		VALUES ($1, $2, $3)
+		INSERT INTO balance_snapshots (user_id, amount, reason, taken_at)
+		VALUES ($1, $2, $3, NOW())
 	`
 	_, err := r.db.Exec(query, userID, amount, reason)
 	if err != nil {
@@ -80,15 +86,23 @@ func (r *BalanceRepository) CreateBalance(userID int) (*models.Balance, error) {
 	return &balance, nil
 }
 
-// UpdateBalance kullanıcının bakiyesini günceller
+// UpdateBalance kullanıcının bakiyesini verilen tutara ayarlar ve değişikliği aynı statement'ta balance_history'ye yazar.
+// Tutar Go'da hesaplanıyorsa satır FOR UPDATE ile kilitlenmeden kullanılmamalı; göreli değişiklik için AdjustBalance kullanılır.
 func (r *BalanceRepository) UpdateBalance(userID int, newAmount float64) error {
 	query := `
-		UPDATE balances 
-		SET amount = $1
-		WHERE user_id = $2
+		WITH updated AS (
+			UPDATE balances b
+			SET amount = $1
+			FROM (SELECT amount FROM balances WHERE user_id = $2 FOR UPDATE) previous
+			WHERE b.user_id = $2
+			RETURNING b.user_id, previous.amount AS previous_amount, b.amount
+		)
+		INSERT INTO balance_history (user_id, previous_amount, new_amount, change_amount, reason)
+		SELECT user_id, previous_amount, amount, amount - previous_amount, $3
+		FROM updated
 	`
 
-	_, err := r.db.Exec(query, newAmount, userID)
+	_, err := r.db.Exec(query, newAmount, userID, models.BalanceReasonAdjustment)
 	if err != nil {
 		return fmt.Errorf("bakiye güncellenemedi: %w", err)
 	}
@@ -96,13 +110,142 @@ func (r *BalanceRepository) UpdateBalance(userID int, newAmount float64) error {
 	return nil
 }
 
-// GetBalanceHistory kullanıcının bakiye geçmişini getirir
-func (r *BalanceRepository) GetBalanceHistory(userID int, limit, offset int) ([]*models.BalanceHistory, error) {
+// AdjustBalance bakiyeyi tek bir UPDATE ile delta kadar değiştirir ve yeni bakiyeyi döner.
+// Tutar Go'da hesaplanıp yazılmadığı için eşzamanlı çağrılar birbirinin üzerine yazmaz (lock gerekmez).
+// Bakiyeyi negatife düşürecek değişiklik uygulanmaz. Değişiklik aynı statement'ta balance_history'ye yazılır.
+func (r *BalanceRepository) AdjustBalance(userID int, delta float64) (float64, error) {
+	query := `
+		WITH updated AS (
+			UPDATE balances
+			SET amount = amount + $1
+			WHERE user_id = $2 AND amount + $1 >= 0
+			RETURNING user_id, amount
+		), history AS (
+			INSERT INTO balance_history (user_id, previous_amount, new_amount, change_amount, reason)
+			SELECT user_id, amount - $1, amount, $1, $3
+			FROM updated
+		)
+		SELECT amount FROM updated
+	`
+
+	var newAmount float64
+	err := r.db.QueryRow(query, delta, userID, models.BalanceReasonAdjustment).Scan(&newAmount)
+	if err == nil {
+		return newAmount, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("bakiye güncellenemedi: %w", err)
+	}
+
+	return 0, r.adjustmentRejected(userID)
+}
+
+// AdjustBalanceWithReference bakiyeyi AdjustBalance gibi değiştirir ve düzeltmeyi referansıyla balance_history'ye yazar.
+// Referans daha önce kullanıldıysa bakiye değişmez ve ilk düzeltme kaydı applied=false ile döner; referans farklı
+// kullanıcı veya tutarla kullanılmışsa ErrAdjustmentReferenceConflict döner. Eşzamanlı aynı referanslı isteklerden
+// yalnızca biri unique index'i geçer, diğerinin statement'ı (bakiye güncellemesi dahil) bütünüyle geri alınır.
+func (r *BalanceRepository) AdjustBalanceWithReference(userID int, delta float64, reference string) (*models.BalanceHistory, bool, error) {
+	query := `
+		WITH existing AS (
+			SELECT 1 FROM balance_history WHERE reason = $3 AND reference = $4
+			UNION ALL
+			SELECT 1 FROM balance_history_archive WHERE reason = $3 AND reference = $4
+		), updated AS (
+			UPDATE balances
+			SET amount = amount + $1
+			WHERE user_id = $2 AND amount + $1 >= 0 AND NOT EXISTS (SELECT 1 FROM existing)
+			RETURNING user_id, amount
+		)
+		INSERT INTO balance_history (user_id, previous_amount, new_amount, change_amount, reason, reference)
+		SELECT user_id, amount - $1, amount, $1, $3, $4
+		FROM updated
+		RETURNING id, user_id, previous_amount, new_amount, change_amount, reason, transaction_id, reference, created_at
+	`
+
+	adjustment, err := scanAdjustment(r.db.QueryRow(query, delta, userID, models.BalanceReasonAdjustment, reference))
+	if err == nil {
+		return adjustment, true, nil
+	}
+	if err != sql.ErrNoRows && !db.IsUniqueViolation(err, adjustmentReferenceIndex) {
+		return nil, false, fmt.Errorf("bakiye güncellenemedi: %w", err)
+	}
+
+	// Uygulanmadıysa ya referans daha önce kullanılmıştır ya da bakiye kaydı yok/yetersizdir
+	original, err := r.findAdjustmentByReference(reference)
+	if err != nil {
+		return nil, false, err
+	}
+	if original == nil {
+		return nil, false, r.adjustmentRejected(userID)
+	}
+	if original.UserID != userID || math.Abs(original.ChangeAmount-delta) >= 0.005 {
+		return nil, false, models.ErrAdjustmentReferenceConflict
+	}
+	return original, false, nil
+}
+
+// findAdjustmentByReference referansla yazılmış düzeltme kaydını (arşivlenmiş olsa da) döner; yoksa nil
+func (r *BalanceRepository) findAdjustmentByReference(reference string) (*models.BalanceHistory, error) {
+	query := `
+		SELECT id, user_id, previous_amount, new_amount, change_amount, reason, transaction_id, reference, created_at
+		FROM balance_history
+		WHERE reason = $1 AND reference = $2
+		UNION ALL
+		SELECT id, user_id, previous_amount, new_amount, change_amount, reason, transaction_id, reference, created_at
+		FROM balance_history_archive
+		WHERE reason = $1 AND reference = $2
+		LIMIT 1
+	`
+
+	adjustment, err := scanAdjustment(r.db.QueryRow(query, models.BalanceReasonAdjustment, reference))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bakiye düzeltmesi arama hatası: %w", err)
+	}
+	return adjustment, nil
+}
+
+// scanAdjustment referanslı balance_history satırını okur
+func scanAdjustment(row *sql.Row) (*models.BalanceHistory, error) {
+	var adjustment models.BalanceHistory
+	err := row.Scan(
+		&adjustment.ID,
+		&adjustment.UserID,
+		&adjustment.PreviousAmount,
+		&adjustment.NewAmount,
+		&adjustment.ChangeAmount,
+		&adjustment.Reason,
+		&adjustment.TransactionID,
+		&adjustment.Reference,
+		&adjustment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// adjustmentRejected uygulanmayan düzeltmenin sebebini döner: bakiye kaydı yok ya da bakiye negatife düşerdi
+func (r *BalanceRepository) adjustmentRejected(userID int) error {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM balances WHERE user_id = $1)`, userID).Scan(&exists); err != nil {
+		return fmt.Errorf("bakiye arama hatası: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("bakiye bulunamadı")
+	}
+	return models.ErrBalanceWouldBeNegative
+}
+
+// GetBalanceHistory kullanıcının bakiye geçmişini verilen yönde (asc|desc) getirir
+func (r *BalanceRepository) GetBalanceHistory(userID int, limit, offset int, sort string) ([]*models.BalanceHistory, error) {
 	query := `
 		SELECT id, user_id, previous_amount, new_amount, change_amount, reason, transaction_id, created_at
 		FROM balance_history 
 		WHERE user_id = $1
-		ORDER BY created_at DESC
+		ORDER BY ` + createdAtOrderBy(sort) + `
 		LIMIT $2 OFFSET $3
 	`
 
@@ -134,36 +277,261 @@ func (r *BalanceRepository) GetBalanceHistory(userID int, limit, offset int) ([]
 	return history, nil
 }
 
-// GetBalanceAtTime belirli bir tarihte kullanıcının bakiyesini hesaplar
+// CreateBalanceSnapshots asOf anındaki bakiyeleri snapshot olarak yazar; yazılan snapshot sayısını döner.
+// Tutar güncel bakiyeden asOf sonrası değişimler çıkarılarak bulunur, böylece geçmişi olmayan başlangıç bakiyeleri de dahildir.
+// Son snapshot'ından sonra bakiye değişikliği olmayan kullanıcılar için yeni snapshot yazılmaz.
+func (r *BalanceRepository) CreateBalanceSnapshots(asOf time.Time, reason string) (int, error) {
+	query := `
+		INSERT INTO balance_snapshots (user_id, amount, reason, taken_at)
+		SELECT b.user_id, b.amount - COALESCE(SUM(h.change_amount), 0), $2, $1
+		FROM balances b
+		LEFT JOIN balance_history h ON h.user_id = b.user_id AND h.created_at > $1 AND h.reason <> $3
+		WHERE NOT EXISTS (
+			SELECT 1 FROM balance_snapshots s
+			WHERE s.user_id = b.user_id
+				AND s.taken_at >= COALESCE(
+					(SELECT MAX(created_at) FROM balance_history WHERE user_id = b.user_id AND created_at <= $1),
+					'-infinity'::timestamp
+				)
+		)
+		GROUP BY b.user_id, b.amount
+	`
+
+	result, err := r.db.Exec(query, asOf, reason, models.BalanceReasonArchiveBaseline)
+	if err != nil {
+		return 0, fmt.Errorf("bakiye snapshot'ları oluşturulamadı: %w", err)
+	}
+
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("bakiye snapshot'ları oluşturulamadı: %w", err)
+	}
+	return int(written), nil
+}
+
+// GetLedgerBalances user_id sırasıyla afterUserID'den sonraki en fazla limit bakiyeyi, balance_history'deki
+// değişimlerin toplamıyla (ledger) birlikte döner. Arşivlenen satırlar baseline kaydıyla temsil edildiğinden
+// arşiv tablosu okunmaz. Geçmişten önce oluşmuş bakiyeler açılış kaydıyla (BalanceReasonOpeningBalance) temsil
+// edildiğinden toplam kayıtlı bakiyeye eşit olmalıdır.
+func (r *BalanceRepository) GetLedgerBalances(afterUserID, limit int) ([]*models.LedgerBalance, error) {
+	query := `
+		WITH batch AS (
+			SELECT user_id, amount
+			FROM balances
+			WHERE user_id > $1
+			ORDER BY user_id
+			LIMIT $2
+		)
+		SELECT b.user_id, b.amount, COALESCE(SUM(h.change_amount), 0)
+		FROM batch b
+		LEFT JOIN balance_history h ON h.user_id = b.user_id
+		GROUP BY b.user_id, b.amount
+		ORDER BY b.user_id
+	`
+
+	rows, err := r.db.Query(query, afterUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ledger bakiyeleri sorgusu hatası: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*models.LedgerBalance
+	for rows.Next() {
+		var balance models.LedgerBalance
+		if err := rows.Scan(&balance.UserID, &balance.Amount, &balance.LedgerAmount); err != nil {
+			return nil, fmt.Errorf("ledger bakiyesi scan hatası: %w", err)
+		}
+		balances = append(balances, &balance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ledger bakiyeleri okunamadı: %w", err)
+	}
+
+	return balances, nil
+}
+
+// GetBalanceAtTime belirli bir tarihte kullanıcının bakiyesini hesaplar.
+// Hedef zamandan önce snapshot varsa ondan başlanıp sonraki değişimler eklenir; yoksa tüm geçmiş toplanır.
 func (r *BalanceRepository) GetBalanceAtTime(userID int, targetTime time.Time) (*models.BalanceAtTime, error) {
+	var snapshotAmount float64
+	var snapshotAt time.Time
+	err := r.db.QueryRow(`
+		SELECT amount, taken_at
+		FROM balance_snapshots
+		WHERE user_id = $1 AND taken_at <= $2
+		ORDER BY taken_at DESC
+		LIMIT 1
+	`, userID, targetTime).Scan(&snapshotAmount, &snapshotAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return r.sumBalanceAtTime(userID, targetTime)
+	case err != nil:
+		return nil, fmt.Errorf("bakiye snapshot'ı alınamadı: %w", err)
+	}
+
+	// Snapshot sonrası değişimler; arşivlenmiş satırlar arşiv tablosundan okunur, baseline'lar (arşiv toplamı) atlanır
+	query := `
+		SELECT COALESCE(SUM(change_amount), 0) as total_change
+		FROM (
+			SELECT change_amount
+			FROM balance_history
+			WHERE user_id = $1 AND created_at > $2 AND created_at <= $3 AND reason <> $4
+			UNION ALL
+			SELECT change_amount
+			FROM balance_history_archive
+			WHERE user_id = $1 AND created_at > $2 AND created_at <= $3
+		) changes
+	`
+
+	var totalChange float64
+	if err := r.db.QueryRow(query, userID, snapshotAt, targetTime, models.BalanceReasonArchiveBaseline).Scan(&totalChange); err != nil {
+		return nil, fmt.Errorf("bakiye hesaplama hatası: %w", err)
+	}
+
+	result := newBalanceAtTime(userID, snapshotAmount+totalChange, targetTime)
+	result.SnapshotAt = &snapshotAt
+	return result, nil
+}
+
+// sumBalanceAtTime snapshot yokken bakiyeyi sıfırdan başlayıp hedef zamana kadarki tüm değişimleri toplayarak hesaplar
+func (r *BalanceRepository) sumBalanceAtTime(userID int, targetTime time.Time) (*models.BalanceAtTime, error) {
 	// O tarihe kadar olan tüm balance değişikliklerini topla
+	// Arşivlenen satırlar balance_history'de baseline kaydı ile temsil edilir.
+	// Hedef zamandan önce baseline varsa arşiv zaten onun içinde; yoksa arşivdeki satırlar da toplanır.
 	query := `
 		SELECT COALESCE(SUM(change_amount), 0) as total_change
-		FROM balance_history 
-		WHERE user_id = $1 AND created_at <= $2
+		FROM (
+			SELECT change_amount
+			FROM balance_history 
+			WHERE user_id = $1 AND created_at <= $2
+			UNION ALL
+			SELECT change_amount
+			FROM balance_history_archive
+			WHERE user_id = $1 AND created_at <= $2
+				AND NOT EXISTS (
+					SELECT 1 FROM balance_history
+					WHERE user_id = $1 AND reason = $3 AND created_at <= $2
+				)
+		) changes
 	`
 
 	var totalChange float64
-	err := r.db.QueryRow(query, userID, targetTime).Scan(&totalChange)
+	err := r.db.QueryRow(query, userID, targetTime, models.BalanceReasonArchiveBaseline).Scan(&totalChange)
 	if err != nil {
 		return nil, fmt.Errorf("bakiye hesaplama hatası: %w", err)
 	}
 
-	// Kullanıcının ilk bakiyesi genelde 0, sonra change_amount'ları topla
-	// Not: Eğer başlangıç bakiyesi 0 değilse, bu query'i güncelle
-	finalAmount := totalChange
+	// Snapshot yoksa başlangıç bakiyesi 0 kabul edilir (geçmişi olmayan başlangıç bakiyesi sadece snapshot ile doğru hesaplanır)
+	return newBalanceAtTime(userID, totalChange, targetTime), nil
+}
 
+// newBalanceAtTime hesaplanan tutardan yanıt oluşturur
+func newBalanceAtTime(userID int, amount float64, targetTime time.Time) *models.BalanceAtTime {
 	// Negatif bakiye olmasın
-	if finalAmount < 0 {
-		finalAmount = 0
+	if amount < 0 {
+		amount = 0
 	}
 
-	result := &models.BalanceAtTime{
+	return &models.BalanceAtTime{
 		UserID:  userID,
-		Amount:  finalAmount,
+		Amount:  amount,
 		AtTime:  targetTime.Format("2006-01-02T15:04:05Z"),
 		Message: fmt.Sprintf("Bakiye %s tarihinde hesaplandı", targetTime.Format("2006-01-02 15:04:05")),
 	}
+}
+
+// GetPendingOutgoingAmount kullanıcının pending (veya fraud incelemesindeki) giden işlemlerinin toplamını döner
+func (r *BalanceRepository) GetPendingOutgoingAmount(userID int) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE from_user_id = $1 AND status IN ($2, $3)
+	`
+
+	var amount float64
+	if err := r.db.QueryRow(query, userID, models.StatusPending, models.StatusPendingReview).Scan(&amount); err != nil {
+		return 0, fmt.Errorf("pending işlem toplamı alınamadı: %w", err)
+	}
+
+	return amount, nil
+}
+
+// GetActiveHoldAmount kullanıcının aktif (capture/void edilmemiş) hold'larının toplamını döner
+func (r *BalanceRepository) GetActiveHoldAmount(userID int) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM balance_holds
+		WHERE user_id = $1 AND status = $2
+	`
+
+	var amount float64
+	if err := r.db.QueryRow(query, userID, models.HoldStatusActive).Scan(&amount); err != nil {
+		return 0, fmt.Errorf("aktif hold toplamı alınamadı: %w", err)
+	}
+
+	return amount, nil
+}
+
+// GetMinimumBalance kullanıcının belirlediği minimum bakiyeyi döner (bakiye kaydı yoksa 0)
+func (r *BalanceRepository) GetMinimumBalance(userID int) (float64, error) {
+	var minimum float64
+	err := r.db.QueryRow(`SELECT minimum_balance FROM balances WHERE user_id = $1`, userID).Scan(&minimum)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("minimum bakiye alınamadı: %w", err)
+	}
+
+	return minimum, nil
+}
+
+// SetMinimumBalance kullanıcının minimum bakiyesini kaydeder (bakiye kaydı yoksa sıfır bakiyeyle oluşturulur)
+func (r *BalanceRepository) SetMinimumBalance(userID int, minimum float64) error {
+	query := `
+		INSERT INTO balances (user_id, amount, minimum_balance)
+		VALUES ($1, 0.00, $2)
+		ON CONFLICT (user_id) DO UPDATE SET minimum_balance = EXCLUDED.minimum_balance
+	`
+
+	if _, err := r.db.Exec(query, userID, minimum); err != nil {
+		return fmt.Errorf("minimum bakiye güncellenemedi: %w", err)
+	}
+
+	return nil
+}
+
+// ArchiveBalanceHistory belirtilen andan eski balance_history satırlarını arşiv tablosuna taşır.
+// Her kullanıcı için taşınan değişimlerin toplamı tek bir baseline kaydı olarak bırakılır,
+// böylece point-in-time sorguları (SUM(change_amount)) arşivlemeden sonra da doğru kalır.
+// Önceki baseline'lar arşive kopyalanmaz (orijinal satırlar zaten arşivde), yeni baseline'a dahil edilir.
+func (r *BalanceRepository) ArchiveBalanceHistory(before time.Time) (*models.BalanceArchiveResult, error) {
+	query := `
+		WITH moved AS (
+			DELETE FROM balance_history
+			WHERE created_at < $1
+			RETURNING id, user_id, previous_amount, new_amount, change_amount, reason, transaction_id, reference, created_at
+		), archived AS (
+			INSERT INTO balance_history_archive (id, user_id, previous_amount, new_amount, change_amount, reason, transaction_id, reference, created_at)
+			SELECT id, user_id, previous_amount, new_amount, change_amount, reason, transaction_id, reference, created_at
+			FROM moved
+			WHERE reason <> $2
+			RETURNING 1
+		), baselines AS (
+			INSERT INTO balance_history (user_id, previous_amount, new_amount, change_amount, reason, created_at)
+			SELECT user_id, 0, SUM(change_amount), SUM(change_amount), $2, MAX(created_at)
+			FROM moved
+			GROUP BY user_id
+			RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM archived), (SELECT COUNT(*) FROM baselines)
+	`
+
+	result := &models.BalanceArchiveResult{Before: before}
+	err := r.db.QueryRow(query, before, models.BalanceReasonArchiveBaseline).Scan(&result.ArchivedRows, &result.Baselines)
+	if err != nil {
+		return nil, fmt.Errorf("bakiye geçmişi arşivlenemedi: %w", err)
+	}
 
 	return result, nil
 }
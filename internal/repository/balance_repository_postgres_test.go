@@ -0,0 +1,119 @@
+//go:build postgres
+
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/config"
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// newTestPostgresDB DB_* ortam değişkenlerindeki (varsayılanlar config.LoadConfig'teki gibi) migrate edilmiş
+// PostgreSQL'e bağlanır. Bu dosya sadece `go test -tags postgres ./...` ile derlenir.
+func newTestPostgresDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	database, err := db.Connect(config.LoadConfig().GetDSN(), false)
+	require.NoError(t, err, "PostgreSQL'e bağlanılamadı")
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// createTestUserWithBalance bakiyesi verilen tutarda olan geçici bir kullanıcı oluşturur (test sonunda silinir)
+func createTestUserWithBalance(t *testing.T, database *sql.DB, amount float64) int {
+	t.Helper()
+
+	user, err := NewUserRepository(database).Create(&models.CreateUserRequest{
+		Name:     "Adjust Test",
+		Email:    fmt.Sprintf("adjust-test-%d@example.com", time.Now().UnixNano()),
+		Password: "not-a-real-hash",
+		Role:     "user",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+	balanceRepo := NewBalanceRepository(database)
+	_, err = balanceRepo.CreateBalance(user.ID)
+	require.NoError(t, err)
+	_, err = balanceRepo.AdjustBalance(user.ID, amount)
+	require.NoError(t, err)
+	return user.ID
+}
+
+// TestBalanceRepository_AdjustBalance_ConcurrentMixedDeltas, aynı bakiyeye eşzamanlı artı/eksi düzeltmelerde
+// hiçbir güncellemenin kaybolmadığını (son bakiye = başlangıç + uygulanan deltalar), bakiyenin hiçbir anda negatife
+// düşmediğini ve reddedilen düzeltmelerin ErrBalanceWouldBeNegative döndüğünü test eder.
+func TestBalanceRepository_AdjustBalance_ConcurrentMixedDeltas(t *testing.T) {
+	// Arrange
+	database := newTestPostgresDB(t)
+	repo := NewBalanceRepository(database)
+
+	const initial = 100.0
+	userID := createTestUserWithBalance(t, database, initial)
+
+	// 25 x +10 ve 25 x -30: net -500, bakiye yetmediği için eksilerin bir kısmı reddedilmek zorunda
+	var deltas []float64
+	for i := 0; i < 25; i++ {
+		deltas = append(deltas, 10, -30)
+	}
+
+	type outcome struct {
+		delta     float64
+		newAmount float64
+		err       error
+	}
+	outcomes := make([]outcome, len(deltas))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Act
+	for i, delta := range deltas {
+		wg.Add(1)
+		go func(i int, delta float64) {
+			defer wg.Done()
+			<-start
+			newAmount, err := repo.AdjustBalance(userID, delta)
+			outcomes[i] = outcome{delta: delta, newAmount: newAmount, err: err}
+		}(i, delta)
+	}
+	close(start)
+	wg.Wait()
+
+	balance, err := repo.GetByUserID(userID)
+	require.NoError(t, err)
+
+	var historySum float64
+	err = database.QueryRow(`
+		SELECT COALESCE(SUM(change_amount), 0) FROM balance_history WHERE user_id = $1 AND reason = $2
+	`, userID, models.BalanceReasonAdjustment).Scan(&historySum)
+	require.NoError(t, err)
+
+	// Assert
+	applied := initial
+	rejected := 0
+	for _, o := range outcomes {
+		if o.err != nil {
+			assert.True(t, errors.Is(o.err, models.ErrBalanceWouldBeNegative), "beklenmeyen hata: %v", o.err)
+			assert.Negative(t, o.delta, "artı düzeltme reddedilmemeli")
+			rejected++
+			continue
+		}
+		assert.GreaterOrEqual(t, o.newAmount, 0.0)
+		applied += o.delta
+	}
+
+	assert.Positive(t, rejected)
+	assert.InDelta(t, applied, balance.Amount, 0.001)
+	assert.GreaterOrEqual(t, balance.Amount, 0.0)
+	assert.InDelta(t, applied, historySum, 0.001) // başlangıç bakiyesi de adjustment olarak yazıldı
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestBalanceRepository_ArchiveBalanceHistory_ReturnsCounts, arşivlenen satır ve baseline sayılarının döndüğünü test eder.
+func TestBalanceRepository_ArchiveBalanceHistory_ReturnsCounts(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBalanceRepository(db)
+	before := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("WITH moved AS \\(\\s*DELETE FROM balance_history").
+		WithArgs(before, models.BalanceReasonArchiveBaseline).
+		WillReturnRows(sqlmock.NewRows([]string{"archived", "baselines"}).AddRow(42, 3))
+
+	// Act
+	result, err := repo.ArchiveBalanceHistory(before)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &models.BalanceArchiveResult{ArchivedRows: 42, Baselines: 3, Before: before}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBalanceRepository_GetBalanceAtTime_IncludesArchiveFallback, sorgunun baseline yoksa arşivi de topladığını test eder.
+func TestBalanceRepository_GetBalanceAtTime_IncludesArchiveFallback(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBalanceRepository(db)
+	target := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Hedef zamandan önce snapshot yok
+	mock.ExpectQuery("FROM balance_snapshots").
+		WithArgs(10, target).
+		WillReturnRows(sqlmock.NewRows([]string{"amount", "taken_at"}))
+	mock.ExpectQuery("FROM balance_history_archive").
+		WithArgs(10, target, models.BalanceReasonArchiveBaseline).
+		WillReturnRows(sqlmock.NewRows([]string{"total_change"}).AddRow(250.0))
+
+	// Act
+	result, err := repo.GetBalanceAtTime(10, target)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 250.0, result.Amount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBalanceRepository_GetBalanceAtTime_SnapshotMatchesFullSum, snapshot + sonraki değişimlerle hesaplanan bakiyenin
+// başlangıç bakiyesi dahil tüm geçmişin toplamıyla aynı olduğunu test eder.
+func TestBalanceRepository_GetBalanceAtTime_SnapshotMatchesFullSum(t *testing.T) {
+	// Arrange
+	base := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	const openingBalance = 500.0 // balance_history'de karşılığı olmayan başlangıç bakiyesi
+	changes := []struct {
+		at     time.Time
+		amount float64
+	}{
+		{base.Add(1 * time.Hour), 100},
+		{base.Add(2 * time.Hour), -40.5},
+		{base.Add(3 * time.Hour), 250},
+		{base.Add(5 * time.Hour), -75.25},
+		{base.Add(8 * time.Hour), 19.75},
+	}
+	sumChanges := func(after, until time.Time) float64 {
+		total := 0.0
+		for _, change := range changes {
+			if change.at.After(after) && !change.at.After(until) {
+				total += change.amount
+			}
+		}
+		return total
+	}
+	fullSum := func(until time.Time) float64 {
+		return openingBalance + sumChanges(time.Time{}, until)
+	}
+
+	// Snapshot job'ının yazdığı tutar: güncel bakiye - snapshot sonrası değişimler
+	snapshotAt := base.Add(4 * time.Hour)
+	current := fullSum(base.Add(24 * time.Hour))
+	snapshotAmount := current - sumChanges(snapshotAt, base.Add(24*time.Hour))
+
+	for _, target := range []time.Time{snapshotAt, base.Add(5 * time.Hour), base.Add(6 * time.Hour), base.Add(12 * time.Hour)} {
+		t.Run(target.Format(time.Kitchen), func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewBalanceRepository(db)
+
+			mock.ExpectQuery("FROM balance_snapshots").
+				WithArgs(10, target).
+				WillReturnRows(sqlmock.NewRows([]string{"amount", "taken_at"}).AddRow(snapshotAmount, snapshotAt))
+			mock.ExpectQuery("created_at > \\$2 AND created_at <= \\$3").
+				WithArgs(10, snapshotAt, target, models.BalanceReasonArchiveBaseline).
+				WillReturnRows(sqlmock.NewRows([]string{"total_change"}).AddRow(sumChanges(snapshotAt, target)))
+
+			// Act
+			result, err := repo.GetBalanceAtTime(10, target)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.InDelta(t, fullSum(target), result.Amount, 1e-9)
+			assert.Equal(t, snapshotAt, *result.SnapshotAt)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestBalanceRepository_GetBalanceHistory_SortOrder, bakiye geçmişinin asc ve desc sıralama ile sorgulandığını test eder.
+func TestBalanceRepository_GetBalanceHistory_SortOrder(t *testing.T) {
+	cases := map[string]string{
+		models.SortAsc:  "ORDER BY created_at ASC, id ASC",
+		models.SortDesc: "ORDER BY created_at DESC, id DESC",
+	}
+
+	for sort, orderBy := range cases {
+		t.Run(sort, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+				func(_, actual string) error {
+					if !strings.Contains(actual, orderBy) {
+						return fmt.Errorf("sorgu %q içermiyor: %s", orderBy, actual)
+					}
+					return nil
+				})))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewBalanceRepository(db)
+			mock.ExpectQuery("").
+				WithArgs(10, 20, 0).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "previous_amount", "new_amount", "change_amount", "reason", "transaction_id", "created_at"}).
+					AddRow(1, 10, 0.0, 100.0, 100.0, "credit", nil, time.Now()))
+
+			// Act
+			history, err := repo.GetBalanceHistory(10, 20, 0, sort)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Len(t, history, 1)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestBalanceRepository_AdjustBalance_SingleGuardedStatement, değişikliğin okuma yapılmadan tek bir
+// "amount = amount + delta" UPDATE'i ile (geçmiş kaydı aynı statement'ta) uygulandığını ve bakiyeyi negatife
+// düşürecek delta'nın aynı statement'taki koşul tarafından reddedildiğini test eder.
+func TestBalanceRepository_AdjustBalance_SingleGuardedStatement(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBalanceRepository(db)
+	adjustQuery := "^\\s*WITH updated AS \\(\\s*UPDATE balances\\s+SET amount = amount \\+ \\$1\\s+WHERE user_id = \\$2 AND amount \\+ \\$1 >= 0\\s+" +
+		"RETURNING user_id, amount\\s*\\), history AS \\(\\s*INSERT INTO balance_history .+\\)\\s*SELECT amount FROM updated\\s*$"
+
+	// Mutlak tutar değil delta gönderilir; yeni bakiye RETURNING ile aynı statement'tan okunur
+	mock.ExpectQuery(adjustQuery).WithArgs(10.0, 7, models.BalanceReasonAdjustment).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(110.0))
+	// Negatife düşürecek delta: koşul satırı eşlemez, ardından yalnızca red sebebi okunur
+	mock.ExpectQuery(adjustQuery).WithArgs(-500.0, 7, models.BalanceReasonAdjustment).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Act
+	amount, adjustErr := repo.AdjustBalance(7, 10)
+	_, negativeErr := repo.AdjustBalance(7, -500)
+
+	// Assert
+	assert.NoError(t, adjustErr)
+	assert.Equal(t, 110.0, amount)
+	assert.ErrorIs(t, negativeErr, models.ErrBalanceWouldBeNegative)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBalanceRepository_AdjustBalance_RejectsNegative, bakiyeyi negatife düşürecek değişikliğin uygulanmadığını,
+// bakiye kaydı olmayan kullanıcının ise ayrı hata aldığını test eder.
+func TestBalanceRepository_AdjustBalance_RejectsNegative(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBalanceRepository(db)
+
+	mock.ExpectQuery("UPDATE balances").WithArgs(-50.0, 7, models.BalanceReasonAdjustment).WillReturnRows(sqlmock.NewRows([]string{"amount"}))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("UPDATE balances").WithArgs(-50.0, 8, models.BalanceReasonAdjustment).WillReturnRows(sqlmock.NewRows([]string{"amount"}))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(8).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	// Act
+	_, negativeErr := repo.AdjustBalance(7, -50)
+	_, missingErr := repo.AdjustBalance(8, -50)
+
+	// Assert
+	assert.ErrorIs(t, negativeErr, models.ErrBalanceWouldBeNegative)
+	assert.Error(t, missingErr)
+	assert.NotErrorIs(t, missingErr, models.ErrBalanceWouldBeNegative)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBalanceRepository_AdjustBalanceWithReference_AppliedOnce, aynı referansla ikinci kez gönderilen düzeltmenin
+// bakiyeye uygulanmadan ilk düzeltme kaydını döndürdüğünü, referansın başka tutarla kullanılamadığını test eder.
+func TestBalanceRepository_AdjustBalanceWithReference_AppliedOnce(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBalanceRepository(db)
+	columns := []string{"id", "user_id", "previous_amount", "new_amount", "change_amount", "reason", "transaction_id", "reference", "created_at"}
+	createdAt := time.Now()
+	originalRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(columns).AddRow(55, 7, 100.0, 125.0, 25.0, models.BalanceReasonAdjustment, nil, "comp-2024-001", createdAt)
+	}
+
+	// İlk gönderim uygulanır
+	mock.ExpectQuery("INSERT INTO balance_history").
+		WithArgs(25.0, 7, models.BalanceReasonAdjustment, "comp-2024-001").
+		WillReturnRows(originalRow())
+	// Tekrar gönderimde NOT EXISTS nedeniyle satır güncellenmez, ilk kayıt referansla bulunur
+	mock.ExpectQuery("INSERT INTO balance_history").
+		WithArgs(25.0, 7, models.BalanceReasonAdjustment, "comp-2024-001").
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery("FROM balance_history\\s+WHERE reason = \\$1 AND reference = \\$2").
+		WithArgs(models.BalanceReasonAdjustment, "comp-2024-001").
+		WillReturnRows(originalRow())
+	// Aynı referans farklı tutarla
+	mock.ExpectQuery("INSERT INTO balance_history").
+		WithArgs(40.0, 7, models.BalanceReasonAdjustment, "comp-2024-001").
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery("FROM balance_history\\s+WHERE reason = \\$1 AND reference = \\$2").
+		WithArgs(models.BalanceReasonAdjustment, "comp-2024-001").
+		WillReturnRows(originalRow())
+
+	// Act
+	first, firstApplied, firstErr := repo.AdjustBalanceWithReference(7, 25, "comp-2024-001")
+	replay, replayApplied, replayErr := repo.AdjustBalanceWithReference(7, 25, "comp-2024-001")
+	_, _, conflictErr := repo.AdjustBalanceWithReference(7, 40, "comp-2024-001")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.True(t, firstApplied)
+	assert.Equal(t, 125.0, first.NewAmount)
+	assert.Equal(t, "comp-2024-001", *first.Reference)
+
+	assert.NoError(t, replayErr)
+	assert.False(t, replayApplied)
+	assert.Equal(t, first.ID, replay.ID)
+	assert.Equal(t, 125.0, replay.NewAmount)
+
+	assert.ErrorIs(t, conflictErr, models.ErrAdjustmentReferenceConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
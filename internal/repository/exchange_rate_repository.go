@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// ExchangeRateRepository kur tablosu database işlemleri
+type ExchangeRateRepository struct {
+	db *sql.DB
+}
+
+// NewExchangeRateRepository yeni repository oluşturur
+func NewExchangeRateRepository(db *sql.DB) interfaces.ExchangeRateRepositoryInterface {
+	return &ExchangeRateRepository{db: db}
+}
+
+// GetRate iki para birimi arasındaki kuru getirir
+func (r *ExchangeRateRepository) GetRate(baseCurrency, quoteCurrency string) (*models.ExchangeRate, error) {
+	query := `
+		SELECT id, base_currency, quote_currency, rate, updated_at
+		FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2
+	`
+
+	var rate models.ExchangeRate
+	err := r.db.QueryRow(query, baseCurrency, quoteCurrency).Scan(
+		&rate.ID,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("kur bulunamadı: %s/%s", baseCurrency, quoteCurrency)
+		}
+		return nil, fmt.Errorf("kur sorgusu hatası: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// UpsertRate kuru ekler veya günceller
+func (r *ExchangeRateRepository) UpsertRate(baseCurrency, quoteCurrency string, rate float64) error {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (base_currency, quote_currency)
+		DO UPDATE SET rate = EXCLUDED.rate, updated_at = CURRENT_TIMESTAMP
+	`
+
+	if _, err := r.db.Exec(query, baseCurrency, quoteCurrency, rate); err != nil {
+		return fmt.Errorf("kur kaydedilemedi: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// IdempotencyRepository Idempotency-Key kayıtları database işlemleri
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository yeni repository oluşturur
+func NewIdempotencyRepository(db *sql.DB) interfaces.IdempotencyRepositoryInterface {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve key'i in_progress olarak kaydeder; key zaten varsa mevcut kaydı ve false döner.
+// ON CONFLICT DO NOTHING sayesinde eşzamanlı aynı key'li istekler unique-violation hatası almaz.
+func (r *IdempotencyRepository) Reserve(record *models.IdempotencyRecord, expiredBefore time.Time) (*models.IdempotencyRecord, bool, error) {
+	// Saklama süresi dolmuş kayıt key'i bloklamasın
+	_, err := r.db.Exec(`
+		DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND created_at < $3
+	`, record.UserID, record.Key, expiredBefore)
+	if err != nil {
+		return nil, false, fmt.Errorf("süresi dolmuş idempotency key silinemedi: %w", err)
+	}
+
+	reserved := *record
+	reserved.Status = models.IdempotencyStatusInProgress
+	err = r.db.QueryRow(`
+		INSERT INTO idempotency_keys (user_id, idempotency_key, endpoint, request_hash, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, idempotency_key) DO NOTHING
+		RETURNING id, created_at
+	`, record.UserID, record.Key, record.Endpoint, record.RequestHash, reserved.Status).Scan(&reserved.ID, &reserved.CreatedAt)
+	if err == nil {
+		return &reserved, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("idempotency key kaydedilemedi: %w", err)
+	}
+
+	// Key başka bir istek tarafından alınmış: mevcut kaydı dön
+	existing := &models.IdempotencyRecord{}
+	var transactionID sql.NullInt64
+	var newBalance sql.NullFloat64
+	err = r.db.QueryRow(`
+		SELECT id, user_id, idempotency_key, endpoint, request_hash, status, transaction_id, new_balance, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+	`, record.UserID, record.Key).Scan(
+		&existing.ID, &existing.UserID, &existing.Key, &existing.Endpoint, &existing.RequestHash,
+		&existing.Status, &transactionID, &newBalance, &existing.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		// Kayıt bu arada silinmiş (ilk istek başarısız oldu): çağıran tekrar dener
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("idempotency key okunamadı: %w", err)
+	}
+
+	if transactionID.Valid {
+		id := int(transactionID.Int64)
+		existing.TransactionID = &id
+	}
+	existing.NewBalance = newBalance.Float64
+	return existing, false, nil
+}
+
+// Complete kaydı ilk isteğin sonucuyla, işlemi yazan DB transaction'ı içinde tamamlar.
+// Böylece para hareketi commit edilip key in_progress kalamaz; tx geri alınırsa key de tamamlanmamış olur.
+func (r *IdempotencyRepository) Complete(tx *sql.Tx, id int, transactionID int, newBalance float64) error {
+	_, err := tx.Exec(`
+		UPDATE idempotency_keys SET status = $1, transaction_id = $2, new_balance = $3 WHERE id = $4
+	`, models.IdempotencyStatusCompleted, transactionID, newBalance, id)
+	if err != nil {
+		return fmt.Errorf("idempotency key tamamlanamadı: %w", err)
+	}
+	return nil
+}
+
+// Release başarısız isteğin kaydını siler (aynı key ile tekrar denenebilir)
+func (r *IdempotencyRepository) Release(id int) error {
+	if _, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("idempotency key silinemedi: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired verilen andan eski kayıtları siler, silinen sayısını döner
+func (r *IdempotencyRepository) DeleteExpired(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("süresi dolmuş idempotency key'ler silinemedi: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// NotificationPreferenceRepository bildirim tercihleri database işlemleri
+type NotificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationPreferenceRepository yeni repository oluşturur
+func NewNotificationPreferenceRepository(db *sql.DB) interfaces.NotificationPreferenceRepositoryInterface {
+	return &NotificationPreferenceRepository{db: db}
+}
+
+// GetByUserID kullanıcının kayıtlı tercihlerini getirir (kayıt yoksa nil, nil)
+func (r *NotificationPreferenceRepository) GetByUserID(userID int) (*models.NotificationPreferences, error) {
+	query := `
+		SELECT user_id, transfer_received, low_balance, security_events, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+
+	var preferences models.NotificationPreferences
+	err := r.db.QueryRow(query, userID).Scan(
+		&preferences.UserID,
+		&preferences.TransferReceived,
+		&preferences.LowBalance,
+		&preferences.SecurityEvents,
+		&preferences.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("bildirim tercihleri alınamadı: %w", err)
+	}
+
+	return &preferences, nil
+}
+
+// Upsert tercihleri ekler veya günceller, kaydedilen hali döner
+func (r *NotificationPreferenceRepository) Upsert(preferences *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	query := `
+		INSERT INTO notification_preferences (user_id, transfer_received, low_balance, security_events, updated_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			transfer_received = EXCLUDED.transfer_received,
+			low_balance = EXCLUDED.low_balance,
+			security_events = EXCLUDED.security_events,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	saved := *preferences
+	err := r.db.QueryRow(query, preferences.UserID, preferences.TransferReceived, preferences.LowBalance, preferences.SecurityEvents).
+		Scan(&saved.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("bildirim tercihleri kaydedilemedi: %w", err)
+	}
+
+	return &saved, nil
+}
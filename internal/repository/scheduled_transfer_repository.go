@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// ScheduledTransferRepository ileri tarihli transfer database işlemleri
+type ScheduledTransferRepository struct {
+	db *sql.DB
+}
+
+// NewScheduledTransferRepository yeni repository oluşturur
+func NewScheduledTransferRepository(db *sql.DB) interfaces.ScheduledTransferRepositoryInterface {
+	return &ScheduledTransferRepository{db: db}
+}
+
+// scheduledTransferColumns kayıt okunurken seçilen kolonlar (scanScheduledTransfer ile aynı sırada)
+const scheduledTransferColumns = `id, from_user_id, to_user_id, amount, COALESCE(description, ''), scheduled_at, status, transaction_id, COALESCE(last_error, ''), created_at`
+
+// scanScheduledTransfer tek satırı modele okur
+func scanScheduledTransfer(scanner interface{ Scan(...interface{}) error }) (*models.ScheduledTransfer, error) {
+	transfer := &models.ScheduledTransfer{}
+	var transactionID sql.NullInt64
+	err := scanner.Scan(&transfer.ID, &transfer.FromUserID, &transfer.ToUserID, &transfer.Amount, &transfer.Description,
+		&transfer.ScheduledAt, &transfer.Status, &transactionID, &transfer.LastError, &transfer.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if transactionID.Valid {
+		id := int(transactionID.Int64)
+		transfer.TransactionID = &id
+	}
+	return transfer, nil
+}
+
+// Create transferi scheduled status'u ile kaydeder. maxActive > 0 ise kullanıcının aktif (scheduled/processing)
+// kayıt sayısı sınırdayken kaydetmez ve nil, nil döner. Sayım ve kayıt kullanıcı bazlı advisory lock altında
+// tek DB transaction'ında yapılır: eşzamanlı istekler sıraya girer, sınır aşılamaz.
+func (r *ScheduledTransferRepository) Create(transfer *models.ScheduledTransfer, maxActive int) (*models.ScheduledTransfer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("zamanlanmış transfer kaydedilemedi: %w", err)
+	}
+	defer tx.Rollback()
+
+	if maxActive > 0 {
+		// Sayım kilitten sonraki ayrı statement'ta yapılır; böylece sırada bekleyen istek önceki kaydı görür
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('scheduled_transfers'), $1)`, transfer.FromUserID); err != nil {
+			return nil, fmt.Errorf("zamanlanmış transfer kilidi alınamadı: %w", err)
+		}
+
+		var active int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM scheduled_transfers WHERE from_user_id = $1 AND status IN ($2, $3)
+		`, transfer.FromUserID, models.ScheduledStatusScheduled, models.ScheduledStatusProcessing).Scan(&active)
+		if err != nil {
+			return nil, fmt.Errorf("aktif zamanlanmış transferler sayılamadı: %w", err)
+		}
+		if active >= maxActive {
+			return nil, nil
+		}
+	}
+
+	created := *transfer
+	created.Status = models.ScheduledStatusScheduled
+	err = tx.QueryRow(`
+		INSERT INTO scheduled_transfers (from_user_id, to_user_id, amount, description, scheduled_at, status)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		RETURNING id, created_at
+	`, transfer.FromUserID, transfer.ToUserID, transfer.Amount, transfer.Description, transfer.ScheduledAt, created.Status).
+		Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("zamanlanmış transfer kaydedilemedi: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("zamanlanmış transfer kaydedilemedi: %w", err)
+	}
+	return &created, nil
+}
+
+// GetByID kaydı getirir (yoksa nil, nil)
+func (r *ScheduledTransferRepository) GetByID(id int) (*models.ScheduledTransfer, error) {
+	row := r.db.QueryRow(`SELECT `+scheduledTransferColumns+` FROM scheduled_transfers WHERE id = $1`, id)
+	transfer, err := scanScheduledTransfer(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("zamanlanmış transfer alınamadı: %w", err)
+	}
+	return transfer, nil
+}
+
+// ClaimDue zamanı gelen kayıtları tek sorguda processing olarak işaretler.
+// SKIP LOCKED sayesinde eşzamanlı scheduler'lar birbirini beklemez ve aynı kaydı almaz.
+func (r *ScheduledTransferRepository) ClaimDue(now, staleBefore time.Time, limit int) ([]*models.ScheduledTransfer, error) {
+	rows, err := r.db.Query(`
+		UPDATE scheduled_transfers
+		SET status = $1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM scheduled_transfers
+			WHERE (status = $2 AND scheduled_at <= $3) OR (status = $1 AND updated_at < $4)
+			ORDER BY scheduled_at
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+scheduledTransferColumns,
+		models.ScheduledStatusProcessing, models.ScheduledStatusScheduled, now, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("zamanı gelen transferler alınamadı: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []*models.ScheduledTransfer
+	for rows.Next() {
+		transfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("zamanlanmış transfer okunamadı: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("zamanlanmış transfer okunamadı: %w", err)
+	}
+	return transfers, nil
+}
+
+// Reschedule processing'deki kaydı tekrar scheduled yapar
+func (r *ScheduledTransferRepository) Reschedule(id int) error {
+	_, err := r.db.Exec(`
+		UPDATE scheduled_transfers SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3
+	`, models.ScheduledStatusScheduled, id, models.ScheduledStatusProcessing)
+	if err != nil {
+		return fmt.Errorf("zamanlanmış transfer tekrar sıraya alınamadı: %w", err)
+	}
+	return nil
+}
+
+// MarkCompleted kaydı oluşan transferle tamamlar
+func (r *ScheduledTransferRepository) MarkCompleted(id int, transactionID int) error {
+	_, err := r.db.Exec(`
+		UPDATE scheduled_transfers SET status = $1, transaction_id = $2, last_error = NULL, updated_at = NOW() WHERE id = $3
+	`, models.ScheduledStatusCompleted, transactionID, id)
+	if err != nil {
+		return fmt.Errorf("zamanlanmış transfer tamamlanamadı: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed kaydı hata mesajıyla failed yapar
+func (r *ScheduledTransferRepository) MarkFailed(id int, lastError string) error {
+	_, err := r.db.Exec(`
+		UPDATE scheduled_transfers SET status = $1, last_error = $2, updated_at = NOW() WHERE id = $3
+	`, models.ScheduledStatusFailed, lastError, id)
+	if err != nil {
+		return fmt.Errorf("zamanlanmış transfer güncellenemedi: %w", err)
+	}
+	return nil
+}
+
+// Cancel scheduled durumdaki kaydı iptal eder; scheduler kaydı zaten aldıysa false döner
+func (r *ScheduledTransferRepository) Cancel(id int) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE scheduled_transfers SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3
+	`, models.ScheduledStatusCancelled, id, models.ScheduledStatusScheduled)
+	if err != nil {
+		return false, fmt.Errorf("zamanlanmış transfer iptal edilemedi: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("zamanlanmış transfer iptal edilemedi: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestScheduledTransferRepository_Create_CountsUnderAdvisoryLock, aktif kayıt sınırı verildiğinde sayımın kullanıcı bazlı
+// advisory lock alındıktan sonra aynı DB transaction'ında yapıldığını, sınırdayken kayıt yazılmadan nil döndüğünü test eder.
+func TestScheduledTransferRepository_Create_CountsUnderAdvisoryLock(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewScheduledTransferRepository(db)
+	scheduledAt := time.Date(2025, 9, 2, 12, 0, 0, 0, time.UTC)
+	transfer := &models.ScheduledTransfer{FromUserID: 10, ToUserID: 20, Amount: 75, ScheduledAt: scheduledAt}
+
+	// Sınırın altında: kilit → sayım → kayıt → commit
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtext\('scheduled_transfers'\), \$1\)`).WithArgs(10).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM scheduled_transfers WHERE from_user_id = \$1`).
+		WithArgs(10, models.ScheduledStatusScheduled, models.ScheduledStatusProcessing).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO scheduled_transfers").
+		WithArgs(10, 20, 75.0, "", scheduledAt, models.ScheduledStatusScheduled).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+	mock.ExpectCommit()
+
+	// Sınırda: kayıt yazılmadan rollback
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs(10).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM scheduled_transfers`).
+		WithArgs(10, models.ScheduledStatusScheduled, models.ScheduledStatusProcessing).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	// Act
+	created, createErr := repo.Create(transfer, 2)
+	limited, limitErr := repo.Create(transfer, 2)
+
+	// Assert
+	assert.NoError(t, createErr)
+	assert.Equal(t, 3, created.ID)
+	assert.Equal(t, models.ScheduledStatusScheduled, created.Status)
+	assert.NoError(t, limitErr)
+	assert.Nil(t, limited)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
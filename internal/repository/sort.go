@@ -0,0 +1,26 @@
+package repository
+
+import "github.com/onerilhan/go-payment-api/internal/models"
+
+// createdAtOrderBy geçmiş sorguları için ORDER BY ifadesini döner.
+// Aynı created_at'e sahip satırların sırası sayfalar arasında değişmesin diye id ile de sıralanır.
+// Sadece sabit ifadeler döner; kullanıcı girdisi SQL'e eklenmez.
+func createdAtOrderBy(sort string) string {
+	if sort == models.SortAsc {
+		return "created_at ASC, id ASC"
+	}
+	return "created_at DESC, id DESC"
+}
+
+// historyOrderBy filtreli geçmiş sorgusu için ORDER BY ifadesini döner (tutar eşitse en yeni önce).
+// createdAtOrderBy gibi sadece sabit ifadeler döner.
+func historyOrderBy(sort string) string {
+	switch sort {
+	case models.SortAmountAsc:
+		return "amount ASC, created_at DESC, id DESC"
+	case models.SortAmountDesc:
+		return "amount DESC, created_at DESC, id DESC"
+	default:
+		return createdAtOrderBy(sort)
+	}
+}
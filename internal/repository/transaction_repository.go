@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/onerilhan/go-payment-api/internal/interfaces"
 	"github.com/onerilhan/go-payment-api/internal/models"
@@ -21,8 +23,8 @@ func NewTransactionRepository(db *sql.DB) interfaces.TransactionRepositoryInterf
 // Create yeni transaction oluşturur
 func (r *TransactionRepository) Create(tx *models.Transaction) (*models.Transaction, error) {
 	query := `
-		INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description) 
-		VALUES ($1, $2, $3, $4, $5, $6) 
+		INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description, channel) 
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')) 
 		RETURNING id, created_at
 	`
 
@@ -34,6 +36,7 @@ func (r *TransactionRepository) Create(tx *models.Transaction) (*models.Transact
 		tx.Type,
 		tx.Status,
 		tx.Description,
+		tx.Channel,
 	).Scan(&tx.ID, &tx.CreatedAt)
 
 	if err != nil {
@@ -46,7 +49,7 @@ func (r *TransactionRepository) Create(tx *models.Transaction) (*models.Transact
 // GetByID ID ile transaction getirir
 func (r *TransactionRepository) GetByID(id int) (*models.Transaction, error) {
 	query := `
-		SELECT id, from_user_id, to_user_id, amount, type, status, description, created_at
+		SELECT id, from_user_id, to_user_id, amount, type, status, description, COALESCE(channel, ''), created_at
 		FROM transactions 
 		WHERE id = $1
 	`
@@ -60,6 +63,7 @@ func (r *TransactionRepository) GetByID(id int) (*models.Transaction, error) {
 		&tx.Type,
 		&tx.Status,
 		&tx.Description,
+		&tx.Channel,
 		&tx.CreatedAt,
 	)
 
@@ -73,13 +77,13 @@ func (r *TransactionRepository) GetByID(id int) (*models.Transaction, error) {
 	return &tx, nil
 }
 
-// GetByUserID kullanıcının transaction'larını getirir
-func (r *TransactionRepository) GetByUserID(userID int, limit, offset int) ([]*models.Transaction, error) {
+// GetByUserID kullanıcının transaction'larını verilen yönde (asc|desc) getirir
+func (r *TransactionRepository) GetByUserID(userID int, limit, offset int, sort string) ([]*models.Transaction, error) {
 	query := `
-		SELECT id, from_user_id, to_user_id, amount, type, status, description, created_at
+		SELECT id, from_user_id, to_user_id, amount, type, status, description, COALESCE(channel, ''), created_at
 		FROM transactions 
 		WHERE from_user_id = $1 OR to_user_id = $1
-		ORDER BY created_at DESC
+		ORDER BY ` + createdAtOrderBy(sort) + `
 		LIMIT $2 OFFSET $3
 	`
 
@@ -100,6 +104,7 @@ func (r *TransactionRepository) GetByUserID(userID int, limit, offset int) ([]*m
 			&tx.Type,
 			&tx.Status,
 			&tx.Description,
+			&tx.Channel,
 			&tx.CreatedAt,
 		)
 		if err != nil {
@@ -111,10 +116,85 @@ func (r *TransactionRepository) GetByUserID(userID int, limit, offset int) ([]*m
 	return transactions, nil
 }
 
+// GetByUserIDFiltered kullanıcının transaction'larını tip, status ve tarih aralığı filtreleriyle getirir.
+// Filtre değerleri parametre olarak geçilir; ORDER BY sadece sabit ifadelerden seçilir.
+// filter.Cursor verilirse offset yerine keyset (created_at, id) ile sayfalanır; offset 0 geçilmelidir.
+func (r *TransactionRepository) GetByUserIDFiltered(userID int, filter *models.TransactionHistoryFilter, limit, offset int) ([]*models.Transaction, error) {
+	if filter == nil {
+		filter = &models.TransactionHistoryFilter{}
+	}
+
+	conditions := []string{"(from_user_id = $1 OR to_user_id = $1)"}
+	args := []interface{}{userID}
+	addCondition := func(expr string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(expr, len(args)))
+	}
+
+	if filter.Type != "" {
+		addCondition("type = $%d", filter.Type)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		addCondition("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < $%d", filter.To)
+	}
+	// Keyset: cursor satırından sonrası (ORDER BY ile aynı yönde); araya eklenen yeni satırlar sayfaları kaydırmaz
+	if filter.Cursor != nil {
+		operator := "<"
+		if filter.Sort == models.SortAsc {
+			operator = ">"
+		}
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) %s ($%d, $%d)", operator, len(args)-1, len(args)))
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT id, from_user_id, to_user_id, amount, type, status, description, COALESCE(channel, ''), created_at
+		FROM transactions
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, strings.Join(conditions, " AND "), historyOrderBy(filter.Sort), len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("transaction listesi alınamadı: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []*models.Transaction{}
+	for rows.Next() {
+		var tx models.Transaction
+		err := rows.Scan(
+			&tx.ID,
+			&tx.FromUserID,
+			&tx.ToUserID,
+			&tx.Amount,
+			&tx.Type,
+			&tx.Status,
+			&tx.Description,
+			&tx.Channel,
+			&tx.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("transaction scan hatası: %w", err)
+		}
+		transactions = append(transactions, &tx)
+	}
+
+	return transactions, rows.Err()
+}
+
 // GetByStatus, belirli bir durumdaki transaction'ları getirir
 func (r *TransactionRepository) GetByStatus(status string, limit, offset int) ([]*models.Transaction, error) {
 	query := `
-		SELECT id, from_user_id, to_user_id, amount, type, status, description, created_at
+		SELECT id, from_user_id, to_user_id, amount, type, status, description, COALESCE(channel, ''), created_at
 		FROM transactions 
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -138,6 +218,7 @@ func (r *TransactionRepository) GetByStatus(status string, limit, offset int) ([
 			&tx.Type,
 			&tx.Status,
 			&tx.Description,
+			&tx.Channel,
 			&tx.CreatedAt,
 		)
 		if err != nil {
@@ -149,6 +230,252 @@ func (r *TransactionRepository) GetByStatus(status string, limit, offset int) ([
 	return transactions, nil
 }
 
+// StreamByDateRange, belirli tarih aralığındaki transaction'ları tek tek callback'e iletir.
+// Satırlar belleğe toplanmaz; gece raporlama/ETL işleri için tasarlanmıştır.
+// Callback hata dönerse iterasyon durur ve hata çağırana iletilir.
+func (r *TransactionRepository) StreamByDateRange(from, to time.Time, fn func(*models.Transaction) error) error {
+	query := `
+		SELECT id, from_user_id, to_user_id, amount, type, status, description, COALESCE(channel, ''), created_at
+		FROM transactions 
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return fmt.Errorf("transaction stream sorgusu başarısız: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tx models.Transaction
+		err := rows.Scan(
+			&tx.ID,
+			&tx.FromUserID,
+			&tx.ToUserID,
+			&tx.Amount,
+			&tx.Type,
+			&tx.Status,
+			&tx.Description,
+			&tx.Channel,
+			&tx.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("transaction scan hatası: %w", err)
+		}
+		if err := fn(&tx); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("transaction stream iterasyon hatası: %w", err)
+	}
+
+	return nil
+}
+
+// CountUserTransactionsSince, kullanıcının belirli bir andan bu yana başlattığı transaction sayısını döner.
+// Kullanıcının gönderdiği (transfer/debit) ve kendi hesabına yatırdığı (credit) işlemler sayılır, failed olanlar hariç.
+func (r *TransactionRepository) CountUserTransactionsSince(userID int, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE (from_user_id = $1 OR (to_user_id = $1 AND from_user_id IS NULL))
+			AND created_at >= $2
+			AND status <> 'failed'
+	`
+
+	var count int
+	if err := r.db.QueryRow(query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("transaction sayısı alınamadı: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetUserUsageSince, kullanıcının belirli bir andan bu yana yaptığı işlem sayısını ve gönderdiği toplam tutarı döner.
+// Sayı CountUserTransactionsSince ile aynı kurala göre, tutar ise kullanıcının gönderdiği (transfer/debit) işlemlerden hesaplanır.
+func (r *TransactionRepository) GetUserUsageSince(userID int, since time.Time) (*models.TransactionUsage, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(amount) FILTER (WHERE from_user_id = $1), 0)
+		FROM transactions
+		WHERE (from_user_id = $1 OR (to_user_id = $1 AND from_user_id IS NULL))
+			AND created_at >= $2
+			AND status <> 'failed'
+	`
+
+	var usage models.TransactionUsage
+	if err := r.db.QueryRow(query, userID, since).Scan(&usage.Count, &usage.Amount); err != nil {
+		return nil, fmt.Errorf("işlem kullanımı alınamadı: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// CountTransfersWithDescriptionSince, göndericinin alıcıya belirli bir andan bu yana yaptığı açıklamalı transfer sayısını döner.
+// Failed transferler ve boş açıklamalılar sayılmaz.
+func (r *TransactionRepository) CountTransfersWithDescriptionSince(fromUserID, toUserID int, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE from_user_id = $1
+			AND to_user_id = $2
+			AND type = $3
+			AND TRIM(COALESCE(description, '')) <> ''
+			AND created_at >= $4
+			AND status <> 'failed'
+	`
+
+	var count int
+	if err := r.db.QueryRow(query, fromUserID, toUserID, models.TypeTransfer, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("açıklamalı transfer sayısı alınamadı: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountCompletedTransfersBetween, göndericiden alıcıya yapılmış tamamlanmış transfer sayısını döner.
+func (r *TransactionRepository) CountCompletedTransfersBetween(fromUserID, toUserID int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE from_user_id = $1
+			AND to_user_id = $2
+			AND type = $3
+			AND status = $4
+	`
+
+	var count int
+	if err := r.db.QueryRow(query, fromUserID, toUserID, models.TypeTransfer, models.StatusCompleted).Scan(&count); err != nil {
+		return 0, fmt.Errorf("tamamlanmış transfer sayısı alınamadı: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTransferAmountHistorySince, kullanıcının belirli bir andan bu yana tamamlanmış giden transferlerinin
+// sayısını, ortalama ve en büyük tutarını döner (anomali tespiti için).
+func (r *TransactionRepository) GetTransferAmountHistorySince(userID int, since time.Time) (*models.TransferAmountHistory, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(AVG(amount), 0), COALESCE(MAX(amount), 0)
+		FROM transactions
+		WHERE from_user_id = $1
+			AND type = $2
+			AND status = $3
+			AND created_at >= $4
+	`
+
+	var history models.TransferAmountHistory
+	if err := r.db.QueryRow(query, userID, models.TypeTransfer, models.StatusCompleted, since).Scan(&history.Count, &history.Average, &history.Max); err != nil {
+		return nil, fmt.Errorf("transfer geçmişi alınamadı: %w", err)
+	}
+
+	return &history, nil
+}
+
+// GetTransactionReviews, verilen status'taki fraud inceleme kayıtlarını transaction'larıyla birlikte en eskiden yeniye döner.
+func (r *TransactionRepository) GetTransactionReviews(status string, limit, offset int) ([]*models.TransactionReview, error) {
+	query := `
+		SELECT r.id, r.transaction_id, r.reason, r.status, r.note, r.reviewed_by, r.reviewed_at, r.created_at,
+			t.from_user_id, t.to_user_id, t.amount, t.type, t.status, t.description, t.created_at
+		FROM transaction_reviews r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.status = $1
+		ORDER BY r.created_at ASC, r.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("inceleme kayıtları alınamadı: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]*models.TransactionReview, 0)
+	for rows.Next() {
+		review := &models.TransactionReview{Transaction: &models.Transaction{}}
+		tx := review.Transaction
+
+		var note sql.NullString
+		if err := rows.Scan(
+			&review.ID, &review.TransactionID, &review.Reason, &review.Status, &note, &review.ReviewedBy, &review.ReviewedAt, &review.CreatedAt,
+			&tx.FromUserID, &tx.ToUserID, &tx.Amount, &tx.Type, &tx.Status, &tx.Description, &tx.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("inceleme kaydı okunamadı: %w", err)
+		}
+		review.Note = note.String
+		tx.ID = review.TransactionID
+
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("inceleme kayıtları okunamadı: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// GetCounterpartySummary, kullanıcının transfer yaptığı karşı tarafları hacme göre sıralı olarak özetler.
+// Yalnızca completed transferler sayılır; from/to sıfır ise o uçta tarih sınırı uygulanmaz. Karşı taraf bilgileri maskelenir.
+func (r *TransactionRepository) GetCounterpartySummary(userID int, from, to time.Time, limit int) ([]*models.CounterpartySummary, error) {
+	query := `
+		SELECT
+			u.name,
+			u.email,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.from_user_id = $1), 0) AS total_sent,
+			COALESCE(SUM(t.amount) FILTER (WHERE t.to_user_id = $1), 0) AS total_received,
+			COUNT(*) AS transaction_count
+		FROM transactions t
+		JOIN users u ON u.id = CASE WHEN t.from_user_id = $1 THEN t.to_user_id ELSE t.from_user_id END
+		WHERE (t.from_user_id = $1 OR t.to_user_id = $1)
+			AND t.from_user_id IS NOT NULL
+			AND t.to_user_id IS NOT NULL
+			AND t.from_user_id <> t.to_user_id
+			AND t.status = $2
+	`
+	args := []interface{}{userID, models.StatusCompleted}
+
+	if !from.IsZero() {
+		args = append(args, from)
+		query += fmt.Sprintf(" AND t.created_at >= $%d", len(args))
+	}
+	if !to.IsZero() {
+		args = append(args, to)
+		query += fmt.Sprintf(" AND t.created_at < $%d", len(args))
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(`
+		GROUP BY u.id, u.name, u.email
+		ORDER BY total_sent + total_received DESC, u.id
+		LIMIT $%d`, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("karşı taraf özeti alınamadı: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := make([]*models.CounterpartySummary, 0)
+	for rows.Next() {
+		var summary models.CounterpartySummary
+		if err := rows.Scan(&summary.Name, &summary.Email, &summary.TotalSent, &summary.TotalReceived, &summary.TransactionCount); err != nil {
+			return nil, fmt.Errorf("karşı taraf özeti okunamadı: %w", err)
+		}
+		summary.Name = models.MaskName(summary.Name)
+		summary.Email = models.MaskEmail(summary.Email)
+		summaries = append(summaries, &summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("karşı taraf özeti okunamadı: %w", err)
+	}
+
+	return summaries, nil
+}
+
 // UpdateStatus, bir transaction'ın durumunu günceller
 func (r *TransactionRepository) UpdateStatus(id int, status string) error {
 	query := `UPDATE transactions SET status = $1 WHERE id = $2`
@@ -170,47 +497,77 @@ func (r *TransactionRepository) UpdateStatus(id int, status string) error {
 }
 
 // GetUserTransactionStats, bir kullanıcının işlem istatistiklerini hesaplar
+// Tip bazlı sayılar registry'deki (models.TransactionTypes) tüm tipler için, kanal dağılımı ile birlikte döner.
+// Tutarlar SQL'de numeric olarak yuvarlanır ve minor unit ile toplanır; float toplama hatası raporlara yansımaz.
 func (r *TransactionRepository) GetUserTransactionStats(userID int) (*models.TransactionStats, error) {
-	// Bu sorgu, senin TransactionStats modelindeki tüm alanları dolduracak şekilde güncellendi.
-	query := `
+	precision := models.AmountPrecision()
+	query := fmt.Sprintf(`
 		SELECT
-			-- Toplam işlem sayısı
-			COUNT(*) AS total_transactions,
-			-- Farklı tiplerdeki işlem sayıları (PostgreSQL'e özel FILTER kullanımı)
-			COUNT(*) FILTER (WHERE type = 'credit') AS total_credits,
-			COUNT(*) FILTER (WHERE type = 'debit') AS total_debits,
-			COUNT(*) FILTER (WHERE type = 'transfer') AS total_transfers,
-			-- Farklı tiplerdeki işlem tutarlarının toplamı
-			COALESCE(SUM(amount) FILTER (WHERE type = 'credit'), 0) AS total_credit_amount,
-			COALESCE(SUM(amount) FILTER (WHERE type = 'debit'), 0) AS total_debit_amount,
-			COALESCE(SUM(amount) FILTER (WHERE type = 'transfer'), 0) AS total_transfer_amount,
-			-- Son işlem tarihi (string formatında)
-			TO_CHAR(MAX(created_at), 'YYYY-MM-DD"T"HH24:MI:SS"Z"') as last_transaction_date
+			type,
+			COALESCE(channel, '') AS channel,
+			COUNT(*) AS total,
+			COALESCE(ROUND(SUM(amount)::numeric, %d), 0)::text AS total_amount,
+			MAX(created_at) AS last_created_at
 		FROM
 			transactions
 		WHERE
 			from_user_id = $1 OR to_user_id = $1
-	`
-	var stats models.TransactionStats
-	stats.UserID = userID // UserID'yi manuel olarak atıyoruz, çünkü sorgudan dönmüyor.
-
-	err := r.db.QueryRow(query, userID).Scan(
-		&stats.TotalTransactions,
-		&stats.TotalCredits,
-		&stats.TotalDebits,
-		&stats.TotalTransfers,
-		&stats.TotalCreditAmount,
-		&stats.TotalDebitAmount,
-		&stats.TotalTransferAmount,
-		&stats.LastTransactionDate,
-	)
+		GROUP BY type, channel
+	`, precision)
 
+	rows, err := r.db.Query(query, userID)
 	if err != nil {
-		// Eğer hiç işlem yoksa Scan hata verebilir ama bu bir sorun değil.
-		// Bu durumu kontrol edebilir veya stats'ın sıfır değerlerini dönebiliriz.
-		// Şimdilik, hata varsa direkt dönelim.
 		return nil, fmt.Errorf("kullanıcı işlem istatistikleri alınamadı: %w", err)
 	}
+	defer rows.Close()
+
+	stats := models.NewTransactionStats(userID)
+	var lastCreatedAt time.Time
+
+	// Kanal satırları tip bazında minor unit olarak birleştirilir, tutara en sonda bir kez çevrilir
+	var typeOrder []string
+	typeCounts := make(map[string]int)
+	typeUnits := make(map[string]int64)
+
+	for rows.Next() {
+		var (
+			txType      string
+			channel     string
+			count       int
+			amount      string
+			lastCreated sql.NullTime
+		)
+		if err := rows.Scan(&txType, &channel, &count, &amount, &lastCreated); err != nil {
+			return nil, fmt.Errorf("kullanıcı işlem istatistikleri okunamadı: %w", err)
+		}
+
+		units, err := models.ParseMinorUnits(amount, precision)
+		if err != nil {
+			return nil, fmt.Errorf("kullanıcı işlem istatistikleri okunamadı: %w", err)
+		}
+		if _, seen := typeCounts[txType]; !seen {
+			typeOrder = append(typeOrder, txType)
+		}
+		typeCounts[txType] += count
+		typeUnits[txType] += units
+
+		stats.AddChannelTotals(txType, channel, count)
+		if lastCreated.Valid && lastCreated.Time.After(lastCreatedAt) {
+			lastCreatedAt = lastCreated.Time
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("kullanıcı işlem istatistikleri okunamadı: %w", err)
+	}
+
+	for _, txType := range typeOrder {
+		stats.AddTypeTotals(txType, typeCounts[txType], models.MinorUnitsToAmount(typeUnits[txType], precision))
+	}
+
+	if !lastCreatedAt.IsZero() {
+		lastDate := lastCreatedAt.Format("2006-01-02T15:04:05Z")
+		stats.LastTransactionDate = &lastDate
+	}
 
-	return &stats, nil
+	return stats, nil
 }
@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestTransactionRepository_StreamByDateRange_InvokesCallbackPerRow, her satır için callback'in bir kez çağrıldığını
+// ve export'a giden kanal bilgisinin (channel) satırla birlikte okunduğunu test eder.
+func TestTransactionRepository_StreamByDateRange_InvokesCallbackPerRow(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}).
+		AddRow(1, nil, 10, 100.0, "credit", models.StatusCompleted, "yatırma", "web", from.Add(time.Hour)).
+		AddRow(2, 10, nil, 25.5, "debit", models.StatusCompleted, "çekme", "mobile", from.Add(2*time.Hour)).
+		AddRow(3, 10, 20, 50.0, "transfer", models.StatusCompleted, "transfer", "", from.Add(3*time.Hour))
+
+	mock.ExpectQuery(`SELECT (.+) COALESCE\(channel, ''\), created_at FROM transactions`).
+		WithArgs(from, to).
+		WillReturnRows(rows)
+
+	// Act
+	var seen []int
+	var channels []string
+	err = repo.StreamByDateRange(from, to, func(tx *models.Transaction) error {
+		seen = append(seen, tx.ID)
+		channels = append(channels, tx.Channel)
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, seen)
+	assert.Equal(t, []string{"web", "mobile", ""}, channels)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTransactionRepository_StreamByDateRange_StopsOnCallbackError, callback hatasında iterasyonun durduğunu test eder.
+func TestTransactionRepository_StreamByDateRange_StopsOnCallbackError(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}).
+		AddRow(1, nil, 10, 100.0, "credit", models.StatusCompleted, "", "", from).
+		AddRow(2, nil, 10, 200.0, "credit", models.StatusCompleted, "", "", from)
+
+	mock.ExpectQuery("SELECT (.+) FROM transactions").
+		WithArgs(from, to).
+		WillReturnRows(rows)
+
+	stopErr := errors.New("export hatası")
+
+	// Act
+	calls := 0
+	err = repo.StreamByDateRange(from, to, func(tx *models.Transaction) error {
+		calls++
+		return stopErr
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, stopErr)
+	assert.Equal(t, 1, calls)
+}
+
+// TestTransactionRepository_GetUserTransactionStats_AggregatesByType, tip bazlı satırların istatistiğe toplandığını test eder.
+func TestTransactionRepository_GetUserTransactionStats_AggregatesByType(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+	last := time.Date(2025, 8, 1, 10, 30, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"type", "channel", "total", "total_amount", "last_created_at"}).
+		AddRow(models.TypeCredit, "web", 1, 100.0, last.Add(-2*time.Hour)).
+		AddRow(models.TypeCredit, "", 1, 200.0, last.Add(-time.Hour)).
+		AddRow(models.TypeTransfer, "web", 1, 50.0, last)
+
+	mock.ExpectQuery("SELECT (.+) FROM\\s+transactions (.+) GROUP BY type, channel").
+		WithArgs(10).
+		WillReturnRows(rows)
+
+	// Act
+	stats, err := repo.GetUserTransactionStats(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalTransactions)
+	assert.Equal(t, 2, stats.TotalCredits)
+	assert.Equal(t, 300.0, stats.TotalCreditAmount)
+	assert.Equal(t, 1, stats.TotalTransfers)
+	assert.Equal(t, 0, stats.CountsByType[models.TypeDebit])
+	assert.Equal(t, map[string]int{"web": 2, models.UnknownChannel: 1}, stats.CountsByChannel)
+	assert.Equal(t, "2025-08-01T10:30:00Z", *stats.LastTransactionDate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTransactionRepository_GetUserTransactionStats_DecimalSafeTotals, float ile toplandığında kayan (0.1 + 0.2 + 0.3 gibi)
+// kanal toplamlarının SQL'de yuvarlanıp kuruşu kuruşuna doğru toplandığını test eder.
+func TestTransactionRepository_GetUserTransactionStats_DecimalSafeTotals(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+	last := time.Date(2025, 8, 1, 10, 30, 0, 0, time.UTC)
+
+	// Naif float toplamı: 0.1 + 0.2 + 0.3 = 0.6000000000000001
+	naive := 0.0
+	for _, amount := range []float64{0.1, 0.2, 0.3} {
+		naive += amount
+	}
+	assert.NotEqual(t, 0.6, naive)
+
+	rows := sqlmock.NewRows([]string{"type", "channel", "total", "total_amount", "last_created_at"}).
+		AddRow(models.TypeCredit, "web", 3, "0.10", last).
+		AddRow(models.TypeCredit, "mobile", 2, "0.20", last).
+		AddRow(models.TypeCredit, "", 1, "0.30", last).
+		AddRow(models.TypeDebit, "web", 7, "1234567.89", last)
+
+	mock.ExpectQuery("ROUND\\(SUM\\(amount\\)::numeric, 2\\)").
+		WithArgs(10).
+		WillReturnRows(rows)
+
+	// Act
+	stats, err := repo.GetUserTransactionStats(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0.6, stats.TotalCreditAmount)
+	assert.Equal(t, 0.6, stats.AmountsByType[models.TypeCredit])
+	assert.Equal(t, 6, stats.TotalCredits)
+	assert.Equal(t, 1234567.89, stats.TotalDebitAmount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTransactionRepository_GetByUserID_SortOrder, transaction geçmişinin asc ve desc sıralama ile sorgulandığını test eder.
+func TestTransactionRepository_GetByUserID_SortOrder(t *testing.T) {
+	cases := map[string]string{
+		models.SortAsc:  "ORDER BY created_at ASC, id ASC",
+		models.SortDesc: "ORDER BY created_at DESC, id DESC",
+	}
+
+	for sort, orderBy := range cases {
+		t.Run(sort, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+				func(_, actual string) error {
+					if !strings.Contains(actual, orderBy) {
+						return fmt.Errorf("sorgu %q içermiyor: %s", orderBy, actual)
+					}
+					return nil
+				})))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewTransactionRepository(db)
+			mock.ExpectQuery("").
+				WithArgs(10, 20, 0).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}).
+					AddRow(1, nil, 10, 100.0, "credit", models.StatusCompleted, "yatırma", "api", time.Now()))
+
+			// Act
+			transactions, err := repo.GetByUserID(10, 20, 0, sort)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Len(t, transactions, 1)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestTransactionRepository_GetByUserIDFiltered_CombinedFilters, tip, status ve tarih filtrelerinin parametre olarak
+// geçildiğini ve tutara göre sıralandığını test eder.
+func TestTransactionRepository_GetByUserIDFiltered_CombinedFilters(t *testing.T) {
+	// Arrange
+	const expectedQuery = "WHERE (from_user_id = $1 OR to_user_id = $1) AND type = $2 AND status = $3 AND created_at >= $4 AND created_at < $5 " +
+		"ORDER BY amount DESC, created_at DESC, id DESC LIMIT $6 OFFSET $7"
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+		func(_, actual string) error {
+			if !strings.Contains(strings.Join(strings.Fields(actual), " "), expectedQuery) {
+				return fmt.Errorf("sorgu %q içermiyor: %s", expectedQuery, actual)
+			}
+			return nil
+		})))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	filter := &models.TransactionHistoryFilter{
+		Type:   models.TypeTransfer,
+		Status: models.StatusCompleted,
+		From:   from,
+		To:     to,
+		Sort:   models.SortAmountDesc,
+	}
+
+	mock.ExpectQuery("").
+		WithArgs(10, models.TypeTransfer, models.StatusCompleted, from, to, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}).
+			AddRow(2, 10, 20, 300.0, "transfer", models.StatusCompleted, "kira", "web", from.Add(48*time.Hour)).
+			AddRow(1, 30, 10, 75.0, "transfer", models.StatusCompleted, "yemek", "mobile", from.Add(24*time.Hour)))
+
+	// Act
+	transactions, err := repo.GetByUserIDFiltered(10, filter, 20, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, 300.0, transactions[0].Amount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTransactionRepository_GetByUserIDFiltered_EmptyResult, eşleşen kayıt yoksa nil yerine boş liste döndüğünü test eder.
+func TestTransactionRepository_GetByUserIDFiltered_EmptyResult(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE (.+) AND status = \\$2 ORDER BY created_at DESC, id DESC").
+		WithArgs(10, models.StatusFailed, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}))
+
+	// Act
+	transactions, err := repo.GetByUserIDFiltered(10, &models.TransactionHistoryFilter{Status: models.StatusFailed, Sort: models.SortDesc}, 10, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, transactions)
+	assert.Empty(t, transactions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTransactionRepository_GetByUserIDFiltered_CursorKeyset, cursor verildiğinde offset yerine sıralama yönündeki
+// (created_at, id) karşılaştırmasıyla sayfalandığını test eder.
+func TestTransactionRepository_GetByUserIDFiltered_CursorKeyset(t *testing.T) {
+	cases := map[string]string{
+		models.SortDesc: "AND (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4 OFFSET $5",
+		models.SortAsc:  "AND (created_at, id) > ($2, $3) ORDER BY created_at ASC, id ASC LIMIT $4 OFFSET $5",
+	}
+
+	for sort, expectedQuery := range cases {
+		t.Run(sort, func(t *testing.T) {
+			// Arrange
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+				func(_, actual string) error {
+					if !strings.Contains(strings.Join(strings.Fields(actual), " "), expectedQuery) {
+						return fmt.Errorf("sorgu %q içermiyor: %s", expectedQuery, actual)
+					}
+					return nil
+				})))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewTransactionRepository(db)
+			cursor := &models.HistoryCursor{CreatedAt: time.Date(2025, 8, 1, 12, 0, 0, 123456000, time.UTC), ID: 42}
+
+			mock.ExpectQuery("").
+				WithArgs(10, cursor.CreatedAt, 42, 3, 0).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}))
+
+			// Act
+			_, err = repo.GetByUserIDFiltered(10, &models.TransactionHistoryFilter{Sort: sort, Cursor: cursor}, 3, 0)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestTransactionRepository_GetCounterpartySummary_AggregatesAndMasks, karşı taraf özetinin toplamları döndüğünü ve kimliği maskelediğini test eder.
+func TestTransactionRepository_GetCounterpartySummary_AggregatesAndMasks(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+
+	from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	rows := sqlmock.NewRows([]string{"name", "email", "total_sent", "total_received", "transaction_count"}).
+		AddRow("Ayşe Yılmaz", "ayse@example.com", 500.0, 250.0, 6).
+		AddRow("Bob", "bob@example.com", 0.0, 100.0, 1)
+
+	mock.ExpectQuery(`(?s)SUM\(t\.amount\) FILTER \(WHERE t\.from_user_id = \$1\).*t\.created_at >= \$3 AND t\.created_at < \$4.*GROUP BY u\.id.*ORDER BY total_sent \+ total_received DESC.*LIMIT \$5`).
+		WithArgs(10, models.StatusCompleted, from, to, 5).
+		WillReturnRows(rows)
+
+	// Act
+	summaries, err := repo.GetCounterpartySummary(10, from, to, 5)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 2)
+	assert.Equal(t, &models.CounterpartySummary{
+		Name:             "A*** Y*****",
+		Email:            "a***@example.com",
+		TotalSent:        500,
+		TotalReceived:    250,
+		TransactionCount: 6,
+	}, summaries[0])
+	assert.Equal(t, 750.0, summaries[0].Volume())
+	assert.Equal(t, "B**", summaries[1].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTransactionRepository_GetCounterpartySummary_OpenDateRange, tarih verilmediğinde tarih filtresi eklenmediğini test eder.
+func TestTransactionRepository_GetCounterpartySummary_OpenDateRange(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		if strings.Contains(actualSQL, "created_at") {
+			return fmt.Errorf("tarih filtresi beklenmiyordu: %s", actualSQL)
+		}
+		return nil
+	})))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTransactionRepository(db)
+
+	mock.ExpectQuery("").
+		WithArgs(10, models.StatusCompleted, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "email", "total_sent", "total_received", "transaction_count"}))
+
+	// Act
+	summaries, err := repo.GetCounterpartySummary(10, time.Time{}, time.Time{}, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, summaries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+//go:build postgres
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/services"
+)
+
+// TestTransactionService_Transfer_OpposingTransfersNoDeadlock, iki kullanıcı arasında eşzamanlı karşılıklı
+// transferlerin (A→B ve B→A) deadlock (40P01) almadan tamamlandığını ve son bakiyelerin doğru olduğunu test eder.
+// Tekrar deneme kapalıdır; kilit sırası yöne göre değişseydi deadlock hatası doğrudan dönerdi.
+func TestTransactionService_Transfer_OpposingTransfersNoDeadlock(t *testing.T) {
+	// Arrange
+	database := newTestPostgresDB(t)
+	userA := createTestUserWithBalance(t, database, 100)
+	userB := createTestUserWithBalance(t, database, 100)
+	t.Cleanup(func() {
+		database.Exec(`DELETE FROM transactions WHERE from_user_id IN ($1, $2) OR to_user_id IN ($1, $2)`, userA, userB)
+	})
+
+	transactionService := services.NewTransactionService(NewTransactionRepository(database), nil, nil, database)
+	transactionService.SetLimitConfig(nil)
+	transactionService.SetRetryConfig(nil)
+
+	// 10 x (A→B 3) ve 10 x (B→A 5): A = 100 - 30 + 50 = 120, B = 100 + 30 - 50 = 80
+	type transfer struct {
+		fromUserID, toUserID int
+		amount               float64
+	}
+	var transfers []transfer
+	for i := 0; i < 10; i++ {
+		transfers = append(transfers, transfer{userA, userB, 3}, transfer{userB, userA, 5})
+	}
+
+	errs := make([]error, len(transfers))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Act
+	for i, tr := range transfers {
+		wg.Add(1)
+		go func(i int, tr transfer) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = transactionService.Transfer(context.Background(), tr.fromUserID, &models.TransferRequest{ToUserID: tr.toUserID, Amount: tr.amount})
+		}(i, tr)
+	}
+	close(start)
+	wg.Wait()
+
+	balanceRepo := NewBalanceRepository(database)
+	balanceA, err := balanceRepo.GetByUserID(userA)
+	require.NoError(t, err)
+	balanceB, err := balanceRepo.GetByUserID(userB)
+	require.NoError(t, err)
+
+	// Assert
+	for _, err := range errs {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			assert.NotEqual(t, db.SQLStateDeadlockDetected, string(pqErr.Code), "transfer deadlock aldı")
+		}
+		assert.NoError(t, err)
+	}
+	assert.InDelta(t, 120.0, balanceA.Amount, 0.001)
+	assert.InDelta(t, 80.0, balanceB.Amount, 0.001)
+}
@@ -8,9 +8,13 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/onerilhan/go-payment-api/internal/db"
 	"github.com/onerilhan/go-payment-api/internal/models"
 )
 
+// activeEmailIndex aktif kullanıcılar arasında email benzersizliğini sağlayan partial unique index adı
+const activeEmailIndex = "idx_users_email_active"
+
 // UserRepository kullanıcı database işlemleri
 type UserRepository struct {
 	db *sql.DB
@@ -24,23 +28,30 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 // Create yeni kullanıcı oluşturur
 func (r *UserRepository) Create(user *models.CreateUserRequest) (*models.User, error) {
 	query := `
-		INSERT INTO users (name, email, password, role) 
-		VALUES ($1, $2, $3, $4) 
-		RETURNING id, name, email, role, created_at
+		INSERT INTO users (name, email, password, role, account_number) 
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')) 
+		RETURNING id, name, email, role, created_at, account_number
 	`
 
 	var result models.User
-	err := r.db.QueryRow(query, user.Name, user.Email, user.Password, user.Role).Scan(
+	var accountNumber sql.NullString
+	err := r.db.QueryRow(query, user.Name, user.Email, user.Password, user.Role, user.AccountNumber).Scan(
 		&result.ID,
 		&result.Name,
 		&result.Email,
 		&result.Role,
 		&result.CreatedAt,
+		&accountNumber,
 	)
 
 	if err != nil {
+		// Eşzamanlı kayıtta uygulama kontrolünü geçen ikinci istek index'e takılır
+		if db.IsUniqueViolation(err, activeEmailIndex) {
+			return nil, models.ErrEmailAlreadyInUse
+		}
 		return nil, fmt.Errorf("kullanıcı oluşturulamadı: %w", err)
 	}
+	result.AccountNumber = nullStringPtr(accountNumber)
 
 	return &result, nil
 }
@@ -48,12 +59,13 @@ func (r *UserRepository) Create(user *models.CreateUserRequest) (*models.User, e
 // GetByEmail email ile kullanıcı bulur
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password, role, created_at 
+		SELECT id, name, email, password, role, created_at, account_number 
 		FROM users 
 		WHERE email = $1 AND deleted_at IS NULL
 	`
 
 	var user models.User
+	var accountNumber sql.NullString
 	err := r.db.QueryRow(query, email).Scan(
 		&user.ID,
 		&user.Name,
@@ -61,6 +73,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 		&user.Password,
 		&user.Role,
 		&user.CreatedAt,
+		&accountNumber,
 	)
 
 	if err != nil {
@@ -70,24 +83,29 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 		return nil, fmt.Errorf("kullanıcı arama hatası: %w", err)
 	}
 
+	user.AccountNumber = nullStringPtr(accountNumber)
+
 	return &user, nil
 }
 
 // GetByID ID ile kullanıcı bulur
 func (r *UserRepository) GetByID(id int) (*models.User, error) {
 	query := `
-		SELECT id, name, email, role, created_at 
+		SELECT id, name, email, role, created_at, account_number, version 
 		FROM users 
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var user models.User
+	var accountNumber sql.NullString
 	err := r.db.QueryRow(query, id).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
 		&user.Role,
 		&user.CreatedAt,
+		&accountNumber,
+		&user.Version,
 	)
 
 	if err != nil {
@@ -97,6 +115,38 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 		return nil, fmt.Errorf("kullanıcı arama hatası: %w", err)
 	}
 
+	user.AccountNumber = nullStringPtr(accountNumber)
+
+	return &user, nil
+}
+
+// GetByAccountNumber hesap numarası ile kullanıcı bulur
+func (r *UserRepository) GetByAccountNumber(accountNumber string) (*models.User, error) {
+	query := `
+		SELECT id, name, email, role, created_at, account_number 
+		FROM users 
+		WHERE account_number = $1 AND deleted_at IS NULL
+	`
+
+	var user models.User
+	var storedAccountNumber sql.NullString
+	err := r.db.QueryRow(query, accountNumber).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&storedAccountNumber,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("hesap numarasına ait kullanıcı bulunamadı")
+		}
+		return nil, fmt.Errorf("kullanıcı arama hatası: %w", err)
+	}
+	user.AccountNumber = nullStringPtr(storedAccountNumber)
+
 	return &user, nil
 }
 
@@ -150,16 +200,27 @@ func (r *UserRepository) Update(id int, req *models.UpdateUserRequest) (*models.
 	args = append(args, time.Now())
 	argIndex++
 
-	// ID'yi en sona ekle
+	// Versiyonu artır (optimistic concurrency)
+	setParts = append(setParts, "version = version + 1")
+
+	// ID'yi ekle
+	conditions := fmt.Sprintf("id = $%d AND deleted_at IS NULL", argIndex)
 	args = append(args, id)
+	argIndex++
+
+	// If-Match: sadece okunan versiyon hâlâ geçerliyse güncelle
+	if req.ExpectedVersion != nil {
+		conditions += fmt.Sprintf(" AND version = $%d", argIndex)
+		args = append(args, *req.ExpectedVersion)
+	}
 
 	// Query'yi oluştur
 	query := fmt.Sprintf(`
 		UPDATE users 
 		SET %s
-		WHERE id = $%d AND deleted_at IS NULL
-		RETURNING id, name, email, role, created_at
-	`, strings.Join(setParts, ", "), argIndex)
+		WHERE %s
+		RETURNING id, name, email, role, created_at, version
+	`, strings.Join(setParts, ", "), conditions)
 
 	// Query'yi çalıştır
 	var user models.User
@@ -169,18 +230,42 @@ func (r *UserRepository) Update(id int, req *models.UpdateUserRequest) (*models.
 		&user.Email,
 		&user.Role,
 		&user.CreatedAt,
+		&user.Version,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
+			if req.ExpectedVersion != nil && r.exists(id) {
+				return nil, models.ErrUserVersionConflict
+			}
 			return nil, fmt.Errorf("kullanıcı bulunamadı")
 		}
+		if db.IsUniqueViolation(err, activeEmailIndex) {
+			return nil, models.ErrEmailAlreadyInUse
+		}
 		return nil, fmt.Errorf("kullanıcı güncellenemedi: %w", err)
 	}
 
 	return &user, nil
 }
 
+// exists silinmemiş kullanıcının var olup olmadığını döner (versiyon çakışmasını "bulunamadı"dan ayırmak için)
+func (r *UserRepository) exists(id int) bool {
+	var found bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&found)
+	return err == nil && found
+}
+
+// IsActive kullanıcının var olup silinmemiş (hesabı kapatılmamış) olduğunu döner
+func (r *UserRepository) IsActive(id int) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("kullanıcı durumu alınamadı: %w", err)
+	}
+	return active, nil
+}
+
 // Delete kullanıcıyı siler (soft delete)
 func (r *UserRepository) Delete(id int) error {
 	query := `
@@ -249,3 +334,23 @@ func (r *UserRepository) GetAll(limit, offset int) ([]*models.User, int, error)
 
 	return users, totalCount, nil
 }
+
+// CountByRole belirli role sahip (silinmemiş) kullanıcı sayısını döner
+func (r *UserRepository) CountByRole(role string) (int, error) {
+	query := `SELECT COUNT(*) FROM users WHERE role = $1 AND deleted_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRow(query, role).Scan(&count); err != nil {
+		return 0, fmt.Errorf("rol bazlı kullanıcı sayısı alınamadı: %w", err)
+	}
+
+	return count, nil
+}
+
+// nullStringPtr NULL olabilen kolonu *string'e çevirir
+func nullStringPtr(ns sql.NullString) *string {
+	if !ns.Valid {
+		return nil
+	}
+	return &ns.String
+}
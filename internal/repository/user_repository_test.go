@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestUserRepository_Update_MatchingVersionIncrements, beklenen versiyon eşleşince güncellemenin yapılıp versiyonun arttığını test eder.
+func TestUserRepository_Update_MatchingVersionIncrements(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	name := "Yeni İsim"
+	version := 2
+
+	mock.ExpectQuery(`UPDATE users\s+SET name = \$1, updated_at = \$2, version = version \+ 1\s+WHERE id = \$3 AND deleted_at IS NULL AND version = \$4`).
+		WithArgs(name, sqlmock.AnyArg(), 7, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}).
+			AddRow(7, name, "ali@example.com", "user", time.Now(), 3))
+
+	// Act
+	user, err := repo.Update(7, &models.UpdateUserRequest{Name: &name, ExpectedVersion: &version})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, user.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUserRepository_Update_StaleVersionConflicts, versiyon değiştiyse ErrUserVersionConflict döndüğünü test eder.
+func TestUserRepository_Update_StaleVersionConflicts(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	name := "Yeni İsim"
+	version := 2
+
+	mock.ExpectQuery("UPDATE users").
+		WithArgs(name, sqlmock.AnyArg(), 7, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "role", "created_at", "version"}))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Act
+	user, err := repo.Update(7, &models.UpdateUserRequest{Name: &name, ExpectedVersion: &version})
+
+	// Assert
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, models.ErrUserVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUserRepository_Create_ActiveEmailViolationIsFriendly, aktif email unique index ihlalinin "bu email zaten kullanılıyor" hatasına çevrildiğini test eder.
+func TestUserRepository_Create_ActiveEmailViolationIsFriendly(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: activeEmailIndex})
+
+	// Act
+	user, err := repo.Create(&models.CreateUserRequest{Name: "Ali", Email: "ali@example.com", Password: "hash", Role: "user"})
+
+	// Assert
+	assert.Nil(t, user)
+	assert.ErrorIs(t, err, models.ErrEmailAlreadyInUse)
+	assert.Equal(t, "bu email zaten kullanılıyor", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUserRepository_Create_OtherUniqueViolationIsWrapped, başka bir unique index ihlalinin email hatasına çevrilmediğini test eder.
+func TestUserRepository_Create_OtherUniqueViolationIsWrapped(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	mock.ExpectQuery("INSERT INTO users").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_users_account_number"})
+
+	// Act
+	_, err = repo.Create(&models.CreateUserRequest{Name: "Ali", Email: "ali@example.com", Password: "hash", Role: "user"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, models.ErrEmailAlreadyInUse)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// accountNumberIndex hesap numarası unique index adı (çakışmada yeniden üretmek için)
+const accountNumberIndex = "idx_users_account_number"
+
+// AccountNumberConfig hesap numarası (IBAN benzeri) üretim ayarları
+type AccountNumberConfig struct {
+	CountryCode   string // 2 harfli ülke kodu
+	BankCode      string // BBAN'ın sabit başlangıcı (banka kodu + rezerv alan)
+	AccountDigits int    // Rastgele üretilen hesap hanesi sayısı
+	MaxAttempts   int    // Unique çakışmasında maksimum üretim denemesi
+}
+
+// DefaultAccountNumberConfig varsayılan hesap numarası ayarları (TR IBAN uzunluğu: 26)
+func DefaultAccountNumberConfig() *AccountNumberConfig {
+	return &AccountNumberConfig{
+		CountryCode:   "TR",
+		BankCode:      "000990",
+		AccountDigits: 16,
+		MaxAttempts:   3,
+	}
+}
+
+// GenerateAccountNumber config'e göre rastgele, mod-97 checksum'lı yeni hesap numarası üretir
+func GenerateAccountNumber(config *AccountNumberConfig) (string, error) {
+	if config == nil {
+		config = DefaultAccountNumberConfig()
+	}
+
+	digits := make([]byte, config.AccountDigits)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("hesap numarası üretilemedi: %w", err)
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+
+	countryCode := models.NormalizeAccountNumber(config.CountryCode)
+	bban := models.NormalizeAccountNumber(config.BankCode) + string(digits)
+
+	checkDigits, err := models.AccountNumberCheckDigits(countryCode, bban)
+	if err != nil {
+		return "", fmt.Errorf("hesap numarası kontrol basamağı hesaplanamadı: %w", err)
+	}
+
+	accountNumber := countryCode + checkDigits + bban
+	if err := models.ValidateAccountNumber(accountNumber); err != nil {
+		return "", fmt.Errorf("hesap numarası ayarları geçersiz: %w", err)
+	}
+
+	return accountNumber, nil
+}
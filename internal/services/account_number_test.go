@@ -0,0 +1,124 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestGenerateAccountNumber_ValidChecksum, üretilen hesap numarasının format ve checksum'ının geçerli olduğunu test eder.
+func TestGenerateAccountNumber_ValidChecksum(t *testing.T) {
+	// Arrange
+	config := DefaultAccountNumberConfig()
+
+	for i := 0; i < 50; i++ {
+		// Act
+		accountNumber, err := GenerateAccountNumber(config)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, accountNumber, 26)
+		assert.True(t, strings.HasPrefix(accountNumber, "TR"))
+		assert.Equal(t, "000990", accountNumber[4:10])
+		assert.NoError(t, models.ValidateAccountNumber(accountNumber))
+	}
+}
+
+// TestValidateAccountNumber, bilinen geçerli numaraların kabul edildiğini ve yazım hatalarının yakalandığını test eder.
+func TestValidateAccountNumber(t *testing.T) {
+	tests := []struct {
+		name          string
+		accountNumber string
+		wantErr       bool
+	}{
+		{"geçerli IBAN örneği", "GB82 WEST 1234 5698 7654 32", false},
+		{"küçük harf ve boşluk", "gb82west12345698765432", false},
+		{"tek hane hatası", "GB82 WEST 1234 5698 7654 33", true},
+		{"yer değiştirmiş haneler", "GB82 WEST 1234 5698 7645 32", true},
+		{"çok kısa", "TR12", true},
+		{"ülke kodu yok", "1282WEST12345698765432", true},
+		{"geçersiz karakter", "GB82 WEST 1234 5698 7654 3!", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := models.ValidateAccountNumber(tt.accountNumber)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestTransactionService_ResolveAccountNumber, geçerli hesap numarasının kullanıcı ID'sine çözümlendiğini test eder.
+func TestTransactionService_ResolveAccountNumber(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), nil)
+
+	accountNumber, err := GenerateAccountNumber(DefaultAccountNumberConfig())
+	require.NoError(t, err)
+	mockUserRepo.On("GetByAccountNumber", accountNumber).Return(&models.User{ID: 42, AccountNumber: &accountNumber}, nil)
+
+	// Act: kullanıcı numarayı gruplanmış ve küçük harfle girse de çözümlenir
+	spaced := strings.ToLower(accountNumber[:4] + " " + accountNumber[4:])
+	userID, err := transactionService.ResolveAccountNumber(spaced)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 42, userID)
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestTransactionService_ResolveAccountNumber_InvalidChecksumSkipsLookup, checksum hatalı numarada DB'ye gidilmediğini test eder.
+func TestTransactionService_ResolveAccountNumber_InvalidChecksumSkipsLookup(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), nil)
+
+	// Act
+	_, err := transactionService.ResolveAccountNumber("GB82 WEST 1234 5698 7654 33")
+
+	// Assert
+	assert.EqualError(t, err, "hesap numarası geçersiz (checksum hatası)")
+	mockUserRepo.AssertNotCalled(t, "GetByAccountNumber", mock.Anything)
+}
+
+// TestUserService_Register_RetriesOnAccountNumberCollision, hesap numarası çakışmasında yeni numara ile tekrar denendiğini test eder.
+func TestUserService_Register_RetriesOnAccountNumberCollision(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+
+	req := &models.CreateUserRequest{
+		Name:            "Test User",
+		Email:           "test@example.com",
+		Password:        "Password123",
+		ConfirmPassword: "Password123",
+	}
+
+	collision := &pq.Error{Code: "23505", Constraint: accountNumberIndex}
+	mockRepo.On("GetByEmail", req.Email).Return(nil, assert.AnError)
+	mockRepo.On("Create", mock.AnythingOfType("*models.CreateUserRequest")).Return((*models.User)(nil), collision).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*models.CreateUserRequest")).Return(&models.User{ID: 1}, nil).Once()
+
+	// Act
+	user, err := userService.Register(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, user.ID)
+	assert.NoError(t, models.ValidateAccountNumber(req.AccountNumber))
+	mockRepo.AssertNumberOfCalls(t, "Create", 2)
+}
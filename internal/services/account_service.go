@@ -0,0 +1,171 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/auth"
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// ErrAccountHasOpenItems hesabın tarafı olduğu sonuçlanmamış hold, zamanlanmış transfer veya incelemedeki transfer varken kapatma istendiğinde döner
+var ErrAccountHasOpenItems = errors.New("hesapta sonuçlanmamış işlemler var")
+
+// closeAccountDescription kalan bakiye aktarımının transaction açıklaması
+const closeAccountDescription = "Hesap kapatma aktarımı"
+
+// AccountService hesap yaşam döngüsü (kapatma vb.) business logic'i
+type AccountService struct {
+	database    *sql.DB
+	retryConfig *db.RetryConfig // Deadlock/serialization hatalarında tekrar ayarları
+}
+
+// NewAccountService yeni account service oluşturur
+func NewAccountService(database *sql.DB) *AccountService {
+	return &AccountService{database: database, retryConfig: db.DefaultRetryConfig()}
+}
+
+// SetRetryConfig deadlock/serialization tekrar ayarlarını değiştirir (nil ise tekrar yapılmaz)
+func (s *AccountService) SetRetryConfig(config *db.RetryConfig) {
+	if config == nil {
+		config = &db.RetryConfig{}
+	}
+	s.retryConfig = config
+}
+
+// CloseAccount kullanıcının hesabını kapatır
+// Bakiye sıfır değilse kalan tutar belirtilen hesaba aktarılır; hepsi tek DB transaction'ında yapılır.
+// Hesabın tarafı olduğu aktif hold, zamanlanmış veya incelemedeki transfer varken hesap kapatılmaz.
+func (s *AccountService) CloseAccount(userID int, req *models.CloseAccountRequest) (*models.CloseAccountResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if req.TransferToUserID != nil && *req.TransferToUserID == userID {
+		return nil, fmt.Errorf("kalan bakiye kapatılan hesaba aktarılamaz")
+	}
+
+	var result *models.CloseAccountResult
+
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+		result = &models.CloseAccountResult{UserID: userID}
+
+		// 1. Bakiyeyi lock et; aktarım hesabı varsa transferlerle aynı sırada (küçük user_id önce) ikisi birlikte
+		var balance, targetBalance float64
+		var transaction *models.Transaction
+		if req.TransferToUserID != nil {
+			targetUserID := *req.TransferToUserID
+
+			var targetExists bool
+			err := txRepo.QueryRow(`
+				SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)
+			`, targetUserID).Scan(&targetExists)
+			if err != nil {
+				return fmt.Errorf("aktarım hesabı sorgusu hatası: %w", err)
+			}
+			if !targetExists {
+				return fmt.Errorf("aktarım hesabı bulunamadı")
+			}
+
+			transaction = models.NewTransferTransaction(userID, targetUserID, 0, closeAccountDescription)
+			balance, targetBalance, err = lockTransferBalances(txRepo, userID, targetUserID, 0, transaction, false)
+			if err != nil {
+				return err
+			}
+		} else {
+			err := txRepo.QueryRow(`
+				SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE
+			`, userID).Scan(&balance)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("bakiye sorgusu hatası: %w", err)
+			}
+		}
+
+		if balance > 0 && transaction == nil {
+			return fmt.Errorf("bakiye sıfır değil (%.2f TL). Hesabı kapatmak için aktarım hesabı belirtin", balance)
+		}
+
+		// 2. Kapatmadan sonra capture/onay/çalıştırma anında kapalı hesaba dokunacak işlemler
+		if err := checkNoOpenItems(txRepo, userID); err != nil {
+			return err
+		}
+
+		// 3. Kalan bakiye varsa hedef hesaba aktar
+		if balance > 0 {
+			transaction.Amount = balance
+
+			var transactionID int
+			err := txRepo.QueryRow(`
+				INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING id
+			`, userID, transaction.ToUserID, balance, transaction.Type, transaction.Status, transaction.Description).Scan(&transactionID)
+			if err != nil {
+				return fmt.Errorf("aktarım kaydı oluşturulamadı: %w", err)
+			}
+			if err := applyTransfer(txRepo, userID, *transaction.ToUserID, balance, balance, targetBalance, transactionID, transaction); err != nil {
+				return err
+			}
+
+			result.TransferredAmount = balance
+			result.TransferToUserID = req.TransferToUserID
+			result.TransactionID = &transactionID
+		}
+
+		// 4. Kullanıcıyı soft delete yap
+		res, err := txRepo.Exec(`
+			UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL
+		`, userID)
+		if err != nil {
+			return fmt.Errorf("hesap kapatılamadı: %w", err)
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("kapatma sonucu kontrol edilemedi: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("kullanıcı bulunamadı veya hesap zaten kapatılmış")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit sonrası mevcut token'ları iptal et
+	auth.RevokeUserTokens(userID)
+
+	log.Info().
+		Int("user_id", userID).
+		Float64("transferred_amount", result.TransferredAmount).
+		Msg("Hesap kapatıldı")
+
+	return result, nil
+}
+
+// checkNoOpenItems hesabın tarafı olduğu aktif hold, zamanlanmış transfer veya incelemedeki transfer varsa kapatmayı reddeder
+func checkNoOpenItems(txRepo *db.TransactionRepository, userID int) error {
+	var holds, scheduled, reviews int
+	err := txRepo.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM balance_holds WHERE (user_id = $1 OR to_user_id = $1) AND status = $2),
+			(SELECT COUNT(*) FROM scheduled_transfers WHERE (from_user_id = $1 OR to_user_id = $1) AND status IN ($3, $4)),
+			(SELECT COUNT(*) FROM transactions WHERE (from_user_id = $1 OR to_user_id = $1) AND status = $5)
+	`, userID, models.HoldStatusActive, models.ScheduledStatusScheduled, models.ScheduledStatusProcessing, models.StatusPendingReview).
+		Scan(&holds, &scheduled, &reviews)
+	if err != nil {
+		return fmt.Errorf("açık işlemler kontrol edilemedi: %w", err)
+	}
+
+	if holds+scheduled+reviews > 0 {
+		return fmt.Errorf("%w: %d aktif hold, %d zamanlanmış transfer, %d incelemede transfer. Hesabı kapatmadan önce iptal edin veya sonuçlanmasını bekleyin",
+			ErrAccountHasOpenItems, holds, scheduled, reviews)
+	}
+	return nil
+}
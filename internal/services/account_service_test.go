@@ -0,0 +1,208 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/auth"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestAccountService_CloseAccount_NonZeroBalanceWithoutTarget, bakiyesi olan ve aktarım hesabı belirtmeyen kullanıcının reddedildiğini test eder.
+func TestAccountService_CloseAccount_NonZeroBalanceWithoutTarget(t *testing.T) {
+	// Arrange
+	database, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(250.0))
+	mock.ExpectRollback()
+
+	accountService := NewAccountService(database)
+
+	// Act
+	result, err := accountService.CloseAccount(userID, &models.CloseAccountRequest{})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "bakiye sıfır değil (250.00 TL). Hesabı kapatmak için aktarım hesabı belirtin")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAccountService_CloseAccount_CleanClose, sıfır bakiyeli hesabın kapatılıp token'larının iptal edildiğini test eder.
+func TestAccountService_CloseAccount_CleanClose(t *testing.T) {
+	// Arrange
+	database, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID := 11
+	assert.NoError(t, auth.Configure([]byte("account-service-test-secret-0123456789")))
+	token, err := auth.GenerateToken(userID, "kapat@example.com", "user")
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	expectOpenItems(mock, userID, 0, 0, 0)
+	mock.ExpectExec("UPDATE users SET deleted_at").
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	accountService := NewAccountService(database)
+
+	// Act
+	result, err := accountService.CloseAccount(userID, &models.CloseAccountRequest{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, userID, result.UserID)
+	assert.Zero(t, result.TransferredAmount)
+	assert.Nil(t, result.TransferToUserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = auth.ValidateToken(token)
+	assert.Error(t, err, "kapatılan hesabın token'ı geçersiz olmalı")
+}
+
+// expectOpenItems hesabın tarafı olduğu aktif hold / zamanlanmış / incelemedeki transfer sayılarını mock'lar
+func expectOpenItems(mock sqlmock.Sqlmock, userID, holds, scheduled, reviews int) {
+	mock.ExpectQuery("FROM balance_holds").
+		WithArgs(userID, models.HoldStatusActive, models.ScheduledStatusScheduled, models.ScheduledStatusProcessing, models.StatusPendingReview).
+		WillReturnRows(sqlmock.NewRows([]string{"holds", "scheduled", "reviews"}).AddRow(holds, scheduled, reviews))
+}
+
+// TestAccountService_CloseAccount_TransfersRemainingBalance, kalan bakiyenin hedef hesaba aktarılıp hesabın kapatıldığını test eder.
+func TestAccountService_CloseAccount_TransfersRemainingBalance(t *testing.T) {
+	// Arrange
+	database, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID, targetUserID := 12, 20
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(targetUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(75.5))
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(targetUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(10.0))
+	expectOpenItems(mock, userID, 0, 0, 0)
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(userID, targetUserID, 75.5, models.TypeTransfer, models.StatusPending, "Hesap kapatma aktarımı").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+	mock.ExpectExec("UPDATE balances").
+		WithArgs(0.0, userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO balance_history").
+		WithArgs(userID, 75.5, 0.0, -75.5, models.BalanceReasonTransferOut, 99).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE balances").
+		WithArgs(85.5, targetUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO balance_history").
+		WithArgs(targetUserID, 10.0, 85.5, 75.5, models.BalanceReasonTransferIn, 99).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("UPDATE transactions SET status").
+		WithArgs(models.StatusCompleted, 99).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET deleted_at").
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	accountService := NewAccountService(database)
+
+	// Act
+	result, err := accountService.CloseAccount(userID, &models.CloseAccountRequest{TransferToUserID: &targetUserID})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 75.5, result.TransferredAmount)
+	assert.Equal(t, targetUserID, *result.TransferToUserID)
+	assert.Equal(t, 99, *result.TransactionID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAccountService_CloseAccount_LocksLowerUserIDFirst, aktarım hesabının ID'si küçükse bakiyelerin transferlerle aynı
+// sırada (önce aktarım hesabı) kilitlendiğini test eder; ters sıra eşzamanlı bir transferle deadlock'a girerdi.
+func TestAccountService_CloseAccount_LocksLowerUserIDFirst(t *testing.T) {
+	// Arrange
+	database, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID, targetUserID := 30, 5
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(targetUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(targetUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(10.0))
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	expectOpenItems(mock, userID, 0, 0, 0)
+	mock.ExpectExec("UPDATE users SET deleted_at").
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	accountService := NewAccountService(database)
+
+	// Act
+	result, err := accountService.CloseAccount(userID, &models.CloseAccountRequest{TransferToUserID: &targetUserID})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Zero(t, result.TransferredAmount)
+	assert.Nil(t, result.TransactionID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAccountService_CloseAccount_RejectsWithOpenItems, aktif hold veya zamanlanmış transferi olan hesabın bakiye
+// aktarılmadan ve silinmeden reddedildiğini test eder.
+func TestAccountService_CloseAccount_RejectsWithOpenItems(t *testing.T) {
+	// Arrange
+	database, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID, targetUserID := 12, 20
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(targetUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(75.5))
+	mock.ExpectQuery("SELECT amount FROM balances").
+		WithArgs(targetUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(10.0))
+	expectOpenItems(mock, userID, 1, 2, 0)
+	mock.ExpectRollback()
+
+	accountService := NewAccountService(database)
+
+	// Act
+	result, err := accountService.CloseAccount(userID, &models.CloseAccountRequest{TransferToUserID: &targetUserID})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrAccountHasOpenItems)
+	assert.EqualError(t, err, "hesapta sonuçlanmamış işlemler var: 1 aktif hold, 2 zamanlanmış transfer, 0 incelemede transfer. "+
+		"Hesabı kapatmadan önce iptal edin veya sonuçlanmasını bekleyin")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// BalanceArchiveConfig balance_history arşivleme ayarları
+type BalanceArchiveConfig struct {
+	Retention time.Duration // Bu süreden eski geçmiş arşive taşınır
+	Interval  time.Duration // Arşivleme job'ının çalışma sıklığı
+}
+
+// DefaultBalanceArchiveConfig varsayılan arşivleme ayarları (1 yıl saklama, günde bir çalışma)
+func DefaultBalanceArchiveConfig() *BalanceArchiveConfig {
+	return &BalanceArchiveConfig{
+		Retention: 365 * 24 * time.Hour,
+		Interval:  24 * time.Hour,
+	}
+}
+
+// ArchiveBalanceHistory retention süresinden eski bakiye geçmişini arşivler
+func ArchiveBalanceHistory(repo interfaces.BalanceRepositoryInterface, config *BalanceArchiveConfig, now time.Time) (*models.BalanceArchiveResult, error) {
+	if config == nil {
+		config = DefaultBalanceArchiveConfig()
+	}
+	if config.Retention <= 0 {
+		return nil, fmt.Errorf("geçersiz arşiv saklama süresi: %s", config.Retention)
+	}
+
+	result, err := repo.ArchiveBalanceHistory(now.Add(-config.Retention))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int("archived_rows", result.ArchivedRows).
+		Int("baselines", result.Baselines).
+		Time("before", result.Before).
+		Msg("Bakiye geçmişi arşivlendi")
+
+	return result, nil
+}
+
+// StartBalanceHistoryArchiver arşivlemeyi hemen ve periyodik olarak çalıştırır
+func StartBalanceHistoryArchiver(ctx context.Context, repo interfaces.BalanceRepositoryInterface, config *BalanceArchiveConfig) {
+	if config == nil {
+		config = DefaultBalanceArchiveConfig()
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultBalanceArchiveConfig().Interval
+	}
+
+	go func() {
+		run := func() {
+			if _, err := ArchiveBalanceHistory(repo, config, time.Now()); err != nil {
+				log.Error().Err(err).Msg("Bakiye geçmişi arşivleme başarısız")
+			}
+		}
+
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Balance history archiver stopped")
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestArchiveBalanceHistory_UsesRetentionCutoff, arşiv sınırının now - retention olarak hesaplandığını test eder.
+func TestArchiveBalanceHistory_UsesRetentionCutoff(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBalanceRepository)
+	now := time.Date(2025, 8, 15, 3, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-30 * 24 * time.Hour)
+	expected := &models.BalanceArchiveResult{ArchivedRows: 12, Baselines: 2, Before: cutoff}
+
+	mockRepo.On("ArchiveBalanceHistory", cutoff).Return(expected, nil)
+
+	// Act
+	result, err := ArchiveBalanceHistory(mockRepo, &BalanceArchiveConfig{Retention: 30 * 24 * time.Hour}, now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestArchiveBalanceHistory_RejectsNonPositiveRetention, geçersiz saklama süresinde repository'nin çağrılmadığını test eder.
+func TestArchiveBalanceHistory_RejectsNonPositiveRetention(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBalanceRepository)
+
+	// Act
+	result, err := ArchiveBalanceHistory(mockRepo, &BalanceArchiveConfig{Retention: 0}, time.Now())
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "ArchiveBalanceHistory")
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// BaseCurrency bakiyelerin tutulduğu (native) para birimi
+const BaseCurrency = "TRY"
+
+// MaxDisplayCurrencies tek istekte gösterilebilecek en fazla para birimi
+const MaxDisplayCurrencies = 10
+
+var (
+	// ErrUnsupportedCurrency gösterim için istenen para birimi geçersiz veya desteklenmiyorsa döner
+	ErrUnsupportedCurrency = errors.New("desteklenmeyen para birimi")
+	// ErrExchangeRateUnavailable desteklenen para biriminin kuru alınamadığında döner
+	ErrExchangeRateUnavailable = errors.New("kur bilgisi alınamadı")
+)
+
+// SetCurrencyDisplay bakiyenin diğer para birimlerinde gösterimi için kur sağlayıcısını ve izin verilen birimleri ayarlar.
+// provider nil ise gösterim kapalıdır.
+func (s *BalanceService) SetCurrencyDisplay(provider interfaces.ExchangeRateProvider, currencies []string) {
+	allowed := make(map[string]bool, len(currencies))
+	for _, currency := range currencies {
+		if code, err := normalizeCurrency(currency); err == nil {
+			allowed[code] = true
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rateProvider = provider
+	s.displayCurrencies = allowed
+}
+
+// GetBalanceInCurrencies kesinleşmiş bakiyeyi istenen para birimlerine çevirir.
+// Dönüşümler gösterge niteliğindedir; bakiye ve işlemler BaseCurrency cinsinden kalır.
+func (s *BalanceService) GetBalanceInCurrencies(userID int, targets []string) (*models.BalanceDisplay, error) {
+	s.mutex.RLock()
+	provider, allowed := s.rateProvider, s.displayCurrencies
+	s.mutex.RUnlock()
+
+	currencies, err := parseDisplayCurrencies(targets, allowed, provider != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.GetBalance(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	display := &models.BalanceDisplay{
+		UserID:        balance.UserID,
+		Currency:      BaseCurrency,
+		Amount:        balance.Amount,
+		Converted:     make([]models.ConvertedBalance, 0, len(currencies)),
+		Indicative:    true,
+		LastUpdatedAt: balance.LastUpdatedAt,
+	}
+	for _, currency := range currencies {
+		rate := 1.0
+		if currency != BaseCurrency {
+			rate, err = provider.Rate(BaseCurrency, currency)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s/%s: %v", ErrExchangeRateUnavailable, BaseCurrency, currency, err)
+			}
+		}
+		display.Converted = append(display.Converted, models.ConvertedBalance{
+			Currency: currency,
+			Amount:   convertWithRate(balance.Amount, rate),
+			Rate:     rate,
+		})
+	}
+
+	return display, nil
+}
+
+// parseDisplayCurrencies istenen para birimlerini normalize eder, tekrarları atar ve izin listesine göre doğrular
+func parseDisplayCurrencies(targets []string, allowed map[string]bool, enabled bool) ([]string, error) {
+	if !enabled {
+		return nil, fmt.Errorf("%w: döviz gösterimi yapılandırılmamış", ErrUnsupportedCurrency)
+	}
+
+	var currencies []string
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if strings.TrimSpace(target) == "" {
+			continue
+		}
+		code, err := normalizeCurrency(target)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, strings.TrimSpace(target))
+		}
+		if code != BaseCurrency && !allowed[code] {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			currencies = append(currencies, code)
+		}
+	}
+
+	if len(currencies) == 0 {
+		return nil, fmt.Errorf("%w: en az bir para birimi belirtilmelidir", ErrUnsupportedCurrency)
+	}
+	if len(currencies) > MaxDisplayCurrencies {
+		return nil, fmt.Errorf("%w: en fazla %d para birimi istenebilir", ErrUnsupportedCurrency, MaxDisplayCurrencies)
+	}
+	return currencies, nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/features"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+var (
+	// ErrHoldsDisabled "holds" feature flag'i kapalıyken authorize/capture/void çağrıldığında döner
+	ErrHoldsDisabled = errors.New("hold (bloke) özelliği bu ortamda kapalı")
+	// ErrHoldNotFound hold bulunamadığında veya kullanıcı hold'un tarafı olmadığında döner
+	ErrHoldNotFound = errors.New("hold bulunamadı")
+	// ErrHoldForbidden ödeyen taraf kendi hold'unu capture/void etmeye çalıştığında döner (yalnızca alıcı veya admin yapabilir)
+	ErrHoldForbidden = errors.New("bu hold için capture/void yetkiniz yok")
+	// ErrHoldNotActive capture/void edilmiş hold tekrar kullanılmak istendiğinde döner
+	ErrHoldNotActive = errors.New("hold aktif değil")
+	// ErrCaptureExceedsHold capture tutarı hold tutarından büyük olduğunda döner
+	ErrCaptureExceedsHold = errors.New("capture tutarı hold tutarını aşamaz")
+)
+
+// heldAmount kullanıcının aktif hold toplamını DB transaction'ı içinde döner ("holds" flag'i kapalıysa sorgu yapılmaz)
+func heldAmount(txRepo *db.TransactionRepository, userID int) (float64, error) {
+	if !features.Enabled(features.Holds) {
+		return 0, nil
+	}
+
+	var held float64
+	err := txRepo.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM balance_holds WHERE user_id = $1 AND status = $2
+	`, userID, models.HoldStatusActive).Scan(&held)
+	if err != nil {
+		return 0, fmt.Errorf("aktif hold toplamı alınamadı: %w", err)
+	}
+	return held, nil
+}
+
+// rowQuerier tek satır sorgusu yapabilen DB veya DB transaction'ı (*sql.DB, *db.TransactionRepository)
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// dailyHoldUsage kullanıcının since'ten beri oluşturduğu aktif hold sayısını ve toplamını döner ("holds" flag'i kapalıysa
+// sorgu yapılmaz). Hold capture'da günlük limitlere tekrar takılmadığından aktif hold'lar günlük kullanıma sayılır.
+func dailyHoldUsage(querier rowQuerier, userID int, since time.Time) (int, float64, error) {
+	if !features.Enabled(features.Holds) {
+		return 0, 0, nil
+	}
+
+	var count int
+	var held float64
+	err := querier.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM balance_holds WHERE user_id = $1 AND status = $2 AND created_at >= $3
+	`, userID, models.HoldStatusActive, since).Scan(&count, &held)
+	if err != nil {
+		return 0, 0, fmt.Errorf("günlük hold kullanımı alınamadı: %w", err)
+	}
+	return count, held, nil
+}
+
+// AuthorizeHold checkout'ta kullanıcının bakiyesinden tutarı bloke eder (bakiye hareket etmez, kullanılabilir bakiye düşer).
+// Hold capture ile incelemesiz transfere dönüştüğünden transferin tüm kontrolleri authorize anında uygulanır.
+func (s *TransactionService) AuthorizeHold(ctx context.Context, userID int, req *models.AuthorizeHoldRequest) (*models.BalanceHold, error) {
+	if !features.Enabled(features.Holds) {
+		return nil, ErrHoldsDisabled
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Tutar aralığı, açıklama zorunluluğu ve kendine transfer kontrolü transferle aynıdır
+	transferReq := &models.TransferRequest{ToUserID: req.ToUserID, Amount: req.Amount, Description: req.Description}
+	transaction, err := s.prepareTransfer(userID, transferReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Yeni hesaplar kayıttan sonraki bekleme süresinde hold da oluşturamaz
+	if err := s.checkNewAccountCooldown(userID); err != nil {
+		return nil, err
+	}
+
+	limits, err := s.dailyLimitsFor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasDescription(req.Description) {
+		if err := s.checkMemoTransferRate(userID, req.ToUserID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fraud incelemesine takılacak transfer hold ile bloke edilemez; capture incelemeyi atlamış olurdu
+	reason, err := s.reviewReason(userID, transferReq)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return nil, fmt.Errorf("%w (%s)", ErrTransferRequiresReview, reason)
+	}
+
+	hold := &models.BalanceHold{
+		UserID:      userID,
+		ToUserID:    req.ToUserID,
+		Amount:      req.Amount,
+		Status:      models.HoldStatusActive,
+		Description: req.Description,
+	}
+
+	err = db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+
+		// Bakiye lock'lanır: eşzamanlı transfer veya hold aynı tutarı tekrar kullanamaz
+		var balance float64
+		err := txRepo.QueryRow(`SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE`, userID).Scan(&balance)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("kullanıcının bakiyesi bulunamadı")
+		}
+		if err != nil {
+			return fmt.Errorf("bakiye sorgusu hatası: %w", err)
+		}
+
+		held, err := heldAmount(txRepo, userID)
+		if err != nil {
+			return err
+		}
+		if balance-held < req.Amount {
+			return newInsufficientBalanceError(balance-held, req.Amount)
+		}
+		// Capture minimum bakiyeyi uygular; authorize'da kontrol edilmezse hold hiç capture edilemezdi
+		if err := checkMinimumBalance(txRepo, userID, balance-held, req.Amount, transaction); err != nil {
+			return err
+		}
+		// Hold günlük sayı ve giden tutar limitlerine authorize anında sayılır; capture ayrıca kontrol edilmez
+		if err := checkDailyCount(txRepo, userID, limits.count, transaction); err != nil {
+			return err
+		}
+		if err := checkDailyOutbound(txRepo, userID, req.Amount, limits.amount, transaction); err != nil {
+			return err
+		}
+
+		err = txRepo.QueryRow(`
+			INSERT INTO balance_holds (user_id, to_user_id, amount, status, description)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+			RETURNING id, created_at
+		`, userID, req.ToUserID, req.Amount, hold.Status, req.Description).Scan(&hold.ID, &hold.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("hold oluşturulamadı: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// CaptureHold aktif hold'un tamamını veya bir kısmını alıcıya transfer eder.
+// Hold tek seferde kapatılır: kısmi capture'da kalan tutar serbest bırakılır.
+// asAdmin false ise yalnızca hold'un alıcısı (ör. merchant) capture edebilir.
+func (s *TransactionService) CaptureHold(ctx context.Context, userID int, asAdmin bool, req *models.CaptureHoldRequest) (*models.CaptureHoldResponse, error) {
+	if !features.Enabled(features.Holds) {
+		return nil, ErrHoldsDisabled
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var hold *models.BalanceHold
+	var transaction *models.Transaction
+	var releaseSlot func()
+	startedAt := time.Now()
+
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+
+		var err error
+		hold, err = lockBalanceHold(txRepo, req.HoldID, userID, asAdmin)
+		if err != nil {
+			return err
+		}
+		if !hold.IsActive() {
+			return fmt.Errorf("%w: %s", ErrHoldNotActive, hold.Status)
+		}
+
+		// Ödeyenin ardışık transferleri arası minimum süre capture'da da uygulanır (tekrar denemede yeniden ayrılmaz)
+		if releaseSlot == nil {
+			if releaseSlot, err = s.reserveTransferSlot(hold.UserID); err != nil {
+				return err
+			}
+		}
+
+		amount := hold.Amount
+		if req.Amount != nil {
+			if *req.Amount > hold.Amount {
+				return fmt.Errorf("%w (%.2f TL)", ErrCaptureExceedsHold, hold.Amount)
+			}
+			amount = *req.Amount
+		}
+
+		// Hold transferden önce kapatılır; aksi halde capture edilen tutar bakiye kontrolünde bloke sayılırdı
+		_, err = txRepo.Exec(`
+			UPDATE balance_holds SET status = $1, captured_amount = $2, updated_at = NOW() WHERE id = $3
+		`, models.HoldStatusCaptured, amount, hold.ID)
+		if err != nil {
+			return fmt.Errorf("hold güncellenemedi: %w", err)
+		}
+
+		transferReq := &models.TransferRequest{ToUserID: hold.ToUserID, Amount: amount, Description: hold.Description}
+		transaction = models.NewTransferTransaction(hold.UserID, hold.ToUserID, amount, hold.Description)
+		transaction.Channel = requestChannel(ctx)
+		// Hold authorize anında günlük sayı ve tutar limitlerine sayıldı; capture limitlere tekrar takılmaz
+		if err := executeTransfer(txRepo, hold.UserID, transferReq, transaction, dailyLimits{}); err != nil {
+			return err
+		}
+
+		_, err = txRepo.Exec(`UPDATE balance_holds SET transaction_id = $1 WHERE id = $2`, transaction.ID, hold.ID)
+		if err != nil {
+			return fmt.Errorf("hold transfer bağlantısı kaydedilemedi: %w", err)
+		}
+
+		hold.Status = models.HoldStatusCaptured
+		hold.CapturedAmount = amount
+		hold.TransactionID = &transaction.ID
+		return nil
+	})
+
+	if transaction != nil {
+		logTransactionCreated(ctx, transaction, startedAt, err)
+		s.fireStatusHooks(ctx, models.StatusPending, transaction, err)
+	}
+	if err != nil {
+		if releaseSlot != nil {
+			releaseSlot()
+		}
+		return nil, err
+	}
+
+	return &models.CaptureHoldResponse{
+		Hold:        hold,
+		Transaction: transaction,
+		Released:    hold.ReleasedAmount(),
+	}, nil
+}
+
+// VoidHold aktif hold'u capture etmeden serbest bırakır (bakiye hareket etmez).
+// asAdmin false ise yalnızca hold'un alıcısı (ör. merchant) void edebilir.
+func (s *TransactionService) VoidHold(ctx context.Context, userID int, asAdmin bool, req *models.VoidHoldRequest) (*models.BalanceHold, error) {
+	if !features.Enabled(features.Holds) {
+		return nil, ErrHoldsDisabled
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var hold *models.BalanceHold
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+
+		var err error
+		hold, err = lockBalanceHold(txRepo, req.HoldID, userID, asAdmin)
+		if err != nil {
+			return err
+		}
+		if !hold.IsActive() {
+			return fmt.Errorf("%w: %s", ErrHoldNotActive, hold.Status)
+		}
+
+		_, err = txRepo.Exec(`UPDATE balance_holds SET status = $1, updated_at = NOW() WHERE id = $2`, models.HoldStatusVoided, hold.ID)
+		if err != nil {
+			return fmt.Errorf("hold güncellenemedi: %w", err)
+		}
+
+		hold.Status = models.HoldStatusVoided
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// lockBalanceHold hold'u FOR UPDATE ile kilitleyerek okur. Admin değilse yalnızca alıcı devam edebilir:
+// ödeyen ErrHoldForbidden, hold'un tarafı olmayan kullanıcı bulunamadı alır.
+func lockBalanceHold(txRepo *db.TransactionRepository, holdID, userID int, asAdmin bool) (*models.BalanceHold, error) {
+	hold := &models.BalanceHold{}
+	var description sql.NullString
+	var transactionID sql.NullInt64
+
+	err := txRepo.QueryRow(`
+		SELECT id, user_id, to_user_id, amount, captured_amount, status, description, transaction_id, created_at
+		FROM balance_holds
+		WHERE id = $1
+		FOR UPDATE
+	`, holdID).Scan(&hold.ID, &hold.UserID, &hold.ToUserID, &hold.Amount, &hold.CapturedAmount, &hold.Status,
+		&description, &transactionID, &hold.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("hold alınamadı: %w", err)
+	}
+
+	if !asAdmin && hold.ToUserID != userID {
+		// Başka kullanıcıların hold'larının varlığı sızdırılmaz
+		if hold.UserID != userID {
+			return nil, ErrHoldNotFound
+		}
+		// Ödeyen bloke tutarı kendi başına serbest bırakamaz; capture/void satıcının kararıdır
+		return nil, ErrHoldForbidden
+	}
+
+	hold.Description = description.String
+	if transactionID.Valid {
+		id := int(transactionID.Int64)
+		hold.TransactionID = &id
+	}
+	return hold, nil
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/features"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// holdColumns lockBalanceHold'un okuduğu kolonlar
+var holdColumns = []string{"id", "user_id", "to_user_id", "amount", "captured_amount", "status", "description", "transaction_id", "created_at"}
+
+// expectDailyHolds günlük limit kontrolünde okunan bugünkü aktif hold sayısını ve toplamını mock'lar
+func expectDailyHolds(dbMock sqlmock.Sqlmock, userID int, count int, held float64) {
+	dbMock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE\\(SUM\\(amount\\), 0\\) FROM balance_holds").
+		WithArgs(userID, models.HoldStatusActive, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "sum"}).AddRow(count, held))
+}
+
+// TestTransactionService_Hold_AuthorizePartialCaptureVoidRemainder, authorize → kısmi capture akışında kısmi tutarın transfer edildiğini,
+// kalanın serbest bırakıldığını ve kapanmış hold'un tekrar void edilemediğini test eder.
+func TestTransactionService_Hold_AuthorizePartialCaptureVoidRemainder(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// Authorize: 500 TL bakiye, 100 TL'lik başka aktif hold var → 300 TL bloke edilebilir
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(500.0))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("INSERT INTO balance_holds").WithArgs(10, 20, 300.0, models.HoldStatusActive, "Sipariş #42").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+	dbMock.ExpectCommit()
+
+	// Capture: 300 TL'lik hold'un 120 TL'si satıcıya transfer edilir
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(holdColumns).AddRow(7, 10, 20, 300.0, 0.0, models.HoldStatusActive, "Sipariş #42", nil, time.Now()))
+	dbMock.ExpectExec("UPDATE balance_holds SET status").WithArgs(models.HoldStatusCaptured, 120.0, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(500.0))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(55, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(380.0, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(120.0, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("UPDATE balance_holds SET transaction_id").WithArgs(55, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	// Void: kalan tutar capture'da zaten serbest bırakıldı, hold artık aktif değil
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(holdColumns).AddRow(7, 10, 20, 300.0, 120.0, models.HoldStatusCaptured, "Sipariş #42", 55, time.Now()))
+	dbMock.ExpectRollback()
+
+	mockTxRepo := new(MockTransactionRepository)
+	mockTxRepo.On("CountTransfersWithDescriptionSince", 10, 20, mock.AnythingOfType("time.Time")).Return(0, nil)
+	transactionService := NewTransactionService(mockTxRepo, nil, new(MockBalanceService), database)
+	captureAmount := 120.0
+
+	// Act
+	hold, authorizeErr := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 300, Description: "Sipariş #42"})
+	require.NoError(t, authorizeErr)
+	captured, captureErr := transactionService.CaptureHold(context.Background(), 20, false, &models.CaptureHoldRequest{HoldID: hold.ID, Amount: &captureAmount})
+	require.NoError(t, captureErr)
+	voided, voidErr := transactionService.VoidHold(context.Background(), 20, false, &models.VoidHoldRequest{HoldID: hold.ID})
+
+	// Assert
+	assert.Equal(t, 7, hold.ID)
+	assert.Equal(t, models.HoldStatusActive, hold.Status)
+
+	assert.Equal(t, models.HoldStatusCaptured, captured.Hold.Status)
+	assert.Equal(t, 120.0, captured.Hold.CapturedAmount)
+	assert.Equal(t, 180.0, captured.Released)
+	assert.Equal(t, 55, captured.Transaction.ID)
+	assert.Equal(t, models.StatusCompleted, captured.Transaction.Status)
+	assert.Equal(t, 120.0, captured.Transaction.Amount)
+
+	assert.Nil(t, voided)
+	assert.ErrorIs(t, voidErr, ErrHoldNotActive)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Hold_AuthorizeExceedingAvailableAndVoid, aktif hold'lar düşüldükten sonra yetmeyen tutarın reddedildiğini
+// ve aktif hold'un void ile serbest bırakıldığını test eder.
+func TestTransactionService_Hold_AuthorizeExceedingAvailableAndVoid(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(500.0))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(300.0))
+	dbMock.ExpectRollback()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(holdColumns).AddRow(7, 10, 20, 300.0, 0.0, models.HoldStatusActive, nil, nil, time.Now()))
+	dbMock.ExpectExec("UPDATE balance_holds SET status").WithArgs(models.HoldStatusVoided, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	hold, authorizeErr := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 250})
+	voided, voidErr := transactionService.VoidHold(context.Background(), 20, false, &models.VoidHoldRequest{HoldID: 7})
+
+	// Assert
+	assert.Nil(t, hold)
+	assert.ErrorIs(t, authorizeErr, ErrInsufficientBalance)
+	require.NoError(t, voidErr)
+	assert.Equal(t, models.HoldStatusVoided, voided.Status)
+	assert.Equal(t, 300.0, voided.ReleasedAmount())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Hold_AuthorizeCountsTowardDailyLimit, bloke tutarın authorize anında günlük giden limite
+// sayıldığını; aktif hold varken yeni hold'un ve transferin limiti aşamadığını test eder (capture limite tekrar takılmaz).
+func TestTransactionService_Hold_AuthorizeCountsTowardDailyLimit(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+
+	// Bugün 600 TL gönderildi: 300 TL'lik hold limite sığar (600 + 300 ≤ 1000)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(5000.0))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	expectDailyOutboundSum(dbMock, 10, 600)
+	expectDailyHolds(dbMock, 10, 0, 0)
+	dbMock.ExpectQuery("INSERT INTO balance_holds").WithArgs(10, 20, 300.0, models.HoldStatusActive, "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+	dbMock.ExpectCommit()
+
+	// İkinci hold: 600 gönderilen + 300 bloke + 200 > 1000
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(5000.0))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(300.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	expectDailyOutboundSum(dbMock, 10, 600)
+	expectDailyHolds(dbMock, 10, 1, 300)
+	dbMock.ExpectRollback()
+
+	// Transfer: bloke tutar giden toplama eklendiği için aynı şekilde reddedilir
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(5000.0))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(300.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	expectDailyOutboundSum(dbMock, 10, 600)
+	expectDailyHolds(dbMock, 10, 1, 300)
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{DailyAmountLimitDefault: 1000})
+
+	// Act
+	hold, authorizeErr := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 300})
+	secondHold, secondErr := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 200})
+	transfer, transferErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 200})
+
+	// Assert
+	require.NoError(t, authorizeErr)
+	assert.Equal(t, 7, hold.ID)
+	assert.Nil(t, secondHold)
+	assert.ErrorIs(t, secondErr, ErrDailyLimitExceeded)
+	assert.EqualError(t, secondErr, "günlük limit aşıldı: bugün 900/1,000 TL gönderildi")
+	assert.Nil(t, transfer)
+	assert.ErrorIs(t, transferErr, ErrDailyLimitExceeded)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Hold_AuthorizeRejectsNewAccountWithinCooldown, bekleme süresi dolmamış yeni hesabın
+// DB'ye gitmeden hold oluşturamadığını test eder (capture transferin bekleme süresi kontrolünü atlatmamalı).
+func TestTransactionService_Hold_AuthorizeRejectsNewAccountWithinCooldown(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user", CreatedAt: time.Now().Add(-10 * time.Minute)}, nil)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{NewAccountTransferCooldown: time.Hour})
+
+	// Act
+	hold, err := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 50})
+
+	// Assert
+	assert.Nil(t, hold)
+	assert.ErrorIs(t, err, ErrNewAccountTransferCooldown)
+}
+
+// TestTransactionService_Hold_AuthorizeRejectsReviewFlaggedTransfer, fraud incelemesine takılacak tutarın hold ile
+// bloke edilemediğini test eder (capture incelemesiz transfer olurdu).
+func TestTransactionService_Hold_AuthorizeRejectsReviewFlaggedTransfer(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(nil)
+	transactionService.SetReviewRules(AmountReviewRule(5000))
+
+	// Act
+	hold, err := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 6000})
+
+	// Assert
+	assert.Nil(t, hold)
+	assert.ErrorIs(t, err, ErrTransferRequiresReview)
+}
+
+// TestTransactionService_Hold_AuthorizeRespectsMinimumBalance, bakiyeyi minimum bakiyenin altına düşürecek hold'un
+// bakiye kilidi altında reddedildiğini test eder (aksi halde hold her capture denemesinde başarısız olurdu).
+func TestTransactionService_Hold_AuthorizeRespectsMinimumBalance(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// 500 TL bakiye, 100 TL aktif hold, 300 TL minimum: en fazla 100 TL bloke edilebilir
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(500.0))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100.0))
+	expectMinimumBalance(dbMock, 10, 300)
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	// Act
+	hold, err := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 150})
+
+	// Assert
+	assert.Nil(t, hold)
+	assert.ErrorIs(t, err, ErrBelowMinimumBalance)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Hold_PayerCannotCaptureOrVoid, ödeyenin kendi hold'unu capture/void edemediğini,
+// hold'un tarafı olmayan kullanıcının hold'u göremediğini ve admin'in void edebildiğini test eder.
+func TestTransactionService_Hold_PayerCannotCaptureOrVoid(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	activeHold := func() *sqlmock.Rows {
+		return sqlmock.NewRows(holdColumns).AddRow(7, 10, 20, 300.0, 0.0, models.HoldStatusActive, nil, nil, time.Now())
+	}
+
+	// Ödeyen capture ve void dener: hold güncellenmeden rollback
+	for i := 0; i < 2; i++ {
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery("FROM balance_holds").WithArgs(7).WillReturnRows(activeHold())
+		dbMock.ExpectRollback()
+	}
+	// Hold'un tarafı olmayan kullanıcı
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(7).WillReturnRows(activeHold())
+	dbMock.ExpectRollback()
+	// Admin void eder
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(7).WillReturnRows(activeHold())
+	dbMock.ExpectExec("UPDATE balance_holds SET status").WithArgs(models.HoldStatusVoided, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	captured, captureErr := transactionService.CaptureHold(context.Background(), 10, false, &models.CaptureHoldRequest{HoldID: 7})
+	payerVoided, payerVoidErr := transactionService.VoidHold(context.Background(), 10, false, &models.VoidHoldRequest{HoldID: 7})
+	strangerVoided, strangerVoidErr := transactionService.VoidHold(context.Background(), 30, false, &models.VoidHoldRequest{HoldID: 7})
+	adminVoided, adminVoidErr := transactionService.VoidHold(context.Background(), 1, true, &models.VoidHoldRequest{HoldID: 7})
+
+	// Assert
+	assert.Nil(t, captured)
+	assert.ErrorIs(t, captureErr, ErrHoldForbidden)
+	assert.Nil(t, payerVoided)
+	assert.ErrorIs(t, payerVoidErr, ErrHoldForbidden)
+	assert.Nil(t, strangerVoided)
+	assert.ErrorIs(t, strangerVoidErr, ErrHoldNotFound)
+	require.NoError(t, adminVoidErr)
+	assert.Equal(t, models.HoldStatusVoided, adminVoided.Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Hold_DisabledFlag, "holds" flag'i kapalıyken authorize'ın DB'ye gitmeden reddedildiğini test eder.
+func TestTransactionService_Hold_DisabledFlag(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: false})
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+
+	// Act
+	hold, err := transactionService.AuthorizeHold(context.Background(), 10, &models.AuthorizeHoldRequest{ToUserID: 20, Amount: 100})
+
+	// Assert
+	assert.Nil(t, hold)
+	assert.ErrorIs(t, err, ErrHoldsDisabled)
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// BalanceReconciliationConfig periyodik bakiye mutabakatı ayarları
+type BalanceReconciliationConfig struct {
+	Interval  time.Duration // Mutabakat job'ının çalışma sıklığı
+	BatchSize int           // Tek sorguda ledger ile karşılaştırılan bakiye sayısı
+	Tolerance float64       // Bu değerden küçük farklar (yuvarlama) sapma sayılmaz
+}
+
+// DefaultBalanceReconciliationConfig varsayılan mutabakat ayarları (günde bir, 500'lük batch'ler)
+func DefaultBalanceReconciliationConfig() *BalanceReconciliationConfig {
+	return &BalanceReconciliationConfig{
+		Interval:  24 * time.Hour,
+		BatchSize: 500,
+		Tolerance: 0.005,
+	}
+}
+
+// ReconciliationAlertFunc sapma bulunan mutabakat raporunu ilgili kanala iletir
+type ReconciliationAlertFunc func(ctx context.Context, report *models.BalanceReconciliationReport)
+
+// LogReconciliationAlert sapan kullanıcıları error seviyesinde loglar (log tabanlı alarm kuralları için varsayılan alert)
+func LogReconciliationAlert(ctx context.Context, report *models.BalanceReconciliationReport) {
+	userIDs := make([]int, 0, len(report.Drifts))
+	for _, drift := range report.Drifts {
+		userIDs = append(userIDs, drift.UserID)
+	}
+
+	log.Error().
+		Str("alert", "balance_drift").
+		Int("drifted_users", len(report.Drifts)).
+		Int("scanned", report.Scanned).
+		Ints("user_ids", userIDs).
+		Interface("drifts", report.Drifts).
+		Msg("Bakiye mutabakatında ledger ile uyuşmayan bakiyeler bulundu")
+}
+
+// ReconcileBalances tüm bakiyeleri batch'ler halinde balance_history toplamıyla karşılaştırır ve sapanları raporlar.
+// Context iptal edilirse tarama yarıda kesilir ve context hatası döner.
+func ReconcileBalances(ctx context.Context, repo interfaces.BalanceRepositoryInterface, config *BalanceReconciliationConfig) (*models.BalanceReconciliationReport, error) {
+	if config == nil {
+		config = DefaultBalanceReconciliationConfig()
+	}
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("geçersiz mutabakat batch boyutu: %d", config.BatchSize)
+	}
+
+	report := &models.BalanceReconciliationReport{
+		Drifts:    []models.BalanceDrift{},
+		StartedAt: time.Now(),
+	}
+
+	afterUserID := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		balances, err := repo.GetLedgerBalances(afterUserID, config.BatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("bakiye mutabakatı yapılamadı: %w", err)
+		}
+
+		for _, balance := range balances {
+			difference := balance.Amount - balance.LedgerAmount
+			if math.Abs(difference) >= config.Tolerance {
+				report.Drifts = append(report.Drifts, models.BalanceDrift{
+					UserID:       balance.UserID,
+					Amount:       balance.Amount,
+					LedgerAmount: balance.LedgerAmount,
+					Difference:   difference,
+				})
+			}
+			afterUserID = balance.UserID
+		}
+		report.Scanned += len(balances)
+
+		if len(balances) < config.BatchSize {
+			break
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// StartBalanceReconciler mutabakatı hemen ve periyodik olarak çalıştırır; sapma bulunursa alert çağrılır (nil = LogReconciliationAlert)
+func StartBalanceReconciler(ctx context.Context, repo interfaces.BalanceRepositoryInterface, config *BalanceReconciliationConfig, alert ReconciliationAlertFunc) {
+	if config == nil {
+		config = DefaultBalanceReconciliationConfig()
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultBalanceReconciliationConfig().Interval
+	}
+	if alert == nil {
+		alert = LogReconciliationAlert
+	}
+
+	go func() {
+		run := func() {
+			report, err := ReconcileBalances(ctx, repo, config)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error().Err(err).Msg("Bakiye mutabakatı başarısız")
+				}
+				return
+			}
+
+			log.Info().
+				Int("scanned", report.Scanned).
+				Int("drifted_users", len(report.Drifts)).
+				Dur("duration", report.FinishedAt.Sub(report.StartedAt)).
+				Msg("Bakiye mutabakatı tamamlandı")
+
+			if len(report.Drifts) > 0 {
+				alert(ctx, report)
+			}
+		}
+
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Balance reconciler stopped")
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestStartBalanceReconciler_AlertsOnDriftedBalance, batch'ler halinde taranan bakiyelerden ledger'dan sapanın
+// alert'e iletildiğini, yuvarlama farkının ise sapma sayılmadığını test eder.
+func TestStartBalanceReconciler_AlertsOnDriftedBalance(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockRepo := new(MockBalanceRepository)
+	mockRepo.On("GetLedgerBalances", 0, 2).Return([]*models.LedgerBalance{
+		{UserID: 3, Amount: 100, LedgerAmount: 100},
+		{UserID: 7, Amount: 250.004, LedgerAmount: 250},
+	}, nil)
+	mockRepo.On("GetLedgerBalances", 7, 2).Return([]*models.LedgerBalance{
+		{UserID: 12, Amount: 900, LedgerAmount: 400}, // Geçmişe yazılmadan artırılmış bakiye
+	}, nil)
+
+	reports := make(chan *models.BalanceReconciliationReport, 1)
+	alert := func(ctx context.Context, report *models.BalanceReconciliationReport) {
+		reports <- report
+	}
+
+	// Act
+	StartBalanceReconciler(ctx, mockRepo, &BalanceReconciliationConfig{Interval: time.Hour, BatchSize: 2, Tolerance: 0.005}, alert)
+
+	// Assert
+	var report *models.BalanceReconciliationReport
+	select {
+	case report = <-reports:
+	case <-time.After(time.Second):
+		require.FailNow(t, "sapma için alert gönderilmedi")
+	}
+
+	assert.Equal(t, 3, report.Scanned)
+	assert.Equal(t, []models.BalanceDrift{{UserID: 12, Amount: 900, LedgerAmount: 400, Difference: 500}}, report.Drifts)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestReconcileBalances_StopsWhenContextCancelled, iptal edilmiş context ile taramanın başlamadan context hatası döndüğünü test eder.
+func TestReconcileBalances_StopsWhenContextCancelled(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mockRepo := new(MockBalanceRepository)
+
+	// Act
+	report, err := ReconcileBalances(ctx, mockRepo, DefaultBalanceReconciliationConfig())
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, report)
+	mockRepo.AssertNotCalled(t, "GetLedgerBalances", 0, 500)
+}
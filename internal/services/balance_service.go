@@ -1,10 +1,13 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/onerilhan/go-payment-api/internal/features"
 	"github.com/onerilhan/go-payment-api/internal/interfaces"
 	"github.com/onerilhan/go-payment-api/internal/models"
 )
@@ -13,6 +16,10 @@ import (
 type BalanceService struct {
 	balanceRepo interfaces.BalanceRepositoryInterface
 	mutex       sync.RWMutex // Thread-safe operations için
+
+	// Bakiyenin diğer para birimlerinde gösterimi (provider nil = kapalı)
+	rateProvider      interfaces.ExchangeRateProvider
+	displayCurrencies map[string]bool
 }
 
 // NewBalanceService, yeni bir service oluşturur.
@@ -36,6 +43,45 @@ func (s *BalanceService) GetBalance(userID int) (*models.Balance, error) {
 	return balance, nil
 }
 
+// GetAvailableBalance, kullanıcının kullanılabilir bakiyesini hesaplar.
+// Kesinleşmiş bakiyeden pending giden işlemler ve aktif hold'lar düşülür; tüm özellikler bu hesabı kullanmalıdır.
+func (s *BalanceService) GetAvailableBalance(userID int) (*models.AvailableBalance, error) {
+	s.mutex.RLock() // Okuma kilidi
+	defer s.mutex.RUnlock()
+
+	balance, err := s.balanceRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("bakiye alınamadı: %w", err)
+	}
+
+	pendingOutgoing, err := s.balanceRepo.GetPendingOutgoingAmount(userID)
+	if err != nil {
+		return nil, fmt.Errorf("kullanılabilir bakiye hesaplanamadı: %w", err)
+	}
+
+	// Aktif hold'lar ("holds" flag'i açıkken) capture/void edilene kadar kullanılamaz
+	held := 0.0
+	if features.Enabled(features.Holds) {
+		held, err = s.balanceRepo.GetActiveHoldAmount(userID)
+		if err != nil {
+			return nil, fmt.Errorf("kullanılabilir bakiye hesaplanamadı: %w", err)
+		}
+	}
+
+	available := balance.Amount - pendingOutgoing - held
+	if available < 0 {
+		available = 0
+	}
+
+	return &models.AvailableBalance{
+		UserID:          userID,
+		Settled:         balance.Amount,
+		PendingOutgoing: pendingOutgoing,
+		Held:            held,
+		Available:       available,
+	}, nil
+}
+
 // UpdateBalance, kullanıcının bakiyesini günceller.
 func (s *BalanceService) UpdateBalance(userID int, amount float64) error {
 	s.mutex.Lock() // Yazma kilidi
@@ -44,8 +90,49 @@ func (s *BalanceService) UpdateBalance(userID int, amount float64) error {
 	return s.balanceRepo.UpdateBalance(userID, amount)
 }
 
+// AdjustBalance, bakiyeyi delta kadar atomik olarak değiştirir ve yeni bakiyeyi döner.
+// Değişiklik veritabanında tek UPDATE ile uygulandığı için servis kilidi gerekmez (birden fazla instance'ta da güvenli).
+func (s *BalanceService) AdjustBalance(userID int, delta float64) (float64, error) {
+	newAmount, err := s.balanceRepo.AdjustBalance(userID, delta)
+	if errors.Is(err, models.ErrBalanceWouldBeNegative) {
+		return 0, fmt.Errorf("%w: %w", ErrInsufficientBalance, err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("bakiye güncellenemedi: %w", err)
+	}
+	return newAmount, nil
+}
+
+// AdjustBalanceWithReference, bakiyeyi idempotency referansıyla delta kadar değiştirir (ör. admin düzeltmeleri).
+// Aynı referans tekrar gönderilirse bakiye ikinci kez değişmez ve ilk düzeltme kaydı applied=false ile döner.
+func (s *BalanceService) AdjustBalanceWithReference(userID int, delta float64, reference string) (*models.BalanceHistory, bool, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return nil, false, fmt.Errorf("düzeltme referansı gerekli")
+	}
+	if len(reference) > models.MaxAdjustmentReferenceLength {
+		return nil, false, fmt.Errorf("düzeltme referansı en fazla %d karakter olabilir", models.MaxAdjustmentReferenceLength)
+	}
+	if delta == 0 {
+		return nil, false, fmt.Errorf("düzeltme tutarı sıfır olamaz")
+	}
+
+	adjustment, applied, err := s.balanceRepo.AdjustBalanceWithReference(userID, delta, reference)
+	if errors.Is(err, models.ErrBalanceWouldBeNegative) {
+		return nil, false, fmt.Errorf("%w: %w", ErrInsufficientBalance, err)
+	}
+	if errors.Is(err, models.ErrAdjustmentReferenceConflict) {
+		return nil, false, err
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("bakiye güncellenemedi: %w", err)
+	}
+	return adjustment, applied, nil
+}
+
 // GetBalanceHistory, kullanıcının bakiye geçmişini listeler.
-func (s *BalanceService) GetBalanceHistory(userID int, limit, offset int) ([]*models.BalanceHistory, error) {
+// sort boş ise varsayılan sıralama yönü kullanılır.
+func (s *BalanceService) GetBalanceHistory(userID int, limit, offset int, sort string) ([]*models.BalanceHistory, error) {
 	s.mutex.RLock() // Okuma kilidi
 	defer s.mutex.RUnlock()
 
@@ -57,7 +144,12 @@ func (s *BalanceService) GetBalanceHistory(userID int, limit, offset int) ([]*mo
 		offset = 0 // default offset
 	}
 
-	history, err := s.balanceRepo.GetBalanceHistory(userID, limit, offset)
+	sort, err := models.ParseSortOrder(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.balanceRepo.GetBalanceHistory(userID, limit, offset, sort)
 	if err != nil {
 		return nil, fmt.Errorf("bakiye geçmişi alınamadı: %w", err)
 	}
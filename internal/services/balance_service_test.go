@@ -40,8 +40,21 @@ func (m *MockBalanceRepository) UpdateBalance(userID int, newAmount float64) err
 	return args.Error(0)
 }
 
-func (m *MockBalanceRepository) GetBalanceHistory(userID int, limit, offset int) ([]*models.BalanceHistory, error) {
-	args := m.Called(userID, limit, offset)
+func (m *MockBalanceRepository) AdjustBalance(userID int, delta float64) (float64, error) {
+	args := m.Called(userID, delta)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockBalanceRepository) AdjustBalanceWithReference(userID int, delta float64, reference string) (*models.BalanceHistory, bool, error) {
+	args := m.Called(userID, delta, reference)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.BalanceHistory), args.Bool(1), args.Error(2)
+}
+
+func (m *MockBalanceRepository) GetBalanceHistory(userID int, limit, offset int, sort string) ([]*models.BalanceHistory, error) {
+	args := m.Called(userID, limit, offset, sort)
 	return args.Get(0).([]*models.BalanceHistory), args.Error(1)
 }
 
@@ -50,6 +63,11 @@ func (m *MockBalanceRepository) CreateBalanceSnapshot(userID int, amount float64
 	return args.Error(0)
 }
 
+func (m *MockBalanceRepository) CreateBalanceSnapshots(asOf time.Time, reason string) (int, error) {
+	args := m.Called(asOf, reason)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockBalanceRepository) GetBalanceAtTime(userID int, atTime time.Time) (*models.BalanceAtTime, error) {
 	args := m.Called(userID, atTime)
 	if args.Get(0) == nil {
@@ -58,6 +76,42 @@ func (m *MockBalanceRepository) GetBalanceAtTime(userID int, atTime time.Time) (
 	return args.Get(0).(*models.BalanceAtTime), args.Error(1)
 }
 
+func (m *MockBalanceRepository) GetMinimumBalance(userID int) (float64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockBalanceRepository) SetMinimumBalance(userID int, minimum float64) error {
+	args := m.Called(userID, minimum)
+	return args.Error(0)
+}
+
+func (m *MockBalanceRepository) GetPendingOutgoingAmount(userID int) (float64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockBalanceRepository) GetActiveHoldAmount(userID int) (float64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockBalanceRepository) ArchiveBalanceHistory(before time.Time) (*models.BalanceArchiveResult, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BalanceArchiveResult), args.Error(1)
+}
+
+func (m *MockBalanceRepository) GetLedgerBalances(afterUserID, limit int) ([]*models.LedgerBalance, error) {
+	args := m.Called(afterUserID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LedgerBalance), args.Error(1)
+}
+
 // TestBalanceService_GetBalance_Success, bakiye getirme işleminin başarılı senaryosunu test eder.
 func TestBalanceService_GetBalance_Success(t *testing.T) {
 	// Arrange
@@ -99,3 +153,115 @@ func TestBalanceService_GetBalance_Error(t *testing.T) {
 	assert.Nil(t, result)
 	mockBalanceRepo.AssertExpectations(t)
 }
+
+// TestBalanceService_GetAvailableBalance_SubtractsPendingOutgoing, kullanılabilir bakiyenin pending giden işlemler düşülerek hesaplandığını test eder.
+func TestBalanceService_GetAvailableBalance_SubtractsPendingOutgoing(t *testing.T) {
+	// Arrange
+	mockBalanceRepo := new(MockBalanceRepository)
+	balanceService := NewBalanceService(mockBalanceRepo)
+
+	userID := 1
+	mockBalanceRepo.On("GetByUserID", userID).Return(&models.Balance{UserID: userID, Amount: 500.0}, nil)
+	mockBalanceRepo.On("GetPendingOutgoingAmount", userID).Return(120.0, nil)
+
+	// Act
+	result, err := balanceService.GetAvailableBalance(userID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &models.AvailableBalance{
+		UserID:          userID,
+		Settled:         500.0,
+		PendingOutgoing: 120.0,
+		Held:            0,
+		Available:       380.0,
+	}, result)
+	mockBalanceRepo.AssertExpectations(t)
+}
+
+// TestBalanceService_GetAvailableBalance_NeverNegative, pending tutarı bakiyeyi aşsa da kullanılabilir bakiyenin negatif olmadığını test eder.
+func TestBalanceService_GetAvailableBalance_NeverNegative(t *testing.T) {
+	// Arrange
+	mockBalanceRepo := new(MockBalanceRepository)
+	balanceService := NewBalanceService(mockBalanceRepo)
+
+	mockBalanceRepo.On("GetByUserID", 1).Return(&models.Balance{UserID: 1, Amount: 50.0}, nil)
+	mockBalanceRepo.On("GetPendingOutgoingAmount", 1).Return(80.0, nil)
+
+	// Act
+	result, err := balanceService.GetAvailableBalance(1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, result.Available)
+}
+
+// TestBalanceService_AdjustBalance_NegativeIsInsufficient, negatife düşürecek değişikliğin yetersiz bakiye hatası olarak döndüğünü test eder.
+func TestBalanceService_AdjustBalance_NegativeIsInsufficient(t *testing.T) {
+	// Arrange
+	mockBalanceRepo := new(MockBalanceRepository)
+	balanceService := NewBalanceService(mockBalanceRepo)
+
+	mockBalanceRepo.On("AdjustBalance", 1, 25.0).Return(125.0, nil)
+	mockBalanceRepo.On("AdjustBalance", 1, -500.0).Return(0.0, models.ErrBalanceWouldBeNegative)
+
+	// Act
+	credited, creditErr := balanceService.AdjustBalance(1, 25)
+	_, debitErr := balanceService.AdjustBalance(1, -500)
+
+	// Assert
+	assert.NoError(t, creditErr)
+	assert.Equal(t, 125.0, credited)
+	assert.ErrorIs(t, debitErr, ErrInsufficientBalance)
+	mockBalanceRepo.AssertExpectations(t)
+}
+
+// TestBalanceService_GetBalanceInCurrencies_ConvertsWithRates, bakiyenin doğrudan ve ters kurla çevrildiğini,
+// native bakiyenin korunup sonucun gösterge olarak işaretlendiğini test eder.
+func TestBalanceService_GetBalanceInCurrencies_ConvertsWithRates(t *testing.T) {
+	// Arrange
+	mockBalanceRepo := new(MockBalanceRepository)
+	balanceService := NewBalanceService(mockBalanceRepo)
+	balanceService.SetCurrencyDisplay(NewStaticRateProvider(map[string]float64{
+		"TRY/EUR": 0.025,
+		"USD/TRY": 32,
+	}), []string{"EUR", "usd"})
+
+	mockBalanceRepo.On("GetByUserID", 1).Return(&models.Balance{UserID: 1, Amount: 1000}, nil)
+
+	// Act
+	display, err := balanceService.GetBalanceInCurrencies(1, []string{" eur", "USD", "EUR"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, BaseCurrency, display.Currency)
+	assert.Equal(t, 1000.0, display.Amount)
+	assert.True(t, display.Indicative)
+	assert.Equal(t, []models.ConvertedBalance{
+		{Currency: "EUR", Amount: 25, Rate: 0.025},
+		{Currency: "USD", Amount: 31.25, Rate: 1.0 / 32},
+	}, display.Converted)
+	mockBalanceRepo.AssertExpectations(t)
+}
+
+// TestBalanceService_GetBalanceInCurrencies_UnsupportedCurrency, izin listesinde olmayan, geçersiz biçimli ve
+// gösterim kapalıyken istenen para birimlerinin bakiye okunmadan reddedildiğini test eder.
+func TestBalanceService_GetBalanceInCurrencies_UnsupportedCurrency(t *testing.T) {
+	// Arrange
+	mockBalanceRepo := new(MockBalanceRepository)
+	disabled := NewBalanceService(mockBalanceRepo)
+	balanceService := NewBalanceService(mockBalanceRepo)
+	balanceService.SetCurrencyDisplay(NewStaticRateProvider(map[string]float64{"USD/TRY": 32, "GBP/TRY": 41}), []string{"USD"})
+
+	// Act
+	_, notAllowedErr := balanceService.GetBalanceInCurrencies(1, []string{"USD", "GBP"})
+	_, malformedErr := balanceService.GetBalanceInCurrencies(1, []string{"DOLAR"})
+	_, disabledErr := disabled.GetBalanceInCurrencies(1, []string{"USD"})
+
+	// Assert
+	assert.ErrorIs(t, notAllowedErr, ErrUnsupportedCurrency)
+	assert.Contains(t, notAllowedErr.Error(), "GBP")
+	assert.ErrorIs(t, malformedErr, ErrUnsupportedCurrency)
+	assert.ErrorIs(t, disabledErr, ErrUnsupportedCurrency)
+	mockBalanceRepo.AssertNotCalled(t, "GetByUserID", 1)
+}
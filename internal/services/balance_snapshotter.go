@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// BalanceSnapshotConfig periyodik bakiye snapshot ayarları
+type BalanceSnapshotConfig struct {
+	Interval    time.Duration // Snapshot job'ının çalışma sıklığı
+	SettleDelay time.Duration // Snapshot anı şimdiden bu kadar geride alınır (süren transaction'lar commit olsun)
+}
+
+// DefaultBalanceSnapshotConfig varsayılan snapshot ayarları (saatte bir, 1 dk geriden)
+func DefaultBalanceSnapshotConfig() *BalanceSnapshotConfig {
+	return &BalanceSnapshotConfig{
+		Interval:    time.Hour,
+		SettleDelay: time.Minute,
+	}
+}
+
+// TakeBalanceSnapshots bakiyesi son snapshot'tan beri değişen kullanıcılar için snapshot yazar
+func TakeBalanceSnapshots(repo interfaces.BalanceRepositoryInterface, config *BalanceSnapshotConfig, now time.Time) (int, error) {
+	if config == nil {
+		config = DefaultBalanceSnapshotConfig()
+	}
+	if config.SettleDelay < 0 {
+		return 0, fmt.Errorf("geçersiz snapshot gecikmesi: %s", config.SettleDelay)
+	}
+
+	asOf := now.Add(-config.SettleDelay)
+	written, err := repo.CreateBalanceSnapshots(asOf, models.BalanceSnapshotReasonPeriodic)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info().
+		Int("snapshots", written).
+		Time("as_of", asOf).
+		Msg("Bakiye snapshot'ları alındı")
+
+	return written, nil
+}
+
+// StartBalanceSnapshotWriter snapshot'ları hemen ve periyodik olarak yazar
+func StartBalanceSnapshotWriter(ctx context.Context, repo interfaces.BalanceRepositoryInterface, config *BalanceSnapshotConfig) {
+	if config == nil {
+		config = DefaultBalanceSnapshotConfig()
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultBalanceSnapshotConfig().Interval
+	}
+
+	go func() {
+		run := func() {
+			if _, err := TakeBalanceSnapshots(repo, config, time.Now()); err != nil {
+				log.Error().Err(err).Msg("Bakiye snapshot'ı alınamadı")
+			}
+		}
+
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Balance snapshot writer stopped")
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestTakeBalanceSnapshots_UsesSettleDelay, snapshot anının now - settle delay olarak hesaplandığını test eder.
+func TestTakeBalanceSnapshots_UsesSettleDelay(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBalanceRepository)
+	now := time.Date(2025, 8, 15, 3, 0, 0, 0, time.UTC)
+	asOf := now.Add(-2 * time.Minute)
+
+	mockRepo.On("CreateBalanceSnapshots", asOf, models.BalanceSnapshotReasonPeriodic).Return(7, nil)
+
+	// Act
+	written, err := TakeBalanceSnapshots(mockRepo, &BalanceSnapshotConfig{Interval: time.Hour, SettleDelay: 2 * time.Minute}, now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, written)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// BatchTransfer bir kullanıcıdan birden fazla alıcıya toplu transfer yapar.
+// atomic modda tüm transferler tek DB transaction'ında yapılır, biri başarısız olursa hiçbiri uygulanmaz.
+// best_effort modda her transfer kendi transaction'ında yapılır; başarısızlar raporlanır,
+// gönderenin bakiyesi tükenirse kalan transferler denenmeden atlanır.
+func (s *TransactionService) BatchTransfer(ctx context.Context, fromUserID int, req *models.BatchTransferRequest) (*models.BatchTransferResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*models.Transaction, len(req.Transfers))
+	for i := range req.Transfers {
+		transaction, err := s.prepareTransfer(fromUserID, &req.Transfers[i])
+		if err != nil {
+			return nil, fmt.Errorf("transfer #%d: %w", i+1, err)
+		}
+		transaction.Channel = requestChannel(ctx)
+		transactions[i] = transaction
+	}
+
+	// Yeni hesaplar kayıttan sonraki bekleme süresinde transfer yapamaz
+	if err := s.checkNewAccountCooldown(fromUserID); err != nil {
+		return nil, err
+	}
+
+	// Günlük transaction sayısı limiti (batch'teki tüm transferler sayılır; sığmayan batch baştan reddedilir)
+	if err := s.checkDailyCountLimitFor(fromUserID, len(req.Transfers)); err != nil {
+		return nil, err
+	}
+
+	// Günlük sayı ve giden tutar limitleri (her transferde bakiye kilitliyken kontrol edilir)
+	limits, err := s.dailyLimitsFor(fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Aynı alıcıya açıklamalı transfer limiti (batch içindeki açıklamalı transferler de sayılır)
+	memoCounts := make(map[int]int)
+	var memoRecipients []int
+	for _, transfer := range req.Transfers {
+		if !hasDescription(transfer.Description) {
+			continue
+		}
+		if memoCounts[transfer.ToUserID] == 0 {
+			memoRecipients = append(memoRecipients, transfer.ToUserID)
+		}
+		memoCounts[transfer.ToUserID]++
+	}
+	for _, toUserID := range memoRecipients {
+		if err := s.checkMemoTransferRate(fromUserID, toUserID, memoCounts[toUserID]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fraud incelemesine takılan transferler batch ile yapılamaz (tekil transfer olarak incelemeye alınır)
+	for i := range req.Transfers {
+		reason, err := s.reviewReason(fromUserID, &req.Transfers[i])
+		if err != nil {
+			return nil, fmt.Errorf("transfer #%d: %w", i+1, err)
+		}
+		if reason != "" {
+			return nil, fmt.Errorf("transfer #%d: %w (%s)", i+1, ErrTransferRequiresReview, reason)
+		}
+	}
+
+	// Ardışık transferler arası minimum süre: batch tek transfer gibi bir aralık kullanır
+	releaseSlot, err := s.reserveTransferSlot(fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Mode == models.BatchModeBestEffort {
+		result := s.batchTransferBestEffort(ctx, fromUserID, req, transactions, limits)
+		if result.Succeeded == 0 {
+			releaseSlot()
+		}
+		return result, nil
+	}
+
+	result, err := s.batchTransferAtomic(ctx, fromUserID, req, transactions, limits)
+	if err != nil {
+		releaseSlot()
+		return nil, err
+	}
+	return result, nil
+}
+
+// batchTransferAtomic tüm transferleri tek DB transaction'ında uygular
+func (s *TransactionService) batchTransferAtomic(ctx context.Context, fromUserID int, req *models.BatchTransferRequest, transactions []*models.Transaction, limits dailyLimits) (*models.BatchTransferResult, error) {
+	startedAt := time.Now()
+
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+		for i := range req.Transfers {
+			// Tekrar denemede önceki denemenin status'u taşınmasın
+			transactions[i].Status = models.StatusPending
+
+			if err := executeTransfer(txRepo, fromUserID, &req.Transfers[i], transactions[i], limits); err != nil {
+				return fmt.Errorf("transfer #%d (alıcı %d): %w", i+1, req.Transfers[i].ToUserID, err)
+			}
+		}
+		return nil // SUCCESS - tüm transferler commit edilecek
+	})
+
+	for _, transaction := range transactions {
+		logTransactionCreated(ctx, transaction, startedAt, err)
+		s.fireStatusHooks(ctx, models.StatusPending, transaction, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BatchTransferResult{Mode: models.BatchModeAtomic}
+	for i, transfer := range req.Transfers {
+		result.Results = append(result.Results, models.BatchTransferItemResult{
+			ToUserID:    transfer.ToUserID,
+			Amount:      transfer.Amount,
+			Success:     true,
+			Transaction: transactions[i],
+		})
+		result.Succeeded++
+	}
+
+	return result, nil
+}
+
+// batchTransferBestEffort her transferi ayrı DB transaction'ında uygular ve sonucu alıcı bazında raporlar
+func (s *TransactionService) batchTransferBestEffort(ctx context.Context, fromUserID int, req *models.BatchTransferRequest, transactions []*models.Transaction, limits dailyLimits) *models.BatchTransferResult {
+	result := &models.BatchTransferResult{Mode: models.BatchModeBestEffort}
+	balanceExhausted := false
+
+	for i, transfer := range req.Transfers {
+		item := models.BatchTransferItemResult{
+			ToUserID: transfer.ToUserID,
+			Amount:   transfer.Amount,
+		}
+
+		// Bakiye tükendiyse kalanları deneme
+		if balanceExhausted {
+			item.Skipped = true
+			item.Error = "gönderen bakiyesi tükendi, transfer denenmedi"
+			result.Skipped++
+			result.Results = append(result.Results, item)
+			continue
+		}
+
+		transaction := transactions[i]
+		startedAt := time.Now()
+
+		err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+			// Tekrar denemede önceki denemenin status'u taşınmasın
+			transaction.Status = models.StatusPending
+			return executeTransfer(db.NewTransactionRepository(tx), fromUserID, &req.Transfers[i], transaction, limits)
+		})
+
+		logTransactionCreated(ctx, transaction, startedAt, err)
+		s.fireStatusHooks(ctx, models.StatusPending, transaction, err)
+
+		if err != nil {
+			item.Error = err.Error()
+			result.Failed++
+			// Büyük bir transferin bakiyeye yetmemesi bakiyenin tükendiği anlamına gelmez: kalanlar ancak
+			// kilit altında okunan kullanılabilir bakiye hiçbirine yetmiyorsa atlanır
+			var balanceErr *InsufficientBalanceError
+			if errors.As(err, &balanceErr) && !coversAnyTransfer(balanceErr.CurrentBalance, req.Transfers[i+1:]) {
+				balanceExhausted = true
+			}
+		} else {
+			item.Success = true
+			item.Transaction = transaction
+			result.Succeeded++
+		}
+
+		result.Results = append(result.Results, item)
+	}
+
+	return result
+}
+
+// coversAnyTransfer kullanılabilir bakiyenin kalan transferlerden en az birine (en küçüğüne) yetip yetmediğini döner
+func coversAnyTransfer(available float64, remaining []models.TransferRequest) bool {
+	if available <= 0 {
+		return false
+	}
+	for _, transfer := range remaining {
+		if transfer.Amount <= available {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// expectSuccessfulTransfer tek bir transferin başarılı DB akışını mock'a ekler
+func expectSuccessfulTransfer(dbMock sqlmock.Sqlmock, fromUserID, toUserID int, fromBalance, amount float64, transactionID int) {
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(fromUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(fromBalance))
+	expectMinimumBalance(dbMock, fromUserID, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(toUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(transactionID, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(fromBalance-amount, fromUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(amount, toUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+}
+
+// TestTransactionService_BatchTransfer_BestEffortPartialFailure, best_effort modda bir alıcı başarısız olsa da diğerlerinin işlendiğini test eder.
+func TestTransactionService_BatchTransfer_BestEffortPartialFailure(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	expectSuccessfulTransfer(dbMock, 10, 20, 1000, 100, 1)
+
+	// Alıcı 30'un bakiyesi yok ve oluşturulamıyor (ör. kullanıcı mevcut değil)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(900.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}))
+	dbMock.ExpectExec("INSERT INTO balances").WithArgs(30).WillReturnError(errors.New("foreign key violation"))
+	dbMock.ExpectRollback()
+
+	expectSuccessfulTransfer(dbMock, 10, 40, 900, 200, 2)
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	req := &models.BatchTransferRequest{
+		Mode: models.BatchModeBestEffort,
+		Transfers: []models.TransferRequest{
+			{ToUserID: 20, Amount: 100},
+			{ToUserID: 30, Amount: 50},
+			{ToUserID: 40, Amount: 200},
+		},
+	}
+
+	// Act
+	result, err := transactionService.BatchTransfer(context.Background(), 10, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 0, result.Skipped)
+	require.Len(t, result.Results, 3)
+
+	assert.True(t, result.Results[0].Success)
+	assert.Equal(t, 1, result.Results[0].Transaction.ID)
+
+	assert.False(t, result.Results[1].Success)
+	assert.Equal(t, 30, result.Results[1].ToUserID)
+	assert.Contains(t, result.Results[1].Error, "alan kullanıcı bakiyesi oluşturulamadı")
+	assert.Nil(t, result.Results[1].Transaction)
+
+	assert.True(t, result.Results[2].Success)
+	assert.Equal(t, models.StatusCompleted, result.Results[2].Transaction.Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_BatchTransfer_BestEffortStopsOnBalanceExhaustion, kalan bakiye kalan transferlerin hiçbirine
+// yetmiyorsa kalanların denenmeden atlandığını test eder.
+func TestTransactionService_BatchTransfer_BestEffortStopsOnBalanceExhaustion(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	expectSuccessfulTransfer(dbMock, 10, 20, 150, 100, 1)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(50.0))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	req := &models.BatchTransferRequest{
+		Mode: models.BatchModeBestEffort,
+		Transfers: []models.TransferRequest{
+			{ToUserID: 20, Amount: 100},
+			{ToUserID: 30, Amount: 100},
+			{ToUserID: 40, Amount: 60},
+		},
+	}
+
+	// Act
+	result, err := transactionService.BatchTransfer(context.Background(), 10, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Contains(t, result.Results[1].Error, "yetersiz bakiye")
+	assert.True(t, result.Results[2].Skipped)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_BatchTransfer_BestEffortContinuesAfterLargeFailure, bakiyeye yetmeyen büyük transferden sonra
+// bakiyenin yettiği daha küçük transferin atlanmadan denendiğini ve başarılı olduğunu test eder.
+func TestTransactionService_BatchTransfer_BestEffortContinuesAfterLargeFailure(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(50.0))
+	dbMock.ExpectRollback()
+
+	expectSuccessfulTransfer(dbMock, 10, 30, 50, 30, 1)
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	req := &models.BatchTransferRequest{
+		Mode: models.BatchModeBestEffort,
+		Transfers: []models.TransferRequest{
+			{ToUserID: 20, Amount: 100},
+			{ToUserID: 30, Amount: 30},
+		},
+	}
+
+	// Act
+	result, err := transactionService.BatchTransfer(context.Background(), 10, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 0, result.Skipped)
+	require.Len(t, result.Results, 2)
+	assert.Contains(t, result.Results[0].Error, "yetersiz bakiye")
+	assert.True(t, result.Results[1].Success)
+	assert.False(t, result.Results[1].Skipped)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_BatchTransfer_AtomicRollsBackAll, atomic modda bir transfer başarısız olunca tüm batch'in geri alındığını test eder.
+func TestTransactionService_BatchTransfer_AtomicRollsBackAll(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(100.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(20.0))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	req := &models.BatchTransferRequest{
+		Transfers: []models.TransferRequest{
+			{ToUserID: 20, Amount: 80},
+			{ToUserID: 30, Amount: 50},
+		},
+	}
+
+	// Act
+	result, err := transactionService.BatchTransfer(context.Background(), 10, req)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.Equal(t, models.BatchModeAtomic, req.Mode)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_BatchTransfer_EnforcesTransferInterval, batch'in de transferler arası minimum süreye tabi
+// olduğunu test eder: başarısız batch aralığı başlatmaz, başarılı batch'ten hemen sonraki transfer reddedilir.
+func TestTransactionService_BatchTransfer_EnforcesTransferInterval(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).WillReturnError(sql.ErrConnDone)
+	dbMock.ExpectRollback()
+	expectSuccessfulTransfer(dbMock, 10, 20, 100, 30, 1)
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{MinTransferInterval: 2 * time.Second})
+	req := func() *models.BatchTransferRequest {
+		return &models.BatchTransferRequest{Transfers: []models.TransferRequest{{ToUserID: 20, Amount: 30}}}
+	}
+
+	// Act
+	_, failedErr := transactionService.BatchTransfer(context.Background(), 10, req())
+	result, batchErr := transactionService.BatchTransfer(context.Background(), 10, req())
+	transfer, transferErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 30})
+	againResult, againErr := transactionService.BatchTransfer(context.Background(), 10, req())
+
+	// Assert
+	assert.ErrorIs(t, failedErr, sql.ErrConnDone)
+	require.NoError(t, batchErr)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Nil(t, transfer)
+	assert.ErrorIs(t, transferErr, ErrTransferTooSoon)
+	assert.Nil(t, againResult)
+	assert.ErrorIs(t, againErr, ErrTransferTooSoon)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestBatchTransferRequest_Validate_InvalidMode, geçersiz batch modunun reddedildiğini test eder.
+func TestBatchTransferRequest_Validate_InvalidMode(t *testing.T) {
+	// Arrange
+	req := &models.BatchTransferRequest{
+		Mode:      "yarım",
+		Transfers: []models.TransferRequest{{ToUserID: 20, Amount: 10}},
+	}
+
+	// Act
+	err := req.Validate()
+
+	// Assert
+	assert.EqualError(t, err, "geçersiz batch modu: yarım. Geçerli modlar: atomic, best_effort")
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+)
+
+// normalizeCurrency para birimi kodunu normalize eder ve doğrular (ISO 4217, 3 harf)
+func normalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", fmt.Errorf("geçersiz para birimi: %q", code)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", fmt.Errorf("geçersiz para birimi: %q", code)
+		}
+	}
+	return code, nil
+}
+
+// ConvertAmount tutarı provider kuru ile from'dan to para birimine çevirir (kuruş hassasiyetine yuvarlar)
+func ConvertAmount(provider interfaces.ExchangeRateProvider, amount float64, from, to string) (float64, error) {
+	from, err := normalizeCurrency(from)
+	if err != nil {
+		return 0, err
+	}
+	to, err = normalizeCurrency(to)
+	if err != nil {
+		return 0, err
+	}
+
+	if from == to {
+		return amount, nil
+	}
+
+	rate, err := provider.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return convertWithRate(amount, rate), nil
+}
+
+// convertWithRate tutarı kur ile çarpar ve kuruş hassasiyetine yuvarlar
+func convertWithRate(amount, rate float64) float64 {
+	return math.Round(amount*rate*100) / 100
+}
+
+// StaticRateProvider config'den gelen sabit kur tablosu ile çalışan provider
+type StaticRateProvider struct {
+	rates map[string]float64 // "USD/TRY" -> 32.5
+}
+
+// NewStaticRateProvider sabit kur tablosundan provider oluşturur
+func NewStaticRateProvider(rates map[string]float64) *StaticRateProvider {
+	normalized := make(map[string]float64, len(rates))
+	for pair, rate := range rates {
+		normalized[strings.ToUpper(strings.TrimSpace(pair))] = rate
+	}
+	return &StaticRateProvider{rates: normalized}
+}
+
+// Rate tablodan kuru döner; doğrudan kur yoksa ters kurdan hesaplar
+func (p *StaticRateProvider) Rate(from, to string) (float64, error) {
+	if rate, ok := p.rates[from+"/"+to]; ok && rate > 0 {
+		return rate, nil
+	}
+	if inverse, ok := p.rates[to+"/"+from]; ok && inverse > 0 {
+		return 1 / inverse, nil
+	}
+	return 0, fmt.Errorf("kur bulunamadı: %s/%s", from, to)
+}
+
+// RepositoryRateProvider kur tablosunu database'den okuyan provider
+type RepositoryRateProvider struct {
+	repo interfaces.ExchangeRateRepositoryInterface
+}
+
+// NewRepositoryRateProvider repository tabanlı provider oluşturur
+func NewRepositoryRateProvider(repo interfaces.ExchangeRateRepositoryInterface) *RepositoryRateProvider {
+	return &RepositoryRateProvider{repo: repo}
+}
+
+// Rate kuru repository'den döner
+func (p *RepositoryRateProvider) Rate(from, to string) (float64, error) {
+	rate, err := p.repo.GetRate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return rate.Rate, nil
+}
+
+// ExternalRateProvider harici kur API'si için provider (henüz implement edilmedi)
+type ExternalRateProvider struct {
+	baseURL string
+}
+
+// NewExternalRateProvider harici API provider'ı oluşturur
+func NewExternalRateProvider(baseURL string) *ExternalRateProvider {
+	return &ExternalRateProvider{baseURL: baseURL}
+}
+
+// Rate harici API entegrasyonu yapılana kadar hata döner
+func (p *ExternalRateProvider) Rate(from, to string) (float64, error) {
+	return 0, fmt.Errorf("harici kur sağlayıcısı henüz desteklenmiyor (%s): %s/%s", p.baseURL, from, to)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// MockExchangeRateRepository, ExchangeRateRepositoryInterface için sahte (mock) bir yapıdır.
+type MockExchangeRateRepository struct {
+	mock.Mock
+}
+
+var _ interfaces.ExchangeRateRepositoryInterface = (*MockExchangeRateRepository)(nil)
+
+func (m *MockExchangeRateRepository) GetRate(baseCurrency, quoteCurrency string) (*models.ExchangeRate, error) {
+	args := m.Called(baseCurrency, quoteCurrency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExchangeRate), args.Error(1)
+}
+func (m *MockExchangeRateRepository) UpsertRate(baseCurrency, quoteCurrency string, rate float64) error {
+	args := m.Called(baseCurrency, quoteCurrency, rate)
+	return args.Error(0)
+}
+
+// TestConvertAmount_StaticProvider, sabit kur tablosu ile doğrudan, ters ve aynı para birimi dönüşümlerini test eder.
+func TestConvertAmount_StaticProvider(t *testing.T) {
+	// Arrange
+	provider := NewStaticRateProvider(map[string]float64{
+		"USD/TRY": 32.5,
+		"eur/try": 35.0,
+	})
+
+	// Act
+	direct, directErr := ConvertAmount(provider, 100, "USD", "TRY")
+	inverse, inverseErr := ConvertAmount(provider, 3500, "try", "eur")
+	same, sameErr := ConvertAmount(provider, 42.42, "TRY", "TRY")
+
+	// Assert
+	assert.NoError(t, directErr)
+	assert.Equal(t, 3250.0, direct)
+	assert.NoError(t, inverseErr)
+	assert.Equal(t, 100.0, inverse)
+	assert.NoError(t, sameErr)
+	assert.Equal(t, 42.42, same)
+}
+
+// TestConvertAmount_UnknownCurrency, tanımsız ve geçersiz para birimlerinde hata döndüğünü test eder.
+func TestConvertAmount_UnknownCurrency(t *testing.T) {
+	// Arrange
+	provider := NewStaticRateProvider(map[string]float64{"USD/TRY": 32.5})
+
+	// Act
+	_, unknownErr := ConvertAmount(provider, 100, "GBP", "TRY")
+	_, invalidErr := ConvertAmount(provider, 100, "DOLAR", "TRY")
+
+	// Assert
+	assert.EqualError(t, unknownErr, "kur bulunamadı: GBP/TRY")
+	assert.EqualError(t, invalidErr, `geçersiz para birimi: "DOLAR"`)
+}
+
+// TestConvertAmount_RepositoryProvider, repository tabanlı provider ile dönüşümü ve kur bulunamama hatasını test eder.
+func TestConvertAmount_RepositoryProvider(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockExchangeRateRepository)
+	mockRepo.On("GetRate", "EUR", "USD").Return(&models.ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.0845}, nil)
+	mockRepo.On("GetRate", "JPY", "USD").Return(nil, fmt.Errorf("kur bulunamadı: JPY/USD"))
+	provider := NewRepositoryRateProvider(mockRepo)
+
+	// Act
+	converted, err := ConvertAmount(provider, 250, "EUR", "USD")
+	_, missingErr := ConvertAmount(provider, 1000, "JPY", "USD")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 271.13, converted)
+	assert.EqualError(t, missingErr, "kur bulunamadı: JPY/USD")
+	mockRepo.AssertExpectations(t)
+}
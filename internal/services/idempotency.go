@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// Idempotency kaydında tutulan endpoint isimleri (aynı key farklı endpoint'te kullanılamaz)
+const (
+	IdempotencyEndpointCredit   = "credit"
+	IdempotencyEndpointDebit    = "debit"
+	IdempotencyEndpointTransfer = "transfer"
+)
+
+var (
+	// ErrIdempotencyKeyReused aynı Idempotency-Key farklı bir istekle tekrar kullanıldığında döner
+	ErrIdempotencyKeyReused = errors.New("Idempotency-Key farklı bir istek için kullanılmış")
+	// ErrIdempotencyKeyInProgress aynı key'li ilk istek bekleme süresi içinde sonuçlanmadığında döner
+	ErrIdempotencyKeyInProgress = errors.New("aynı Idempotency-Key ile gönderilen istek hâlâ işleniyor")
+)
+
+// IdempotencyConfig Idempotency-Key saklama ve bekleme ayarları
+type IdempotencyConfig struct {
+	Retention       time.Duration // Key bu süre boyunca ilk sonucu döner, sonra tekrar kullanılabilir
+	WaitTimeout     time.Duration // Aynı key'li eşzamanlı isteğin ilk isteğin sonucunu bekleme süresi
+	PollInterval    time.Duration // Bekleme sırasında kaydın tekrar kontrol edilme sıklığı
+	CleanupInterval time.Duration // Süresi dolan key'lerin silinme sıklığı
+}
+
+// DefaultIdempotencyConfig varsayılan ayarlar (24 saat saklama, 30 sn bekleme)
+func DefaultIdempotencyConfig() *IdempotencyConfig {
+	return &IdempotencyConfig{
+		Retention:       24 * time.Hour,
+		WaitTimeout:     30 * time.Second,
+		PollInterval:    50 * time.Millisecond,
+		CleanupInterval: time.Hour,
+	}
+}
+
+// SetIdempotency Idempotency-Key desteğini açar (repo nil ise header yok sayılır)
+func (s *TransactionService) SetIdempotency(repo interfaces.IdempotencyRepositoryInterface, config *IdempotencyConfig) {
+	if config == nil {
+		config = DefaultIdempotencyConfig()
+	}
+	s.idempotencyRepo = repo
+	s.idempotencyConfig = config
+}
+
+// idempotencyRecordKey key'i alan isteğin idempotency kaydı ID'sinin context anahtarı
+type idempotencyRecordKey struct{}
+
+// runIdempotent context'te Idempotency-Key varsa isteği bir kez çalıştırır; aynı key'le tekrar gelen istek
+// ilk isteğin sonucunu alır. İlk istek hâlâ işleniyorsa sonucu beklenir. Başarısız istek kaydedilmez, tekrar denenebilir.
+// execute'a verilen context, işlemi yazan DB transaction'ının completeIdempotency ile key'i tamamlaması için kaydı taşır.
+func (s *TransactionService) runIdempotent(ctx context.Context, userID int, endpoint string, req interface{}, execute func(ctx context.Context) (*models.Transaction, float64, error)) (*models.Transaction, float64, error) {
+	key := utils.IdempotencyKeyFromContext(ctx)
+	if key == "" || s.idempotencyRepo == nil {
+		return execute(ctx)
+	}
+
+	hash, err := idempotencyRequestHash(endpoint, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	config := s.idempotencyConfig
+	deadline := time.Now().Add(config.WaitTimeout)
+	for {
+		record, created, err := s.idempotencyRepo.Reserve(&models.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Endpoint:    endpoint,
+			RequestHash: hash,
+		}, time.Now().Add(-config.Retention))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if created {
+			return s.executeIdempotent(context.WithValue(ctx, idempotencyRecordKey{}, record.ID), record, execute)
+		}
+
+		if record != nil {
+			if record.RequestHash != hash {
+				return nil, 0, ErrIdempotencyKeyReused
+			}
+			if record.IsCompleted() {
+				return s.replayIdempotent(record)
+			}
+		}
+
+		// İlk istek hâlâ işleniyor (veya başarısız olup key'i bıraktı): tekrar kontrol et
+		if time.Now().After(deadline) {
+			return nil, 0, ErrIdempotencyKeyInProgress
+		}
+		select {
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		case <-time.After(config.PollInterval):
+		}
+	}
+}
+
+// executeIdempotent key'i alan isteği çalıştırır. Sonuç, işlemle aynı DB transaction'ında kaydedildiğinden
+// commit sonrası ayrı bir adım yoktur; başarısız (geri alınmış) isteğin key'i bırakılır.
+func (s *TransactionService) executeIdempotent(ctx context.Context, record *models.IdempotencyRecord, execute func(ctx context.Context) (*models.Transaction, float64, error)) (*models.Transaction, float64, error) {
+	transaction, newBalance, err := execute(ctx)
+	if err != nil {
+		if releaseErr := s.idempotencyRepo.Release(record.ID); releaseErr != nil {
+			log.Error().Err(releaseErr).Int("idempotency_id", record.ID).Msg("Başarısız isteğin idempotency key'i bırakılamadı")
+		}
+		return nil, 0, err
+	}
+	return transaction, newBalance, nil
+}
+
+// completeIdempotency context'te key'i alan isteğin kaydı varsa onu işlemin sonucuyla verilen DB transaction'ı içinde
+// tamamlar. Hata dönerse transaction geri alınmalıdır; aksi halde para hareketi key'siz commit edilir.
+func (s *TransactionService) completeIdempotency(ctx context.Context, tx *sql.Tx, transactionID int, newBalance float64) error {
+	recordID, ok := ctx.Value(idempotencyRecordKey{}).(int)
+	if !ok || s.idempotencyRepo == nil {
+		return nil
+	}
+	if err := s.idempotencyRepo.Complete(tx, recordID, transactionID, newBalance); err != nil {
+		return fmt.Errorf("idempotency key sonucu kaydedilemedi: %w", err)
+	}
+	return nil
+}
+
+// replayIdempotent tamamlanmış kaydın transaction'ını ve bakiyesini döner (işlem tekrar çalıştırılmaz)
+func (s *TransactionService) replayIdempotent(record *models.IdempotencyRecord) (*models.Transaction, float64, error) {
+	if record.TransactionID == nil {
+		return nil, 0, fmt.Errorf("idempotency kaydının transaction'ı bulunamadı")
+	}
+
+	transaction, err := s.transactionRepo.GetByID(*record.TransactionID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ilk isteğin transaction'ı alınamadı: %w", err)
+	}
+
+	log.Info().
+		Int("user_id", record.UserID).
+		Str("endpoint", record.Endpoint).
+		Int("transaction_id", transaction.ID).
+		Msg("Idempotency-Key tekrarı: ilk sonuç döndü")
+	return transaction, record.NewBalance, nil
+}
+
+// idempotencyRequestHash endpoint ve istek gövdesinden key'in hangi istek için kullanıldığını gösteren hash üretir
+func idempotencyRequestHash(endpoint string, req interface{}) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("istek hash'lenemedi: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(endpoint+":"), body...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// StartIdempotencyKeyCleanup saklama süresi dolan key'leri periyodik olarak siler (ctx iptal edilince durur)
+func StartIdempotencyKeyCleanup(ctx context.Context, repo interfaces.IdempotencyRepositoryInterface, config *IdempotencyConfig) {
+	if config == nil {
+		config = DefaultIdempotencyConfig()
+	}
+	interval := config.CleanupInterval
+	if interval <= 0 {
+		interval = DefaultIdempotencyConfig().CleanupInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Idempotency key cleanup stopped")
+				return
+			case <-ticker.C:
+				deleted, err := repo.DeleteExpired(time.Now().Add(-config.Retention))
+				if err != nil {
+					log.Error().Err(err).Msg("Idempotency key temizliği başarısız")
+					continue
+				}
+				log.Debug().Int64("deleted", deleted).Msg("Süresi dolan idempotency key'ler silindi")
+			}
+		}
+	}()
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// fakeIdempotencyRepository unique (user_id, key) davranışını bellekte taklit eden repository
+type fakeIdempotencyRepository struct {
+	mu           sync.Mutex
+	records      map[string]*models.IdempotencyRecord
+	nextID       int
+	reserveCalls int
+}
+
+func newFakeIdempotencyRepository() *fakeIdempotencyRepository {
+	return &fakeIdempotencyRepository{records: make(map[string]*models.IdempotencyRecord)}
+}
+
+func (f *fakeIdempotencyRepository) Reserve(record *models.IdempotencyRecord, expiredBefore time.Time) (*models.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reserveCalls++
+	mapKey := fmt.Sprintf("%d:%s", record.UserID, record.Key)
+	if existing, ok := f.records[mapKey]; ok {
+		copied := *existing
+		return &copied, false, nil
+	}
+
+	f.nextID++
+	reserved := *record
+	reserved.ID = f.nextID
+	reserved.Status = models.IdempotencyStatusInProgress
+	reserved.CreatedAt = time.Now()
+	f.records[mapKey] = &reserved
+
+	copied := reserved
+	return &copied, true, nil
+}
+
+// Complete tx verilmişse gerçek repository gibi UPDATE'i o DB transaction'ında çalıştırır (sqlmock sırayı doğrular)
+func (f *fakeIdempotencyRepository) Complete(tx *sql.Tx, id int, transactionID int, newBalance float64) error {
+	if tx != nil {
+		if _, err := tx.Exec(`UPDATE idempotency_keys SET status = $1, transaction_id = $2, new_balance = $3 WHERE id = $4`,
+			models.IdempotencyStatusCompleted, transactionID, newBalance, id); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, record := range f.records {
+		if record.ID == id {
+			record.Status = models.IdempotencyStatusCompleted
+			record.TransactionID = &transactionID
+			record.NewBalance = newBalance
+		}
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) Release(id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for mapKey, record := range f.records {
+		if record.ID == id {
+			delete(f.records, mapKey)
+		}
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) DeleteExpired(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeIdempotencyRepository) ReserveCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reserveCalls
+}
+
+// newIdempotentTestService hızlı poll aralığıyla idempotency açık servis oluşturur
+func newIdempotentTestService(txRepo *MockTransactionRepository, repo *fakeIdempotencyRepository) *TransactionService {
+	transactionService := NewTransactionService(txRepo, nil, new(MockBalanceService), nil)
+	transactionService.SetIdempotency(repo, &IdempotencyConfig{
+		Retention:    time.Hour,
+		WaitTimeout:  2 * time.Second,
+		PollInterval: 5 * time.Millisecond,
+	})
+	return transactionService
+}
+
+// TestTransactionService_Idempotency_ConcurrentDuplicateWaitsForFirst, aynı key'le eşzamanlı gelen ikinci isteğin
+// hata almadan ilk isteği beklediğini ve onun sonucunu döndüğünü (işlem bir kez çalışır) test eder.
+func TestTransactionService_Idempotency_ConcurrentDuplicateWaitsForFirst(t *testing.T) {
+	// Arrange
+	mockTxRepo := new(MockTransactionRepository)
+	repo := newFakeIdempotencyRepository()
+	transactionService := newIdempotentTestService(mockTxRepo, repo)
+
+	firstResult := &models.Transaction{ID: 99, Amount: 100, Status: models.StatusCompleted}
+	mockTxRepo.On("GetByID", 99).Return(firstResult, nil)
+
+	var executions atomic.Int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	execute := func(ctx context.Context) (*models.Transaction, float64, error) {
+		executions.Add(1)
+		started <- struct{}{}
+		<-release
+		return firstResult, 400, transactionService.completeIdempotency(ctx, nil, firstResult.ID, 400)
+	}
+
+	ctx := utils.WithIdempotencyKey(context.Background(), "checkout-42")
+	req := &models.CreditRequest{Amount: 100}
+
+	type outcome struct {
+		transaction *models.Transaction
+		newBalance  float64
+		err         error
+	}
+	outcomes := make([]outcome, 2)
+	var wg sync.WaitGroup
+	run := func(i int) {
+		defer wg.Done()
+		transaction, newBalance, err := transactionService.runIdempotent(ctx, 10, IdempotencyEndpointCredit, req, execute)
+		outcomes[i] = outcome{transaction, newBalance, err}
+	}
+
+	// Act
+	wg.Add(1)
+	go run(0)
+	<-started
+
+	wg.Add(1)
+	go run(1)
+	require.Eventually(t, func() bool { return repo.ReserveCalls() >= 3 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, int32(1), executions.Load())
+	for _, result := range outcomes {
+		require.NoError(t, result.err)
+		assert.Equal(t, 99, result.transaction.ID)
+		assert.Equal(t, 400.0, result.newBalance)
+	}
+}
+
+// TestTransactionService_Idempotency_KeyReusedWithDifferentRequest, aynı key'in farklı tutarla tekrar kullanımının reddedildiğini test eder.
+func TestTransactionService_Idempotency_KeyReusedWithDifferentRequest(t *testing.T) {
+	// Arrange
+	repo := newFakeIdempotencyRepository()
+	transactionService := newIdempotentTestService(new(MockTransactionRepository), repo)
+	ctx := utils.WithIdempotencyKey(context.Background(), "checkout-42")
+
+	execute := func(ctx context.Context) (*models.Transaction, float64, error) {
+		return &models.Transaction{ID: 5}, 0, transactionService.completeIdempotency(ctx, nil, 5, 0)
+	}
+	_, _, err := transactionService.runIdempotent(ctx, 10, IdempotencyEndpointTransfer, &models.TransferRequest{ToUserID: 20, Amount: 100}, execute)
+	require.NoError(t, err)
+
+	// Act
+	transaction, _, err := transactionService.runIdempotent(ctx, 10, IdempotencyEndpointTransfer, &models.TransferRequest{ToUserID: 20, Amount: 250}, execute)
+
+	// Assert
+	assert.Nil(t, transaction)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+// TestTransactionService_Idempotency_FailedRequestCanBeRetried, başarısız isteğin key'i bıraktığını ve aynı key'le tekrar çalıştırılabildiğini test eder.
+func TestTransactionService_Idempotency_FailedRequestCanBeRetried(t *testing.T) {
+	// Arrange
+	repo := newFakeIdempotencyRepository()
+	transactionService := newIdempotentTestService(new(MockTransactionRepository), repo)
+	ctx := utils.WithIdempotencyKey(context.Background(), "debit-7")
+	req := &models.DebitRequest{Amount: 50}
+
+	_, _, firstErr := transactionService.runIdempotent(ctx, 10, IdempotencyEndpointDebit, req, func(ctx context.Context) (*models.Transaction, float64, error) {
+		return nil, 0, ErrInsufficientBalance
+	})
+
+	// Act
+	transaction, newBalance, err := transactionService.runIdempotent(ctx, 10, IdempotencyEndpointDebit, req, func(ctx context.Context) (*models.Transaction, float64, error) {
+		return &models.Transaction{ID: 8}, 150, transactionService.completeIdempotency(ctx, nil, 8, 150)
+	})
+
+	// Assert
+	assert.ErrorIs(t, firstErr, ErrInsufficientBalance)
+	require.NoError(t, err)
+	assert.Equal(t, 8, transaction.ID)
+	assert.Equal(t, 150.0, newBalance)
+}
+
+// expectIdempotentCredit key'li bir yatırımın DB adımlarını bekler; key aynı DB transaction'ında, commit'ten önce tamamlanır
+func expectIdempotentCredit(dbMock sqlmock.Sqlmock, userID, transactionID int, completeErr error) {
+	dbMock.ExpectBegin()
+	expectEnsureBalance(dbMock, userID)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(transactionID, time.Now()))
+	expectAdjustBalance(dbMock, userID, 50.0, 100.0)
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	complete := dbMock.ExpectExec("UPDATE idempotency_keys SET status").
+		WithArgs(models.IdempotencyStatusCompleted, transactionID, 150.0, sqlmock.AnyArg())
+	if completeErr != nil {
+		complete.WillReturnError(completeErr)
+		dbMock.ExpectRollback()
+		return
+	}
+	complete.WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+}
+
+// TestTransactionService_Idempotency_CompletedWithCommit, key'in para hareketiyle aynı DB transaction'ında tamamlandığını ve
+// commit sonrası ayrı bir adım olmadan tekrar eden isteğin işlemi yeniden çalıştırmadan ilk transaction'ı aldığını test eder.
+func TestTransactionService_Idempotency_CompletedWithCommit(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	expectIdempotentCredit(dbMock, userID, 77, nil)
+
+	mockTxRepo := new(MockTransactionRepository)
+	original := &models.Transaction{ID: 77, ToUserID: &userID, Amount: 50, Status: models.StatusCompleted}
+	mockTxRepo.On("GetByID", 77).Return(original, nil)
+
+	repo := newFakeIdempotencyRepository()
+	transactionService := NewTransactionService(mockTxRepo, nil, new(MockBalanceService), database)
+	transactionService.SetIdempotency(repo, nil)
+
+	ctx := utils.WithIdempotencyKey(context.Background(), "deposit-1")
+	req := &models.CreditRequest{Amount: 50}
+
+	// Act
+	first, firstBalance, firstErr := transactionService.Credit(ctx, userID, req)
+	replayed, replayedBalance, replayErr := transactionService.Credit(ctx, userID, req)
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, replayErr)
+	assert.Equal(t, 77, first.ID)
+	assert.Equal(t, 77, replayed.ID)
+	assert.Equal(t, firstBalance, replayedBalance)
+	assert.NoError(t, dbMock.ExpectationsWereMet()) // Tekrar eden istek yeni bir DB transaction'ı açmadı
+}
+
+// TestTransactionService_Idempotency_CompleteFailureRollsBack, key tamamlanamazsa para hareketinin de geri alındığını,
+// key'in bırakıldığını ve aynı key'le tekrar denemenin işlemi çalıştırıp ilk sonucu kaydettiğini test eder.
+func TestTransactionService_Idempotency_CompleteFailureRollsBack(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	expectIdempotentCredit(dbMock, userID, 77, errors.New("connection reset"))
+	expectIdempotentCredit(dbMock, userID, 78, nil)
+
+	repo := newFakeIdempotencyRepository()
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetIdempotency(repo, nil)
+
+	ctx := utils.WithIdempotencyKey(context.Background(), "deposit-1")
+	req := &models.CreditRequest{Amount: 50}
+
+	// Act
+	_, _, firstErr := transactionService.Credit(ctx, userID, req)
+	retried, newBalance, retryErr := transactionService.Credit(ctx, userID, req)
+
+	// Assert
+	assert.ErrorContains(t, firstErr, "idempotency key sonucu kaydedilemedi")
+	require.NoError(t, retryErr)
+	assert.Equal(t, 78, retried.ID)
+	assert.Equal(t, 150.0, newBalance)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
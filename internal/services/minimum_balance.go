@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// ErrBelowMinimumBalance işlem bakiyeyi kullanıcının belirlediği minimum bakiyenin altına düşürecekse döner
+var ErrBelowMinimumBalance = errors.New("işlem bakiyeyi belirlediğiniz minimum bakiyenin altına düşürür")
+
+// checkMinimumBalance kilitli bakiyeden amount çıkınca kullanıcının tabanının altına inilmediğini kontrol eder.
+// available aktif hold'lar düşülmüş bakiyedir; taban yalnızca transfer ve çekimlerde uygulanır.
+func checkMinimumBalance(txRepo *db.TransactionRepository, userID int, available, amount float64, transaction *models.Transaction) error {
+	var minimum float64
+	err := txRepo.QueryRow(`SELECT minimum_balance FROM balances WHERE user_id = $1`, userID).Scan(&minimum)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("minimum bakiye sorgusu hatası: %w", err)
+	}
+
+	if minimum > 0 && available-amount < minimum {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("%w. Minimum bakiye: %.2f TL, harcanabilir tutar: %.2f TL", ErrBelowMinimumBalance, minimum, math.Max(available-minimum, 0))
+	}
+	return nil
+}
+
+// GetMinimumBalance kullanıcının belirlediği minimum bakiyeyi getirir
+func (s *BalanceService) GetMinimumBalance(userID int) (*models.MinimumBalance, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	minimum, err := s.balanceRepo.GetMinimumBalance(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MinimumBalance{UserID: userID, MinimumBalance: minimum}, nil
+}
+
+// SetMinimumBalance kullanıcının minimum bakiyesini günceller.
+// Taban mevcut bakiyenin üzerine de çıkarılabilir (yalnızca giden işlemleri engeller), her zaman düşürülebilir.
+func (s *BalanceService) SetMinimumBalance(userID int, req *models.UpdateMinimumBalanceRequest) (*models.MinimumBalance, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.balanceRepo.SetMinimumBalance(userID, *req.MinimumBalance); err != nil {
+		return nil, err
+	}
+	return &models.MinimumBalance{UserID: userID, MinimumBalance: *req.MinimumBalance}, nil
+}
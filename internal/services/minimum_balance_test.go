@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestTransactionService_Debit_MinimumBalance, tabanın altına düşüren çekimin reddedildiğini ve taban düşürülünce
+// aynı çekimin geçtiğini test eder.
+func TestTransactionService_Debit_MinimumBalance(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+
+	// 100 TL bakiye, 80 TL taban: 30 TL çekim bakiyeyi 70'e düşürür → red
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(100.0))
+	expectMinimumBalance(dbMock, userID, 80)
+	dbMock.ExpectRollback()
+
+	// Taban 50'ye düşürüldükten sonra aynı çekim geçer
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(100.0))
+	expectMinimumBalance(dbMock, userID, 50)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(70.0, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	mockBalanceRepo := new(MockBalanceRepository)
+	mockBalanceRepo.On("SetMinimumBalance", userID, 50.0).Return(nil)
+	balanceService := NewBalanceService(mockBalanceRepo)
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	lowered := 50.0
+
+	// Act
+	rejected, _, rejectErr := transactionService.Debit(context.Background(), userID, &models.DebitRequest{Amount: 30})
+	floor, setErr := balanceService.SetMinimumBalance(userID, &models.UpdateMinimumBalanceRequest{MinimumBalance: &lowered})
+	accepted, newBalance, acceptErr := transactionService.Debit(context.Background(), userID, &models.DebitRequest{Amount: 30})
+
+	// Assert
+	assert.Nil(t, rejected)
+	assert.ErrorIs(t, rejectErr, ErrBelowMinimumBalance)
+	assert.Contains(t, rejectErr.Error(), "harcanabilir tutar: 20.00 TL")
+
+	require.NoError(t, setErr)
+	assert.Equal(t, 50.0, floor.MinimumBalance)
+
+	require.NoError(t, acceptErr)
+	assert.Equal(t, models.StatusCompleted, accepted.Status)
+	assert.Equal(t, 70.0, newBalance)
+	mockBalanceRepo.AssertExpectations(t)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestBalanceService_SetMinimumBalance_RejectsInvalid, eksik ve negatif tabanın repository'ye gitmeden reddedildiğini test eder.
+func TestBalanceService_SetMinimumBalance_RejectsInvalid(t *testing.T) {
+	// Arrange
+	mockBalanceRepo := new(MockBalanceRepository)
+	balanceService := NewBalanceService(mockBalanceRepo)
+	negative := -1.0
+
+	// Act
+	_, missingErr := balanceService.SetMinimumBalance(10, &models.UpdateMinimumBalanceRequest{})
+	_, negativeErr := balanceService.SetMinimumBalance(10, &models.UpdateMinimumBalanceRequest{MinimumBalance: &negative})
+
+	// Assert
+	assert.Error(t, missingErr)
+	assert.Error(t, negativeErr)
+	mockBalanceRepo.AssertNotCalled(t, "SetMinimumBalance", 10, negative)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// NotificationService kullanıcı bildirim tercihlerini yönetir ve bildirim gönderen noktalar için kapı görevi görür
+type NotificationService struct {
+	preferenceRepo interfaces.NotificationPreferenceRepositoryInterface
+}
+
+// NewNotificationService yeni service oluşturur
+func NewNotificationService(preferenceRepo interfaces.NotificationPreferenceRepositoryInterface) *NotificationService {
+	return &NotificationService{preferenceRepo: preferenceRepo}
+}
+
+// GetPreferences kullanıcının tercihlerini döner (kayıt yoksa varsayılanlar)
+func (s *NotificationService) GetPreferences(userID int) (*models.NotificationPreferences, error) {
+	preferences, err := s.preferenceRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences gönderilen tercihleri mevcutların üzerine yazar ve kaydeder
+func (s *NotificationService) UpdatePreferences(userID int, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	req.ApplyTo(preferences)
+
+	saved, err := s.preferenceRepo.Upsert(preferences)
+	if err != nil {
+		return nil, fmt.Errorf("bildirim tercihleri güncellenemedi: %w", err)
+	}
+	return saved, nil
+}
+
+// ShouldNotify kullanıcının event için bildirim almak isteyip istemediğini döner.
+// Tercihler okunamazsa bildirim kaybolmasın diye gönderime izin verilir.
+func (s *NotificationService) ShouldNotify(userID int, event string) bool {
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		log.Warn().Err(err).Int("user_id", userID).Str("event", event).Msg("Bildirim tercihleri okunamadı, varsayılan olarak gönderiliyor")
+		return true
+	}
+	return preferences.Allows(event)
+}
+
+// TransferReceivedHook alıcıya bildirim gönderen status hook'unu tercih kontrolüyle sarar:
+// sadece transfer tipindeki işlemlerde ve alıcı transfer_received bildirimini kapatmamışsa deliver çağrılır.
+func (s *NotificationService) TransferReceivedHook(deliver StatusHook) StatusHook {
+	return func(ctx context.Context, transaction *models.Transaction, from, to string) {
+		if transaction.Type != models.TypeTransfer || transaction.ToUserID == nil {
+			return
+		}
+		if !s.ShouldNotify(*transaction.ToUserID, models.NotificationTransferReceived) {
+			log.Debug().
+				Int("transaction_id", transaction.ID).
+				Int("user_id", *transaction.ToUserID).
+				Msg("Transfer bildirimi kullanıcı tercihi nedeniyle gönderilmedi")
+			return
+		}
+		deliver(ctx, transaction, from, to)
+	}
+}
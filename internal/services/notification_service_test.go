@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// MockNotificationPreferenceRepository bildirim tercihleri repository mock'u
+type MockNotificationPreferenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationPreferenceRepository) GetByUserID(userID int) (*models.NotificationPreferences, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationPreferences), args.Error(1)
+}
+func (m *MockNotificationPreferenceRepository) Upsert(preferences *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	args := m.Called(preferences)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationPreferences), args.Error(1)
+}
+
+// TestNotificationService_TransferReceivedHook_DisabledPreferenceSuppresses, transfer_received kapalı alıcıya bildirimin gitmediğini, açık alıcıya gittiğini test eder.
+func TestNotificationService_TransferReceivedHook_DisabledPreferenceSuppresses(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockNotificationPreferenceRepository)
+	optedOut := models.DefaultNotificationPreferences(20)
+	optedOut.TransferReceived = false
+	mockRepo.On("GetByUserID", 20).Return(optedOut, nil)
+	mockRepo.On("GetByUserID", 30).Return(nil, nil) // kayıt yok: varsayılanlar
+
+	var delivered []int
+	hook := NewNotificationService(mockRepo).TransferReceivedHook(func(ctx context.Context, transaction *models.Transaction, from, to string) {
+		delivered = append(delivered, *transaction.ToUserID)
+	})
+
+	optedOutUser, defaultUser := 20, 30
+
+	// Act
+	hook(context.Background(), &models.Transaction{ID: 1, Type: models.TypeTransfer, ToUserID: &optedOutUser}, models.StatusPending, models.StatusCompleted)
+	hook(context.Background(), &models.Transaction{ID: 2, Type: models.TypeTransfer, ToUserID: &defaultUser}, models.StatusPending, models.StatusCompleted)
+
+	// Assert
+	assert.Equal(t, []int{30}, delivered)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestNotificationService_UpdatePreferences_MergesWithDefaults, kısmi güncellemenin varsayılanlarla birleştirilip kaydedildiğini test eder.
+func TestNotificationService_UpdatePreferences_MergesWithDefaults(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockNotificationPreferenceRepository)
+	mockRepo.On("GetByUserID", 10).Return(nil, nil)
+	merged := &models.NotificationPreferences{UserID: 10, TransferReceived: true, LowBalance: false, SecurityEvents: true}
+	mockRepo.On("Upsert", mock.MatchedBy(func(preferences *models.NotificationPreferences) bool {
+		return *preferences == *merged
+	})).Return(merged, nil)
+
+	disabled := false
+
+	// Act
+	preferences, err := NewNotificationService(mockRepo).UpdatePreferences(10, &models.UpdateNotificationPreferencesRequest{LowBalance: &disabled})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, merged, preferences)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// SetBulkStatusMaxIDs toplu status güncellemesinde tek istekte kabul edilen en fazla ID sayısını ayarlar (<= 0 = varsayılan)
+func (s *TransactionService) SetBulkStatusMaxIDs(maxIDs int) {
+	s.bulkStatusMaxIDs = maxIDs
+}
+
+// BulkUpdateStatus takılı kalmış transaction'ları toplu olarak failed/cancelled durumuna alır (incident kurtarma).
+// Her ID kendi DB transaction'ında işlenir: geçiş CanTransition ile doğrulanır, işleme bağlı bakiye hareketi
+// varsa geri alınır ve değişiklik audit log'a yazılır. Bir ID'nin hatası diğerlerini etkilemez; sonuçlar ID bazında döner.
+func (s *TransactionService) BulkUpdateStatus(ctx context.Context, req *models.BulkStatusUpdateRequest, decision *models.ReviewDecision) (*models.BulkStatusUpdateResult, error) {
+	maxIDs := s.bulkStatusMaxIDs
+	if maxIDs <= 0 {
+		maxIDs = models.DefaultBulkStatusMaxIDs
+	}
+	if err := req.Validate(maxIDs); err != nil {
+		return nil, err
+	}
+	decision.Reason = req.Reason
+
+	result := &models.BulkStatusUpdateResult{
+		Status:  req.Status,
+		Results: make([]models.BulkStatusItemResult, 0, len(req.TransactionIDs)),
+	}
+
+	for _, id := range req.TransactionIDs {
+		item := s.updateTransactionStatus(ctx, id, req.Status, decision)
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	return result, nil
+}
+
+// updateTransactionStatus tek transaction'ın status'unu kilit altında değiştirir ve sonucunu döner
+func (s *TransactionService) updateTransactionStatus(ctx context.Context, id int, status string, decision *models.ReviewDecision) models.BulkStatusItemResult {
+	item := models.BulkStatusItemResult{TransactionID: id}
+	var transaction *models.Transaction
+	var previousStatus string
+
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+		item.Compensated = false
+
+		transaction = &models.Transaction{ID: id}
+		err := txRepo.QueryRow(`
+			SELECT from_user_id, to_user_id, amount, type, status
+			FROM transactions
+			WHERE id = $1
+			FOR UPDATE
+		`, id).Scan(&transaction.FromUserID, &transaction.ToUserID, &transaction.Amount, &transaction.Type, &transaction.Status)
+		if err == sql.ErrNoRows {
+			return ErrTransactionNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("transaction alınamadı: %w", err)
+		}
+		previousStatus = transaction.Status
+
+		if err := transaction.SetStatus(status); err != nil {
+			return err
+		}
+
+		compensated, err := compensateBalanceChanges(txRepo, id)
+		if err != nil {
+			return err
+		}
+		item.Compensated = compensated
+
+		if _, err := txRepo.Exec(`UPDATE transactions SET status = $1 WHERE id = $2`, transaction.Status, id); err != nil {
+			return fmt.Errorf("transaction status database'de güncellenemedi: %w", err)
+		}
+
+		// İncelemedeki transfer toplu olarak failed'a alınırsa açık inceleme kaydı da reddedilmiş sayılır;
+		// aksi halde inceleme kuyruğunda karar verilemeyecek bir kayıt kalırdı
+		if previousStatus == models.StatusPendingReview {
+			if err := closeOpenReview(txRepo, id, decision); err != nil {
+				return err
+			}
+		}
+
+		oldData, _ := json.Marshal(map[string]string{"status": previousStatus})
+		newData, _ := json.Marshal(map[string]interface{}{"status": transaction.Status, "compensated": compensated})
+		return insertTransactionAuditLog(txRepo, id, models.AuditActionBulkStatus, oldData, newData, decision)
+	})
+
+	item.PreviousStatus = previousStatus
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+
+	s.fireStatusHooks(ctx, previousStatus, transaction, nil)
+	item.Success = true
+	item.Status = transaction.Status
+	return item
+}
+
+// closeOpenReview transaction'ın açık inceleme kaydını admin ve sebeple birlikte rejected olarak kapatır
+func closeOpenReview(txRepo *db.TransactionRepository, transactionID int, decision *models.ReviewDecision) error {
+	_, err := txRepo.Exec(`
+		UPDATE transaction_reviews
+		SET status = $1, note = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE transaction_id = $4 AND status = $5
+	`, models.ReviewStatusRejected, decision.Reason, decision.AdminUserID, transactionID, models.ReviewStatusOpen)
+	if err != nil {
+		return fmt.Errorf("inceleme kaydı kapatılamadı: %w", err)
+	}
+	return nil
+}
+
+// compensateBalanceChanges transaction'a bağlı bakiye hareketlerini ters yönde uygular.
+// Bakiyeler yalnızca tamamlanan işlemlerde değiştiğinden normalde hareket yoktur; yarım kalmış kayıtlar içindir.
+// Geri alma bakiyeyi negatife düşürecekse hata döner ve hiçbir değişiklik uygulanmaz.
+func compensateBalanceChanges(txRepo *db.TransactionRepository, transactionID int) (bool, error) {
+	type balanceChange struct {
+		userID int
+		change float64
+	}
+
+	rows, err := txRepo.Query(`
+		SELECT user_id, SUM(change_amount)
+		FROM balance_history
+		WHERE transaction_id = $1
+		GROUP BY user_id
+		ORDER BY user_id
+	`, transactionID)
+	if err != nil {
+		return false, fmt.Errorf("bakiye hareketleri alınamadı: %w", err)
+	}
+
+	var changes []balanceChange
+	for rows.Next() {
+		var c balanceChange
+		if err := rows.Scan(&c.userID, &c.change); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("bakiye hareketi okunamadı: %w", err)
+		}
+		if c.change != 0 {
+			changes = append(changes, c)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("bakiye hareketleri alınamadı: %w", err)
+	}
+
+	for _, c := range changes {
+		previous, next, err := adjustBalance(txRepo, c.userID, -c.change)
+		if err != nil {
+			return false, fmt.Errorf("kullanıcı %d bakiyesi geri alınamadı: %w", c.userID, err)
+		}
+		if err := recordBalanceChange(txRepo, c.userID, previous, next, models.BalanceReasonCompensation, transactionID); err != nil {
+			return false, err
+		}
+	}
+
+	return len(changes) > 0, nil
+}
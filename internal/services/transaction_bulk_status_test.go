@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// expectLockedTransaction bulk status için FOR UPDATE ile okunan transaction satırını mock'lar
+func expectLockedTransaction(dbMock sqlmock.Sqlmock, id int, status string) {
+	dbMock.ExpectQuery("SELECT from_user_id, to_user_id, amount, type, status FROM transactions").WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"from_user_id", "to_user_id", "amount", "type", "status"}).
+			AddRow(10, 20, 50.0, "transfer", status))
+}
+
+// TestTransactionService_BulkUpdateStatus_MixedTransitions, tek istekte geçerli geçişlerin uygulanıp audit log'a
+// yazıldığını, yarım kalmış bakiye hareketinin geri alındığını, geçersiz geçiş ve bulunamayan ID'nin ise
+// diğerlerini etkilemeden ID bazında hata döndüğünü test eder.
+func TestTransactionService_BulkUpdateStatus_MixedTransitions(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// ID 1: pending → cancelled, bakiye hareketi yok
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 1, models.StatusPending)
+	dbMock.ExpectQuery("FROM balance_history").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "sum"}))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusCancelled, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs(models.AuditEntityTransaction, 1, models.AuditActionBulkStatus, 99,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), "incident-42", "10.0.0.1", "ops-tool").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	// ID 2: completed → cancelled geçersiz geçiş
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 2, models.StatusCompleted)
+	dbMock.ExpectRollback()
+
+	// ID 3: bulunamadı
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT from_user_id, to_user_id, amount, type, status FROM transactions").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"from_user_id", "to_user_id", "amount", "type", "status"}))
+	dbMock.ExpectRollback()
+
+	// ID 4: pending kalmış ama alıcıya 50 TL yansımış → geri alınır
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 4, models.StatusPending)
+	dbMock.ExpectQuery("FROM balance_history").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "sum"}).AddRow(20, 50.0))
+	expectAdjustBalance(dbMock, 20, -50.0, 80.0)
+	dbMock.ExpectExec("INSERT INTO balance_history").
+		WithArgs(20, 80.0, 30.0, -50.0, models.BalanceReasonCompensation, 4).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusCancelled, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	req := &models.BulkStatusUpdateRequest{TransactionIDs: []int{1, 2, 3, 4, 1}, Status: " Cancelled ", Reason: "incident-42"}
+	decision := &models.ReviewDecision{AdminUserID: 99, IPAddress: "10.0.0.1", UserAgent: "ops-tool"}
+
+	// Act
+	result, err := transactionService.BulkUpdateStatus(context.Background(), req, decision)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, result.Status)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 2, result.Failed)
+	require.Len(t, result.Results, 4)
+
+	assert.True(t, result.Results[0].Success)
+	assert.Equal(t, models.StatusPending, result.Results[0].PreviousStatus)
+	assert.Equal(t, models.StatusCancelled, result.Results[0].Status)
+	assert.False(t, result.Results[0].Compensated)
+
+	assert.False(t, result.Results[1].Success)
+	assert.Equal(t, models.StatusCompleted, result.Results[1].PreviousStatus)
+	assert.NotEmpty(t, result.Results[1].Error)
+
+	assert.False(t, result.Results[2].Success)
+	assert.Equal(t, ErrTransactionNotFound.Error(), result.Results[2].Error)
+
+	assert.True(t, result.Results[3].Success)
+	assert.True(t, result.Results[3].Compensated)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_BulkUpdateStatus_ClosesOpenReview, incelemedeki transfer toplu olarak failed'a alındığında
+// açık inceleme kaydının aynı DB transaction'ında admin ve sebeple rejected olarak kapatıldığını test eder.
+func TestTransactionService_BulkUpdateStatus_ClosesOpenReview(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 5, models.StatusPendingReview)
+	dbMock.ExpectQuery("FROM balance_history").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "sum"}))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusFailed, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("UPDATE transaction_reviews").
+		WithArgs(models.ReviewStatusRejected, "incident-42", 99, 5, models.ReviewStatusOpen).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	req := &models.BulkStatusUpdateRequest{TransactionIDs: []int{5}, Status: models.StatusFailed, Reason: "incident-42"}
+	decision := &models.ReviewDecision{AdminUserID: 99}
+
+	// Act
+	result, err := transactionService.BulkUpdateStatus(context.Background(), req, decision)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, models.StatusPendingReview, result.Results[0].PreviousStatus)
+	assert.Equal(t, models.StatusFailed, result.Results[0].Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_BulkUpdateStatus_RejectsInvalidRequest, geçersiz hedef status ve limit aşımının
+// DB'ye gitmeden reddedildiğini test eder.
+func TestTransactionService_BulkUpdateStatus_RejectsInvalidRequest(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetBulkStatusMaxIDs(2)
+
+	// Act
+	_, completedErr := transactionService.BulkUpdateStatus(context.Background(),
+		&models.BulkStatusUpdateRequest{TransactionIDs: []int{1}, Status: models.StatusCompleted, Reason: "x"}, &models.ReviewDecision{})
+	_, limitErr := transactionService.BulkUpdateStatus(context.Background(),
+		&models.BulkStatusUpdateRequest{TransactionIDs: []int{1, 2, 3}, Status: models.StatusFailed, Reason: "x"}, &models.ReviewDecision{})
+
+	// Assert
+	assert.Error(t, completedErr)
+	assert.Error(t, limitErr)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
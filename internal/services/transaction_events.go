@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// TransactionCreatedEvent transaction tamamlandığında loglanan canonical event adı
+const TransactionCreatedEvent = "transaction_created"
+
+// logTransactionCreated transaction sonucunu tek bir structured event olarak loglar.
+// Request ID context'ten alınır; başarısız işlemler de aynı alanlarla (error eklenerek) loglanır.
+func logTransactionCreated(ctx context.Context, transaction *models.Transaction, startedAt time.Time, err error) {
+	status := transaction.Status
+	if err != nil {
+		status = models.StatusFailed
+	}
+
+	var event *zerolog.Event
+	if err != nil {
+		event = log.Warn().Err(err)
+	} else {
+		event = log.Info()
+	}
+
+	event = event.
+		Str("event", TransactionCreatedEvent).
+		Str("request_id", utils.RequestIDFromContext(ctx)).
+		Int("transaction_id", transaction.ID).
+		Str("type", transaction.Type).
+		Str("status", status).
+		Float64("amount", transaction.Amount).
+		Dur("duration", time.Since(startedAt))
+
+	if transaction.FromUserID != nil {
+		event = event.Int("from_user_id", *transaction.FromUserID)
+	}
+	if transaction.ToUserID != nil {
+		event = event.Int("to_user_id", *transaction.ToUserID)
+	}
+
+	event.Msg("Transaction created")
+}
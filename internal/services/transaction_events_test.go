@@ -0,0 +1,75 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// captureLog global logger'ı buffer'a yönlendirir, test bitince eski haline döndürür
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+// TestLogTransactionCreated_Fields, transaction event'inin beklenen alanları içerdiğini test eder.
+func TestLogTransactionCreated_Fields(t *testing.T) {
+	// Arrange
+	buf := captureLog(t)
+	ctx := utils.WithRequestID(context.Background(), "req-123")
+	transaction := models.NewTransferTransaction(10, 20, 250, "kira")
+	transaction.ID = 42
+	transaction.Status = models.StatusCompleted
+
+	// Act
+	logTransactionCreated(ctx, transaction, time.Now().Add(-5*time.Millisecond), nil)
+
+	// Assert
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	assert.Equal(t, TransactionCreatedEvent, event["event"])
+	assert.Equal(t, "info", event["level"])
+	assert.Equal(t, "req-123", event["request_id"])
+	assert.Equal(t, float64(42), event["transaction_id"])
+	assert.Equal(t, float64(10), event["from_user_id"])
+	assert.Equal(t, float64(20), event["to_user_id"])
+	assert.Equal(t, float64(250), event["amount"])
+	assert.Equal(t, "transfer", event["type"])
+	assert.Equal(t, models.StatusCompleted, event["status"])
+	assert.Contains(t, event, "duration")
+}
+
+// TestLogTransactionCreated_Failure, başarısız transaction'ın failed status ve hata ile loglandığını test eder.
+func TestLogTransactionCreated_Failure(t *testing.T) {
+	// Arrange
+	buf := captureLog(t)
+	transaction := models.NewCreditTransaction(7, 100, "yatırma")
+
+	// Act
+	logTransactionCreated(context.Background(), transaction, time.Now(), errors.New("bakiye güncellenemedi"))
+
+	// Assert
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	assert.Equal(t, "warn", event["level"])
+	assert.Equal(t, models.StatusFailed, event["status"])
+	assert.Equal(t, "bakiye güncellenemedi", event["error"])
+	assert.Equal(t, "", event["request_id"])
+	assert.Equal(t, float64(7), event["to_user_id"])
+	assert.NotContains(t, event, "from_user_id")
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/storage"
+)
+
+// Transaction export dosya formatları
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatJSONL = "jsonl"
+)
+
+// transactionExportColumns CSV export'un başlık satırı
+var transactionExportColumns = []string{"id", "from_user_id", "to_user_id", "amount", "type", "status", "description", "channel", "created_at"}
+
+// TransactionExportConfig günlük transaction export job'ı ayarları
+type TransactionExportConfig struct {
+	Format   string        // csv | jsonl
+	Prefix   string        // Storage'da dosyaların yazıldığı klasör
+	Interval time.Duration // Job'ın çalışma sıklığı (her turda bir önceki gün yazılır)
+}
+
+// DefaultTransactionExportConfig varsayılan export ayarları (günde bir, CSV)
+func DefaultTransactionExportConfig() *TransactionExportConfig {
+	return &TransactionExportConfig{
+		Format:   ExportFormatCSV,
+		Prefix:   "exports/transactions",
+		Interval: 24 * time.Hour,
+	}
+}
+
+// TransactionExportResult tamamlanan export'un özeti
+type TransactionExportResult struct {
+	Key  string
+	Rows int
+}
+
+// ExportTransactions verilen günün (UTC) transaction'larını storage'a yazar.
+// Satırlar repository'den stream edilip doğrudan storage'a aktarılır, belleğe toplanmaz.
+// Aynı gün tekrar export edilirse dosyanın üzerine yazılır.
+func ExportTransactions(ctx context.Context, repo interfaces.TransactionRepositoryInterface, store storage.Storage, config *TransactionExportConfig, day time.Time) (*TransactionExportResult, error) {
+	if config == nil {
+		config = DefaultTransactionExportConfig()
+	}
+	if config.Format != ExportFormatCSV && config.Format != ExportFormatJSONL {
+		return nil, fmt.Errorf("geçersiz export formatı: %s", config.Format)
+	}
+
+	from := startOfDay(day.UTC())
+	to := from.AddDate(0, 0, 1)
+	key := fmt.Sprintf("%s/transactions-%s.%s", strings.Trim(config.Prefix, "/"), from.Format("2006-01-02"), config.Format)
+
+	reader, writer := io.Pipe()
+	rows := 0
+	go func() {
+		writer.CloseWithError(writeTransactionExport(writer, repo, config.Format, from, to, &rows))
+	}()
+
+	if err := store.Put(ctx, key, reader); err != nil {
+		reader.CloseWithError(err)
+		return nil, fmt.Errorf("transaction export yazılamadı: %w", err)
+	}
+
+	log.Info().Str("key", key).Int("rows", rows).Msg("Transaction export tamamlandı")
+
+	return &TransactionExportResult{Key: key, Rows: rows}, nil
+}
+
+// writeTransactionExport tarih aralığındaki transaction'ları seçilen formatta w'ye yazar
+func writeTransactionExport(w io.Writer, repo interfaces.TransactionRepositoryInterface, format string, from, to time.Time, rows *int) error {
+	if format == ExportFormatJSONL {
+		encoder := json.NewEncoder(w)
+		return repo.StreamByDateRange(from, to, func(tx *models.Transaction) error {
+			*rows++
+			return encoder.Encode(tx)
+		})
+	}
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(transactionExportColumns); err != nil {
+		return err
+	}
+	err := repo.StreamByDateRange(from, to, func(tx *models.Transaction) error {
+		*rows++
+		return csvWriter.Write(transactionExportRecord(tx))
+	})
+	if err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// transactionExportRecord transaction'ı CSV satırına çevirir (boş taraf boş hücre olarak yazılır)
+func transactionExportRecord(tx *models.Transaction) []string {
+	optionalID := func(id *int) string {
+		if id == nil {
+			return ""
+		}
+		return strconv.Itoa(*id)
+	}
+
+	return []string{
+		strconv.Itoa(tx.ID),
+		optionalID(tx.FromUserID),
+		optionalID(tx.ToUserID),
+		strconv.FormatFloat(tx.Amount, 'f', models.AmountPrecision(), 64),
+		tx.Type,
+		tx.Status,
+		tx.Description,
+		tx.Channel,
+		tx.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// StartTransactionExporter bir önceki günün export'unu hemen ve periyodik olarak çalıştırır
+func StartTransactionExporter(ctx context.Context, repo interfaces.TransactionRepositoryInterface, store storage.Storage, config *TransactionExportConfig) {
+	if config == nil {
+		config = DefaultTransactionExportConfig()
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultTransactionExportConfig().Interval
+	}
+
+	go func() {
+		run := func() {
+			yesterday := time.Now().UTC().AddDate(0, 0, -1)
+			if _, err := ExportTransactions(ctx, repo, store, config, yesterday); err != nil {
+				log.Error().Err(err).Msg("Transaction export başarısız")
+			}
+		}
+
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Transaction exporter stopped")
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
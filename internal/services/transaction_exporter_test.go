@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/storage"
+)
+
+// streamTransactions StreamByDateRange mock'una verilen transaction'ları callback'e iletir
+func streamTransactions(transactions ...*models.Transaction) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func(*models.Transaction) error)
+		for _, tx := range transactions {
+			if err := fn(tx); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// TestExportTransactions_WritesDayToLocalStorage, günün transaction'larının local storage'a beklenen satırlarla
+// CSV ve JSONL olarak yazıldığını test eder.
+func TestExportTransactions_WritesDayToLocalStorage(t *testing.T) {
+	// Arrange
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	day := time.Date(2025, 9, 1, 15, 30, 0, 0, time.UTC)
+	from := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	sender, receiver := 10, 20
+	transactions := []*models.Transaction{
+		{ID: 1, FromUserID: &sender, ToUserID: &receiver, Amount: 150.5, Type: models.TypeTransfer, Status: models.StatusCompleted,
+			Description: "kira, eylül", Channel: "web", CreatedAt: from.Add(9 * time.Hour)},
+		{ID: 2, ToUserID: &sender, Amount: 40, Type: models.TypeCredit, Status: models.StatusCompleted,
+			Description: "Hesaba para yatırma", CreatedAt: from.Add(10 * time.Hour)},
+	}
+
+	mockTxRepo := new(MockTransactionRepository)
+	mockTxRepo.On("StreamByDateRange", from, to, mock.Anything).Run(streamTransactions(transactions...)).Return(nil)
+
+	// Act
+	csvResult, csvErr := ExportTransactions(context.Background(), mockTxRepo, store,
+		&TransactionExportConfig{Format: ExportFormatCSV, Prefix: "exports/transactions"}, day)
+	jsonlResult, jsonlErr := ExportTransactions(context.Background(), mockTxRepo, store,
+		&TransactionExportConfig{Format: ExportFormatJSONL, Prefix: "exports/transactions"}, day)
+
+	// Assert
+	require.NoError(t, csvErr)
+	assert.Equal(t, "exports/transactions/transactions-2025-09-01.csv", csvResult.Key)
+	assert.Equal(t, 2, csvResult.Rows)
+
+	csvPath, err := store.Path(csvResult.Key)
+	require.NoError(t, err)
+	csvContent, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"id,from_user_id,to_user_id,amount,type,status,description,channel,created_at",
+		`1,10,20,150.50,transfer,completed,"kira, eylül",web,2025-09-01T09:00:00Z`,
+		"2,,10,40.00,credit,completed,Hesaba para yatırma,,2025-09-01T10:00:00Z",
+	}, "\n")+"\n", string(csvContent))
+
+	require.NoError(t, jsonlErr)
+	assert.Equal(t, 2, jsonlResult.Rows)
+	jsonlContent, err := os.ReadFile(filepath.Join(filepath.Dir(csvPath), "transactions-2025-09-01.jsonl"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(jsonlContent)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"id":1`)
+	assert.Contains(t, lines[1], `"type":"credit"`)
+	mockTxRepo.AssertExpectations(t)
+}
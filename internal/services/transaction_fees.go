@@ -0,0 +1,50 @@
+package services
+
+import (
+	"math"
+
+	"github.com/onerilhan/go-payment-api/internal/features"
+)
+
+// FeeConfig transfer ücreti ayarları
+type FeeConfig struct {
+	TransferFeeRate float64 // Transfer tutarının yüzdesi olarak ücret (0.001 = %0.1)
+	MinTransferFee  float64 // Minimum ücret
+	MaxTransferFee  float64 // Maksimum ücret (0 = üst sınır yok)
+}
+
+// DefaultFeeConfig varsayılan ücret ayarları
+func DefaultFeeConfig() *FeeConfig {
+	return &FeeConfig{
+		TransferFeeRate: 0.001,
+		MinTransferFee:  1,
+		MaxTransferFee:  50,
+	}
+}
+
+// SetFeeConfig ücret ayarlarını değiştirir (nil ise varsayılan kullanılır)
+func (s *TransactionService) SetFeeConfig(config *FeeConfig) {
+	if config == nil {
+		config = DefaultFeeConfig()
+	}
+	s.feeConfig = config
+}
+
+// CalculateTransferFee transfer tutarı için ücreti hesaplar.
+// "fees" feature flag'i kapalıysa hesaplama yapılmaz ve 0 döner.
+func (s *TransactionService) CalculateTransferFee(amount float64) float64 {
+	if !features.Enabled(features.Fees) || s.feeConfig == nil || amount <= 0 {
+		return 0
+	}
+
+	fee := amount * s.feeConfig.TransferFeeRate
+	if fee < s.feeConfig.MinTransferFee {
+		fee = s.feeConfig.MinTransferFee
+	}
+	if s.feeConfig.MaxTransferFee > 0 && fee > s.feeConfig.MaxTransferFee {
+		fee = s.feeConfig.MaxTransferFee
+	}
+
+	// Kuruş hassasiyetine yuvarla
+	return math.Round(fee*100) / 100
+}
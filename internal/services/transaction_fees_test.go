@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/features"
+)
+
+// withFeatureFlags test süresince flag'leri değiştirir, test bitince eski hallerine döndürür
+func withFeatureFlags(t *testing.T, flags map[string]bool) {
+	previous := features.All()
+	features.Set(flags)
+	t.Cleanup(func() { features.Set(previous) })
+}
+
+// TestTransactionService_CalculateTransferFee_DisabledFlagSkipsFee, "fees" flag'i kapalıyken ücret hesaplanmadığını test eder.
+func TestTransactionService_CalculateTransferFee_DisabledFlagSkipsFee(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Fees: false})
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	transactionService.SetFeeConfig(&FeeConfig{TransferFeeRate: 0.01, MinTransferFee: 5})
+
+	// Act
+	fee := transactionService.CalculateTransferFee(1000)
+
+	// Assert
+	assert.Equal(t, 0.0, fee)
+}
+
+// TestTransactionService_CalculateTransferFee_EnabledFlagAppliesBounds, flag açıkken ücretin oran ve min/max sınırlarıyla hesaplandığını test eder.
+func TestTransactionService_CalculateTransferFee_EnabledFlagAppliesBounds(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Fees: true})
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	transactionService.SetFeeConfig(&FeeConfig{TransferFeeRate: 0.01, MinTransferFee: 1, MaxTransferFee: 50})
+
+	// Act & Assert
+	assert.Equal(t, 1.0, transactionService.CalculateTransferFee(20))
+	assert.Equal(t, 12.35, transactionService.CalculateTransferFee(1234.5))
+	assert.Equal(t, 50.0, transactionService.CalculateTransferFee(100000))
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// StatusHook transaction status geçişi commit edildikten sonra çalışan yan etki (webhook, hold serbest bırakma, bildirim...)
+type StatusHook func(ctx context.Context, transaction *models.Transaction, from, to string)
+
+// StatusTransition hook registry anahtarı: (from, to) status çifti
+type StatusTransition struct {
+	From string
+	To   string
+}
+
+// StatusHookRegistry (from, to) geçişlerine bağlı hook'ları tutar (eşzamanlı kullanım güvenli)
+type StatusHookRegistry struct {
+	mutex sync.RWMutex
+	hooks map[StatusTransition][]StatusHook
+}
+
+// NewStatusHookRegistry boş hook registry'si oluşturur
+func NewStatusHookRegistry() *StatusHookRegistry {
+	return &StatusHookRegistry{
+		hooks: make(map[StatusTransition][]StatusHook),
+	}
+}
+
+// Register geçişe hook ekler; state machine'in izin vermediği geçişler reddedilir
+func (r *StatusHookRegistry) Register(from, to string, hook StatusHook) error {
+	if hook == nil {
+		return fmt.Errorf("hook nil olamaz")
+	}
+	if err := (&models.Transaction{Status: from}).CanTransition(to); err != nil {
+		return fmt.Errorf("hook kaydedilemedi: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := StatusTransition{From: from, To: to}
+	r.hooks[key] = append(r.hooks[key], hook)
+	return nil
+}
+
+// Fire geçişe kayıtlı hook'ları kayıt sırasıyla çalıştırır.
+// Panikleyen hook loglanır, diğer hook'lar ve çağıran etkilenmez.
+func (r *StatusHookRegistry) Fire(ctx context.Context, transaction *models.Transaction, from, to string) {
+	r.mutex.RLock()
+	hooks := r.hooks[StatusTransition{From: from, To: to}]
+	r.mutex.RUnlock()
+
+	for _, hook := range hooks {
+		runStatusHook(ctx, hook, transaction, from, to)
+	}
+}
+
+// runStatusHook tek bir hook'u panic recovery ile çalıştırır
+func runStatusHook(ctx context.Context, hook StatusHook, transaction *models.Transaction, from, to string) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Error().
+				Interface("recover", rec).
+				Int("transaction_id", transaction.ID).
+				Str("from", from).
+				Str("to", to).
+				Msg("Status hook panikledi ama toparlandı")
+		}
+	}()
+
+	hook(ctx, transaction, from, to)
+}
+
+// SetStatusHooks status geçiş hook'larını ayarlar (nil ise hook çalıştırılmaz)
+func (s *TransactionService) SetStatusHooks(hooks *StatusHookRegistry) {
+	s.statusHooks = hooks
+}
+
+// fireStatusHooks commit edilmiş geçiş için hook'ları çalıştırır.
+// Rollback olan işlemler DB'ye yazılmadığından (err != nil) hook tetiklenmez.
+func (s *TransactionService) fireStatusHooks(ctx context.Context, from string, transaction *models.Transaction, err error) {
+	if s.statusHooks == nil || err != nil || transaction.Status == from {
+		return
+	}
+	s.statusHooks.Fire(ctx, transaction, from, transaction.Status)
+}
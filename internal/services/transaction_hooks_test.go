@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestStatusHookRegistry_FiresOnlyMatchingTransition, commit edilen işlemde sadece eşleşen geçişin hook'unun çalıştığını test eder.
+func TestStatusHookRegistry_FiresOnlyMatchingTransition(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	dbMock.ExpectBegin()
+	expectEnsureBalance(dbMock, userID)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+	expectAdjustBalance(dbMock, userID, 50.0, 100.0)
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	var completed []int
+	var failed []int
+	hooks := NewStatusHookRegistry()
+	require.NoError(t, hooks.Register(models.StatusPending, models.StatusCompleted, func(ctx context.Context, transaction *models.Transaction, from, to string) {
+		completed = append(completed, transaction.ID)
+	}))
+	require.NoError(t, hooks.Register(models.StatusPending, models.StatusFailed, func(ctx context.Context, transaction *models.Transaction, from, to string) {
+		failed = append(failed, transaction.ID)
+	}))
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+	transactionService.SetStatusHooks(hooks)
+
+	// Act
+	_, _, err = transactionService.Credit(context.Background(), userID, &models.CreditRequest{Amount: 50})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int{7}, completed)
+	assert.Empty(t, failed)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestStatusHookRegistry_RejectsInvalidTransition, state machine'in izin vermediği geçişe hook kaydedilemediğini test eder.
+func TestStatusHookRegistry_RejectsInvalidTransition(t *testing.T) {
+	// Arrange
+	hooks := NewStatusHookRegistry()
+	noop := func(ctx context.Context, transaction *models.Transaction, from, to string) {}
+
+	// Act
+	err := hooks.Register(models.StatusCompleted, models.StatusPending, noop)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+// TestStatusHookRegistry_RecoversFromPanickingHook, panikleyen hook'un sonraki hook'ları engellemediğini test eder.
+func TestStatusHookRegistry_RecoversFromPanickingHook(t *testing.T) {
+	// Arrange
+	hooks := NewStatusHookRegistry()
+	called := false
+	require.NoError(t, hooks.Register(models.StatusPendingReview, models.StatusFailed, func(ctx context.Context, transaction *models.Transaction, from, to string) {
+		panic("webhook patladı")
+	}))
+	require.NoError(t, hooks.Register(models.StatusPendingReview, models.StatusFailed, func(ctx context.Context, transaction *models.Transaction, from, to string) {
+		called = true
+	}))
+
+	// Act
+	hooks.Fire(context.Background(), &models.Transaction{ID: 1, Status: models.StatusFailed}, models.StatusPendingReview, models.StatusFailed)
+
+	// Assert
+	assert.True(t, called)
+}
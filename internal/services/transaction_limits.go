@@ -0,0 +1,457 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// ErrMemoTransferRateLimited aynı alıcıya açıklamalı transfer sınırı aşıldığında döner
+var ErrMemoTransferRateLimited = errors.New("aynı alıcıya açıklamalı transfer limiti aşıldı")
+
+// ErrDailyLimitExceeded işlem günlük giden tutar limitini (debit + giden transfer) aşacaksa döner
+var ErrDailyLimitExceeded = errors.New("günlük limit aşıldı")
+
+// ErrNewAccountTransferCooldown yeni kayıt olmuş hesap bekleme süresi dolmadan transfer yapmaya çalıştığında döner
+var ErrNewAccountTransferCooldown = errors.New("yeni hesaplar kayıttan sonra bir süre transfer yapamaz")
+
+// DefaultMaxTransactionAmount tek işlemde (transfer, yatırma, çekme) varsayılan maksimum tutar
+const DefaultMaxTransactionAmount = 1000000
+
+// TransactionLimitConfig kullanıcı bazlı transaction limit ayarları
+type TransactionLimitConfig struct {
+	// MinAmountByType / MaxAmountByType işlem tipine göre tek işlemde izin verilen tutar aralığı (0 veya tanımsız = sınır yok)
+	MinAmountByType map[string]float64
+	MaxAmountByType map[string]float64
+	// DailyAmountLimitDefault günlük toplam giden tutar limiti; çekmeler ve giden transferler sayılır (0 = limitsiz)
+	DailyAmountLimitDefault float64
+	// DailyAmountByRole role göre DailyAmountLimitDefault'u ezer (ör. mod için daha yüksek; 0 = o role limitsiz)
+	DailyAmountByRole map[string]float64
+
+	// DailyCountByRole role göre günlük maksimum transaction sayısı (0 veya tanımsız = limitsiz)
+	DailyCountByRole map[string]int
+	// DailySoftLimitRatio günlük limitin bu oranına ulaşıldığında işlem yapılır ama uyarı döner (0 = uyarı yok)
+	DailySoftLimitRatio float64
+
+	// TransferDescriptionRequiredAbove bu tutarın üzerindeki transferlerde açıklama zorunlu (0 = hiçbir zaman)
+	TransferDescriptionRequiredAbove float64
+
+	// MemoTransfersPerRecipient aynı alıcıya pencere içinde yapılabilecek açıklamalı transfer sayısı (0 = limitsiz)
+	MemoTransfersPerRecipient int
+	// MemoTransferWindow açıklamalı transfer limitinin uygulandığı zaman penceresi
+	MemoTransferWindow time.Duration
+
+	// NewAccountTransferCooldown kayıttan sonra transferin kapalı olduğu süre (0 = kapalı; credit/debit etkilenmez)
+	NewAccountTransferCooldown time.Duration
+
+	// MinTransferInterval aynı kullanıcının ardışık iki transferi arasında geçmesi gereken süre (0 = kapalı)
+	MinTransferInterval time.Duration
+}
+
+// DefaultTransactionLimitConfig varsayılan limit ayarları.
+// Sayı/tutar limitleri, açıklama zorunluluğu ve memo limiti varsayılan olarak kapalıdır; ayarlanmadıkça mevcut davranış değişmez.
+func DefaultTransactionLimitConfig() *TransactionLimitConfig {
+	return &TransactionLimitConfig{
+		MaxAmountByType: map[string]float64{
+			models.TypeCredit:   DefaultMaxTransactionAmount,
+			models.TypeDebit:    DefaultMaxTransactionAmount,
+			models.TypeTransfer: DefaultMaxTransactionAmount,
+		},
+		DailySoftLimitRatio: 0.8,
+		MemoTransferWindow:  time.Hour,
+	}
+}
+
+// AmountRange işlem tipinin tek işlem tutar aralığını döner (0 = o yönde sınır yok)
+func (c *TransactionLimitConfig) AmountRange(txType string) (float64, float64) {
+	if c == nil {
+		return 0, 0
+	}
+	return c.MinAmountByType[txType], c.MaxAmountByType[txType]
+}
+
+// DailyAmountLimit role ait günlük giden tutar limitini döner (0 = limitsiz)
+func (c *TransactionLimitConfig) DailyAmountLimit(role string) float64 {
+	if c == nil {
+		return 0
+	}
+	if limit, ok := c.DailyAmountByRole[role]; ok {
+		return limit
+	}
+	return c.DailyAmountLimitDefault
+}
+
+// DailyCountLimit role ait günlük transaction sayısı limitini döner (0 = limitsiz)
+func (c *TransactionLimitConfig) DailyCountLimit(role string) int {
+	if c == nil {
+		return 0
+	}
+	return c.DailyCountByRole[role]
+}
+
+// SoftLimitRatio uyarı eşiği oranını döner (0 ile 1 arasında değilse 0 = uyarı yok)
+func (c *TransactionLimitConfig) SoftLimitRatio() float64 {
+	if c == nil || c.DailySoftLimitRatio <= 0 || c.DailySoftLimitRatio >= 1 {
+		return 0
+	}
+	return c.DailySoftLimitRatio
+}
+
+// TransferDescriptionThreshold açıklamanın zorunlu olduğu transfer tutarı eşiğini döner (0 = kontrol yok)
+func (c *TransactionLimitConfig) TransferDescriptionThreshold() float64 {
+	if c == nil {
+		return 0
+	}
+	return c.TransferDescriptionRequiredAbove
+}
+
+// MemoTransferLimit aynı alıcıya açıklamalı transfer limitini ve penceresini döner (0 = kontrol yok)
+func (c *TransactionLimitConfig) MemoTransferLimit() (int, time.Duration) {
+	if c == nil || c.MemoTransferWindow <= 0 {
+		return 0, 0
+	}
+	return c.MemoTransfersPerRecipient, c.MemoTransferWindow
+}
+
+// TransferInterval kullanıcının ardışık transferleri arasındaki minimum süreyi döner (0 = kontrol yok)
+func (c *TransactionLimitConfig) TransferInterval() time.Duration {
+	if c == nil || c.MinTransferInterval < 0 {
+		return 0
+	}
+	return c.MinTransferInterval
+}
+
+// TransferCooldown yeni hesaplar için transfer bekleme süresini döner (0 = kontrol yok)
+func (c *TransactionLimitConfig) TransferCooldown() time.Duration {
+	if c == nil || c.NewAccountTransferCooldown < 0 {
+		return 0
+	}
+	return c.NewAccountTransferCooldown
+}
+
+// SetLimitConfig transaction limit ayarlarını değiştirir (nil ise limit uygulanmaz)
+func (s *TransactionService) SetLimitConfig(config *TransactionLimitConfig) {
+	s.limitConfig = config
+}
+
+// checkDailyCountLimitFor kullanıcının bugün planlanan n işlemle birlikte role limitini aşmadığını kontrol eder.
+// Kilit almadan sayar; sadece toplu işlemlerin baştan reddi içindir, asıl kontrol checkDailyCount ile yapılır.
+func (s *TransactionService) checkDailyCountLimitFor(userID int, n int) error {
+	if s.limitConfig == nil || s.userRepo == nil {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("kullanıcı bilgisi alınamadı: %w", err)
+	}
+
+	limit := s.limitConfig.DailyCountLimit(user.Role)
+	if limit <= 0 {
+		return nil
+	}
+
+	count, err := s.transactionRepo.CountUserTransactionsSince(userID, startOfDay(time.Now()))
+	if err != nil {
+		return fmt.Errorf("günlük transaction sayısı kontrol edilemedi: %w", err)
+	}
+
+	if count+n > limit {
+		return fmt.Errorf("günlük transaction sayısı limitine ulaşıldı: bugün %d/%d işlem yapıldı", count, limit)
+	}
+
+	return nil
+}
+
+// amountLimitLabels hata mesajlarında işlem tipinin adı
+var amountLimitLabels = map[string]string{
+	models.TypeCredit:   "yatırma",
+	models.TypeDebit:    "çekme",
+	models.TypeTransfer: "transfer",
+}
+
+// checkAmountLimits tutarın işlem tipi için ayarlanmış minimum/maksimum aralıkta olduğunu kontrol eder
+func (s *TransactionService) checkAmountLimits(txType string, amount float64) error {
+	minAmount, maxAmount := s.limitConfig.AmountRange(txType)
+	label := amountLimitLabels[txType]
+
+	if minAmount > 0 && amount < minAmount {
+		return fmt.Errorf("minimum %s tutarı: %s TL", label, formatLimitAmount(minAmount))
+	}
+	if maxAmount > 0 && amount > maxAmount {
+		return fmt.Errorf("maksimum %s limiti: %s TL", label, formatLimitAmount(maxAmount))
+	}
+
+	return nil
+}
+
+// dailyLimits kullanıcının role göre günlük limitleri; bakiye kilidi altında uygulanır (0 = limitsiz)
+type dailyLimits struct {
+	amount float64 // Giden tutar limiti (debit + giden transfer)
+	count  int     // Transaction sayısı limiti
+}
+
+// dailyLimitsFor kullanıcının rolüne göre günlük tutar ve sayı limitlerini döner
+func (s *TransactionService) dailyLimitsFor(userID int) (dailyLimits, error) {
+	if s.limitConfig == nil || s.userRepo == nil ||
+		(s.limitConfig.DailyAmountLimitDefault <= 0 && len(s.limitConfig.DailyAmountByRole) == 0 && len(s.limitConfig.DailyCountByRole) == 0) {
+		return dailyLimits{}, nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return dailyLimits{}, fmt.Errorf("kullanıcı bilgisi alınamadı: %w", err)
+	}
+
+	return dailyLimits{
+		amount: s.limitConfig.DailyAmountLimit(user.Role),
+		count:  s.limitConfig.DailyCountLimit(user.Role),
+	}, nil
+}
+
+// checkDailyCount bugünkü transaction sayısı yeni işlemle birlikte limiti aşıyorsa işlemi reddeder.
+// checkDailyOutbound gibi kullanıcının bakiyesi kilitlendikten sonra aynı DB transaction'ında çağrılmalı;
+// sayım kilit dışında yapılırsa eşzamanlı istekler aynı sayıyı görüp limiti birlikte aşar.
+func checkDailyCount(txRepo *db.TransactionRepository, userID int, limit int, transaction *models.Transaction) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	dayStart := startOfDay(time.Now())
+
+	// CountUserTransactionsSince ile aynı kural: gönderilenler ve kendi hesabına yatırılanlar, failed hariç
+	var count int
+	err := txRepo.QueryRow(`
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE (from_user_id = $1 OR (to_user_id = $1 AND from_user_id IS NULL))
+			AND created_at >= $2
+			AND status <> 'failed'
+	`, userID, dayStart).Scan(&count)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("günlük transaction sayısı kontrol edilemedi: %w", err)
+	}
+
+	// Capture'ı bekleyen aktif hold'lar da birer işlem sayılır
+	holds, _, err := dailyHoldUsage(txRepo, userID, dayStart)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return err
+	}
+	count += holds
+
+	if count+1 > limit {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("günlük transaction sayısı limitine ulaşıldı: bugün %d/%d işlem yapıldı", count, limit)
+	}
+
+	return nil
+}
+
+// checkDailyOutbound bugünkü giden toplamı amount ile birlikte limiti aşıyorsa işlemi reddeder.
+// Gönderenin bakiyesi kilitlendikten sonra aynı DB transaction'ında çağrılmalı: kilit aynı kullanıcının
+// eşzamanlı debit/transferlerini sıraya sokar, böylece iki işlem limiti birlikte aşamaz.
+// Bugün bloke edilip henüz capture/void edilmemiş tutar da gönderilmiş sayılır: capture limite tekrar takılmaz.
+func checkDailyOutbound(txRepo *db.TransactionRepository, userID int, amount, limit float64, transaction *models.Transaction) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	dayStart := startOfDay(time.Now())
+
+	var spent float64
+	err := txRepo.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE from_user_id = $1 AND created_at >= $2 AND status <> 'failed'
+	`, userID, dayStart).Scan(&spent)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("günlük giden tutar alınamadı: %w", err)
+	}
+
+	_, held, err := dailyHoldUsage(txRepo, userID, dayStart)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return err
+	}
+	spent += held
+
+	if spent+amount > limit {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("%w: bugün %s/%s TL gönderildi", ErrDailyLimitExceeded, formatLimitAmount(spent), formatLimitAmount(limit))
+	}
+
+	return nil
+}
+
+// formatLimitAmount tutarı binlik ayraçlı yazar (1000000 → "1,000,000", 1.5 → "1.50")
+func formatLimitAmount(amount float64) string {
+	text := strconv.FormatFloat(amount, 'f', 2, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if fraction == "00" {
+		return grouped.String()
+	}
+	return grouped.String() + "." + fraction
+}
+
+// GetDailyLimitWarning başarılı işlemden sonra günlük sayı veya giden tutar kullanımı soft limit eşiğine ulaştıysa uyarı döner.
+// Kullanım limit kontrolleri ve önizlemeyle aynı şekilde bugünkü aktif hold'ları da içerir (bkz. dailyUsage).
+// Eşiğin altında, limitsiz role'de veya uyarı kapalıyken nil döner; red yalnızca hard limitte (%100) yapılır.
+func (s *TransactionService) GetDailyLimitWarning(userID int) (*models.LimitWarning, error) {
+	ratio := s.limitConfig.SoftLimitRatio()
+	if ratio == 0 || s.userRepo == nil {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("kullanıcı bilgisi alınamadı: %w", err)
+	}
+
+	countLimit := s.limitConfig.DailyCountLimit(user.Role)
+	amountLimit := s.limitConfig.DailyAmountLimit(user.Role)
+	if countLimit <= 0 && amountLimit <= 0 {
+		return nil, nil
+	}
+
+	count, amount, err := s.dailyUsage(userID, startOfDay(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	if warning := models.NewCountLimitWarning(models.LimitPeriodDaily, countLimit, count, ratio); warning != nil {
+		return warning, nil
+	}
+	return models.NewAmountLimitWarning(models.LimitPeriodDaily, amountLimit, amount, ratio), nil
+}
+
+// dailyUsage kullanıcının dayStart'tan beri işlem sayısını ve giden tutarını bugünkü aktif hold'lar dahil döner.
+// checkDailyCount/checkDailyOutbound ile aynı kural: hold capture'da limite tekrar takılmadığından kullanılmış sayılır.
+func (s *TransactionService) dailyUsage(userID int, dayStart time.Time) (int, float64, error) {
+	daily, err := s.transactionRepo.GetUserUsageSince(userID, dayStart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("günlük kullanım alınamadı: %w", err)
+	}
+
+	holdCount, held, err := dailyHoldUsage(s.database, userID, dayStart)
+	if err != nil {
+		return 0, 0, err
+	}
+	return daily.Count + holdCount, daily.Amount + held, nil
+}
+
+// checkNewAccountCooldown gönderenin hesabının transfer bekleme süresini doldurduğunu kontrol eder
+func (s *TransactionService) checkNewAccountCooldown(userID int) error {
+	cooldown := s.limitConfig.TransferCooldown()
+	if cooldown <= 0 || s.userRepo == nil {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("kullanıcı bilgisi alınamadı: %w", err)
+	}
+
+	if remaining := time.Until(user.CreatedAt.Add(cooldown)); remaining > 0 {
+		return fmt.Errorf("%w: kalan süre %s", ErrNewAccountTransferCooldown, remaining.Round(time.Second))
+	}
+
+	return nil
+}
+
+// checkMemoTransferRate göndericinin alıcıya planlanan n açıklamalı transferle birlikte limiti aşmadığını kontrol eder.
+// Açıklamalar spam kanalı olarak kullanılmasın diye sadece açıklamalı transferler sayılır.
+func (s *TransactionService) checkMemoTransferRate(fromUserID, toUserID int, n int) error {
+	limit, window := s.limitConfig.MemoTransferLimit()
+	if limit <= 0 || n == 0 {
+		return nil
+	}
+
+	count, err := s.transactionRepo.CountTransfersWithDescriptionSince(fromUserID, toUserID, time.Now().Add(-window))
+	if err != nil {
+		return fmt.Errorf("açıklamalı transfer sayısı kontrol edilemedi: %w", err)
+	}
+
+	if count+n > limit {
+		return fmt.Errorf("%w: alıcı %d için son %s içinde %d/%d açıklamalı transfer yapıldı",
+			ErrMemoTransferRateLimited, toUserID, window, count, limit)
+	}
+
+	return nil
+}
+
+// hasDescription transfer açıklamasının dolu olup olmadığını döner
+func hasDescription(description string) bool {
+	return strings.TrimSpace(description) != ""
+}
+
+// GetTransactionLimits kullanıcının limitlerini ve bugün/bu ay tükettiği kısmı döner (günlük kullanıma aktif hold'lar dahildir).
+// Tanımlı olmayan limitler (ör. aylık limitler) limitsiz olarak döner, kullanım yine raporlanır.
+func (s *TransactionService) GetTransactionLimits(userID int) (*models.TransactionLimits, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("kullanıcı bilgisi alınamadı: %w", err)
+	}
+
+	now := time.Now()
+	dayStart := startOfDay(now)
+	monthStart := startOfMonth(now)
+
+	// Limit kontrolleri bugünkü aktif hold'ları kullanılmış sayar; önizleme de aynı kuralla hesaplanır
+	dailyCount, dailyAmount, err := s.dailyUsage(userID, dayStart)
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := s.transactionRepo.GetUserUsageSince(userID, monthStart)
+	if err != nil {
+		return nil, fmt.Errorf("aylık kullanım alınamadı: %w", err)
+	}
+
+	_, maxTransferAmount := s.limitConfig.AmountRange(models.TypeTransfer)
+
+	return &models.TransactionLimits{
+		UserID:            userID,
+		Role:              user.Role,
+		MaxTransferAmount: maxTransferAmount,
+		Daily: models.PeriodAllowance{
+			Period: models.LimitPeriodDaily,
+			Since:  dayStart,
+			Count:  models.NewCountAllowance(s.limitConfig.DailyCountLimit(user.Role), dailyCount),
+			Amount: models.NewAmountAllowance(s.limitConfig.DailyAmountLimit(user.Role), dailyAmount),
+		},
+		Monthly: models.PeriodAllowance{
+			Period: models.LimitPeriodMonthly,
+			Since:  monthStart,
+			Count:  models.NewCountAllowance(0, monthly.Count),
+			Amount: models.NewAmountAllowance(0, monthly.Amount),
+		},
+	}, nil
+}
+
+// startOfMonth verilen zamanın ay başlangıcını döner
+func startOfMonth(t time.Time) time.Time {
+	year, month, _ := t.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// startOfDay verilen zamanın gün başlangıcını döner
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -1,15 +1,27 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/onerilhan/go-payment-api/internal/db"
 	"github.com/onerilhan/go-payment-api/internal/models"
 	"github.com/rs/zerolog/log"
 )
 
+var (
+	// ErrTransactionQueueFull queue buffer'ı dolu olduğunda job kabul edilmez
+	ErrTransactionQueueFull = errors.New("transaction queue dolu, daha sonra tekrar deneyin")
+	// ErrTransactionQueueStopped queue kapatılırken gelen veya kapanış süresi içinde işlenemeyen job'ın sonucu (transfer yapılmadı)
+	ErrTransactionQueueStopped = errors.New("transaction queue kapatılıyor, işlem gerçekleştirilmedi")
+)
+
 // TransactionJob queue'da işlenecek transaction job'ı
 type TransactionJob struct {
+	Ctx        context.Context // Request context (request ID korelasyonu için)
 	FromUserID int
 	Request    *models.TransferRequest
 	ResultChan chan TransactionResult
@@ -21,23 +33,97 @@ type TransactionResult struct {
 	Error       error
 }
 
+// TransferExecutor queue worker'larının transferi çalıştırdığı servis (TransactionService)
+type TransferExecutor interface {
+	Transfer(ctx context.Context, fromUserID int, req *models.TransferRequest) (*models.Transaction, error)
+}
+
+// TransactionQueueRetryConfig geçici hatalarda (deadlock, serialization) job tekrar ayarları
+type TransactionQueueRetryConfig struct {
+	MaxRetries     int              // İlk denemeden sonraki maksimum tekrar sayısı (0 = tekrar yok)
+	BaseDelay      time.Duration    // İlk tekrar öncesi bekleme (her denemede ikiye katlanır)
+	MaxDelay       time.Duration    // Bekleme üst sınırı (0 = sınır yok)
+	DeadLetterSize int              // Saklanan en fazla dead-letter kaydı; dolunca en eskisi düşer (0 = saklanmaz)
+	IsRetryable    func(error) bool // Tekrar edilecek hatalar (nil ise db.IsRetryableError)
+}
+
+// DefaultTransactionQueueRetryConfig varsayılan queue tekrar ayarları
+func DefaultTransactionQueueRetryConfig() *TransactionQueueRetryConfig {
+	return &TransactionQueueRetryConfig{
+		MaxRetries:     2,
+		BaseDelay:      100 * time.Millisecond,
+		MaxDelay:       2 * time.Second,
+		DeadLetterSize: 100,
+	}
+}
+
+// DeadLetterJob tekrar hakları tükendiği için işlenemeyen job ve son hatası
+type DeadLetterJob struct {
+	FromUserID int                     `json:"from_user_id"`
+	Request    *models.TransferRequest `json:"request"`
+	Attempts   int                     `json:"attempts"`
+	LastError  string                  `json:"last_error"`
+	FailedAt   time.Time               `json:"failed_at"`
+}
+
+// TransactionQueueMetrics queue tekrar ve dead-letter sayaçları
+type TransactionQueueMetrics struct {
+	RetriedJobs      int64 `json:"retried_jobs_total"`       // En az bir kez tekrar edilen job sayısı
+	Retries          int64 `json:"retries_total"`            // Toplam tekrar denemesi
+	DeadLetteredJobs int64 `json:"dead_lettered_jobs_total"` // Tekrar hakkı tükenip dead-letter'a düşen job sayısı
+	DeadLetterSize   int   `json:"dead_letter_size"`         // Şu an saklanan dead-letter kaydı
+}
+
 // TransactionQueue transaction işleme queue'su
 type TransactionQueue struct {
 	jobChan    chan TransactionJob
 	workers    int
 	bufferSize int
 	wg         sync.WaitGroup
-	service    *TransactionService
+	service    TransferExecutor
+
+	retryConfig *TransactionQueueRetryConfig
+	mutex       sync.Mutex // deadLetters ve metrics'i korur
+	deadLetters []DeadLetterJob
+	metrics     TransactionQueueMetrics
+
+	enqueueTimeout time.Duration // Buffer doluyken AddJob'ın boş yer beklediği süre (0 = beklemeden reddet)
+
+	closeMutex sync.RWMutex  // AddJob gönderimleri ile jobChan'in kapatılmasını sıralar
+	closed     bool          // Drain/Stop çağrıldı, yeni job kabul edilmez
+	abort      chan struct{} // Drain süresi doldu; henüz başlamamış job'lar işlenmeden sonuçlandırılır
+	abortOnce  sync.Once
 }
 
-// NewTransactionQueue yeni queue oluşturur
-func NewTransactionQueue(workers int, service *TransactionService, bufferSize int) *TransactionQueue {
+// DefaultEnqueueTimeout buffer doluyken job'ın yer açılmasını beklediği varsayılan süre
+const DefaultEnqueueTimeout = 100 * time.Millisecond
+
+// NewTransactionQueue yeni queue oluşturur (varsayılan tekrar ayarlarıyla)
+func NewTransactionQueue(workers int, service TransferExecutor, bufferSize int) *TransactionQueue {
 	return &TransactionQueue{
-		jobChan:    make(chan TransactionJob, bufferSize),
-		workers:    workers,
-		bufferSize: bufferSize,
-		service:    service,
+		jobChan:     make(chan TransactionJob, bufferSize),
+		workers:     workers,
+		bufferSize:  bufferSize,
+		service:     service,
+		retryConfig: DefaultTransactionQueueRetryConfig(),
+
+		enqueueTimeout: DefaultEnqueueTimeout,
+
+		abort: make(chan struct{}),
+	}
+}
+
+// SetEnqueueTimeout buffer doluyken AddJob'ın yer açılmasını bekleyeceği süreyi ayarlar (<= 0 = beklemeden reddet)
+func (q *TransactionQueue) SetEnqueueTimeout(timeout time.Duration) {
+	q.enqueueTimeout = timeout
+}
+
+// SetRetryConfig job tekrar ve dead-letter ayarlarını değiştirir (nil ise tekrar yapılmaz). Start'tan önce çağrılmalıdır.
+func (q *TransactionQueue) SetRetryConfig(config *TransactionQueueRetryConfig) {
+	if config == nil {
+		config = &TransactionQueueRetryConfig{}
 	}
+	q.retryConfig = config
 }
 
 // Start worker'ları başlatır
@@ -53,13 +139,66 @@ func (q *TransactionQueue) Start() {
 	}
 }
 
-// Stop queue'yu durdurur
+// Stop yeni job kabulünü durdurur ve buffer'daki tüm job'lar işlenene kadar bekler
 func (q *TransactionQueue) Stop() {
-	close(q.jobChan)
-	q.wg.Wait()
+	q.Drain(context.Background())
 	log.Info().Msg("⏹️ Transaction queue durduruldu")
 }
 
+// Drain yeni job kabulünü durdurur ve buffer'da bekleyen job'ların işlenmesini ctx süresince bekler.
+// Süre dolarsa henüz başlamamış job'lar işlenmeden ErrTransactionQueueStopped ile sonuçlandırılır ve ctx hatası döner;
+// o an işlenmekte olan job'lar kendi sonuçlarını gönderir. Her job'ın result channel'ına tam olarak bir sonuç yazılır.
+func (q *TransactionQueue) Drain(ctx context.Context) error {
+	q.closeMutex.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobChan)
+	}
+	q.closeMutex.Unlock()
+
+	workersDone := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(workersDone)
+	}()
+
+	var err error
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		err = ctx.Err()
+		q.abortOnce.Do(func() { close(q.abort) })
+	}
+
+	// Worker'ların almadığı job'lar (süre doldu veya worker hiç başlatılmadı) hata sonucuyla kapatılır
+	dropped := 0
+	for job := range q.jobChan {
+		q.failJob(job, ErrTransactionQueueStopped)
+		dropped++
+	}
+	if dropped > 0 {
+		log.Warn().Int("dropped_jobs", dropped).Msg("⚠️ Transaction queue kapanışında işlenemeyen job'lar hata ile sonuçlandırıldı")
+	}
+
+	return err
+}
+
+// aborted Drain süresinin dolup dolmadığını döner
+func (q *TransactionQueue) aborted() bool {
+	select {
+	case <-q.abort:
+		return true
+	default:
+		return false
+	}
+}
+
+// failJob job'ı işlemeden verilen hatayla sonuçlandırır ve result channel'ını kapatır
+func (q *TransactionQueue) failJob(job TransactionJob, err error) {
+	job.ResultChan <- TransactionResult{Error: err}
+	close(job.ResultChan)
+}
+
 // worker tek bir worker'ın işlem yapması
 func (q *TransactionQueue) worker(id int) {
 	defer q.wg.Done()
@@ -77,6 +216,11 @@ func (q *TransactionQueue) worker(id int) {
 	log.Info().Int("worker_id", id).Msg("🚀 Worker başlatıldı")
 
 	for job := range q.jobChan {
+		if q.aborted() {
+			q.failJob(job, ErrTransactionQueueStopped)
+			continue
+		}
+
 		log.Debug().
 			Int("worker_id", id).
 			Int("from_user", job.FromUserID).
@@ -84,8 +228,8 @@ func (q *TransactionQueue) worker(id int) {
 			Float64("amount", job.Request.Amount).
 			Msg("💼 Transaction işleniyor")
 
-		// Transaction'ı işle
-		transaction, err := q.service.Transfer(job.FromUserID, job.Request)
+		// Transaction'ı işle (geçici hatalarda backoff ile tekrar denenir)
+		transaction, err := q.process(job)
 
 		// Sonucu gönder ve channel'ı kapat
 		job.ResultChan <- TransactionResult{
@@ -104,29 +248,165 @@ func (q *TransactionQueue) worker(id int) {
 	log.Info().Int("worker_id", id).Msg("🛑 Worker durduruldu")
 }
 
-// AddJob queue'ya yeni job ekler
-func (q *TransactionQueue) AddJob(fromUserID int, req *models.TransferRequest) <-chan TransactionResult {
+// process job'ı çalıştırır; tekrar edilebilir hatalarda üstel backoff ile yeniden dener.
+// Tekrar hakları tükenirse job son hatasıyla dead-letter'a yazılır. Request context'i iptal edilirse beklemeden döner.
+func (q *TransactionQueue) process(job TransactionJob) (*models.Transaction, error) {
+	config := q.retryConfig
+	isRetryable := config.IsRetryable
+	if isRetryable == nil {
+		isRetryable = db.IsRetryableError
+	}
+
+	ctx := job.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for attempt := 0; ; attempt++ {
+		transaction, err := q.service.Transfer(ctx, job.FromUserID, job.Request)
+		if err == nil || !isRetryable(err) {
+			return transaction, err
+		}
+
+		if attempt >= config.MaxRetries {
+			q.deadLetter(job, attempt+1, err)
+			return nil, err
+		}
+
+		delay := queueRetryDelay(config, attempt)
+		q.recordRetry(attempt == 0)
+		log.Warn().
+			Err(err).
+			Int("from_user", job.FromUserID).
+			Int("attempt", attempt+1).
+			Int("max_retries", config.MaxRetries).
+			Dur("delay", delay).
+			Msg("🔁 Transaction geçici hatayla başarısız, tekrar deneniyor")
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-q.abort:
+			return nil, fmt.Errorf("%w: %v", ErrTransactionQueueStopped, err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// queueRetryDelay attempt. tekrar öncesi üstel bekleme süresini döner (MaxDelay ile sınırlı)
+func queueRetryDelay(config *TransactionQueueRetryConfig, attempt int) time.Duration {
+	delay := config.BaseDelay << attempt
+	if delay < 0 || (config.MaxDelay > 0 && delay > config.MaxDelay) {
+		delay = config.MaxDelay
+	}
+	return delay
+}
+
+// recordRetry tekrar sayaçlarını artırır (firstRetry ise job ilk kez tekrar ediliyordur)
+func (q *TransactionQueue) recordRetry(firstRetry bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.metrics.Retries++
+	if firstRetry {
+		q.metrics.RetriedJobs++
+	}
+}
+
+// deadLetter tekrar hakları tükenen job'ı son hatasıyla saklar; kapasite doluysa en eski kayıt düşer
+func (q *TransactionQueue) deadLetter(job TransactionJob, attempts int, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.metrics.DeadLetteredJobs++
+	if limit := q.retryConfig.DeadLetterSize; limit > 0 {
+		if len(q.deadLetters) >= limit {
+			q.deadLetters = q.deadLetters[len(q.deadLetters)-limit+1:]
+		}
+		q.deadLetters = append(q.deadLetters, DeadLetterJob{
+			FromUserID: job.FromUserID,
+			Request:    job.Request,
+			Attempts:   attempts,
+			LastError:  err.Error(),
+			FailedAt:   time.Now(),
+		})
+	}
+
+	log.Error().
+		Err(err).
+		Int("from_user", job.FromUserID).
+		Int("attempts", attempts).
+		Msg("☠️ Transaction tekrar hakları tükendi, dead-letter'a alındı")
+}
+
+// DeadLetters saklanan dead-letter kayıtlarının kopyasını eskiden yeniye döner
+func (q *TransactionQueue) DeadLetters() []DeadLetterJob {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deadLetters := make([]DeadLetterJob, len(q.deadLetters))
+	copy(deadLetters, q.deadLetters)
+	return deadLetters
+}
+
+// Metrics tekrar ve dead-letter sayaçlarının anlık kopyasını döner
+func (q *TransactionQueue) Metrics() TransactionQueueMetrics {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	metrics := q.metrics
+	metrics.DeadLetterSize = len(q.deadLetters)
+	return metrics
+}
+
+// Stats queue'da bekleyen job sayısını ve buffer kapasitesini döner (readiness degradation için)
+func (q *TransactionQueue) Stats() (length, capacity int) {
+	return len(q.jobChan), cap(q.jobChan)
+}
+
+// AddJob queue'ya yeni job ekler. Buffer doluysa enqueueTimeout kadar yer açılmasını bekler;
+// süre dolarsa sonuç ErrTransactionQueueFull olur (ani yük patlamalarında istekler hemen reddedilmez).
+// Queue Drain/Stop ile kapatıldıysa job kabul edilmez ve sonuç ErrTransactionQueueStopped olur.
+func (q *TransactionQueue) AddJob(ctx context.Context, fromUserID int, req *models.TransferRequest) <-chan TransactionResult {
 	resultChan := make(chan TransactionResult, 1)
 
 	job := TransactionJob{
+		Ctx:        ctx,
 		FromUserID: fromUserID,
 		Request:    req,
 		ResultChan: resultChan,
 	}
 
+	q.closeMutex.RLock()
+	defer q.closeMutex.RUnlock()
+
+	if q.closed {
+		log.Warn().Int("from_user", fromUserID).Msg("⚠️ Transaction queue kapatılıyor, job reddedildi")
+		q.failJob(job, ErrTransactionQueueStopped)
+		return resultChan
+	}
+
 	select {
 	case q.jobChan <- job:
 		log.Debug().Int("from_user", fromUserID).Msg("📤 Job queue'ya eklendi")
+		return resultChan
 	default:
-		// Queue dolu - channel'ı kapat
-		go func() {
-			resultChan <- TransactionResult{
-				Transaction: nil,
-				Error:       fmt.Errorf("transaction queue dolu, daha sonra tekrar deneyin"),
-			}
-			close(resultChan) // FIX: Channel'ı kapat
-		}()
 	}
 
+	if q.enqueueTimeout > 0 {
+		timer := time.NewTimer(q.enqueueTimeout)
+		defer timer.Stop()
+
+		select {
+		case q.jobChan <- job:
+			log.Debug().Int("from_user", fromUserID).Msg("📤 Job queue'ya eklendi (yer açılması beklendi)")
+			return resultChan
+		case <-timer.C:
+		}
+	}
+
+	// Queue dolu - sonucu yaz ve channel'ı kapat (buffer'lı olduğundan bloklamaz)
+	log.Warn().Int("from_user", fromUserID).Dur("enqueue_timeout", q.enqueueTimeout).Msg("⚠️ Transaction queue dolu, job reddedildi")
+	q.failJob(job, ErrTransactionQueueFull)
 	return resultChan
 }
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// flakyTransferExecutor ilk failures çağrıda err döner, sonrasında transferi başarılı sayar
+type flakyTransferExecutor struct {
+	mutex    sync.Mutex
+	failures int
+	err      error
+	calls    int
+}
+
+func (e *flakyTransferExecutor) Transfer(ctx context.Context, fromUserID int, req *models.TransferRequest) (*models.Transaction, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.calls++
+	if e.calls <= e.failures {
+		return nil, e.err
+	}
+	return &models.Transaction{ID: e.calls, Amount: req.Amount, Status: models.StatusCompleted}, nil
+}
+
+// runQueueJob tek worker'lı, hızlı backoff'lu queue ile bir job çalıştırır ve sonucunu döner
+func runQueueJob(t *testing.T, executor TransferExecutor, maxRetries int) (*TransactionQueue, TransactionResult) {
+	t.Helper()
+
+	queue := NewTransactionQueue(1, executor, 1)
+	queue.SetRetryConfig(&TransactionQueueRetryConfig{
+		MaxRetries:     maxRetries,
+		BaseDelay:      time.Millisecond,
+		MaxDelay:       5 * time.Millisecond,
+		DeadLetterSize: 10,
+	})
+	queue.Start()
+	defer queue.Stop()
+
+	select {
+	case result := <-queue.AddJob(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 50}):
+		return queue, result
+	case <-time.After(time.Second):
+		require.FailNow(t, "job sonucu zamanında gelmedi")
+		return nil, TransactionResult{}
+	}
+}
+
+// TestTransactionQueue_RetriesTransientErrorsThenSucceeds, serialization hatasıyla iki kez düşen job'ın üçüncü
+// denemede başarılı olduğunu ve tekrar sayaçlarının arttığını test eder.
+func TestTransactionQueue_RetriesTransientErrorsThenSucceeds(t *testing.T) {
+	// Arrange
+	executor := &flakyTransferExecutor{failures: 2, err: &pq.Error{Code: db.SQLStateSerializationFailure}}
+
+	// Act
+	queue, result := runQueueJob(t, executor, 3)
+
+	// Assert
+	require.NoError(t, result.Error)
+	assert.Equal(t, 3, result.Transaction.ID)
+	assert.Equal(t, 3, executor.calls)
+
+	metrics := queue.Metrics()
+	assert.Equal(t, int64(1), metrics.RetriedJobs)
+	assert.Equal(t, int64(2), metrics.Retries)
+	assert.Equal(t, int64(0), metrics.DeadLetteredJobs)
+	assert.Empty(t, queue.DeadLetters())
+}
+
+// TestTransactionQueue_ExhaustedRetriesGoToDeadLetter, tekrar hakları tükenen job'ın son hatasıyla dead-letter'a
+// yazıldığını ve kullanıcıya hatanın döndüğünü test eder.
+func TestTransactionQueue_ExhaustedRetriesGoToDeadLetter(t *testing.T) {
+	// Arrange
+	deadlock := &pq.Error{Code: db.SQLStateDeadlockDetected, Message: "deadlock detected"}
+	executor := &flakyTransferExecutor{failures: 5, err: deadlock}
+
+	// Act
+	queue, result := runQueueJob(t, executor, 2)
+
+	// Assert
+	assert.ErrorIs(t, result.Error, deadlock)
+	assert.Equal(t, 3, executor.calls)
+
+	deadLetters := queue.DeadLetters()
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, 10, deadLetters[0].FromUserID)
+	assert.Equal(t, 20, deadLetters[0].Request.ToUserID)
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.Equal(t, deadlock.Error(), deadLetters[0].LastError)
+
+	metrics := queue.Metrics()
+	assert.Equal(t, int64(1), metrics.RetriedJobs)
+	assert.Equal(t, int64(2), metrics.Retries)
+	assert.Equal(t, int64(1), metrics.DeadLetteredJobs)
+	assert.Equal(t, 1, metrics.DeadLetterSize)
+}
+
+// TestTransactionQueue_PermanentErrorNotRetried, yetersiz bakiye gibi kalıcı hataların tekrar edilmeden döndüğünü test eder.
+func TestTransactionQueue_PermanentErrorNotRetried(t *testing.T) {
+	// Arrange
+	executor := &flakyTransferExecutor{failures: 1, err: ErrInsufficientBalance}
+
+	// Act
+	queue, result := runQueueJob(t, executor, 3)
+
+	// Assert
+	assert.True(t, errors.Is(result.Error, ErrInsufficientBalance))
+	assert.Equal(t, 1, executor.calls)
+	assert.Equal(t, TransactionQueueMetrics{}, queue.Metrics())
+}
+
+// gatedTransferExecutor her transferde başladığını bildirir ve gate kapanana kadar bekler
+type gatedTransferExecutor struct {
+	started chan int
+	gate    chan struct{}
+}
+
+func (e *gatedTransferExecutor) Transfer(ctx context.Context, fromUserID int, req *models.TransferRequest) (*models.Transaction, error) {
+	e.started <- fromUserID
+	<-e.gate
+	return &models.Transaction{ID: fromUserID, Status: models.StatusCompleted}, nil
+}
+
+// TestTransactionQueue_AddJob_WaitsForFreeSlot, buffer doluyken gelen job'ın worker yer açana kadar beklediğini
+// ve reddedilmeden işlendiğini test eder.
+func TestTransactionQueue_AddJob_WaitsForFreeSlot(t *testing.T) {
+	// Arrange
+	executor := &gatedTransferExecutor{started: make(chan int, 3), gate: make(chan struct{})}
+	queue := NewTransactionQueue(1, executor, 1)
+	queue.SetEnqueueTimeout(time.Second)
+	queue.Start()
+	defer queue.Stop()
+
+	// 1. job worker'da bekler, 2. job buffer'ı doldurur
+	first := queue.AddJob(context.Background(), 1, &models.TransferRequest{ToUserID: 20, Amount: 10})
+	<-executor.started
+	second := queue.AddJob(context.Background(), 2, &models.TransferRequest{ToUserID: 20, Amount: 10})
+
+	// Act: 3. job eklenirken worker kısa süre sonra serbest bırakılır
+	time.AfterFunc(20*time.Millisecond, func() { close(executor.gate) })
+	third := queue.AddJob(context.Background(), 3, &models.TransferRequest{ToUserID: 20, Amount: 10})
+
+	// Assert
+	for i, resultChan := range []<-chan TransactionResult{first, second, third} {
+		result := <-resultChan
+		require.NoError(t, result.Error)
+		assert.Equal(t, i+1, result.Transaction.ID)
+	}
+}
+
+// TestTransactionQueue_AddJob_TimesOutWhenFull, buffer süre boyunca dolu kalırsa job'ın bekleme süresi sonunda
+// ErrTransactionQueueFull ile reddedildiğini test eder.
+func TestTransactionQueue_AddJob_TimesOutWhenFull(t *testing.T) {
+	// Arrange: worker başlatılmadığından buffer boşalmaz
+	queue := NewTransactionQueue(1, &flakyTransferExecutor{}, 1)
+	queue.SetEnqueueTimeout(30 * time.Millisecond)
+	queue.AddJob(context.Background(), 1, &models.TransferRequest{ToUserID: 20, Amount: 10})
+
+	// Act
+	startedAt := time.Now()
+	result := <-queue.AddJob(context.Background(), 2, &models.TransferRequest{ToUserID: 20, Amount: 10})
+	waited := time.Since(startedAt)
+
+	// Assert
+	assert.ErrorIs(t, result.Error, ErrTransactionQueueFull)
+	assert.Nil(t, result.Transaction)
+	assert.GreaterOrEqual(t, waited, 30*time.Millisecond)
+}
+
+// receiveExactlyOnce result channel'ından tek sonucu alır ve ardından channel'ın kapandığını doğrular
+func receiveExactlyOnce(t *testing.T, resultChan <-chan TransactionResult) TransactionResult {
+	t.Helper()
+
+	var result TransactionResult
+	select {
+	case result = <-resultChan:
+	case <-time.After(time.Second):
+		require.FailNow(t, "job sonucu zamanında gelmedi")
+	}
+
+	_, open := <-resultChan
+	assert.False(t, open, "result channel'ına birden fazla sonuç yazıldı")
+	return result
+}
+
+// TestTransactionQueue_Drain_ProcessesBufferedJobs, Drain'in buffer'daki job'ların hepsini işlediğini,
+// sonrasında gelen job'ı ErrTransactionQueueStopped ile reddettiğini test eder.
+func TestTransactionQueue_Drain_ProcessesBufferedJobs(t *testing.T) {
+	// Arrange
+	executor := &gatedTransferExecutor{started: make(chan int, 3), gate: make(chan struct{})}
+	queue := NewTransactionQueue(1, executor, 2)
+	queue.Start()
+
+	resultChans := make([]<-chan TransactionResult, 0, 3)
+	for userID := 1; userID <= 3; userID++ {
+		resultChans = append(resultChans, queue.AddJob(context.Background(), userID, &models.TransferRequest{ToUserID: 20, Amount: 10}))
+	}
+	<-executor.started
+
+	// Act
+	time.AfterFunc(20*time.Millisecond, func() { close(executor.gate) })
+	drainErr := queue.Drain(context.Background())
+	late := queue.AddJob(context.Background(), 4, &models.TransferRequest{ToUserID: 20, Amount: 10})
+
+	// Assert
+	require.NoError(t, drainErr)
+	for i, resultChan := range resultChans {
+		result := receiveExactlyOnce(t, resultChan)
+		require.NoError(t, result.Error)
+		assert.Equal(t, i+1, result.Transaction.ID)
+	}
+	assert.ErrorIs(t, receiveExactlyOnce(t, late).Error, ErrTransactionQueueStopped)
+}
+
+// TestTransactionQueue_Drain_DeadlineFailsPendingJobs, Drain süresi dolduğunda başlamamış job'ların işlenmeden
+// ErrTransactionQueueStopped aldığını, işlenmekte olan job'ın ise kendi sonucunu gönderdiğini test eder.
+func TestTransactionQueue_Drain_DeadlineFailsPendingJobs(t *testing.T) {
+	// Arrange
+	executor := &gatedTransferExecutor{started: make(chan int, 3), gate: make(chan struct{})}
+	queue := NewTransactionQueue(1, executor, 2)
+	queue.Start()
+
+	inFlight := queue.AddJob(context.Background(), 1, &models.TransferRequest{ToUserID: 20, Amount: 10})
+	<-executor.started
+	pending := []<-chan TransactionResult{
+		queue.AddJob(context.Background(), 2, &models.TransferRequest{ToUserID: 20, Amount: 10}),
+		queue.AddJob(context.Background(), 3, &models.TransferRequest{ToUserID: 20, Amount: 10}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	// Act
+	drainErr := queue.Drain(ctx)
+	close(executor.gate)
+
+	// Assert
+	assert.ErrorIs(t, drainErr, context.DeadlineExceeded)
+	for _, resultChan := range pending {
+		result := receiveExactlyOnce(t, resultChan)
+		assert.ErrorIs(t, result.Error, ErrTransactionQueueStopped)
+		assert.Nil(t, result.Transaction)
+	}
+
+	result := receiveExactlyOnce(t, inFlight)
+	require.NoError(t, result.Error)
+	assert.Equal(t, 1, result.Transaction.ID)
+	assert.Len(t, executor.started, 0)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/features"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+var (
+	// ErrRefundsDisabled "refunds" feature flag'i kapalıyken kısmi iade istendiğinde döner
+	ErrRefundsDisabled = errors.New("kısmi iade özelliği bu ortamda kapalı")
+	// ErrRefundForbidden iade isteyen kullanıcı transferin alıcısı (iadeyi yapacak taraf) değilse döner
+	ErrRefundForbidden = errors.New("bu transaction için iade yetkiniz yok")
+	// ErrRefundExceedsRemaining iade tutarı orijinalin henüz iade edilmemiş kısmını aşarsa döner
+	ErrRefundExceedsRemaining = errors.New("iade tutarı iade edilebilir kalan tutarı aşıyor")
+)
+
+// Refund completed bir transaction'ın bir kısmını geri veren iade kaydını tek DB transaction'ında oluşturur.
+// Orijinal FOR UPDATE ile kilitlendiğinden eşzamanlı iadeler sırayla işlenir ve toplamları orijinal tutarı aşamaz.
+// asAdmin false ise yalnızca transferin alıcısı (ör. merchant) kendi aldığı transferi iade edebilir.
+func (s *TransactionService) Refund(ctx context.Context, originalTxID, requesterID int, asAdmin bool, req *models.RefundTransactionRequest) (*models.RefundResult, error) {
+	if !features.Enabled(features.Refunds) {
+		return nil, ErrRefundsDisabled
+	}
+	if originalTxID <= 0 {
+		return nil, fmt.Errorf("geçersiz transaction ID")
+	}
+
+	var refund *models.Transaction
+	var result *models.RefundResult
+	startedAt := time.Now()
+
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+		refund = nil
+
+		original, err := lockTransactionRow(txRepo, originalTxID)
+		if err != nil {
+			return err
+		}
+		if !asAdmin && (original.Type != models.TypeTransfer || original.ToUserID == nil || *original.ToUserID != requesterID) {
+			return ErrRefundForbidden
+		}
+		if err := checkCounterable(txRepo, original); err != nil {
+			return err
+		}
+
+		refunded, err := refundedTotal(txRepo, originalTxID)
+		if err != nil {
+			return err
+		}
+		remaining := minorUnits(original.Amount) - minorUnits(refunded)
+		if minorUnits(req.Amount) > remaining {
+			return fmt.Errorf("%w: iade edilebilir kalan %.2f TL", ErrRefundExceedsRemaining, fromMinorUnits(remaining))
+		}
+
+		refund, err = models.NewRefundTransaction(original, req.Amount, fmt.Sprintf("#%d kısmi iadesi: %s", original.ID, req.Reason))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrTransactionNotReversible, err)
+		}
+		refund.Channel = requestChannel(ctx)
+
+		if err := executeReversal(txRepo, refund); err != nil {
+			return err
+		}
+
+		result = &models.RefundResult{
+			Refund:              refund,
+			OriginalAmount:      original.Amount,
+			RefundedTotal:       fromMinorUnits(minorUnits(refunded) + minorUnits(req.Amount)),
+			RemainingRefundable: fromMinorUnits(remaining - minorUnits(req.Amount)),
+		}
+		return nil
+	})
+
+	if refund != nil {
+		logTransactionCreated(ctx, refund, startedAt, err)
+		s.fireStatusHooks(ctx, models.StatusPending, refund, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// minorUnits tutarı hassasiyet basamağında tam sayıya çevirir (kümülatif karşılaştırmada float kayması olmasın)
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * math.Pow10(models.AmountPrecision())))
+}
+
+// fromMinorUnits minorUnits ile çevrilmiş tutarı geri çevirir
+func fromMinorUnits(units int64) float64 {
+	return models.MinorUnitsToAmount(units, models.AmountPrecision())
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/features"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// expectRefundableTransfer 10 → 20 giden 150 TL'lik completed transferin kilitlenip kontrol edilmesini mock'lar
+func expectRefundableTransfer(dbMock sqlmock.Sqlmock, refunded float64) {
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(10, 20, 150.0, models.TypeTransfer, models.StatusCompleted, nil, nil))
+	dbMock.ExpectQuery("SELECT id FROM transactions WHERE reversed_transaction_id").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectRefundedTotal(dbMock, 42, refunded)
+}
+
+// expectRefundApplied alıcı 20'den gönderen 10'a amount tutarlı iadenin işlenmesini mock'lar
+func expectRefundApplied(dbMock sqlmock.Sqlmock, refundID int, amount float64) {
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(50.0))
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(400.0))
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(20, 10, amount, models.TypeTransfer, models.StatusPending, "#42 kısmi iadesi: eksik ürün", "", nil, 42).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(refundID, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(400.0-amount, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").
+		WithArgs(20, 400.0, 400.0-amount, -amount, models.BalanceReasonRefundOut, refundID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(50.0+amount, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").
+		WithArgs(10, 50.0, 50.0+amount, amount, models.BalanceReasonRefundIn, refundID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusCompleted, refundID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// TestTransactionService_Refund_PartialByRecipient, transferin alıcısının bir kısmını gönderene iade edebildiğini,
+// iade kaydının orijinale bağlandığını ve kalan iade edilebilir tutarın döndüğünü test eder.
+func TestTransactionService_Refund_PartialByRecipient(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Refunds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	expectRefundableTransfer(dbMock, 0)
+	expectRefundApplied(dbMock, 80, 40)
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	result, err := transactionService.Refund(context.Background(), 42, 20, false,
+		&models.RefundTransactionRequest{Amount: 40, Reason: "eksik ürün"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 80, result.Refund.ID)
+	assert.Equal(t, models.StatusCompleted, result.Refund.Status)
+	assert.Equal(t, 42, *result.Refund.RefundedTransactionID)
+	assert.Nil(t, result.Refund.ReversedTransactionID)
+	assert.Equal(t, 150.0, result.OriginalAmount)
+	assert.Equal(t, 40.0, result.RefundedTotal)
+	assert.Equal(t, 110.0, result.RemainingRefundable)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Refund_OverRefundRejected, orijinal tutarı aşan iadenin bakiyelere dokunmadan reddedildiğini test eder.
+func TestTransactionService_Refund_OverRefundRejected(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Refunds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	expectRefundableTransfer(dbMock, 0)
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	result, err := transactionService.Refund(context.Background(), 42, 20, false,
+		&models.RefundTransactionRequest{Amount: 150.01, Reason: "eksik ürün"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrRefundExceedsRemaining)
+	assert.Contains(t, err.Error(), "iade edilebilir kalan 150.00 TL")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Refund_CumulativeLimit, önceki iadelerle birlikte orijinali aşan iadenin reddedildiğini,
+// kalan tutarın tamamının ise iade edilebildiğini test eder.
+func TestTransactionService_Refund_CumulativeLimit(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Refunds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// 150 TL'nin 120'si daha önce iade edilmiş: 40 reddedilir, kalan 30 geçer
+	dbMock.ExpectBegin()
+	expectRefundableTransfer(dbMock, 120)
+	dbMock.ExpectRollback()
+
+	dbMock.ExpectBegin()
+	expectRefundableTransfer(dbMock, 120)
+	expectRefundApplied(dbMock, 81, 30)
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	rejected, rejectErr := transactionService.Refund(context.Background(), 42, 1, true,
+		&models.RefundTransactionRequest{Amount: 40, Reason: "eksik ürün"})
+	accepted, acceptErr := transactionService.Refund(context.Background(), 42, 1, true,
+		&models.RefundTransactionRequest{Amount: 30, Reason: "eksik ürün"})
+
+	// Assert
+	assert.Nil(t, rejected)
+	assert.ErrorIs(t, rejectErr, ErrRefundExceedsRemaining)
+	assert.Contains(t, rejectErr.Error(), "iade edilebilir kalan 30.00 TL")
+
+	require.NoError(t, acceptErr)
+	assert.Equal(t, 150.0, accepted.RefundedTotal)
+	assert.Equal(t, 0.0, accepted.RemainingRefundable)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Refund_OwnershipAndFlag, transferin alıcısı olmayan kullanıcının iade yapamadığını ve
+// "refunds" flag'i kapalıyken iadenin DB'ye gitmeden reddedildiğini test eder.
+func TestTransactionService_Refund_OwnershipAndFlag(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Refunds: true})
+
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// Gönderen (10) kendi gönderdiği transferi iade edemez
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(10, 20, 150.0, models.TypeTransfer, models.StatusCompleted, nil, nil))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	req := &models.RefundTransactionRequest{Amount: 10, Reason: "eksik ürün"}
+
+	// Act
+	_, forbiddenErr := transactionService.Refund(context.Background(), 42, 10, false, req)
+	features.SetEnabled(features.Refunds, false)
+	_, disabledErr := transactionService.Refund(context.Background(), 42, 20, false, req)
+
+	// Assert
+	assert.ErrorIs(t, forbiddenErr, ErrRefundForbidden)
+	assert.ErrorIs(t, disabledErr, ErrRefundsDisabled)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+var (
+	// ErrTransactionNotFound ters çevrilecek transaction bulunamadığında döner
+	ErrTransactionNotFound = errors.New("transaction bulunamadı")
+	// ErrTransactionNotReversible completed olmayan (pending, failed...) veya kendisi ters kayıt olan transaction için döner
+	ErrTransactionNotReversible = errors.New("transaction ters çevrilemez")
+	// ErrTransactionAlreadyReversed transaction daha önce ters çevrildiyse döner
+	ErrTransactionAlreadyReversed = errors.New("transaction zaten ters çevrilmiş")
+)
+
+// Reverse completed bir transaction'ı dengeleyen ters kaydı (credit ↔ debit, transfer ters yönde) tek DB transaction'ında oluşturur.
+// Bakiyeler FOR UPDATE ile kilitlenir; ters kayıt reversed_transaction_id ile orijinale bağlanır.
+func (s *TransactionService) Reverse(ctx context.Context, originalTxID int, reason string) (*models.Transaction, error) {
+	if originalTxID <= 0 {
+		return nil, fmt.Errorf("geçersiz transaction ID")
+	}
+
+	var reversal *models.Transaction
+	startedAt := time.Now()
+
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+
+		// Orijinal kilitlenir: eşzamanlı iki ters kayıt isteği sırayla işlenir
+		original, err := lockReversibleTransaction(txRepo, originalTxID)
+		if err != nil {
+			return err
+		}
+
+		reversal, err = models.NewReversalTransaction(original, fmt.Sprintf("#%d ters kaydı: %s", original.ID, reason))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrTransactionNotReversible, err)
+		}
+		reversal.Channel = requestChannel(ctx)
+
+		return executeReversal(txRepo, reversal)
+	})
+
+	if reversal != nil {
+		logTransactionCreated(ctx, reversal, startedAt, err)
+		s.fireStatusHooks(ctx, models.StatusPending, reversal, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// lockReversibleTransaction orijinal transaction'ı FOR UPDATE ile okur ve ters çevrilebilir olduğunu kontrol eder.
+// Kısmi iade yapılmış transaction tamamen ters çevrilemez; kalan tutar iade ile geri verilir.
+func lockReversibleTransaction(txRepo *db.TransactionRepository, id int) (*models.Transaction, error) {
+	original, err := lockTransactionRow(txRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCounterable(txRepo, original); err != nil {
+		return nil, err
+	}
+
+	refunded, err := refundedTotal(txRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if refunded > 0 {
+		return nil, fmt.Errorf("%w: %.2f TL kısmi iade yapılmış", ErrTransactionNotReversible, refunded)
+	}
+
+	return original, nil
+}
+
+// lockTransactionRow transaction'ı ters kayıt/iade bağlantılarıyla birlikte FOR UPDATE ile okur
+func lockTransactionRow(txRepo *db.TransactionRepository, id int) (*models.Transaction, error) {
+	original := &models.Transaction{ID: id}
+	var reversedTransactionID, refundedTransactionID sql.NullInt64
+
+	err := txRepo.QueryRow(`
+		SELECT from_user_id, to_user_id, amount, type, status, reversed_transaction_id, refunded_transaction_id
+		FROM transactions
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&original.FromUserID, &original.ToUserID, &original.Amount, &original.Type, &original.Status,
+		&reversedTransactionID, &refundedTransactionID)
+	if err == sql.ErrNoRows {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("transaction alınamadı: %w", err)
+	}
+
+	if reversedTransactionID.Valid {
+		linkedID := int(reversedTransactionID.Int64)
+		original.ReversedTransactionID = &linkedID
+	}
+	if refundedTransactionID.Valid {
+		linkedID := int(refundedTransactionID.Int64)
+		original.RefundedTransactionID = &linkedID
+	}
+	return original, nil
+}
+
+// checkCounterable kilitli transaction'ın completed, kendisi ters kayıt/iade olmayan ve henüz ters çevrilmemiş olduğunu kontrol eder
+func checkCounterable(txRepo *db.TransactionRepository, original *models.Transaction) error {
+	if original.ReversedTransactionID != nil {
+		return fmt.Errorf("%w: ters kayıt tekrar ters çevrilemez", ErrTransactionNotReversible)
+	}
+	if original.RefundedTransactionID != nil {
+		return fmt.Errorf("%w: iade kaydı ters çevrilemez", ErrTransactionNotReversible)
+	}
+	if original.Status != models.StatusCompleted {
+		return fmt.Errorf("%w: status %s", ErrTransactionNotReversible, original.Status)
+	}
+
+	var existingID int
+	err := txRepo.QueryRow(`SELECT id FROM transactions WHERE reversed_transaction_id = $1`, original.ID).Scan(&existingID)
+	if err == nil {
+		return fmt.Errorf("%w (ters kayıt #%d)", ErrTransactionAlreadyReversed, existingID)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("önceki ters kayıt kontrolü yapılamadı: %w", err)
+	}
+
+	return nil
+}
+
+// refundedTotal transaction'a bağlı tamamlanmış kısmi iadelerin toplamını döner
+func refundedTotal(txRepo *db.TransactionRepository, id int) (float64, error) {
+	var total float64
+	err := txRepo.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE refunded_transaction_id = $1 AND status = $2
+	`, id, models.StatusCompleted).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("önceki iadeler alınamadı: %w", err)
+	}
+	return total, nil
+}
+
+// executeReversal ters kaydın (veya kısmi iadenin) bakiyelerini kilitler, kaydı oluşturur ve bakiyeleri günceller
+func executeReversal(txRepo *db.TransactionRepository, reversal *models.Transaction) error {
+	reasonOut, reasonIn := models.BalanceReasonReversalOut, models.BalanceReasonReversalIn
+	if reversal.RefundedTransactionID != nil {
+		reasonOut, reasonIn = models.BalanceReasonRefundOut, models.BalanceReasonRefundIn
+	}
+
+	// Para çıkan tarafın bakiyesi yetmeli (aktif hold'lar kullanılamaz)
+	var fromBalance, toBalance float64
+	var err error
+	switch {
+	case reversal.FromUserID != nil && reversal.ToUserID != nil:
+		fromBalance, toBalance, err = lockTransferBalances(txRepo, *reversal.FromUserID, *reversal.ToUserID, reversal.Amount, reversal, false)
+	case reversal.FromUserID != nil:
+		fromBalance, err = lockReversalBalance(txRepo, *reversal.FromUserID, reversal.Amount, reversal)
+	default:
+		toBalance, err = lockReversalBalance(txRepo, *reversal.ToUserID, 0, reversal)
+	}
+	if err != nil {
+		return err
+	}
+
+	var createdAt sql.NullTime
+	err = txRepo.QueryRow(`
+		INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description, channel, reversed_transaction_id, refunded_transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9)
+		RETURNING id, created_at
+	`, reversal.FromUserID, reversal.ToUserID, reversal.Amount, reversal.Type, reversal.Status, reversal.Description,
+		reversal.Channel, reversal.ReversedTransactionID, reversal.RefundedTransactionID).Scan(&reversal.ID, &createdAt)
+	if err != nil {
+		reversal.SetStatus(models.StatusFailed)
+		return fmt.Errorf("ters kayıt oluşturulamadı: %w", err)
+	}
+	reversal.CreatedAt = createdAt.Time
+
+	if reversal.FromUserID != nil {
+		if _, err := txRepo.Exec(`UPDATE balances SET amount = $1 WHERE user_id = $2`, fromBalance-reversal.Amount, *reversal.FromUserID); err != nil {
+			reversal.SetStatus(models.StatusFailed)
+			return fmt.Errorf("gönderen bakiye güncellenemedi: %w", err)
+		}
+		if err := recordBalanceChange(txRepo, *reversal.FromUserID, fromBalance, fromBalance-reversal.Amount, reasonOut, reversal.ID); err != nil {
+			reversal.SetStatus(models.StatusFailed)
+			return err
+		}
+	}
+	if reversal.ToUserID != nil {
+		if _, err := txRepo.Exec(`UPDATE balances SET amount = $1 WHERE user_id = $2`, toBalance+reversal.Amount, *reversal.ToUserID); err != nil {
+			reversal.SetStatus(models.StatusFailed)
+			return fmt.Errorf("alan bakiye güncellenemedi: %w", err)
+		}
+		if err := recordBalanceChange(txRepo, *reversal.ToUserID, toBalance, toBalance+reversal.Amount, reasonIn, reversal.ID); err != nil {
+			reversal.SetStatus(models.StatusFailed)
+			return err
+		}
+	}
+
+	if err := reversal.SetStatus(models.StatusCompleted); err != nil {
+		return fmt.Errorf("transaction status güncellenemedi: %w", err)
+	}
+	if _, err := txRepo.Exec(`UPDATE transactions SET status = $1 WHERE id = $2`, reversal.Status, reversal.ID); err != nil {
+		return fmt.Errorf("transaction status database'de güncellenemedi: %w", err)
+	}
+
+	return nil
+}
+
+// lockReversalBalance tek taraflı ters kayıtta (credit/debit) kullanıcının bakiyesini kilitler ve required kadar kullanılabilir bakiye arar
+func lockReversalBalance(txRepo *db.TransactionRepository, userID int, required float64, reversal *models.Transaction) (float64, error) {
+	var balance float64
+	err := txRepo.QueryRow(`SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE`, userID).Scan(&balance)
+	if err == sql.ErrNoRows {
+		reversal.SetStatus(models.StatusFailed)
+		return 0, fmt.Errorf("kullanıcının bakiyesi bulunamadı")
+	}
+	if err != nil {
+		reversal.SetStatus(models.StatusFailed)
+		return 0, fmt.Errorf("bakiye sorgusu hatası: %w", err)
+	}
+
+	if required > 0 {
+		held, err := heldAmount(txRepo, userID)
+		if err != nil {
+			reversal.SetStatus(models.StatusFailed)
+			return 0, err
+		}
+		if balance-held < required {
+			reversal.SetStatus(models.StatusFailed)
+			return 0, newInsufficientBalanceError(balance-held, required)
+		}
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// reversalLockColumns lockTransactionRow'un okuduğu kolonlar
+var reversalLockColumns = []string{"from_user_id", "to_user_id", "amount", "type", "status", "reversed_transaction_id", "refunded_transaction_id"}
+
+// expectRefundedTotal orijinale bağlı tamamlanmış kısmi iadelerin toplamını mock'lar
+func expectRefundedTotal(dbMock sqlmock.Sqlmock, id int, total float64) {
+	dbMock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\), 0\\) FROM transactions WHERE refunded_transaction_id").
+		WithArgs(id, models.StatusCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(total))
+}
+
+// TestTransactionService_Reverse_TransferThenDoubleReversal, transferin ters yönde geri alındığını ve
+// aynı transaction'ın ikinci kez ters çevrilemediğini test eder.
+func TestTransactionService_Reverse_TransferThenDoubleReversal(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// İlk ters kayıt: 10 → 20 giden 150 TL, 20 → 10 olarak geri döner
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(10, 20, 150.0, models.TypeTransfer, models.StatusCompleted, nil, nil))
+	dbMock.ExpectQuery("SELECT id FROM transactions WHERE reversed_transaction_id").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectRefundedTotal(dbMock, 42, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(50.0))
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(400.0))
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(20, 10, 150.0, models.TypeTransfer, models.StatusPending, "#42 ters kaydı: hatalı transfer", "", 42, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(77, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(250.0, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(200.0, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusCompleted, 77).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	// İkinci ters kayıt: orijinal zaten #77 ile ters çevrilmiş
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(10, 20, 150.0, models.TypeTransfer, models.StatusCompleted, nil, nil))
+	dbMock.ExpectQuery("SELECT id FROM transactions WHERE reversed_transaction_id").WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(77))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	reversal, firstErr := transactionService.Reverse(context.Background(), 42, "hatalı transfer")
+	second, secondErr := transactionService.Reverse(context.Background(), 42, "hatalı transfer")
+
+	// Assert
+	require.NoError(t, firstErr)
+	assert.Equal(t, 77, reversal.ID)
+	assert.Equal(t, models.StatusCompleted, reversal.Status)
+	assert.Equal(t, 20, *reversal.FromUserID)
+	assert.Equal(t, 10, *reversal.ToUserID)
+	assert.Equal(t, 42, *reversal.ReversedTransactionID)
+
+	assert.Nil(t, second)
+	assert.ErrorIs(t, secondErr, ErrTransactionAlreadyReversed)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Reverse_WritesLinkedBalanceHistory, credit'in geri alınmasının bakiye değişikliğini
+// aynı DB transaction'ında ters kayda bağlı, reversal_out reason'lı bir balance_history satırı olarak yazdığını test eder.
+func TestTransactionService_Reverse_WritesLinkedBalanceHistory(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(9).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(nil, 10, 100.0, models.TypeCredit, models.StatusCompleted, nil, nil))
+	dbMock.ExpectQuery("SELECT id FROM transactions WHERE reversed_transaction_id").WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectRefundedTotal(dbMock, 9, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(130.0))
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(10, nil, 100.0, models.TypeDebit, models.StatusPending, "#9 ters kaydı: hatalı yatırma", "", 9, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(78, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(30.0, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").
+		WithArgs(10, 130.0, 30.0, -100.0, models.BalanceReasonReversalOut, 78).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusCompleted, 78).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	reversal, err := transactionService.Reverse(context.Background(), 9, "hatalı yatırma")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 78, reversal.ID)
+	assert.Equal(t, 9, *reversal.ReversedTransactionID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Reverse_ReversalCannotBeReversed, ters kaydın kendisinin ters çevrilemediğini test eder.
+func TestTransactionService_Reverse_ReversalCannotBeReversed(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(77).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(20, 10, 150.0, models.TypeTransfer, models.StatusCompleted, 42, nil))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	reversal, err := transactionService.Reverse(context.Background(), 77, "geri al")
+
+	// Assert
+	assert.Nil(t, reversal)
+	assert.ErrorIs(t, err, ErrTransactionNotReversible)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Reverse_PendingTransaction, completed olmayan transaction'ın ters çevrilemediğini test eder.
+func TestTransactionService_Reverse_PendingTransaction(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(10, 20, 80.0, models.TypeTransfer, models.StatusPendingReview, nil, nil))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	reversal, err := transactionService.Reverse(context.Background(), 5, "iptal")
+
+	// Assert
+	assert.Nil(t, reversal)
+	assert.ErrorIs(t, err, ErrTransactionNotReversible)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Reverse_CreditInsufficientBalance, credit'in geri alınmasında (debit) bakiye yetmezse reddedildiğini test eder.
+func TestTransactionService_Reverse_CreditInsufficientBalance(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transactions\\s+WHERE id = \\$1\\s+FOR UPDATE").WithArgs(9).
+		WillReturnRows(sqlmock.NewRows(reversalLockColumns).AddRow(nil, 10, 100.0, models.TypeCredit, models.StatusCompleted, nil, nil))
+	dbMock.ExpectQuery("SELECT id FROM transactions WHERE reversed_transaction_id").WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectRefundedTotal(dbMock, 9, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(30.0))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	reversal, err := transactionService.Reverse(context.Background(), 9, "hatalı yatırma")
+
+	// Assert
+	assert.Nil(t, reversal)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/onerilhan/go-payment-api/internal/db"
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+var (
+	// ErrTransferRequiresReview batch içindeki bir transfer veya hold incelemeye takıldığında döner (tekil transfer olarak gönderilmeli)
+	ErrTransferRequiresReview = errors.New("transfer fraud incelemesi gerektiriyor")
+	// ErrReviewNotFound inceleme kaydı bulunamadığında döner
+	ErrReviewNotFound = errors.New("inceleme kaydı bulunamadı")
+	// ErrReviewAlreadyDecided karara bağlanmış incelemeye tekrar karar verilmek istendiğinde döner
+	ErrReviewAlreadyDecided = errors.New("inceleme zaten karara bağlanmış")
+)
+
+// ReviewRule transferi değerlendirir; boş olmayan bir sebep dönerse transfer tamamlanmaz, incelemeye alınır
+type ReviewRule func(fromUserID int, req *models.TransferRequest) (reason string, err error)
+
+// FraudReviewConfig yerleşik inceleme kurallarının eşikleri (0 = kural kapalı)
+type FraudReviewConfig struct {
+	// AmountThreshold bu tutar ve üzerindeki tüm transferler incelemeye alınır
+	AmountThreshold float64
+	// NewRecipientAmountThreshold daha önce tamamlanmış transfer yapılmamış alıcıya bu tutar ve üzeri incelemeye alınır
+	NewRecipientAmountThreshold float64
+	// AnomalyMultiple kullanıcının son dönem ortalamasının bu katını ve en büyük transferini aşan tutarlar incelemeye alınır
+	AnomalyMultiple float64
+	// AnomalyLookback ortalama/max hesabına giren geçmiş süresi
+	AnomalyLookback time.Duration
+	// AnomalyMinHistory geçmişte bu sayıdan az transfer varsa anomali kuralı uygulanmaz (karşılaştırılacak veri yok)
+	AnomalyMinHistory int
+}
+
+// ReviewRules config'teki eşiklerden yerleşik kuralları oluşturur
+func (c *FraudReviewConfig) ReviewRules(transactionRepo interfaces.TransactionRepositoryInterface) []ReviewRule {
+	if c == nil {
+		return nil
+	}
+
+	var rules []ReviewRule
+	if c.AmountThreshold > 0 {
+		rules = append(rules, AmountReviewRule(c.AmountThreshold))
+	}
+	if c.NewRecipientAmountThreshold > 0 {
+		rules = append(rules, NewRecipientReviewRule(transactionRepo, c.NewRecipientAmountThreshold))
+	}
+	if c.AnomalyMultiple > 0 && c.AnomalyLookback > 0 {
+		rules = append(rules, AmountAnomalyReviewRule(transactionRepo, c.AnomalyMultiple, c.AnomalyLookback, c.AnomalyMinHistory))
+	}
+	return rules
+}
+
+// AmountReviewRule eşik ve üzerindeki transferleri incelemeye alan kural
+func AmountReviewRule(threshold float64) ReviewRule {
+	return func(fromUserID int, req *models.TransferRequest) (string, error) {
+		if req.Amount >= threshold {
+			return fmt.Sprintf("tutar %.2f TL inceleme eşiğini (%.2f TL) aşıyor", req.Amount, threshold), nil
+		}
+		return "", nil
+	}
+}
+
+// NewRecipientReviewRule daha önce tamamlanmış transfer yapılmamış alıcıya eşik ve üzerindeki transferleri incelemeye alan kural
+func NewRecipientReviewRule(transactionRepo interfaces.TransactionRepositoryInterface, threshold float64) ReviewRule {
+	return func(fromUserID int, req *models.TransferRequest) (string, error) {
+		if req.Amount < threshold {
+			return "", nil
+		}
+
+		count, err := transactionRepo.CountCompletedTransfersBetween(fromUserID, req.ToUserID)
+		if err != nil {
+			return "", fmt.Errorf("alıcı geçmişi kontrol edilemedi: %w", err)
+		}
+		if count == 0 {
+			return fmt.Sprintf("yeni alıcıya %.2f TL transfer (eşik %.2f TL)", req.Amount, threshold), nil
+		}
+		return "", nil
+	}
+}
+
+// AmountAnomalyReviewRule kullanıcının son dönem geçmişine göre anormal büyüklükteki transferleri incelemeye alan kural.
+// Tutar hem ortalamanın multiple katını hem de dönemdeki en büyük transferi aşıyorsa tetiklenir;
+// daha önce benzer tutarlar gönderen kullanıcılar bu sayede takılmaz.
+func AmountAnomalyReviewRule(transactionRepo interfaces.TransactionRepositoryInterface, multiple float64, lookback time.Duration, minHistory int) ReviewRule {
+	return func(fromUserID int, req *models.TransferRequest) (string, error) {
+		history, err := transactionRepo.GetTransferAmountHistorySince(fromUserID, time.Now().Add(-lookback))
+		if err != nil {
+			return "", fmt.Errorf("transfer geçmişi kontrol edilemedi: %w", err)
+		}
+		if history.Count == 0 || history.Count < minHistory {
+			return "", nil
+		}
+
+		if req.Amount > history.Average*multiple && req.Amount > history.Max {
+			return fmt.Sprintf("tutar %.2f TL son dönem ortalamasının (%.2f TL) %.0f katını aşıyor", req.Amount, history.Average, multiple), nil
+		}
+		return "", nil
+	}
+}
+
+// SetReviewRules transferlere uygulanacak inceleme kurallarını ayarlar (boş = inceleme kapalı)
+func (s *TransactionService) SetReviewRules(rules ...ReviewRule) {
+	s.reviewRules = rules
+}
+
+// reviewReason tüm kuralları çalıştırır ve tetiklenenlerin sebeplerini birleştirir (boş = inceleme gerekmez)
+func (s *TransactionService) reviewReason(fromUserID int, req *models.TransferRequest) (string, error) {
+	var reasons []string
+	for _, rule := range s.reviewRules {
+		reason, err := rule(fromUserID, req)
+		if err != nil {
+			return "", err
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return strings.Join(reasons, "; "), nil
+}
+
+// holdTransferForReview transferi bakiye hareket ettirmeden pending_review olarak kaydeder ve inceleme kaydı açar
+func (s *TransactionService) holdTransferForReview(ctx context.Context, fromUserID int, req *models.TransferRequest, transaction *models.Transaction, reason string, limits dailyLimits) error {
+	return db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		// Tekrar denemede önceki denemenin status'u taşınmasın
+		transaction.Status = models.StatusPending
+		txRepo := db.NewTransactionRepository(tx)
+
+		// İncelemeye alınmadan önce bakiyenin o an yettiği kontrol edilir (onayda tekrar kontrol edilir).
+		// Bakiye kilitlenir: bekleyen transfer de günlük sayı ve giden tutar limitlerine dahildir.
+		var fromBalance float64
+		err := txRepo.QueryRow(`SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE`, fromUserID).Scan(&fromBalance)
+		if err == sql.ErrNoRows {
+			transaction.SetStatus(models.StatusFailed)
+			return fmt.Errorf("gönderen kullanıcının bakiyesi bulunamadı")
+		}
+		if err != nil {
+			transaction.SetStatus(models.StatusFailed)
+			return fmt.Errorf("gönderen bakiye sorgusu hatası: %w", err)
+		}
+		held, err := heldAmount(txRepo, fromUserID)
+		if err != nil {
+			transaction.SetStatus(models.StatusFailed)
+			return err
+		}
+		if fromBalance-held < req.Amount {
+			transaction.SetStatus(models.StatusFailed)
+			return newInsufficientBalanceError(fromBalance-held, req.Amount)
+		}
+		if err := checkMinimumBalance(txRepo, fromUserID, fromBalance-held, req.Amount, transaction); err != nil {
+			return err
+		}
+		if err := checkDailyCount(txRepo, fromUserID, limits.count, transaction); err != nil {
+			return err
+		}
+		// Giden limit hold anında uygulanır: pending_review kaydı bugünkü giden toplama girdiğinden onayda tekrar sayılmaz
+		if err := checkDailyOutbound(txRepo, fromUserID, req.Amount, limits.amount, transaction); err != nil {
+			return err
+		}
+
+		if err := transaction.SetStatus(models.StatusPendingReview); err != nil {
+			return fmt.Errorf("transaction status güncellenemedi: %w", err)
+		}
+
+		var createdAt sql.NullTime
+		err = txRepo.QueryRow(`
+			INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description, channel)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+			RETURNING id, created_at
+		`, fromUserID, req.ToUserID, req.Amount, transaction.Type, transaction.Status, req.Description, transaction.Channel).Scan(&transaction.ID, &createdAt)
+		if err != nil {
+			return fmt.Errorf("transaction kaydı oluşturulamadı: %w", err)
+		}
+		transaction.CreatedAt = createdAt.Time
+
+		_, err = txRepo.Exec(`
+			INSERT INTO transaction_reviews (transaction_id, reason, status) VALUES ($1, $2, $3)
+		`, transaction.ID, reason, models.ReviewStatusOpen)
+		if err != nil {
+			return fmt.Errorf("inceleme kaydı oluşturulamadı: %w", err)
+		}
+
+		return s.completeIdempotency(ctx, tx, transaction.ID, 0)
+	})
+}
+
+// ListTransferReviews verilen status'taki inceleme kayıtlarını (transaction'larıyla) en eskiden yeniye listeler
+func (s *TransactionService) ListTransferReviews(status string, limit, offset int) ([]*models.TransactionReview, error) {
+	if status == "" {
+		status = models.ReviewStatusOpen
+	}
+	if !models.IsValidReviewStatus(status) {
+		return nil, fmt.Errorf("geçersiz inceleme status'u: %s", status)
+	}
+
+	reviews, err := s.transactionRepo.GetTransactionReviews(status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("inceleme kayıtları alınamadı: %w", err)
+	}
+	return reviews, nil
+}
+
+// İnceleme kaydının kilitlenirken arandığı kolonlar
+const (
+	reviewLookupByID            = "r.id"
+	reviewLookupByTransactionID = "r.transaction_id"
+)
+
+// ApproveTransferReview incelemedeki transferi normal transfer adımlarıyla (bakiye lock, yeterlilik, güncelleme) gerçekleştirir.
+// Gönderenin bakiyesi onay anında tekrar kontrol edilir; artık yetmiyorsa hata döner ve inceleme açık kalır.
+func (s *TransactionService) ApproveTransferReview(ctx context.Context, reviewID int, decision *models.ReviewDecision) (*models.TransactionReview, error) {
+	return s.decideTransferReview(ctx, reviewLookupByID, reviewID, decision, models.ReviewStatusApproved)
+}
+
+// RejectTransferReview incelemedeki transferi bakiye hareket ettirmeden failed olarak kapatır
+func (s *TransactionService) RejectTransferReview(ctx context.Context, reviewID int, decision *models.ReviewDecision) (*models.TransactionReview, error) {
+	return s.decideTransferReview(ctx, reviewLookupByID, reviewID, decision, models.ReviewStatusRejected)
+}
+
+// ApproveHeldTransaction pending_review durumundaki transaction'ı ID'si ile onaylar (bkz. ApproveTransferReview)
+func (s *TransactionService) ApproveHeldTransaction(ctx context.Context, transactionID int, decision *models.ReviewDecision) (*models.TransactionReview, error) {
+	return s.decideTransferReview(ctx, reviewLookupByTransactionID, transactionID, decision, models.ReviewStatusApproved)
+}
+
+// RejectHeldTransaction pending_review durumundaki transaction'ı ID'si ile reddeder (bkz. RejectTransferReview)
+func (s *TransactionService) RejectHeldTransaction(ctx context.Context, transactionID int, decision *models.ReviewDecision) (*models.TransactionReview, error) {
+	return s.decideTransferReview(ctx, reviewLookupByTransactionID, transactionID, decision, models.ReviewStatusRejected)
+}
+
+// decideTransferReview inceleme kaydını kilitler, kararı uygular, kaydı günceller ve audit kaydı yazar (tek DB transaction'ı)
+func (s *TransactionService) decideTransferReview(ctx context.Context, lookup string, id int, decision *models.ReviewDecision, outcome string) (*models.TransactionReview, error) {
+	var review *models.TransactionReview
+	startedAt := time.Now()
+
+	err := db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		txRepo := db.NewTransactionRepository(tx)
+
+		var err error
+		review, err = lockTransferReview(txRepo, lookup, id)
+		if err != nil {
+			return err
+		}
+		if !review.IsOpen() {
+			return fmt.Errorf("%w: %s", ErrReviewAlreadyDecided, review.Status)
+		}
+
+		transaction := review.Transaction
+		previousStatus := transaction.Status
+		if outcome == models.ReviewStatusApproved {
+			// Bakiye veya gönderenin minimum bakiyesi hold anından beri değişmiş olabilir: lock altında tekrar kontrol edilir.
+			// Transferi kullanıcı başlattığı için admin onayı minimum bakiye kuralını atlatmaz.
+			fromBalance, toBalance, err := lockTransferBalances(txRepo, *transaction.FromUserID, *transaction.ToUserID, transaction.Amount, transaction, true)
+			if err != nil {
+				return err
+			}
+			if err := applyTransfer(txRepo, *transaction.FromUserID, *transaction.ToUserID, transaction.Amount, fromBalance, toBalance, transaction.ID, transaction); err != nil {
+				return err
+			}
+		} else {
+			if err := transaction.SetStatus(models.StatusFailed); err != nil {
+				return fmt.Errorf("transaction status güncellenemedi: %w", err)
+			}
+			if _, err := txRepo.Exec(`UPDATE transactions SET status = $1 WHERE id = $2`, transaction.Status, transaction.ID); err != nil {
+				return fmt.Errorf("transaction status database'de güncellenemedi: %w", err)
+			}
+		}
+
+		var reviewedAt time.Time
+		err = txRepo.QueryRow(`
+			UPDATE transaction_reviews
+			SET status = $1, note = $2, reviewed_by = $3, reviewed_at = NOW()
+			WHERE id = $4
+			RETURNING reviewed_at
+		`, outcome, decision.Reason, decision.AdminUserID, review.ID).Scan(&reviewedAt)
+		if err != nil {
+			return fmt.Errorf("inceleme kaydı güncellenemedi: %w", err)
+		}
+
+		if err := insertReviewAuditLog(txRepo, transaction, previousStatus, outcome, decision); err != nil {
+			return err
+		}
+
+		review.Status = outcome
+		review.Note = decision.Reason
+		review.ReviewedBy = &decision.AdminUserID
+		review.ReviewedAt = &reviewedAt
+		return nil
+	})
+
+	if review != nil && review.Transaction != nil && outcome == models.ReviewStatusApproved {
+		logTransactionCreated(ctx, review.Transaction, startedAt, err)
+	}
+	if review != nil && review.Transaction != nil {
+		s.fireStatusHooks(ctx, models.StatusPendingReview, review.Transaction, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// insertReviewAuditLog inceleme kararını audit_logs tablosuna yazar (status değişimi, admin, sebep, IP, user agent)
+func insertReviewAuditLog(txRepo *db.TransactionRepository, transaction *models.Transaction, previousStatus, outcome string, decision *models.ReviewDecision) error {
+	oldData, _ := json.Marshal(map[string]string{"status": previousStatus})
+	newData, _ := json.Marshal(map[string]string{"status": transaction.Status, "review": outcome})
+
+	action := models.AuditActionReviewApprove
+	if outcome == models.ReviewStatusRejected {
+		action = models.AuditActionReviewReject
+	}
+
+	return insertTransactionAuditLog(txRepo, transaction.ID, action, oldData, newData, decision)
+}
+
+// insertTransactionAuditLog admin'in transaction üzerindeki işlemini audit_logs tablosuna yazar
+func insertTransactionAuditLog(txRepo *db.TransactionRepository, transactionID int, action string, oldData, newData []byte, decision *models.ReviewDecision) error {
+	// INET kolonu geçersiz değeri kabul etmez; çözümlenemeyen IP boş bırakılır
+	var ipAddress interface{}
+	if ip := net.ParseIP(decision.IPAddress); ip != nil {
+		ipAddress = ip.String()
+	}
+
+	_, err := txRepo.Exec(`
+		INSERT INTO audit_logs (entity_type, entity_id, action, user_id, old_data, new_data, details, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, models.AuditEntityTransaction, transactionID, action, decision.AdminUserID,
+		oldData, newData, decision.Reason, ipAddress, decision.UserAgent)
+	if err != nil {
+		return fmt.Errorf("audit log oluşturulamadı: %w", err)
+	}
+	return nil
+}
+
+// lockTransferReview inceleme kaydını ve transferini FOR UPDATE ile kilitleyerek okur (lookup: review ID veya transaction ID kolonu)
+func lockTransferReview(txRepo *db.TransactionRepository, lookup string, id int) (*models.TransactionReview, error) {
+	review := &models.TransactionReview{Transaction: &models.Transaction{}}
+	transaction := review.Transaction
+
+	var note sql.NullString
+	err := txRepo.QueryRow(fmt.Sprintf(`
+		SELECT r.id, r.transaction_id, r.reason, r.status, r.note, r.created_at,
+			t.from_user_id, t.to_user_id, t.amount, t.type, t.status, t.description, t.created_at
+		FROM transaction_reviews r
+		JOIN transactions t ON t.id = r.transaction_id
+		WHERE %s = $1
+		FOR UPDATE
+	`, lookup), id).Scan(
+		&review.ID, &review.TransactionID, &review.Reason, &review.Status, &note, &review.CreatedAt,
+		&transaction.FromUserID, &transaction.ToUserID, &transaction.Amount, &transaction.Type, &transaction.Status, &transaction.Description, &transaction.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inceleme kaydı alınamadı: %w", err)
+	}
+	if transaction.FromUserID == nil || transaction.ToUserID == nil {
+		return nil, fmt.Errorf("inceleme kaydı transfer transaction'ına ait değil")
+	}
+
+	review.Note = note.String
+	transaction.ID = review.TransactionID
+
+	return review, nil
+}
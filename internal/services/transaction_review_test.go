@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// reviewRowColumns lockTransferReview sorgusunun kolonları
+var reviewRowColumns = []string{
+	"id", "transaction_id", "reason", "status", "note", "created_at",
+	"from_user_id", "to_user_id", "amount", "type", "status", "description", "created_at",
+}
+
+// TestTransactionService_Transfer_FlaggedTransferEntersReview, kurala takılan transferin bakiye hareket etmeden incelemeye alındığını test eder.
+func TestTransactionService_Transfer_FlaggedTransferEntersReview(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(10000.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(10, 20, 6000.0, models.TypeTransfer, models.StatusPendingReview, "kira", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, time.Now()))
+	dbMock.ExpectExec("INSERT INTO transaction_reviews").
+		WithArgs(5, sqlmock.AnyArg(), models.ReviewStatusOpen).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+	transactionService.SetReviewRules(AmountReviewRule(5000))
+
+	// Act
+	result, err := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 6000, Description: "kira"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.ID)
+	assert.Equal(t, models.StatusPendingReview, result.Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet()) // UPDATE balances beklenmedi: bakiye hareket etmedi
+}
+
+// TestTransactionService_Transfer_FlaggedTransferOverDailyLimitRejected, incelemeye takılan transferin günlük giden
+// limitin kalanını aşıyorsa incelemeye alınmadan reddedildiğini test eder (onay limiti atlatmamalı).
+func TestTransactionService_Transfer_FlaggedTransferOverDailyLimitRejected(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	// Bugün 9.000 TL gönderildi, limit 10.000 TL: 5.000 TL'lik işaretli transfer sığmaz
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(20000.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	expectDailyOutboundSum(dbMock, 10, 9000)
+	dbMock.ExpectRollback()
+
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{DailyAmountLimitDefault: 10000})
+	transactionService.SetReviewRules(AmountReviewRule(4000))
+
+	// Act
+	result, err := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 5000})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrDailyLimitExceeded)
+	assert.NoError(t, dbMock.ExpectationsWereMet()) // INSERT beklenmedi: incelemeye alınmadı
+}
+
+// TestTransactionService_Transfer_NewRecipientRule, yeni alıcıya eşik üzeri transferin incelemeye, bilinen alıcıya transferin normal akışa gittiğini test eder.
+func TestTransactionService_Transfer_NewRecipientRule(t *testing.T) {
+	// Arrange
+	mockTxRepo := new(MockTransactionRepository)
+	mockTxRepo.On("CountCompletedTransfersBetween", 10, 20).Return(0, nil)
+	mockTxRepo.On("CountCompletedTransfersBetween", 10, 30).Return(2, nil)
+
+	config := &FraudReviewConfig{NewRecipientAmountThreshold: 1000}
+	transactionService := NewTransactionService(mockTxRepo, nil, new(MockBalanceService), nil)
+	transactionService.SetReviewRules(config.ReviewRules(mockTxRepo)...)
+
+	// Act
+	newRecipientReason, newErr := transactionService.reviewReason(10, &models.TransferRequest{ToUserID: 20, Amount: 1500})
+	knownRecipientReason, knownErr := transactionService.reviewReason(10, &models.TransferRequest{ToUserID: 30, Amount: 1500})
+	smallReason, smallErr := transactionService.reviewReason(10, &models.TransferRequest{ToUserID: 20, Amount: 999})
+
+	// Assert
+	assert.NoError(t, newErr)
+	assert.NoError(t, knownErr)
+	assert.NoError(t, smallErr)
+	assert.Equal(t, "yeni alıcıya 1500.00 TL transfer (eşik 1000.00 TL)", newRecipientReason)
+	assert.Empty(t, knownRecipientReason)
+	assert.Empty(t, smallReason)
+	mockTxRepo.AssertNumberOfCalls(t, "CountCompletedTransfersBetween", 2)
+}
+
+// TestTransactionService_Transfer_AmountAnomalyRule, geçmiş ortalamanın katını aşan tutarın incelemeye alındığını, normal tutarın alınmadığını test eder.
+func TestTransactionService_Transfer_AmountAnomalyRule(t *testing.T) {
+	// Arrange
+	mockTxRepo := new(MockTransactionRepository)
+	mockTxRepo.On("GetTransferAmountHistorySince", 10, mock.AnythingOfType("time.Time")).
+		Return(&models.TransferAmountHistory{Count: 8, Average: 200, Max: 600}, nil)
+
+	config := &FraudReviewConfig{AnomalyMultiple: 10, AnomalyLookback: 30 * 24 * time.Hour, AnomalyMinHistory: 5}
+	transactionService := NewTransactionService(mockTxRepo, nil, new(MockBalanceService), nil)
+	transactionService.SetReviewRules(config.ReviewRules(mockTxRepo)...)
+
+	// Act
+	anomalyReason, anomalyErr := transactionService.reviewReason(10, &models.TransferRequest{ToUserID: 20, Amount: 2500})
+	normalReason, normalErr := transactionService.reviewReason(10, &models.TransferRequest{ToUserID: 20, Amount: 500})
+
+	// Assert
+	assert.NoError(t, anomalyErr)
+	assert.NoError(t, normalErr)
+	assert.Equal(t, "tutar 2500.00 TL son dönem ortalamasının (200.00 TL) 10 katını aşıyor", anomalyReason)
+	assert.Empty(t, normalReason)
+}
+
+// TestTransactionService_Transfer_AmountAnomalyRule_SkipsShortHistory, yeterli geçmişi olmayan kullanıcıda anomali kuralının uygulanmadığını test eder.
+func TestTransactionService_Transfer_AmountAnomalyRule_SkipsShortHistory(t *testing.T) {
+	// Arrange
+	mockTxRepo := new(MockTransactionRepository)
+	mockTxRepo.On("GetTransferAmountHistorySince", 10, mock.AnythingOfType("time.Time")).
+		Return(&models.TransferAmountHistory{Count: 2, Average: 50, Max: 60}, nil)
+
+	rule := AmountAnomalyReviewRule(mockTxRepo, 10, 30*24*time.Hour, 5)
+
+	// Act
+	reason, err := rule(10, &models.TransferRequest{ToUserID: 20, Amount: 5000})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, reason)
+}
+
+// TestTransactionService_ApproveTransferReview_SettlesTransfer, onaylanan incelemenin normal transfer adımlarıyla gerçekleştirildiğini test eder.
+func TestTransactionService_ApproveTransferReview_SettlesTransfer(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	reviewedAt := time.Now()
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transaction_reviews r").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(reviewRowColumns).
+			AddRow(3, 5, "tutar eşiği", models.ReviewStatusOpen, nil, reviewedAt.Add(-time.Hour),
+				10, 20, 6000.0, models.TypeTransfer, models.StatusPendingReview, "kira", reviewedAt.Add(-time.Hour)))
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(10000.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(100.0))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(4000.0, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(6100.0, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusCompleted, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery("UPDATE transaction_reviews").WithArgs(models.ReviewStatusApproved, "doğrulandı", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewed_at"}).AddRow(reviewedAt))
+	dbMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	review, err := transactionService.ApproveTransferReview(context.Background(), 3, &models.ReviewDecision{AdminUserID: 1, Reason: "doğrulandı"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewStatusApproved, review.Status)
+	assert.Equal(t, models.StatusCompleted, review.Transaction.Status)
+	assert.Equal(t, 1, *review.ReviewedBy)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_RejectTransferReview_FailsTransfer, reddedilen incelemede transferin bakiye hareket etmeden failed olduğunu test eder.
+func TestTransactionService_RejectTransferReview_FailsTransfer(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	now := time.Now()
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transaction_reviews r").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(reviewRowColumns).
+			AddRow(3, 5, "tutar eşiği", models.ReviewStatusOpen, nil, now,
+				10, 20, 6000.0, models.TypeTransfer, models.StatusPendingReview, "kira", now))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusFailed, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery("UPDATE transaction_reviews").WithArgs(models.ReviewStatusRejected, "", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewed_at"}).AddRow(now))
+	dbMock.ExpectExec("INSERT INTO audit_logs").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	review, err := transactionService.RejectTransferReview(context.Background(), 3, &models.ReviewDecision{AdminUserID: 1})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewStatusRejected, review.Status)
+	assert.Equal(t, models.StatusFailed, review.Transaction.Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_ApproveTransferReview_AlreadyDecided, karara bağlanmış incelemenin tekrar onaylanamadığını test eder.
+func TestTransactionService_ApproveTransferReview_AlreadyDecided(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	now := time.Now()
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("FROM transaction_reviews r").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(reviewRowColumns).
+			AddRow(3, 5, "tutar eşiği", models.ReviewStatusRejected, "şüpheli", now,
+				10, 20, 6000.0, models.TypeTransfer, models.StatusFailed, "kira", now))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	review, err := transactionService.ApproveTransferReview(context.Background(), 3, &models.ReviewDecision{AdminUserID: 1})
+
+	// Assert
+	assert.Nil(t, review)
+	assert.ErrorIs(t, err, ErrReviewAlreadyDecided)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectHeldTransactionLock transaction ID ile açık inceleme kaydının kilitlenmesini bekler (10 -> 20, 6000 TL)
+func expectHeldTransactionLock(dbMock sqlmock.Sqlmock, transactionID int) {
+	now := time.Now()
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`WHERE r\.transaction_id = \$1`).WithArgs(transactionID).
+		WillReturnRows(sqlmock.NewRows(reviewRowColumns).
+			AddRow(3, transactionID, "tutar eşiği", models.ReviewStatusOpen, nil, now,
+				10, 20, 6000.0, models.TypeTransfer, models.StatusPendingReview, "kira", now))
+}
+
+// TestTransactionService_ApproveHeldTransaction_SufficientBalance, onay anında bakiye yeterliyse transferin gerçekleştiğini ve audit kaydı yazıldığını test eder.
+func TestTransactionService_ApproveHeldTransaction_SufficientBalance(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	expectHeldTransactionLock(dbMock, 5)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(6000.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(0.0, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(6000.0, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusCompleted, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery("UPDATE transaction_reviews").WithArgs(models.ReviewStatusApproved, "müşteri aradı", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewed_at"}).AddRow(time.Now()))
+	dbMock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs(models.AuditEntityTransaction, 5, models.AuditActionReviewApprove, 1,
+			[]byte(`{"status":"pending_review"}`), []byte(`{"review":"approved","status":"completed"}`),
+			"müşteri aradı", "10.0.0.7", "admin-panel").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	review, err := transactionService.ApproveHeldTransaction(context.Background(), 5, &models.ReviewDecision{
+		AdminUserID: 1,
+		Reason:      "müşteri aradı",
+		IPAddress:   "10.0.0.7",
+		UserAgent:   "admin-panel",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 5, review.TransactionID)
+	assert.Equal(t, models.StatusCompleted, review.Transaction.Status)
+	assert.Equal(t, "müşteri aradı", review.Note)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_ApproveHeldTransaction_InsufficientBalance, hold'dan sonra bakiye azaldıysa onayın reddedildiğini ve hiçbir şeyin değişmediğini test eder.
+func TestTransactionService_ApproveHeldTransaction_InsufficientBalance(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	expectHeldTransactionLock(dbMock, 5)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(2500.0))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	review, err := transactionService.ApproveHeldTransaction(context.Background(), 5, &models.ReviewDecision{AdminUserID: 1})
+
+	// Assert
+	assert.Nil(t, review)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.NoError(t, dbMock.ExpectationsWereMet()) // Bakiye/status/inceleme güncellemesi yok, rollback
+}
+
+// TestTransactionService_ApproveHeldTransaction_BelowMinimumBalance, onay gönderenin bakiyesini minimum bakiyesinin
+// altına düşürecekse onayın reddedildiğini ve transaction'ın incelemede kaldığını test eder.
+func TestTransactionService_ApproveHeldTransaction_BelowMinimumBalance(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	expectHeldTransactionLock(dbMock, 5)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(6500.0))
+	expectMinimumBalance(dbMock, 10, 1000)
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	review, err := transactionService.ApproveHeldTransaction(context.Background(), 5, &models.ReviewDecision{AdminUserID: 1})
+
+	// Assert
+	assert.Nil(t, review)
+	assert.ErrorIs(t, err, ErrBelowMinimumBalance)
+	assert.NoError(t, dbMock.ExpectationsWereMet()) // Bakiye/status/inceleme güncellemesi yok, pending_review olarak kalır
+}
+
+// TestTransactionService_RejectHeldTransaction_FailsWithAudit, reddedilen transaction'ın failed olduğunu ve sebebin audit kaydına yazıldığını test eder.
+func TestTransactionService_RejectHeldTransaction_FailsWithAudit(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer database.Close()
+
+	expectHeldTransactionLock(dbMock, 5)
+	dbMock.ExpectExec("UPDATE transactions SET status").WithArgs(models.StatusFailed, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery("UPDATE transaction_reviews").WithArgs(models.ReviewStatusRejected, "çalıntı kart şüphesi", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewed_at"}).AddRow(time.Now()))
+	dbMock.ExpectExec("INSERT INTO audit_logs").
+		WithArgs(models.AuditEntityTransaction, 5, models.AuditActionReviewReject, 1,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), "çalıntı kart şüphesi", nil, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	review, err := transactionService.RejectHeldTransaction(context.Background(), 5, &models.ReviewDecision{
+		AdminUserID: 1,
+		Reason:      "çalıntı kart şüphesi",
+		IPAddress:   "not-an-ip",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewStatusRejected, review.Status)
+	assert.Equal(t, models.StatusFailed, review.Transaction.Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
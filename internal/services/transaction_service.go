@@ -1,46 +1,102 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/onerilhan/go-payment-api/internal/db"
 	"github.com/onerilhan/go-payment-api/internal/interfaces"
 	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
+// ErrInsufficientBalance gönderenin bakiyesi işlem için yetersiz
+var ErrInsufficientBalance = errors.New("yetersiz bakiye")
+
+// InsufficientBalanceError kullanılabilir bakiye ve istenen tutarı taşıyan yetersiz bakiye hatası.
+// errors.Is(err, ErrInsufficientBalance) ile yakalanır; tutarlar errors.As ile okunur.
+type InsufficientBalanceError struct {
+	CurrentBalance float64 // Kullanılabilir bakiye (aktif hold'lar düşülmüş)
+	Requested      float64
+}
+
+// newInsufficientBalanceError kullanılabilir bakiye ve istenen tutardan hata oluşturur
+func newInsufficientBalanceError(currentBalance, requested float64) *InsufficientBalanceError {
+	return &InsufficientBalanceError{CurrentBalance: currentBalance, Requested: requested}
+}
+
+// Error mevcut bakiyeyi içeren mesajı döner
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("%s. Mevcut bakiye: %.2f TL", ErrInsufficientBalance, e.CurrentBalance)
+}
+
+// Unwrap errors.Is(err, ErrInsufficientBalance) kontrolünü sağlar
+func (e *InsufficientBalanceError) Unwrap() error {
+	return ErrInsufficientBalance
+}
+
+// Shortfall işlem için eksik kalan tutarı tutar hassasiyetinde yuvarlanmış olarak döner
+func (e *InsufficientBalanceError) Shortfall() float64 {
+	scale := math.Pow10(models.AmountPrecision())
+	return math.Round((e.Requested-e.CurrentBalance)*scale) / scale
+}
+
+// ErrSelfTransfer alıcı (ID, email veya hesap numarası ile çözümlenmiş) gönderenin kendisi
+var ErrSelfTransfer = errors.New("kendinize para gönderemezsiniz")
+
 // TransactionService transaction business logic'i
 type TransactionService struct {
 	transactionRepo interfaces.TransactionRepositoryInterface
+	userRepo        interfaces.UserRepositoryInterface
 	balanceService  interfaces.BalanceServiceInterface // DİKKAT: ARTIK BU DA ARAYÜZ
 	database        *sql.DB
+	retryConfig     *db.RetryConfig         // Deadlock/serialization hatalarında tekrar ayarları
+	limitConfig     *TransactionLimitConfig // Role bazlı günlük limitler
+	feeConfig       *FeeConfig              // Transfer ücretleri ("fees" flag'i açıksa uygulanır)
+	reviewRules     []ReviewRule            // Tetiklenirse transfer tamamlanmaz, fraud incelemesine alınır
+	statusHooks     *StatusHookRegistry     // Commit edilen status geçişlerinde çalışan yan etkiler (nil = kapalı)
+
+	idempotencyRepo   interfaces.IdempotencyRepositoryInterface // Idempotency-Key kayıtları (nil = header yok sayılır)
+	idempotencyConfig *IdempotencyConfig
+
+	bulkStatusMaxIDs int // Admin toplu status güncellemesinde istek başına en fazla ID (0 = varsayılan)
+
+	transferTimes transferIntervalTracker // Ardışık transferler arası minimum süre için son transfer zamanları
 }
 
 // NewTransactionService, arayüzleri kabul eder ve *pointer döner
 // Bu, hem 'lock' uyarısını engeller hem de main.go'daki hatayı çözer.
 func NewTransactionService(transactionRepo interfaces.TransactionRepositoryInterface,
+	userRepo interfaces.UserRepositoryInterface,
 	balanceService interfaces.BalanceServiceInterface, // Bu da arayüz
 	database *sql.DB) *TransactionService {
 	return &TransactionService{
 		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
 		balanceService:  balanceService,
 		database:        database,
+		retryConfig:     db.DefaultRetryConfig(),
+		limitConfig:     DefaultTransactionLimitConfig(),
+		feeConfig:       DefaultFeeConfig(),
 	}
 }
 
-// ValidateTransactionType transaction type'ını doğrular
-func (s *TransactionService) ValidateTransactionType(txType string) error {
-	validTypes := map[string]bool{
-		"credit":   true,
-		"debit":    true,
-		"transfer": true,
-	}
-
-	if !validTypes[txType] {
-		return fmt.Errorf("geçersiz transaction tipi: %s. Geçerli tipler: credit, debit, transfer", txType)
+// SetRetryConfig deadlock/serialization tekrar ayarlarını değiştirir (nil ise tekrar yapılmaz)
+func (s *TransactionService) SetRetryConfig(config *db.RetryConfig) {
+	if config == nil {
+		config = &db.RetryConfig{}
 	}
+	s.retryConfig = config
+}
 
-	return nil
+// ValidateTransactionType transaction type'ını doğrular
+func (s *TransactionService) ValidateTransactionType(txType string) error {
+	return models.ValidateTransactionType(txType)
 }
 
 // ValidateAmount para miktarını doğrular
@@ -49,23 +105,115 @@ func (s *TransactionService) ValidateAmount(amount float64) error {
 		return fmt.Errorf("miktar sıfırdan büyük olmalıdır")
 	}
 
-	if amount > 1000000 { // maksimum limit
-		return fmt.Errorf("maksimum transfer limiti: 1,000,000 TL")
+	return s.checkAmountLimits(models.TypeTransfer, amount)
+}
+
+// Transfer kullanıcılar arası para transferi yapar (context'te Idempotency-Key varsa tekrar eden istek ilk sonucu alır)
+func (s *TransactionService) Transfer(ctx context.Context, fromUserID int, req *models.TransferRequest) (*models.Transaction, error) {
+	transaction, _, err := s.runIdempotent(ctx, fromUserID, IdempotencyEndpointTransfer, req, func(ctx context.Context) (*models.Transaction, float64, error) {
+		transaction, err := s.transfer(ctx, fromUserID, req)
+		return transaction, 0, err
+	})
+	return transaction, err
+}
+
+// transfer kullanıcılar arası para transferi yapar - STATE MANAGEMENT EKLENDİ
+func (s *TransactionService) transfer(ctx context.Context, fromUserID int, req *models.TransferRequest) (*models.Transaction, error) {
+	transaction, err := s.prepareTransfer(fromUserID, req)
+	if err != nil {
+		return nil, err
+	}
+	transaction.Channel = requestChannel(ctx)
+
+	// Yeni hesaplar kayıttan sonraki bekleme süresinde transfer yapamaz
+	if err := s.checkNewAccountCooldown(fromUserID); err != nil {
+		return nil, err
 	}
 
-	return nil
+	// Günlük transaction sayısı ve giden tutar limitleri (role bazlı, bakiye kilitliyken kontrol edilir)
+	limits, err := s.dailyLimitsFor(fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Aynı alıcıya açıklamalı transfer limiti (memo spam önleme)
+	if hasDescription(req.Description) {
+		if err := s.checkMemoTransferRate(fromUserID, req.ToUserID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ardışık transferler arası minimum süre (otomatik boşaltma girişimlerini yavaşlatır)
+	releaseSlot, err := s.reserveTransferSlot(fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fraud inceleme kuralları: tetiklenirse bakiye hareket etmez, transfer pending_review olarak bekletilir
+	reason, err := s.reviewReason(fromUserID, req)
+	if err != nil {
+		releaseSlot()
+		return nil, err
+	}
+	if reason != "" {
+		if err := s.holdTransferForReview(ctx, fromUserID, req, transaction, reason, limits); err != nil {
+			releaseSlot()
+			return nil, err
+		}
+		s.fireStatusHooks(ctx, models.StatusPending, transaction, nil)
+		return transaction, nil
+	}
+
+	var result *models.Transaction
+	startedAt := time.Now()
+
+	// Database transaction ile rollback mechanism (deadlock'ta tekrar denenir)
+	err = db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		// Tekrar denemede önceki denemenin status'u taşınmasın
+		transaction.Status = models.StatusPending
+
+		if err := executeTransfer(db.NewTransactionRepository(tx), fromUserID, req, transaction, limits); err != nil {
+			return err
+		}
+		if err := s.completeIdempotency(ctx, tx, transaction.ID, 0); err != nil {
+			return err
+		}
+
+		result = transaction
+		return nil // SUCCESS - transaction commit edilecek
+	})
+
+	logTransactionCreated(ctx, transaction, startedAt, err)
+	s.fireStatusHooks(ctx, models.StatusPending, transaction, err)
+
+	if err != nil {
+		releaseSlot()
+		return nil, err
+	}
+
+	return result, nil
 }
 
-// Transfer kullanıcılar arası para transferi yapar - STATE MANAGEMENT EKLENDİ
-func (s *TransactionService) Transfer(fromUserID int, req *models.TransferRequest) (*models.Transaction, error) {
+// prepareTransfer transfer isteğini doğrular ve pending transaction modelini oluşturur
+func (s *TransactionService) prepareTransfer(fromUserID int, req *models.TransferRequest) (*models.Transaction, error) {
 	//  Request validation
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 
-	// Aynı kullanıcıya transfer kontrolü
-	if fromUserID == req.ToUserID {
-		return nil, fmt.Errorf("kendinize para gönderemezsiniz")
+	// İşlem tipine göre ayarlanmış tutar aralığı
+	if err := s.checkAmountLimits(models.TypeTransfer, req.Amount); err != nil {
+		return nil, err
+	}
+
+	// Eşik üzerindeki transferlerde açıklama zorunlu
+	if err := req.ValidateDescription(s.limitConfig.TransferDescriptionThreshold()); err != nil {
+		return nil, err
+	}
+
+	// Aynı kullanıcıya transfer kontrolü (tüm giriş noktaları alıcı çözümlendikten sonra buradan geçer)
+	if err := checkSelfTransfer(fromUserID, req.ToUserID); err != nil {
+		return nil, err
 	}
 
 	//  Factory method ile transaction oluştur
@@ -76,121 +224,281 @@ func (s *TransactionService) Transfer(fromUserID int, req *models.TransferReques
 		return nil, fmt.Errorf("transaction validation hatası: %w", err)
 	}
 
-	var result *models.Transaction
+	return transaction, nil
+}
 
-	// Database transaction ile rollback mechanism
-	err := db.WithTransaction(s.database, func(tx *sql.Tx) error {
-		txRepo := db.NewTransactionRepository(tx)
+// requestChannel context'teki istek kanalını transaction'a yazılacak biçimde döner (bilinmiyorsa boş)
+func requestChannel(ctx context.Context) string {
+	return models.NormalizeChannel(utils.ChannelFromContext(ctx))
+}
 
-		// 1. Gönderen kullanıcının bakiyesini kontrol et ve lock et
-		var fromBalance float64
-		err := txRepo.QueryRow(`
-			SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE
-		`, fromUserID).Scan(&fromBalance)
+// checkSelfTransfer çözümlenmiş alıcının gönderenin kendisi olmadığını kontrol eder
+func checkSelfTransfer(fromUserID, toUserID int) error {
+	if fromUserID == toUserID {
+		return ErrSelfTransfer
+	}
+	return nil
+}
 
-		if err == sql.ErrNoRows {
-			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("gönderen kullanıcının bakiyesi bulunamadı")
+// executeTransfer transfer adımlarını verilen DB transaction'ı içinde uygular (bakiye lock, kayıt, bakiye güncelleme).
+// Gönderenin günlük sayı ve giden tutar limitleri (sıfır olmayanlar) kilit altında kontrol edilir.
+// Başarılı olursa transaction modelinin ID/CreatedAt alanları doldurulur; commit/rollback çağırana aittir.
+func executeTransfer(txRepo *db.TransactionRepository, fromUserID int, req *models.TransferRequest, transaction *models.Transaction, limits dailyLimits) error {
+	// 1-3. Bakiyeleri lock et ve yeterlilik kontrolü yap
+	fromBalance, toBalance, err := lockTransferBalances(txRepo, fromUserID, req.ToUserID, req.Amount, transaction, true)
+	if err != nil {
+		return err
+	}
+
+	// Günlük sayı ve giden tutar limitleri (aynı DB transaction'ında önceden yapılan transferler de sayılır)
+	if err := checkDailyCount(txRepo, fromUserID, limits.count, transaction); err != nil {
+		return err
+	}
+	if err := checkDailyOutbound(txRepo, fromUserID, req.Amount, limits.amount, transaction); err != nil {
+		return err
+	}
+
+	// 4. Transaction kaydını oluştur (PENDING status ile)
+	var transactionID int
+	var createdAt sql.NullTime
+	err = txRepo.QueryRow(`
+		INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description, channel) 
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING id, created_at
+	`, fromUserID, req.ToUserID, req.Amount, transaction.Type, transaction.Status, req.Description, transaction.Channel).Scan(&transactionID, &createdAt)
+
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("transaction kaydı oluşturulamadı: %w", err)
+	}
+
+	// 5. Bakiyeleri güncelle ve completed olarak işaretle
+	if err := applyTransfer(txRepo, fromUserID, req.ToUserID, req.Amount, fromBalance, toBalance, transactionID, transaction); err != nil {
+		return err
+	}
+
+	// 6. Model alanlarını doldur
+	transaction.ID = transactionID
+	transaction.CreatedAt = createdAt.Time
+
+	return nil
+}
+
+// lockTransferBalances gönderen ve alıcı bakiyelerini FOR UPDATE ile kilitler, gönderenin bakiyesinin yettiğini kontrol eder.
+// Kilitler transfer yönünden bağımsız olarak her zaman küçük user_id'den başlanarak alınır; böylece karşılıklı
+// transferler (A→B ve B→A) birbirinin kilidini ters sırada bekleyip deadlock'a girmez.
+// enforceMinimum true ise gönderenin kendi belirlediği minimum bakiye de kontrol edilir (admin işlemlerinde uygulanmaz).
+// Alıcının bakiye kaydı yoksa oluşturulur. Hata durumunda transaction failed olarak işaretlenir.
+func lockTransferBalances(txRepo *db.TransactionRepository, fromUserID, toUserID int, amount float64, transaction *models.Transaction, enforceMinimum bool) (float64, float64, error) {
+	var fromBalance, toBalance float64
+	var err error
+
+	if fromUserID < toUserID {
+		if fromBalance, err = lockSenderBalance(txRepo, fromUserID, amount, transaction, enforceMinimum); err != nil {
+			return 0, 0, err
 		}
-		if err != nil {
-			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("gönderen bakiye sorgusu hatası: %w", err)
+		if toBalance, err = lockReceiverBalance(txRepo, toUserID, transaction); err != nil {
+			return 0, 0, err
 		}
-
-		// 2. Yeterli bakiye kontrolü
-		if fromBalance < req.Amount {
-			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("yetersiz bakiye. Mevcut bakiye: %.2f TL", fromBalance)
+	} else {
+		if toBalance, err = lockReceiverBalance(txRepo, toUserID, transaction); err != nil {
+			return 0, 0, err
 		}
+		if fromBalance, err = lockSenderBalance(txRepo, fromUserID, amount, transaction, enforceMinimum); err != nil {
+			return 0, 0, err
+		}
+	}
 
-		// 3. Alan kullanıcının bakiyesini al ve lock et
-		var toBalance float64
-		err = txRepo.QueryRow(`
-			SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE
-		`, req.ToUserID).Scan(&toBalance)
+	return fromBalance, toBalance, nil
+}
 
-		if err == sql.ErrNoRows {
-			// Alan kullanıcının bakiyesi yoksa oluştur
-			_, err = txRepo.Exec(`
-				INSERT INTO balances (user_id, amount) VALUES ($1, 0.00)
-			`, req.ToUserID)
-			if err != nil {
-				transaction.SetStatus(models.StatusFailed)
-				return fmt.Errorf("alan kullanıcı bakiyesi oluşturulamadı: %w", err)
-			}
-			toBalance = 0.00
-		} else if err != nil {
-			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("alan kullanıcı bakiye sorgusu hatası: %w", err)
-		}
+// lockSenderBalance gönderenin bakiyesini kilitler ve aktif hold'lar düşüldükten sonra tutarın yettiğini kontrol eder
+func lockSenderBalance(txRepo *db.TransactionRepository, fromUserID int, amount float64, transaction *models.Transaction, enforceMinimum bool) (float64, error) {
+	var fromBalance float64
+	err := txRepo.QueryRow(`
+		SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE
+	`, fromUserID).Scan(&fromBalance)
 
-		// 4. Transaction kaydını oluştur (PENDING status ile)
-		var transactionID int
-		var createdAt sql.NullTime
-		err = txRepo.QueryRow(`
-			INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description) 
-			VALUES ($1, $2, $3, $4, $5, $6)
-			RETURNING id, created_at
-		`, fromUserID, req.ToUserID, req.Amount, transaction.Type, transaction.Status, req.Description).Scan(&transactionID, &createdAt)
+	if err == sql.ErrNoRows {
+		transaction.SetStatus(models.StatusFailed)
+		return 0, fmt.Errorf("gönderen kullanıcının bakiyesi bulunamadı")
+	}
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return 0, fmt.Errorf("gönderen bakiye sorgusu hatası: %w", err)
+	}
 
-		if err != nil {
-			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("transaction kaydı oluşturulamadı: %w", err)
+	// Yeterli bakiye kontrolü (aktif hold'lar kullanılamaz)
+	held, err := heldAmount(txRepo, fromUserID)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return 0, err
+	}
+	if fromBalance-held < amount {
+		transaction.SetStatus(models.StatusFailed)
+		return 0, newInsufficientBalanceError(fromBalance-held, amount)
+	}
+	if enforceMinimum {
+		if err := checkMinimumBalance(txRepo, fromUserID, fromBalance-held, amount, transaction); err != nil {
+			return 0, err
 		}
+	}
 
-		// 5. Bakiyeleri güncelle
-		newFromBalance := fromBalance - req.Amount
-		newToBalance := toBalance + req.Amount
+	return fromBalance, nil
+}
 
-		// Gönderen bakiyesini güncelle
-		_, err = txRepo.Exec(`
-			UPDATE balances SET amount = $1 WHERE user_id = $2
-		`, newFromBalance, fromUserID)
-		if err != nil {
-			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("gönderen bakiye güncellenemedi: %w", err)
-		}
+// lockReceiverBalance alıcının bakiyesini kilitler; bakiye kaydı yoksa 0 ile oluşturur
+func lockReceiverBalance(txRepo *db.TransactionRepository, toUserID int, transaction *models.Transaction) (float64, error) {
+	var toBalance float64
+	err := txRepo.QueryRow(`
+		SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE
+	`, toUserID).Scan(&toBalance)
 
-		// Alan bakiyesini güncelle
+	if err == sql.ErrNoRows {
 		_, err = txRepo.Exec(`
-			UPDATE balances SET amount = $1 WHERE user_id = $2
-		`, newToBalance, req.ToUserID)
+			INSERT INTO balances (user_id, amount) VALUES ($1, 0.00)
+		`, toUserID)
 		if err != nil {
 			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("alan bakiye güncellenemedi: %w", err)
+			return 0, fmt.Errorf("alan kullanıcı bakiyesi oluşturulamadı: %w", err)
 		}
+		return 0.00, nil
+	}
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return 0, fmt.Errorf("alan kullanıcı bakiye sorgusu hatası: %w", err)
+	}
 
-		//  Transaction'ı completed olarak işaretle
-		if err := transaction.SetStatus(models.StatusCompleted); err != nil {
-			return fmt.Errorf("transaction status güncellenemedi: %w", err)
-		}
+	return toBalance, nil
+}
 
-		// Status'u database'de güncelle
-		_, err = txRepo.Exec(`
-			UPDATE transactions SET status = $1 WHERE id = $2
-		`, transaction.Status, transactionID)
-		if err != nil {
-			return fmt.Errorf("transaction status database'de güncellenemedi: %w", err)
-		}
+// applyTransfer kilitli bakiyeleri günceller ve kayıtlı transaction'ı completed olarak işaretler
+func applyTransfer(txRepo *db.TransactionRepository, fromUserID, toUserID int, amount, fromBalance, toBalance float64, transactionID int, transaction *models.Transaction) error {
+	newFromBalance := fromBalance - amount
+	newToBalance := toBalance + amount
 
-		// 6. Result struct'ını oluştur
-		transaction.ID = transactionID
-		transaction.CreatedAt = createdAt.Time
-		result = transaction
+	// Gönderen bakiyesini güncelle
+	_, err := txRepo.Exec(`
+		UPDATE balances SET amount = $1 WHERE user_id = $2
+	`, newFromBalance, fromUserID)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("gönderen bakiye güncellenemedi: %w", err)
+	}
+	if err := recordBalanceChange(txRepo, fromUserID, fromBalance, newFromBalance, models.BalanceReasonTransferOut, transactionID); err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return err
+	}
 
-		return nil // SUCCESS - transaction commit edilecek
-	})
+	// Alan bakiyesini güncelle
+	_, err = txRepo.Exec(`
+		UPDATE balances SET amount = $1 WHERE user_id = $2
+	`, newToBalance, toUserID)
+	if err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return fmt.Errorf("alan bakiye güncellenemedi: %w", err)
+	}
+	if err := recordBalanceChange(txRepo, toUserID, toBalance, newToBalance, models.BalanceReasonTransferIn, transactionID); err != nil {
+		transaction.SetStatus(models.StatusFailed)
+		return err
+	}
+
+	//  Transaction'ı completed olarak işaretle
+	if err := transaction.SetStatus(models.StatusCompleted); err != nil {
+		return fmt.Errorf("transaction status güncellenemedi: %w", err)
+	}
+
+	// Status'u database'de güncelle
+	_, err = txRepo.Exec(`
+		UPDATE transactions SET status = $1 WHERE id = $2
+	`, transaction.Status, transactionID)
+	if err != nil {
+		return fmt.Errorf("transaction status database'de güncellenemedi: %w", err)
+	}
 
+	return nil
+}
+
+// adjustBalance bakiyeyi tek bir UPDATE ile delta kadar değiştirir ve önceki/yeni bakiyeyi döner.
+// Bakiye Go'da okunup yazılmadığı için satırı önceden FOR UPDATE ile kilitlemek gerekmez; bakiyeyi negatife
+// düşürecek değişiklik uygulanmaz (ErrInsufficientBalance).
+func adjustBalance(txRepo *db.TransactionRepository, userID int, delta float64) (float64, float64, error) {
+	var previous, next float64
+	err := txRepo.QueryRow(`
+		UPDATE balances SET amount = amount + $1
+		WHERE user_id = $2 AND amount + $1 >= 0
+		RETURNING amount - $1, amount
+	`, delta, userID).Scan(&previous, &next)
+	if err == sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("bakiye güncellenemedi: %w", ErrInsufficientBalance)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("bakiye güncellenemedi: %w", err)
+	}
+	return previous, next, nil
+}
+
+// recordBalanceChange bakiye değişikliğini aynı DB transaction'ı içinde balance_history'ye yazar
+func recordBalanceChange(txRepo *db.TransactionRepository, userID int, previous, next float64, reason string, transactionID int) error {
+	_, err := txRepo.Exec(`
+		INSERT INTO balance_history (user_id, previous_amount, new_amount, change_amount, reason, transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, userID, previous, next, next-previous, reason, transactionID)
+	if err != nil {
+		return fmt.Errorf("bakiye geçmişi yazılamadı: %w", err)
+	}
+	return nil
+}
+
+// ResolveAccountNumber hesap numarasını kullanıcı ID'sine çevirir (checksum lookup'tan önce doğrulanır)
+func (s *TransactionService) ResolveAccountNumber(accountNumber string) (int, error) {
+	if err := models.ValidateAccountNumber(accountNumber); err != nil {
+		return 0, err
+	}
+
+	user, err := s.userRepo.GetByAccountNumber(models.NormalizeAccountNumber(accountNumber))
+	if err != nil {
+		return 0, err
+	}
+
+	return user.ID, nil
+}
+
+// ResolveEmail alıcının email adresini kullanıcı ID'sine çevirir
+func (s *TransactionService) ResolveEmail(email string) (int, error) {
+	user, err := s.userRepo.GetByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return 0, err
+	}
+
+	return user.ID, nil
+}
+
+// GetUserTransactions kullanıcının transaction geçmişini getirir (sort: asc|desc, boş ise varsayılan)
+func (s *TransactionService) GetUserTransactions(userID int, limit, offset int, sort string) ([]*models.Transaction, error) {
+	sort, err := models.ParseSortOrder(sort)
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	transactions, err := s.transactionRepo.GetByUserID(userID, limit, offset, sort)
+	if err != nil {
+		return nil, fmt.Errorf("transaction geçmişi alınamadı: %w", err)
+	}
+
+	return transactions, nil
 }
 
-// GetUserTransactions kullanıcının transaction geçmişini getirir
-func (s *TransactionService) GetUserTransactions(userID int, limit, offset int) ([]*models.Transaction, error) {
-	transactions, err := s.transactionRepo.GetByUserID(userID, limit, offset)
+// GetUserTransactionsFiltered kullanıcının transaction geçmişini tip, status, tarih ve sıralama filtreleriyle getirir
+func (s *TransactionService) GetUserTransactionsFiltered(userID int, filter *models.TransactionHistoryFilter, limit, offset int) ([]*models.Transaction, error) {
+	if filter == nil {
+		filter = &models.TransactionHistoryFilter{}
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.GetByUserIDFiltered(userID, filter, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("transaction geçmişi alınamadı: %w", err)
 	}
@@ -198,11 +506,74 @@ func (s *TransactionService) GetUserTransactions(userID int, limit, offset int)
 	return transactions, nil
 }
 
+// GetUserTransactionsPage kullanıcının transaction geçmişini cursor ile sayfalar.
+// filter.Cursor nil ise ilk sayfa döner; sonraki sayfa varsa NextCursor doldurulur.
+func (s *TransactionService) GetUserTransactionsPage(userID int, filter *models.TransactionHistoryFilter, limit int) (*models.TransactionPage, error) {
+	if filter == nil {
+		filter = &models.TransactionHistoryFilter{}
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if filter.Sort == models.SortAmountAsc || filter.Sort == models.SortAmountDesc {
+		return nil, fmt.Errorf("cursor sayfalama sadece tarih sıralamasıyla (asc | desc) kullanılabilir")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit pozitif olmalıdır")
+	}
+
+	// Bir fazla satır okunur: varsa sonraki sayfa vardır
+	transactions, err := s.transactionRepo.GetByUserIDFiltered(userID, filter, limit+1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("transaction geçmişi alınamadı: %w", err)
+	}
+
+	page := &models.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = (&models.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID}).Encode()
+	}
+
+	return page, nil
+}
+
+// GetCounterparties kullanıcının en çok işlem yaptığı karşı tarafları döner (from/to sıfır = o uçta sınır yok)
+func (s *TransactionService) GetCounterparties(userID int, from, to time.Time, limit int) ([]*models.CounterpartySummary, error) {
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, fmt.Errorf("başlangıç tarihi bitiş tarihinden önce olmalıdır")
+	}
+	if limit <= 0 || limit > models.MaxCounterpartyLimit {
+		limit = models.DefaultCounterpartyLimit
+	}
+
+	summaries, err := s.transactionRepo.GetCounterpartySummary(userID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("karşı taraf özeti alınamadı: %w", err)
+	}
+
+	return summaries, nil
+}
+
 // Credit kullanıcının hesabına para yatırır - STATE MANAGEMENT EKLENDİ
-func (s *TransactionService) Credit(userID int, req *models.CreditRequest) (*models.Transaction, error) {
+// İşlem sonrası bakiye transaction içinde hesaplanan değerdir; ayrıca okunmasına gerek yoktur.
+// Context'te Idempotency-Key varsa tekrar eden istek ilk sonucu alır.
+func (s *TransactionService) Credit(ctx context.Context, userID int, req *models.CreditRequest) (*models.Transaction, float64, error) {
+	return s.runIdempotent(ctx, userID, IdempotencyEndpointCredit, req, func(ctx context.Context) (*models.Transaction, float64, error) {
+		return s.credit(ctx, userID, req)
+	})
+}
+
+// credit Idempotency-Key kontrolünden sonra işlemi gerçekleştirir
+func (s *TransactionService) credit(ctx context.Context, userID int, req *models.CreditRequest) (*models.Transaction, float64, error) {
 	//  Request validation
 	if err := req.Validate(); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	// İşlem tipine göre ayarlanmış tutar aralığı
+	if err := s.checkAmountLimits(models.TypeCredit, req.Amount); err != nil {
+		return nil, 0, err
 	}
 
 	// Default description
@@ -213,49 +584,58 @@ func (s *TransactionService) Credit(userID int, req *models.CreditRequest) (*mod
 
 	//  Factory method ile transaction oluştur
 	transaction := models.NewCreditTransaction(userID, req.Amount, description)
+	transaction.Channel = requestChannel(ctx)
 
 	//  Transaction validation
 	if err := transaction.Validate(); err != nil {
-		return nil, fmt.Errorf("transaction validation hatası: %w", err)
+		return nil, 0, fmt.Errorf("transaction validation hatası: %w", err)
+	}
+
+	// Günlük transaction sayısı limiti (role bazlı, bakiye kilitliyken kontrol edilir)
+	limits, err := s.dailyLimitsFor(userID)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	var result *models.Transaction
+	var newBalance float64 // Transaction içinde hesaplanan, işlem sonrası kesin bakiye
+	startedAt := time.Now()
 
-	// Database transaction ile rollback mechanism
-	err := db.WithTransaction(s.database, func(tx *sql.Tx) error {
+	// Database transaction ile rollback mechanism (deadlock'ta tekrar denenir)
+	err = db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		// Tekrar denemede önceki denemenin status'u taşınmasın
+		transaction.Status = models.StatusPending
 		txRepo := db.NewTransactionRepository(tx)
 
-		// 1. Kullanıcının mevcut bakiyesini al ve lock et
-		var currentBalance float64
-		err := txRepo.QueryRow(`
-			SELECT amount FROM balances WHERE user_id = $1 FOR UPDATE
-		`, userID).Scan(&currentBalance)
+		// 1. Bakiye kaydı yoksa oluştur (yatırma bakiyeyi azaltmadığı için FOR UPDATE kilidi gerekmez)
+		_, err := txRepo.Exec(`
+			INSERT INTO balances (user_id, amount) VALUES ($1, 0.00) ON CONFLICT (user_id) DO NOTHING
+		`, userID)
+		if err != nil {
+			//  Transaction status güncelle
+			transaction.SetStatus(models.StatusFailed)
+			return fmt.Errorf("bakiye oluşturulamadı: %w", err)
+		}
 
-		if err == sql.ErrNoRows {
-			// Bakiye yoksa oluştur
-			_, err = txRepo.Exec(`
-				INSERT INTO balances (user_id, amount) VALUES ($1, 0.00)
-			`, userID)
-			if err != nil {
-				//  Transaction status güncelle
+		// Sayı limiti varsa bakiye kilitlenir: aynı kullanıcının eşzamanlı işlemleri sayımı sırayla görür
+		if limits.count > 0 {
+			if _, err := txRepo.Exec(`SELECT 1 FROM balances WHERE user_id = $1 FOR UPDATE`, userID); err != nil {
 				transaction.SetStatus(models.StatusFailed)
-				return fmt.Errorf("bakiye oluşturulamadı: %w", err)
+				return fmt.Errorf("bakiye sorgusu hatası: %w", err)
+			}
+			if err := checkDailyCount(txRepo, userID, limits.count, transaction); err != nil {
+				return err
 			}
-			currentBalance = 0.00
-		} else if err != nil {
-			//  Transaction status güncelle
-			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("bakiye sorgusu hatası: %w", err)
 		}
 
 		// 2. Transaction kaydını oluştur (PENDING status ile)
 		var transactionID int
 		var createdAt sql.NullTime
 		err = txRepo.QueryRow(`
-			INSERT INTO transactions (to_user_id, from_user_id, amount, type, status, description) 
-			VALUES ($1, NULL, $2, $3, $4, $5)
+			INSERT INTO transactions (to_user_id, from_user_id, amount, type, status, description, channel) 
+			VALUES ($1, NULL, $2, $3, $4, $5, NULLIF($6, ''))
 			RETURNING id, created_at
-		`, userID, req.Amount, transaction.Type, transaction.Status, description).Scan(&transactionID, &createdAt)
+		`, userID, req.Amount, transaction.Type, transaction.Status, description, transaction.Channel).Scan(&transactionID, &createdAt)
 
 		if err != nil {
 			//  Transaction status güncelle
@@ -263,15 +643,17 @@ func (s *TransactionService) Credit(userID int, req *models.CreditRequest) (*mod
 			return fmt.Errorf("transaction kaydı oluşturulamadı: %w", err)
 		}
 
-		// 3. Bakiyeyi artır
-		newBalance := currentBalance + req.Amount
-		_, err = txRepo.Exec(`
-			UPDATE balances SET amount = $1 WHERE user_id = $2
-		`, newBalance, userID)
+		// 3. Bakiyeyi atomik olarak artır (eşzamanlı yatırmalar birbirinin üzerine yazmaz)
+		var previousBalance float64
+		previousBalance, newBalance, err = adjustBalance(txRepo, userID, req.Amount)
 		if err != nil {
 			//  Transaction status güncelle
 			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("bakiye güncellenemedi: %w", err)
+			return err
+		}
+		if err := recordBalanceChange(txRepo, userID, previousBalance, newBalance, models.BalanceReasonCredit, transactionID); err != nil {
+			transaction.SetStatus(models.StatusFailed)
+			return err
 		}
 
 		//  Transaction'ı completed olarak işaretle
@@ -286,6 +668,9 @@ func (s *TransactionService) Credit(userID int, req *models.CreditRequest) (*mod
 		if err != nil {
 			return fmt.Errorf("transaction status database'de güncellenemedi: %w", err)
 		}
+		if err := s.completeIdempotency(ctx, tx, transactionID, newBalance); err != nil {
+			return err
+		}
 
 		// 4. Result struct'ını oluştur
 		transaction.ID = transactionID
@@ -295,18 +680,35 @@ func (s *TransactionService) Credit(userID int, req *models.CreditRequest) (*mod
 		return nil // SUCCESS - transaction commit edilecek
 	})
 
+	logTransactionCreated(ctx, transaction, startedAt, err)
+	s.fireStatusHooks(ctx, models.StatusPending, transaction, err)
+
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return result, nil
+	return result, newBalance, nil
 }
 
 // Debit kullanıcının hesabından para çeker - STATE MANAGEMENT EKLENDİ
-func (s *TransactionService) Debit(userID int, req *models.DebitRequest) (*models.Transaction, error) {
+// İşlem sonrası bakiye transaction içinde hesaplanan değerdir; ayrıca okunmasına gerek yoktur.
+// Context'te Idempotency-Key varsa tekrar eden istek ilk sonucu alır.
+func (s *TransactionService) Debit(ctx context.Context, userID int, req *models.DebitRequest) (*models.Transaction, float64, error) {
+	return s.runIdempotent(ctx, userID, IdempotencyEndpointDebit, req, func(ctx context.Context) (*models.Transaction, float64, error) {
+		return s.debit(ctx, userID, req)
+	})
+}
+
+// debit Idempotency-Key kontrolünden sonra işlemi gerçekleştirir
+func (s *TransactionService) debit(ctx context.Context, userID int, req *models.DebitRequest) (*models.Transaction, float64, error) {
 	// Request validation
 	if err := req.Validate(); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	// İşlem tipine göre ayarlanmış tutar aralığı
+	if err := s.checkAmountLimits(models.TypeDebit, req.Amount); err != nil {
+		return nil, 0, err
 	}
 
 	// Default description
@@ -317,16 +719,27 @@ func (s *TransactionService) Debit(userID int, req *models.DebitRequest) (*model
 
 	//  Factory method ile transaction oluştur
 	transaction := models.NewDebitTransaction(userID, req.Amount, description)
+	transaction.Channel = requestChannel(ctx)
 
 	// Transaction validation
 	if err := transaction.Validate(); err != nil {
-		return nil, fmt.Errorf("transaction validation hatası: %w", err)
+		return nil, 0, fmt.Errorf("transaction validation hatası: %w", err)
+	}
+
+	// Günlük transaction sayısı ve giden tutar limitleri (role bazlı, bakiye kilitliyken kontrol edilir)
+	limits, err := s.dailyLimitsFor(userID)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	var result *models.Transaction
+	var newBalance float64 // Transaction içinde hesaplanan, işlem sonrası kesin bakiye
+	startedAt := time.Now()
 
-	// Database transaction ile rollback mechanism
-	err := db.WithTransaction(s.database, func(tx *sql.Tx) error {
+	// Database transaction ile rollback mechanism (deadlock'ta tekrar denenir)
+	err = db.WithTransactionRetry(s.database, s.retryConfig, func(tx *sql.Tx) error {
+		// Tekrar denemede önceki denemenin status'u taşınmasın
+		transaction.Status = models.StatusPending
 		txRepo := db.NewTransactionRepository(tx)
 
 		// 1. Kullanıcının mevcut bakiyesini al ve lock et
@@ -344,20 +757,36 @@ func (s *TransactionService) Debit(userID int, req *models.DebitRequest) (*model
 			return fmt.Errorf("bakiye sorgusu hatası: %w", err)
 		}
 
-		// 2. Yeterli bakiye kontrolü
-		if currentBalance < req.Amount {
+		// 2. Yeterli bakiye kontrolü (aktif hold'lar kullanılamaz)
+		held, err := heldAmount(txRepo, userID)
+		if err != nil {
 			transaction.SetStatus(models.StatusFailed)
-			return fmt.Errorf("yetersiz bakiye. Mevcut bakiye: %.2f TL", currentBalance)
+			return err
+		}
+		if currentBalance-held < req.Amount {
+			transaction.SetStatus(models.StatusFailed)
+			return newInsufficientBalanceError(currentBalance-held, req.Amount)
+		}
+		if err := checkMinimumBalance(txRepo, userID, currentBalance-held, req.Amount, transaction); err != nil {
+			return err
+		}
+
+		// Günlük sayı ve giden tutar limitleri
+		if err := checkDailyCount(txRepo, userID, limits.count, transaction); err != nil {
+			return err
+		}
+		if err := checkDailyOutbound(txRepo, userID, req.Amount, limits.amount, transaction); err != nil {
+			return err
 		}
 
 		// 3. Transaction kaydını oluştur (PENDING status ile)
 		var transactionID int
 		var createdAt sql.NullTime
 		err = txRepo.QueryRow(`
-			INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description) 
-			VALUES ($1, NULL, $2, $3, $4, $5)
+			INSERT INTO transactions (from_user_id, to_user_id, amount, type, status, description, channel) 
+			VALUES ($1, NULL, $2, $3, $4, $5, NULLIF($6, ''))
 			RETURNING id, created_at
-		`, userID, req.Amount, transaction.Type, transaction.Status, description).Scan(&transactionID, &createdAt)
+		`, userID, req.Amount, transaction.Type, transaction.Status, description, transaction.Channel).Scan(&transactionID, &createdAt)
 
 		if err != nil {
 			transaction.SetStatus(models.StatusFailed)
@@ -365,7 +794,7 @@ func (s *TransactionService) Debit(userID int, req *models.DebitRequest) (*model
 		}
 
 		// 4. Bakiyeyi azalt
-		newBalance := currentBalance - req.Amount
+		newBalance = currentBalance - req.Amount
 		_, err = txRepo.Exec(`
 			UPDATE balances SET amount = $1 WHERE user_id = $2
 		`, newBalance, userID)
@@ -373,6 +802,10 @@ func (s *TransactionService) Debit(userID int, req *models.DebitRequest) (*model
 			transaction.SetStatus(models.StatusFailed)
 			return fmt.Errorf("bakiye güncellenemedi: %w", err)
 		}
+		if err := recordBalanceChange(txRepo, userID, currentBalance, newBalance, models.BalanceReasonDebit, transactionID); err != nil {
+			transaction.SetStatus(models.StatusFailed)
+			return err
+		}
 
 		//  Transaction'ı completed olarak işaretle
 		if err := transaction.SetStatus(models.StatusCompleted); err != nil {
@@ -386,6 +819,9 @@ func (s *TransactionService) Debit(userID int, req *models.DebitRequest) (*model
 		if err != nil {
 			return fmt.Errorf("transaction status database'de güncellenemedi: %w", err)
 		}
+		if err := s.completeIdempotency(ctx, tx, transactionID, newBalance); err != nil {
+			return err
+		}
 
 		// 5. Result struct'ını oluştur
 		transaction.ID = transactionID
@@ -395,11 +831,14 @@ func (s *TransactionService) Debit(userID int, req *models.DebitRequest) (*model
 		return nil // SUCCESS - transaction commit edilecek
 	})
 
+	logTransactionCreated(ctx, transaction, startedAt, err)
+	s.fireStatusHooks(ctx, models.StatusPending, transaction, err)
+
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return result, nil
+	return result, newBalance, nil
 }
 
 // GetTransactionByID ID ile transaction getirir
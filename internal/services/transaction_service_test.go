@@ -1,14 +1,20 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/onerilhan/go-payment-api/internal/features"
 	"github.com/onerilhan/go-payment-api/internal/interfaces"
 	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
 )
 
 // MockTransactionRepository, TransactionRepositoryInterface için sahte (mock) bir yapıdır.
@@ -29,14 +35,62 @@ func (m *MockTransactionRepository) GetByID(id int) (*models.Transaction, error)
 	}
 	return args.Get(0).(*models.Transaction), args.Error(1)
 }
-func (m *MockTransactionRepository) GetByUserID(userID, limit, offset int) ([]*models.Transaction, error) {
-	args := m.Called(userID, limit, offset)
+func (m *MockTransactionRepository) GetByUserID(userID, limit, offset int, sort string) ([]*models.Transaction, error) {
+	args := m.Called(userID, limit, offset, sort)
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+func (m *MockTransactionRepository) GetByUserIDFiltered(userID int, filter *models.TransactionHistoryFilter, limit, offset int) ([]*models.Transaction, error) {
+	args := m.Called(userID, filter, limit, offset)
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 func (m *MockTransactionRepository) GetByStatus(status string, limit, offset int) ([]*models.Transaction, error) {
 	args := m.Called(status, limit, offset)
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
+func (m *MockTransactionRepository) StreamByDateRange(from, to time.Time, fn func(*models.Transaction) error) error {
+	args := m.Called(from, to, fn)
+	return args.Error(0)
+}
+func (m *MockTransactionRepository) CountUserTransactionsSince(userID int, since time.Time) (int, error) {
+	args := m.Called(userID, since)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockTransactionRepository) GetUserUsageSince(userID int, since time.Time) (*models.TransactionUsage, error) {
+	args := m.Called(userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TransactionUsage), args.Error(1)
+}
+func (m *MockTransactionRepository) CountTransfersWithDescriptionSince(fromUserID, toUserID int, since time.Time) (int, error) {
+	args := m.Called(fromUserID, toUserID, since)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockTransactionRepository) GetTransferAmountHistorySince(userID int, since time.Time) (*models.TransferAmountHistory, error) {
+	args := m.Called(userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TransferAmountHistory), args.Error(1)
+}
+func (m *MockTransactionRepository) CountCompletedTransfersBetween(fromUserID, toUserID int) (int, error) {
+	args := m.Called(fromUserID, toUserID)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockTransactionRepository) GetTransactionReviews(status string, limit, offset int) ([]*models.TransactionReview, error) {
+	args := m.Called(status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TransactionReview), args.Error(1)
+}
+func (m *MockTransactionRepository) GetCounterpartySummary(userID int, from, to time.Time, limit int) ([]*models.CounterpartySummary, error) {
+	args := m.Called(userID, from, to, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CounterpartySummary), args.Error(1)
+}
 func (m *MockTransactionRepository) UpdateStatus(id int, status string) error {
 	args := m.Called(id, status)
 	return args.Error(0)
@@ -63,12 +117,30 @@ func (m *MockBalanceService) GetBalance(userID int) (*models.Balance, error) {
 	}
 	return args.Get(0).(*models.Balance), args.Error(1)
 }
+func (m *MockBalanceService) GetAvailableBalance(userID int) (*models.AvailableBalance, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AvailableBalance), args.Error(1)
+}
 func (m *MockBalanceService) UpdateBalance(userID int, amount float64) error {
 	args := m.Called(userID, amount)
 	return args.Error(0)
 }
-func (m *MockBalanceService) GetBalanceHistory(userID, limit, offset int) ([]*models.BalanceHistory, error) {
-	args := m.Called(userID, limit, offset)
+func (m *MockBalanceService) AdjustBalance(userID int, delta float64) (float64, error) {
+	args := m.Called(userID, delta)
+	return args.Get(0).(float64), args.Error(1)
+}
+func (m *MockBalanceService) AdjustBalanceWithReference(userID int, delta float64, reference string) (*models.BalanceHistory, bool, error) {
+	args := m.Called(userID, delta, reference)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.BalanceHistory), args.Bool(1), args.Error(2)
+}
+func (m *MockBalanceService) GetBalanceHistory(userID, limit, offset int, sort string) ([]*models.BalanceHistory, error) {
+	args := m.Called(userID, limit, offset, sort)
 	return args.Get(0).([]*models.BalanceHistory), args.Error(1)
 }
 func (m *MockBalanceService) GetBalanceAtTime(userID int, targetTime string) (*models.BalanceAtTime, error) {
@@ -88,7 +160,7 @@ func TestTransactionService_GetTransactionByID_Success(t *testing.T) {
 	// Arrange
 	mockTxRepo := new(MockTransactionRepository)
 	mockBalanceService := new(MockBalanceService)
-	transactionService := NewTransactionService(mockTxRepo, mockBalanceService, nil)
+	transactionService := NewTransactionService(mockTxRepo, nil, mockBalanceService, nil)
 
 	txID := 1
 	fromUserID := 10
@@ -114,3 +186,990 @@ func TestTransactionService_GetTransactionByID_Success(t *testing.T) {
 	assert.Equal(t, expectedTransaction, result)
 	mockTxRepo.AssertExpectations(t)
 }
+
+// TestTransactionService_Credit_DailyCountLimitReached, günlük işlem sayısı limitine ulaşan kullanıcının reddedildiğini ve
+// sayımın bakiye kilidi alındıktan sonra aynı DB transaction'ında yapıldığını test eder.
+func TestTransactionService_Credit_DailyCountLimitReached(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockTxRepo := new(MockTransactionRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockBalanceService := new(MockBalanceService)
+	transactionService := NewTransactionService(mockTxRepo, mockUserRepo, mockBalanceService, database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		DailyCountByRole: map[string]int{"user": 5},
+	})
+
+	userID := 10
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID, Role: "user"}, nil)
+
+	dbMock.ExpectBegin()
+	expectEnsureBalance(dbMock, userID)
+	dbMock.ExpectExec("SELECT 1 FROM balances WHERE user_id = \\$1 FOR UPDATE").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectDailyCount(dbMock, userID, 5)
+	dbMock.ExpectRollback()
+
+	// Act
+	result, _, err := transactionService.Credit(context.Background(), userID, &models.CreditRequest{Amount: 100})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "günlük transaction sayısı limitine ulaşıldı: bugün 5/5 işlem yapıldı")
+	mockUserRepo.AssertExpectations(t)
+	mockTxRepo.AssertNotCalled(t, "CountUserTransactionsSince", userID, mock.Anything)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Credit_ReturnsInTransactionBalance, dönen bakiyenin transaction içinde hesaplanan değer olduğunu test eder.
+func TestTransactionService_Credit_ReturnsInTransactionBalance(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	dbMock.ExpectBegin()
+	expectEnsureBalance(dbMock, userID)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	expectAdjustBalance(dbMock, userID, 50.0, 100.0)
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	// Commit sonrası eşzamanlı bir işlem bakiyeyi değiştirmiş olsun; ayrı okuma bu değeri döndürürdü
+	mockBalanceService := new(MockBalanceService)
+	mockBalanceService.On("GetBalance", userID).Return(&models.Balance{UserID: userID, Amount: 999}, nil).Maybe()
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, mockBalanceService, database)
+
+	// Act
+	result, newBalance, err := transactionService.Credit(context.Background(), userID, &models.CreditRequest{Amount: 50})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, 150.0, newBalance)
+	mockBalanceService.AssertNotCalled(t, "GetBalance", userID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Credit_RecordsBalanceHistory, yatırımın aynı DB transaction'ında doğru değişimle tek bir
+// balance_history satırı yazdığını test eder.
+func TestTransactionService_Credit_RecordsBalanceHistory(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	dbMock.ExpectBegin()
+	expectEnsureBalance(dbMock, userID)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(42, time.Now()))
+	expectAdjustBalance(dbMock, userID, 25.5, 100.0)
+	dbMock.ExpectExec("INSERT INTO balance_history").
+		WithArgs(userID, 100.0, 125.5, 25.5, models.BalanceReasonCredit, 42).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+
+	// Act
+	result, _, err := transactionService.Credit(context.Background(), userID, &models.CreditRequest{Amount: 25.5})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result.ID)
+	assert.NoError(t, dbMock.ExpectationsWereMet()) // Fazladan history satırı sıradaki beklentiyle eşleşmez
+}
+
+// TestTransactionService_Debit_ReturnsInTransactionBalance, çekim sonrası dönen bakiyenin transaction içinde hesaplandığını test eder.
+func TestTransactionService_Debit_ReturnsInTransactionBalance(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(100.0))
+	expectMinimumBalance(dbMock, userID, 0)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(70.0, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	mockBalanceService := new(MockBalanceService)
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, mockBalanceService, database)
+
+	// Act
+	result, newBalance, err := transactionService.Debit(context.Background(), userID, &models.DebitRequest{Amount: 30})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, 70.0, newBalance)
+	mockBalanceService.AssertNotCalled(t, "GetBalance", userID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_CheckDailyCountLimit_BelowCapAndUnlimitedRole, limit altındaki ve limitsiz role'deki kullanıcının geçtiğini test eder.
+func TestTransactionService_CheckDailyCountLimit_BelowCapAndUnlimitedRole(t *testing.T) {
+	// Arrange
+	mockTxRepo := new(MockTransactionRepository)
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(mockTxRepo, mockUserRepo, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		DailyCountByRole: map[string]int{"user": 5},
+	})
+
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+	mockUserRepo.On("GetByID", 1).Return(&models.User{ID: 1, Role: "admin"}, nil)
+	mockTxRepo.On("CountUserTransactionsSince", 10, mock.AnythingOfType("time.Time")).Return(4, nil)
+
+	// Act
+	userErr := transactionService.checkDailyCountLimitFor(10, 1)
+	adminErr := transactionService.checkDailyCountLimitFor(1, 1)
+
+	// Assert
+	assert.NoError(t, userErr)
+	assert.NoError(t, adminErr)
+	mockTxRepo.AssertNotCalled(t, "CountUserTransactionsSince", 1, mock.Anything)
+}
+
+// TestTransactionService_CheckAmountLimits_ConfiguredBoundaries, tip bazlı min/max sınırlarının uç değerlerde uygulandığını
+// ve hata mesajlarının ayarlanan değerleri gösterdiğini test eder.
+func TestTransactionService_CheckAmountLimits_ConfiguredBoundaries(t *testing.T) {
+	// Arrange
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		MinAmountByType: map[string]float64{models.TypeTransfer: 1},
+		MaxAmountByType: map[string]float64{
+			models.TypeTransfer: 5000,
+			models.TypeCredit:   20000,
+			models.TypeDebit:    2500.5,
+		},
+	})
+
+	// Act & Assert
+	assert.NoError(t, transactionService.checkAmountLimits(models.TypeTransfer, 1))
+	assert.NoError(t, transactionService.checkAmountLimits(models.TypeTransfer, 5000))
+	assert.EqualError(t, transactionService.checkAmountLimits(models.TypeTransfer, 0.99), "minimum transfer tutarı: 1 TL")
+	assert.EqualError(t, transactionService.checkAmountLimits(models.TypeTransfer, 5000.01), "maksimum transfer limiti: 5,000 TL")
+
+	assert.NoError(t, transactionService.checkAmountLimits(models.TypeCredit, 0.01))
+	assert.NoError(t, transactionService.checkAmountLimits(models.TypeCredit, 20000))
+	assert.EqualError(t, transactionService.checkAmountLimits(models.TypeCredit, 20000.01), "maksimum yatırma limiti: 20,000 TL")
+
+	assert.NoError(t, transactionService.checkAmountLimits(models.TypeDebit, 2500.5))
+	assert.EqualError(t, transactionService.checkAmountLimits(models.TypeDebit, 2500.51), "maksimum çekme limiti: 2,500.50 TL")
+
+	assert.EqualError(t, transactionService.ValidateAmount(0.5), "minimum transfer tutarı: 1 TL")
+	assert.EqualError(t, transactionService.ValidateAmount(0), "miktar sıfırdan büyük olmalıdır")
+}
+
+// TestTransactionService_AmountLimits_DefaultsAndRequestPaths, varsayılan 1,000,000 TL tavanının ve ayarlanan sınırların
+// transfer, yatırma ve çekme isteklerinde bakiyeye dokunmadan uygulandığını test eder.
+func TestTransactionService_AmountLimits_DefaultsAndRequestPaths(t *testing.T) {
+	// Arrange
+	defaults := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	configured := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	configured.SetLimitConfig(&TransactionLimitConfig{
+		MinAmountByType: map[string]float64{models.TypeTransfer: 1},
+		MaxAmountByType: map[string]float64{models.TypeCredit: 750, models.TypeDebit: 300},
+	})
+
+	// Act
+	defaultErr := defaults.ValidateAmount(1000000.01)
+	defaultBoundaryErr := defaults.ValidateAmount(1000000)
+	_, transferErr := configured.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 0.5})
+	_, _, creditErr := configured.Credit(context.Background(), 10, &models.CreditRequest{Amount: 750.01})
+	_, _, debitErr := configured.Debit(context.Background(), 10, &models.DebitRequest{Amount: 301})
+
+	// Assert
+	assert.EqualError(t, defaultErr, "maksimum transfer limiti: 1,000,000 TL")
+	assert.NoError(t, defaultBoundaryErr)
+	assert.EqualError(t, transferErr, "minimum transfer tutarı: 1 TL")
+	assert.EqualError(t, creditErr, "maksimum yatırma limiti: 750 TL")
+	assert.EqualError(t, debitErr, "maksimum çekme limiti: 300 TL")
+}
+
+// TestDefaultTransactionLimitConfig_OptInLimitsOff, yeni eklenen sayı limiti, açıklama zorunluluğu ve memo limitinin
+// ayarlanmadıkça kapalı olduğunu test eder (mevcut API davranışı değişmesin diye).
+func TestDefaultTransactionLimitConfig_OptInLimitsOff(t *testing.T) {
+	// Arrange
+	config := DefaultTransactionLimitConfig()
+
+	// Act
+	memoLimit, _ := config.MemoTransferLimit()
+
+	// Assert
+	assert.Equal(t, 0, config.DailyCountLimit("user"))
+	assert.Equal(t, 0, config.DailyCountLimit("mod"))
+	assert.Equal(t, 0.0, config.DailyAmountLimit("user"))
+	assert.Equal(t, 0.0, config.TransferDescriptionThreshold())
+	assert.Equal(t, 0, memoLimit)
+}
+
+// expectDailyOutboundSum bakiye kilidinden sonra okunan bugünkü giden toplamı mock'lar
+func expectDailyOutboundSum(dbMock sqlmock.Sqlmock, userID int, spent float64) {
+	dbMock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\), 0\\)\\s+FROM transactions").WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(spent))
+}
+
+// expectDailyCount bakiye kilidinden sonra okunan bugünkü transaction sayısını mock'lar
+func expectDailyCount(dbMock sqlmock.Sqlmock, userID int, count int) {
+	dbMock.ExpectQuery("SELECT COUNT\\(\\*\\)\\s+FROM transactions").WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+// expectEnsureBalance yatırmadan önce bakiye kaydının (yoksa) oluşturulmasını mock'lar
+func expectEnsureBalance(dbMock sqlmock.Sqlmock, userID int) {
+	dbMock.ExpectExec("INSERT INTO balances").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// expectAdjustBalance bakiyenin atomik UPDATE ile delta kadar değişmesini mock'lar
+func expectAdjustBalance(dbMock sqlmock.Sqlmock, userID int, delta, previous float64) {
+	dbMock.ExpectQuery("UPDATE balances SET amount = amount \\+ \\$1").WithArgs(delta, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"previous", "amount"}).AddRow(previous, previous+delta))
+}
+
+// expectMinimumBalance bakiye kilidinden sonra okunan kullanıcının minimum bakiyesini mock'lar
+func expectMinimumBalance(dbMock sqlmock.Sqlmock, userID int, minimum float64) {
+	dbMock.ExpectQuery("SELECT minimum_balance FROM balances").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"minimum_balance"}).AddRow(minimum))
+}
+
+// TestTransactionService_Transfer_DailyOutboundLimitExactAndOver, aynı gün yapılan transferlerin limite tam ulaşınca
+// geçtiğini, limiti 0.01 TL aşan transferin ise bakiye kilidi altında reddedildiğini test eder.
+func TestTransactionService_Transfer_DailyOutboundLimitExactAndOver(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{DailyAmountLimitDefault: 1000})
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+
+	// Her transferde o ana kadar gönderilen toplam okunur: 0 → 400 → 1000
+	for i, transfer := range []struct{ spent, amount float64 }{{0, 400}, {400, 600}} {
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+			WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(5000.0))
+		expectMinimumBalance(dbMock, 10, 0)
+		dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+			WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+		expectDailyOutboundSum(dbMock, 10, transfer.spent)
+		dbMock.ExpectQuery("INSERT INTO transactions").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(i+1, time.Now()))
+		dbMock.ExpectExec("UPDATE balances").WithArgs(5000-transfer.amount, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectExec("UPDATE balances").WithArgs(transfer.amount, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectCommit()
+	}
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(5000.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+	expectDailyOutboundSum(dbMock, 10, 1000)
+	dbMock.ExpectRollback()
+
+	// Act
+	_, firstErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 400})
+	_, exactErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 600})
+	result, overErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 0.01})
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, exactErr)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, overErr, ErrDailyLimitExceeded)
+	assert.EqualError(t, overErr, "günlük limit aşıldı: bugün 1,000/1,000 TL gönderildi")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Debit_DailyOutboundLimitRoleOverride, çekimlerin de günlük giden limite sayıldığını ve
+// mod için tanımlı daha yüksek limitin varsayılanı ezdiğini test eder.
+func TestTransactionService_Debit_DailyOutboundLimitRoleOverride(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		DailyAmountLimitDefault: 1000,
+		DailyAmountByRole:       map[string]float64{"mod": 5000},
+	})
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+	mockUserRepo.On("GetByID", 11).Return(&models.User{ID: 11, Role: "mod"}, nil)
+
+	// user: 900 + 150 > 1000 → red
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(2000.0))
+	expectMinimumBalance(dbMock, 10, 0)
+	expectDailyOutboundSum(dbMock, 10, 900)
+	dbMock.ExpectRollback()
+
+	// mod: 900 + 150 <= 5000 → geçer
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(11).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(2000.0))
+	expectMinimumBalance(dbMock, 11, 0)
+	expectDailyOutboundSum(dbMock, 11, 900)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(1850.0, 11).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	// Act
+	userResult, _, userErr := transactionService.Debit(context.Background(), 10, &models.DebitRequest{Amount: 150})
+	_, modBalance, modErr := transactionService.Debit(context.Background(), 11, &models.DebitRequest{Amount: 150})
+
+	// Assert
+	assert.Nil(t, userResult)
+	assert.EqualError(t, userErr, "günlük limit aşıldı: bugün 900/1,000 TL gönderildi")
+	assert.NoError(t, modErr)
+	assert.Equal(t, 1850.0, modBalance)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_DailyCountCheckedUnderBalanceLock, günlük işlem sayısının bakiyeler kilitlendikten sonra
+// sayıldığını; kilidi ilk alan isteğin son hakkı kullanmasının ardından sıradaki isteğin reddedildiğini test eder.
+func TestTransactionService_Transfer_DailyCountCheckedUnderBalanceLock(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockTxRepo := new(MockTransactionRepository)
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(mockTxRepo, mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{DailyCountByRole: map[string]int{"user": 3}})
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+
+	// İki istek kilit öncesinde aynı sayıyı (2) görürdü; kilit altında ikincisi ilkinin kaydını görür
+	for i, count := range []int{2, 3} {
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+			WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(500.0))
+		expectMinimumBalance(dbMock, 10, 0)
+		dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).
+			WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(0.0))
+		expectDailyCount(dbMock, 10, count)
+		if i == 1 {
+			dbMock.ExpectRollback()
+			continue
+		}
+		dbMock.ExpectQuery("INSERT INTO transactions").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+		dbMock.ExpectExec("UPDATE balances").WithArgs(450.0, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectExec("UPDATE balances").WithArgs(50.0, 20).WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+		dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectCommit()
+	}
+
+	// Act
+	_, firstErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 50})
+	result, secondErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 50})
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.Nil(t, result)
+	assert.EqualError(t, secondErr, "günlük transaction sayısı limitine ulaşıldı: bugün 3/3 işlem yapıldı")
+	mockTxRepo.AssertNotCalled(t, "CountUserTransactionsSince", 10, mock.Anything)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_InsufficientBalanceShortfall, yetersiz bakiyeli transferde hatanın kullanılabilir bakiyeyi
+// (aktif hold'lar düşülmüş), istenen tutarı ve float kayması olmadan eksik tutarı taşıdığını test eder.
+func TestTransactionService_Transfer_InsufficientBalanceShortfall(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	// 100.30 bakiyenin 70.20'si hold'da: kullanılabilir 30.10
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(100.3))
+	dbMock.ExpectQuery("FROM balance_holds").WithArgs(10, models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(70.2))
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	// Act
+	result, err := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 100})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+
+	var balanceErr *InsufficientBalanceError
+	assert.True(t, errors.As(err, &balanceErr))
+	assert.InDelta(t, 30.1, balanceErr.CurrentBalance, 1e-9)
+	assert.Equal(t, 100.0, balanceErr.Requested)
+	assert.Equal(t, 69.9, balanceErr.Shortfall())
+	assert.Equal(t, "yetersiz bakiye. Mevcut bakiye: 30.10 TL", err.Error())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectOrderedTransfer bakiyelerin küçük user_id önce kilitlendiği başarılı bir transferi mock'lar
+func expectOrderedTransfer(dbMock sqlmock.Sqlmock, fromUserID, toUserID int, amount float64, balances map[int]float64) {
+	first, second := fromUserID, toUserID
+	if second < first {
+		first, second = second, first
+	}
+
+	dbMock.ExpectBegin()
+	for _, userID := range []int{first, second} {
+		dbMock.ExpectQuery(`SELECT amount FROM balances WHERE user_id = \$1 FOR UPDATE`).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(balances[userID]))
+		if userID == fromUserID {
+			expectMinimumBalance(dbMock, fromUserID, 0)
+		}
+	}
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(balances[fromUserID]-amount, fromUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE balances").WithArgs(balances[toUserID]+amount, toUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+}
+
+// TestTransactionService_Transfer_OpposingTransfersLockInSameOrder, karşılıklı transferlerde (10→20 ve 20→10)
+// bakiyelerin yönden bağımsız olarak küçük user_id önce kilitlendiğini (SELECT ... FOR UPDATE sırası) test eder.
+// Eşzamanlı çalışmada deadlock olmadığı postgres build tag'li repository testinde doğrulanır.
+func TestTransactionService_Transfer_OpposingTransfersLockInSameOrder(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	expectOrderedTransfer(dbMock, 10, 20, 30, map[int]float64{10: 100, 20: 100})
+	expectOrderedTransfer(dbMock, 20, 10, 50, map[int]float64{10: 70, 20: 130})
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	// Act
+	_, errForward := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 30})
+	_, errReverse := transactionService.Transfer(context.Background(), 20, &models.TransferRequest{ToUserID: 10, Amount: 50})
+
+	// Assert: mock'lar sıralı olduğundan 20'yi önce kilitleyen transfer hata alır
+	assert.NoError(t, errForward)
+	assert.NoError(t, errReverse)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_MinIntervalBetweenTransfers, ardışık ikinci transferin aralık dolmadan DB'ye gitmeden
+// kalan süreyle reddedildiğini, başarısız transferin ise aralığı başlatmadığını test eder.
+func TestTransactionService_Transfer_MinIntervalBetweenTransfers(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	// Kullanıcı 30'un ilk denemesi DB hatasıyla başarısız olur, hemen ardından tekrar denemesi geçer
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WithArgs(20).WillReturnError(sql.ErrConnDone)
+	dbMock.ExpectRollback()
+	expectOrderedTransfer(dbMock, 30, 20, 10, map[int]float64{20: 0, 30: 100})
+
+	// Kullanıcı 10'un ilk transferi geçer, ikincisi aralık dolmadığı için DB'ye ulaşmaz
+	expectOrderedTransfer(dbMock, 10, 20, 25, map[int]float64{10: 100, 20: 10})
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{MinTransferInterval: 2 * time.Second})
+
+	// Act
+	_, failedErr := transactionService.Transfer(context.Background(), 30, &models.TransferRequest{ToUserID: 20, Amount: 10})
+	_, retryErr := transactionService.Transfer(context.Background(), 30, &models.TransferRequest{ToUserID: 20, Amount: 10})
+	_, firstErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 25})
+	result, secondErr := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 25})
+
+	// Assert
+	assert.ErrorIs(t, failedErr, sql.ErrConnDone)
+	assert.NoError(t, retryErr)
+	assert.NoError(t, firstErr)
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, secondErr, ErrTransferTooSoon)
+	var intervalErr *TransferIntervalError
+	assert.True(t, errors.As(secondErr, &intervalErr))
+	assert.True(t, intervalErr.RetryAfter > 0 && intervalErr.RetryAfter <= 2*time.Second)
+	assert.Equal(t, 2, intervalErr.RetryAfterSeconds())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_DescriptionRequiredAboveThreshold, eşik üzerindeki açıklamasız transferin reddedildiğini test eder.
+func TestTransactionService_Transfer_DescriptionRequiredAboveThreshold(t *testing.T) {
+	// Arrange
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{TransferDescriptionRequiredAbove: 10000})
+
+	// Act
+	result, err := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 15000})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "10000.00 TL üzerindeki transferlerde açıklama (transfer amacı) zorunludur")
+}
+
+// TestTransactionService_Transfer_DescriptionOptionalBelowThreshold, eşik altındaki açıklamasız transferin açıklama kontrolünden geçtiğini test eder.
+func TestTransactionService_Transfer_DescriptionOptionalBelowThreshold(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	// Açıklama kontrolünden geçen istek DB aşamasına ulaşır; burada bilinçli olarak hata döndürülür
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WillReturnError(sql.ErrConnDone)
+	dbMock.ExpectRollback()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{TransferDescriptionRequiredAbove: 10000})
+
+	// Act
+	_, err = transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 500})
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_MemoRateLimitPerRecipient, aynı alıcıya 4. hızlı açıklamalı transferin reddedildiğini test eder.
+func TestTransactionService_Transfer_MemoRateLimitPerRecipient(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockTxRepo := new(MockTransactionRepository)
+	transactionService := NewTransactionService(mockTxRepo, nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		MemoTransfersPerRecipient: 3,
+		MemoTransferWindow:        time.Hour,
+	})
+
+	// Repository her başarılı transferden sonra bir fazlasını sayar
+	for i := 0; i < 4; i++ {
+		mockTxRepo.On("CountTransfersWithDescriptionSince", 10, 20, mock.AnythingOfType("time.Time")).Return(i, nil).Once()
+	}
+	for i := 0; i < 3; i++ {
+		expectSuccessfulTransfer(dbMock, 10, 20, 1000-float64(i), 1, i+1)
+	}
+
+	req := &models.TransferRequest{ToUserID: 20, Amount: 1, Description: "Büyük indirim! www.ornek.com"}
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_, err := transactionService.Transfer(context.Background(), 10, req)
+		assert.NoError(t, err)
+	}
+	result, err := transactionService.Transfer(context.Background(), 10, req)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrMemoTransferRateLimited)
+	assert.Contains(t, err.Error(), "3/3")
+	mockTxRepo.AssertExpectations(t)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_MemoRateLimitIgnoresPlainTransfers, açıklamasız transferlerin memo limitine takılmadığını test eder.
+func TestTransactionService_Transfer_MemoRateLimitIgnoresPlainTransfers(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockTxRepo := new(MockTransactionRepository)
+	transactionService := NewTransactionService(mockTxRepo, nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		MemoTransfersPerRecipient: 3,
+		MemoTransferWindow:        time.Hour,
+	})
+
+	expectSuccessfulTransfer(dbMock, 10, 20, 1000, 1, 1)
+
+	// Act
+	_, err = transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 1, Description: "  "})
+
+	// Assert
+	assert.NoError(t, err)
+	mockTxRepo.AssertNotCalled(t, "CountTransfersWithDescriptionSince", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_GetTransactionLimits_ComputesRemainingAllowance, mock kullanımdan kalan hakların hesaplandığını test eder.
+func TestTransactionService_GetTransactionLimits_ComputesRemainingAllowance(t *testing.T) {
+	// Arrange
+	mockTxRepo := new(MockTransactionRepository)
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(mockTxRepo, mockUserRepo, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		DailyCountByRole: map[string]int{"user": 5},
+	})
+
+	userID := 10
+	mockUserRepo.On("GetByID", userID).Return(&models.User{ID: userID, Role: "user"}, nil)
+	// Önce günlük, sonra aylık kullanım sorgulanır (ayın ilk günü iki başlangıç aynı olabilir)
+	mockTxRepo.On("GetUserUsageSince", userID, mock.AnythingOfType("time.Time")).Return(&models.TransactionUsage{Count: 3, Amount: 750}, nil).Once()
+	mockTxRepo.On("GetUserUsageSince", userID, mock.AnythingOfType("time.Time")).Return(&models.TransactionUsage{Count: 12, Amount: 4200}, nil).Once()
+
+	// Act
+	limits, err := transactionService.GetTransactionLimits(userID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "user", limits.Role)
+	assert.Equal(t, startOfMonth(limits.Daily.Since), limits.Monthly.Since)
+	assert.Equal(t, 5, *limits.Daily.Count.Limit)
+	assert.Equal(t, 3, limits.Daily.Count.Used)
+	assert.Equal(t, 2, *limits.Daily.Count.Remaining)
+	assert.Equal(t, 750.0, limits.Daily.Amount.Used)
+	assert.Nil(t, limits.Daily.Amount.Limit)
+	assert.Equal(t, 12, limits.Monthly.Count.Used)
+	assert.Nil(t, limits.Monthly.Count.Remaining)
+	assert.Equal(t, 4200.0, limits.Monthly.Amount.Used)
+	mockUserRepo.AssertExpectations(t)
+	mockTxRepo.AssertExpectations(t)
+}
+
+// TestTransactionService_GetTransactionLimits_RemainingNeverNegative, limit aşılmışsa kalan hakkın sıfır döndüğünü test eder.
+func TestTransactionService_GetTransactionLimits_RemainingNeverNegative(t *testing.T) {
+	// Arrange
+	mockTxRepo := new(MockTransactionRepository)
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(mockTxRepo, mockUserRepo, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		DailyCountByRole: map[string]int{"user": 5},
+	})
+
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+	mockTxRepo.On("GetUserUsageSince", 10, mock.AnythingOfType("time.Time")).Return(&models.TransactionUsage{Count: 7, Amount: 100}, nil)
+
+	// Act
+	limits, err := transactionService.GetTransactionLimits(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, *limits.Daily.Count.Remaining)
+}
+
+// TestTransactionService_GetTransactionLimits_CountsActiveHolds, bugünkü aktif hold'ların günlük kullanıma eklendiğini,
+// önizlemenin limit kontrolleriyle aynı kalan hakkı gösterdiğini test eder.
+func TestTransactionService_GetTransactionLimits_CountsActiveHolds(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockTxRepo := new(MockTransactionRepository)
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(mockTxRepo, mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		DailyCountByRole:  map[string]int{"user": 5},
+		DailyAmountByRole: map[string]float64{"user": 1000},
+	})
+
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+	mockTxRepo.On("GetUserUsageSince", 10, mock.AnythingOfType("time.Time")).Return(&models.TransactionUsage{Count: 2, Amount: 400}, nil)
+	expectDailyHolds(dbMock, 10, 1, 350)
+
+	// Act
+	limits, err := transactionService.GetTransactionLimits(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, limits.Daily.Count.Used)
+	assert.Equal(t, 2, *limits.Daily.Count.Remaining)
+	assert.Equal(t, 750.0, limits.Daily.Amount.Used)
+	assert.Equal(t, 250.0, *limits.Daily.Amount.Remaining)
+	assert.Equal(t, 2, limits.Monthly.Count.Used)
+	assert.Equal(t, 400.0, limits.Monthly.Amount.Used)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_GetDailyLimitWarning_CountsActiveHolds, soft limit uyarısının bugünkü aktif hold'ları da
+// kullanılmış saydığını test eder: hold'lar olmadan eşiğin altında kalan giden tutar, hold'larla tutar uyarısı üretir.
+func TestTransactionService_GetDailyLimitWarning_CountsActiveHolds(t *testing.T) {
+	// Arrange
+	withFeatureFlags(t, map[string]bool{features.Holds: true})
+
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockTxRepo := new(MockTransactionRepository)
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(mockTxRepo, mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{
+		DailyCountByRole:    map[string]int{"user": 10},
+		DailyAmountByRole:   map[string]float64{"user": 1000},
+		DailySoftLimitRatio: 0.8,
+	})
+
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user"}, nil)
+	mockTxRepo.On("GetUserUsageSince", 10, mock.AnythingOfType("time.Time")).Return(&models.TransactionUsage{Count: 2, Amount: 400}, nil)
+	expectDailyHolds(dbMock, 10, 1, 500)
+
+	// Act
+	warning, err := transactionService.GetDailyLimitWarning(10)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, warning) {
+		assert.Equal(t, 900.0, warning.UsedAmount)
+		assert.Equal(t, 100.0, warning.RemainingAmount)
+		assert.Equal(t, "daily; used_amount=900.00; limit_amount=1000.00; remaining_amount=100.00", warning.HeaderValue())
+	}
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_SelfViaEmail, email ile kendine transferin çözümlemeden sonra reddedildiğini test eder.
+func TestTransactionService_Transfer_SelfViaEmail(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), nil)
+
+	req := &models.TransferByEmailRequest{ToEmail: " Ali@Example.com ", Amount: 50}
+	assert.NoError(t, req.Validate())
+	mockUserRepo.On("GetByEmail", "ali@example.com").Return(&models.User{ID: 10, Email: "ali@example.com"}, nil)
+
+	// Act
+	toUserID, err := transactionService.ResolveEmail(req.ToEmail)
+	assert.NoError(t, err)
+	result, err := transactionService.Transfer(context.Background(), 10, req.ToTransferRequest(toUserID))
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrSelfTransfer)
+	assert.EqualError(t, err, "kendinize para gönderemezsiniz")
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestTransactionService_Transfer_SelfViaAccountNumber, hesap numarası ile kendine transferin DB'ye gitmeden reddedildiğini test eder.
+func TestTransactionService_Transfer_SelfViaAccountNumber(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), database)
+
+	accountNumber, err := GenerateAccountNumber(DefaultAccountNumberConfig())
+	assert.NoError(t, err)
+	mockUserRepo.On("GetByAccountNumber", accountNumber).Return(&models.User{ID: 10, AccountNumber: &accountNumber}, nil)
+
+	req := &models.TransferByAccountNumberRequest{ToAccountNumber: accountNumber, Amount: 50}
+
+	// Act
+	toUserID, err := transactionService.ResolveAccountNumber(req.ToAccountNumber)
+	assert.NoError(t, err)
+	result, err := transactionService.Transfer(context.Background(), 10, req.ToTransferRequest(toUserID))
+
+	// Assert: bakiye kilitleme/oluşturma adımlarına hiç ulaşılmaz
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrSelfTransfer)
+	mockUserRepo.AssertExpectations(t)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Transfer_NewAccountWithinCooldown, bekleme süresi dolmamış yeni hesabın transferinin reddedildiğini test eder.
+func TestTransactionService_Transfer_NewAccountWithinCooldown(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), nil)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{NewAccountTransferCooldown: time.Hour})
+
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user", CreatedAt: time.Now().Add(-10 * time.Minute)}, nil)
+
+	// Act
+	result, err := transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 50})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrNewAccountTransferCooldown)
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestTransactionService_Transfer_NewAccountPastCooldown, bekleme süresini dolduran hesabın transferinin DB aşamasına ulaştığını test eder.
+func TestTransactionService_Transfer_NewAccountPastCooldown(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	// Bekleme kontrolünden geçen istek DB aşamasına ulaşır; burada bilinçli olarak hata döndürülür
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT amount FROM balances").WillReturnError(sql.ErrConnDone)
+	dbMock.ExpectRollback()
+
+	mockUserRepo := new(MockUserRepository)
+	transactionService := NewTransactionService(new(MockTransactionRepository), mockUserRepo, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(&TransactionLimitConfig{NewAccountTransferCooldown: time.Hour})
+
+	mockUserRepo.On("GetByID", 10).Return(&models.User{ID: 10, Role: "user", CreatedAt: time.Now().Add(-2 * time.Hour)}, nil)
+
+	// Act
+	_, err = transactionService.Transfer(context.Background(), 10, &models.TransferRequest{ToUserID: 20, Amount: 50})
+
+	// Assert
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.NotErrorIs(t, err, ErrNewAccountTransferCooldown)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// TestTransactionService_Credit_RecordsRequestChannel, isteğin kanalının transaction kaydına yazıldığını test eder.
+func TestTransactionService_Credit_RecordsRequestChannel(t *testing.T) {
+	// Arrange
+	database, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer database.Close()
+
+	userID := 10
+	dbMock.ExpectBegin()
+	expectEnsureBalance(dbMock, userID)
+	dbMock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(userID, 50.0, models.TypeCredit, models.StatusPending, "Hesaba para yatırma", models.ChannelMobile).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	expectAdjustBalance(dbMock, userID, 50.0, 100.0)
+	dbMock.ExpectExec("INSERT INTO balance_history").WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec("UPDATE transactions SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), database)
+	transactionService.SetLimitConfig(nil)
+
+	// Auth middleware kanalı context'e bu şekilde ekler
+	ctx := utils.WithChannel(context.Background(), "Mobile")
+
+	// Act
+	result, _, err := transactionService.Credit(ctx, userID, &models.CreditRequest{Amount: 50})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.ChannelMobile, result.Channel)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// keysetTransactionRepository geçmiş sorgusunu bellekteki satırlar üzerinde (created_at DESC, id DESC) keyset ile çalıştırır
+type keysetTransactionRepository struct {
+	MockTransactionRepository
+	rows []*models.Transaction
+}
+
+func (r *keysetTransactionRepository) GetByUserIDFiltered(userID int, filter *models.TransactionHistoryFilter, limit, offset int) ([]*models.Transaction, error) {
+	before := func(a, b *models.Transaction) bool {
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID > b.ID
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+
+	var result []*models.Transaction
+	for _, row := range r.rows {
+		if filter.Cursor != nil && !before(&models.Transaction{ID: filter.Cursor.ID, CreatedAt: filter.Cursor.CreatedAt}, row) {
+			continue
+		}
+		inserted := false
+		for i, existing := range result {
+			if before(row, existing) {
+				result = append(result[:i], append([]*models.Transaction{row}, result[i:]...)...)
+				inserted = true
+				break
+			}
+		}
+		if !inserted {
+			result = append(result, row)
+		}
+	}
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// TestTransactionService_GetUserTransactionsPage_StableAcrossInserts, sayfalar arasında yeni satır eklendiğinde
+// cursor sayfalamanın satırı tekrar etmediğini veya atlamadığını test eder.
+func TestTransactionService_GetUserTransactionsPage_StableAcrossInserts(t *testing.T) {
+	// Arrange
+	base := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	repo := &keysetTransactionRepository{}
+	for id := 1; id <= 5; id++ {
+		// 2 ve 3 aynı saniyede: id ile ayrışmalı
+		createdAt := base.Add(time.Duration(id) * time.Minute)
+		if id == 3 {
+			createdAt = base.Add(2 * time.Minute)
+		}
+		repo.rows = append(repo.rows, &models.Transaction{ID: id, Amount: float64(id), CreatedAt: createdAt})
+	}
+	transactionService := NewTransactionService(repo, nil, new(MockBalanceService), nil)
+
+	var seen []int
+	collect := func(page *models.TransactionPage) {
+		for _, tx := range page.Transactions {
+			seen = append(seen, tx.ID)
+		}
+	}
+
+	// Act
+	first, err := transactionService.GetUserTransactionsPage(10, &models.TransactionHistoryFilter{Sort: models.SortDesc}, 2)
+	assert.NoError(t, err)
+	collect(first)
+
+	// İlk sayfadan sonra yeni transaction'lar gelir
+	repo.rows = append(repo.rows,
+		&models.Transaction{ID: 6, CreatedAt: base.Add(time.Hour)},
+		&models.Transaction{ID: 7, CreatedAt: base.Add(2 * time.Hour)},
+	)
+
+	cursor := first.NextCursor
+	pages := 1
+	for cursor != "" {
+		decoded, decodeErr := models.DecodeHistoryCursor(cursor)
+		assert.NoError(t, decodeErr)
+
+		page, pageErr := transactionService.GetUserTransactionsPage(10, &models.TransactionHistoryFilter{Sort: models.SortDesc, Cursor: decoded}, 2)
+		assert.NoError(t, pageErr)
+		collect(page)
+		cursor = page.NextCursor
+		pages++
+	}
+
+	// Assert
+	assert.Equal(t, []int{5, 4, 3, 2, 1}, seen)
+	assert.Equal(t, 3, pages)
+}
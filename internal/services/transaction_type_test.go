@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+)
+
+// TestTransactionTypeRegistry_RegisteredTypeValidEverywhere, kayıt edilen yeni tipin tüm validatörlerde geçerli olduğunu test eder.
+func TestTransactionTypeRegistry_RegisteredTypeValidEverywhere(t *testing.T) {
+	// Arrange
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	userID := 10
+	fee := &models.Transaction{FromUserID: &userID, Amount: 2.5, Type: "test_fee", Status: models.StatusPending}
+
+	assert.Error(t, transactionService.ValidateTransactionType("test_fee"))
+	assert.Error(t, fee.ValidateType())
+
+	// Act
+	err := models.RegisterTransactionType("test_fee")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, transactionService.ValidateTransactionType("test_fee"))
+	assert.NoError(t, fee.ValidateType())
+	assert.NoError(t, fee.Validate())
+	assert.Contains(t, models.TransactionTypes(), "test_fee")
+
+	stats := models.NewTransactionStats(userID)
+	stats.AddTypeTotals("test_fee", 3, 7.5)
+	assert.Equal(t, 3, stats.CountsByType["test_fee"])
+	assert.Equal(t, 7.5, stats.AmountsByType["test_fee"])
+	assert.Equal(t, 3, stats.TotalTransactions)
+}
+
+// TestTransactionTypeRegistry_RejectsInvalidNames, geçersiz tip isimlerinin kayıt edilmediğini test eder.
+func TestTransactionTypeRegistry_RejectsInvalidNames(t *testing.T) {
+	for _, name := range []string{"", "Refund", "fee-type", "a_type_name_that_is_way_too_long"} {
+		// Act
+		err := models.RegisterTransactionType(name)
+
+		// Assert
+		assert.Error(t, err, name)
+		assert.False(t, models.IsValidTransactionType(name), name)
+	}
+}
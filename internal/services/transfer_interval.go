@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrTransferTooSoon kullanıcı bir önceki transferinden sonra minimum bekleme süresi dolmadan transfer yaptığında döner
+var ErrTransferTooSoon = errors.New("transferler arasındaki minimum bekleme süresi dolmadı")
+
+// transferIntervalPruneSize takip edilen kullanıcı sayısı bu değeri aştığında süresi dolmuş kayıtlar temizlenir
+const transferIntervalPruneSize = 10000
+
+// TransferIntervalError bir sonraki transferin ne kadar sonra yapılabileceğini taşıyan bekleme hatası.
+// errors.Is(err, ErrTransferTooSoon) ile yakalanır; kalan süre errors.As ile okunur.
+type TransferIntervalError struct {
+	RetryAfter time.Duration
+}
+
+// Error kalan bekleme süresini içeren mesajı döner
+func (e *TransferIntervalError) Error() string {
+	return fmt.Sprintf("%s: %d saniye sonra tekrar deneyin", ErrTransferTooSoon, e.RetryAfterSeconds())
+}
+
+// Unwrap errors.Is(err, ErrTransferTooSoon) kontrolünü sağlar
+func (e *TransferIntervalError) Unwrap() error {
+	return ErrTransferTooSoon
+}
+
+// RetryAfterSeconds Retry-After header'ı için kalan süreyi yukarı yuvarlanmış saniye olarak döner (en az 1)
+func (e *TransferIntervalError) RetryAfterSeconds() int {
+	return int(math.Max(math.Ceil(e.RetryAfter.Seconds()), 1))
+}
+
+// transferIntervalTracker kullanıcıların son transfer zamanını bellekte tutar (tek instance içinde geçerlidir)
+type transferIntervalTracker struct {
+	mu   sync.Mutex
+	last map[int]time.Time
+}
+
+// reserveTransferSlot kullanıcının son transferinden bu yana minimum süre geçtiyse transfer zamanını şimdi olarak
+// işaretler. Kontrol ve işaretleme aynı kilit altında yapıldığından eşzamanlı istekler aralığı atlayamaz.
+// Dönen release, transfer başarısız olursa çağrılır ve önceki kaydı geri yükler (başarısız deneme aralığı başlatmaz).
+func (s *TransactionService) reserveTransferSlot(userID int) (func(), error) {
+	interval := s.limitConfig.TransferInterval()
+	if interval <= 0 {
+		return func() {}, nil
+	}
+
+	tracker := &s.transferTimes
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if tracker.last == nil {
+		tracker.last = make(map[int]time.Time)
+	}
+
+	now := time.Now()
+	previous, hadPrevious := tracker.last[userID]
+	if hadPrevious {
+		if wait := previous.Add(interval).Sub(now); wait > 0 {
+			return nil, &TransferIntervalError{RetryAfter: wait}
+		}
+	}
+
+	if len(tracker.last) >= transferIntervalPruneSize {
+		for id, at := range tracker.last {
+			if now.Sub(at) >= interval {
+				delete(tracker.last, id)
+			}
+		}
+	}
+	tracker.last[userID] = now
+
+	release := func() {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+
+		// Arada başka bir transfer işaretlendiyse ona dokunulmaz
+		if !tracker.last[userID].Equal(now) {
+			return
+		}
+		if hadPrevious {
+			tracker.last[userID] = previous
+		} else {
+			delete(tracker.last, userID)
+		}
+	}
+	return release, nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/onerilhan/go-payment-api/internal/interfaces"
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+var (
+	// ErrScheduledTransferNotFound kayıt yoksa veya kullanıcıya ait değilse döner
+	ErrScheduledTransferNotFound = errors.New("zamanlanmış transfer bulunamadı")
+	// ErrScheduledTransferNotCancellable scheduler kaydı aldıktan (veya kayıt kapandıktan) sonra iptal istendiğinde döner
+	ErrScheduledTransferNotCancellable = errors.New("zamanlanmış transfer artık iptal edilemez")
+	// ErrScheduledTransferLimitExceeded kullanıcının aktif zamanlanmış transfer sayısı üst sınırdayken yeni kayıt istendiğinde döner
+	ErrScheduledTransferLimitExceeded = errors.New("aktif zamanlanmış transfer sınırına ulaşıldı")
+)
+
+// TransferSchedulerConfig ileri tarihli transfer scheduler ayarları
+type TransferSchedulerConfig struct {
+	PollInterval time.Duration // Zamanı gelen transferlerin taranma sıklığı
+	BatchSize    int           // Bir turda alınan en fazla kayıt
+	StaleAfter   time.Duration // Bu süreden uzun processing'de kalan kayıt (ör. restart) tekrar alınır
+
+	MaxActivePerUser int // Kullanıcı başına en fazla aktif (scheduled/processing) kayıt (0 = sınırsız)
+}
+
+// DefaultTransferSchedulerConfig varsayılan scheduler ayarları (10 sn'de bir, tur başına 50 kayıt, kullanıcı başına 100 aktif kayıt)
+func DefaultTransferSchedulerConfig() *TransferSchedulerConfig {
+	return &TransferSchedulerConfig{
+		PollInterval: 10 * time.Second,
+		BatchSize:    50,
+		StaleAfter:   5 * time.Minute,
+
+		MaxActivePerUser: 100,
+	}
+}
+
+// TransferEnqueuer transferi işlenmek üzere kabul eden queue (TransactionQueue)
+type TransferEnqueuer interface {
+	AddJob(ctx context.Context, fromUserID int, req *models.TransferRequest) <-chan TransactionResult
+}
+
+// TransferScheduler ileri tarihli transferleri saklar ve zamanı gelenleri transaction queue'ya verir
+type TransferScheduler struct {
+	service *TransactionService
+	repo    interfaces.ScheduledTransferRepositoryInterface
+	queue   TransferEnqueuer
+	config  *TransferSchedulerConfig
+	now     func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewTransferScheduler yeni scheduler oluşturur (config nil ise varsayılanlar kullanılır)
+func NewTransferScheduler(service *TransactionService, repo interfaces.ScheduledTransferRepositoryInterface, queue TransferEnqueuer, config *TransferSchedulerConfig) *TransferScheduler {
+	if config == nil {
+		config = DefaultTransferSchedulerConfig()
+	}
+	return &TransferScheduler{
+		service: service,
+		repo:    repo,
+		queue:   queue,
+		config:  config,
+		now:     time.Now,
+	}
+}
+
+// Schedule transferi doğrular ve ScheduledAt anında çalışmak üzere kaydeder.
+// Bakiye ve limit kontrolleri çalışma anında normal transfer akışında yapılır.
+func (s *TransferScheduler) Schedule(fromUserID int, req *models.TransferRequest) (*models.ScheduledTransfer, error) {
+	if req.ScheduledAt == nil {
+		return nil, fmt.Errorf("scheduled_at gereklidir")
+	}
+	if err := models.ValidateScheduledAt(*req.ScheduledAt, s.now()); err != nil {
+		return nil, err
+	}
+	if _, err := s.service.prepareTransfer(fromUserID, req); err != nil {
+		return nil, err
+	}
+
+	// Aktif kayıt sınırı repository'de sayım ve kayıtla birlikte atomik olarak uygulanır
+	scheduled, err := s.repo.Create(&models.ScheduledTransfer{
+		FromUserID:  fromUserID,
+		ToUserID:    req.ToUserID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		ScheduledAt: req.ScheduledAt.UTC(),
+	}, s.config.MaxActivePerUser)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled == nil {
+		return nil, fmt.Errorf("%w (en fazla %d)", ErrScheduledTransferLimitExceeded, s.config.MaxActivePerUser)
+	}
+	return scheduled, nil
+}
+
+// Cancel kullanıcının henüz çalışmamış zamanlanmış transferini iptal eder
+func (s *TransferScheduler) Cancel(userID, id int) (*models.ScheduledTransfer, error) {
+	transfer, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	// Başka kullanıcıların kayıtlarının varlığı sızdırılmaz
+	if transfer == nil || transfer.FromUserID != userID {
+		return nil, ErrScheduledTransferNotFound
+	}
+	if !transfer.IsCancellable() {
+		return nil, fmt.Errorf("%w: %s", ErrScheduledTransferNotCancellable, transfer.Status)
+	}
+
+	// Okuma ile iptal arasında scheduler kaydı almış olabilir
+	cancelled, err := s.repo.Cancel(id)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, ErrScheduledTransferNotCancellable
+	}
+
+	transfer.Status = models.ScheduledStatusCancelled
+	return transfer, nil
+}
+
+// RunDue zamanı gelen transferleri alır, queue'ya verir ve sonuçlarını kaydeder; işlenen kayıt sayısını döner.
+// Her kayıt kendi Idempotency-Key'i ile çalışır: takılı kalıp tekrar alınan kayıt ikinci kez transfer yapmaz.
+func (s *TransferScheduler) RunDue(ctx context.Context) (int, error) {
+	// scheduled_at UTC saklanır; karşılaştırma sunucunun yerel saat diliminden etkilenmesin
+	now := s.now().UTC()
+	transfers, err := s.repo.ClaimDue(now, now.Add(-s.config.StaleAfter), s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, transfer := range transfers {
+		jobCtx := utils.WithIdempotencyKey(ctx, fmt.Sprintf("scheduled-transfer-%d", transfer.ID))
+		result := <-s.queue.AddJob(jobCtx, transfer.FromUserID, transfer.ToTransferRequest())
+
+		switch {
+		case errors.Is(result.Error, ErrTransactionQueueFull), errors.Is(result.Error, ErrTransactionQueueStopped):
+			err = s.repo.Reschedule(transfer.ID)
+		case errors.Is(result.Error, ErrIdempotencyKeyInProgress):
+			// Önceki deneme (ör. stale alınan kayıt) hâlâ işleniyor; sonucu sonraki turda idempotency kaydından alınır
+			log.Info().Int("scheduled_transfer_id", transfer.ID).Msg("Zamanlanmış transferin önceki denemesi sürüyor, tekrar zamanlandı")
+			err = s.repo.Reschedule(transfer.ID)
+		case result.Error != nil:
+			log.Warn().Err(result.Error).Int("scheduled_transfer_id", transfer.ID).Msg("Zamanlanmış transfer başarısız")
+			err = s.repo.MarkFailed(transfer.ID, result.Error.Error())
+		default:
+			err = s.repo.MarkCompleted(transfer.ID, result.Transaction.ID)
+		}
+		if err != nil {
+			log.Error().Err(err).Int("scheduled_transfer_id", transfer.ID).Msg("Zamanlanmış transfer sonucu kaydedilemedi")
+		}
+	}
+
+	return len(transfers), nil
+}
+
+// Start zamanı gelen transferleri periyodik olarak çalıştırır (Stop veya ctx iptaliyle durur)
+func (s *TransferScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	interval := s.config.PollInterval
+	if interval <= 0 {
+		interval = DefaultTransferSchedulerConfig().PollInterval
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				log.Info().Msg("Transfer scheduler stopped")
+				return
+			case <-ticker.C:
+				if _, err := s.RunDue(ctx); err != nil {
+					log.Error().Err(err).Msg("Zamanlanmış transferler çalıştırılamadı")
+				}
+			}
+		}
+	}()
+}
+
+// Stop scheduler'ı durdurur ve süren turun bitmesini bekler (queue kapatılmadan önce çağrılmalı)
+func (s *TransferScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
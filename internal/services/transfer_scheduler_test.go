@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onerilhan/go-payment-api/internal/models"
+	"github.com/onerilhan/go-payment-api/internal/utils"
+)
+
+// fakeScheduledTransferRepository zamanlanmış transfer tablosunu bellekte taklit eden repository
+type fakeScheduledTransferRepository struct {
+	mu        sync.Mutex
+	transfers map[int]*models.ScheduledTransfer
+	nextID    int
+
+	claimedNow, claimedStaleBefore time.Time // Son ClaimDue çağrısının zamanları
+}
+
+func newFakeScheduledTransferRepository() *fakeScheduledTransferRepository {
+	return &fakeScheduledTransferRepository{transfers: make(map[int]*models.ScheduledTransfer)}
+}
+
+func (f *fakeScheduledTransferRepository) Create(transfer *models.ScheduledTransfer, maxActive int) (*models.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Gerçek repository gibi sayım ve kayıt aynı kilit altında yapılır
+	if maxActive > 0 && f.countActive(transfer.FromUserID) >= maxActive {
+		return nil, nil
+	}
+
+	f.nextID++
+	created := *transfer
+	created.ID = f.nextID
+	created.Status = models.ScheduledStatusScheduled
+	f.transfers[created.ID] = &created
+
+	copied := created
+	return &copied, nil
+}
+
+func (f *fakeScheduledTransferRepository) GetByID(id int) (*models.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transfer, ok := f.transfers[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *transfer
+	return &copied, nil
+}
+
+func (f *fakeScheduledTransferRepository) ClaimDue(now, staleBefore time.Time, limit int) ([]*models.ScheduledTransfer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.claimedNow, f.claimedStaleBefore = now, staleBefore
+	var due []*models.ScheduledTransfer
+	for id := 1; id <= f.nextID && len(due) < limit; id++ {
+		transfer, ok := f.transfers[id]
+		if !ok || transfer.Status != models.ScheduledStatusScheduled || transfer.ScheduledAt.After(now) {
+			continue
+		}
+		transfer.Status = models.ScheduledStatusProcessing
+		copied := *transfer
+		due = append(due, &copied)
+	}
+	return due, nil
+}
+
+func (f *fakeScheduledTransferRepository) setStatus(id int, status string, update func(*models.ScheduledTransfer)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transfer := f.transfers[id]
+	transfer.Status = status
+	if update != nil {
+		update(transfer)
+	}
+}
+
+func (f *fakeScheduledTransferRepository) Reschedule(id int) error {
+	f.setStatus(id, models.ScheduledStatusScheduled, nil)
+	return nil
+}
+
+func (f *fakeScheduledTransferRepository) MarkCompleted(id int, transactionID int) error {
+	f.setStatus(id, models.ScheduledStatusCompleted, func(t *models.ScheduledTransfer) { t.TransactionID = &transactionID })
+	return nil
+}
+
+func (f *fakeScheduledTransferRepository) MarkFailed(id int, lastError string) error {
+	f.setStatus(id, models.ScheduledStatusFailed, func(t *models.ScheduledTransfer) { t.LastError = lastError })
+	return nil
+}
+
+func (f *fakeScheduledTransferRepository) Cancel(id int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transfer, ok := f.transfers[id]
+	if !ok || transfer.Status != models.ScheduledStatusScheduled {
+		return false, nil
+	}
+	transfer.Status = models.ScheduledStatusCancelled
+	return true, nil
+}
+
+// countActive kullanıcının scheduled/processing kayıt sayısını döner (f.mu tutulurken çağrılır)
+func (f *fakeScheduledTransferRepository) countActive(userID int) int {
+	count := 0
+	for _, transfer := range f.transfers {
+		if transfer.FromUserID == userID && (transfer.Status == models.ScheduledStatusScheduled || transfer.Status == models.ScheduledStatusProcessing) {
+			count++
+		}
+	}
+	return count
+}
+
+// scheduledJob fake queue'ya verilen job
+type scheduledJob struct {
+	fromUserID     int
+	request        *models.TransferRequest
+	idempotencyKey string
+}
+
+// fakeTransferQueue job'ları kaydeder ve verilen sonucu hemen döner
+type fakeTransferQueue struct {
+	jobs   []scheduledJob
+	result TransactionResult
+}
+
+func (q *fakeTransferQueue) AddJob(ctx context.Context, fromUserID int, req *models.TransferRequest) <-chan TransactionResult {
+	key := utils.IdempotencyKeyFromContext(ctx)
+	q.jobs = append(q.jobs, scheduledJob{fromUserID: fromUserID, request: req, idempotencyKey: key})
+
+	resultChan := make(chan TransactionResult, 1)
+	resultChan <- q.result
+	close(resultChan)
+	return resultChan
+}
+
+// newTestTransferScheduler saati sabitlenmiş scheduler oluşturur
+func newTestTransferScheduler(repo *fakeScheduledTransferRepository, queue *fakeTransferQueue, now time.Time) *TransferScheduler {
+	transactionService := NewTransactionService(new(MockTransactionRepository), nil, new(MockBalanceService), nil)
+	scheduler := NewTransferScheduler(transactionService, repo, queue, nil)
+	scheduler.now = func() time.Time { return now }
+	return scheduler
+}
+
+// TestTransferScheduler_Schedule_StoresFutureRejectsPast, gelecekteki transferin kaydedildiğini ve geçmiş zamanın reddedildiğini test eder.
+func TestTransferScheduler_Schedule_StoresFutureRejectsPast(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeScheduledTransferRepository()
+	scheduler := newTestTransferScheduler(repo, &fakeTransferQueue{}, now)
+
+	future := now.Add(24 * time.Hour)
+	past := now.Add(-time.Minute)
+
+	// Act
+	scheduled, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 75, Description: "kira", ScheduledAt: &future})
+	rejected, pastErr := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 75, ScheduledAt: &past})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.ScheduledStatusScheduled, scheduled.Status)
+	assert.Equal(t, future, scheduled.ScheduledAt)
+	assert.Equal(t, 75.0, scheduled.Amount)
+
+	assert.Nil(t, rejected)
+	assert.Error(t, pastErr)
+	assert.Len(t, repo.transfers, 1)
+}
+
+// TestTransferScheduler_Schedule_RejectsBeyondActiveLimit, kullanıcının aktif kayıt sayısı sınırdayken yeni transferin
+// reddedildiğini, iptal edilen kaydın sınırdan düşüldüğünü ve diğer kullanıcıların etkilenmediğini test eder.
+func TestTransferScheduler_Schedule_RejectsBeyondActiveLimit(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeScheduledTransferRepository()
+	scheduler := newTestTransferScheduler(repo, &fakeTransferQueue{}, now)
+	scheduler.config.MaxActivePerUser = 2
+
+	future := now.Add(24 * time.Hour)
+	first, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 10, ScheduledAt: &future})
+	require.NoError(t, err)
+	_, err = scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 10, ScheduledAt: &future})
+	require.NoError(t, err)
+
+	// Act
+	rejected, limitErr := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 10, ScheduledAt: &future})
+	_, otherUserErr := scheduler.Schedule(30, &models.TransferRequest{ToUserID: 20, Amount: 10, ScheduledAt: &future})
+
+	_, cancelErr := scheduler.Cancel(10, first.ID)
+	_, afterCancelErr := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 10, ScheduledAt: &future})
+
+	// Assert
+	assert.Nil(t, rejected)
+	assert.ErrorIs(t, limitErr, ErrScheduledTransferLimitExceeded)
+	assert.NoError(t, otherUserErr)
+	require.NoError(t, cancelErr)
+	assert.NoError(t, afterCancelErr)
+	assert.Len(t, repo.transfers, 4)
+}
+
+// TestTransferScheduler_Schedule_ConcurrentRequestsRespectActiveLimit, aynı kullanıcının eşzamanlı isteklerinde
+// aktif kayıt sınırının aşılmadığını test eder: sınır kadar istek kaydedilir, kalanlar reddedilir.
+func TestTransferScheduler_Schedule_ConcurrentRequestsRespectActiveLimit(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeScheduledTransferRepository()
+	scheduler := newTestTransferScheduler(repo, &fakeTransferQueue{}, now)
+	scheduler.config.MaxActivePerUser = 5
+
+	const requests = 20
+	future := now.Add(24 * time.Hour)
+	errs := make(chan error, requests)
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 10, ScheduledAt: &future})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	succeeded, limited := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrScheduledTransferLimitExceeded):
+			limited++
+		default:
+			t.Errorf("beklenmeyen hata: %v", err)
+		}
+	}
+	assert.Equal(t, 5, succeeded)
+	assert.Equal(t, requests-5, limited)
+	assert.Len(t, repo.transfers, 5)
+}
+
+// TestTransferScheduler_RunDue_EnqueuesOnlyDueTransfers, sadece zamanı gelen transferin queue'ya verildiğini ve sonucunun kaydedildiğini test eder.
+func TestTransferScheduler_RunDue_EnqueuesOnlyDueTransfers(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeScheduledTransferRepository()
+	queue := &fakeTransferQueue{result: TransactionResult{Transaction: &models.Transaction{ID: 501, Status: models.StatusCompleted}}}
+	scheduler := newTestTransferScheduler(repo, queue, now.Add(-2*time.Hour))
+
+	soon := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+	due, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 40, ScheduledAt: &soon})
+	require.NoError(t, err)
+	notDue, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 30, Amount: 60, ScheduledAt: &later})
+	require.NoError(t, err)
+
+	scheduler.now = func() time.Time { return now }
+
+	// Act
+	processed, err := scheduler.RunDue(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	require.Len(t, queue.jobs, 1)
+	assert.Equal(t, 10, queue.jobs[0].fromUserID)
+	assert.Equal(t, 20, queue.jobs[0].request.ToUserID)
+	assert.Nil(t, queue.jobs[0].request.ScheduledAt)
+	assert.Equal(t, "scheduled-transfer-1", queue.jobs[0].idempotencyKey)
+
+	completed, _ := repo.GetByID(due.ID)
+	assert.Equal(t, models.ScheduledStatusCompleted, completed.Status)
+	assert.Equal(t, 501, *completed.TransactionID)
+
+	pending, _ := repo.GetByID(notDue.ID)
+	assert.Equal(t, models.ScheduledStatusScheduled, pending.Status)
+}
+
+// TestTransferScheduler_RunDue_QueueFullReschedules, queue doluyken alınan kaydın sonraki tura bırakıldığını test eder.
+func TestTransferScheduler_RunDue_QueueFullReschedules(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeScheduledTransferRepository()
+	queue := &fakeTransferQueue{result: TransactionResult{Error: ErrTransactionQueueFull}}
+	scheduler := newTestTransferScheduler(repo, queue, now.Add(-time.Hour))
+
+	at := now.Add(-time.Minute)
+	scheduled, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 40, ScheduledAt: &at})
+	require.NoError(t, err)
+	scheduler.now = func() time.Time { return now }
+
+	// Act
+	_, err = scheduler.RunDue(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	transfer, _ := repo.GetByID(scheduled.ID)
+	assert.Equal(t, models.ScheduledStatusScheduled, transfer.Status)
+}
+
+// TestTransferScheduler_RunDue_ClaimsWithUTCTimes, sunucu saati UTC dışı bir bölgedeyken de ClaimDue'ya UTC zamanların
+// verildiğini test eder (scheduled_at UTC saklanır).
+func TestTransferScheduler_RunDue_ClaimsWithUTCTimes(t *testing.T) {
+	// Arrange
+	istanbul := time.FixedZone("Europe/Istanbul", 3*60*60)
+	now := time.Date(2025, 9, 1, 15, 0, 0, 0, istanbul)
+	repo := newFakeScheduledTransferRepository()
+	scheduler := newTestTransferScheduler(repo, &fakeTransferQueue{}, now)
+
+	// Act
+	_, err := scheduler.RunDue(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, repo.claimedNow.Location())
+	assert.Equal(t, time.UTC, repo.claimedStaleBefore.Location())
+	assert.True(t, repo.claimedNow.Equal(now))
+	assert.True(t, repo.claimedStaleBefore.Equal(now.Add(-DefaultTransferSchedulerConfig().StaleAfter)))
+}
+
+// TestTransferScheduler_RunDue_KeyInProgressReschedules, önceki denemesi hâlâ işlenen (Idempotency-Key in_progress) kaydın
+// failed işaretlenmeyip tekrar zamanlandığını ve sonraki turda idempotency kaydından gelen sonuçla tamamlandığını test eder.
+func TestTransferScheduler_RunDue_KeyInProgressReschedules(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeScheduledTransferRepository()
+	queue := &fakeTransferQueue{result: TransactionResult{Error: ErrIdempotencyKeyInProgress}}
+	scheduler := newTestTransferScheduler(repo, queue, now.Add(-time.Hour))
+
+	at := now.Add(-time.Minute)
+	scheduled, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 40, ScheduledAt: &at})
+	require.NoError(t, err)
+	scheduler.now = func() time.Time { return now }
+
+	// Act
+	_, firstErr := scheduler.RunDue(context.Background())
+	afterFirst, _ := repo.GetByID(scheduled.ID)
+
+	queue.result = TransactionResult{Transaction: &models.Transaction{ID: 501, Status: models.StatusCompleted}}
+	_, secondErr := scheduler.RunDue(context.Background())
+	afterSecond, _ := repo.GetByID(scheduled.ID)
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, secondErr)
+	assert.Equal(t, models.ScheduledStatusScheduled, afterFirst.Status)
+	assert.Empty(t, afterFirst.LastError)
+	assert.Equal(t, models.ScheduledStatusCompleted, afterSecond.Status)
+	assert.Equal(t, 501, *afterSecond.TransactionID)
+	require.Len(t, queue.jobs, 2)
+	assert.Equal(t, queue.jobs[0].idempotencyKey, queue.jobs[1].idempotencyKey)
+}
+
+// TestTransferScheduler_Cancel, iptal edilen transferin zamanı gelince çalışmadığını, başkasının kaydının ve
+// çalışmış kaydın iptal edilemediğini test eder.
+func TestTransferScheduler_Cancel(t *testing.T) {
+	// Arrange
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeScheduledTransferRepository()
+	queue := &fakeTransferQueue{result: TransactionResult{Transaction: &models.Transaction{ID: 7}}}
+	scheduler := newTestTransferScheduler(repo, queue, now.Add(-time.Hour))
+
+	at := now.Add(-time.Minute)
+	cancelTarget, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 20, Amount: 40, ScheduledAt: &at})
+	require.NoError(t, err)
+	executed, err := scheduler.Schedule(10, &models.TransferRequest{ToUserID: 30, Amount: 50, ScheduledAt: &at})
+	require.NoError(t, err)
+
+	// Act
+	_, otherUserErr := scheduler.Cancel(99, cancelTarget.ID)
+	cancelled, cancelErr := scheduler.Cancel(10, cancelTarget.ID)
+
+	scheduler.now = func() time.Time { return now }
+	_, runErr := scheduler.RunDue(context.Background())
+	_, lateErr := scheduler.Cancel(10, executed.ID)
+
+	// Assert
+	assert.ErrorIs(t, otherUserErr, ErrScheduledTransferNotFound)
+	require.NoError(t, cancelErr)
+	assert.Equal(t, models.ScheduledStatusCancelled, cancelled.Status)
+
+	require.NoError(t, runErr)
+	require.Len(t, queue.jobs, 1)
+	assert.Equal(t, 30, queue.jobs[0].request.ToUserID)
+	assert.ErrorIs(t, lateErr, ErrScheduledTransferNotCancellable)
+}
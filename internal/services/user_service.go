@@ -1,23 +1,61 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/onerilhan/go-payment-api/internal/auth"
+	"github.com/onerilhan/go-payment-api/internal/db"
 	"github.com/onerilhan/go-payment-api/internal/interfaces"
 	"github.com/onerilhan/go-payment-api/internal/models"
 )
 
 // UserService kullanıcı business logic'i
 type UserService struct {
-	userRepo interfaces.UserRepositoryInterface // ← interface kullan
+	userRepo            interfaces.UserRepositoryInterface // ← interface kullan
+	accountNumberConfig *AccountNumberConfig               // Register'da hesap numarası üretimi (nil = kapalı)
+	registerableRoles   map[string]bool                    // Kullanıcının kendi kaydında seçebileceği roller
 }
 
 // NewUserService yeni service oluşturur
 func NewUserService(userRepo interfaces.UserRepositoryInterface) *UserService {
-	return &UserService{userRepo: userRepo}
+	return &UserService{
+		userRepo:            userRepo,
+		accountNumberConfig: DefaultAccountNumberConfig(),
+		registerableRoles:   map[string]bool{models.RoleUser: true}, // Varsayılan: sadece user
+	}
+}
+
+// SetRegisterableRoles kendi kendine kayıt olunabilecek rolleri ayarlar.
+// Roller tanımlı olmalıdır; admin ve mod hiçbir zaman kendi kendine atanamaz.
+func (s *UserService) SetRegisterableRoles(roles []string) error {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		role = models.NormalizeRole(role)
+		if !models.IsKnownRole(role) {
+			return fmt.Errorf("kayıt için tanımsız rol: %s", role)
+		}
+		if models.IsPrivilegedRole(role) {
+			return fmt.Errorf("%s rolü kendi kendine kayıt için açılamaz", role)
+		}
+		allowed[role] = true
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("en az bir kayıt rolü tanımlanmalıdır")
+	}
+
+	s.registerableRoles = allowed
+	return nil
+}
+
+// SetAccountNumberConfig hesap numarası üretim ayarlarını değiştirir (nil ise hesap numarası üretilmez)
+func (s *UserService) SetAccountNumberConfig(config *AccountNumberConfig) {
+	s.accountNumberConfig = config
 }
 
 // Register yeni kullanıcı kaydeder
@@ -25,21 +63,23 @@ func (s *UserService) Register(req *models.CreateUserRequest) (*models.User, err
 	// Email zaten var mı kontrol et
 	existingUser, _ := s.userRepo.GetByEmail(req.Email)
 	if existingUser != nil {
-		return nil, fmt.Errorf("bu email zaten kullanılıyor")
+		return nil, models.ErrEmailAlreadyInUse
 	}
 
 	// GÜVENLIK: Role assignment kontrolü
 	// Sadece admin ve mod rolleri özel izin gerektirir
-	if req.Role == "admin" || req.Role == "mod" {
+	req.Role = models.NormalizeRole(req.Role)
+	if models.IsPrivilegedRole(req.Role) {
 		return nil, fmt.Errorf("admin ve moderator hesapları sadece sistem yöneticisi tarafından oluşturulabilir")
 	}
 
 	// Geçerli role kontrolü ve default assignment
-	if req.Role == "" || req.Role == "user" {
-		req.Role = "user" // Default role
-	} else {
-		// Geçersiz role girişi
-		return nil, fmt.Errorf("geçersiz rol: %s. Sadece 'user' rolü ile kayıt olabilirsiniz", req.Role)
+	if req.Role == "" {
+		req.Role = models.RoleUser // Default role
+	}
+	if !s.registerableRoles[req.Role] {
+		// İzin verilmeyen role girişi
+		return nil, fmt.Errorf("geçersiz rol: %s. Kayıt için izin verilen roller: %s", req.Role, strings.Join(s.RegisterableRoles(), ", "))
 	}
 
 	// Şifreyi hashle
@@ -51,9 +91,13 @@ func (s *UserService) Register(req *models.CreateUserRequest) (*models.User, err
 	// Hashlenen şifreyi request'e ata
 	req.Password = string(hashedPassword)
 
-	// Kullanıcıyı oluştur
-	user, err := s.userRepo.Create(req)
+	// Kullanıcıyı oluştur (hesap numarası çakışırsa yeni numara ile tekrar denenir)
+	user, err := s.createWithAccountNumber(req)
 	if err != nil {
+		// Eşzamanlı kayıt yarışını DB index'i yakalar; mesaj ön kontrolle aynı kalır
+		if errors.Is(err, models.ErrEmailAlreadyInUse) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("kullanıcı oluşturulamadı: %w", err)
 	}
 
@@ -63,6 +107,44 @@ func (s *UserService) Register(req *models.CreateUserRequest) (*models.User, err
 	return user, nil
 }
 
+// RegisterableRoles kendi kendine kayıt olunabilen rolleri sıralı olarak döner
+func (s *UserService) RegisterableRoles() []string {
+	roles := make([]string, 0, len(s.registerableRoles))
+	for role := range s.registerableRoles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// createWithAccountNumber kullanıcıyı benzersiz bir hesap numarası ile oluşturur
+func (s *UserService) createWithAccountNumber(req *models.CreateUserRequest) (*models.User, error) {
+	if s.accountNumberConfig == nil {
+		return s.userRepo.Create(req)
+	}
+
+	maxAttempts := s.accountNumberConfig.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		accountNumber, err := GenerateAccountNumber(s.accountNumberConfig)
+		if err != nil {
+			return nil, err
+		}
+		req.AccountNumber = accountNumber
+
+		user, err := s.userRepo.Create(req)
+		if err == nil {
+			return user, nil
+		}
+		if !db.IsUniqueViolation(err, accountNumberIndex) || attempt >= maxAttempts {
+			return nil, err
+		}
+	}
+}
+
 // Login kullanıcı girişi yapar ve token döner
 func (s *UserService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
 	// Email ile kullanıcıyı bul
@@ -77,16 +159,18 @@ func (s *UserService) Login(req *models.LoginRequest) (*models.LoginResponse, er
 		return nil, fmt.Errorf("email veya şifre hatalı")
 	}
 
-	// JWT token oluştur (role'u da dahil et)
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role)
+	// Access/refresh token çifti oluştur (role'u da dahil et)
+	tokens, err := auth.GenerateTokenPair(user.ID, user.Email, user.Role)
 	if err != nil {
 		return nil, fmt.Errorf("token oluşturulamadı: %w", err)
 	}
 
 	// Response oluştur
 	response := &models.LoginResponse{
-		User:  user,
-		Token: token,
+		User:         user,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}
 
 	return response, nil
@@ -97,7 +181,7 @@ func (s *UserService) CreateAdminUser(req *models.CreateUserRequest) (*models.Us
 	// Email zaten var mı kontrol et
 	existingUser, _ := s.userRepo.GetByEmail(req.Email)
 	if existingUser != nil {
-		return nil, fmt.Errorf("bu email zaten kullanılıyor")
+		return nil, models.ErrEmailAlreadyInUse
 	}
 
 	// Role'u admin olarak force et
@@ -121,6 +205,56 @@ func (s *UserService) CreateAdminUser(req *models.CreateUserRequest) (*models.Us
 	return user, nil
 }
 
+// BootstrapAdmin hiç admin yoksa verilen bilgilerle ilk admin'i oluşturur (idempotent)
+// Email veya şifre boşsa ya da zaten bir admin varsa hiçbir şey yapmaz; oluşturulduysa created true döner.
+// Email zaten kullanımdaysa (aynı anda açılan başka bir instance admin'i oluşturmuş ya da email admin olmayan bir
+// kullanıcıya ait) açılış durdurulmaz, uyarı loglanıp oluşturulmadı olarak döner.
+func (s *UserService) BootstrapAdmin(name, email, password string) (user *models.User, created bool, err error) {
+	if email == "" || password == "" {
+		return nil, false, nil
+	}
+
+	adminCount, err := s.userRepo.CountByRole("admin")
+	if err != nil {
+		return nil, false, fmt.Errorf("admin kontrolü yapılamadı: %w", err)
+	}
+	if adminCount > 0 {
+		return nil, false, nil
+	}
+
+	req := &models.CreateUserRequest{
+		Name:            name,
+		Email:           email,
+		Password:        password,
+		ConfirmPassword: password,
+		Role:            "admin",
+	}
+	if err := req.Validate(); err != nil {
+		return nil, false, fmt.Errorf("admin bootstrap bilgileri geçersiz: %w", err)
+	}
+
+	user, err = s.CreateAdminUser(req)
+	if errors.Is(err, models.ErrEmailAlreadyInUse) {
+		s.logBootstrapEmailInUse(email)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return user, true, nil
+}
+
+// logBootstrapEmailInUse bootstrap email'i kullanımdayken nedenini loglar
+func (s *UserService) logBootstrapEmailInUse(email string) {
+	existing, err := s.userRepo.GetByEmail(email)
+	if err == nil && existing != nil && existing.Role == "admin" {
+		log.Info().Int("user_id", existing.ID).Str("email", email).Msg("Admin bootstrap atlandı: admin başka bir instance tarafından oluşturulmuş")
+		return
+	}
+	log.Warn().Str("email", email).Msg("Admin bootstrap atlandı: ADMIN_EMAIL admin olmayan bir kullanıcıya ait, admin oluşturulmadı")
+}
+
 // PromoteUserToMod bir user'ı moderator yapar (sadece admin yapabilir)
 func (s *UserService) PromoteUserToMod(adminUserID, targetUserID int) error {
 	// Admin kontrolü burada yapılmayacak, RBAC middleware'de yapılacak
@@ -207,6 +341,9 @@ func (s *UserService) UpdateUser(userID int, req *models.UpdateUserRequest) (*mo
 	// Repository'den güncelle
 	updatedUser, err := s.userRepo.Update(userID, req)
 	if err != nil {
+		if errors.Is(err, models.ErrEmailAlreadyInUse) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("kullanıcı güncellenemedi: %w", err)
 	}
 
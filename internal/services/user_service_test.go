@@ -38,6 +38,14 @@ func (m *MockUserRepository) GetByID(id int) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByAccountNumber(accountNumber string) (*models.User, error) {
+	args := m.Called(accountNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(id int, user *models.UpdateUserRequest) (*models.User, error) {
 	args := m.Called(id, user)
 	if args.Get(0) == nil {
@@ -56,6 +64,16 @@ func (m *MockUserRepository) GetAll(limit, offset int) ([]*models.User, int, err
 	return args.Get(0).([]*models.User), args.Int(1), args.Error(2)
 }
 
+func (m *MockUserRepository) CountByRole(role string) (int, error) {
+	args := m.Called(role)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) IsActive(id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
 // İlk basit test - kullanıcı kaydı
 func TestUserService_Register_Success(t *testing.T) {
 	// Arrange
@@ -128,3 +146,155 @@ func TestUserService_Register_EmailExists(t *testing.T) {
 	// Mock assertions
 	mockRepo.AssertExpectations(t)
 }
+
+// TestUserService_BootstrapAdmin_CreatesWhenNoAdmin, hiç admin yokken bootstrap'ın admin oluşturduğunu test eder.
+func TestUserService_BootstrapAdmin_CreatesWhenNoAdmin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+
+	mockRepo.On("CountByRole", "admin").Return(0, nil)
+	mockRepo.On("GetByEmail", "root@example.com").Return(nil, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(req *models.CreateUserRequest) bool {
+		return req.Email == "root@example.com" && req.Role == "admin" && req.Password != "Admin123!"
+	})).Return(&models.User{ID: 1, Name: "System Admin", Email: "root@example.com", Role: "admin"}, nil)
+
+	// Act
+	user, created, err := userService.BootstrapAdmin("System Admin", "root@example.com", "Admin123!")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "admin", user.Role)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUserService_BootstrapAdmin_SkipsWhenAdminExists, admin varken bootstrap'ın kullanıcı oluşturmadığını test eder.
+func TestUserService_BootstrapAdmin_SkipsWhenAdminExists(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+
+	mockRepo.On("CountByRole", "admin").Return(1, nil)
+
+	// Act
+	user, created, err := userService.BootstrapAdmin("System Admin", "root@example.com", "Admin123!")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Nil(t, user)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestUserService_BootstrapAdmin_EmailInUseDoesNotFail, ADMIN_EMAIL admin olmayan bir kullanıcıya aitken veya eşzamanlı
+// açılan başka bir instance admin'i aynı anda oluşturduğunda (unique ihlali) bootstrap'ın hata dönmediğini test eder.
+func TestUserService_BootstrapAdmin_EmailInUseDoesNotFail(t *testing.T) {
+	// Arrange
+	existingRepo := new(MockUserRepository)
+	existingRepo.On("CountByRole", "admin").Return(0, nil)
+	existingRepo.On("GetByEmail", "root@example.com").Return(&models.User{ID: 5, Email: "root@example.com", Role: "user"}, nil)
+
+	racingRepo := new(MockUserRepository)
+	racingRepo.On("CountByRole", "admin").Return(0, nil)
+	racingRepo.On("GetByEmail", "root@example.com").Return(nil, nil).Once()
+	racingRepo.On("Create", mock.Anything).Return((*models.User)(nil), models.ErrEmailAlreadyInUse)
+	racingRepo.On("GetByEmail", "root@example.com").Return(&models.User{ID: 1, Email: "root@example.com", Role: "admin"}, nil)
+
+	// Act
+	existingUser, existingCreated, existingErr := NewUserService(existingRepo).BootstrapAdmin("System Admin", "root@example.com", "Admin123!")
+	racingUser, racingCreated, racingErr := NewUserService(racingRepo).BootstrapAdmin("System Admin", "root@example.com", "Admin123!")
+
+	// Assert
+	assert.NoError(t, existingErr)
+	assert.False(t, existingCreated)
+	assert.Nil(t, existingUser)
+	existingRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	assert.NoError(t, racingErr)
+	assert.False(t, racingCreated)
+	assert.Nil(t, racingUser)
+	racingRepo.AssertExpectations(t)
+}
+
+// TestUserService_BootstrapAdmin_DisabledWithoutCredentials, email/şifre verilmediğinde bootstrap'ın hiçbir şey yapmadığını test eder.
+func TestUserService_BootstrapAdmin_DisabledWithoutCredentials(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+
+	// Act
+	user, created, err := userService.BootstrapAdmin("System Admin", "", "")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Nil(t, user)
+	mockRepo.AssertNotCalled(t, "CountByRole", mock.Anything)
+}
+
+// TestUserService_Register_ConfiguredRoleAllowed, kayıt için izin verilen merchant rolüyle kaydın başarılı olduğunu test eder.
+func TestUserService_Register_ConfiguredRoleAllowed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+	userService.SetAccountNumberConfig(nil)
+	assert.NoError(t, userService.SetRegisterableRoles([]string{"user", "Merchant"}))
+
+	mockRepo.On("GetByEmail", "shop@example.com").Return(nil, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(req *models.CreateUserRequest) bool {
+		return req.Role == models.RoleMerchant
+	})).Return(&models.User{ID: 7, Email: "shop@example.com", Role: models.RoleMerchant}, nil)
+
+	// Act
+	user, err := userService.Register(&models.CreateUserRequest{
+		Name:     "Shop",
+		Email:    "shop@example.com",
+		Password: "Password123!",
+		Role:     "merchant",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleMerchant, user.Role)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUserService_Register_DisallowedRoleRejected, izin listesinde olmayan rolle kaydın reddedildiğini test eder.
+func TestUserService_Register_DisallowedRoleRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+
+	mockRepo.On("GetByEmail", "shop@example.com").Return(nil, nil)
+
+	// Act
+	user, err := userService.Register(&models.CreateUserRequest{
+		Name:     "Shop",
+		Email:    "shop@example.com",
+		Password: "Password123!",
+		Role:     "merchant",
+	})
+
+	// Assert
+	assert.Nil(t, user)
+	assert.EqualError(t, err, "geçersiz rol: merchant. Kayıt için izin verilen roller: user")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestUserService_SetRegisterableRoles_RejectsPrivilegedAndUnknown, admin/mod ve tanımsız rollerin izin listesine eklenemediğini test eder.
+func TestUserService_SetRegisterableRoles_RejectsPrivilegedAndUnknown(t *testing.T) {
+	// Arrange
+	userService := NewUserService(new(MockUserRepository))
+
+	// Act
+	adminErr := userService.SetRegisterableRoles([]string{"user", "admin"})
+	modErr := userService.SetRegisterableRoles([]string{"mod"})
+	unknownErr := userService.SetRegisterableRoles([]string{"superuser"})
+
+	// Assert
+	assert.EqualError(t, adminErr, "admin rolü kendi kendine kayıt için açılamaz")
+	assert.EqualError(t, modErr, "mod rolü kendi kendine kayıt için açılamaz")
+	assert.EqualError(t, unknownErr, "kayıt için tanımsız rol: superuser")
+	assert.Equal(t, []string{"user"}, userService.RegisterableRoles())
+}
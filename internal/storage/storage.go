@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidKey boş veya kök dizinin dışına çıkan key verildiğinde döner
+var ErrInvalidKey = errors.New("geçersiz storage key")
+
+// Storage export dosyalarının yazıldığı object store.
+// Local disk varsayılan implementasyondur; S3/GCS implementasyonları aynı interface'i sağlar.
+type Storage interface {
+	// Put key altına r'deki içeriği yazar (varsa üzerine yazar); r sonuna kadar okunur
+	Put(ctx context.Context, key string, r io.Reader) error
+}
+
+// LocalStorage key'leri kök dizin altındaki dosyalara yazan Storage
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage verilen dizini kök alan local storage oluşturur (dizin yoksa oluşturulur)
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	if strings.TrimSpace(root) == "" {
+		return nil, fmt.Errorf("storage dizini boş olamaz")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("storage dizini oluşturulamadı: %w", err)
+	}
+	return &LocalStorage{root: root}, nil
+}
+
+// Path key'in disk üzerindeki yolunu döner
+func (s *LocalStorage) Path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put içeriği önce geçici dosyaya yazar, tamamlanınca yerine taşır (yarım dosya görünmez)
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	target, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("storage dizini oluşturulamadı: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("geçici dosya oluşturulamadı: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage yazma hatası: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage yazma hatası: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("storage dosyası taşınamadı: %w", err)
+	}
+	return nil
+}
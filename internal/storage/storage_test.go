@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLocalStorage_Put, içeriğin key altındaki dosyaya yazıldığını ve kök dışına çıkan key'lerin reddedildiğini test eder.
+func TestLocalStorage_Put(t *testing.T) {
+	// Arrange
+	root := t.TempDir()
+	store, err := NewLocalStorage(root)
+	require.NoError(t, err)
+
+	// Act
+	putErr := store.Put(context.Background(), "exports/day.csv", strings.NewReader("id\n1\n"))
+	escapeErr := store.Put(context.Background(), "../outside.csv", strings.NewReader("x"))
+	emptyErr := store.Put(context.Background(), "", strings.NewReader("x"))
+
+	// Assert
+	require.NoError(t, putErr)
+	content, err := os.ReadFile(filepath.Join(root, "exports", "day.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "id\n1\n", string(content))
+
+	assert.ErrorIs(t, escapeErr, ErrInvalidKey)
+	assert.ErrorIs(t, emptyErr, ErrInvalidKey)
+	_, statErr := os.Stat(filepath.Join(filepath.Dir(root), "outside.csv"))
+	assert.True(t, os.IsNotExist(statErr))
+}
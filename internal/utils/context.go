@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// requestIDKey request ID'nin context'teki key tipi
+type requestIDKey struct{}
+
+// WithRequestID request ID'yi context'e ekler
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext context'teki request ID'yi döner (yoksa boş string)
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// QueryCounter bir request süresince çalıştırılan DB sorgularını sayar (eşzamanlı kullanım güvenli)
+type QueryCounter struct {
+	count atomic.Int64
+}
+
+// Inc sayacı bir artırır
+func (c *QueryCounter) Inc() {
+	c.count.Add(1)
+}
+
+// Count şu ana kadar sayılan sorgu sayısını döner
+func (c *QueryCounter) Count() int64 {
+	return c.count.Load()
+}
+
+// queryCounterKey query counter'ın context'teki key tipi
+type queryCounterKey struct{}
+
+// WithQueryCounter context'te sayaç varsa onu, yoksa yeni bir sayaç ekleyip döner
+func WithQueryCounter(ctx context.Context) (context.Context, *QueryCounter) {
+	if counter := QueryCounterFromContext(ctx); counter != nil {
+		return ctx, counter
+	}
+	counter := &QueryCounter{}
+	return context.WithValue(ctx, queryCounterKey{}, counter), counter
+}
+
+// QueryCounterFromContext context'teki query counter'ı döner (yoksa nil)
+func QueryCounterFromContext(ctx context.Context) *QueryCounter {
+	if ctx == nil {
+		return nil
+	}
+	counter, _ := ctx.Value(queryCounterKey{}).(*QueryCounter)
+	return counter
+}
+
+// ServerTiming bir request'in aşama sürelerini (validation, auth, db...) toplar (eşzamanlı kullanım güvenli)
+type ServerTiming struct {
+	mutex  sync.Mutex
+	stages []TimingStage
+}
+
+// Server-Timing aşama adları
+const (
+	TimingValidation = "validation"
+	TimingAuth       = "auth"
+	TimingDB         = "db"
+)
+
+// TimingStage bir aşamanın adı ve request boyunca toplam süresi
+type TimingStage struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Add aşama süresini ekler; aynı aşama birden çok kez ölçülürse süreler toplanır (ör. birden fazla DB sorgusu)
+func (t *ServerTiming) Add(name string, duration time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i := range t.stages {
+		if t.stages[i].Name == name {
+			t.stages[i].Duration += duration
+			return
+		}
+	}
+	t.stages = append(t.stages, TimingStage{Name: name, Duration: duration})
+}
+
+// Stages aşamaları ilk ölçülme sırasıyla döner
+func (t *ServerTiming) Stages() []TimingStage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stages := make([]TimingStage, len(t.stages))
+	copy(stages, t.stages)
+	return stages
+}
+
+// serverTimingKey server timing'in context'teki key tipi
+type serverTimingKey struct{}
+
+// WithServerTiming context'e yeni bir aşama süresi toplayıcı ekler
+func WithServerTiming(ctx context.Context) (context.Context, *ServerTiming) {
+	timing := &ServerTiming{}
+	return context.WithValue(ctx, serverTimingKey{}, timing), timing
+}
+
+// ServerTimingFromContext context'teki aşama süresi toplayıcıyı döner (yoksa nil)
+func ServerTimingFromContext(ctx context.Context) *ServerTiming {
+	if ctx == nil {
+		return nil
+	}
+	timing, _ := ctx.Value(serverTimingKey{}).(*ServerTiming)
+	return timing
+}
+
+// RecordTiming context'te toplayıcı varsa aşama süresini ekler (Server-Timing kapalıyken no-op)
+func RecordTiming(ctx context.Context, name string, duration time.Duration) {
+	if timing := ServerTimingFromContext(ctx); timing != nil {
+		timing.Add(name, duration)
+	}
+}
+
+// channelKey istek kanalının (web/mobile/api) context'teki key tipi
+type channelKey struct{}
+
+// WithChannel isteği başlatan kanalı context'e ekler
+func WithChannel(ctx context.Context, channel string) context.Context {
+	return context.WithValue(ctx, channelKey{}, channel)
+}
+
+// ChannelFromContext context'teki kanalı döner (yoksa boş string)
+func ChannelFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	channel, _ := ctx.Value(channelKey{}).(string)
+	return channel
+}
+
+// idempotencyKeyKey Idempotency-Key header değerinin context'teki key tipi
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey isteğin Idempotency-Key değerini context'e ekler (transfer queue'sundan da geçer)
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext context'teki Idempotency-Key'i döner (yoksa boş string)
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
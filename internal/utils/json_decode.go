@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// JSON decode hata türleri (JSONDecodeError.Kind)
+const (
+	JSONErrorSyntax        = "syntax"
+	JSONErrorTypeMismatch  = "type_mismatch"
+	JSONErrorUnexpectedEOF = "unexpected_eof"
+	JSONErrorEmptyBody     = "empty_body"
+	JSONErrorOther         = "other"
+)
+
+// MaxJSONBodySize DecodeJSONBody'nin belleğe okuyacağı en büyük body (validation middleware'in varsayılan MaxBodySize'ı ile aynı).
+// Middleware sadece Content-Length'i kontrol ettiğinden chunked body'ler burada sınırlanır.
+const MaxJSONBodySize = 1024 * 1024
+
+// ErrJSONBodyTooLarge body MaxJSONBodySize'ı aştığında döner (body decode edilmez)
+var ErrJSONBodyTooLarge = errors.New("request body çok büyük")
+
+// JSONDecodeError malformed JSON body'sinin hata yerini taşır.
+// Offset ve body yalnızca hata mesajını zenginleştirmek içindir; client'a ne kadarının gösterileceğine çağıran karar verir.
+type JSONDecodeError struct {
+	Err    error
+	Kind   string
+	Offset int64 // Hatanın oluştuğu byte offset'i (bilinmiyorsa -1)
+
+	Field    string // Tip uyuşmazlığında alan yolu (ör. "amount")
+	Expected string // Tip uyuşmazlığında beklenen Go tipi
+	Actual   string // Tip uyuşmazlığında gelen JSON değer tipi
+
+	body []byte
+}
+
+// Error alttaki decode hatasının mesajını döner
+func (e *JSONDecodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap errors.Is(err, io.EOF) gibi kontrollerin çalışması için alttaki hatayı döner
+func (e *JSONDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// errorIndex hatalı byte'ın body'deki indeksini döner. Decoder Offset'i hatalı byte okunduktan sonraki konumdur;
+// beklenmedik EOF'ta ise body'nin sonunu gösterir.
+func (e *JSONDecodeError) errorIndex() int {
+	index := int(e.Offset)
+	if e.Kind == JSONErrorSyntax || e.Kind == JSONErrorTypeMismatch {
+		index--
+	}
+	return min(max(index, 0), len(e.body))
+}
+
+// Position hatalı byte'ın body içindeki satır ve sütununu (1'den başlayan) döner; body veya offset yoksa ok=false
+func (e *JSONDecodeError) Position() (line, column int, ok bool) {
+	if e.Offset < 0 || e.body == nil {
+		return 0, 0, false
+	}
+	offset := e.errorIndex()
+
+	before := e.body[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = offset - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	return line, column, true
+}
+
+// Snippet hatalı byte'ın çevresinden en fazla maxLen byte'lık, kontrol karakterlerinden arındırılmış kesit döner.
+// Kesit body'nin başından veya sonundan kırpıldıysa "..." ile işaretlenir.
+func (e *JSONDecodeError) Snippet(maxLen int) string {
+	if maxLen <= 0 || e.Offset < 0 || len(e.body) == 0 {
+		return ""
+	}
+	offset := e.errorIndex()
+
+	start := max(offset-maxLen/2, 0)
+	end := min(start+maxLen, len(e.body))
+	start = max(end-maxLen, 0)
+
+	snippet := strings.ToValidUTF8(string(e.body[start:end]), "")
+	snippet = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, snippet)
+
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(e.body) {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// DecodeJSONBody body'yi tek bir JSON değeri olarak dst'ye decode eder.
+// Body hata yerini gösterebilmek için önce en fazla MaxJSONBodySize kadar okunur, aşarsa ErrJSONBodyTooLarge döner;
+// decode hatası *JSONDecodeError olarak döner.
+func DecodeJSONBody(body io.Reader, dst interface{}) error {
+	data, err := io.ReadAll(io.LimitReader(body, MaxJSONBodySize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxJSONBodySize {
+		return fmt.Errorf("%w. Maksimum boyut: %d bytes", ErrJSONBodyTooLarge, MaxJSONBodySize)
+	}
+
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(dst); err != nil {
+		return NewJSONDecodeError(err, data)
+	}
+	return nil
+}
+
+// NewJSONDecodeError decode hatasını türüne göre sınıflandırır; body nil olabilir (stream decode)
+func NewJSONDecodeError(err error, body []byte) *JSONDecodeError {
+	decodeErr := &JSONDecodeError{Err: err, Kind: JSONErrorOther, Offset: -1, body: body}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		decodeErr.Kind = JSONErrorSyntax
+		decodeErr.Offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		decodeErr.Kind = JSONErrorTypeMismatch
+		decodeErr.Offset = typeErr.Offset
+		decodeErr.Field = typeErr.Field
+		decodeErr.Actual = typeErr.Value
+		if typeErr.Type != nil {
+			decodeErr.Expected = typeErr.Type.String()
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		decodeErr.Kind = JSONErrorUnexpectedEOF
+		if body != nil {
+			decodeErr.Offset = int64(len(body))
+		}
+	case errors.Is(err, io.EOF):
+		decodeErr.Kind = JSONErrorEmptyBody
+	}
+	return decodeErr
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecodeJSONBody_SyntaxErrorSnippet, uzun body'deki sözdizimi hatasında kesitin hata yeri çevresinden
+// kırpıldığını, kontrol karakterlerinden arındırıldığını ve satır/sütunun doğru hesaplandığını test eder.
+func TestDecodeJSONBody_SyntaxErrorSnippet(t *testing.T) {
+	// Arrange
+	body := "{\n\t\"note\": \"" + strings.Repeat("a", 50) + "\",\n\t\"amount\": 10,,\n\t\"tail\": \"" + strings.Repeat("b", 50) + "\"\n}"
+	var dst map[string]interface{}
+
+	// Act
+	err := DecodeJSONBody(strings.NewReader(body), &dst)
+
+	// Assert
+	var decodeErr *JSONDecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, JSONErrorSyntax, decodeErr.Kind)
+
+	line, column, ok := decodeErr.Position()
+	require.True(t, ok)
+	assert.Equal(t, 3, line)
+	assert.Equal(t, 15, column)
+
+	snippet := decodeErr.Snippet(20)
+	assert.Equal(t, `...ount": 10,,  "tail":...`, snippet)
+	assert.NotContains(t, snippet, "\n")
+	assert.Empty(t, decodeErr.Snippet(0))
+}
+
+// TestDecodeJSONBody_RejectsOversizedBody, Content-Length'siz (chunked) gelse bile MaxJSONBodySize'ı aşan body'nin
+// decode edilmeden reddedildiğini, sınırdaki body'nin ise kabul edildiğini test eder.
+func TestDecodeJSONBody_RejectsOversizedBody(t *testing.T) {
+	// Arrange
+	atLimit := `"` + strings.Repeat("a", MaxJSONBodySize-2) + `"`
+	overLimit := `"` + strings.Repeat("a", MaxJSONBodySize-1) + `"`
+	var dst string
+
+	// Act
+	atLimitErr := DecodeJSONBody(strings.NewReader(atLimit), &dst)
+	overLimitErr := DecodeJSONBody(strings.NewReader(overLimit), &dst)
+
+	// Assert
+	assert.NoError(t, atLimitErr)
+	assert.Len(t, dst, MaxJSONBodySize-2)
+	assert.ErrorIs(t, overLimitErr, ErrJSONBodyTooLarge)
+}
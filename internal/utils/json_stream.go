@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrJSONArrayTooLarge array eleman sayısı limiti decode sırasında aşıldığında döner
+var ErrJSONArrayTooLarge = errors.New("JSON array eleman limiti aşıldı")
+
+// DecodeJSONArray decoder'daki sıradaki JSON array'ini eleman eleman decode eder.
+// Array önce tamamen belleğe alınmaz; maxItems aşıldığı anda kalan veri okunmadan hata döner.
+// null değeri boş array gibi kabul edilir.
+func DecodeJSONArray(dec *json.Decoder, maxItems int, decodeItem func(dec *json.Decoder) error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if d, ok := token.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("geçersiz JSON: '[' bekleniyordu")
+	}
+
+	count := 0
+	for dec.More() {
+		if count >= maxItems {
+			return fmt.Errorf("%w: en fazla %d eleman gönderilebilir", ErrJSONArrayTooLarge, maxItems)
+		}
+		if err := decodeItem(dec); err != nil {
+			return err
+		}
+		count++
+	}
+
+	return ExpectJSONDelim(dec, ']')
+}
+
+// ExpectJSONDelim sıradaki token'ın beklenen ayraç ({, }, [, ]) olduğunu kontrol eder
+func ExpectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("geçersiz JSON: '%s' bekleniyordu", delim)
+	}
+	return nil
+}
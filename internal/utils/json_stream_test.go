@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// endlessArrayReader hiç bitmeyen bir JSON array üretir ve okunan byte sayısını tutar
+type endlessArrayReader struct {
+	started   bool
+	bytesRead int
+}
+
+func (r *endlessArrayReader) Read(p []byte) (int, error) {
+	item := `{"to_user_id":2,"amount":1},`
+	n := 0
+	if !r.started {
+		p[0] = '['
+		n = 1
+		r.started = true
+	}
+	for n+len(item) <= len(p) {
+		n += copy(p[n:], item)
+	}
+	r.bytesRead += n
+	return n, nil
+}
+
+// TestDecodeJSONArray_RejectsOverCapWithoutBuffering, limiti aşan array'in tamamı okunmadan reddedildiğini test eder.
+func TestDecodeJSONArray_RejectsOverCapWithoutBuffering(t *testing.T) {
+	// Arrange
+	reader := &endlessArrayReader{}
+	dec := json.NewDecoder(reader)
+	decoded := 0
+
+	// Act
+	err := DecodeJSONArray(dec, 100, func(dec *json.Decoder) error {
+		var item map[string]interface{}
+		decoded++
+		return dec.Decode(&item)
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrJSONArrayTooLarge)
+	assert.Equal(t, 100, decoded)
+	assert.Less(t, reader.bytesRead, 64*1024)
+}
+
+// TestDecodeJSONArray_DecodesWithinCap, limit içindeki array'in tüm elemanlarının decode edildiğini test eder.
+func TestDecodeJSONArray_DecodesWithinCap(t *testing.T) {
+	// Arrange
+	dec := json.NewDecoder(strings.NewReader(`[1, 2, 3]`))
+	var items []int
+
+	// Act
+	err := DecodeJSONArray(dec, 3, func(dec *json.Decoder) error {
+		var item int
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		items = append(items, item)
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+	_, err = dec.Token()
+	assert.ErrorIs(t, err, io.EOF)
+}
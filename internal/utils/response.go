@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// jsonCharset JSON yanıtlarında kullanılan charset (config ile değiştirilebilir)
+var jsonCharset = "utf-8"
+
+// SetJSONCharset JSON yanıtlarının charset'ini ayarlar (boş ise charset eklenmez)
+func SetJSONCharset(charset string) {
+	jsonCharset = charset
+}
+
+// JSONContentType charset dahil JSON Content-Type değerini döner
+func JSONContentType() string {
+	if jsonCharset == "" {
+		return "application/json"
+	}
+	return "application/json; charset=" + jsonCharset
+}
+
+// WriteJSON Content-Type, status code ve JSON body'yi tek seferde yazar
+func WriteJSON(w http.ResponseWriter, statusCode int, payload interface{}) error {
+	w.Header().Set("Content-Type", JSONContentType())
+	w.WriteHeader(statusCode)
+	return json.NewEncoder(w).Encode(payload)
+}
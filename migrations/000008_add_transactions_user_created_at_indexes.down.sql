@@ -0,0 +1,3 @@
+-- Remove daily limit indexes
+DROP INDEX IF EXISTS idx_transactions_to_user_created_at;
+DROP INDEX IF EXISTS idx_transactions_from_user_created_at;
@@ -0,0 +1,3 @@
+-- Günlük transaction sayısı/tutarı sorguları için composite index'ler
+CREATE INDEX IF NOT EXISTS idx_transactions_from_user_created_at ON transactions(from_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_to_user_created_at ON transactions(to_user_id, created_at);
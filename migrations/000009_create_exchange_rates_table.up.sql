@@ -0,0 +1,9 @@
+-- Create exchange_rates table for cross-currency conversions
+CREATE TABLE exchange_rates (
+    id SERIAL PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate DECIMAL(20,8) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (base_currency, quote_currency)
+);
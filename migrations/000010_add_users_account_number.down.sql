@@ -0,0 +1,3 @@
+-- Remove account number column and its unique index
+DROP INDEX IF EXISTS idx_users_account_number;
+ALTER TABLE users DROP COLUMN IF EXISTS account_number;
@@ -0,0 +1,7 @@
+-- Add shareable, checksummed account number (IBAN-like) to users
+ALTER TABLE users ADD COLUMN IF NOT EXISTS account_number VARCHAR(34);
+
+-- Account numbers must be unique; existing users without one are allowed
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_account_number
+    ON users(account_number)
+    WHERE account_number IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Drop balance_history archive table
+DROP INDEX IF EXISTS idx_balance_history_archive_user_date;
+DROP TABLE IF EXISTS balance_history_archive;
@@ -0,0 +1,15 @@
+-- Archive table for balance_history rows older than the retention window
+CREATE TABLE IF NOT EXISTS balance_history_archive (
+    id INTEGER PRIMARY KEY, -- balance_history'deki orijinal id korunur
+    user_id INTEGER NOT NULL,
+    previous_amount DECIMAL(15,2) NOT NULL,
+    new_amount DECIMAL(15,2) NOT NULL,
+    change_amount DECIMAL(15,2) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    transaction_id INTEGER,
+    created_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Point-in-time sorguları için composite index
+CREATE INDEX IF NOT EXISTS idx_balance_history_archive_user_date ON balance_history_archive(user_id, created_at);
@@ -0,0 +1,3 @@
+-- Restore the hardcoded transaction type constraint
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('credit', 'debit', 'transfer'));
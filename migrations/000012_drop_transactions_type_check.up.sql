@@ -0,0 +1,3 @@
+-- Valid transaction types are defined by the application's type registry
+-- (models.RegisterTransactionType), so new types need no schema change
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS transaction_reviews;
+
+-- Held transfers cannot be represented without the review table
+UPDATE transactions SET status = 'failed' WHERE status = 'pending_review';
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check
+    CHECK (status IN ('pending', 'completed', 'failed', 'cancelled'));
@@ -0,0 +1,19 @@
+-- Allow transfers held for fraud review
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check
+    CHECK (status IN ('pending', 'pending_review', 'completed', 'failed', 'cancelled'));
+
+-- Review records for held transfers; admins approve (settle) or reject (fail) them
+CREATE TABLE IF NOT EXISTS transaction_reviews (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(id),
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'approved', 'rejected')),
+    note TEXT,
+    reviewed_by INTEGER REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Admin kuyruğu status'a göre, en eskiden yeniye listelenir
+CREATE INDEX IF NOT EXISTS idx_transaction_reviews_status_created_at ON transaction_reviews(status, created_at);
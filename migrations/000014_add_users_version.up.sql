@@ -0,0 +1,3 @@
+-- Optimistic concurrency için kullanıcı versiyonu (her güncellemede artar, If-Match/ETag ile karşılaştırılır)
+ALTER TABLE users
+ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
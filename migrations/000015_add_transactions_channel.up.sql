@@ -0,0 +1,3 @@
+-- Transaction'ı başlatan kanal (web/mobile/api); kanal bilinmeyen eski kayıtlar NULL kalır
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS channel VARCHAR(20);
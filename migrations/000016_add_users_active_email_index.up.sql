@@ -0,0 +1,9 @@
+-- Email sadece aktif (silinmemiş) kullanıcılar arasında benzersiz olmalı.
+-- Tablo seviyesindeki UNIQUE constraint silinmiş hesapların email'ini de kilitlediği için partial index ile değiştirilir;
+-- eşzamanlı kayıtlarda uygulama kontrolünü geçen ikinci INSERT bu index'e takılır.
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_email_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active
+ON users(email)
+WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- Kullanıcı bazlı bildirim tercihleri; satır yoksa varsayılanlar (hepsi açık) uygulanır
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    transfer_received BOOLEAN NOT NULL DEFAULT TRUE,
+    low_balance BOOLEAN NOT NULL DEFAULT TRUE,
+    security_events BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- Marketplace authorize/capture akışı için bakiye blokeleri; aktif hold tutarı kullanılabilir bakiyeden düşülür
+CREATE TABLE IF NOT EXISTS balance_holds (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    to_user_id INTEGER NOT NULL REFERENCES users(id),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    captured_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'voided')),
+    description TEXT,
+    transaction_id INTEGER REFERENCES transactions(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Kullanılabilir bakiye hesabı kullanıcının aktif hold'larını toplar
+CREATE INDEX IF NOT EXISTS idx_balance_holds_user_active ON balance_holds(user_id) WHERE status = 'active';
@@ -0,0 +1,16 @@
+-- Transaction endpoint'lerinde Idempotency-Key ile tekrar gönderilen isteklerin ilk sonucunu tutar
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    endpoint VARCHAR(50) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed')),
+    transaction_id INTEGER REFERENCES transactions(id),
+    new_balance DECIMAL(15,2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idempotency_keys_user_key_unique UNIQUE (user_id, idempotency_key)
+);
+
+-- Saklama süresi dolan key'ler created_at'e göre temizlenir
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
@@ -0,0 +1,5 @@
+-- Ters kayıt (reversal) orijinal transaction'a bu kolonla bağlanır
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversed_transaction_id INTEGER REFERENCES transactions(id);
+
+-- Bir transaction en fazla bir kez ters çevrilebilir
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reversed_transaction_id ON transactions(reversed_transaction_id) WHERE reversed_transaction_id IS NOT NULL;
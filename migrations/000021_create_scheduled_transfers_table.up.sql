@@ -0,0 +1,17 @@
+-- İleri tarihli transferler; zamanı gelenler scheduler tarafından transaction queue'ya verilir
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id SERIAL PRIMARY KEY,
+    from_user_id INTEGER NOT NULL REFERENCES users(id),
+    to_user_id INTEGER NOT NULL REFERENCES users(id),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    description TEXT,
+    scheduled_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'processing', 'completed', 'failed', 'cancelled')),
+    transaction_id INTEGER REFERENCES transactions(id),
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Scheduler sadece bekleyen transferleri zamanına göre tarar
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers(scheduled_at) WHERE status = 'scheduled';
@@ -0,0 +1,12 @@
+-- Periyodik bakiye snapshot'ları; point-in-time sorguları en yakın snapshot'tan ileri doğru delta toplar
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(15,2) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    taken_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Hedef zamandan önceki en son snapshot
+CREATE INDEX IF NOT EXISTS idx_balance_snapshots_user_taken ON balance_snapshots(user_id, taken_at DESC);
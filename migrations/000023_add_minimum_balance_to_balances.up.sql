@@ -0,0 +1,2 @@
+-- Kullanıcının transfer/çekimlerle altına inilemeyen, kendi belirlediği bakiye tabanı (0 = taban yok)
+ALTER TABLE balances ADD COLUMN IF NOT EXISTS minimum_balance DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (minimum_balance >= 0);
@@ -0,0 +1,5 @@
+-- Kısmi iade kayıtları orijinal transaction'a bu kolonla bağlanır (bir transaction'ın birden çok iadesi olabilir)
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refunded_transaction_id INTEGER REFERENCES transactions(id);
+
+-- Kümülatif iade toplamı orijinal ID ile hesaplanır
+CREATE INDEX IF NOT EXISTS idx_transactions_refunded_transaction_id ON transactions(refunded_transaction_id) WHERE refunded_transaction_id IS NOT NULL;
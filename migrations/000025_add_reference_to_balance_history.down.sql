@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_balance_history_archive_reason_reference;
+ALTER TABLE balance_history_archive DROP COLUMN IF EXISTS reference;
+DROP INDEX IF EXISTS idx_balance_history_reason_reference;
+ALTER TABLE balance_history DROP COLUMN IF EXISTS reference;
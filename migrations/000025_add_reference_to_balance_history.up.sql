@@ -0,0 +1,7 @@
+-- Doğrudan bakiye düzeltmeleri idempotency referansı taşır; aynı reason + referans ikinci kez yazılamaz
+ALTER TABLE balance_history ADD COLUMN IF NOT EXISTS reference VARCHAR(100);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_balance_history_reason_reference ON balance_history(reason, reference) WHERE reference IS NOT NULL;
+
+-- Arşivlenen düzeltmelerin referansı korunur (tekrar kontrolü arşive de bakar)
+ALTER TABLE balance_history_archive ADD COLUMN IF NOT EXISTS reference VARCHAR(100);
+CREATE INDEX IF NOT EXISTS idx_balance_history_archive_reason_reference ON balance_history_archive(reason, reference) WHERE reference IS NOT NULL;
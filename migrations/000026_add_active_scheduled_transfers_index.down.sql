@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_scheduled_transfers_active_user;
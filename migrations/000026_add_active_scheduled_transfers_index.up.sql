@@ -0,0 +1,2 @@
+-- Kullanıcı başına aktif zamanlanmış transfer sınırı kontrolü bekleyen/işlenen kayıtları sayar
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_active_user ON scheduled_transfers(from_user_id) WHERE status IN ('scheduled', 'processing');
@@ -0,0 +1 @@
+DELETE FROM balance_history WHERE reason = 'opening_balance';
@@ -0,0 +1,24 @@
+-- Geçmiş kaydı tutulmadan önce oluşmuş bakiyeler için kullanıcı başına tek bir açılış kaydı (ledger = SUM(change_amount))
+-- Arşiv baseline'ı gibi previous 0, change = bakiye ile mevcut geçmiş toplamı arasındaki fark, fark yoksa kayıt yazılmaz.
+-- Kayıt kullanıcının en eski geçmiş/arşiv/snapshot zamanına tarihlenir: point-in-time ve snapshot sonrası toplamlar değişmez.
+INSERT INTO balance_history (user_id, previous_amount, new_amount, change_amount, reason, created_at)
+SELECT b.user_id, 0, b.amount - COALESCE(h.total, 0), b.amount - COALESCE(h.total, 0), 'opening_balance',
+       COALESCE(LEAST(h.first_at, a.first_at, s.first_at), b.last_updated_at, CURRENT_TIMESTAMP)
+FROM balances b
+LEFT JOIN (
+    SELECT user_id, SUM(change_amount) AS total, MIN(created_at) AS first_at
+    FROM balance_history
+    GROUP BY user_id
+) h ON h.user_id = b.user_id
+LEFT JOIN (
+    SELECT user_id, MIN(created_at) AS first_at
+    FROM balance_history_archive
+    GROUP BY user_id
+) a ON a.user_id = b.user_id
+LEFT JOIN (
+    SELECT user_id, MIN(taken_at) AS first_at
+    FROM balance_snapshots
+    GROUP BY user_id
+) s ON s.user_id = b.user_id
+WHERE b.amount <> COALESCE(h.total, 0)
+  AND NOT EXISTS (SELECT 1 FROM balance_history o WHERE o.user_id = b.user_id AND o.reason = 'opening_balance');